@@ -13,8 +13,9 @@ CYAN='\033[0;36m'
 NC='\033[0m' # No Color
 
 # Configuration
-BINARY_NAME="cpu_monitor"
-SOURCE_FILE="cpu_monitor.go"
+MODULE="github.com/jeremycharlesgillespie/kode_kronical_perf_monitor"
+BINARY_NAME="kkperf"                          # Started by "run"
+BINARIES="kkperf kkperf-agent kkperf-report"  # Built from ./cmd/<name>
 BUILD_DIR="."
 
 # Function to print colored output
@@ -63,9 +64,9 @@ check_dependencies() {
     
     # Check for stress command
     if command_exists stress; then
-        print_success "stress command available - stress testing will work"
+        print_success "stress command available - usable as [stress] backend and by certify"
     else
-        print_warning "stress command not found - stress testing will be disabled"
+        print_warning "stress command not found - the built-in stress engine is used instead"
         print_info "Install with: sudo apt install stress (Ubuntu/Debian)"
         print_info "             sudo pacman -S stress (Arch Linux)"
         print_info "             sudo yum install stress (CentOS/RHEL)"
@@ -88,7 +89,7 @@ setup_module() {
     
     if [ ! -f "go.mod" ]; then
         print_info "Creating go.mod file..."
-        go mod init cpu_monitor
+        go mod init "$MODULE"
     fi
     
     print_info "Installing/updating dependencies..."
@@ -124,49 +125,58 @@ build_app() {
     get_build_info
     
     # Create ldflags with version information
-    LDFLAGS="-X 'main.version=${BUILD_VERSION}' -X 'main.commit=${BUILD_COMMIT}' -X 'main.date=${BUILD_DATE}'"
+    LDFLAGS="-X '${MODULE}/internal/version.Version=${BUILD_VERSION}' -X '${MODULE}/internal/version.Commit=${BUILD_COMMIT}' -X '${MODULE}/internal/version.Date=${BUILD_DATE}'"
     
     case "$build_type" in
         "static")
-            print_step "Building static binary..."
-            print_info "Version: $BUILD_VERSION, Commit: $BUILD_COMMIT"
-            CGO_ENABLED=0 go build -ldflags="-w -s $LDFLAGS" -o "$BINARY_NAME" "$SOURCE_FILE"
+            print_step "Building static binaries..."
             ;;
         "debug")
-            print_step "Building debug binary..."
-            print_info "Version: $BUILD_VERSION, Commit: $BUILD_COMMIT"
-            go build -gcflags="-N -l" -ldflags="$LDFLAGS" -o "$BINARY_NAME" "$SOURCE_FILE"
+            print_step "Building debug binaries..."
             ;;
         *)
-            print_step "Building standard binary..."
-            print_info "Version: $BUILD_VERSION, Commit: $BUILD_COMMIT"
-            go build -ldflags="$LDFLAGS" -o "$BINARY_NAME" "$SOURCE_FILE"
+            print_step "Building standard binaries..."
             ;;
     esac
+    print_info "Version: $BUILD_VERSION, Commit: $BUILD_COMMIT"
     
-    if [ -f "$BINARY_NAME" ]; then
-        print_success "Build complete: ./$BINARY_NAME"
+    for binary in $BINARIES; do
+        case "$build_type" in
+            "static")
+                CGO_ENABLED=0 go build -ldflags="-w -s $LDFLAGS" -o "$BUILD_DIR/$binary" "./cmd/$binary"
+                ;;
+            "debug")
+                go build -gcflags="-N -l" -ldflags="$LDFLAGS" -o "$BUILD_DIR/$binary" "./cmd/$binary"
+                ;;
+            *)
+                go build -ldflags="$LDFLAGS" -o "$BUILD_DIR/$binary" "./cmd/$binary"
+                ;;
+        esac
         
-        # Show file size
-        if command_exists ls; then
-            SIZE=$(ls -lh "$BINARY_NAME" | awk '{print $5}')
-            print_info "Binary size: $SIZE"
+        if [ -f "$BUILD_DIR/$binary" ]; then
+            print_success "Build complete: $BUILD_DIR/$binary"
+            
+            # Show file size
+            if command_exists ls; then
+                SIZE=$(ls -lh "$BUILD_DIR/$binary" | awk '{print $5}')
+                print_info "Binary size: $SIZE"
+            fi
+            
+            # Make executable (just in case)
+            chmod +x "$BUILD_DIR/$binary"
+        else
+            print_error "Build failed - $binary not created"
+            exit 1
         fi
-        
-        # Make executable (just in case)
-        chmod +x "$BINARY_NAME"
-    else
-        print_error "Build failed - binary not created"
-        exit 1
-    fi
+    done
 }
 
 # Function to run the application
 run_app() {
-    if [ -f "$BINARY_NAME" ]; then
+    if [ -f "$BUILD_DIR/$BINARY_NAME" ]; then
         print_step "Starting CPU Monitor..."
         echo
-        ./"$BINARY_NAME"
+        "$BUILD_DIR/$BINARY_NAME"
     else
         print_error "Binary not found. Run build first."
         exit 1
@@ -177,10 +187,15 @@ run_app() {
 clean_build() {
     print_step "Cleaning build artifacts..."
     
-    if [ -f "$BINARY_NAME" ]; then
-        rm "$BINARY_NAME"
-        print_success "Removed $BINARY_NAME"
-    else
+    local removed=0
+    for binary in $BINARIES; do
+        if [ -f "$BUILD_DIR/$binary" ]; then
+            rm "$BUILD_DIR/$binary"
+            print_success "Removed $binary"
+            removed=1
+        fi
+    done
+    if [ "$removed" -eq 0 ]; then
         print_info "No build artifacts to clean"
     fi
 }
@@ -217,20 +232,24 @@ install_system() {
     
     if [ "$EUID" -eq 0 ]; then
         # Running as root
-        cp "$BINARY_NAME" /usr/local/bin/
-        print_success "Installed to /usr/local/bin/$BINARY_NAME"
+        for binary in $BINARIES; do
+            cp "$BUILD_DIR/$binary" /usr/local/bin/
+            print_success "Installed to /usr/local/bin/$binary"
+        done
     else
         # Not root, use sudo
         if command_exists sudo; then
-            sudo cp "$BINARY_NAME" /usr/local/bin/
-            print_success "Installed to /usr/local/bin/$BINARY_NAME"
+            for binary in $BINARIES; do
+                sudo cp "$BUILD_DIR/$binary" /usr/local/bin/
+                print_success "Installed to /usr/local/bin/$binary"
+            done
         else
             print_error "sudo not available. Run as root or install sudo."
             exit 1
         fi
     fi
     
-    print_info "You can now run 'cpu_monitor' from anywhere"
+    print_info "You can now run 'kkperf' from anywhere"
 }
 
 # Main script logic
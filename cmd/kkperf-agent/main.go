@@ -0,0 +1,11 @@
+// Command kkperf-agent collects CPU and thermal samples without a terminal
+// UI and exports them to Prometheus, Telegraf, Zabbix, MQTT, SNMP, and the
+// history store.
+package main
+
+import "github.com/jeremycharlesgillespie/kode_kronical_perf_monitor/internal/monitor"
+
+// main runs the headless collector.
+func main() {
+	monitor.AgentMain()
+}
@@ -0,0 +1,10 @@
+// Command kkperf-report prints daily and weekly summaries of the kkperf
+// history store.
+package main
+
+import "github.com/jeremycharlesgillespie/kode_kronical_perf_monitor/internal/monitor"
+
+// main generates the report.
+func main() {
+	monitor.ReportMain()
+}
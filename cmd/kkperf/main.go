@@ -0,0 +1,9 @@
+// Command kkperf is the Kode Kronical Perf Monitor terminal UI.
+package main
+
+import "github.com/jeremycharlesgillespie/kode_kronical_perf_monitor/internal/monitor"
+
+// main starts the terminal UI or runs a subcommand.
+func main() {
+	monitor.Main()
+}
@@ -1,7 +1,7 @@
-module cpu_monitor
+module github.com/jeremycharlesgillespie/kode_kronical_perf_monitor
 
 go 1.19
 
 require golang.org/x/term v0.23.0
 
-require golang.org/x/sys v0.23.0 // indirect
+require golang.org/x/sys v0.23.0
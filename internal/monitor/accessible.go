@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// say prints a plain-text line for screen readers. Lines are never colored
+// or redrawn, so each one is announced exactly once.
+func (m *Monitor) say(format string, args ...interface{}) {
+	fmt.Fprintf(m.out, format+"\r\n", args...)
+}
+
+// announce prints a periodic status line in accessible mode, such as
+// "CPU 42 percent, temperature 61 degrees, rising". The trend compares the
+// temperature against the previous announcement.
+func (m *Monitor) announce(now time.Time, totalUsage, temp float64) {
+	if now.Sub(m.lastAnnounce) < m.cfg.AnnounceInterval {
+		return
+	}
+	m.lastAnnounce = now
+
+	if temp <= 0 {
+		m.say(tr("CPU %s percent, temperature unavailable"), formatNumber(totalUsage, 0))
+		return
+	}
+
+	trend := tr("steady")
+	if m.lastAnnouncedTemp > 0 {
+		switch delta := temp - m.lastAnnouncedTemp; {
+		case delta >= 1:
+			trend = tr("rising")
+		case delta <= -1:
+			trend = tr("falling")
+		}
+	}
+	m.lastAnnouncedTemp = temp
+
+	m.say(tr("CPU %s percent, temperature %s degrees, %s"),
+		formatNumber(totalUsage, 0), formatNumber(convertTemp(temp), 0), trend)
+}
+
+// announceStress reports the stress test state after it has been toggled.
+func (m *Monitor) announceStress() {
+	switch {
+	case !m.stressAvailable:
+		m.say(tr("Stress test not available"))
+	case m.stressRunning:
+		m.say(tr("Stress test on"))
+	case m.safetyCooldown() > 0:
+		m.say(tr("Stress is locked for another %s after the safety stop"), m.safetyCooldown().Round(time.Second))
+	default:
+		m.say(tr("Stress test off"))
+	}
+}
+
+// announceHelp lists the controls as a single sentence instead of
+// drawing the help page.
+func (m *Monitor) announceHelp() {
+	keys := m.cfg.KeyBindings
+	m.say(tr("Keys: %s toggles the stress test, %s repeats this help, %s quits."),
+		strings.ToLower(keys.label(actionStress)), keys.label(actionHelp), keys.label(actionQuit))
+}
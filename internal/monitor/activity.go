@@ -0,0 +1,197 @@
+package monitor
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// activitySample holds subsystem utilization percentages (0-100%).
+// A value of -1 means the subsystem could not be measured.
+type activitySample struct {
+	gpu  float64 // Busiest GPU, from amdgpu's gpu_busy_percent
+	disk float64 // Busiest whole disk, from /proc/diskstats io_ticks
+	net  float64 // Combined network throughput relative to link capacity
+}
+
+// activitySampler turns the cumulative disk and network counters from
+// /proc into utilization percentages by diffing successive readings.
+type activitySampler struct {
+	capacityMbps float64           // Fallback link capacity when sysfs reports none
+	lastTime     time.Time         // When the counters below were read
+	lastIOTicks  map[string]uint64 // Milliseconds spent doing I/O, per disk
+	lastNetBytes uint64            // Total rx+tx bytes over all interfaces
+	last         activitySample    // Most recent result, for availability checks
+}
+
+// newActivitySampler creates a sampler and takes the initial counter readings.
+func newActivitySampler(capacityMbps float64) *activitySampler {
+	a := &activitySampler{capacityMbps: capacityMbps}
+	a.lastTime = time.Now()
+	a.lastIOTicks = readDiskIOTicks()
+	a.lastNetBytes = readNetBytes()
+	a.last = activitySample{gpu: readGPUBusy(), disk: -1, net: -1}
+	if len(a.lastIOTicks) > 0 {
+		a.last.disk = 0
+	}
+	if linkCapacityMbps(capacityMbps) > 0 {
+		a.last.net = 0
+	}
+	return a
+}
+
+// sample reads the current counters and returns utilization since the
+// previous call.
+func (a *activitySampler) sample() activitySample {
+	now := time.Now()
+	elapsed := now.Sub(a.lastTime)
+	a.lastTime = now
+
+	result := activitySample{gpu: readGPUBusy(), disk: -1, net: -1}
+	if elapsed <= 0 {
+		return result
+	}
+
+	// Disk: the busiest device, like iostat's %util
+	ticks := readDiskIOTicks()
+	if len(ticks) > 0 {
+		result.disk = 0
+		for dev, t := range ticks {
+			if prev, ok := a.lastIOTicks[dev]; ok && t >= prev {
+				// Fractional milliseconds: polls under 1ms apart would divide by 0
+				util := float64(t-prev) / (float64(elapsed) / float64(time.Millisecond)) * 100
+				if util > result.disk {
+					result.disk = util
+				}
+			}
+		}
+		if result.disk > 100 {
+			result.disk = 100
+		}
+	}
+	a.lastIOTicks = ticks
+
+	// Network: bits per second over the summed link speed
+	bytes := readNetBytes()
+	if capacity := linkCapacityMbps(a.capacityMbps); capacity > 0 && bytes >= a.lastNetBytes {
+		bitsPerSec := float64(bytes-a.lastNetBytes) * 8 / elapsed.Seconds()
+		result.net = bitsPerSec / (capacity * 1e6) * 100
+		if result.net > 100 {
+			result.net = 100
+		}
+	}
+	a.lastNetBytes = bytes
+
+	a.last = result
+	return result
+}
+
+// readDiskIOTicks returns the io_ticks counter (ms spent doing I/O) for each
+// whole disk in /proc/diskstats. Partitions and virtual devices are skipped.
+func readDiskIOTicks() map[string]uint64 {
+	ticks := make(map[string]uint64)
+	file, err := os.Open(filepath.Join(procDir, "diskstats"))
+	if err != nil {
+		return ticks
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 13 || !isWholeDisk(fields[2]) {
+			continue
+		}
+		if t, err := strconv.ParseUint(fields[12], 10, 64); err == nil {
+			ticks[fields[2]] = t
+		}
+	}
+	return ticks
+}
+
+// isWholeDisk reports whether a block device name refers to a physical
+// disk rather than a partition, loop, or RAM device.
+func isWholeDisk(name string) bool {
+	if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") || strings.HasPrefix(name, "zram") {
+		return false
+	}
+	// Whole disks have a directory in /sys/block; partitions do not
+	_, err := os.Stat(filepath.Join(sysDir, "block", name))
+	return err == nil
+}
+
+// readNetBytes returns the total received plus transmitted bytes of all
+// network interfaces except loopback, from /proc/net/dev.
+func readNetBytes() uint64 {
+	file, err := os.Open(filepath.Join(procDir, "net", "dev"))
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	var total uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		colon := strings.Index(line, ":")
+		if colon < 0 || strings.TrimSpace(line[:colon]) == "lo" {
+			continue
+		}
+		fields := strings.Fields(line[colon+1:])
+		if len(fields) < 9 {
+			continue
+		}
+		rx, _ := strconv.ParseUint(fields[0], 10, 64)
+		tx, _ := strconv.ParseUint(fields[8], 10, 64)
+		total += rx + tx
+	}
+	return total
+}
+
+// linkCapacityMbps sums the reported speed of all interfaces that are up.
+// Virtual interfaces report no speed, in which case fallback is used.
+func linkCapacityMbps(fallback float64) float64 {
+	dirs, _ := filepath.Glob(filepath.Join(sysDir, "class", "net", "*"))
+	total := 0.0
+	for _, dir := range dirs {
+		if filepath.Base(dir) == "lo" {
+			continue
+		}
+		state, err := ioutil.ReadFile(filepath.Join(dir, "operstate"))
+		if err != nil || strings.TrimSpace(string(state)) != "up" {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, "speed"))
+		if err != nil {
+			continue
+		}
+		if speed, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64); err == nil && speed > 0 {
+			total += speed
+		}
+	}
+	if total == 0 {
+		return fallback
+	}
+	return total
+}
+
+// readGPUBusy returns the busiest GPU's utilization from the amdgpu
+// gpu_busy_percent attribute, or -1 when no GPU exposes it.
+func readGPUBusy() float64 {
+	paths, _ := filepath.Glob(filepath.Join(sysDir, "class", "drm", "card*", "device", "gpu_busy_percent"))
+	busy := -1.0
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if v, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64); err == nil && v > busy {
+			busy = v
+		}
+	}
+	return busy
+}
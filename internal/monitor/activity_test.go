@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestActivityShortPoll checks that disk utilization stays a percentage
+// when two samples are less than a millisecond apart, and that partial
+// milliseconds count toward the interval.
+func TestActivityShortPoll(t *testing.T) {
+	dir := t.TempDir()
+	savedProc, savedSys := procDir, sysDir
+	t.Cleanup(func() { procDir, sysDir = savedProc, savedSys })
+	procDir, sysDir = filepath.Join(dir, "proc"), filepath.Join(dir, "sys")
+	os.MkdirAll(procDir, 0755)
+	os.MkdirAll(filepath.Join(sysDir, "block", "sda"), 0755)
+	diskstats := func(ticks string) {
+		line := "   8       0 sda 100 0 800 50 100 0 800 50 0 " + ticks + " 100 0 0 0 0\n"
+		ioutil.WriteFile(filepath.Join(procDir, "diskstats"), []byte(line), 0644)
+	}
+	diskstats("1000")
+	a := newActivitySampler(0)
+
+	for _, ticks := range []string{"1000", "1001"} {
+		diskstats(ticks)
+		a.lastTime = time.Now().Add(-100 * time.Microsecond)
+		if disk := a.sample().disk; math.IsNaN(disk) || disk < 0 || disk > 100 {
+			t.Errorf("io_ticks %s after 100µs: disk %.1f%%; want 0-100%%", ticks, disk)
+		}
+	}
+
+	// 1ms busy in 1.5ms is two thirds, not all of a truncated 1ms
+	diskstats("1002")
+	a.lastTime = time.Now().Add(-1500 * time.Microsecond)
+	if disk := a.sample().disk; disk <= 0 || disk > 67 {
+		t.Errorf("1ms of I/O after 1.5ms: disk %.1f%%; want about 67%%", disk)
+	}
+}
@@ -0,0 +1,254 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// alertFlash is how long the alert banner stays in each of its two styles.
+const alertFlash = 500 * time.Millisecond
+
+// webhookTimeout bounds a webhook request, so a dead endpoint does not
+// pile up requests.
+const webhookTimeout = 10 * time.Second
+
+// alertEvent is a raised or cleared alert as the webhook receives it. Text
+// is a one-line summary, which Slack and Mattermost incoming webhooks show
+// as the message.
+type alertEvent struct {
+	Event  string    `json:"event"` // "raised" or "cleared"
+	Rule   string    `json:"rule"`
+	Metric string    `json:"metric"`
+	Value  float64   `json:"value"` // In the unit of Unit
+	Unit   string    `json:"unit"`
+	Host   string    `json:"host"`
+	Time   time.Time `json:"time"`
+	Text   string    `json:"text"`
+}
+
+// alertRule is one [[alerts.rule]] of the config: a condition on a metric
+// that raises an alert once it has held for a while.
+type alertRule struct {
+	When string        `toml:"when"` // "METRIC > VALUE" or "METRIC < VALUE", e.g. "temp > 90"
+	For  time.Duration `toml:"for"`  // How long the condition must hold before the alert is raised
+
+	metric string  // Parsed form of When
+	below  bool    // Raised below the limit rather than above it
+	limit  float64 // In the metric's export unit, e.g. °C or MB/s
+}
+
+// parse fills in the parsed form of the condition.
+func (r *alertRule) parse() error {
+	fields := strings.Fields(r.When)
+	if len(fields) != 3 || (fields[1] != ">" && fields[1] != "<") {
+		return fmt.Errorf("must be \"METRIC > VALUE\" or \"METRIC < VALUE\"")
+	}
+	if _, ok := metricUnits[fields[0]]; !ok {
+		names := make([]string, 0, len(metricUnits))
+		for name := range metricUnits {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown metric %q (expected %s)", fields[0], strings.Join(names, ", "))
+	}
+	limit, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return fmt.Errorf("invalid value %q", fields[2])
+	}
+	r.metric, r.below, r.limit = fields[0], fields[1] == "<", limit
+	return nil
+}
+
+// String describes the rule as it is logged and exported, e.g.
+// "temp > 90 for 10s".
+func (r *alertRule) String() string {
+	s := strings.Join(strings.Fields(r.When), " ")
+	if r.For > 0 {
+		s += " for " + r.For.String()
+	}
+	return s
+}
+
+// holds reports whether the condition holds for a sample. A metric the
+// machine does not provide never raises the alert.
+func (r *alertRule) holds(s *Sample) bool {
+	v, ok := metricValue(s, r.metric)
+	if !ok {
+		return false
+	}
+	v = metricUnits[r.metric].exported(v)
+	if r.below {
+		return v < r.limit
+	}
+	return v > r.limit
+}
+
+// alertState tracks one rule between polls.
+type alertState struct {
+	since  time.Time // When the condition started to hold; zero while it does not
+	raised bool
+}
+
+// defaultAlertLog returns the alert log path next to the history store.
+func defaultAlertLog() string {
+	dir := defaultHistoryDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(dir), "alerts.log")
+}
+
+// checkAlertRules evaluates the [alerts] rules against a sample and
+// returns the raised ones. Raising and clearing an alert appends a line to
+// the alert log, so the headless agent leaves a record too.
+func (m *Monitor) checkAlertRules(s *Sample) []string {
+	rules := m.cfg.Alerts.Rules
+	if len(rules) == 0 {
+		return nil
+	}
+	if len(m.alertStates) != len(rules) {
+		m.alertStates = make([]alertState, len(rules))
+	}
+	var raised []string
+	for i := range rules {
+		r, st := &rules[i], &m.alertStates[i]
+		v, _ := metricValue(s, r.metric)
+		value := metricUnits[r.metric].export(v, 1) + " " + metricUnits[r.metric].symbol()
+		switch {
+		case !r.holds(s):
+			if st.raised {
+				logInfo("alert cleared", "rule", r.String(), "value", value)
+				appendAlertLog(m.cfg, s.Time, fmt.Sprintf("cleared: %s (%s)", r, value))
+				m.notifyAlert("cleared", r, v, s.Time)
+			}
+			*st = alertState{}
+			continue
+		case st.since.IsZero():
+			st.since = s.Time
+		}
+		if !st.raised && s.Time.Sub(st.since) >= r.For {
+			st.raised = true
+			logWarn("alert raised", "rule", r.String(), "value", value)
+			appendAlertLog(m.cfg, s.Time, fmt.Sprintf("raised: %s (%s)", r, value))
+			m.notifyAlert("raised", r, v, s.Time)
+		}
+		if st.raised {
+			raised = append(raised, r.String())
+		}
+	}
+	return raised
+}
+
+// appendAlertLog appends a timestamped line to the alert log. Errors are
+// ignored, as they are for the safety log.
+func appendAlertLog(cfg *Config, now time.Time, event string) {
+	path := cfg.Alerts.Log
+	if path == "" {
+		path = defaultAlertLog()
+	}
+	if path == "" {
+		return
+	}
+	os.MkdirAll(filepath.Dir(path), 0755)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s %s\n", now.Format(time.RFC3339), event)
+}
+
+// notifyAlert POSTs a raised or cleared alert to the [alerts] webhook and
+// runs the [alerts] command, both in the background so a slow endpoint
+// or command does not hold up the poll. Failures are logged.
+func (m *Monitor) notifyAlert(event string, r *alertRule, v float64, now time.Time) {
+	c := m.cfg.Alerts
+	if c.Webhook == "" && c.Command == "" {
+		return
+	}
+	u := metricUnits[r.metric]
+	host, _ := os.Hostname()
+	e := alertEvent{Event: event, Rule: r.String(), Metric: r.metric, Value: roundTo(u.exported(v), 1), Unit: u.symbol(), Host: host, Time: now}
+	e.Text = fmt.Sprintf("kkperf on %s: alert %s: %s (%s %s)", host, event, e.Rule, u.export(v, 1), e.Unit)
+
+	if c.Webhook != "" {
+		data, _ := json.Marshal(e)
+		go func() {
+			client := &http.Client{Timeout: webhookTimeout}
+			resp, err := client.Post(c.Webhook, "application/json", bytes.NewReader(data))
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode >= 300 {
+					err = fmt.Errorf("%s", resp.Status)
+				}
+			}
+			if err != nil {
+				logWarn("alert webhook failed", "url", c.Webhook, "err", err)
+			}
+		}()
+	}
+	if c.Command != "" {
+		cmd := exec.Command(shellName, shellFlag, c.Command)
+		cmd.Env = append(os.Environ(), "KKPERF_ALERT_EVENT="+event, "KKPERF_ALERT_RULE="+e.Rule,
+			"KKPERF_ALERT_METRIC="+r.metric, "KKPERF_ALERT_VALUE="+u.export(v, 1))
+		if err := cmd.Start(); err != nil {
+			logWarn("alert command failed", "command", c.Command, "err", err)
+			return
+		}
+		go cmd.Wait()
+	}
+}
+
+// updateAlerts takes the raised alerts of the latest sample. A new alert
+// rings the terminal bell, or is announced in accessible mode; the screen
+// is cleared whenever the banner gains or loses a line.
+func (m *Monitor) updateAlerts(alerts []string) {
+	if m.headless {
+		return
+	}
+	shown := map[string]bool{}
+	for _, a := range m.alerts {
+		shown[a] = true
+	}
+	changed := len(alerts) != len(m.alerts)
+	for _, a := range alerts {
+		if shown[a] {
+			continue
+		}
+		changed = true
+		if m.cfg.Accessible {
+			m.say(tr("Alert: %s"), a)
+		} else {
+			fmt.Fprint(m.out, "\a")
+		}
+	}
+	m.alerts = alerts
+	if changed && !m.cfg.Accessible {
+		fmt.Fprint(m.out, clearScreen)
+	}
+}
+
+// displayAlerts draws a banner line per raised alert under the status
+// line, flashing between red text and red reverse video.
+func (m *Monitor) displayAlerts() {
+	if len(m.alerts) == 0 {
+		return
+	}
+	style := colorRed
+	if timeNow().UnixNano()/int64(alertFlash)%2 == 0 {
+		style = colorRed + "\033[7m"
+	}
+	for _, a := range m.alerts {
+		fmt.Fprintf(m.out, "%s ⚠ %s %s %s\r\n", style, tr("ALERT:"), a, colorReset)
+	}
+	fmt.Fprint(m.out, "\r\n")
+}
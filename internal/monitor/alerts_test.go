@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAlertRules checks that a rule is raised only once its condition has
+// held for its duration, is logged when raised and cleared, and that an
+// unknown metric is rejected.
+func TestAlertRules(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Alerts.Log = filepath.Join(t.TempDir(), "alerts.log")
+	cfg.Alerts.Rules = []alertRule{{When: "temp > 90", For: 10 * time.Second}, {When: "disk_read > 100"}}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	m := &Monitor{cfg: cfg}
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		after time.Duration
+		temp  float64
+		read  float64 // Bytes per second
+		want  string
+	}{
+		{0, 92, 0, ""},
+		{5 * time.Second, 93, 150e6, "disk_read > 100"},
+		{10 * time.Second, 94, 0, "temp > 90 for 10s"},
+		{12 * time.Second, 85, 0, ""},
+	} {
+		s := &Sample{Time: start.Add(c.after), Temp: c.temp, Disks: []DiskIO{{Device: "sda", ReadBytes: c.read}}}
+		if got := strings.Join(m.checkAlertRules(s), "; "); got != c.want {
+			t.Errorf("after %s: raised %q, want %q", c.after, got, c.want)
+		}
+	}
+
+	data, err := ioutil.ReadFile(cfg.Alerts.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		events = append(events, line[strings.Index(line, " ")+1:])
+	}
+	want := []string{"raised: disk_read > 100 (150.0 MB/s)", "raised: temp > 90 for 10s (94.0 °C)", "cleared: disk_read > 100 (0.0 MB/s)", "cleared: temp > 90 for 10s (85.0 °C)"}
+	if strings.Join(events, "|") != strings.Join(want, "|") {
+		t.Errorf("log %q, want %q", events, want)
+	}
+
+	cfg.Alerts.Rules = []alertRule{{When: "fans > 3000"}}
+	if err := cfg.validate(); err == nil {
+		t.Error("unknown metric accepted")
+	}
+}
+
+// TestAlertNotify checks that a raised alert is POSTed to the webhook as
+// JSON and runs the command with the alert in its environment.
+func TestAlertNotify(t *testing.T) {
+	events := make(chan alertEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e alertEvent
+		json.NewDecoder(r.Body).Decode(&e)
+		events <- e
+	}))
+	defer server.Close()
+
+	out := filepath.Join(t.TempDir(), "alert")
+	cfg := defaultConfig()
+	cfg.Alerts.Log = filepath.Join(t.TempDir(), "alerts.log")
+	cfg.Alerts.Webhook = server.URL
+	cfg.Alerts.Command = `echo "$KKPERF_ALERT_EVENT $KKPERF_ALERT_RULE $KKPERF_ALERT_VALUE" > ` + out
+	cfg.Alerts.Rules = []alertRule{{When: "cpu > 90"}}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	m := &Monitor{cfg: cfg}
+	m.checkAlertRules(&Sample{Time: time.Now(), CPU: 97.34})
+
+	select {
+	case e := <-events:
+		if e.Event != "raised" || e.Rule != "cpu > 90" || e.Value != 97.3 || e.Unit != "%" || !strings.Contains(e.Text, "alert raised: cpu > 90 (97.3 %)") {
+			t.Errorf("webhook got %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not called")
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		data, _ := ioutil.ReadFile(out)
+		if got := strings.TrimSpace(string(data)); got == "raised cpu > 90 97.3" {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("command wrote %q", got)
+		}
+	}
+
+	cfg.Alerts.Webhook = "slack.example.com/hook"
+	if err := cfg.validate(); err == nil {
+		t.Error("webhook without a scheme accepted")
+	}
+}
@@ -0,0 +1,177 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ambientTimeout bounds one read of the ambient source, so a hung
+// endpoint or script cannot pile up readers.
+const ambientTimeout = 10 * time.Second
+
+// defaultAmbientPattern takes the first number in the source's output.
+var defaultAmbientPattern = regexp.MustCompile(`-?[0-9]+(?:\.[0-9]+)?`)
+
+// ambientSource reads the room temperature from an external source in
+// the background: a file, an HTTP endpoint or a command, as configured in
+// [ambient]. The cooler's quality is the package temperature over
+// ambient, which does not change with the weather.
+type ambientSource struct {
+	cfg     *Config
+	pattern *regexp.Regexp
+
+	mu      sync.Mutex
+	celsius float64
+	read    time.Time // When celsius was read; zero until the first good reading
+	err     error     // Error of the last read, nil after a good one
+}
+
+// newAmbientSource starts reading the configured source every interval.
+// It returns nil when no source is configured.
+func newAmbientSource(cfg *Config) *ambientSource {
+	a := cfg.Ambient
+	if a.File == "" && a.URL == "" && a.Command == "" {
+		return nil
+	}
+	s := &ambientSource{cfg: cfg, pattern: defaultAmbientPattern}
+	if a.Pattern != "" {
+		s.pattern = regexp.MustCompile(a.Pattern) // Checked by validate
+	}
+	go s.run()
+	return s
+}
+
+// run reads the source until the process exits.
+func (s *ambientSource) run() {
+	for {
+		celsius, err := s.fetch()
+		s.mu.Lock()
+		s.err = err
+		if err == nil {
+			s.celsius, s.read = celsius, time.Now()
+		}
+		s.mu.Unlock()
+		time.Sleep(s.cfg.Ambient.Interval)
+	}
+}
+
+// fetch reads the source once and converts the value to °C.
+func (s *ambientSource) fetch() (float64, error) {
+	a := s.cfg.Ambient
+	var data []byte
+	var err error
+	switch {
+	case a.File != "":
+		data, err = ioutil.ReadFile(a.File)
+	case a.URL != "":
+		client := http.Client{Timeout: ambientTimeout}
+		var resp *http.Response
+		resp, err = client.Get(a.URL)
+		if err == nil {
+			data, err = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err == nil && resp.StatusCode != http.StatusOK {
+				err = fmt.Errorf("%s: %s", a.URL, resp.Status)
+			}
+		}
+	default:
+		ctx, cancel := context.WithTimeout(context.Background(), ambientTimeout)
+		data, err = exec.CommandContext(ctx, shellName, shellFlag, a.Command).Output()
+		cancel()
+	}
+	if err != nil {
+		return 0, err
+	}
+	return parseAmbient(string(data), s.pattern, a.Unit)
+}
+
+// parseAmbient extracts the temperature from a source's output with
+// pattern, using its first group when it has one, and converts it from
+// unit ("C", "F", or "mC") to °C.
+func parseAmbient(text string, pattern *regexp.Regexp, unit string) (float64, error) {
+	match := pattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, fmt.Errorf("no temperature in %q", truncate(text, 40))
+	}
+	value := match[0]
+	if len(match) > 1 {
+		value = match[1]
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid temperature %q", value)
+	}
+	switch unit {
+	case "F":
+		v = (v - 32) * 5 / 9
+	case "mC":
+		v /= 1000
+	}
+	return v, nil
+}
+
+// truncate shortens s to at most n bytes for error messages.
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n] + "…"
+	}
+	return s
+}
+
+// reading returns the latest ambient temperature in °C. ok is false
+// before the first good reading and once readings are older than three
+// intervals, so a dead sensor does not leave a stale value on screen.
+func (s *ambientSource) reading() (celsius float64, ok bool) {
+	if s == nil {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.read.IsZero() || time.Since(s.read) > 3*s.cfg.Ambient.Interval {
+		return 0, false
+	}
+	return s.celsius, true
+}
+
+// lastErr returns the error of the last read, or nil when it succeeded.
+func (s *ambientSource) lastErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// displayAmbient prints the ambient temperature and the package
+// temperature over it on one line, when a source is configured.
+func (m *Monitor) displayAmbient(currentTemp float64) {
+	if m.ambient == nil {
+		return
+	}
+	ambient, ok := m.ambient.reading()
+	if !ok {
+		fmt.Fprintf(m.out, "%s%s%s --", colorBlue, tr("Ambient:"), colorReset)
+		if err := m.ambient.lastErr(); err != nil {
+			fmt.Fprintf(m.out, "  %s%s%s", colorDarkYellow, truncate(err.Error(), 60), colorReset)
+		}
+		fmt.Fprint(m.out, "\r\n\r\n")
+		return
+	}
+	fmt.Fprintf(m.out, "%s%s%s %s", colorBlue, tr("Ambient:"), colorReset, formatTemp(ambient, 1))
+	if currentTemp > 0 {
+		delta := currentTemp - ambient
+		sign := ""
+		if delta > 0 {
+			sign = "+"
+		}
+		// Colored as the temperature the package would reach in a 25°C room
+		fmt.Fprintf(m.out, "  %s%s%s %s%s%s%s", colorBlue, tr("Δ over ambient:"), colorReset,
+			getTempColor(delta+25), sign, formatTempDelta(delta, 1), colorReset)
+	}
+	fmt.Fprint(m.out, "\r\n\r\n")
+}
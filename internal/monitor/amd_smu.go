@@ -0,0 +1,206 @@
+package monitor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// smuDir is where the ryzen_smu module exposes the SMU power management table.
+var smuDir = "/sys/kernel/ryzen_smu_drv"
+
+// AMD RAPL registers used for per-core power
+const (
+	msrAMDPowerUnit  = 0xC0010299 // Bits 12:8 hold the energy status unit
+	msrAMDCoreEnergy = 0xC001029A // Per-core 32-bit energy counter
+)
+
+// smuSampler reads AMD power telemetry: PPT/TDC/EDC limit usage from the
+// ryzen_smu power management table, core and SoC power from zenpower, and
+// per-core power from the core energy MSRs. Each source is optional.
+type smuSampler struct {
+	available bool   // Whether any source is present
+	pmTable   bool   // Whether ryzen_smu's pm_table is readable
+	zenpower  string // zenpower hwmon directory, "" if not loaded
+
+	energyUnit float64 // Joules per energy count, 0 when the MSRs are unreadable
+	lastEnergy []uint32
+	lastTime   time.Time
+
+	// Latest readings; limits are 0 when unknown
+	ppt, pptLimit float64 // Package power tracking in W
+	tdc, tdcLimit float64 // Thermal design current in A
+	edc, edcLimit float64 // Electrical design current in A
+	corePower     float64 // SVI2 core rail power in W
+	socPower      float64 // SVI2 SoC rail power in W
+	cpuPower      []float64
+}
+
+// newSMUSampler probes the telemetry sources of an AMD system with the
+// given number of CPUs.
+func newSMUSampler(cores int) *smuSampler {
+	s := &smuSampler{}
+	if !strings.Contains(cpuVendor(), "AMD") {
+		return s
+	}
+	if _, ok := readPMTable(); ok {
+		s.pmTable = true
+	}
+	s.zenpower = findHwmonChip("zenpower")
+	if unit, ok := readMSR(0, msrAMDPowerUnit); ok {
+		s.energyUnit = 1 / math.Pow(2, float64((unit>>8)&0x1f))
+		s.lastEnergy = make([]uint32, cores)
+		s.cpuPower = make([]float64, cores)
+		s.readCoreEnergy(s.lastEnergy)
+		s.lastTime = time.Now()
+	}
+	s.available = s.pmTable || s.zenpower != "" || s.energyUnit > 0
+	return s
+}
+
+// sample refreshes the readings from every available source.
+func (s *smuSampler) sample() {
+	if !s.available {
+		return
+	}
+	if s.pmTable {
+		if table, ok := readPMTable(); ok {
+			// The first ten entries are the same on Zen 2 and Zen 3
+			// desktop tables: PPT, TDC, THM, FIT and EDC limit/value pairs
+			s.pptLimit, s.ppt = table[0], table[1]
+			s.tdcLimit, s.tdc = table[2], table[3]
+			s.edcLimit, s.edc = table[8], table[9]
+		}
+	}
+	if s.zenpower != "" {
+		s.corePower = readHwmonPower(s.zenpower, "SVI2_P_Core")
+		s.socPower = readHwmonPower(s.zenpower, "SVI2_P_SoC")
+	}
+	if s.energyUnit > 0 {
+		now := time.Now()
+		energy := make([]uint32, len(s.lastEnergy))
+		s.readCoreEnergy(energy)
+		if dt := now.Sub(s.lastTime).Seconds(); dt > 0 {
+			for i := range energy {
+				// Unsigned subtraction handles the 32-bit wraparound
+				s.cpuPower[i] = float64(energy[i]-s.lastEnergy[i]) * s.energyUnit / dt
+			}
+		}
+		s.lastEnergy, s.lastTime = energy, now
+	}
+}
+
+// readCoreEnergy fills energy with each CPU's core energy counter.
+func (s *smuSampler) readCoreEnergy(energy []uint32) {
+	values, ok := readMSRs(len(energy), msrAMDCoreEnergy)
+	for i := range energy {
+		if ok[i] {
+			energy[i] = uint32(values[i])
+		}
+	}
+}
+
+// hasLimits reports whether PPT/TDC/EDC limit usage is known.
+func (s *smuSampler) hasLimits() bool {
+	return s.pptLimit > 0 || s.tdcLimit > 0 || s.edcLimit > 0
+}
+
+// readPMTable reads the first entries of the ryzen_smu power management
+// table as float32 values.
+func readPMTable() ([]float64, bool) {
+	data, err := readPrivileged(filepath.Join(smuDir, "pm_table"))
+	if err != nil || len(data) < 40 {
+		return nil, false
+	}
+	table := make([]float64, 10)
+	for i := range table {
+		table[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:])))
+	}
+	return table, true
+}
+
+// findHwmonChip returns the hwmon directory of the named chip, or "".
+func findHwmonChip(name string) string {
+	chips, _ := filepath.Glob(filepath.Join(hwmonDir, "hwmon*"))
+	for _, dir := range chips {
+		if readSysfsString(filepath.Join(dir, "name")) == name {
+			return dir
+		}
+	}
+	return ""
+}
+
+// readHwmonPower reads the power channel with the given label in W.
+func readHwmonPower(dir, label string) float64 {
+	labels, _ := filepath.Glob(filepath.Join(dir, "power*_label"))
+	for _, path := range labels {
+		if readSysfsString(path) != label {
+			continue
+		}
+		// hwmon reports microwatts
+		return readKHz(strings.TrimSuffix(path, "_label")+"_input") / 1e6
+	}
+	return 0
+}
+
+// limitBar draws a usage bar for a limit, colored green below 70%,
+// yellow below 90% and red above.
+func limitBar(percent float64, width int) string {
+	filled := int(percent/100*float64(width) + 0.5)
+	if filled > width {
+		filled = width
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	color := colorGreen
+	if percent >= 90 {
+		color = colorRed
+	} else if percent >= 70 {
+		color = colorYellow
+	}
+	return color + strings.Repeat("█", filled) + colorReset + strings.Repeat("░", width-filled)
+}
+
+// displaySMULimits prints PPT/TDC/EDC limit usage bars and rail power
+// below the status line while the stress test runs.
+func (m *Monitor) displaySMULimits() {
+	s := m.smu
+	var parts []string
+	limit := func(name string, value, max float64, unit string) {
+		if max <= 0 {
+			return
+		}
+		percent := value / max * 100
+		parts = append(parts, fmt.Sprintf("%s%s%s %s %3.0f%% %.0f/%.0f %s",
+			colorBlue, name, colorReset, limitBar(percent, 10), percent, value, max, unit))
+	}
+	limit("PPT", s.ppt, s.pptLimit, "W")
+	limit("TDC", s.tdc, s.tdcLimit, "A")
+	limit("EDC", s.edc, s.edcLimit, "A")
+	if len(parts) > 0 {
+		fmt.Fprintf(m.out, "%s\r\n", strings.Join(parts, "  "))
+	}
+
+	var power []string
+	if s.corePower > 0 {
+		power = append(power, tr("Core:")+" "+unitWatts.format(s.corePower, 1))
+	}
+	if s.socPower > 0 {
+		power = append(power, tr("SoC:")+" "+unitWatts.format(s.socPower, 1))
+	}
+	if len(power) > 0 {
+		fmt.Fprintf(m.out, "%s %s\r\n", tr("Power:"), strings.Join(power, "  "))
+	}
+	if len(parts) > 0 || len(power) > 0 {
+		fmt.Fprint(m.out, "\r\n")
+	}
+}
+
+// showSMULimits reports whether the limit bars are part of the main view.
+func (m *Monitor) showSMULimits() bool {
+	return m.stressRunning && (m.smu.hasLimits() || m.smu.corePower > 0 || m.smu.socPower > 0)
+}
@@ -0,0 +1,306 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	clockTicks        = 100 // USER_HZ, the unit of /proc/<pid>/stat times on every Linux architecture
+	attributionRows   = 8   // Rows per table
+	attributionColumn = 28  // Width of the name column
+)
+
+// passwdFile maps UIDs to user names. Tests point it at a fixture.
+var passwdFile = "/etc/passwd"
+
+// Priority classes of processes, from the scheduling policy and nice
+// value in /proc/<pid>/stat.
+const (
+	classRealtime = "Real-time (FIFO/RR/deadline)"
+	classRaised   = "Raised (nice < 0)"
+	classNormal   = "Normal (nice 0)"
+	classLow      = "Low (nice > 0)"
+	classIdle     = "Batch/idle policy"
+)
+
+// priorityClasses lists the classes from most to least urgent.
+var priorityClasses = []string{classRealtime, classRaised, classNormal, classLow, classIdle}
+
+// cpuShare is the CPU time of one user, cgroup or priority class.
+type cpuShare struct {
+	name  string
+	cpu   float64 // Share of all cores (0-100%)
+	procs int     // Processes that used CPU time
+}
+
+// procTimes is what the attribution page needs to know about a process.
+type procTimes struct {
+	jiffies uint64 // utime + stime
+	uid     int
+	cgroup  string // Top-level cgroup, e.g. "user.slice"
+	class   string // One of priorityClasses
+}
+
+// attributionSampler sums per-process CPU time by user, by top-level
+// cgroup, which for systemd machines is the slice: system.slice for
+// services, user.slice for logins, machine.slice for VMs and containers,
+// and by priority class, which sets background batch work apart from
+// interactive load.
+type attributionSampler struct {
+	cores    int
+	procs    map[int]procTimes // Previous reading by PID
+	users    []cpuShare        // Busiest first
+	groups   []cpuShare
+	classes  []cpuShare     // In priorityClasses order
+	names    map[int]string // User names by UID
+	lastTime time.Time
+}
+
+// newAttributionSampler takes the first reading.
+func newAttributionSampler(cores int) *attributionSampler {
+	a := &attributionSampler{cores: cores, names: readPasswd()}
+	a.sample()
+	return a
+}
+
+// readPasswd returns the user names in passwdFile by UID.
+func readPasswd() map[int]string {
+	names := map[int]string{}
+	f, err := os.Open(passwdFile)
+	if err != nil {
+		return names
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 {
+			continue
+		}
+		if uid, err := strconv.Atoi(fields[2]); err == nil {
+			if _, seen := names[uid]; !seen {
+				names[uid] = fields[0]
+			}
+		}
+	}
+	return names
+}
+
+// parseProcStat returns the fields of /proc/<pid>/stat after the command
+// name, so that field n of proc(5) is at index n-3. The name is skipped
+// by its closing parenthesis, as it may contain spaces and parentheses.
+func parseProcStat(data string) []string {
+	i := strings.LastIndexByte(data, ')')
+	if i < 0 {
+		return nil
+	}
+	return strings.Fields(data[i+1:])
+}
+
+// priorityClass classifies a process by its scheduling policy and nice
+// value (fields 41 and 19 of /proc/<pid>/stat).
+func priorityClass(policy, nice int) string {
+	switch {
+	case policy == 1 || policy == 2 || policy == 6: // SCHED_FIFO, SCHED_RR, SCHED_DEADLINE
+		return classRealtime
+	case policy == 3 || policy == 5: // SCHED_BATCH, SCHED_IDLE
+		return classIdle
+	case nice < 0:
+		return classRaised
+	case nice > 0:
+		return classLow
+	}
+	return classNormal
+}
+
+// readProcTimes reads the CPU time, owner, cgroup and priority class of
+// a process.
+func readProcTimes(dir string) (procTimes, bool) {
+	var p procTimes
+	stat := parseProcStat(readSysfsString(filepath.Join(dir, "stat")))
+	if len(stat) < 39 {
+		return p, false
+	}
+	utime, _ := strconv.ParseUint(stat[11], 10, 64)
+	stime, _ := strconv.ParseUint(stat[12], 10, 64)
+	p.jiffies = utime + stime
+	nice, _ := strconv.Atoi(stat[16])
+	policy, _ := strconv.Atoi(stat[38])
+	p.class = priorityClass(policy, nice)
+
+	p.uid = -1
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "status")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "Uid:" {
+				p.uid, _ = strconv.Atoi(fields[1])
+				break
+			}
+		}
+	}
+
+	// cgroup v2 has a single "0::/path" line; under v1 the systemd or
+	// cpu hierarchy gives the slice
+	p.cgroup = "/"
+	for _, line := range strings.Split(readSysfsString(filepath.Join(dir, "cgroup")), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) < 3 || !(parts[0] == "0" || parts[1] == "name=systemd" || strings.Contains(parts[1], "cpu")) {
+			continue
+		}
+		top := strings.SplitN(strings.TrimPrefix(parts[2], "/"), "/", 2)[0]
+		if top != "" {
+			p.cgroup = top
+		}
+		break
+	}
+	return p, true
+}
+
+// sample refreshes the CPU shares. Processes that started since the
+// previous reading count with all of their CPU time.
+func (a *attributionSampler) sample() {
+	now := timeNow()
+	dt := now.Sub(a.lastTime).Seconds()
+	first := a.lastTime.IsZero()
+	a.lastTime = now
+
+	procs := map[int]procTimes{}
+	users := map[string]*cpuShare{}
+	groups := map[string]*cpuShare{}
+	classes := map[string]*cpuShare{}
+	entries, _ := ioutil.ReadDir(procDir)
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		p, ok := readProcTimes(filepath.Join(procDir, e.Name()))
+		if !ok {
+			continue
+		}
+		procs[pid] = p
+		prev := a.procs[pid].jiffies
+		if first || dt <= 0 || p.jiffies <= prev {
+			continue
+		}
+		cpu := float64(p.jiffies-prev) / clockTicks / dt / float64(a.cores) * 100
+
+		user, ok := a.names[p.uid]
+		if !ok {
+			user = strconv.Itoa(p.uid)
+		}
+		for _, share := range []struct {
+			m    map[string]*cpuShare
+			name string
+		}{{users, user}, {groups, p.cgroup}, {classes, p.class}} {
+			s := share.m[share.name]
+			if s == nil {
+				s = &cpuShare{name: share.name}
+				share.m[share.name] = s
+			}
+			s.cpu += cpu
+			s.procs++
+		}
+	}
+	a.procs = procs
+	a.users, a.groups = sortedShares(users), sortedShares(groups)
+	a.classes = a.classes[:0]
+	for _, class := range priorityClasses {
+		if s := classes[class]; s != nil {
+			a.classes = append(a.classes, *s)
+		} else {
+			a.classes = append(a.classes, cpuShare{name: class})
+		}
+	}
+}
+
+// sortedShares returns the shares busiest first, by name among equals.
+func sortedShares(shares map[string]*cpuShare) []cpuShare {
+	list := make([]cpuShare, 0, len(shares))
+	for _, s := range shares {
+		list = append(list, *s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].cpu != list[j].cpu {
+			return list[i].cpu > list[j].cpu
+		}
+		return list[i].name < list[j].name
+	})
+	return list
+}
+
+// openAttributionPage starts sampling per-process CPU time and shows the
+// page.
+func (m *Monitor) openAttributionPage() {
+	m.attribution = newAttributionSampler(m.cores)
+	m.showAttribution = true
+	fmt.Fprint(m.out, clearScreen)
+}
+
+// handleAttributionKey processes a key press while the CPU attribution
+// page is shown. It returns false when the application should quit.
+func (m *Monitor) handleAttributionKey(key byte) bool {
+	switch m.pageAction(key, actionAttribution) {
+	case actionStress:
+		if m.stressRunning {
+			m.stopStress()
+		} else {
+			m.startStress()
+		}
+		return true
+	case actionClose:
+		m.showAttribution = false
+		m.attribution = nil
+		fmt.Fprint(m.out, clearScreen)
+		return true
+	case actionQuit:
+		return false
+	}
+	return true
+}
+
+// displayShares prints one table of CPU shares with a bar per row. With
+// translate set the names are UI strings rather than user or cgroup names.
+func (m *Monitor) displayShares(title, column string, shares []cpuShare, translate bool) {
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, title, colorReset)
+	fmt.Fprintf(m.out, "%s%-*s %7s %6s%s\r\n", colorBlue, attributionColumn, column, tr("CPU"), tr("Procs"), colorReset)
+	if len(shares) == 0 {
+		fmt.Fprintf(m.out, "%s\r\n", tr("No CPU time used"))
+	}
+	for i, s := range shares {
+		if i == attributionRows {
+			break
+		}
+		name := s.name
+		if translate {
+			name = tr(name)
+		}
+		if len([]rune(name)) > attributionColumn {
+			name = string([]rune(name)[:attributionColumn-1]) + "…"
+		}
+		bar := int(s.cpu/100*30 + 0.5)
+		fmt.Fprintf(m.out, "%-*s %7s %6d %s%s%s\r\n", attributionColumn, name, formatPercent(s.cpu, 1), s.procs,
+			getUsageColor(s.cpu), strings.Repeat("■", bar), colorReset)
+	}
+	fmt.Fprint(m.out, "\r\n")
+}
+
+// displayAttributionPage draws the CPU time of the last poll summed by
+// user, by top-level cgroup and by priority class, as shares of all
+// cores, so that on a shared machine the user or service slice behind
+// the load stands out, and niced batch jobs from interactive work.
+func (m *Monitor) displayAttributionPage() {
+	fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor - %s ===%s\r\n\r\n", colorGreen, tr("CPU Attribution"), colorReset)
+	a := m.attribution
+	m.displayShares(tr("By user"), tr("User"), a.users, false)
+	m.displayShares(tr("By cgroup"), tr("Cgroup"), a.groups, false)
+	m.displayShares(tr("By priority"), tr("Class"), a.classes, true)
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorYellow, tr("SPACE: stress  U/ESC: close"), colorReset)
+}
@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// baselineCoreDelta is how far above its baseline a core's usage must be,
+// in percentage points, to be listed on the baseline line.
+const baselineCoreDelta = 10
+
+// baselineCoresListed caps the cores the baseline line lists.
+const baselineCoresListed = 3
+
+// idleBaseline is the idle reading the session is compared against.
+type idleBaseline struct {
+	cpu     float64   // Mean total CPU usage (0-100%)
+	temp    float64   // Mean temperature in °C, 0 without a sensor
+	cores   []float64 // Mean usage per core
+	current []float64 // Rolling usage per core of the latest poll
+}
+
+// baselineRun is a baseline measurement in progress.
+type baselineRun struct {
+	until time.Time
+	polls int
+	cpu   float64 // Sums of the polls so far
+	temp  float64
+	tempN int
+	cores []float64
+}
+
+// startBaseline measures a new idle baseline over [baseline] duration.
+// The machine must be idle, so the stress test must be off; the previous
+// baseline stays in use until the new one is measured.
+func (m *Monitor) startBaseline() {
+	if m.stressRunning {
+		if m.cfg.Accessible {
+			m.say(tr("Stop the stress test to measure the idle baseline."))
+		} else {
+			fmt.Fprint(m.out, "\a")
+		}
+		return
+	}
+	m.baselineRun = &baselineRun{until: timeNow().Add(m.cfg.Baseline.Duration), cores: make([]float64, m.cores)}
+	if m.cfg.Accessible {
+		m.say(tr("Measuring the idle baseline for %s."), m.cfg.Baseline.Duration)
+	}
+}
+
+// updateBaseline takes a poll into the baseline being measured, and keeps
+// the rolling per-core usage the deltas are shown for. Starting stress
+// cancels the measurement.
+func (m *Monitor) updateBaseline(s *Sample, cores []float64) {
+	if m.baseline != nil {
+		m.baseline.current = append(m.baseline.current[:0], cores...)
+	}
+	r := m.baselineRun
+	if r == nil {
+		return
+	}
+	if m.stressRunning {
+		m.baselineRun = nil
+		logInfo("idle baseline cancelled by stress")
+		return
+	}
+	r.polls++
+	r.cpu += s.CPU
+	if s.Temp > 0 {
+		r.temp += s.Temp
+		r.tempN++
+	}
+	for i := range r.cores {
+		if i < len(s.Cores) {
+			r.cores[i] += s.Cores[i]
+		}
+	}
+	if timeNow().Before(r.until) {
+		return
+	}
+
+	b := &idleBaseline{cpu: r.cpu / float64(r.polls), cores: r.cores, current: append([]float64(nil), cores...)}
+	for i := range b.cores {
+		b.cores[i] /= float64(r.polls)
+	}
+	if r.tempN > 0 {
+		b.temp = r.temp / float64(r.tempN)
+	}
+	m.baseline, m.baselineRun = b, nil
+	logInfo("idle baseline measured", "cpu", b.cpu, "temp", b.temp)
+	switch {
+	case m.headless:
+	case m.cfg.Accessible:
+		m.say(tr("Idle baseline: CPU %s, temperature %s."), formatPercent(b.cpu, 1), formatTemp(b.temp, 1))
+	default:
+		fmt.Fprint(m.out, clearScreen) // The baseline line appears
+	}
+}
+
+// baselineStatus returns the status line tag of a baseline measurement,
+// with the time it has left.
+func (m *Monitor) baselineStatus() string {
+	if m.baselineRun == nil {
+		return ""
+	}
+	left := m.baselineRun.until.Sub(timeNow())
+	if left < 0 {
+		left = 0
+	}
+	return fmt.Sprintf("  %s[%s %s]%s", colorCyan, tr("BASELINE"), left.Round(time.Second), colorReset)
+}
+
+// formatBaselineDelta formats the difference to the baseline, in yellow
+// above it and in green at or below it.
+func formatBaselineDelta(delta float64, format func(float64, int) string) string {
+	color, sign := colorGreen, ""
+	if delta > 0 {
+		color, sign = colorYellow, "+"
+	}
+	return color + sign + format(delta, 1) + colorReset
+}
+
+// displayBaseline prints how far the readings are above the idle
+// baseline, such as "Above idle baseline: Temp +3.2°C  CPU +4.1%  Cores
+// 3 +22%  7 +15%", listing the cores furthest above theirs.
+func (m *Monitor) displayBaseline(currentTotalUsage, currentTemp float64) {
+	b := m.baseline
+	if b == nil {
+		return
+	}
+	fmt.Fprintf(m.out, "%s%s%s", colorBlue, tr("Above idle baseline:"), colorReset)
+	if b.temp > 0 && currentTemp > 0 {
+		fmt.Fprintf(m.out, " %s %s ", tr("Temp"), formatBaselineDelta(currentTemp-b.temp, formatTempDelta))
+	}
+	fmt.Fprintf(m.out, " %s %s", tr("CPU"), formatBaselineDelta(currentTotalUsage-b.cpu, formatPercent))
+
+	var above []int
+	for i, v := range b.current {
+		if i < len(b.cores) && v-b.cores[i] >= baselineCoreDelta {
+			above = append(above, i)
+		}
+	}
+	sort.SliceStable(above, func(i, j int) bool {
+		return b.current[above[i]]-b.cores[above[i]] > b.current[above[j]]-b.cores[above[j]]
+	})
+	if len(above) > 0 {
+		var cores []string
+		for n, i := range above {
+			if n == baselineCoresListed {
+				cores = append(cores, fmt.Sprintf("+%d", len(above)-baselineCoresListed))
+				break
+			}
+			cores = append(cores, fmt.Sprintf("%d %s", i, formatBaselineDelta(b.current[i]-b.cores[i], formatPercent)))
+		}
+		fmt.Fprintf(m.out, "  %s %s", tr("Cores"), strings.Join(cores, "  "))
+	}
+	fmt.Fprint(m.out, "\033[K\r\n\r\n") // The list of cores shrinks and grows
+}
@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestIdleBaseline checks that the baseline is the mean of the polls over
+// [baseline] duration, that stress cancels it, and that the baseline line
+// lists the cores furthest above theirs.
+func TestIdleBaseline(t *testing.T) {
+	savedTimeNow := timeNow
+	t.Cleanup(func() { timeNow = savedTimeNow })
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	clock := start
+	timeNow = func() time.Time { return clock }
+
+	cfg := defaultConfig()
+	cfg.Baseline.Duration = 2 * time.Second
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	m := &Monitor{cfg: cfg, out: &bytes.Buffer{}, cores: 5}
+	m.startBaseline()
+	for i, cores := range [][]float64{{2, 4, 6, 0, 0}, {4, 6, 8, 0, 2}} {
+		clock = start.Add(time.Duration(i+1) * time.Second)
+		s := &Sample{Time: clock, CPU: 3 + float64(i), Temp: 40 + float64(i), Cores: cores}
+		m.updateBaseline(s, cores)
+	}
+	if m.baseline == nil || m.baselineRun != nil {
+		t.Fatal("baseline not measured")
+	}
+	if b := m.baseline; b.cpu != 3.5 || b.temp != 40.5 || fmt.Sprint(b.cores) != "[3 5 7 0 1]" {
+		t.Errorf("baseline cpu %v, temp %v, cores %v", b.cpu, b.temp, b.cores)
+	}
+
+	current := []float64{50, 5, 27, 90, 20}
+	m.updateBaseline(&Sample{}, current)
+	screen := newScreenBuffer(100, 3)
+	m.out = screen
+	m.displayBaseline(38.5, 43.7)
+	if want := "Above idle baseline: Temp +3.2°C  CPU +35.0%  Cores 3 +90.0%  0 +47.0%  2 +20.0%  +1"; !strings.Contains(screen.String(), want) {
+		t.Errorf("baseline line %q, want %q", screen.String(), want)
+	}
+
+	m.startBaseline()
+	m.stressRunning = true
+	m.updateBaseline(&Sample{CPU: 100}, current)
+	if m.baselineRun != nil || m.baseline.cpu != 3.5 {
+		t.Error("stress did not cancel the measurement, or replaced the baseline")
+	}
+}
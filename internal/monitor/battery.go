@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BatteryReading is the state of one laptop battery.
+type BatteryReading struct {
+	Name    string  // Power supply name, e.g. "BAT0"
+	Percent float64 // Charge (0-100%), -1 when not reported
+	Status  string  // "Charging", "Discharging", "Full" or "Not charging", as the kernel reports it
+	Watts   float64 // Charge or discharge rate in W, 0 when not reported
+}
+
+// batterySampler reads the system batteries of the power_supply class.
+// Batteries of peripherals, which the kernel scopes as "Device", are
+// left out.
+type batterySampler struct {
+	dirs []string
+}
+
+// newBatterySampler finds the system batteries.
+func newBatterySampler() *batterySampler {
+	b := &batterySampler{}
+	supplies, _ := filepath.Glob(filepath.Join(sysDir, "class", "power_supply", "*"))
+	sort.Slice(supplies, func(i, j int) bool { return naturalLess(supplies[i], supplies[j]) })
+	for _, dir := range supplies {
+		if readSysfsString(filepath.Join(dir, "type")) == "Battery" && readSysfsString(filepath.Join(dir, "scope")) != "Device" {
+			b.dirs = append(b.dirs, dir)
+		}
+	}
+	return b
+}
+
+// sample reads every battery. The rate comes from power_now, or from
+// current_now and voltage_now on batteries that report charge in µAh;
+// the charge from capacity, or from the energy or charge counters.
+func (b *batterySampler) sample() []BatteryReading {
+	var readings []BatteryReading
+	for _, dir := range b.dirs {
+		read := func(name string) (float64, bool) {
+			v, err := strconv.ParseFloat(readSysfsString(filepath.Join(dir, name)), 64)
+			return v, err == nil
+		}
+		r := BatteryReading{Name: filepath.Base(dir), Percent: -1, Status: readSysfsString(filepath.Join(dir, "status"))}
+		if v, ok := read("capacity"); ok {
+			r.Percent = v
+		} else if now, ok := read("energy_now"); ok {
+			if full, ok := read("energy_full"); ok && full > 0 {
+				r.Percent = now / full * 100
+			}
+		} else if now, ok := read("charge_now"); ok {
+			if full, ok := read("charge_full"); ok && full > 0 {
+				r.Percent = now / full * 100
+			}
+		}
+		if v, ok := read("power_now"); ok {
+			r.Watts = v / 1e6
+		} else if current, ok := read("current_now"); ok {
+			if volts, ok := read("voltage_now"); ok {
+				r.Watts = current / 1e6 * volts / 1e6
+			}
+		}
+		// Some firmware reports discharge as a negative rate
+		if r.Watts < 0 {
+			r.Watts = -r.Watts
+		}
+		readings = append(readings, r)
+	}
+	return readings
+}
+
+// displayBattery prints the charge, state and rate of every battery under
+// the status line. A discharge rate above [battery] warn_watts is red, as
+// it shows when the stress test drains a laptop faster than its battery
+// is meant to be drawn.
+func (m *Monitor) displayBattery() {
+	if len(m.batteries) == 0 {
+		return
+	}
+	parts := make([]string, 0, len(m.batteries))
+	for _, b := range m.batteries {
+		part := fmt.Sprintf("%s%s%s", colorBlue, b.Name, colorReset)
+		if b.Percent >= 0 {
+			// Low charge is the alarming end, so the usage colors run reversed
+			part += fmt.Sprintf(" %s%s%s", getUsageColor(100-b.Percent), formatPercent(b.Percent, 0), colorReset)
+		}
+		if b.Status != "" {
+			part += " " + tr(b.Status)
+		}
+		if b.Watts > 0 {
+			color := colorYellow
+			if b.Status == "Discharging" && m.cfg.Battery.WarnWatts > 0 && b.Watts > m.cfg.Battery.WarnWatts {
+				color = colorBrightRed
+			} else if b.Status == "Charging" {
+				color = colorGreen
+			}
+			part += fmt.Sprintf(" %s%s W%s", color, formatNumber(b.Watts, 1), colorReset)
+		}
+		parts = append(parts, part)
+	}
+	fmt.Fprintf(m.out, "%s %s\r\n\r\n", tr("Battery:"), strings.Join(parts, "  "))
+}
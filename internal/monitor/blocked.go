@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blockedListed is how many blocked tasks the status area names.
+const blockedListed = 3
+
+// blockedTask is a thread in uninterruptible sleep (D state).
+type blockedTask struct {
+	tid   int
+	name  string
+	wchan string        // Kernel function it waits in, when readable
+	stuck time.Duration // How long it has been in D state without a break
+}
+
+// blockedTracker follows threads in uninterruptible sleep. They wait on
+// disk or network filesystem I/O and cannot be interrupted, which is what
+// makes a machine feel slow while the CPU is idle. The threads are only
+// looked up while /proc/stat counts blocked tasks, so an idle tracker
+// costs one read per poll.
+type blockedTracker struct {
+	since map[int]time.Time // When each blocked thread was first seen in D state
+	tasks []blockedTask     // Longest stuck first
+	count int               // Blocked tasks according to /proc/stat
+}
+
+// newBlockedTracker creates an empty tracker.
+func newBlockedTracker() *blockedTracker {
+	return &blockedTracker{since: map[int]time.Time{}}
+}
+
+// iowaitShare computes the percentage of CPU time spent idle while I/O
+// was outstanding between two CPUStats readings.
+func iowaitShare(prev, curr CPUStats) float64 {
+	total := func(s CPUStats) uint64 {
+		return s.user + s.nice + s.system + s.idle + s.iowait + s.irq + s.soft + s.steal
+	}
+	if total(curr) <= total(prev) || curr.iowait < prev.iowait {
+		return 0
+	}
+	return float64(curr.iowait-prev.iowait) / float64(total(curr)-total(prev)) * 100
+}
+
+// readProcsBlocked returns the procs_blocked count of /proc/stat.
+func readProcsBlocked() int {
+	f, err := os.Open(filepath.Join(procDir, "stat"))
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) == 2 && fields[0] == "procs_blocked" {
+			n, _ := strconv.Atoi(fields[1])
+			return n
+		}
+	}
+	return 0
+}
+
+// sample refreshes the blocked threads.
+func (b *blockedTracker) sample() {
+	now := timeNow()
+	b.count = readProcsBlocked()
+	b.tasks = b.tasks[:0]
+	if b.count == 0 {
+		for tid := range b.since {
+			delete(b.since, tid)
+		}
+		return
+	}
+
+	blocked := map[int]bool{}
+	stats, _ := filepath.Glob(filepath.Join(procDir, "[0-9]*", "task", "[0-9]*", "stat"))
+	for _, path := range stats {
+		data := readSysfsString(path)
+		stat := parseProcStat(data)
+		if len(stat) == 0 || stat[0] != "D" {
+			continue
+		}
+		dir := filepath.Dir(path)
+		tid, err := strconv.Atoi(filepath.Base(dir))
+		if err != nil {
+			continue
+		}
+		blocked[tid] = true
+		since, seen := b.since[tid]
+		if !seen {
+			since = now
+			b.since[tid] = now
+		}
+		name := data
+		if i, j := strings.IndexByte(data, '('), strings.LastIndexByte(data, ')'); i >= 0 && j > i {
+			name = data[i+1 : j]
+		}
+		b.tasks = append(b.tasks, blockedTask{
+			tid:   tid,
+			name:  name,
+			wchan: readSysfsString(filepath.Join(dir, "wchan")),
+			stuck: now.Sub(since),
+		})
+	}
+	for tid := range b.since {
+		if !blocked[tid] {
+			delete(b.since, tid)
+		}
+	}
+	sort.Slice(b.tasks, func(i, j int) bool {
+		if b.tasks[i].stuck != b.tasks[j].stuck {
+			return b.tasks[i].stuck > b.tasks[j].stuck
+		}
+		return b.tasks[i].tid < b.tasks[j].tid
+	})
+}
+
+// blockedVisible reports whether the I/O wait line is shown: while any
+// task is blocked or at least 1% of CPU time goes to iowait.
+func (m *Monitor) blockedVisible() bool {
+	return m.blocked.count > 0 || m.iowaitUsage >= 1
+}
+
+// displayBlocked prints the iowait share and the threads stuck in D state
+// longest, such as "I/O wait: 12.0%  D state: 1  rsync[2211] 12s
+// (folio_wait_bit_common)", when there is I/O waiting to show.
+func (m *Monitor) displayBlocked() {
+	if !m.blockedVisible() {
+		return
+	}
+	b := m.blocked
+	fmt.Fprintf(m.out, "%s%s%s %s%s%s  %s%s%s %d", colorBlue, tr("I/O wait:"), colorReset,
+		getUsageColor(m.iowaitUsage*4), formatPercent(m.iowaitUsage, 1), colorReset, // 25% iowait shows as critical
+		colorBlue, tr("D state:"), colorReset, b.count)
+	for i, t := range b.tasks {
+		if i == blockedListed {
+			fmt.Fprintf(m.out, "  +%d", len(b.tasks)-blockedListed)
+			break
+		}
+		color := colorReset
+		if t.stuck >= 10*time.Second {
+			color = colorRed
+		}
+		fmt.Fprintf(m.out, "  %s%s[%d] %s%s", color, t.name, t.tid, t.stuck.Round(time.Second), colorReset)
+		if t.wchan != "" && t.wchan != "0" {
+			fmt.Fprintf(m.out, " (%s)", t.wchan)
+		}
+	}
+	fmt.Fprint(m.out, "\r\n\r\n")
+}
@@ -0,0 +1,179 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// bookmark summarizes the polls of one marked time range of the session.
+type bookmark struct {
+	polls              int
+	cpuSum, cpuMax     float64
+	tempSum, tempMax   float64
+	tempPolls          int // Polls with a temperature reading
+	powerSum, powerMax float64
+	powerPolls         int // Polls with a package power reading
+}
+
+// add sums one poll into the range. Temperature and power are left out
+// when they are missing, so a sensor gap does not pull the average down.
+func (b *bookmark) add(cpu, temp, power float64) {
+	b.polls++
+	b.cpuSum += cpu
+	b.cpuMax = math.Max(b.cpuMax, cpu)
+	if temp > 0 {
+		b.tempPolls++
+		b.tempSum += temp
+		b.tempMax = math.Max(b.tempMax, temp)
+	}
+	if power >= 0 {
+		b.powerPolls++
+		b.powerSum += power
+		b.powerMax = math.Max(b.powerMax, power)
+	}
+}
+
+// duration returns the time the range covers at polls interval apart.
+func (b *bookmark) duration(interval time.Duration) time.Duration {
+	return time.Duration(b.polls) * interval
+}
+
+// bookmarks holds the two ranges compared on the A/B page and the range
+// being marked. Ranges are summarized as they are polled rather than read
+// back from the history, which coarsens older data.
+type bookmarks struct {
+	ranges [2]*bookmark // A and B, nil until marked
+	open   *bookmark    // Range being marked, nil when none
+	next   int          // Slot the next range goes into
+}
+
+// toggle starts a range or, while one is open, ends it and stores it in
+// the next slot. A third range replaces A, a fourth B, and so on, so the
+// two latest ranges are compared.
+func (b *bookmarks) toggle() {
+	if b.open == nil {
+		b.open = &bookmark{}
+		return
+	}
+	if b.open.polls > 0 {
+		b.ranges[b.next] = b.open
+		b.next = 1 - b.next
+	}
+	b.open = nil
+}
+
+// add sums one poll into the open range.
+func (b *bookmarks) add(cpu, temp, power float64) {
+	if b.open != nil {
+		b.open.add(cpu, temp, power)
+	}
+}
+
+// bookmarkNames are the slot labels.
+var bookmarkNames = [2]string{"A", "B"}
+
+// bookmarkStatus returns the status-line marker of the range being
+// marked, or an empty string.
+func (m *Monitor) bookmarkStatus() string {
+	b := m.bookmarks.open
+	if b == nil {
+		return ""
+	}
+	return fmt.Sprintf("  %s[%s %s %s]%s", colorMagenta, tr("MARK"), bookmarkNames[m.bookmarks.next],
+		b.duration(m.cfg.PollInterval).Round(time.Second), colorReset)
+}
+
+// handleBookmarksKey processes a key press while the A/B page is shown.
+// It returns false when the application should quit.
+func (m *Monitor) handleBookmarksKey(key byte) bool {
+	switch m.pageAction(key, actionCompare) {
+	case actionStress:
+		if m.stressRunning {
+			m.stopStress()
+		} else {
+			m.startStress()
+		}
+		return true
+	case actionClose:
+		m.showBookmarks = false
+		fmt.Fprint(m.out, clearScreen)
+		return true
+	case actionQuit:
+		return false
+	}
+	if m.cfg.KeyBindings[key] == actionMark {
+		m.bookmarks.toggle()
+	}
+	return true
+}
+
+// displayBookmarksPage compares the summaries of ranges A and B side by
+// side, with the change from A to B, e.g. before and after a cooler
+// change or a BIOS setting.
+func (m *Monitor) displayBookmarksPage() {
+	fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor - %s ===%s\r\n\r\n", colorGreen, tr("A/B Comparison"), colorReset)
+	a, b := m.bookmarks.ranges[0], m.bookmarks.ranges[1]
+
+	fmt.Fprintf(m.out, "  %s%s%s%s%s%s\r\n", colorCyan, padRight("", 18),
+		padRight(tr("Range")+" A", 16), padRight(tr("Range")+" B", 16), tr("B - A"), colorReset)
+	row := func(label string, value func(*bookmark) (float64, bool), format func(float64) string, delta func(float64) string) {
+		cell := func(r *bookmark) string {
+			if r == nil {
+				return padRight("-", 16)
+			}
+			v, ok := value(r)
+			if !ok {
+				return padRight("n/a", 16)
+			}
+			return padRight(format(v), 16)
+		}
+		diff := ""
+		if a != nil && b != nil && delta != nil {
+			va, okA := value(a)
+			vb, okB := value(b)
+			if okA && okB {
+				color := colorGreen
+				if vb > va {
+					color = colorRed
+				}
+				sign := ""
+				if vb >= va {
+					sign = "+"
+				}
+				diff = color + sign + delta(vb-va) + colorReset
+			}
+		}
+		fmt.Fprintf(m.out, "  %s%s%s%s%s%s\r\n", colorBlue, padRight(tr(label), 18), colorReset, cell(a), cell(b), diff)
+	}
+	percent := func(v float64) string { return unitPercent.format(v, 1) }
+	temp := func(v float64) string { return unitCelsius.format(v, 1) }
+	tempDelta := func(v float64) string { return unitCelsiusDelta.format(v, 1) }
+	watts := func(v float64) string { return unitWatts.format(v, 1) }
+
+	row("Duration", func(r *bookmark) (float64, bool) { return r.duration(m.cfg.PollInterval).Seconds(), true },
+		func(v float64) string { return time.Duration(v * float64(time.Second)).Round(time.Second).String() }, nil)
+	row("Avg CPU", func(r *bookmark) (float64, bool) { return r.cpuSum / float64(r.polls), true }, percent, percent)
+	row("Max CPU", func(r *bookmark) (float64, bool) { return r.cpuMax, true }, percent, percent)
+	row("Avg temperature", func(r *bookmark) (float64, bool) {
+		return r.tempSum / float64(r.tempPolls), r.tempPolls > 0
+	}, temp, tempDelta)
+	row("Max temperature", func(r *bookmark) (float64, bool) { return r.tempMax, r.tempPolls > 0 }, temp, tempDelta)
+	row("Avg power", func(r *bookmark) (float64, bool) {
+		return r.powerSum / float64(r.powerPolls), r.powerPolls > 0
+	}, watts, watts)
+	row("Max power", func(r *bookmark) (float64, bool) { return r.powerMax, r.powerPolls > 0 }, watts, watts)
+	fmt.Fprint(m.out, "\r\n")
+
+	mark := m.cfg.KeyBindings.label(actionMark)
+	if m.bookmarks.open != nil {
+		fmt.Fprintf(m.out, "%s"+tr("Marking range %s: %s so far, %s ends it")+"%s\r\n", colorMagenta,
+			bookmarkNames[m.bookmarks.next], m.bookmarks.open.duration(m.cfg.PollInterval).Round(time.Second), mark, colorReset)
+	} else if a == nil || b == nil {
+		fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, fmt.Sprintf(tr("Press %s to start a range and %s again to end it"), mark, mark), colorReset)
+	} else {
+		fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, tr("A new range replaces the older of the two"), colorReset)
+	}
+	fmt.Fprint(m.out, "\r\n")
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorYellow, tr("M: start/end range  SPACE: stress  A/ESC: close"), colorReset)
+}
@@ -0,0 +1,671 @@
+package monitor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jeremycharlesgillespie/kode_kronical_perf_monitor/internal/version"
+)
+
+// Phase verdicts of a certification run
+const (
+	verdictPass    = "PASS"
+	verdictFail    = "FAIL"
+	verdictSkipped = "SKIPPED"
+)
+
+// certPoint is one per-second sample of a certification run.
+type certPoint struct {
+	t    time.Duration // Since the start of the run
+	cpu  float64
+	temp float64
+}
+
+// certPhase is the outcome of one load phase.
+type certPhase struct {
+	Name       string
+	Start, End time.Time
+	Verdict    string
+	Reason     string // Why the phase failed or was skipped
+	MaxTemp    float64
+	AvgTemp    float64
+	AvgCPU     float64
+	Throttle   uint64 // Thermal throttle events during the phase
+	EDACCE     uint64 // Corrected memory errors during the phase
+	EDACUE     uint64 // Uncorrected memory errors during the phase
+	DiskIOPS   float64
+	DiskLatUs  float64
+	points     []certPoint
+	tempSum    float64
+	tempN      int
+	cpuSum     float64
+	diskPolls  int
+	throttleAt uint64
+}
+
+// runCertify implements the "certify" subcommand: it runs the configured
+// load phases back to back and writes a signed HTML report.
+func runCertify(args []string) int {
+	path := configPath()
+	duration := time.Duration(0)
+	phases := ""
+	out := ""
+	verify := ""
+	trustedKey := ""
+	fingerprint := ""
+
+	fs := flag.NewFlagSet("certify", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&path, "c", path, "")
+	fs.StringVar(&path, "config", path, "")
+	fs.DurationVar(&duration, "duration", duration, "")
+	fs.StringVar(&phases, "phases", phases, "")
+	fs.StringVar(&out, "out", out, "")
+	fs.StringVar(&verify, "verify", verify, "")
+	fs.StringVar(&trustedKey, "key", trustedKey, "")
+	fs.StringVar(&fingerprint, "fingerprint", fingerprint, "")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Println(certifyUsage)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if verify != "" {
+		fingerprint, err := verifyReport(verify, trustedKey, fingerprint)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Signature check failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Signature OK, key %s\n", fingerprint)
+		return 0
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	activeLocale = resolveLocale(cfg)
+	defer children.close()
+	if duration > 0 {
+		cfg.Certify.PhaseDuration = duration
+	}
+	if cfg.Certify.PhaseDuration < 10*time.Second {
+		fmt.Fprintln(os.Stderr, "Error: phases must last at least 10s")
+		return 1
+	}
+	if phases != "" {
+		cfg.Certify.Phases = strings.Split(phases, ",")
+	}
+	for _, name := range cfg.Certify.Phases {
+		if !validCertPhase(name) {
+			fmt.Fprintf(os.Stderr, "Error: unknown phase %q (use cpu, memory, disk, gpu)\n", name)
+			return 1
+		}
+	}
+	if out == "" {
+		host, _ := os.Hostname()
+		out = filepath.Join(cfg.Certify.Dir, fmt.Sprintf("kkperf-certify-%s-%s.html", host, time.Now().Format("20060102-1504")))
+	}
+
+	key, err := loadSigningKey(signingKeyPath(cfg, path))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	m := NewMonitor(cfg)
+	m.headless = true
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	start := time.Now()
+	var results []*certPhase
+	aborted := false
+	for _, name := range cfg.Certify.Phases {
+		fmt.Printf("Phase %s: %s\n", name, cfg.Certify.PhaseDuration)
+		p := m.runCertPhase(name, start, stop)
+		results = append(results, p)
+		fmt.Printf("Phase %s: %s %s\n", name, p.Verdict, p.Reason)
+		if p.Reason == "interrupted" {
+			aborted = true
+			break
+		}
+	}
+
+	html, err := certifyReport(cfg, start, results, aborted, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	sig := ed25519.Sign(key, html)
+	pub := key.Public().(ed25519.PublicKey)
+	sigFile := fmt.Sprintf("public-key: %s\nsignature: %s\n",
+		base64.StdEncoding.EncodeToString(pub), base64.StdEncoding.EncodeToString(sig))
+	if err := ioutil.WriteFile(out, html, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := ioutil.WriteFile(out+".sig", []byte(sigFile), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	verdict := overallVerdict(results, aborted)
+	fmt.Printf("Result: %s\nReport: %s (signature %s.sig)\nSigning key: %s\n", verdict, out, out, keyFingerprint(pub))
+	if verdict != verdictPass {
+		return 2
+	}
+	return 0
+}
+
+// validCertPhase reports whether name is a known phase.
+func validCertPhase(name string) bool {
+	switch name {
+	case "cpu", "memory", "disk", "gpu":
+		return true
+	}
+	return false
+}
+
+// runCertPhase starts the phase's workload, samples once a second until
+// the phase duration elapses, and judges the result. Exceeding max_temp
+// ends the phase early to protect the hardware.
+func (m *Monitor) runCertPhase(name string, runStart time.Time, stop chan os.Signal) *certPhase {
+	cfg := m.cfg.Certify
+	p := &certPhase{Name: name, Start: time.Now(), Verdict: verdictPass}
+	p.throttleAt, _ = readThrottleCount()
+	ce0, ue0, _ := readEDACCounts()
+
+	cmd, reason := m.startCertWorkload(name)
+	if reason != "" {
+		p.Verdict, p.Reason, p.End = verdictSkipped, reason, time.Now()
+		return p
+	}
+	exited := make(chan error, 1)
+	if cmd != nil {
+		go func() { exited <- cmd.Wait() }()
+	}
+
+	deadline := p.Start.Add(cfg.PhaseDuration)
+loop:
+	for time.Now().Before(deadline) {
+		select {
+		case <-stop:
+			p.Verdict, p.Reason = verdictFail, "interrupted"
+			break loop
+		case err := <-exited:
+			cmd = nil
+			if err != nil {
+				p.Verdict, p.Reason = verdictFail, fmt.Sprintf("workload failed: %v", err)
+				break loop
+			}
+			if name != "gpu" {
+				p.Verdict, p.Reason = verdictFail, "workload ended early"
+				break loop
+			}
+		default:
+		}
+		if name == "disk" && !m.diskStress.isRunning() {
+			p.Verdict, p.Reason = verdictFail, "fio ended early: "+m.diskStress.lastError()
+			break
+		}
+
+		s := m.takeSample(time.Second)
+		p.points = append(p.points, certPoint{t: s.Time.Sub(runStart), cpu: s.CPU, temp: s.Temp})
+		p.cpuSum += s.CPU
+		if s.Temp > 0 {
+			p.tempSum += s.Temp
+			p.tempN++
+			if s.Temp > p.MaxTemp {
+				p.MaxTemp = s.Temp
+			}
+		}
+		if s.DiskIOPS > 0 {
+			p.DiskIOPS += s.DiskIOPS
+			p.DiskLatUs += s.DiskLatency
+			p.diskPolls++
+		}
+		if cfg.MaxTemp > 0 && s.Temp >= cfg.MaxTemp {
+			p.Verdict, p.Reason = verdictFail, fmt.Sprintf("temperature reached %s", formatTemp(s.Temp, 1))
+			break
+		}
+	}
+
+	if cmd != nil {
+		// Workers run in their own process group so all of them stop
+		killProcessGroup(cmd)
+		children.remove(cmd)
+	}
+	if name == "disk" {
+		if reason := m.diskStress.lastError(); reason != "" && p.Verdict == verdictPass {
+			p.Verdict, p.Reason = verdictFail, reason
+		}
+		m.stopDiskStress()
+	}
+
+	p.End = time.Now()
+	if n := len(p.points); n > 0 {
+		p.AvgCPU = p.cpuSum / float64(n)
+	}
+	if p.tempN > 0 {
+		p.AvgTemp = p.tempSum / float64(p.tempN)
+	}
+	if p.diskPolls > 0 {
+		p.DiskIOPS /= float64(p.diskPolls)
+		p.DiskLatUs /= float64(p.diskPolls)
+	}
+	if count, ok := readThrottleCount(); ok {
+		p.Throttle = count - p.throttleAt
+	}
+	if ce1, ue1, ok := readEDACCounts(); ok {
+		p.EDACCE, p.EDACUE = ce1-ce0, ue1-ue0
+		if p.EDACUE > 0 && p.Verdict == verdictPass {
+			p.Verdict, p.Reason = verdictFail, "uncorrected memory errors"
+		}
+	}
+	return p
+}
+
+// startCertWorkload launches the load for a phase. It returns a reason
+// instead when the phase cannot run. The disk phase runs fio through the
+// disk stress and returns no command.
+func (m *Monitor) startCertWorkload(name string) (*exec.Cmd, string) {
+	var cmd *exec.Cmd
+	switch name {
+	case "cpu", "memory":
+		if !stressBinaryAvailable() {
+			return nil, "stress is not installed"
+		}
+		if name == "cpu" {
+			cmd = exec.Command("stress", "--cpu", strconv.Itoa(m.cores))
+		} else {
+			workers := m.cores / 2
+			if workers < 1 {
+				workers = 1
+			}
+			available := memAvailableBytes()
+			if available == 0 {
+				return nil, "cannot read MemAvailable from /proc/meminfo"
+			}
+			perWorker := available * uint64(m.cfg.Certify.MemoryPercent) / 100 / uint64(workers)
+			cmd = exec.Command("stress", "--vm", strconv.Itoa(workers), "--vm-bytes", strconv.FormatUint(perWorker, 10), "--vm-keep")
+		}
+	case "disk":
+		if !fioAvailable() {
+			return nil, "fio is not installed"
+		}
+		// A margin keeps fio running until the phase ends
+		m.cfg.Fio.Runtime = m.cfg.Certify.PhaseDuration + 10*time.Second
+		m.startDiskStress()
+		if !m.diskStress.isRunning() {
+			return nil, m.diskStress.lastError()
+		}
+		return nil, ""
+	case "gpu":
+		if m.cfg.Certify.GPUCommand == "" {
+			return nil, "no gpu_command configured"
+		}
+		cmd = exec.Command(shellName, shellFlag, m.cfg.Certify.GPUCommand)
+	}
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err.Error()
+	}
+	children.add(cmd)
+	return cmd, ""
+}
+
+// overallVerdict passes a run when no phase failed, at least one ran, and
+// it was not interrupted.
+func overallVerdict(results []*certPhase, aborted bool) string {
+	if aborted {
+		return verdictFail
+	}
+	passed := 0
+	for _, p := range results {
+		switch p.Verdict {
+		case verdictFail:
+			return verdictFail
+		case verdictPass:
+			passed++
+		}
+	}
+	if passed == 0 {
+		return verdictFail
+	}
+	return verdictPass
+}
+
+// readEDACCounts sums the corrected and uncorrected error counts of all
+// EDAC memory controllers. ok is false on systems without EDAC.
+func readEDACCounts() (ce, ue uint64, ok bool) {
+	dirs, _ := filepath.Glob(filepath.Join(sysDir, "devices", "system", "edac", "mc", "mc[0-9]*"))
+	for _, dir := range dirs {
+		c, err1 := strconv.ParseUint(readSysfsString(filepath.Join(dir, "ce_count")), 10, 64)
+		u, err2 := strconv.ParseUint(readSysfsString(filepath.Join(dir, "ue_count")), 10, 64)
+		if err1 == nil && err2 == nil {
+			ce, ue, ok = ce+c, ue+u, true
+		}
+	}
+	return ce, ue, ok
+}
+
+// memAvailableBytes returns MemAvailable from /proc/meminfo, or 0.
+func memAvailableBytes() uint64 {
+	data, err := ioutil.ReadFile(filepath.Join(procDir, "meminfo"))
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+			kb, _ := strconv.ParseUint(fields[1], 10, 64)
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+// signingKeyPath returns [certify] signing_key, or certify.key next to
+// the config file at configFile, so --config picks the key with the rest
+// of the settings.
+func signingKeyPath(cfg *Config, configFile string) string {
+	if cfg.Certify.SigningKey != "" {
+		return cfg.Certify.SigningKey
+	}
+	return filepath.Join(filepath.Dir(configFile), "certify.key")
+}
+
+// loadSigningKey reads the Ed25519 key that signs reports, creating it
+// (mode 0600) on first use.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, fmt.Errorf("signing key: %v", err)
+		}
+		pemData := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := ioutil.WriteFile(path, pemData, 0600); err != nil {
+			return nil, fmt.Errorf("signing key: %v", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s: not PEM", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %v", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s: not an Ed25519 key", path)
+	}
+	return key, nil
+}
+
+// keyFingerprint identifies a public key as "SHA256:<base64>", like ssh.
+func keyFingerprint(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// loadPublicKey reads the trusted key of --verify --key: a PEM public key,
+// or the signing key itself, whose public half is used.
+func loadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: not PEM", path)
+	}
+	var parsed interface{}
+	switch block.Type {
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %s: unexpected %s", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %v", path, err)
+	}
+	switch key := parsed.(type) {
+	case ed25519.PublicKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key.Public().(ed25519.PublicKey), nil
+	}
+	return nil, fmt.Errorf("key %s: not an Ed25519 key", path)
+}
+
+// verifyReport checks a report against the signature file next to it and
+// returns the fingerprint of the signing key. The public key in the
+// signature file only says which key signed; anyone can re-sign an edited
+// report with a key of their own, so the key must also be the trusted one,
+// given as a key file or as its fingerprint.
+func verifyReport(path, keyPath, fingerprint string) (string, error) {
+	if keyPath == "" && fingerprint == "" {
+		return "", fmt.Errorf("no trusted key: give --key or --fingerprint")
+	}
+	report, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sigFile, err := ioutil.ReadFile(path + ".sig")
+	if err != nil {
+		return "", err
+	}
+	var pub, sig []byte
+	for _, line := range strings.Split(string(sigFile), "\n") {
+		name, value, _ := strings.Cut(line, ": ")
+		switch name {
+		case "public-key":
+			pub, _ = base64.StdEncoding.DecodeString(value)
+		case "signature":
+			sig, _ = base64.StdEncoding.DecodeString(value)
+		}
+	}
+	if len(pub) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+		return "", fmt.Errorf("malformed %s.sig", path)
+	}
+	if keyPath != "" {
+		trusted, err := loadPublicKey(keyPath)
+		if err != nil {
+			return "", err
+		}
+		if !bytes.Equal(trusted, pub) {
+			return "", fmt.Errorf("signed by %s, not by the trusted key %s", keyFingerprint(pub), keyFingerprint(trusted))
+		}
+	}
+	if fingerprint != "" && keyFingerprint(pub) != fingerprint {
+		return "", fmt.Errorf("signed by %s, not by the trusted key %s", keyFingerprint(pub), fingerprint)
+	}
+	if !ed25519.Verify(pub, report, sig) {
+		return "", fmt.Errorf("report does not match its signature")
+	}
+	fingerprint = keyFingerprint(pub)
+	if !bytes.Contains(report, []byte(fingerprint)) {
+		return "", fmt.Errorf("report names a different signing key")
+	}
+	return fingerprint, nil
+}
+
+// certifyReport renders the HTML report with the temperature and CPU
+// curves of the whole run, phase boundaries, and the phase table.
+func certifyReport(cfg *Config, start time.Time, results []*certPhase, aborted bool, key ed25519.PrivateKey) ([]byte, error) {
+	host, _ := os.Hostname()
+	model := ""
+	if data, err := ioutil.ReadFile(filepath.Join(procDir, "cpuinfo")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "model name") {
+				if _, v, ok := strings.Cut(line, ":"); ok {
+					model = strings.TrimSpace(v)
+				}
+				break
+			}
+		}
+	}
+
+	data := struct {
+		Host, Model, Started, Verdict, Fingerprint, Version string
+		MaxTemp                                             string
+		Aborted                                             bool
+		Phases                                              []*certPhase
+		Chart                                               template.HTML
+	}{
+		Host:        host,
+		Model:       model,
+		Started:     start.Format("2006-01-02 15:04:05 MST"),
+		Verdict:     overallVerdict(results, aborted),
+		Fingerprint: keyFingerprint(key.Public().(ed25519.PublicKey)),
+		Version:     version.Version,
+		MaxTemp:     formatTemp(cfg.Certify.MaxTemp, 0),
+		Aborted:     aborted,
+		Phases:      results,
+		Chart:       template.HTML(certifyChart(start, results)),
+	}
+	var b bytes.Buffer
+	err := certifyTemplate.Execute(&b, data)
+	return b.Bytes(), err
+}
+
+// certifyChart draws the run as an SVG: temperature in red and CPU usage
+// in blue over time, with a labeled band per phase.
+func certifyChart(start time.Time, results []*certPhase) string {
+	const width, height, pad = 900.0, 260.0, 30.0
+	if len(results) == 0 {
+		return ""
+	}
+	total := results[len(results)-1].End.Sub(start).Seconds()
+	if total <= 0 {
+		return ""
+	}
+	maxTemp := 100.0
+	for _, p := range results {
+		if p.MaxTemp > maxTemp {
+			maxTemp = p.MaxTemp
+		}
+	}
+	x := func(t float64) float64 { return pad + t/total*(width-2*pad) }
+	y := func(v, max float64) float64 { return height - pad - v/max*(height-2*pad) }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" font-family="sans-serif" font-size="11">`, width, height)
+	for i, p := range results {
+		x0, x1 := x(p.Start.Sub(start).Seconds()), x(p.End.Sub(start).Seconds())
+		fill := "#f4f4f4"
+		if i%2 == 1 {
+			fill = "#e8e8e8"
+		}
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`, x0, pad, x1-x0, height-2*pad, fill)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f">%s</text>`, x0+4, pad-6, template.HTMLEscapeString(p.Name))
+	}
+	for _, series := range []struct {
+		color string
+		value func(certPoint) float64
+		max   float64
+	}{
+		{"#1f77b4", func(p certPoint) float64 { return p.cpu }, 100},
+		{"#d62728", func(p certPoint) float64 { return p.temp }, maxTemp},
+	} {
+		var points []string
+		for _, p := range results {
+			for _, pt := range p.points {
+				if v := series.value(pt); v > 0 {
+					points = append(points, fmt.Sprintf("%.1f,%.1f", x(pt.t.Seconds()), y(v, series.max)))
+				}
+			}
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"/>`, series.color, strings.Join(points, " "))
+	}
+	fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" fill="#d62728">temperature (0-%.0f °C)</text>`, pad, height-8, maxTemp)
+	fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" fill="#1f77b4">CPU usage (0-100%%)</text>`, pad+200, height-8)
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// certifyTemplate is the HTML report. It is self-contained so it can be
+// archived, mailed, or printed to PDF from a browser.
+var certifyTemplate = template.Must(template.New("certify").Funcs(template.FuncMap{
+	"temp": func(v float64) string {
+		if v == 0 {
+			return "n/a"
+		}
+		return formatTemp(v, 1)
+	},
+	"percent": func(v float64) string { return formatPercent(v, 1) },
+	"minutes": func(from, to time.Time) string { return fmt.Sprintf("%.1f min", to.Sub(from).Minutes()) },
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Burn-in certification: {{.Host}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222 }
+table { border-collapse: collapse } td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: right }
+th:first-child, td:first-child { text-align: left }
+.PASS { color: #2a7d2a; font-weight: bold } .FAIL { color: #c00; font-weight: bold } .SKIPPED { color: #888 }
+footer { margin-top: 2em; font-size: 0.85em; color: #666 }
+</style></head><body>
+<h1>Burn-in certification: <span class="{{.Verdict}}">{{.Verdict}}</span></h1>
+<p>Host <b>{{.Host}}</b>{{if .Model}}, {{.Model}}{{end}}. Started {{.Started}}.
+Phases fail on a workload error, uncorrected memory errors, or a temperature of {{.MaxTemp}} or more.{{if .Aborted}} <b>The run was interrupted.</b>{{end}}</p>
+{{.Chart}}
+<table>
+<tr><th>Phase</th><th>Result</th><th>Duration</th><th>Avg CPU</th><th>Avg temp</th><th>Max temp</th><th>Throttle events</th><th>ECC corrected</th><th>ECC uncorrected</th><th>Disk</th></tr>
+{{range .Phases}}<tr><td>{{.Name}}</td><td class="{{.Verdict}}">{{.Verdict}}{{if .Reason}}: {{.Reason}}{{end}}</td><td>{{minutes .Start .End}}</td><td>{{percent .AvgCPU}}</td><td>{{temp .AvgTemp}}</td><td>{{temp .MaxTemp}}</td><td>{{.Throttle}}</td><td>{{.EDACCE}}</td><td>{{.EDACUE}}</td><td>{{if .DiskIOPS}}{{printf "%.0f" .DiskIOPS}} IOPS, {{printf "%.0f" .DiskLatUs}} µs{{end}}</td></tr>
+{{end}}</table>
+<footer>Generated by Kode Kronical Perf Monitor {{.Version}}. Signed with Ed25519 key {{.Fingerprint}}; verify with <code>kkperf certify --verify FILE</code>.</footer>
+</body></html>
+`))
+
+// certifyUsage documents the certify subcommand.
+const certifyUsage = `Usage: kkperf certify [options]
+
+Run a burn-in: CPU, memory, disk, and GPU load phases back to back, then
+write a signed HTML report with temperature curves, error counters, and
+pass/fail verdicts. Exits 0 on PASS and 2 on FAIL.
+
+Options:
+  --phases LIST     Comma-separated phases (default from config: cpu,memory,disk,gpu)
+  --duration D      Length of each phase, e.g. 10m
+  --out FILE        Report file (default kkperf-certify-HOST-TIME.html in [certify] dir)
+  --verify FILE     Check FILE against FILE.sig and exit; needs --key or --fingerprint
+  --key FILE        Trusted key for --verify: a PEM public key or the signing key
+  --fingerprint FP  Trusted key for --verify by fingerprint, as printed by certify
+  -c, --config PATH Use an alternate config file`
@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestVerifyReport checks that --verify accepts a report signed by the
+// trusted key only, not one re-signed with the key in its .sig file.
+func TestVerifyReport(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.html")
+	sign := func(keyPath string) string {
+		key, err := loadSigningKey(keyPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := key.Public().(ed25519.PublicKey)
+		html := []byte("<p>PASS, key " + keyFingerprint(pub) + "</p>")
+		sig := fmt.Sprintf("public-key: %s\nsignature: %s\n",
+			base64.StdEncoding.EncodeToString(pub), base64.StdEncoding.EncodeToString(ed25519.Sign(key, html)))
+		ioutil.WriteFile(report, html, 0644)
+		ioutil.WriteFile(report+".sig", []byte(sig), 0644)
+		return keyFingerprint(pub)
+	}
+	trustedKey := filepath.Join(dir, "trusted.key")
+	trusted := sign(trustedKey)
+
+	if _, err := verifyReport(report, "", ""); err == nil {
+		t.Error("verified without a trusted key")
+	}
+	if fp, err := verifyReport(report, "", trusted); err != nil || fp != trusted {
+		t.Errorf("fingerprint check = %q, %v; want %q", fp, err, trusted)
+	}
+	if _, err := verifyReport(report, trustedKey, ""); err != nil {
+		t.Errorf("key check failed: %v", err)
+	}
+
+	forged := sign(filepath.Join(dir, "forged.key"))
+	if forged == trusted {
+		t.Fatal("same key generated twice")
+	}
+	if _, err := verifyReport(report, "", trusted); err == nil || !strings.Contains(err.Error(), forged) {
+		t.Errorf("re-signed report passed the fingerprint check: %v", err)
+	}
+	if _, err := verifyReport(report, trustedKey, ""); err == nil {
+		t.Error("re-signed report passed the key check")
+	}
+}
+
+// TestSigningKeyPath checks that the default signing key sits next to the
+// config file given with --config, not the default one.
+func TestSigningKeyPath(t *testing.T) {
+	cfg := defaultConfig()
+	if got, want := signingKeyPath(cfg, filepath.Join("other", "config.toml")), filepath.Join("other", "certify.key"); got != want {
+		t.Errorf("default key = %s; want %s", got, want)
+	}
+	cfg.Certify.SigningKey = "/keys/lab.key"
+	if got := signingKeyPath(cfg, filepath.Join("other", "config.toml")); got != "/keys/lab.key" {
+		t.Errorf("configured key = %s; want /keys/lab.key", got)
+	}
+}
@@ -0,0 +1,153 @@
+package monitor
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Nagios plugin exit codes.
+const (
+	checkOK       = 0
+	checkWarning  = 1
+	checkCritical = 2
+	checkUnknown  = 3
+)
+
+var checkStatusNames = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// checkThresholds holds the limits given to the check subcommand.
+// Zero means the threshold is not set.
+type checkThresholds struct {
+	warnTemp, critTemp float64
+	warnCPU, critCPU   float64
+}
+
+// runCheck implements the "check" subcommand: it takes one sample, prints
+// a Nagios/Icinga plugin status line with perfdata, and returns the plugin
+// exit code. Temperatures are always in °C so thresholds and perfdata do
+// not depend on the display settings.
+func runCheck(args []string) int {
+	var th checkThresholds
+	path := configPath()
+	interval := time.Second
+
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&path, "c", path, "")
+	fs.StringVar(&path, "config", path, "")
+	fs.Float64Var(&th.warnTemp, "warn-temp", 0, "")
+	fs.Float64Var(&th.critTemp, "crit-temp", 0, "")
+	fs.Float64Var(&th.warnCPU, "warn-cpu", 0, "")
+	fs.Float64Var(&th.critCPU, "crit-cpu", 0, "")
+	fs.DurationVar(&interval, "interval", interval, "")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Println(checkUsage)
+			return checkOK
+		}
+		fmt.Printf("KKPERF UNKNOWN - %v\n", err)
+		return checkUnknown
+	}
+	if fs.NArg() > 0 {
+		fmt.Printf("KKPERF UNKNOWN - unknown argument: %s\n", fs.Arg(0))
+		return checkUnknown
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		fmt.Printf("KKPERF UNKNOWN - %v\n", err)
+		return checkUnknown
+	}
+
+	m := NewMonitor(cfg)
+	sample := m.takeSample(interval)
+	status, output := evaluateCheck(&sample, th)
+	fmt.Println(output)
+	return status
+}
+
+// evaluateCheck compares a sample against the thresholds and returns the
+// plugin status and its output line.
+func evaluateCheck(s *Sample, th checkThresholds) (int, string) {
+	status := checkOK
+	var problems []string
+	// CRITICAL outranks UNKNOWN: a missing sensor must not mask a real alarm
+	severity := []int{checkOK: 0, checkWarning: 1, checkUnknown: 2, checkCritical: 3}
+	raise := func(level int, problem string) {
+		if severity[level] > severity[status] {
+			status = level
+		}
+		problems = append(problems, problem)
+	}
+
+	switch {
+	case th.critCPU > 0 && s.CPU >= th.critCPU:
+		raise(checkCritical, fmt.Sprintf("CPU usage %.1f%% >= %g%%", s.CPU, th.critCPU))
+	case th.warnCPU > 0 && s.CPU >= th.warnCPU:
+		raise(checkWarning, fmt.Sprintf("CPU usage %.1f%% >= %g%%", s.CPU, th.warnCPU))
+	}
+
+	tempChecked := th.warnTemp > 0 || th.critTemp > 0
+	switch {
+	case s.Temp <= 0:
+		if tempChecked {
+			raise(checkUnknown, "temperature unavailable")
+		}
+	case th.critTemp > 0 && s.Temp >= th.critTemp:
+		raise(checkCritical, fmt.Sprintf("temperature %.1f°C >= %g°C", s.Temp, th.critTemp))
+	case th.warnTemp > 0 && s.Temp >= th.warnTemp:
+		raise(checkWarning, fmt.Sprintf("temperature %.1f°C >= %g°C", s.Temp, th.warnTemp))
+	}
+
+	if s.CoolingFailure != "" {
+		raise(checkCritical, s.CoolingFailure)
+	}
+	if s.HealthWarning != "" {
+		raise(checkWarning, s.HealthWarning)
+	}
+
+	summary := fmt.Sprintf("CPU usage %.1f%%", s.CPU)
+	if s.Temp > 0 {
+		summary += fmt.Sprintf(", temperature %.1f°C", s.Temp)
+	}
+	if len(problems) > 0 {
+		summary = strings.Join(problems, ", ")
+	}
+
+	// Perfdata: label=value[UOM];warn;crit;min;max
+	threshold := func(v float64) string {
+		if v <= 0 {
+			return ""
+		}
+		return fmt.Sprintf("%g", v)
+	}
+	perf := []string{fmt.Sprintf("cpu=%.1f%%;%s;%s;0;100", s.CPU, threshold(th.warnCPU), threshold(th.critCPU))}
+	if s.Temp > 0 {
+		perf = append(perf, fmt.Sprintf("temperature=%.1f;%s;%s;;", s.Temp, threshold(th.warnTemp), threshold(th.critTemp)))
+	}
+	for i, usage := range s.Cores {
+		perf = append(perf, fmt.Sprintf("core_%d=%.1f%%;;;0;100", i, usage))
+	}
+
+	return status, fmt.Sprintf("KKPERF %s - %s | %s", checkStatusNames[status], summary, strings.Join(perf, " "))
+}
+
+// checkUsage documents the check subcommand.
+const checkUsage = `Usage: kkperf check [options]
+
+Nagios/Icinga plugin mode. Prints one status line with perfdata and exits
+0 (OK), 1 (WARNING), 2 (CRITICAL) or 3 (UNKNOWN). A liquid-cooling failure
+per the [cooling] limits is always CRITICAL; zombies, threads or open
+files over the [health] limits are a WARNING.
+
+Options:
+  --warn-temp C     Warning temperature in °C
+  --crit-temp C     Critical temperature in °C
+  --warn-cpu PCT    Warning total CPU usage
+  --crit-cpu PCT    Critical total CPU usage
+  --interval DUR    Measurement interval (default 1s)
+  -c, --config PATH Use an alternate config file`
@@ -0,0 +1,208 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	clockInterval = 16 * time.Second       // How often the time daemon is asked
+	clockTimeout  = 2 * time.Second        // Bound on one query
+	clockStepMin  = 500 * time.Millisecond // Smallest wall clock jump reported as a step
+	clockOffsetOK = 0.1                    // Offsets up to this many seconds are shown as fine
+)
+
+// clockStatus is what the time daemon reports about the system clock.
+type clockStatus struct {
+	source    string  // "chrony", "ntpd" or "timesyncd"; "" when none answered
+	synced    bool    // Whether the daemon considers the clock synchronized
+	offset    float64 // Seconds the clock is ahead of the time sources
+	hasOffset bool    // Whether the daemon reports an offset (timesyncd does not)
+}
+
+// clockQuery asks the time daemon for its status. Tests replace it.
+var clockQuery = queryClock
+
+// queryClock asks chrony, then ntpd, then systemd-timesyncd, and returns
+// the first answer.
+func queryClock() clockStatus {
+	run := func(name string, args ...string) string {
+		ctx, cancel := context.WithTimeout(context.Background(), clockTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, name, args...).Output()
+		if err != nil {
+			return ""
+		}
+		return string(out)
+	}
+	if s, ok := parseChronyTracking(run("chronyc", "-c", "tracking")); ok {
+		return s
+	}
+	if s, ok := parseNtpqVars(run("ntpq", "-c", "rv 0 leap,offset")); ok {
+		return s
+	}
+	switch strings.TrimSpace(run("timedatectl", "show", "--property=NTPSynchronized", "--value")) {
+	case "yes":
+		return clockStatus{source: "timesyncd", synced: true}
+	case "no":
+		return clockStatus{source: "timesyncd"}
+	}
+	return clockStatus{}
+}
+
+// parseChronyTracking reads the CSV output of "chronyc -c tracking". The
+// sixth field is the offset at the last clock update, positive when the
+// clock was ahead, and the last is the leap status, "Not synchronised"
+// until a source is selected.
+func parseChronyTracking(out string) (clockStatus, bool) {
+	fields := strings.Split(strings.TrimSpace(out), ",")
+	if len(fields) < 14 {
+		return clockStatus{}, false
+	}
+	offset, err := strconv.ParseFloat(fields[5], 64)
+	if err != nil {
+		return clockStatus{}, false
+	}
+	return clockStatus{source: "chrony", synced: fields[13] != "Not synchronised", offset: offset, hasOffset: true}, true
+}
+
+// parseNtpqVars reads "leap=00, offset=-0.123" from "ntpq -c rv". ntpd
+// reports the offset in milliseconds and leap 11 while unsynchronized.
+func parseNtpqVars(out string) (clockStatus, bool) {
+	s := clockStatus{source: "ntpd"}
+	leap := ""
+	for _, v := range strings.FieldsFunc(out, func(r rune) bool { return r == ',' || r == '\n' || r == ' ' }) {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "leap":
+			leap = kv[1]
+		case "offset":
+			if ms, err := strconv.ParseFloat(kv[1], 64); err == nil {
+				s.offset, s.hasOffset = ms/1000, true
+			}
+		}
+	}
+	if leap == "" {
+		return s, false
+	}
+	s.synced = leap != "11"
+	return s, true
+}
+
+// clockWatch follows the time daemon in the background and detects steps
+// of the wall clock between polls: timestamps on either side of a step
+// do not line up, which spoils recordings.
+type clockWatch struct {
+	mu     sync.Mutex
+	status clockStatus
+
+	last     time.Time     // Previous poll, with its monotonic reading
+	steps    int           // Steps seen since startup
+	lastStep time.Duration // Size of the latest step, positive when the clock jumped ahead
+	stepAt   time.Time     // When the latest step was seen
+}
+
+// newClockWatch asks the time daemon once, so the first frame shows its
+// status, and then every clockInterval in the background.
+func newClockWatch() *clockWatch {
+	query := clockQuery
+	c := &clockWatch{status: query()}
+	go func() {
+		for {
+			time.Sleep(clockInterval)
+			status := query()
+			c.mu.Lock()
+			c.status = status
+			c.mu.Unlock()
+		}
+	}()
+	return c
+}
+
+// reading returns the latest daemon status.
+func (c *clockWatch) reading() clockStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// step compares how far the wall clock and the monotonic clock moved
+// since the previous poll and returns the difference when it is at least
+// clockStepMin, or 0. now must carry a monotonic reading, as time.Now
+// does.
+func (c *clockWatch) step(now time.Time) time.Duration {
+	last := c.last
+	c.last = now
+	if last.IsZero() {
+		return 0
+	}
+	step := now.Round(0).Sub(last.Round(0)) - now.Sub(last)
+	if step > -clockStepMin && step < clockStepMin {
+		return 0
+	}
+	c.steps++
+	c.lastStep, c.stepAt = step, now
+	return step
+}
+
+// formatClockOffset formats an offset in seconds with a sign and a unit
+// that suits its size, e.g. "+182 µs", "-12.4 ms" or "+3.20 s".
+func formatClockOffset(seconds float64) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+	}
+	abs := math.Abs(seconds)
+	switch {
+	case abs < 1e-3:
+		return fmt.Sprintf("%s%.0f µs", sign, abs*1e6)
+	case abs < 1:
+		return fmt.Sprintf("%s%s ms", sign, formatNumber(abs*1e3, 1))
+	}
+	return fmt.Sprintf("%s%s s", sign, formatNumber(abs, 2))
+}
+
+// clockVisible reports whether the clock line is shown: when a time
+// daemon answered or the clock has stepped.
+func (m *Monitor) clockVisible() bool {
+	return m.clock.reading().source != "" || m.clock.steps > 0
+}
+
+// displayClock prints the time daemon's sync status and offset, and the
+// latest clock step in red, e.g. "Clock: chrony synchronized  offset
+// +182 µs  stepped +3.20 s at 14:03:07".
+func (m *Monitor) displayClock() {
+	if !m.clockVisible() {
+		return
+	}
+	s := m.clock.reading()
+	fmt.Fprintf(m.out, "%s%s%s", colorBlue, tr("Clock:"), colorReset)
+	if s.source != "" {
+		state, color := tr("synchronized"), colorGreen
+		if !s.synced {
+			state, color = tr("not synchronized"), colorRed
+		}
+		fmt.Fprintf(m.out, " %s %s%s%s", s.source, color, state, colorReset)
+	}
+	if s.hasOffset {
+		color := colorReset
+		if math.Abs(s.offset) > clockOffsetOK {
+			color = colorDarkYellow
+		}
+		fmt.Fprintf(m.out, "  %s %s%s%s", tr("offset"), color, formatClockOffset(s.offset), colorReset)
+	}
+	if m.clock.steps > 0 {
+		fmt.Fprintf(m.out, "  %s"+tr("stepped %s at %s")+"%s", colorRed,
+			formatClockOffset(m.clock.lastStep.Seconds()), m.clock.stepAt.Format("15:04:05"), colorReset)
+	}
+	fmt.Fprint(m.out, "\r\n\r\n")
+}
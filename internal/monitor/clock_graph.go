@@ -0,0 +1,109 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+)
+
+// clockLimitedUsage is the CPU usage (%) above which a clock below the
+// base frequency counts as limited rather than idle.
+const clockLimitedUsage = 50
+
+// baseKHz returns the mean base frequency of the cores, or 0 when unknown.
+func (c *clockSampler) baseKHz() float64 {
+	sum, n := 0.0, 0
+	for i := range c.cores {
+		if base := c.cores[i].baseKHz; base > 0 {
+			sum += base
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// clockLimited reports whether the clock of a poll was below the base
+// frequency while the CPU was busy, which is how power and thermal limits
+// show on CPUs without throttle counters. Rounding in the reported
+// frequencies is allowed for.
+func (m *Monitor) clockLimited(p historyPoint) bool {
+	base := m.clocks.baseKHz() / 1000
+	return base > 0 && p.mhz > 0 && p.mhz < base*0.99 && p.cpu >= clockLimitedUsage
+}
+
+// throttleColor colors a point of the clock graph by throttle state: red
+// when the CPU reported thermal throttling, yellow when the clock was
+// limited, and green otherwise.
+func throttleColor(p historyPoint) string {
+	switch {
+	case p.throttled:
+		return colorRed
+	case p.limited:
+		return colorYellow
+	}
+	return colorGreen
+}
+
+// drawClockGraph plots the mean core clock over time, colored by throttle
+// state, so the moment a stress run starts throttling stands out. The
+// axis runs from zero to the highest boost clock.
+func (m *Monitor) drawClockGraph() {
+	var latest historyPoint
+	if len(m.displayBuffer) > 0 {
+		latest = m.displayBuffer[len(m.displayBuffer)-1]
+	}
+	current := formatMHz(latest.mhz * 1000)
+	if latest.throttled {
+		current += " " + colorRed + tr("THROTTLED")
+	}
+	fmt.Fprintf(m.out, "%s%s%s %s %s%s%s\r\n", colorCyan, tr("Core Clock & Throttling Graph"), colorReset,
+		tr("Current:"), colorYellow, current, colorReset)
+	if !m.clocks.available {
+		fmt.Fprintf(m.out, "        %s\r\n", tr("CPU frequency information is not available (no cpufreq in /sys/devices/system/cpu)"))
+		fmt.Fprintf(m.out, "        %s%-10s%s\r\n", colorCyan, formatWindow(m.window), colorReset)
+		return
+	}
+
+	readings := make([]float64, len(m.displayBuffer))
+	for i, p := range m.displayBuffer {
+		readings[i] = -1
+		if p.mhz > 0 {
+			readings[i] = p.mhz
+		}
+	}
+	top := math.Max(m.clocks.scaleKHz()/1000, 1)
+	axis := newYAxis(scaleFixed, readings, math.Ceil(top/500)*500, 500)
+
+	values := make([]float64, baseGraphWidth)
+	colors := make([]string, baseGraphWidth)
+	for i := range values {
+		values[i] = -1
+		if i < len(readings) {
+			values[i] = axis.fraction(readings[i])
+			colors[i] = throttleColor(m.displayBuffer[i])
+		}
+	}
+	grid := plotSeries(values, colors, 5, m.cfg.CPUGraphStyle)
+	for row := 4; row >= 0; row-- {
+		fmt.Fprintf(m.out, "%s%4.0f MHz%s", colorCyan, axis.top(row, 5), colorReset)
+		for _, cell := range grid[row] {
+			if cell.glyph != "" {
+				fmt.Fprintf(m.out, "%s%s%s", cell.color, cell.glyph, colorReset)
+			} else {
+				fmt.Fprint(m.out, " ")
+			}
+		}
+		fmt.Fprint(m.out, "\r\n")
+	}
+
+	base := tr("base clock unknown")
+	if khz := m.clocks.baseKHz(); khz > 0 {
+		base = fmt.Sprintf(tr("base %s"), formatMHz(khz))
+	}
+	fmt.Fprintf(m.out, "        %s█%s %s  %s█%s %s  %s█%s %s\r\n", colorGreen, colorReset, tr("normal"),
+		colorYellow, colorReset, fmt.Sprintf(tr("below base under load (%s)"), base), colorRed, colorReset, tr("thermal throttling"))
+	fmt.Fprintf(m.out, "        %s%s%s\r\n", colorYellow, m.zoomHint(), colorReset)
+	fmt.Fprintf(m.out, "        %s%-10s%s\r\n", colorCyan, formatWindow(m.window), colorReset)
+}
@@ -0,0 +1,880 @@
+package monitor
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jeremycharlesgillespie/kode_kronical_perf_monitor/internal/toml"
+)
+
+// Config holds user settings loaded from the TOML config file.
+// Every field has a usable zero value or is filled in by defaultConfig,
+// so the monitor runs unchanged when no config file exists.
+type Config struct {
+	Locale          string `toml:"locale"`           // Overrides LANG/LC_* for formatting and UI strings
+	TemperatureUnit string `toml:"temperature_unit"` // "C" (default) or "F"
+	Theme           string `toml:"theme"`            // "default" or "high-contrast"
+	TerminalTitle   bool   `toml:"terminal_title"`   // Keep the terminal title set to live stats
+
+	Sensor           string   `toml:"sensor"`            // Temperature sensor id or file path for the main graph; empty selects automatically
+	SecondarySensors []string `toml:"secondary_sensors"` // Additional sensor ids shown below the status line
+
+	Sensors struct {
+		Allow    []string `toml:"allow"`     // Glob patterns of sensor ids to use; empty allows all
+		Deny     []string `toml:"deny"`      // Glob patterns of sensor ids to ignore
+		MinValid float64  `toml:"min_valid"` // Readings outside [min_valid, max_valid] °C are discarded
+		MaxValid float64  `toml:"max_valid"`
+	} `toml:"sensors"`
+
+	TopProcesses int `toml:"top_processes"` // Rows of the process pane (P)
+
+	PhaseLabels bool `toml:"phase_labels"` // Label idle, ramp, steady and cooldown phases under the combined graph
+
+	GPU struct {
+		Panel     bool   `toml:"panel"`      // Show the GPU panel under the core bars when a card is found
+		NvidiaSMI string `toml:"nvidia_smi"` // nvidia-smi command for NVIDIA cards; empty skips them
+	} `toml:"gpu"`
+
+	Container string `toml:"container"` // "auto" (default) scopes CPU and memory to the cgroup inside a container; "on" always, "off" never
+
+	WSLTemperature bool `toml:"wsl_temperature"` // Under WSL, read the host's CPU temperature through powershell.exe when Linux has none
+
+	ThermalLimit float64 `toml:"thermal_limit"` // °C limit for the headroom display when the sensor reports none; overrides sysfs
+
+	// Per-sensor corrections keyed by sensor id, e.g. [calibration."k10temp/Tctl"]
+	Calibration map[string]sensorCalibration `toml:"calibration"`
+
+	PollInterval time.Duration `toml:"poll_interval"` // Time between samples, 100ms to 5s
+	TimeScale    time.Duration `toml:"time_scale"`    // Graph window at startup, 15s to 24h
+
+	Keys        map[string]string `toml:"keys"` // Key per action, e.g. zoom_in = "+"; unset actions keep their default key
+	KeyBindings keyBindings       `toml:"-"`    // Parsed form of Keys
+
+	Accessible       bool          `toml:"accessible"`        // Announce plain-text status lines instead of drawing the UI
+	ReadOnly         bool          `toml:"read_only"`         // Ignore the keys that start stress runs or change settings, for sharing a live view
+	StartupChecks    bool          `toml:"startup_checks"`    // Check the CPU statistics, temperature, stress engine and RAPL at launch and list what is degraded
+	AnnounceInterval time.Duration `toml:"announce_interval"` // Time between accessible-mode announcements
+
+	CoreViewName      string   `toml:"core_view"`           // "grid" (default up to 64 cores), "vertical", or "heatmap"
+	CoreView          coreView `toml:"-"`                   // Parsed form of CoreViewName
+	VerticalBarHeight int      `toml:"vertical_bar_height"` // Rows per column in the vertical core view
+
+	GraphModeName       string    `toml:"graph_mode"`            // "combined" (default), "stacked", "dual", "temps", "power", "latency", "network", "psu", or "clock"
+	GraphMode           graphMode `toml:"-"`                     // Parsed form of GraphModeName
+	NetworkCapacityMbps float64   `toml:"network_capacity_mbps"` // Link capacity for the network series when sysfs reports none
+
+	Split struct {
+		Enabled    bool          `toml:"enabled"`     // Start in the split view
+		Left       string        `toml:"left"`        // Graph of the left pane: "cpu", "temp", "power", "memory", "gpu", "disk", "net", "psu", or "clock"
+		LeftScale  time.Duration `toml:"left_scale"`  // Time span of the left pane, 15s to 24h
+		Right      string        `toml:"right"`       // Graph of the right pane
+		RightScale time.Duration `toml:"right_scale"` // Time span of the right pane
+	} `toml:"split"`
+
+	GraphStyle struct {
+		CPU     string `toml:"cpu"`      // CPU series in the combined graph
+		DualCPU string `toml:"dual_cpu"` // CPU bars in the dual-axis graph
+		Temp    string `toml:"temp"`     // Temperature line in the dual-axis graph
+	} `toml:"graph_style"`
+	CPUGraphStyle     graphStyle `toml:"-"` // Parsed forms of GraphStyle
+	DualCPUGraphStyle graphStyle `toml:"-"`
+	TempGraphStyle    graphStyle `toml:"-"`
+
+	GraphColor struct {
+		CPU     string `toml:"cpu"`      // What colors the CPU series in the combined graph: "temp", "usage", "power", or a fixed color
+		DualCPU string `toml:"dual_cpu"` // CPU bars in the dual-axis graph
+		Temp    string `toml:"temp"`     // Temperature line in the dual-axis graph
+	} `toml:"graph_color"`
+	CPUGraphColor     graphColor `toml:"-"` // Parsed forms of GraphColor
+	DualCPUGraphColor graphColor `toml:"-"`
+	TempGraphColor    graphColor `toml:"-"`
+
+	GraphScale struct {
+		CPU     string `toml:"cpu"`     // CPU usage in the combined and dual-axis graphs: "auto", "fixed", or "log"
+		Network string `toml:"network"` // Network stress graph: "auto", "fixed", or "log"
+		Split   string `toml:"split"`   // Percentages and power in the split view panes: "auto", "fixed", or "log"
+		Power   string `toml:"power"`   // Power graph: "auto" or "log"
+		PSU     string `toml:"psu"`     // PSU power graph: "auto" or "log"
+		Latency string `toml:"latency"` // Wakeup latency graph: "auto" or "log"
+	} `toml:"graph_scale"`
+	CPUGraphScale     graphScale `toml:"-"` // Parsed forms of GraphScale
+	NetworkGraphScale graphScale `toml:"-"`
+	SplitGraphScale   graphScale `toml:"-"`
+	PowerGraphScale   graphScale `toml:"-"`
+	PSUGraphScale     graphScale `toml:"-"`
+	LatencyGraphScale graphScale `toml:"-"`
+
+	Prometheus struct {
+		Textfile string        `toml:"textfile"` // node_exporter textfile collector output (.prom)
+		Interval time.Duration `toml:"interval"` // How often the file is rewritten
+	} `toml:"prometheus"`
+
+	Telegraf struct {
+		Signal   string        `toml:"signal"`   // The signal setting of the execd input: "STDIN", "SIGHUP", "SIGUSR1", "SIGUSR2", or "none"
+		Interval time.Duration `toml:"interval"` // execd output interval for signal = "none"; 0 waits for Telegraf's signal
+	} `toml:"telegraf"`
+
+	Zabbix struct {
+		Server   string        `toml:"server"`   // Zabbix server or proxy, "host[:port]"; empty disables the sink
+		Host     string        `toml:"host"`     // Host name as configured in Zabbix (default: hostname)
+		Interval time.Duration `toml:"interval"` // How often values are sent
+		Keys     struct {
+			CPU    string `toml:"cpu"`
+			Temp   string `toml:"temp"`
+			GPU    string `toml:"gpu"`
+			Disk   string `toml:"disk"`
+			Net    string `toml:"net"`
+			Stress string `toml:"stress"`
+		} `toml:"keys"` // Trapper item keys; an empty key is not sent
+	} `toml:"zabbix"`
+
+	SNMP struct {
+		BaseOID  string        `toml:"base_oid"` // Subtree served by the snmp subcommand
+		Interval time.Duration `toml:"interval"` // How often the served values are refreshed
+	} `toml:"snmp"`
+
+	MQTT struct {
+		Broker          string        `toml:"broker"` // "host[:port]"; empty disables MQTT
+		Username        string        `toml:"username"`
+		Password        string        `toml:"password"`
+		TopicPrefix     string        `toml:"topic_prefix"`     // State topics are <prefix>/<node>/...
+		NodeID          string        `toml:"node_id"`          // Defaults to the hostname
+		Interval        time.Duration `toml:"interval"`         // How often state is published
+		Discovery       bool          `toml:"discovery"`        // Publish Home Assistant discovery payloads
+		DiscoveryPrefix string        `toml:"discovery_prefix"` // Home Assistant's discovery prefix
+	} `toml:"mqtt"`
+
+	HTTP struct {
+		Listen string `toml:"listen"` // Address for /metrics and /debug endpoints, e.g. "127.0.0.1:9101"; empty disables
+		Token  string `toml:"token"`  // Bearer token every request must carry; empty serves anyone who can connect
+	} `toml:"http"`
+
+	Web struct {
+		Listen string `toml:"listen"` // Address of the browser dashboard, e.g. "0.0.0.0:8080"; empty disables
+		Token  string `toml:"token"`  // Token every request must carry, as for [http]
+	} `toml:"web"`
+
+	Fleet struct {
+		Agents   []string      `toml:"agents"`   // "host:port" of the agents kkperf fleet polls when given none
+		Interval time.Duration `toml:"interval"` // Time between polls of the fleet table
+		Token    string        `toml:"token"`    // Bearer token sent to agents that set [http] token
+	} `toml:"fleet"`
+
+	Socket struct {
+		Path string `toml:"path"` // UNIX domain socket answering GET and SUBSCRIBE requests; empty disables
+	} `toml:"socket"`
+
+	Helper struct {
+		Socket string `toml:"socket"` // Privilege helper for root-only sources (kkperf helper); empty disables
+	} `toml:"helper"`
+
+	FIFO struct {
+		Path     string `toml:"path"`     // Named pipe every poll is written to as one line; empty disables
+		Template string `toml:"template"` // --format template for the line; empty writes key=value pairs
+	} `toml:"fifo"`
+
+	CSV struct {
+		Path string `toml:"path"` // File every poll is appended to as a CSV row; empty disables
+	} `toml:"csv"`
+
+	Record struct {
+		Path string `toml:"path"` // asciinema cast the TUI is recorded to; empty disables
+		FPS  int    `toml:"fps"`  // Frames per second kept in the recording, 1 to 60
+	} `toml:"record"`
+
+	Debug struct {
+		File  string `toml:"file"`  // Log written with --debug
+		Level string `toml:"level"` // Lowest level logged: "debug", "info", "warn", or "error"
+	} `toml:"debug"`
+
+	History struct {
+		Enabled   bool          `toml:"enabled"`   // Record per-minute statistics to disk
+		Dir       string        `toml:"dir"`       // Defaults to ~/.local/share/kkperf/history
+		Retention time.Duration `toml:"retention"` // Older daily files are deleted at startup; 0 keeps everything
+	} `toml:"history"`
+
+	Iperf3 struct {
+		Target   string `toml:"target"`   // iperf3 server for network stress; empty disables it
+		Port     int    `toml:"port"`     // Server port
+		Parallel int    `toml:"parallel"` // Parallel client streams (-P)
+		Reverse  bool   `toml:"reverse"`  // Server sends, client receives (-R)
+	} `toml:"iperf3"`
+
+	Fio struct {
+		Job     string        `toml:"job"`     // "seqread", "seqwrite", "randread", or "randwrite"
+		Dir     string        `toml:"dir"`     // Directory for the test file; must not be tmpfs (direct I/O)
+		Size    string        `toml:"size"`    // Test file size in fio notation, e.g. "1G"
+		Runtime time.Duration `toml:"runtime"` // Stop after this long; 0 runs until toggled off
+	} `toml:"fio"`
+
+	Latency struct {
+		Interval time.Duration `toml:"interval"` // Wakeup period of the latency probe
+	} `toml:"latency"`
+
+	Resctrl struct {
+		Processes []string `toml:"processes"` // Command names given their own monitoring group while the bandwidth page is open
+	} `toml:"resctrl"`
+
+	Report struct {
+		Schedule string       `toml:"schedule"` // "daily", "weekly", or empty for no scheduled reports
+		At       string       `toml:"at"`       // Local time of day the report is generated ("HH:MM")
+		Day      string       `toml:"day"`      // Weekday for weekly reports
+		Weekday  time.Weekday `toml:"-"`        // Parsed form of Day
+		Dir      string       `toml:"dir"`      // Directory reports are written to
+		HotTemp  float64      `toml:"hot_temp"` // Minutes peaking at or above this temperature (°C) are counted; 0 disables
+		Email    struct {
+			Server   string   `toml:"server"` // SMTP server, "host:port"
+			From     string   `toml:"from"`
+			To       []string `toml:"to"` // Recipients; empty disables email
+			Username string   `toml:"username"`
+			Password string   `toml:"password"`
+		} `toml:"email"`
+	} `toml:"report"`
+
+	Ambient struct {
+		File     string        `toml:"file"`     // File holding the room temperature, e.g. a 1-Wire w1_slave
+		URL      string        `toml:"url"`      // HTTP endpoint returning the room temperature
+		Command  string        `toml:"command"`  // Shell command printing the room temperature, e.g. a USB thermometer tool
+		Pattern  string        `toml:"pattern"`  // Regular expression for the value; its first group is used when it has one. Default: the first number
+		Unit     string        `toml:"unit"`     // Unit of the value: "C" (default), "F", or "mC" (millidegrees)
+		Interval time.Duration `toml:"interval"` // How often the source is read
+	} `toml:"ambient"`
+
+	Cooling struct {
+		MinPumpRPM     float64 `toml:"min_pump_rpm"`     // Pump failure below this speed; 0 disables
+		MinFlow        float64 `toml:"min_flow"`         // Low flow below this rate in L/h; 0 disables
+		MaxCoolantTemp float64 `toml:"max_coolant_temp"` // Alert at this coolant temperature (°C); 0 disables
+	} `toml:"cooling"`
+
+	Pause struct {
+		SuspendPolling bool `toml:"suspend_polling"` // Stop polling while paused, and restart the graphs empty on resume
+	} `toml:"pause"`
+
+	Battery struct {
+		WarnWatts float64 `toml:"warn_watts"` // Discharge rate in W shown in red, e.g. under the stress test; 0 disables
+	} `toml:"battery"`
+
+	Stress struct {
+		Backend  string        `toml:"backend"`  // "native" (built-in engine) or "stress" (the external command)
+		Pattern  string        `toml:"pattern"`  // Built-in workload: "int", "fpu", "memory", or "mixed"
+		Workers  int           `toml:"workers"`  // Built-in workers; 0 runs one per CPU
+		Duration time.Duration `toml:"duration"` // Length of a timed run (K)
+	} `toml:"stress"`
+
+	Baseline struct {
+		Startup  bool          `toml:"startup"`  // Measure the idle baseline when the monitor starts
+		Duration time.Duration `toml:"duration"` // How long the baseline is measured
+	} `toml:"baseline"`
+
+	Safety struct {
+		MaxTemp     float64       `toml:"max_temp"`     // Stop stress at this temperature (°C); 0 disables
+		ThrottleFor time.Duration `toml:"throttle_for"` // Stop stress when throttling lasts this long; 0 disables
+		Cooldown    time.Duration `toml:"cooldown"`     // Stress cannot be restarted for this long after a safety stop
+		Log         string        `toml:"log"`          // Safety events are appended here; defaults to ~/.local/share/kkperf/safety.log
+	} `toml:"safety"`
+
+	Emergency struct {
+		Action  string        `toml:"action"`  // "suspend", "shutdown", or "command"; empty disables
+		Temp    float64       `toml:"temp"`    // Critical temperature (°C)
+		For     time.Duration `toml:"for"`     // How long the temperature must stay at or above temp
+		Command string        `toml:"command"` // Shell command for action = "command"; KKPERF_TEMP holds the temperature
+	} `toml:"emergency"`
+
+	Health struct {
+		MaxZombies int     `toml:"max_zombies"` // Warn at this many zombie processes; 0 disables
+		MaxThreads float64 `toml:"max_threads"` // Warn when threads reach this share of the limit (%); 0 disables
+		MaxFDs     float64 `toml:"max_fds"`     // Warn when open files reach this share of fs.file-max (%); 0 disables
+	} `toml:"health"`
+
+	Alerts struct {
+		Log     string      `toml:"log"`     // Raised and cleared alerts are appended here; defaults to ~/.local/share/kkperf/alerts.log
+		Webhook string      `toml:"webhook"` // URL every raised and cleared alert is POSTed to as JSON; empty disables
+		Command string      `toml:"command"` // Shell command run on every raised and cleared alert; KKPERF_ALERT_* describe it
+		Rules   []alertRule `toml:"rule"`    // [[alerts.rule]] tables, each a condition and how long it must hold
+	} `toml:"alerts"`
+
+	Certify struct {
+		Phases        []string      `toml:"phases"`         // Load phases in order: "cpu", "memory", "disk", "gpu"
+		PhaseDuration time.Duration `toml:"phase_duration"` // Length of each phase
+		MemoryPercent int           `toml:"memory_percent"` // Share of available memory the memory phase allocates
+		GPUCommand    string        `toml:"gpu_command"`    // Shell command loading the GPU, e.g. "gpu_burn 600"; empty skips the phase
+		MaxTemp       float64       `toml:"max_temp"`       // A phase fails and stops at this temperature (°C); 0 disables
+		Dir           string        `toml:"dir"`            // Directory reports are written to
+		SigningKey    string        `toml:"signing_key"`    // Ed25519 key (PKCS#8 PEM) signing reports; created when missing, certify.key next to the config by default
+	} `toml:"certify"`
+
+	path string // File the config was loaded from, for saving picker choices
+}
+
+// sensorCalibration corrects a sensor's readings: shown = raw*scale + offset.
+type sensorCalibration struct {
+	Offset float64 `toml:"offset"` // °C added after scaling, e.g. -10 for Ryzen Tctl
+	Scale  float64 `toml:"scale"`  // Multiplier; 0 or unset means 1
+}
+
+// calibrate applies the configured correction for sensor id to a raw
+// reading in °C.
+func (cfg *Config) calibrate(id string, raw float64) float64 {
+	c, ok := cfg.Calibration[id]
+	if !ok {
+		return raw
+	}
+	if c.Scale != 0 {
+		raw *= c.Scale
+	}
+	return raw + c.Offset
+}
+
+// weekdays maps lowercase day names to time.Weekday for config parsing.
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// defaultConfig returns the settings used when no config file is present.
+func defaultConfig() *Config {
+	cfg := &Config{
+		TemperatureUnit:     "C",
+		AnnounceInterval:    10 * time.Second,
+		PollInterval:        defaultPollInterval,
+		TimeScale:           defaultWindow,
+		VerticalBarHeight:   8,
+		NetworkCapacityMbps: 1000,
+	}
+	cfg.Sensors.MinValid = -40
+	cfg.Sensors.MaxValid = 150
+	cfg.Split.Left = "cpu"
+	cfg.Split.LeftScale = 30 * time.Second
+	cfg.Split.Right = "power"
+	cfg.Split.RightScale = 5 * time.Minute
+	cfg.GraphStyle.CPU = "blocks"
+	cfg.GraphStyle.DualCPU = "filled"
+	cfg.GraphStyle.Temp = "points"
+	cfg.GraphColor.CPU = "temp"
+	cfg.GraphColor.DualCPU = "usage"
+	cfg.GraphColor.Temp = "temp"
+	cfg.GraphScale.CPU = "fixed"
+	cfg.GraphScale.Network = "fixed"
+	cfg.GraphScale.Split = "fixed"
+	cfg.GraphScale.Power = "auto"
+	cfg.GraphScale.PSU = "auto"
+	cfg.GraphScale.Latency = "auto"
+	cfg.Helper.Socket = defaultHelperSocket
+	cfg.Prometheus.Interval = 15 * time.Second
+	cfg.Telegraf.Signal = "STDIN"
+	cfg.SNMP.BaseOID = defaultSNMPBaseOID
+	cfg.Fleet.Interval = 2 * time.Second
+	cfg.Record.FPS = 10
+	cfg.Debug.File = defaultDebugFile()
+	cfg.Debug.Level = "debug"
+	cfg.SNMP.Interval = 5 * time.Second
+	cfg.Zabbix.Interval = 60 * time.Second
+	cfg.Zabbix.Keys.CPU = "kkperf.cpu"
+	cfg.Zabbix.Keys.Temp = "kkperf.temp"
+	cfg.Zabbix.Keys.GPU = "kkperf.gpu"
+	cfg.Zabbix.Keys.Disk = "kkperf.disk"
+	cfg.Zabbix.Keys.Net = "kkperf.net"
+	cfg.Zabbix.Keys.Stress = "kkperf.stress"
+	cfg.MQTT.Interval = 10 * time.Second
+	cfg.MQTT.TopicPrefix = "kkperf"
+	cfg.MQTT.Discovery = true
+	cfg.MQTT.DiscoveryPrefix = "homeassistant"
+	cfg.History.Dir = defaultHistoryDir()
+	cfg.History.Retention = 90 * 24 * time.Hour
+	cfg.Report.At = "08:00"
+	cfg.Report.Day = "monday"
+	cfg.Report.HotTemp = 85
+	cfg.Latency.Interval = time.Millisecond
+	cfg.Iperf3.Port = 5201
+	cfg.Iperf3.Parallel = 1
+	cfg.Fio.Job = "randread"
+	cfg.Fio.Dir = "/var/tmp"
+	cfg.Fio.Size = "1G"
+	cfg.Ambient.Unit = "C"
+	cfg.Ambient.Interval = 30 * time.Second
+	cfg.Cooling.MinPumpRPM = 500
+	cfg.Battery.WarnWatts = 25
+	cfg.Cooling.MinFlow = 10
+	cfg.Stress.Backend = "native"
+	cfg.Container = "auto"
+	cfg.GPU.Panel = true
+	cfg.PhaseLabels = true
+	cfg.StartupChecks = true
+	cfg.GPU.NvidiaSMI = "nvidia-smi"
+	cfg.WSLTemperature = true
+	cfg.TopProcesses = 8
+	cfg.Stress.Pattern = "int"
+	cfg.Stress.Duration = 10 * time.Minute
+	cfg.Baseline.Startup = true
+	cfg.Baseline.Duration = 10 * time.Second
+	cfg.Safety.MaxTemp = 95
+	cfg.Safety.Cooldown = 5 * time.Minute
+	cfg.Emergency.Temp = 100
+	cfg.Health.MaxZombies = 20
+	cfg.Health.MaxThreads = 80
+	cfg.Health.MaxFDs = 80
+	cfg.Emergency.For = 30 * time.Second
+	cfg.Certify.Phases = []string{"cpu", "memory", "disk", "gpu"}
+	cfg.Certify.PhaseDuration = 10 * time.Minute
+	cfg.Certify.MemoryPercent = 80
+	cfg.Certify.MaxTemp = 95
+	return cfg
+}
+
+// configPath returns the location of the user's config file,
+// honoring XDG_CONFIG_HOME when it is set.
+func configPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "kkperf", "config.toml")
+}
+
+// loadConfig reads and parses the config file at path on top of the
+// defaults. A missing file is not an error.
+func loadConfig(path string) (*Config, error) {
+	cfg := defaultConfig()
+	cfg.path = path
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			table, err := toml.Parse(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			if err := toml.Decode(table, cfg); err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// validate checks settings for sane values and fills in the parsed forms
+// of enumerated settings (core view, graph mode, graph styles).
+func (cfg *Config) validate() error {
+	switch cfg.TemperatureUnit {
+	case "C", "c", "":
+		cfg.TemperatureUnit = "C"
+	case "F", "f":
+		cfg.TemperatureUnit = "F"
+	default:
+		return fmt.Errorf("temperature_unit must be \"C\" or \"F\"")
+	}
+
+	if cfg.AnnounceInterval < time.Second {
+		return fmt.Errorf("announce_interval must be at least 1s")
+	}
+	if cfg.PollInterval < 100*time.Millisecond || cfg.PollInterval > 5*time.Second {
+		return fmt.Errorf("poll_interval must be between 100ms and 5s")
+	}
+	if cfg.TimeScale < minWindow || cfg.TimeScale > maxWindow {
+		return fmt.Errorf("time_scale must be between 15s and 24h")
+	}
+	bindings, err := newKeyBindings(cfg.Keys)
+	if err != nil {
+		return err
+	}
+	cfg.KeyBindings = bindings
+
+	view, ok := parseCoreView(cfg.CoreViewName)
+	if !ok {
+		return fmt.Errorf("core_view must be \"grid\", \"vertical\", or \"heatmap\"")
+	}
+	cfg.CoreView = view
+	if cfg.VerticalBarHeight < 1 || cfg.VerticalBarHeight > 32 {
+		return fmt.Errorf("vertical_bar_height must be between 1 and 32")
+	}
+
+	mode, ok := parseGraphMode(cfg.GraphModeName)
+	if !ok {
+		return fmt.Errorf("graph_mode must be \"combined\", \"stacked\", \"dual\", \"temps\", \"power\", \"latency\", \"network\", \"psu\", or \"clock\"")
+	}
+	cfg.GraphMode = mode
+	for _, name := range []string{cfg.Split.Left, cfg.Split.Right} {
+		if _, ok := splitMetricIndex(name); !ok {
+			return fmt.Errorf("split.left and split.right must be \"cpu\", \"temp\", \"power\", \"memory\", \"gpu\", \"disk\", \"net\", or \"psu\"")
+		}
+	}
+	for _, window := range []time.Duration{cfg.Split.LeftScale, cfg.Split.RightScale} {
+		if window < minWindow || window > maxWindow {
+			return fmt.Errorf("split.left_scale and split.right_scale must be between 15s and 24h")
+		}
+	}
+	if cfg.NetworkCapacityMbps <= 0 {
+		return fmt.Errorf("network_capacity_mbps must be positive")
+	}
+
+	for _, style := range []struct {
+		name   string
+		value  string
+		parsed *graphStyle
+	}{
+		{"cpu", cfg.GraphStyle.CPU, &cfg.CPUGraphStyle},
+		{"dual_cpu", cfg.GraphStyle.DualCPU, &cfg.DualCPUGraphStyle},
+		{"temp", cfg.GraphStyle.Temp, &cfg.TempGraphStyle},
+	} {
+		parsed, ok := graphStyleNames[style.value]
+		if !ok {
+			return fmt.Errorf("graph_style.%s must be blocks, filled, line, step, or points", style.name)
+		}
+		*style.parsed = parsed
+	}
+	for _, color := range []struct {
+		name   string
+		value  string
+		parsed *graphColor
+	}{
+		{"cpu", cfg.GraphColor.CPU, &cfg.CPUGraphColor},
+		{"dual_cpu", cfg.GraphColor.DualCPU, &cfg.DualCPUGraphColor},
+		{"temp", cfg.GraphColor.Temp, &cfg.TempGraphColor},
+	} {
+		parsed, err := parseGraphColor(color.value)
+		if err != nil {
+			return fmt.Errorf("graph_color.%s %v", color.name, err)
+		}
+		*color.parsed = parsed
+	}
+	for _, scale := range []struct {
+		name   string
+		value  string
+		parsed *graphScale
+		fixed  bool // Whether the metric has a full range to fix the axis to
+	}{
+		{"cpu", cfg.GraphScale.CPU, &cfg.CPUGraphScale, true},
+		{"network", cfg.GraphScale.Network, &cfg.NetworkGraphScale, true},
+		{"split", cfg.GraphScale.Split, &cfg.SplitGraphScale, true},
+		{"power", cfg.GraphScale.Power, &cfg.PowerGraphScale, false},
+		{"psu", cfg.GraphScale.PSU, &cfg.PSUGraphScale, false},
+		{"latency", cfg.GraphScale.Latency, &cfg.LatencyGraphScale, false},
+	} {
+		parsed, ok := graphScaleNames[scale.value]
+		switch {
+		case scale.fixed && !ok:
+			return fmt.Errorf("graph_scale.%s must be auto, fixed, or log", scale.name)
+		case !scale.fixed && (!ok || parsed == scaleFixed):
+			return fmt.Errorf("graph_scale.%s must be auto or log", scale.name)
+		}
+		*scale.parsed = parsed
+	}
+
+	for _, pattern := range append(append([]string{}, cfg.Sensors.Allow...), cfg.Sensors.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("sensors: invalid pattern %q", pattern)
+		}
+	}
+	if cfg.Sensors.MinValid >= cfg.Sensors.MaxValid {
+		return fmt.Errorf("sensors.min_valid must be below sensors.max_valid")
+	}
+
+	if cfg.ThermalLimit < 0 {
+		return fmt.Errorf("thermal_limit must not be negative")
+	}
+
+	sources := 0
+	for _, source := range []string{cfg.Ambient.File, cfg.Ambient.URL, cfg.Ambient.Command} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("ambient: set only one of file, url, and command")
+	}
+	if _, err := regexp.Compile(cfg.Ambient.Pattern); err != nil {
+		return fmt.Errorf("ambient.pattern: %v", err)
+	}
+	if cfg.Ambient.Unit != "C" && cfg.Ambient.Unit != "F" && cfg.Ambient.Unit != "mC" {
+		return fmt.Errorf("ambient.unit must be \"C\", \"F\", or \"mC\"")
+	}
+	if cfg.Record.FPS < 1 || cfg.Record.FPS > 60 {
+		return fmt.Errorf("record.fps must be between 1 and 60")
+	}
+	if cfg.Debug.File == "" {
+		cfg.Debug.File = defaultDebugFile()
+	}
+	if _, ok := logLevelNames[cfg.Debug.Level]; !ok {
+		return fmt.Errorf("debug.level must be \"debug\", \"info\", \"warn\", or \"error\"")
+	}
+	if cfg.Fleet.Interval < time.Second {
+		return fmt.Errorf("fleet.interval must be at least 1s")
+	}
+	if cfg.Ambient.Interval < time.Second {
+		return fmt.Errorf("ambient.interval must be at least 1s")
+	}
+	if cfg.Battery.WarnWatts < 0 {
+		return fmt.Errorf("battery.warn_watts must not be negative")
+	}
+	if cfg.Cooling.MinPumpRPM < 0 || cfg.Cooling.MinFlow < 0 || cfg.Cooling.MaxCoolantTemp < 0 {
+		return fmt.Errorf("cooling limits must not be negative")
+	}
+	if cfg.TopProcesses < 1 || cfg.TopProcesses > 50 {
+		return fmt.Errorf("top_processes must be between 1 and 50")
+	}
+	if cfg.Container != "auto" && cfg.Container != "on" && cfg.Container != "off" {
+		return fmt.Errorf("container must be \"auto\", \"on\", or \"off\"")
+	}
+	if cfg.Stress.Backend != "native" && cfg.Stress.Backend != "stress" {
+		return fmt.Errorf("stress.backend must be \"native\" or \"stress\"")
+	}
+	if !stressPatterns[cfg.Stress.Pattern] {
+		return fmt.Errorf("stress.pattern must be \"int\", \"fpu\", \"memory\", or \"mixed\"")
+	}
+	if cfg.Stress.Workers < 0 {
+		return fmt.Errorf("stress.workers must not be negative")
+	}
+	if cfg.Stress.Duration <= 0 {
+		return fmt.Errorf("stress.duration must be positive")
+	}
+	if cfg.Baseline.Duration <= 0 {
+		return fmt.Errorf("baseline.duration must be positive")
+	}
+	if cfg.Safety.MaxTemp < 0 || cfg.Safety.ThrottleFor < 0 || cfg.Safety.Cooldown < 0 {
+		return fmt.Errorf("safety limits must not be negative")
+	}
+	switch cfg.Emergency.Action {
+	case "", "suspend", "shutdown":
+	case "command":
+		if cfg.Emergency.Command == "" {
+			return fmt.Errorf("emergency.command must be set for action = \"command\"")
+		}
+	default:
+		return fmt.Errorf("emergency.action must be \"suspend\", \"shutdown\", or \"command\"")
+	}
+	if cfg.Emergency.Action != "" && (cfg.Emergency.Temp <= 0 || cfg.Emergency.For < 0) {
+		return fmt.Errorf("emergency.temp must be positive and emergency.for must not be negative")
+	}
+
+	if cfg.Health.MaxZombies < 0 || cfg.Health.MaxThreads < 0 || cfg.Health.MaxFDs < 0 {
+		return fmt.Errorf("health limits must not be negative")
+	}
+	if cfg.Alerts.Webhook != "" {
+		u, err := url.Parse(cfg.Alerts.Webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("alerts.webhook must be an http or https URL")
+		}
+	}
+	for i := range cfg.Alerts.Rules {
+		r := &cfg.Alerts.Rules[i]
+		if err := r.parse(); err != nil {
+			return fmt.Errorf("alerts.rule %q: %v", r.When, err)
+		}
+		if r.For < 0 {
+			return fmt.Errorf("alerts.rule %q: for must not be negative", r.When)
+		}
+	}
+
+	if cfg.Prometheus.Textfile != "" && !strings.HasSuffix(cfg.Prometheus.Textfile, ".prom") {
+		return fmt.Errorf("prometheus.textfile must end in .prom for node_exporter to read it")
+	}
+
+	if cfg.Telegraf.Interval < 0 {
+		return fmt.Errorf("telegraf.interval must not be negative")
+	}
+	switch cfg.Telegraf.Signal {
+	case "STDIN", "SIGHUP", "SIGUSR1", "SIGUSR2":
+	case "none":
+		// Telegraf asks for nothing, so only the schedule prints samples
+		if cfg.Telegraf.Interval == 0 {
+			return fmt.Errorf("telegraf.interval must be set for signal = \"none\", or execd mode prints nothing")
+		}
+	default:
+		return fmt.Errorf("telegraf.signal must be \"STDIN\", \"SIGHUP\", \"SIGUSR1\", \"SIGUSR2\", or \"none\"")
+	}
+
+	if cfg.Zabbix.Server != "" {
+		if cfg.Zabbix.Host == "" {
+			host, err := os.Hostname()
+			if err != nil {
+				return fmt.Errorf("zabbix.host must be set: %v", err)
+			}
+			cfg.Zabbix.Host = host
+		}
+		if cfg.Zabbix.Interval < time.Second {
+			return fmt.Errorf("zabbix.interval must be at least 1s")
+		}
+	}
+
+	if cfg.SNMP.Interval < time.Second {
+		return fmt.Errorf("snmp.interval must be at least 1s")
+	}
+
+	for _, name := range cfg.Resctrl.Processes {
+		if !resctrlNamePattern.MatchString(name) {
+			return fmt.Errorf("resctrl.processes: %q must be letters, digits, '_' and '-' only", name)
+		}
+	}
+
+	if _, ok := fioJobs[cfg.Fio.Job]; !ok {
+		return fmt.Errorf("fio.job must be \"seqread\", \"seqwrite\", \"randread\", or \"randwrite\"")
+	}
+	if cfg.Fio.Size == "" {
+		return fmt.Errorf("fio.size must not be empty")
+	}
+
+	for _, name := range cfg.Certify.Phases {
+		if !validCertPhase(name) {
+			return fmt.Errorf("certify.phases entries must be \"cpu\", \"memory\", \"disk\", or \"gpu\"")
+		}
+	}
+	if cfg.Certify.PhaseDuration < 10*time.Second {
+		return fmt.Errorf("certify.phase_duration must be at least 10s")
+	}
+	if cfg.Certify.MemoryPercent < 1 || cfg.Certify.MemoryPercent > 95 {
+		return fmt.Errorf("certify.memory_percent must be between 1 and 95")
+	}
+
+	if cfg.Iperf3.Port < 1 || cfg.Iperf3.Port > 65535 {
+		return fmt.Errorf("iperf3.port must be between 1 and 65535")
+	}
+	if cfg.Iperf3.Parallel < 1 || cfg.Iperf3.Parallel > 128 {
+		return fmt.Errorf("iperf3.parallel must be between 1 and 128")
+	}
+
+	if cfg.MQTT.Broker != "" && cfg.MQTT.Interval < time.Second {
+		return fmt.Errorf("mqtt.interval must be at least 1s")
+	}
+
+	if cfg.Report.Schedule != "" {
+		if cfg.Report.Schedule != "daily" && cfg.Report.Schedule != "weekly" {
+			return fmt.Errorf("report.schedule must be \"daily\" or \"weekly\"")
+		}
+		if _, err := time.Parse("15:04", cfg.Report.At); err != nil {
+			return fmt.Errorf("report.at must be a time of day such as \"08:00\"")
+		}
+		day, ok := weekdays[strings.ToLower(cfg.Report.Day)]
+		if !ok {
+			return fmt.Errorf("report.day must be a weekday name")
+		}
+		cfg.Report.Weekday = day
+		if cfg.Report.Dir == "" && len(cfg.Report.Email.To) == 0 {
+			return fmt.Errorf("report.dir or report.email.to must be set for scheduled reports")
+		}
+		if len(cfg.Report.Email.To) > 0 && (cfg.Report.Email.Server == "" || cfg.Report.Email.From == "") {
+			return fmt.Errorf("report.email.server and report.email.from must be set to email reports")
+		}
+		// Reports are built from the history store
+		cfg.History.Enabled = true
+	}
+	if cfg.History.Enabled && cfg.History.Dir == "" {
+		return fmt.Errorf("history.dir must be set")
+	}
+
+	return nil
+}
+
+// saveConfigValues sets top-level keys in the config file to the given
+// TOML literals, keeping comments and the rest of the file's layout. Keys
+// that are not present yet are added before the first table. The new file
+// replaces the old one in a single rename, so a crash or a full disk
+// cannot leave a half-written config behind.
+func saveConfigValues(path string, values map[string]string) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target // Replace the file, not a symlink to it
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+
+	// Top-level keys end at the first table header
+	end := len(lines)
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			end = i
+			break
+		}
+	}
+
+	done := map[string]bool{}
+	for i := 0; i < end; i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		eq := strings.Index(trimmed, "=")
+		if eq < 0 || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key := strings.TrimSpace(trimmed[:eq])
+		if value, ok := values[key]; ok {
+			lines[i] = key + " = " + value
+			done[key] = true
+		}
+	}
+
+	var added []string
+	for key, value := range values {
+		if !done[key] {
+			added = append(added, key+" = "+value)
+		}
+	}
+	sort.Strings(added)
+
+	// Insert after the last top-level line, keeping the blank line that
+	// usually separates it from the first table
+	at := end
+	for at > 0 && strings.TrimSpace(lines[at-1]) == "" {
+		at--
+	}
+	if len(added) > 0 && at == end && end < len(lines) {
+		added = append(added, "")
+	}
+	lines = append(lines[:at], append(added, lines[at:]...)...)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".kkperf-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// tomlQuote returns s as a TOML basic string.
+func tomlQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
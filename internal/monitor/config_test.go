@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSaveConfigValues checks that saving keys keeps the rest of the file
+// and its mode, replaces the file a symlink points to, and that a failed
+// save of the sensor picker shows on the status line.
+func TestSaveConfigValues(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.toml")
+	ioutil.WriteFile(target, []byte("# Bench\nsensor = \"old\"\n\n[safety]\nenabled = true\n"), 0600)
+	path := filepath.Join(dir, "config.toml")
+	if err := os.Symlink(target, path); err != nil {
+		t.Skip(err)
+	}
+
+	if err := saveConfigValues(path, map[string]string{"sensor": `"new"`, "secondary_sensors": "[]"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadFile(target)
+	want := "# Bench\nsensor = \"new\"\nsecondary_sensors = []\n\n[safety]\nenabled = true\n"
+	if string(data) != want {
+		t.Errorf("saved config:\n%s\nwant:\n%s", data, want)
+	}
+	if info, err := os.Lstat(path); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("symlink replaced by the saved file")
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0600 {
+		t.Errorf("mode %v after saving; want -rw-------", info.Mode().Perm())
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(dir, ".kkperf-*")); len(tmp) > 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+
+	m, _, _ := fixtureMonitor(t, "4cores", nil, nil)
+	m.out = newScreenBuffer(goldenWidth, goldenHeight)
+	m.cfg.path = filepath.Join(target, "config.toml") // Under a file, so it cannot be written
+	m.showSensors, m.sensorsChanged = true, true
+	m.closeSensorPicker()
+	if status := m.sensorSaveStatus(); !strings.Contains(status, "SENSORS NOT SAVED") {
+		t.Errorf("status line tag = %q; want the failed save", status)
+	}
+}
+
+// TestTelegrafSignal checks that execd mode cannot be configured to wait
+// for requests Telegraf never sends.
+func TestTelegrafSignal(t *testing.T) {
+	for _, tc := range []struct {
+		signal   string
+		interval time.Duration
+		ok       bool
+	}{
+		{"STDIN", 0, true},
+		{"SIGUSR1", 0, true},
+		{"none", 0, false},
+		{"none", 10 * time.Second, true},
+		{"stdin", 0, false},
+	} {
+		cfg := defaultConfig()
+		cfg.Telegraf.Signal, cfg.Telegraf.Interval = tc.signal, tc.interval
+		if err := cfg.validate(); (err == nil) != tc.ok {
+			t.Errorf("signal %q, interval %v: %v; want valid %v", tc.signal, tc.interval, err, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Root of the cgroup hierarchy and of the marker files container runtimes
+// leave behind. Tests point them at fixture trees.
+var (
+	cgroupDir     = "/sys/fs/cgroup"
+	containerRoot = "/"
+)
+
+// containerSampler reads the CPU and memory of the monitor's own cgroup,
+// which inside a container is the container. Host-wide /proc/stat figures
+// there say nothing about the CPU quota, so the CPU usage is taken against
+// the quota instead, and memory against the container's limit.
+type containerSampler struct {
+	runtime string  // "docker", "podman", "kubernetes", "lxc", or "cgroup" when forced on
+	v2      bool    // Unified hierarchy; cgroup v1 keeps each controller in its own directory
+	limit   float64 // CPUs the container may use: the quota, else the cpuset
+	quota   bool    // Whether the limit is a CFS quota rather than the cpuset
+
+	last                      time.Time
+	usage, periods, throttled uint64  // Previous CPU time in µs and CFS period counts
+	cpu                       float64 // CPU usage against the limit (0-100%)
+	throttledShare            float64 // Share of CFS periods throttled since the previous sample (0-100%)
+	memUsed, memLimit         uint64  // Memory in use and its limit in bytes, 0 without one
+}
+
+// newContainerSampler returns a sampler of the monitor's cgroup, or nil
+// when it should show host-wide figures: mode "off", or mode "auto" outside
+// a container. Mode "on" scopes to the cgroup wherever it runs, e.g. in a
+// systemd service with CPUQuota.
+func newContainerSampler(mode string) *containerSampler {
+	runtime := detectContainer()
+	switch {
+	case mode == "off", mode == "auto" && runtime == "":
+		return nil
+	case runtime == "":
+		runtime = "cgroup"
+	}
+	c := &containerSampler{runtime: runtime}
+	if _, err := os.Stat(filepath.Join(cgroupDir, "cgroup.controllers")); err == nil {
+		c.v2 = true
+	} else if _, err := os.Stat(c.path("cpuacct", "cpuacct.usage")); err != nil {
+		return nil // No cgroup CPU accounting to read
+	}
+
+	c.limit = float64(numCPU())
+	// cpuset.cpus.effective under v2, cpuset.effective_cpus under v1
+	for _, file := range []string{"cpuset.cpus.effective", "cpuset.effective_cpus", "cpuset.cpus"} {
+		if cpus := parseCPUList(readSysfsString(c.path("cpuset", file))); cpus > 0 {
+			c.limit = float64(cpus)
+			break
+		}
+	}
+	if quota, period := c.cpuQuota(); quota > 0 && period > 0 && quota/period < c.limit {
+		c.limit, c.quota = quota/period, true
+	}
+	c.sample()
+	return c
+}
+
+// path returns a file of a controller: at the root of the hierarchy under
+// cgroup v2, in the controller's directory under v1.
+func (c *containerSampler) path(controller, file string) string {
+	if c.v2 {
+		return filepath.Join(cgroupDir, file)
+	}
+	if controller == "cpuacct" || controller == "cpu" {
+		// Distributions mount these as cpu,cpuacct with symlinks, or not
+		for _, dir := range []string{controller, "cpu,cpuacct", "cpuacct,cpu"} {
+			if p := filepath.Join(cgroupDir, dir, file); fileExists(p) {
+				return p
+			}
+		}
+	}
+	return filepath.Join(cgroupDir, controller, file)
+}
+
+// cpuQuota returns the CFS quota and period in µs, with a zero quota when
+// the CPU time is not limited.
+func (c *containerSampler) cpuQuota() (quota, period float64) {
+	if c.v2 {
+		// "max 100000" or "200000 100000"
+		fields := strings.Fields(readSysfsString(c.path("cpu", "cpu.max")))
+		if len(fields) != 2 || fields[0] == "max" {
+			return 0, 0
+		}
+		quota, _ = strconv.ParseFloat(fields[0], 64)
+		period, _ = strconv.ParseFloat(fields[1], 64)
+		return quota, period
+	}
+	quota, _ = strconv.ParseFloat(readSysfsString(c.path("cpu", "cpu.cfs_quota_us")), 64)
+	period, _ = strconv.ParseFloat(readSysfsString(c.path("cpu", "cpu.cfs_period_us")), 64)
+	if quota < 0 { // -1 means unlimited
+		return 0, 0
+	}
+	return quota, period
+}
+
+// sample updates the CPU usage and throttled share since the previous
+// call, and the memory in use.
+func (c *containerSampler) sample() {
+	stat := readKeyValues(c.path("cpu", "cpu.stat"))
+	usage := stat["usage_usec"]
+	if !c.v2 {
+		ns, _ := strconv.ParseUint(readSysfsString(c.path("cpuacct", "cpuacct.usage")), 10, 64)
+		usage = ns / 1000
+	}
+	now := timeNow()
+	if !c.last.IsZero() && usage >= c.usage {
+		if elapsed := now.Sub(c.last).Seconds(); elapsed > 0 {
+			c.cpu = math.Min(float64(usage-c.usage)/1e6/elapsed/c.limit*100, 100)
+		}
+		c.throttledShare = 0
+		if periods := stat["nr_periods"]; periods > c.periods {
+			c.throttledShare = float64(stat["nr_throttled"]-c.throttled) / float64(periods-c.periods) * 100
+		}
+	}
+	c.last, c.usage, c.periods, c.throttled = now, usage, stat["nr_periods"], stat["nr_throttled"]
+	c.memUsed, c.memLimit = c.memory()
+}
+
+// memory returns the container's memory in use and its limit in bytes,
+// with the limit 0 when none is set. Like docker stats, the inactive page
+// cache is not counted as in use, since it is reclaimed before the limit
+// is hit.
+func (c *containerSampler) memory() (used, limit uint64) {
+	current, max, inactive := "memory.current", "memory.max", "inactive_file"
+	if !c.v2 {
+		current, max, inactive = "memory.usage_in_bytes", "memory.limit_in_bytes", "total_inactive_file"
+	}
+	used, _ = strconv.ParseUint(readSysfsString(c.path("memory", current)), 10, 64)
+	if cache := readKeyValues(c.path("memory", "memory.stat"))[inactive]; cache < used {
+		used -= cache
+	}
+	// "max" under v2; v1 reports a huge page-aligned number
+	limit, _ = strconv.ParseUint(readSysfsString(c.path("memory", max)), 10, 64)
+	if limit >= 1<<62 {
+		limit = 0
+	}
+	return used, limit
+}
+
+// detectContainer returns the container runtime the monitor runs in, or
+// an empty string on the host. It checks the marker files of Docker and
+// Podman, the variables of Kubernetes and systemd-style runtimes, and the
+// cgroup of PID 1.
+func detectContainer() string {
+	switch {
+	case fileExists(filepath.Join(containerRoot, ".dockerenv")):
+		return "docker"
+	case fileExists(filepath.Join(containerRoot, "run", ".containerenv")):
+		return "podman"
+	case os.Getenv("KUBERNETES_SERVICE_HOST") != "":
+		return "kubernetes"
+	}
+	if name := os.Getenv("container"); name != "" {
+		return name
+	}
+	cgroup := readSysfsString(filepath.Join(procDir, "1", "cgroup"))
+	for _, runtime := range []struct{ marker, name string }{
+		{"kubepods", "kubernetes"}, {"docker", "docker"}, {"libpod", "podman"}, {"lxc", "lxc"}, {"containerd", "containerd"},
+	} {
+		if strings.Contains(cgroup, runtime.marker) {
+			return runtime.name
+		}
+	}
+	return ""
+}
+
+// fileExists reports whether path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// readKeyValues parses a cgroup file of "key value" lines such as cpu.stat.
+func readKeyValues(path string) map[string]uint64 {
+	values := map[string]uint64{}
+	for _, line := range strings.Split(readSysfsString(path), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = v
+		}
+	}
+	return values
+}
+
+// parseCPUList counts the CPUs of a list such as "0-3,8,10-11".
+func parseCPUList(list string) int {
+	count := 0
+	for _, part := range strings.Split(list, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil {
+				continue
+			}
+		}
+		if last >= first {
+			count += last - first + 1
+		}
+	}
+	return count
+}
+
+// displayContainer prints the banner saying that CPU and memory figures
+// are the container's: its CPU usage against its limit, how often the
+// quota throttled it, and its memory limit, e.g. "Container (docker): CPU
+// 75.0% of 2.0 CPUs, 40% throttled  Memory limit 1.0 GiB  (core bars:
+// host)". The core bars still read /proc/stat, which shows every CPU of
+// the host.
+func (m *Monitor) displayContainer() {
+	c := m.container
+	if c == nil {
+		return
+	}
+	fmt.Fprintf(m.out, "%s%s (%s):%s %s %s%s%s "+tr("of %s CPUs"), colorMagenta, tr("Container"), c.runtime, colorReset,
+		tr("CPU"), getUsageColor(c.cpu), formatPercent(c.cpu, 1), colorReset, formatNumber(c.limit, 1))
+	if c.quota {
+		color := colorGreen
+		if c.throttledShare > 0 {
+			color = getUsageColor(50 + c.throttledShare/2)
+		}
+		fmt.Fprintf(m.out, ", %s"+tr("%s throttled")+"%s", color, formatPercent(c.throttledShare, 0), colorReset)
+	}
+	limit := tr("none")
+	if c.memLimit > 0 {
+		limit = formatNumber(float64(c.memLimit)/bytesPerGiB, 1) + " GiB"
+	}
+	fmt.Fprintf(m.out, "  %s %s  %s(%s)%s\r\n\r\n", tr("Memory limit"), limit, colorDarkYellow, tr("core bars: host"), colorReset)
+}
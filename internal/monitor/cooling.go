@@ -0,0 +1,213 @@
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// coolingChips are hwmon drivers of liquid-cooling controllers and AIOs:
+// aquacomputer_d5next, nzxt-kraken2/3, nzxt-smart2, corsair-cpro, the
+// liquidtux drivers and asus_rog_ryujin. Every temperature channel of
+// these chips is a loop temperature; on other chips only channels
+// labelled as coolant are.
+var coolingChips = map[string]bool{
+	"d5next": true, "quadro": true, "octo": true, "aquaero": true, "highflownext": true,
+	"highflow": true, "aquastreamxt": true, "aquastreamult": true, "leakshield": true,
+	"farbwerk360": true, "kraken2": true, "kraken3": true, "z53": true, "kraken2023": true,
+	"nzxtsmart2": true, "smart_device": true, "corsaircpro": true, "rog_ryujin": true,
+}
+
+// Kinds of loop channels.
+const (
+	coolingCoolant = "coolant" // Temperature in °C
+	coolingPump    = "pump"    // Pump speed in RPM
+	coolingFlow    = "flow"    // Flow rate in L/h
+)
+
+// CoolingReading is the current value of one liquid-cooling channel.
+type CoolingReading struct {
+	Sensor string  // Sensor id, "<chip>/<label>"
+	Kind   string  // "coolant" (°C), "pump" (RPM), or "flow" (L/h)
+	Value  float64 // In the unit of the kind
+}
+
+// coolingChannel is one discovered loop sensor.
+type coolingChannel struct {
+	id, label string
+	kind      string
+	path      string
+	scale     float64 // Multiplier from the sysfs value to the kind's unit
+}
+
+// coolingSampler reads coolant temperatures, pump speeds and flow rates
+// from liquid-cooling controllers exposed via hwmon.
+type coolingSampler struct {
+	channels []coolingChannel
+}
+
+// newCoolingSampler discovers the loop channels of every hwmon chip.
+func newCoolingSampler() *coolingSampler {
+	c := &coolingSampler{}
+	chips, _ := filepath.Glob(filepath.Join(hwmonDir, "hwmon*"))
+	sort.Slice(chips, func(i, j int) bool { return naturalLess(chips[i], chips[j]) })
+	seen := map[string]int{}
+	for _, dir := range chips {
+		name := readSysfsString(filepath.Join(dir, "name"))
+		if name == "" {
+			name = filepath.Base(dir)
+		}
+		seen[name]++
+		chip := name
+		if seen[name] > 1 {
+			chip = name + strconv.Itoa(seen[name]) // As in discoverSensors
+		}
+
+		for _, pattern := range []string{"temp*_input", "fan*_input"} {
+			inputs, _ := filepath.Glob(filepath.Join(dir, pattern))
+			sort.Slice(inputs, func(i, j int) bool { return naturalLess(inputs[i], inputs[j]) })
+			for _, input := range inputs {
+				channel := strings.TrimSuffix(filepath.Base(input), "_input")
+				label := readSysfsString(filepath.Join(dir, channel+"_label"))
+				if label == "" {
+					label = channel
+				}
+				kind, scale := classifyCoolingChannel(coolingChips[name], strings.HasPrefix(channel, "temp"), label)
+				if kind == "" {
+					continue
+				}
+				c.channels = append(c.channels, coolingChannel{
+					id: chip + "/" + label, label: label, kind: kind, path: input, scale: scale,
+				})
+			}
+		}
+	}
+	return c
+}
+
+// classifyCoolingChannel returns the kind of a hwmon channel and the
+// scale to its unit, or "" when it is not a loop sensor. Flow sensors
+// report as fans; aquacomputer devices count in dL/h.
+func classifyCoolingChannel(coolingChip, temp bool, label string) (string, float64) {
+	lower := strings.ToLower(label)
+	switch {
+	case temp && (coolingChip || strings.Contains(lower, "coolant") || strings.Contains(lower, "liquid") || strings.Contains(lower, "water")):
+		return coolingCoolant, 0.001
+	case temp:
+		return "", 0
+	case strings.Contains(lower, "flow") && strings.Contains(lower, "dl/h"):
+		return coolingFlow, 0.1
+	case strings.Contains(lower, "flow"):
+		return coolingFlow, 1
+	case strings.Contains(lower, "pump"):
+		return coolingPump, 1
+	}
+	return "", 0
+}
+
+// sample reads every loop channel. Channels that cannot be read, such
+// as unconnected sensor ports, are left out.
+func (c *coolingSampler) sample() []CoolingReading {
+	var readings []CoolingReading
+	for _, ch := range c.channels {
+		v, err := strconv.ParseFloat(readSysfsString(ch.path), 64)
+		if err != nil {
+			continue
+		}
+		readings = append(readings, CoolingReading{Sensor: ch.id, Kind: ch.kind, Value: v * ch.scale})
+	}
+	return readings
+}
+
+// coolingFailure checks the loop readings against the [cooling] limits
+// and describes the first problem, or returns "" when the loop is fine.
+// A pump below its minimum speed is reported first: it is the failure
+// that heats the loop up within minutes. For the screen the message is
+// localized and in the display temperature unit; exporters get it in
+// English and °C.
+func (cfg *Config) coolingFailure(readings []CoolingReading, screen bool) string {
+	c := cfg.Cooling
+	translate, temp := untranslated, func(celsius float64) string { return fmt.Sprintf("%.1f °C", celsius) }
+	if screen {
+		translate, temp = tr, func(celsius float64) string { return unitCelsius.format(celsius, 1) }
+	}
+	for _, kind := range []string{coolingPump, coolingFlow, coolingCoolant} {
+		for _, r := range readings {
+			if r.Kind != kind {
+				continue
+			}
+			switch {
+			case kind == coolingPump && c.MinPumpRPM > 0 && r.Value < c.MinPumpRPM:
+				return fmt.Sprintf(translate("Pump failure: %s at %.0f RPM"), r.Sensor, r.Value)
+			case kind == coolingFlow && c.MinFlow > 0 && r.Value < c.MinFlow:
+				return fmt.Sprintf(translate("Low flow: %s at %.0f L/h"), r.Sensor, r.Value)
+			case kind == coolingCoolant && c.MaxCoolantTemp > 0 && r.Value >= c.MaxCoolantTemp:
+				return fmt.Sprintf(translate("Coolant too hot: %s at %s"), r.Sensor, temp(r.Value))
+			}
+		}
+	}
+	return ""
+}
+
+// untranslated returns s unchanged, for messages that must stay in English.
+func untranslated(s string) string {
+	return s
+}
+
+// updateCoolingAlert raises or clears the loop alert from the latest
+// readings. A new alert rings the terminal bell, or is announced in
+// accessible mode.
+func (m *Monitor) updateCoolingAlert(readings []CoolingReading) {
+	m.coolingReadings = readings
+	alert := m.cfg.coolingFailure(readings, true)
+	if alert != "" && m.coolingAlert == "" && !m.headless {
+		if m.cfg.Accessible {
+			m.say("%s", alert)
+		} else {
+			fmt.Fprint(m.out, "\a")
+		}
+	}
+	m.coolingAlert = alert
+}
+
+// coolingLabel shortens a channel label for the status area, dropping a
+// unit suffix such as " [dL/h]".
+func coolingLabel(sensor string) string {
+	label := sensor[strings.Index(sensor, "/")+1:]
+	if i := strings.Index(label, " ["); i > 0 {
+		label = label[:i]
+	}
+	return label
+}
+
+// displayCooling prints the loop readings on one line, followed by the
+// failure in red when there is one. The terminal bell rings when a
+// failure starts.
+func (m *Monitor) displayCooling() {
+	if len(m.cooling.channels) == 0 {
+		return
+	}
+	var parts []string
+	for _, r := range m.coolingReadings {
+		var value string
+		switch r.Kind {
+		case coolingCoolant:
+			value = getTempColor(r.Value+30) + formatTemp(r.Value, 1) + colorReset // A 40°C loop is as hot as an 70°C CPU
+		case coolingPump:
+			value = unitRPM.format(r.Value, 0)
+		case coolingFlow:
+			value = unitLitersPerHour.format(r.Value, 0)
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", coolingLabel(r.Sensor), value))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "--")
+	}
+	fmt.Fprintf(m.out, "%s%s%s %s\r\n", colorBlue, tr("Loop:"), colorReset, strings.Join(parts, "  "))
+	if m.coolingAlert != "" {
+		fmt.Fprintf(m.out, "%s%s%s\r\n", colorRed, m.coolingAlert, colorReset)
+	}
+	fmt.Fprint(m.out, "\r\n")
+}
@@ -0,0 +1,214 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	coreHistoryColumns  = 72              // Time steps shown, 6 minutes at coreHistoryStep
+	coreHistoryStep     = 5 * time.Second // Time averaged into one column
+	coreHistoryPageRows = 32              // Cores per page
+)
+
+// coreHistoryShades are drawn in the cells in addition to the color so the
+// levels stay readable in banded themes and without color.
+var coreHistoryShades = []string{"░", "▒", "▓", "█"}
+
+// coreHistory keeps per-core usage and temperature averaged
+// over coreHistoryStep, for the core history page. Columns are
+// recorded whether or not the page is open so it opens with history.
+type coreHistory struct {
+	usage, temp [][]float64 // Closed columns, oldest first; one value per core
+	sumUsage    []float64   // Sums of the column being collected
+	sumTemp     []float64
+	polls       int // Polls summed into the open column
+	step        int // Polls per column
+}
+
+// newCoreHistory creates an empty history for the given number of cores
+// polled interval apart.
+func newCoreHistory(cores int, interval time.Duration) *coreHistory {
+	step := int((coreHistoryStep + interval/2) / interval)
+	if step < 1 {
+		step = 1
+	}
+	return &coreHistory{
+		sumUsage: make([]float64, cores),
+		sumTemp:  make([]float64, cores),
+		step:     step,
+	}
+}
+
+// add sums one poll of per-core usage and temperature into the open
+// column and closes the column after step polls.
+func (h *coreHistory) add(cores, temps []float64) {
+	for i := range h.sumUsage {
+		if i < len(cores) && i < len(temps) {
+			h.sumUsage[i] += cores[i]
+			h.sumTemp[i] += temps[i]
+		}
+	}
+	h.polls++
+	if h.polls < h.step {
+		return
+	}
+
+	var usage, temp []float64
+	if len(h.usage) >= coreHistoryColumns {
+		// Reuse the oldest column's slices
+		usage, temp = h.usage[0], h.temp[0]
+		copy(h.usage, h.usage[1:])
+		copy(h.temp, h.temp[1:])
+		h.usage, h.temp = h.usage[:len(h.usage)-1], h.temp[:len(h.temp)-1]
+	} else {
+		usage, temp = make([]float64, len(h.sumUsage)), make([]float64, len(h.sumTemp))
+	}
+	for i := range h.sumUsage {
+		usage[i] = h.sumUsage[i] / float64(h.polls)
+		temp[i] = h.sumTemp[i] / float64(h.polls)
+		h.sumUsage[i], h.sumTemp[i] = 0, 0
+	}
+	h.usage, h.temp = append(h.usage, usage), append(h.temp, temp)
+	h.polls = 0
+}
+
+// column returns the values of core at column i of the page, where the
+// last column is the one still being collected. ok is false for columns
+// without data yet.
+func (h *coreHistory) column(i, core int, temperature bool) (float64, bool) {
+	closed := len(h.usage)
+	if h.polls > 0 {
+		closed++
+	}
+	i -= coreHistoryColumns - closed // Data is right-aligned
+	switch {
+	case i < 0:
+		return 0, false
+	case i < len(h.usage) && temperature:
+		return h.temp[i][core], true
+	case i < len(h.usage):
+		return h.usage[i][core], true
+	case temperature:
+		return h.sumTemp[core] / float64(h.polls), true
+	default:
+		return h.sumUsage[core] / float64(h.polls), true
+	}
+}
+
+// coreHistoryCell returns the colored shade for a usage percentage or,
+// with temperature set, a temperature in °C.
+func coreHistoryCell(v float64, temperature bool) string {
+	color, level := getUsageColor(v), v/25
+	if temperature {
+		color, level = getTempColor(v), (v-40)/15 // 40°C and below to 85°C and above
+	}
+	i := int(level)
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(coreHistoryShades) {
+		i = len(coreHistoryShades) - 1
+	}
+	return color + coreHistoryShades[i] + colorReset
+}
+
+// handleCoreHistoryKey processes a key press while the core history page
+// is shown. It returns false when the application should quit.
+func (m *Monitor) handleCoreHistoryKey(key byte) bool {
+	switch m.pageAction(key, actionCoreHistory) {
+	case actionStress:
+		if m.stressRunning {
+			m.stopStress()
+		} else {
+			m.startStress()
+		}
+		return true
+	case actionClose:
+		m.showCoreHistory = false
+		fmt.Fprint(m.out, clearScreen)
+		return true
+	case actionQuit:
+		return false
+	}
+	switch key {
+	case 't', 'T':
+		m.coreHistoryTemp = !m.coreHistoryTemp
+		fmt.Fprint(m.out, clearScreen)
+	case '[':
+		m.coreHistoryPage-- // Clamped when drawn
+		fmt.Fprint(m.out, clearScreen)
+	case ']':
+		m.coreHistoryPage++
+		fmt.Fprint(m.out, clearScreen)
+	}
+	return true
+}
+
+// displayCoreHistoryPage draws one row per core and one column per time
+// step, newest on the right, so that threads migrating between cores and
+// single-threaded phases stand out. Cells show usage, or the core
+// temperature after T, estimated when the cores have no sensors.
+func (m *Monitor) displayCoreHistoryPage() {
+	h := m.coreHistory
+	temperature := m.coreHistoryTemp && m.maxTemp > 0
+	title := tr("Usage per core")
+	if temperature && m.coreTemps.available() {
+		title = tr("Temperature per core")
+	} else if temperature {
+		title = tr("Estimated temperature per core")
+	}
+	fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor - %s ===%s\r\n\r\n", colorGreen, tr("Core History"), colorReset)
+	fmt.Fprintf(m.out, "%s%s%s  %s\r\n", colorCyan, title, colorReset,
+		fmt.Sprintf(tr("one column per %ds, newest on the right"), int(coreHistoryStep/time.Second)))
+
+	pages := (m.cores + coreHistoryPageRows - 1) / coreHistoryPageRows
+	if m.coreHistoryPage >= pages {
+		m.coreHistoryPage = pages - 1
+	}
+	if m.coreHistoryPage < 0 {
+		m.coreHistoryPage = 0
+	}
+	labelWidth := len(fmt.Sprint(m.cores - 1))
+	first := m.coreHistoryPage * coreHistoryPageRows
+	for core := first; core < m.cores && core < first+coreHistoryPageRows; core++ {
+		var row strings.Builder
+		for i := 0; i < coreHistoryColumns; i++ {
+			if v, ok := h.column(i, core, temperature); ok {
+				row.WriteString(coreHistoryCell(v, temperature))
+			} else {
+				row.WriteByte(' ')
+			}
+		}
+		fmt.Fprintf(m.out, "  %s%*d%s %s\r\n", colorBlue, labelWidth, core, colorReset, row.String())
+	}
+
+	// Time axis under the first, middle and last column
+	minutes := int(coreHistoryColumns * coreHistoryStep / time.Minute)
+	axis := []rune(strings.Repeat(" ", coreHistoryColumns))
+	for i, label := range []string{fmt.Sprintf("-%dm", minutes), fmt.Sprintf("-%dm", minutes/2)} {
+		copy(axis[i*coreHistoryColumns/2:], []rune(label))
+	}
+	now := []rune(tr("now"))
+	copy(axis[coreHistoryColumns-len(now):], now)
+	fmt.Fprintf(m.out, "  %s %s\r\n", strings.Repeat(" ", labelWidth), string(axis))
+
+	// Scale of the shades
+	fmt.Fprintf(m.out, "  %s ", strings.Repeat(" ", labelWidth))
+	for i := range coreHistoryShades {
+		if temperature {
+			temp := 40 + float64(i)*15
+			fmt.Fprintf(m.out, "%s %s  ", coreHistoryCell(temp, true), formatTemp(temp, 0))
+		} else {
+			usage := float64(i) * 25
+			fmt.Fprintf(m.out, "%s %s  ", coreHistoryCell(usage, false), formatPercent(usage, 0))
+		}
+	}
+	fmt.Fprint(m.out, "\r\n\r\n")
+
+	if pages > 1 {
+		fmt.Fprintf(m.out, "%s"+tr("Page %d/%d")+"  %s%s\r\n", colorYellow, m.coreHistoryPage+1, pages, tr("[ ]: page"), colorReset)
+	}
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorYellow, tr("T: usage/temperature  SPACE: stress  C/ESC: close"), colorReset)
+}
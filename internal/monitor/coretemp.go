@@ -0,0 +1,159 @@
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// coreTempSampler reads the temperature of each core from its own sensor:
+// the "Core N" inputs of Intel's coretemp, or on AMD the k10temp "TccdN"
+// input of the chiplet the core sits on. Cores without a sensor of their
+// own fall back to estimateCoreTemp.
+type coreTempSampler struct {
+	paths []string // Sensor input per logical CPU, "" when it has none
+}
+
+// newCoreTempSampler maps each logical CPU to a sensor. Intel sensors are
+// matched by package and core ID from the CPU topology. AMD chiplets are
+// matched through the L3 caches: the caches of a package are numbered in
+// CPU order and split evenly across its CCDs, which also covers Zen 2 with
+// two caches per CCD.
+func newCoreTempSampler(cores int) *coreTempSampler {
+	c := &coreTempSampler{paths: make([]string, cores)}
+
+	coretemp := map[[2]int]string{} // Inputs by package and core ID
+	var ccds [][]string             // Tccd inputs per package
+	chips, _ := filepath.Glob(filepath.Join(hwmonDir, "hwmon*"))
+	sort.Slice(chips, func(i, j int) bool { return naturalLess(chips[i], chips[j]) })
+	for _, dir := range chips {
+		name := readSysfsString(filepath.Join(dir, "name"))
+		if name != "coretemp" && name != "k10temp" {
+			continue
+		}
+		inputs, _ := filepath.Glob(filepath.Join(dir, "temp*_input"))
+		labels := map[string]string{}
+		pkg := 0
+		for _, input := range inputs {
+			labels[input] = readSysfsString(strings.TrimSuffix(input, "_input") + "_label")
+			if id, ok := labelNumber(labels[input], "Package id "); ok {
+				pkg = id
+			}
+		}
+		if name == "k10temp" {
+			// One chip per package, in order
+			pkg = len(ccds)
+			ccds = append(ccds, nil)
+		}
+		for _, input := range inputs {
+			if id, ok := labelNumber(labels[input], "Core "); ok && name == "coretemp" {
+				coretemp[[2]int{pkg, id}] = input
+			}
+			if id, ok := labelNumber(labels[input], "Tccd"); ok && id > 0 && name == "k10temp" {
+				for len(ccds[pkg]) < id {
+					ccds[pkg] = append(ccds[pkg], "")
+				}
+				ccds[pkg][id-1] = input
+			}
+		}
+	}
+
+	l3 := l3Domains(cores)
+	packages := make([]int, cores)
+	caches := map[int][]int{} // L3 indexes of each package, in CPU order
+	for cpu := 0; cpu < cores; cpu++ {
+		dir := filepath.Join(cpuDir, fmt.Sprintf("cpu%d", cpu), "topology")
+		pkg, err := strconv.Atoi(readSysfsString(filepath.Join(dir, "physical_package_id")))
+		if err != nil {
+			pkg = 0
+		}
+		packages[cpu] = pkg
+		core, err := strconv.Atoi(readSysfsString(filepath.Join(dir, "core_id")))
+		if err != nil {
+			core = cpu // Without topology, assume one thread per core
+		}
+		if path, ok := coretemp[[2]int{pkg, core}]; ok {
+			c.paths[cpu] = path
+		}
+		if l3 != nil && cachePosition(caches[pkg], l3[cpu]) < 0 {
+			caches[pkg] = append(caches[pkg], l3[cpu])
+		}
+	}
+
+	for cpu := range c.paths {
+		pkg := packages[cpu]
+		if c.paths[cpu] != "" || pkg >= len(ccds) {
+			continue
+		}
+		switch {
+		case len(ccds[pkg]) == 1:
+			c.paths[cpu] = ccds[pkg][0]
+		case len(ccds[pkg]) > 1 && l3 != nil:
+			pos := cachePosition(caches[pkg], l3[cpu])
+			c.paths[cpu] = ccds[pkg][pos*len(ccds[pkg])/len(caches[pkg])]
+		}
+	}
+	return c
+}
+
+// labelNumber parses the number after prefix in a sensor label such as
+// "Core 3" or "Tccd2".
+func labelNumber(label, prefix string) (int, bool) {
+	if !strings.HasPrefix(label, prefix) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(label, prefix))
+	return n, err == nil
+}
+
+// cachePosition returns the position of cache in caches, or -1.
+func cachePosition(caches []int, cache int) int {
+	for i, x := range caches {
+		if x == cache {
+			return i
+		}
+	}
+	return -1
+}
+
+// available reports whether any core has a sensor of its own.
+func (c *coreTempSampler) available() bool {
+	for _, path := range c.paths {
+		if path != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// sample reads the core temperatures in °C, 0 for cores without a
+// sensor or with an unreadable one, or returns nil when no core has one.
+func (c *coreTempSampler) sample() []float64 {
+	if !c.available() {
+		return nil
+	}
+	temps := make([]float64, len(c.paths))
+	for i, path := range c.paths {
+		if path != "" {
+			temps[i] = readHwmonScaled(path, 1e-3)
+		}
+	}
+	return temps
+}
+
+// coreTemperatures returns the temperature of each core for the core
+// views: its sensor reading where there is one, otherwise the estimate
+// from its usage and the package temperature.
+func (m *Monitor) coreTemperatures(usages []float64, packageTemp float64) []float64 {
+	temps := make([]float64, len(usages))
+	for i, usage := range usages {
+		if i < len(m.coreTempReadings) && m.coreTempReadings[i] > 0 {
+			temps[i] = m.coreTempReadings[i]
+		} else {
+			temps[i] = estimateCoreTemp(usage, packageTemp)
+		}
+	}
+	return temps
+}
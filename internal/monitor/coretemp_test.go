@@ -0,0 +1,30 @@
+package monitor
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestCoreTempSensors checks that cores are matched to their own sensors:
+// coretemp inputs by core ID, and k10temp CCD inputs by L3 cache.
+func TestCoreTempSensors(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    map[int]string // Input file by core
+	}{
+		{"4cores", map[int]string{0: "temp2_input", 3: "temp5_input"}},
+		{"8cores", map[int]string{0: "temp3_input", 7: "temp3_input"}},
+		{"16cores", map[int]string{0: "temp3_input", 7: "temp3_input", 8: "temp4_input", 15: "temp4_input"}},
+		{"128cores", map[int]string{0: "temp3_input", 63: "temp10_input", 64: "temp3_input", 127: "temp10_input"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			m, _, _ := fixtureMonitor(t, tt.fixture, nil, nil)
+			for core, want := range tt.want {
+				if got := filepath.Base(m.coreTemps.paths[core]); got != want {
+					t.Errorf("core %d reads %s, want %s", core, got, want)
+				}
+			}
+		})
+	}
+}
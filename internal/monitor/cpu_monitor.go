@@ -0,0 +1,1642 @@
+// Package monitor implements a real-time CPU performance monitoring application
+// that provides colorful, terminal-based interface for tracking CPU usage and temperature.
+// The monitor displays per-core usage bars, temperature-based color coding,
+// historical graphs, and includes built-in stress testing capabilities.
+// The kkperf, kkperf-agent and kkperf-report commands are built from it.
+package monitor
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"github.com/jeremycharlesgillespie/kode_kronical_perf_monitor/internal/version"
+)
+
+const (
+	baseGraphWidth = 60 // Base width for 30s scale
+	clearScreen = "\033[2J\033[H"
+	moveCursor = "\033[0;0H"
+	hideCursor = "\033[?25l"
+	showCursor = "\033[?25h"
+	colorReset = "\033[0m"
+)
+
+// Colors are variables so the active theme can swap in brighter variants
+var (
+	colorRed     = "\033[0;31m"
+	colorBrightRed = "\033[1;31m"
+	colorGreen   = "\033[0;32m"
+	colorYellow  = "\033[1;33m"
+	colorDarkYellow = "\033[0;33m"
+	colorBlue    = "\033[0;34m"
+	colorDarkBlue = "\033[38;5;17m"
+	colorLightBlue = "\033[38;5;39m"
+	colorMagenta = "\033[0;35m"
+	colorCyan    = "\033[0;36m"
+	colorOrange  = "\033[38;5;208m"
+)
+
+// historyPoint is one column of graph history: the total CPU usage and
+// package temperature, plus the utilization of other subsystems (0-100%)
+// for the stacked activity graph.
+type historyPoint struct {
+	cpu, temp      float64
+	cpuMin, cpuMax float64   // Range of CPU usage over the polls the point covers
+	gpu, disk, net float64
+	sensors        []float64 // Secondary sensor readings, in cfg.SecondarySensors order
+	power          []float64 // RAPL domain power in W, in rapl.domains order
+	latencyAvg     float64   // Probe wakeup latency in µs, 0 while the probe is stopped
+	latencyMax     float64
+	irq            float64   // Hard and soft interrupt share of CPU time (0-100%)
+	iperf          float64   // iperf3 throughput in Mbit/s, 0 while network stress is off
+	psuIn, psuOut  float64   // PSU input and output power in W, 0 when unavailable
+	mem, swap      float64   // RAM and swap in use (0-100%), swap -1 without swap
+	mhz            float64   // Mean core clock in MHz, 0 when unknown
+	diskRead       float64   // Bytes read per second over all disks
+	diskWrite      float64   // Bytes written per second over all disks
+	throttled      bool      // Thermal throttling during the polls the point covers
+	limited        bool      // Clock below base under load during the polls the point covers
+}
+
+// CPUStats represents CPU usage statistics parsed from /proc/stat.
+// Each field corresponds to time spent in different CPU states measured in jiffies.
+type CPUStats struct {
+	user   uint64
+	nice   uint64
+	system uint64
+	idle   uint64
+	iowait uint64
+	irq    uint64
+	soft   uint64
+	steal  uint64
+}
+
+// Monitor represents the main application state and configuration.
+// It manages CPU monitoring, temperature tracking, display rendering,
+// stress testing, and user interaction.
+type Monitor struct {
+	cfg             *Config
+	stressCmd       *exec.Cmd     // External 'stress' backend
+	stressEngine    *stressEngine // Built-in backend
+	stressRunning   bool
+	stressAvailable bool
+	cores           int
+	minTemp        float64
+	rawTemp        float64 // Last temperature before calibration
+	tempSensorID   string  // Sensor the last temperature came from
+	maxTemp        float64
+	pause          pauseState       // Frozen graphs (E)
+	history        *graphHistory  // Graph history at several resolutions
+	coreHistory    *coreHistory   // Per-core usage over time for the core history page
+	scatter        []scatterPoint // Clock, temperature and power of recent polls
+	lastCPUStats   []CPUStats
+	spareCPUStats  []CPUStats       // Reused by getCPUStats so polls do not allocate per core
+	irqUsage       float64          // Share of CPU time spent in hard and soft interrupts
+	iowaitUsage    float64          // Share of CPU time spent idle waiting for I/O
+	blocked        *blockedTracker  // Threads in uninterruptible sleep
+	blockedLine    bool             // Whether the I/O wait line was drawn in the last frame
+	mem            memInfo          // Last /proc/meminfo reading
+	health         healthReading    // Last zombie, thread and open file counts
+	healthAlert    bool             // Whether the health warning was drawn in the last frame
+	entropy        *entropySampler  // Kernel entropy pool
+	coreTemps      *coreTempSampler // Per-core temperature sensors
+	coreTempReadings []float64      // Last per-core sensor readings, 0 for cores without one
+	entropyBits    int              // Last entropy estimate, -1 when unavailable
+	load           loadAverage      // Last load averages and task counts
+	msrTjMax       float64          // TjMax from MSR_TEMPERATURE_TARGET, for the MSR temperature fallback
+	container      *containerSampler // CPU and memory of the monitor's cgroup, nil for host-wide figures
+	topProcs       *topSampler       // Busiest processes, set while their pane is shown
+	diskIO         *diskIOSampler    // Throughput of the disks
+	disks          []DiskIO          // Last disk throughput, nil without /proc/diskstats
+	gpus           *gpuSampler       // AMD and NVIDIA cards, nil without any
+	fanSensors     *fanSampler       // Fan tachometers of the hwmon chips
+	batterySensors *batterySampler   // Laptop batteries
+	batteries      []BatteryReading  // Last battery readings
+	fans           []FanReading      // Last fan speeds
+	fanRanges      map[string]fanRange // Lowest and highest speed of each fan since startup
+	gpuReadings    []GPUReading      // Last GPU readings
+	gpuRows        int               // Height of the GPU panel in the last frame
+	clock          *clockWatch      // Time daemon status and wall clock steps
+	clockLine      bool             // Whether the clock line was drawn in the last frame
+	activity       *activitySampler // GPU, disk, and network utilization
+	throttle       *throttleSampler // Thermal throttle event counters
+	clocks         *clockSampler    // Per-core clocks for the overclocking page
+	smu            *smuSampler      // AMD power limit telemetry
+	rapl           *raplSampler     // Intel RAPL per-domain power
+	resctrl        *resctrlSampler  // Memory bandwidth from resctrl, set while its page is open
+	wakeups        *wakeupSampler   // Interrupt and wakeup rates, set while their page is open
+	attribution    *attributionSampler // CPU time by user, cgroup and priority, set while its page is open
+	latency        *latencyProbe    // Wakeup latency probe for the latency graph
+	netStress      *netStress       // iperf3 network load
+	diskStress     *diskStress      // fio disk load
+	ambient        *ambientSource   // Room temperature source, nil when not configured
+	cooling        *coolingSampler  // Liquid-cooling loop sensors
+	coolingReadings []CoolingReading // Latest loop readings
+	coolingAlert   string           // Localized loop failure, empty when the loop is fine
+	alerts         []string         // [alerts] rules raised in the latest sample
+	alertStates    []alertState     // Per [alerts] rule, in config order
+	psu            *psuSampler      // Power supplies with a hwmon driver
+	psuReading     psuReading       // Latest PSU reading
+	throttleStart  time.Time        // Start of the current throttling, zero when not throttling
+	lastThrottle   time.Time        // Last poll with a throttle event
+	safetyStop     string           // Why the safety limiter stopped stress, until it is restarted
+	safetyStopAt   time.Time        // When it did, while the [safety] cooldown lasts
+	timed          *timedRun        // Timed stress run in progress
+	timedReport    []string         // Report of the last timed run
+	timedQuit      bool             // Quit when the timed run ends (--stress-duration)
+	baseline       *idleBaseline    // Idle reading the deltas are shown against, nil until measured
+	baselineRun    *baselineRun     // Baseline measurement in progress
+	oldTermState   *term.State
+	out            io.Writer        // Where frames are drawn: the terminal, or a screenBuffer in tests
+	terminal       *frameWriter     // Writes the frames to the terminal while the TUI runs
+	recorder       *castRecorder    // Recording of the frames (--record)
+	
+	// Display mode
+	showHelp           bool         // Toggle between main view and help page
+	showSensors        bool         // Sensor picker screen is shown
+	showOverclock      bool         // Overclocking detail page is shown
+	showBandwidth      bool         // Memory bandwidth page is shown
+	showCoreHistory    bool         // Core history page is shown
+	coreHistoryTemp    bool         // Core history shows temperature instead of usage
+	coreHistoryPage    int          // Page of the core history shown
+	showScatter        bool         // Frequency scatter page is shown
+	scatterPower       bool         // Frequency scatter is plotted against power instead of temperature
+	showWakeups        bool         // Wakeups page is shown
+	wakeupsPage        int          // Page of the wakeups table shown
+	showAttribution    bool         // CPU attribution page is shown
+	showBookmarks      bool         // A/B comparison page is shown
+	showTimedReport    bool         // Report of the last timed stress run is shown
+	showStartupChecks  bool         // Startup diagnostics page is shown
+	startupChecks      []startupCheck // Results of the checks run at launch
+	bookmarks          bookmarks    // Marked ranges for the A/B comparison
+	smuShown           bool         // AMD limit bars were part of the last frame
+	coreView           coreView     // How per-core usage is drawn
+	freqBars           bool         // Core bars show clocks instead of usage
+	coreGroups         []coreGroup  // Cores by shared cache, for the heatmap
+	heatmapPage        int          // Page of the heatmap shown
+	graphMode          graphMode    // Which history graph is drawn
+	split              splitView    // Two graphs side by side in place of the history graph
+	
+	// Time scale functionality
+	window             time.Duration  // Time span of the history graph, changed with W and S
+	displayBuffer      []historyPoint // Graph columns sampled from history for the window
+	graphStart         int            // First column of displayBuffer with data
+	
+	// Smooth animation fields
+	currentCoreUsages  []float64    // Current displayed values
+	coreSampleBuffer   [][]float64  // Rolling buffer of samples for each core
+	sampleBufferSize   int          // Number of samples to keep
+	rollingAverage     []float64    // Result buffer of calculateRollingAverage
+	lastPollTime       time.Time    // When we last polled CPU stats
+	lastRenderTime     time.Time    // When we last rendered the display
+	
+	// Accessible mode announcements
+	lastAnnounce       time.Time    // When the last status line was announced
+	lastAnnouncedTemp  float64      // Temperature at the last announcement, for the trend
+	
+	lastTitle          string       // Last terminal title written, to avoid redundant updates
+	
+	sinks              []sink       // Exporters that receive every polled sample
+	emergency          *emergencySink // The [emergency] watchdog among the sinks, for its status
+	headless           bool         // Running without the TUI (exporter-only mode)
+	resets             chan struct{} // Session resets requested over the HTTP endpoint or socket
+	remote             *remoteSource // Monitor of another machine shown instead of this one (kkperf connect)
+	
+	// Temperature sensor selection
+	sensors            []tempSensor // Discovered temperature sources that pass the allow/deny lists
+	deniedSensorPaths  map[string]bool // Files of sensors excluded by the deny list
+	sensorReadings     []float64    // Live readings for the sensor picker
+	sensorRawReadings  []float64    // Uncalibrated readings for the sensor picker
+	secondaryTemps     []float64    // Readings of cfg.SecondarySensors, in order
+	sensorCursor       int          // Highlighted row in the sensor picker
+	sensorsChanged     bool         // Selection changed since the picker was opened
+	sensorSaveErr      error        // Why the last selection could not be saved to the config file
+}
+
+// numCPU returns the number of cores to monitor. Tests replace it to
+// render fixtures of other machines.
+var numCPU = runtime.NumCPU
+
+// NewMonitor creates and initializes a new Monitor instance with default settings.
+// It detects the number of CPU cores, initializes data structures for tracking
+// CPU usage and temperature history,
+// and checks for stress testing tool availability.
+func NewMonitor(cfg *Config) *Monitor {
+	return newMonitor(cfg, numCPU())
+}
+
+// newMonitor creates a Monitor for a machine with the given number of
+// cores: this one, or the remote one kkperf connect follows.
+func newMonitor(cfg *Config, cores int) *Monitor {
+	privHelper = newHelperClient(cfg.Helper.Socket) // Before the samplers probe root-only sources
+	bufferSize := 4 // Keep 4 samples for averaging
+	
+	m := &Monitor{
+		cfg:               cfg,
+		out:               os.Stdout,
+		cores:             cores,
+		minTemp:           999.0,
+		maxTemp:           0.0,
+		showHelp:          false, // Start with main view
+		coreView:          cfg.CoreView,
+		graphMode:         cfg.GraphMode,
+		split:             newSplitView(cfg),
+		activity:          newActivitySampler(cfg.NetworkCapacityMbps),
+		throttle:          newThrottleSampler(),
+		clocks:            newClockSampler(cores),
+		coreHistory:       newCoreHistory(cores, cfg.PollInterval),
+		coreTemps:         newCoreTempSampler(cores),
+		smu:               newSMUSampler(cores),
+		rapl:              newRAPLSampler(),
+		latency:           newLatencyProbe(cfg.Latency.Interval),
+		netStress:         &netStress{},
+		diskStress:        &diskStress{},
+		ambient:           newAmbientSource(cfg),
+		cooling:           newCoolingSampler(),
+		psu:               newPSUSampler(),
+		entropy:           newEntropySampler(),
+		clock:             newClockWatch(),
+		blocked:           newBlockedTracker(),
+		container:         newContainerSampler(cfg.Container),
+		diskIO:            newDiskIOSampler(),
+		gpus:              newGPUSampler(cfg.GPU.NvidiaSMI),
+		fanSensors:        newFanSampler(cfg),
+		batterySensors:    newBatterySampler(),
+		fanRanges:         map[string]fanRange{},
+		resets:            make(chan struct{}, 1),
+		window:            cfg.TimeScale,
+		history:           newGraphHistory(cfg.PollInterval),
+		displayBuffer:     make([]historyPoint, baseGraphWidth),
+		lastCPUStats:      make([]CPUStats, cores+1), // +1 for total CPU
+		currentCoreUsages: make([]float64, cores),
+		coreSampleBuffer:  make([][]float64, cores),
+		sampleBufferSize:  bufferSize,
+		lastPollTime:      time.Now(),
+		lastRenderTime:    time.Now(),
+	}
+	
+	// Initialize sample buffers for each core
+	for i := 0; i < cores; i++ {
+		m.coreSampleBuffer[i] = make([]float64, 0, bufferSize)
+	}
+	
+	// The grid gets too tall on many-core machines
+	m.coreGroups = discoverCoreGroups(cores)
+	if cfg.CoreViewName == "" && cores > heatmapAutoCores {
+		m.coreView = coreViewHeatmap
+	}
+	
+	m.rediscoverSensors()
+	
+	// Initialize CPU stats, so the first poll measures from here
+	m.lastCPUStats = m.getCPUStats()
+	
+	// Check if the stress backend is available
+	m.stressAvailable = m.checkStressAvailable()
+	
+	return m
+}
+
+// cleanup performs necessary cleanup operations when the application exits.
+// This includes stopping any running stress test, restoring terminal state,
+// showing the cursor, and displaying an exit message.
+func (m *Monitor) cleanup() {
+	if m.stressRunning {
+		m.stopStress()
+	}
+	m.stopNetStress()
+	m.stopDiskStress()
+	m.latency.stop()
+	children.close()
+	if m.remote != nil {
+		m.remote.close()
+	}
+	if m.oldTermState != nil {
+		term.Restore(int(os.Stdin.Fd()), m.oldTermState)
+	}
+	if m.cfg.TerminalTitle {
+		fmt.Fprint(m.out, restoreTitle)
+	}
+	m.closeSinks()
+	if m.resctrl != nil {
+		m.resctrl.close()
+	}
+	fmt.Fprint(m.out, showCursor)
+	fmt.Fprintf(m.out, "\n%s%s%s\r\n", colorRed, tr("Exiting..."), colorReset)
+	if m.terminal != nil {
+		m.terminal.close()
+		m.terminal = nil
+	}
+	m.stopRecording()
+	if m.timedReport != nil {
+		// Left in the scrollback once the screen is restored
+		for _, line := range m.timedReport {
+			fmt.Println(line)
+		}
+	}
+}
+
+// calculateCPUUsage computes CPU usage percentages by comparing current
+// CPU statistics with previous readings. Returns total CPU usage and
+// per-core usage percentages (0-100%).
+func (m *Monitor) calculateCPUUsage() (float64, []float64) {
+	currentStats := m.getCPUStats()
+	defer func() {
+		// The previous readings become the buffer for the next poll
+		if &currentStats[0] != &m.lastCPUStats[0] {
+			m.spareCPUStats, m.lastCPUStats = m.lastCPUStats, currentStats
+		}
+	}()
+
+	coreUsages := make([]float64, m.cores)
+	
+	// Calculate total CPU usage
+	totalUsage := m.calculateSingleCPUUsage(m.lastCPUStats[0], currentStats[0])
+	m.irqUsage = irqShare(m.lastCPUStats[0], currentStats[0])
+	m.iowaitUsage = iowaitShare(m.lastCPUStats[0], currentStats[0])
+	
+	// Calculate per-core usage
+	for i := 0; i < m.cores; i++ {
+		coreUsages[i] = m.calculateSingleCPUUsage(m.lastCPUStats[i+1], currentStats[i+1])
+	}
+	
+	return totalUsage, coreUsages
+}
+
+// calculateSingleCPUUsage computes the CPU usage percentage for a single CPU
+// or core by comparing previous and current CPUStats. Uses the standard
+// Linux CPU usage calculation: (total_time - idle_time) / total_time * 100.
+func (m *Monitor) calculateSingleCPUUsage(prev, curr CPUStats) float64 {
+	prevIdle := prev.idle + prev.iowait
+	currIdle := curr.idle + curr.iowait
+	
+	prevNonIdle := prev.user + prev.nice + prev.system + prev.irq + prev.soft + prev.steal
+	currNonIdle := curr.user + curr.nice + curr.system + curr.irq + curr.soft + curr.steal
+	
+	prevTotal := prevIdle + prevNonIdle
+	currTotal := currIdle + currNonIdle
+	
+	totalDiff := float64(currTotal - prevTotal)
+	idleDiff := float64(currIdle - prevIdle)
+	
+	if totalDiff == 0 {
+		return 0
+	}
+	
+	usage := ((totalDiff - idleDiff) / totalDiff) * 100
+	if usage < 0 {
+		usage = 0
+	}
+	if usage > 100 {
+		usage = 100
+	}
+	
+	return usage
+}
+
+// irqShare computes the percentage of CPU time spent servicing hardware
+// and software interrupts between two CPUStats readings.
+func irqShare(prev, curr CPUStats) float64 {
+	total := func(s CPUStats) uint64 {
+		return s.user + s.nice + s.system + s.idle + s.iowait + s.irq + s.soft + s.steal
+	}
+	if total(curr) <= total(prev) || curr.irq+curr.soft < prev.irq+prev.soft {
+		return 0
+	}
+	return float64(curr.irq+curr.soft-prev.irq-prev.soft) / float64(total(curr)-total(prev)) * 100
+}
+
+// getTemperature returns the current CPU temperature in Celsius with the
+// sensor's calibration applied. The uncorrected reading is kept in
+// m.rawTemp. Returns 0 if no temperature source is available.
+func (m *Monitor) getTemperature() float64 {
+	raw, id := m.readRawTemperature()
+	if id != m.tempSensorID {
+		logInfo("temperature source changed", "from", m.tempSensorID, "to", id)
+	}
+	m.rawTemp = raw
+	m.tempSensorID = id
+	if raw == 0 {
+		return 0
+	}
+	return m.cfg.calibrate(id, raw)
+}
+
+// readRawTemperature attempts to read the current CPU temperature in Celsius
+// and returns it with the id of the sensor it came from.
+// Uses the sensor chosen in the config or sensor picker when there is one.
+// Otherwise tries the known CPU sensors (k10temp/zenpower Tdie or Tctl,
+// Intel coretemp package), then the platform's fallbackTemperature. Returns
+// 0 if no temperature source is available.
+func (m *Monitor) readRawTemperature() (float64, string) {
+	if m.cfg.Sensor != "" {
+		if s := m.configuredSensor(); s != nil {
+			if temp, ok := m.readSensor(s); ok {
+				return temp, s.id
+			}
+		}
+	}
+	
+	if s := m.autoCPUSensor(); s != nil {
+		if temp, ok := m.readSensor(s); ok {
+			return temp, s.id
+		}
+	}
+	
+	return m.fallbackTemperature()
+}
+
+// updateMinMax updates the recorded minimum and maximum temperature values
+// based on the provided temperature reading. Ignores zero temperatures
+// when updating minimum values.
+func (m *Monitor) updateMinMax(temp float64) {
+	if temp < m.minTemp && temp > 0 {
+		m.minTemp = temp
+	}
+	if temp > m.maxTemp {
+		m.maxTemp = temp
+	}
+}
+
+// interpolateColor performs linear interpolation between two RGB colors
+// based on a value within a given range. Returns interpolated RGB values
+// as integers (0-255). Used for creating smooth color gradients.
+func interpolateColor(val, min, max float64, r1, g1, b1, r2, g2, b2 int) (int, int, int) {
+	// Normalize value between 0 and 1
+	t := (val - min) / (max - min)
+	if t < 0 {
+		t = 0
+	}
+	if t > 1 {
+		t = 1
+	}
+	
+	// Linear interpolation between colors
+	r := int(float64(r1) + t*float64(r2-r1))
+	g := int(float64(g1) + t*float64(g2-g1))
+	b := int(float64(b1) + t*float64(b2-b1))
+	
+	return r, g, b
+}
+
+// getTempColor returns an ANSI 24-bit color escape sequence based on
+// the provided temperature in Celsius. Creates a gradient from blue (cool)
+// through green and yellow to red and purple (critical temperatures).
+func getTempColor(temp float64) string {
+	if activeTheme.banded {
+		return activeTheme.bandColor(temp, highContrastTempBands)
+	}
+	
+	// Color gradient based on temperature (Celsius)
+	// Cool (35-45°C) -> Warm (50-70°C) -> Hot (75-85°C) -> Critical (90°C+)
+	type colorStop struct {
+		temp    float64
+		r, g, b int
+	}
+	
+	stops := []colorStop{
+		{35,   0,   0, 255},  // Blue - Cool
+		{40,   0, 128, 255},  // Light blue
+		{45,   0, 255, 255},  // Cyan
+		{50,   0, 255,   0},  // Green - Normal
+		{60, 128, 255,   0},  // Yellow-green
+		{65, 255, 255,   0},  // Yellow - Warm
+		{70, 255, 192,   0},  // Orange-yellow
+		{75, 255, 128,   0},  // Orange - Hot
+		{80, 255,  64,   0},  // Dark orange
+		{85, 255,   0,   0},  // Red - Very Hot
+		{90, 255,   0,  64},  // Bright red
+		{95, 255,   0, 128},  // Magenta - Critical
+		{100, 255,  0, 255}, // Purple - Extreme
+	}
+	
+	// Find which two stops we're between
+	var lower, upper colorStop
+	for i := 0; i < len(stops)-1; i++ {
+		if temp >= stops[i].temp && temp <= stops[i+1].temp {
+			lower = stops[i]
+			upper = stops[i+1]
+			break
+		}
+	}
+	
+	// Handle edge cases
+	if temp <= stops[0].temp {
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", stops[0].r, stops[0].g, stops[0].b)
+	}
+	if temp >= stops[len(stops)-1].temp {
+		last := stops[len(stops)-1]
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", last.r, last.g, last.b)
+	}
+	
+	// Interpolate between the two stops
+	r, g, b := interpolateColor(temp, lower.temp, upper.temp,
+		lower.r, lower.g, lower.b,
+		upper.r, upper.g, upper.b)
+	
+	// Return 24-bit true color ANSI escape code
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+}
+
+// getUsageColor returns an ANSI 24-bit color escape sequence based on
+// CPU usage percentage (0-100%). Creates a gradient from dark blue (low usage)
+// through cyan, green, yellow, orange to red (high usage).
+func getUsageColor(usage float64) string {
+	if activeTheme.banded {
+		return activeTheme.bandColor(usage, highContrastUsageBands)
+	}
+	
+	// Define color gradient stops (usage%, r, g, b)
+	// Using a blue -> cyan -> green -> yellow -> orange -> red gradient
+	type colorStop struct {
+		percent float64
+		r, g, b int
+	}
+	
+	stops := []colorStop{
+		{0,    0,   0,  128},  // Dark blue
+		{10,   0,   0,  255},  // Blue
+		{20,   0, 128,  255},  // Light blue
+		{30,   0, 255,  255},  // Cyan
+		{40,   0, 255,    0},  // Green
+		{50, 128, 255,    0},  // Yellow-green
+		{60, 255, 255,    0},  // Yellow
+		{70, 255, 165,    0},  // Orange
+		{80, 255,  64,    0},  // Dark orange
+		{90, 255,   0,    0},  // Red
+		{100, 255,  0,    0},  // Bright red
+	}
+	
+	// Find which two stops we're between
+	var lower, upper colorStop
+	for i := 0; i < len(stops)-1; i++ {
+		if usage >= stops[i].percent && usage <= stops[i+1].percent {
+			lower = stops[i]
+			upper = stops[i+1]
+			break
+		}
+	}
+	
+	// Handle edge cases
+	if usage <= stops[0].percent {
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", stops[0].r, stops[0].g, stops[0].b)
+	}
+	if usage >= stops[len(stops)-1].percent {
+		last := stops[len(stops)-1]
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", last.r, last.g, last.b)
+	}
+	
+	// Interpolate between the two stops
+	r, g, b := interpolateColor(usage, lower.percent, upper.percent,
+		lower.r, lower.g, lower.b,
+		upper.r, upper.g, upper.b)
+	
+	// Return 24-bit true color ANSI escape code
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+}
+
+// barChars are the block characters for bar heights of 0/8 through 8/8.
+var barChars = []string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// estimateCoreTemp estimates a core's temperature from its usage and the
+// package temperature: idle cores sit about 5°C below the package and heat
+// up by as much as 15°C at full load.
+func estimateCoreTemp(usage, packageTemp float64) float64 {
+	baseTemp := packageTemp - 5        // Assume idle cores are 5°C below package
+	tempOffset := (usage / 100.0) * 15 // Up to 15°C rise at 100% usage
+	return baseTemp + tempOffset
+}
+
+// getGridDimensions calculates optimal grid layout (columns, rows) for
+// displaying the given number of CPU cores. Uses predefined layouts for
+// common core counts and falls back to square root approximation for others.
+func getGridDimensions(count int) (cols, rows int) {
+	switch {
+	case count <= 4:
+		return 2, (count + 1) / 2
+	case count <= 6:
+		return 3, 2
+	case count <= 8:
+		return 4, 2
+	case count <= 12:
+		return 4, 3
+	case count <= 16:
+		return 4, 4
+	case count <= 20:
+		return 5, 4
+	case count <= 25:
+		return 5, 5
+	case count <= 30:
+		return 6, 5
+	case count <= 36:
+		return 6, 6
+	default:
+		cols = int(math.Sqrt(float64(count))) + 1
+		return cols, cols
+	}
+}
+
+// updateSampleBuffer adds new CPU usage samples to the rolling buffer
+// for each CPU core. Maintains a fixed buffer size by removing oldest
+// samples when the buffer is full. Used for calculating rolling averages.
+func (m *Monitor) updateSampleBuffer(newSamples []float64) {
+	// Add new samples to buffer and maintain rolling window
+	for i := 0; i < m.cores; i++ {
+		if n := len(m.coreSampleBuffer[i]); n >= m.sampleBufferSize {
+			// Remove oldest sample, shifting in place to keep the capacity
+			copy(m.coreSampleBuffer[i], m.coreSampleBuffer[i][1:])
+			m.coreSampleBuffer[i] = m.coreSampleBuffer[i][:n-1]
+		}
+		// Add new sample
+		m.coreSampleBuffer[i] = append(m.coreSampleBuffer[i], newSamples[i])
+	}
+}
+
+// calculateRollingAverage computes weighted rolling averages for all CPU cores
+// using the sample buffer. More recent samples have higher weights, creating
+// smooth but responsive CPU usage values. Returns clamped values (0-100%).
+// The returned slice is reused by the next call.
+func (m *Monitor) calculateRollingAverage() []float64 {
+	if len(m.rollingAverage) != m.cores {
+		m.rollingAverage = make([]float64, m.cores)
+	}
+	avg := m.rollingAverage
+	for i := 0; i < m.cores; i++ {
+		if len(m.coreSampleBuffer[i]) == 0 {
+			avg[i] = 0
+			continue
+		}
+		
+		// Calculate weighted average with more weight on recent samples
+		sum := 0.0
+		totalWeight := 0.0
+		for j, sample := range m.coreSampleBuffer[i] {
+			// Linear weighting: older samples have less weight
+			weight := float64(j + 1)
+			sum += sample * weight
+			totalWeight += weight
+		}
+		
+		if totalWeight > 0 {
+			avg[i] = sum / totalWeight
+		}
+		
+		// Clamp values
+		if avg[i] < 0 {
+			avg[i] = 0
+		}
+		if avg[i] > 100 {
+			avg[i] = 100
+		}
+	}
+	return avg
+}
+
+// interpolateCoreUsages provides smooth animation between CPU usage values
+// by gradually transitioning current display values toward target rolling
+// averages. This creates fluid 60fps animations without jittery movements.
+func (m *Monitor) interpolateCoreUsages() []float64 {
+	// Calculate smooth interpolation towards rolling average
+	targetValues := m.calculateRollingAverage()
+	
+	// Smooth transition rate (adjust for desired smoothness)
+	smoothingFactor := 0.08 // Lower = smoother, higher = more responsive
+	
+	// Continuously move towards target
+	for i := 0; i < m.cores; i++ {
+		diff := targetValues[i] - m.currentCoreUsages[i]
+		
+		// Apply smoothing
+		m.currentCoreUsages[i] += diff * smoothingFactor
+		
+		// Clamp values
+		if m.currentCoreUsages[i] < 0 {
+			m.currentCoreUsages[i] = 0
+		}
+		if m.currentCoreUsages[i] > 100 {
+			m.currentCoreUsages[i] = 100
+		}
+	}
+	
+	return m.currentCoreUsages
+}
+
+// displayHelpPage renders the comprehensive help screen showing all available
+// controls, time scale options, display explanations, and temperature legend.
+// Provides detailed information about how to use the monitoring application.
+func (m *Monitor) displayHelpPage() {
+	fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor - %s ===%s\r\n\r\n", colorGreen, tr("Help"), colorReset)
+	
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, tr("Controls:"), colorReset)
+	keys := m.cfg.KeyBindings
+	if m.stressAvailable {
+		fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionStress), colorReset, tr("Toggle stress test ON/OFF"))
+	} else {
+		fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorDarkYellow, keys.label(actionStress), colorReset, tr("Toggle stress test (stress command not available)"))
+	}
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionNetStress), colorReset, tr("Toggle iperf3 network stress ON/OFF"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionDiskStress), colorReset, tr("Toggle fio disk stress ON/OFF"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionZoomIn), colorReset, tr("Zoom in (shorter time scale)"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionZoomOut), colorReset, tr("Zoom out (longer time scale)"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionCoreView), colorReset, tr("Switch core view (grid/vertical bars/heatmap)"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionFreqBars), colorReset, tr("Core bars show usage or clock frequency"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionHeatmapPrev)+" "+keys.label(actionHeatmapNext), colorReset, tr("Previous/next heatmap page"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionGraph), colorReset, tr("Switch graph (temperature/stacked/dual-axis/sensors/power/latency/network/PSU/clock)"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionSplit), colorReset, fmt.Sprintf(tr("Split view: two graphs side by side (%s switches pane)"), keys.label(actionSplitFocus)))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionSensors), colorReset, tr("Choose temperature sensors"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionOverclock), colorReset, tr("Overclocking detail (clocks, multipliers, boost residency)"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionBandwidth), colorReset, tr("Memory bandwidth and cache occupancy per resctrl group"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionCoreHistory), colorReset, tr("Core history (usage or temperature per core over time)"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionScatter), colorReset, tr("Clock against temperature or power (throttle curve)"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionWakeups), colorReset, tr("Wakeups per core and process (what keeps cores out of deep idle)"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionAttribution), colorReset, tr("CPU by user, cgroup and priority (who is behind the load)"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionTopProcs), colorReset, tr("Show/hide the busiest processes under the core bars"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionMark), colorReset, tr("Start/end a bookmarked range (A and B)"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionPause), colorReset, tr("Pause/resume the graphs to inspect a spike"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionReset), colorReset, tr("Reset min/max, history and statistics"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionCompare), colorReset, tr("Compare bookmarked ranges A and B side by side"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionTimedStress), colorReset, fmt.Sprintf(tr("Timed stress run of %s with a report"), m.cfg.Stress.Duration))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionBaseline), colorReset, tr("Measure the idle baseline the readings are compared against"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, keys.label(actionHelp), colorReset, tr("Toggle this help page"))
+	fmt.Fprintf(m.out, "  %s%-6s%s - %s\r\n", colorYellow, "ESC/"+keys.label(actionQuit), colorReset, tr("Exit help or quit application"))
+	fmt.Fprintf(m.out, "  %sCtrl+C%s - %s\r\n\r\n", colorYellow, colorReset, tr("Quit application"))
+	
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, tr("Time Scales:"), colorReset)
+	fmt.Fprintf(m.out, "  15s - 24h - %s\r\n\r\n", fmt.Sprintf(tr("%s halves and %s doubles the window, which always ends now"), keys.label(actionZoomIn), keys.label(actionZoomOut)))
+	
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, tr("CPU Core Bars:"), colorReset)
+	fmt.Fprintf(m.out, "  %s\r\n", tr("Height - CPU usage (0-100%)"))
+	fmt.Fprintf(m.out, "  %s\r\n", tr("Color  - Core temperature (estimated where no per-core sensor)"))
+	fmt.Fprintf(m.out, "  %s\r\n\r\n", tr("Bars:  - ▁▂▃▄▅▆▇█ (0% to 100%)"))
+	
+	m.displayTemperatureLegend()
+	fmt.Fprintf(m.out, "%s"+tr("Press %s, ESC, or %s to return to main view")+"%s\r\n", colorYellow, keys.label(actionHelp), keys.label(actionQuit), colorReset)
+}
+
+// displayTemperatureLegend shows a color-coded temperature reference chart
+// with temperature ranges from Cool (40°C) to Critical (95°C). Each range
+// is displayed with its corresponding color for easy interpretation.
+func (m *Monitor) displayTemperatureLegend() {
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, tr("Temperature Legend:"), colorReset)
+	
+	// Show temperature ranges with their colors
+	tempRanges := []struct {
+		temp  float64
+		label string
+	}{
+		{40, "Cool"},
+		{50, "Normal"},
+		{65, "Warm"},
+		{75, "Hot"},
+		{85, "Very Hot"},
+		{95, "Critical"},
+	}
+	
+	// First line: color blocks and labels
+	for i, tempRange := range tempRanges {
+		color := getTempColor(tempRange.temp)
+		fmt.Fprintf(m.out, "%s█%s%s", color, colorReset, tr(tempRange.label))
+		if i < len(tempRanges)-1 {
+			fmt.Fprint(m.out, " ")
+		}
+	}
+	fmt.Fprint(m.out, "\r\n")
+	
+	// Second line: temperature values aligned under color blocks
+	for i, tempRange := range tempRanges {
+		tempStr := fmt.Sprintf("%.0f%s", convertTemp(tempRange.temp), activeLocale.tempUnit)
+		if i < len(tempRanges)-1 {
+			// Pad to align next temp under next color block (+2 for "█" and the space between entries)
+			tempStr = padRight(tempStr, utf8.RuneCountInString(tr(tempRange.label))+2)
+		}
+		fmt.Fprint(m.out, tempStr)
+	}
+	fmt.Fprint(m.out, "\r\n\r\n")
+}
+
+// displayCPUCores renders the CPU core usage visualization as colored
+// bar characters arranged in a grid. Bar height represents usage percentage,
+// and color indicates core temperature (estimated where no per-core sensor,
+// from usage and package temp).
+func (m *Monitor) displayCPUCores(coreUsages []float64, currentTemp float64) {
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, m.coreBarsTitle(), colorReset)
+	
+	if m.coreView == coreViewVertical {
+		m.displayVerticalCores(coreUsages, currentTemp)
+		fmt.Fprint(m.out, "\r\n") // Extra line before temperature legend
+		m.displayTemperatureLegend()
+		return
+	}
+	if m.coreView == coreViewHeatmap {
+		m.displayHeatmap(coreUsages, currentTemp)
+		fmt.Fprint(m.out, "\r\n")
+		m.displayTemperatureLegend()
+		return
+	}
+	
+	cols, rows := getGridDimensions(m.cores)
+	temps := m.coreTemperatures(coreUsages, currentTemp)
+	heights := m.coreBarHeights(coreUsages)
+	
+	for row := 0; row < rows; row++ {
+		rowStart := row * cols
+		
+		// Display single-line bars
+		fmt.Fprint(m.out, "  ")
+		for col := 0; col < cols; col++ {
+			idx := rowStart + col
+			if idx < m.cores {
+				usage := heights[idx]
+				
+				// Get color based on the core's temperature
+				color := getTempColor(temps[idx])
+				
+				// Map usage (0-100%) to bar character (1-8, minimum ▁)
+				barIndex := int(usage / 12.5) // 100% / 8 = 12.5% per bar level
+				if barIndex > 8 {
+					barIndex = 8
+				}
+				if barIndex < 1 {
+					barIndex = 1 // Always show at least ▁
+				}
+				
+				// Display colored bar character
+				fmt.Fprintf(m.out, "%s%s%s", color, barChars[barIndex], colorReset)
+			} else {
+				fmt.Fprint(m.out, " ")
+			}
+			
+			if col < cols-1 {
+				fmt.Fprint(m.out, " ")
+			}
+		}
+		fmt.Fprint(m.out, "\r\n")
+		
+		// Print percentages below the bars
+		// fmt.Fprint(m.out, "  ")
+		// for col := 0; col < cols; col++ {
+		// 	idx := rowStart + col
+		// 	if idx < m.cores {
+		// 		fmt.Fprintf(m.out, "%3.0f%%", coreUsages[idx])
+		// 	} else {
+		// 		fmt.Fprint(m.out, "    ")
+		// 	}
+		// 	if col < cols-1 {
+		// 		fmt.Fprint(m.out, " ")
+		// 	}
+		// }
+		// fmt.Fprint(m.out, "\r\n")
+	}
+	
+	fmt.Fprint(m.out, "\r\n") // Extra line before temperature legend
+	// Display temperature legend
+	m.displayTemperatureLegend()
+}
+
+// drawCombinedGraph renders the historical CPU usage and temperature chart.
+// Height represents CPU usage percentage (0-100%) and color represents
+// temperature at each point in time. Shows current values and time scale info.
+func (m *Monitor) drawCombinedGraph(currentCpu, currentTemp float64) {
+	fmt.Fprintf(m.out, "%s%s%s %s %s%s%s / %s%s%s%*s\r\n", 
+		colorCyan, tr("CPU Usage & Temperature Graph"), colorReset, tr("Current:"),
+		colorYellow, formatPercent(currentCpu, 1), colorReset, colorYellow, formatTemp(currentTemp, 1), colorReset, 20, "")
+	
+	// Draw 5 rows
+	axis := m.cpuAxis()
+	ranges := cpuAxisLabels(axis)
+	
+	// Use stable display buffer - no recalculation!
+	// Height follows CPU usage and the block color follows [graph_color] cpu, temperature by default
+	values := make([]float64, baseGraphWidth)
+	colors := m.seriesColors(m.cfg.CPUGraphColor)
+	for i := 0; i < baseGraphWidth; i++ {
+		values[i] = axis.fraction(m.displayBuffer[i].cpu)
+	}
+	grid := plotSeries(values, colors, 5, m.cfg.CPUGraphStyle)
+	lows, highs := m.cpuEnvelope(axis)
+	plotEnvelope(grid, lows, highs, colors)
+	
+	for row := 4; row >= 0; row-- {
+		fmt.Fprintf(m.out, "%s%s%s", colorCyan, ranges[4-row], colorReset)
+		for _, cell := range grid[row] {
+			if cell.glyph != "" {
+				fmt.Fprintf(m.out, "%s%s%s", cell.color, cell.glyph, colorReset)
+			} else {
+				fmt.Fprint(m.out, " ")
+			}
+		}
+		fmt.Fprint(m.out, "\r\n")
+	}
+	m.drawPhaseRow()
+	m.drawMemoryGraph()
+	m.drawDiskRow()
+	m.drawPowerRow()
+	
+	fmt.Fprintf(m.out, "        %s%s%s\r\n", colorYellow, m.zoomHint(), colorReset)
+	fmt.Fprintf(m.out, "        %s%-10s%s\r\n", colorCyan, formatWindow(m.window), colorReset)
+}
+
+// run starts the main monitoring loop with terminal setup, signal handling,
+// and separate tickers for data polling (500ms) and rendering (60fps).
+// Handles user input for stress testing and view controls until exit.
+func (m *Monitor) run() {
+	// Setup terminal
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		panic(err)
+	}
+	m.oldTermState = oldState
+	enableVirtualTerminal()
+	
+	// Frames reach the terminal from a goroutine, so a slow one cannot hold up the keys
+	m.terminal = newFrameWriter(os.Stdout)
+	m.out = m.terminal
+	if m.recorder != nil {
+		m.out = io.MultiWriter(m.out, m.recorder)
+	}
+	
+	if m.cfg.Accessible {
+		// Plain scrolling output: no cursor tricks for the screen reader to trip over
+		m.lastAnnounce = time.Now()
+		m.say(tr("Kode Kronical Perf Monitor started. Press %s for help."), m.cfg.KeyBindings.label(actionHelp))
+	} else {
+		// Clear screen and hide cursor
+		fmt.Fprint(m.out, clearScreen)
+		fmt.Fprint(m.out, hideCursor)
+	}
+	
+	if m.cfg.TerminalTitle {
+		fmt.Fprint(m.out, saveTitle) // Restored on exit
+	}
+	
+	if m.cfg.StartupChecks {
+		m.startChecks()
+	}
+	if m.cfg.Baseline.Startup && !m.stressRunning {
+		m.startBaseline()
+	}
+	
+	// Setup signal handling
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	
+	// Input channel, with escape sequences turned into keys
+	inputChan := readKeys(os.Stdin)
+	
+	// Separate tickers for polling (500ms for frequent sampling) and rendering (60fps)
+	pollTicker := time.NewTicker(m.cfg.PollInterval)
+	renderTicker := time.NewTicker(frameInterval) // ~60fps
+	defer pollTicker.Stop()
+	defer renderTicker.Stop()
+	
+	// Variables for current display values
+	var currentTemp float64
+	var currentTotalUsage float64
+	
+	for {
+		select {
+		case <-sigChan:
+			return
+			
+		case key := <-inputChan:
+			if m.showStartupChecks {
+				if !m.handleStartupChecksKey(key) {
+					return
+				}
+			} else if m.showSensors {
+				if !m.handleSensorPickerKey(key) {
+					return
+				}
+			} else if m.showOverclock {
+				if !m.handleOverclockKey(key) {
+					return
+				}
+			} else if m.showBandwidth {
+				if !m.handleBandwidthKey(key) {
+					return
+				}
+			} else if m.showCoreHistory {
+				if !m.handleCoreHistoryKey(key) {
+					return
+				}
+			} else if m.showScatter {
+				if !m.handleScatterKey(key) {
+					return
+				}
+			} else if m.showWakeups {
+				if !m.handleWakeupsKey(key) {
+					return
+				}
+			} else if m.showAttribution {
+				if !m.handleAttributionKey(key) {
+					return
+				}
+			} else if m.showBookmarks {
+				if !m.handleBookmarksKey(key) {
+					return
+				}
+			} else if m.showTimedReport {
+				if !m.handleTimedReportKey(key) {
+					return
+				}
+			} else if m.showHelp {
+				// In help mode, H/ESC/Q return to main view
+				switch m.pageAction(key, actionHelp) {
+				case actionClose:
+					m.showHelp = false
+					fmt.Fprint(m.out, clearScreen) // Clear screen when returning to main view
+				case actionQuit:
+					return
+				}
+			} else if !m.handleMainKey(key) {
+				return
+			}
+			
+		case <-pollTicker.C:
+			if m.pollingSuspended() {
+				continue
+			}
+			currentTotalUsage, currentTemp = m.pollTick()
+			if m.timedQuit && m.timed == nil {
+				return // The timed run of --stress-duration is over
+			}
+			
+		case <-m.resets:
+			m.resetSession()
+			
+		case <-renderTicker.C:
+			// Render at 60fps with continuously interpolated values
+			now := time.Now()
+			if !m.terminal.ready() {
+				continue // Still writing the previous frame: skip this one rather than queue it
+			}
+			
+			if m.cfg.Accessible {
+				// Accessible mode replaces the frame with periodic status lines
+				m.announce(now, currentTotalUsage, currentTemp)
+				m.recordCastFrame(now)
+				m.terminal.flush()
+				continue
+			}
+			
+			m.drawFrame(currentTotalUsage, currentTemp)
+			m.recordCastFrame(now)
+			m.terminal.flush()
+			
+			recordFrame(now, time.Now(), m.lastRenderTime)
+			m.lastRenderTime = now
+		}
+	}
+}
+
+// pollTick takes a sample, feeds it to the sinks, smoothing buffers and
+// open pages, and records a history point when the current time scale is
+// due. It returns the smoothed total CPU usage and the temperature.
+func (m *Monitor) pollTick() (float64, float64) {
+	// Poll for new CPU data frequently for smooth averaging
+	sample := m.nextSample()
+	currentTemp := sample.Temp
+	newCoreUsages := sample.Cores
+	m.writeSinks(&sample)
+	m.updateCoolingAlert(sample.Cooling)
+	m.updateAlerts(sample.Alerts)
+	if m.remote == nil {
+		// A remote's stress is its own to stop; stopping it here would
+		// only log and show a safety stop that never happened
+		m.checkStressSafety(&sample)
+		m.checkTimedStress(&sample)
+	}
+	m.psuReading = psuReading{input: sample.PSUInput, output: sample.PSUOutput, rails: sample.PSURails}
+	m.health = healthReading{zombies: sample.Zombies, threads: sample.Threads, threadMax: sample.ThreadMax, fds: sample.FDs, fdMax: sample.FDMax}
+	m.entropyBits = sample.Entropy
+	m.load = loadAverage{running: sample.Running, tasks: sample.Tasks, ok: sample.Load != nil}
+	m.disks = sample.Disks
+	m.gpuReadings = sample.GPUs
+	m.fans = sample.Fans
+	m.updateFanRanges(sample.Fans)
+	m.batteries = sample.Batteries
+	copy(m.load.avg[:], sample.Load)
+	m.mem = memInfo{total: sample.MemTotal, used: sample.MemUsed, swapTotal: sample.SwapTotal, swapUsed: sample.SwapUsed}
+	
+	// Update sample buffer with new readings
+	m.updateSampleBuffer(newCoreUsages)
+	m.coreTempReadings = sample.CoreTemps
+	m.coreHistory.add(newCoreUsages, m.coreTemperatures(newCoreUsages, currentTemp))
+	m.lastPollTime = time.Now()
+	
+	// Update history with rolling average for smoother graph
+	if currentTemp > 0 {
+		m.updateMinMax(currentTemp)
+	}
+	
+	// Use rolling average for total CPU history and combine with temp
+	avgCores := m.calculateRollingAverage()
+	avgTotal := 0.0
+	for _, core := range avgCores {
+		avgTotal += core
+	}
+	currentTotalUsage := avgTotal / float64(len(avgCores))
+	if m.container != nil {
+		currentTotalUsage = sample.CPU // Against the quota rather than the host
+	}
+	m.updateBaseline(&sample, avgCores)
+	
+	if m.cfg.TerminalTitle {
+		m.updateTitle(currentTotalUsage, currentTemp)
+	}
+	
+	// Refresh the other sensors shown on screen
+	if m.showSensors {
+		m.readSensorPicker()
+	}
+	if m.showBandwidth {
+		m.resctrl.sample()
+	}
+	if m.showWakeups {
+		m.wakeups.sample()
+	}
+	if m.showAttribution {
+		m.attribution.sample()
+	}
+	if m.topProcs != nil {
+		m.topProcs.sample()
+	}
+	m.readSecondarySensors()
+	m.clocks.sample()
+	m.smu.sample()
+	m.recordScatter(currentTemp, sample.Power)
+	
+	point := historyPoint{
+		cpu:  currentTotalUsage,
+		temp: currentTemp,
+		gpu:  sample.GPU,
+		disk: sample.Disk,
+		net:  sample.Net,
+		sensors: append([]float64(nil), m.secondaryTemps...),
+		power:   domainWatts(sample.Power),
+	}
+	point.irq = m.irqUsage
+	point.iperf, _ = m.netStress.throughput()
+	point.psuIn, point.psuOut = sample.PSUInput, sample.PSUOutput
+	point.mem, point.swap = m.mem.ramPercent(), m.mem.swapPercent()
+	point.diskRead, point.diskWrite, _ = diskTotals(sample.Disks)
+	point.latencyAvg, point.latencyMax, _ = m.latency.take()
+	point.mhz, point.throttled = m.clocks.meanKHz()/1000, sample.Throttled
+	point.limited = m.clockLimited(point)
+	m.history.add(point)
+	m.bookmarks.add(point.cpu, point.temp, m.packagePower(point))
+	m.refreshGraph()
+	
+	return currentTotalUsage, currentTemp
+}
+
+// drawFrame draws one frame of the current page to m.out, starting at the
+// top-left corner. Core bars are interpolated a step further each frame.
+func (m *Monitor) drawFrame(currentTotalUsage, currentTemp float64) {
+	// Get smoothly interpolated core usages
+	interpolatedCores := m.interpolateCoreUsages()
+	
+	if shown := m.showSMULimits(); shown != m.smuShown {
+		// Frame height changes with the AMD limit bars
+		m.smuShown = shown
+		fmt.Fprint(m.out, clearScreen)
+	}
+	if shown := m.blockedVisible(); shown != m.blockedLine {
+		// The I/O wait line comes and goes
+		m.blockedLine = shown
+		fmt.Fprint(m.out, clearScreen)
+	}
+	if shown := m.cfg.healthWarning(m.health, untranslated) != ""; shown != m.healthAlert {
+		// So does the health warning
+		m.healthAlert = shown
+		fmt.Fprint(m.out, clearScreen)
+	}
+	if shown := m.clockVisible(); shown != m.clockLine {
+		// And the clock line, once a daemon answers or the clock steps
+		m.clockLine = shown
+		fmt.Fprint(m.out, clearScreen)
+	}
+	if rows := m.gpuPanelRows(); rows != m.gpuRows {
+		// And the GPU panel, once nvidia-smi reports
+		m.gpuRows = rows
+		fmt.Fprint(m.out, clearScreen)
+	}
+	
+	// Display
+	fmt.Fprint(m.out, moveCursor)
+	
+	if m.showStartupChecks {
+		m.displayStartupChecksPage()
+	} else if m.showSensors {
+		m.displaySensorPicker()
+	} else if m.showOverclock {
+		m.displayOverclockPage()
+	} else if m.showBandwidth {
+		m.displayBandwidthPage()
+	} else if m.showCoreHistory {
+		m.displayCoreHistoryPage()
+	} else if m.showScatter {
+		m.displayScatterPage()
+	} else if m.showWakeups {
+		m.displayWakeupsPage()
+	} else if m.showAttribution {
+		m.displayAttributionPage()
+	} else if m.showBookmarks {
+		m.displayBookmarksPage()
+	} else if m.showTimedReport {
+		m.displayTimedReportPage()
+	} else if m.showHelp {
+		// Show help page
+		m.displayHelpPage()
+	} else {
+		// Show main monitoring view with minimal instructions
+		fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor ===%s  %s%s%s\r\n", colorGreen, colorReset, colorYellow, fmt.Sprintf(tr("Press %s for help"), m.cfg.KeyBindings.label(actionHelp)), colorReset)
+	
+		var status string
+		if !m.stressAvailable {
+			status = fmt.Sprintf("%s[STRESS N/A]%s", colorDarkYellow, colorReset)
+		} else if m.stressRunning {
+			status = fmt.Sprintf("%s[STRESS ON]%s", colorRed, colorReset)
+		} else {
+			status = fmt.Sprintf("%s[STRESS OFF]%s", colorGreen, colorReset)
+		}
+		
+		veryHotColor := getTempColor(85.0) // Same color as "Very Hot" in temperature legend
+		fmt.Fprintf(m.out, "%s %s%s  %s%s%s %s%s%s  %s%s%s %s%s%s  %s%s%s %s%s%s",
+			tr("Status:"), status, m.netStressStatus()+m.diskStressStatus()+m.safetyStatus()+m.emergencyStatus()+m.sensorSaveStatus()+m.timedStatus()+m.baselineStatus()+m.bookmarkStatus()+m.pauseStatus()+m.readOnlyStatus()+m.remoteStatus(),
+			colorBlue, tr("Current:"), colorReset, colorYellow, formatTemp(currentTemp, 1), colorReset,
+			colorBlue, tr("Min:"), colorReset, colorGreen, formatTemp(m.minTemp, 1), colorReset,
+			colorBlue, tr("Max:"), colorReset, veryHotColor, formatTemp(m.maxTemp, 1), colorReset)
+		if headroom, ok := m.headroom(currentTemp); ok {
+			// Distance to the throttle or warning point
+			label := tr("Δ to max:")
+			if m.tjMax() > 0 && m.cfg.ThermalLimit == 0 {
+				label = tr("Δ to TjMax:")
+			}
+			fmt.Fprintf(m.out, "  %s%s%s %s%s%s", colorBlue, label, colorReset,
+				headroomColor(headroom), formatTempDelta(headroom, 0), colorReset)
+		}
+		fmt.Fprint(m.out, "\r\n\r\n")
+		m.displayAlerts()
+		m.displaySafetyStop()
+		m.displayBaseline(currentTotalUsage, currentTemp)
+		m.displayFans()
+		m.displaySecondarySensors()
+		m.displayAmbient(currentTemp)
+		m.displayCooling()
+		m.displayPSU()
+		m.displayBattery()
+		m.displayContainer()
+		m.displayLoad()
+		m.displayBlocked()
+		m.displayHealth()
+		m.displayEntropy()
+		m.displayClock()
+		if m.smuShown {
+			m.displaySMULimits()
+		}
+		
+		// Display CPU cores with smooth interpolation and temperature colors
+		m.displayCPUCores(interpolatedCores, currentTemp)
+		m.displayGPUs()
+		m.displayTopProcs()
+		
+		if m.split.on {
+			m.drawSplitView()
+			return
+		}
+		
+		// Draw the selected history graph
+		switch m.graphMode {
+		case graphStacked:
+			m.drawStackedGraph()
+		case graphDualAxis:
+			m.drawDualAxisGraph(currentTotalUsage, currentTemp)
+		case graphMultiTemp:
+			m.drawMultiTempGraph(currentTemp)
+		case graphPower:
+			m.drawPowerGraph()
+		case graphLatency:
+			m.drawLatencyGraph()
+		case graphNetwork:
+			m.drawNetworkGraph()
+		case graphPSU:
+			m.drawPSUGraph()
+		case graphClock:
+			m.drawClockGraph()
+		default:
+			m.drawCombinedGraph(currentTotalUsage, currentTemp)
+		}
+	}
+}
+
+// showVersion displays version and build information to stdout.
+func showVersion() {
+	version.Print("Kode Kronical Perf Monitor")
+}
+
+// showUsage displays command-line usage information.
+func showUsage() {
+	fmt.Printf("Usage: %s [options]\n", os.Args[0])
+	fmt.Printf("       %s check [options]   (Nagios/Icinga plugin; see check --help)\n", os.Args[0])
+	fmt.Printf("       %s snmp [options]    (net-snmp pass_persist handler; see snmp --help)\n", os.Args[0])
+	fmt.Printf("       %s report [options]  (summary of the history store; see report --help)\n", os.Args[0])
+	fmt.Printf("       %s certify [options] (burn-in run with a signed report; see certify --help)\n", os.Args[0])
+	fmt.Printf("       %s diff [options] A B (compare two sessions or snapshots; see diff --help)\n", os.Args[0])
+	fmt.Printf("       %s merge [options] FILE... (recordings of several machines on one timeline; see merge --help)\n", os.Args[0])
+	fmt.Printf("       %s fleet [options] HOST:PORT... (table of many agents; see fleet --help)\n", os.Args[0])
+	fmt.Printf("       %s connect [options] HOST:PORT (display of a remote agent; see connect --help)\n", os.Args[0])
+	fmt.Printf("       %s helper [options]  (run as root to read root-only sensors for users; see helper --help)\n\n", os.Args[0])
+	fmt.Println("A real-time CPU performance monitoring application")
+	fmt.Println("Options:")
+	fmt.Println("  -v, --version        Show version information")
+	fmt.Println("  -h, --help           Show this help message")
+	fmt.Println("  -c, --config PATH    Config file (default ~/.config/kkperf/config.toml)")
+	fmt.Println("  -a, --accessible     Screen-reader friendly plain-text output")
+	fmt.Println("  --read-only          Ignore the keys that start stress runs or change settings")
+	fmt.Println("  --theme NAME         Color theme: default or high-contrast")
+	fmt.Println("  --title              Show live CPU usage and temperature in the terminal title")
+	fmt.Println("  --format TEMPLATE    Print one sample using a Go text/template and exit")
+	fmt.Println("  --headless           Run without the TUI, only feeding the exporters given or configured")
+	fmt.Println("  --textfile PATH      Run without the TUI, writing Prometheus metrics to PATH")
+	fmt.Println("  --json               Run without the TUI, writing every poll to stdout as one line of JSON")
+	fmt.Println("  --listen ADDR        Serve /metrics, /debug/pprof/ and /debug/vars on ADDR")
+	fmt.Println("  --web ADDR           Serve a live dashboard for browsers on ADDR")
+	fmt.Println("  --socket PATH        Answer GET and SUBSCRIBE requests on a UNIX socket at PATH")
+	fmt.Println("  --fifo PATH          Write every poll as one line to a FIFO at PATH")
+	fmt.Println("  --log-csv PATH       Append every poll (CPU, per-core usage, temperature) to a CSV file")
+	fmt.Println("  --record PATH        Record the display to an asciinema cast file")
+	fmt.Println("  --stress-duration D  Run stress for D, e.g. 10m, then quit and print a report")
+	fmt.Println("  --debug              Log sensor discovery, parse errors and exporter failures to the [debug] file")
+	fmt.Println("  --telegraf MODE      Act as a Telegraf input: \"exec\" (one sample) or \"execd\" (long-running)")
+	fmt.Println("")
+	fmt.Println("Controls (during monitoring; the [keys] config section remaps them):")
+	fmt.Println("  SPACE   - Toggle CPU stress test")
+	fmt.Println("  N       - Toggle iperf3 network stress")
+	fmt.Println("  D       - Toggle fio disk stress")
+	fmt.Println("  W       - Zoom in (shorter time scale)")  
+	fmt.Println("  S       - Zoom out (longer time scale)")
+	fmt.Println("  V       - Switch core view (grid/vertical bars/heatmap)")
+	fmt.Println("  F       - Core bars show usage or clock frequency")
+	fmt.Println("  [ ]     - Previous/next heatmap page")
+	fmt.Println("  G       - Switch graph (temperature/stacked/dual-axis/sensors/power/latency/network/PSU/clock)")
+	fmt.Println("  L       - Split view: two graphs side by side (Tab switches pane)")
+	fmt.Println("  T       - Choose temperature sensors")
+	fmt.Println("  O       - Overclocking detail (clocks, multipliers, boost residency)")
+	fmt.Println("  B       - Memory bandwidth and cache occupancy per resctrl group")
+	fmt.Println("  C       - Core history (usage or temperature per core over time)")
+	fmt.Println("  X       - Clock against temperature or power (throttle curve)")
+	fmt.Println("  I       - Wakeups per core and process (what keeps cores out of deep idle)")
+	fmt.Println("  U       - CPU by user, cgroup and priority (who is behind the load)")
+	fmt.Println("  P       - Show/hide the busiest processes under the core bars")
+	fmt.Println("  M       - Start/end a bookmarked range (A and B)")
+	fmt.Println("  E       - Pause/resume the graphs to inspect a spike")
+	fmt.Println("  R       - Reset min/max, history and statistics")
+	fmt.Println("  A       - Compare bookmarked ranges A and B side by side")
+	fmt.Println("  K       - Timed stress run of [stress] duration, then a report")
+	fmt.Println("  Y       - Measure the idle baseline the readings are compared against")
+	fmt.Println("  H       - Show help page")
+	fmt.Println("  ESC/Q   - Exit")
+	fmt.Println("  Ctrl+C  - Quit")
+	fmt.Println("")
+	fmt.Println(formatUsage)
+}
+
+// options holds the parsed command-line flags. Flags that mirror a config
+// setting override the value from the config file.
+type options struct {
+	showVersion bool
+	configPath  string
+	accessible  bool
+	readOnly    bool
+	theme       string
+	title       bool
+	format      string
+	textfile    string
+	telegraf    string
+	listen      string
+	socket      string
+	fifo        string
+	logCSV      string
+	record      string
+	headless    bool
+	debug       bool
+	json        bool
+	web         string
+	stressFor   time.Duration
+}
+
+// parseOptions parses the command-line arguments. Both the short and long
+// form of each flag are accepted, with one or two leading dashes.
+func parseOptions(args []string) (*options, error) {
+	opts := &options{configPath: configPath()}
+
+	fs := flag.NewFlagSet("kkperf", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // Errors and usage are reported by main
+	fs.BoolVar(&opts.showVersion, "v", false, "")
+	fs.BoolVar(&opts.showVersion, "version", false, "")
+	fs.StringVar(&opts.configPath, "c", opts.configPath, "")
+	fs.StringVar(&opts.configPath, "config", opts.configPath, "")
+	fs.BoolVar(&opts.accessible, "a", false, "")
+	fs.BoolVar(&opts.accessible, "accessible", false, "")
+	fs.BoolVar(&opts.readOnly, "read-only", false, "")
+	fs.StringVar(&opts.theme, "theme", "", "")
+	fs.BoolVar(&opts.title, "title", false, "")
+	fs.StringVar(&opts.format, "format", "", "")
+	fs.StringVar(&opts.textfile, "textfile", "", "")
+	fs.StringVar(&opts.telegraf, "telegraf", "", "")
+	fs.StringVar(&opts.listen, "listen", "", "")
+	fs.StringVar(&opts.socket, "socket", "", "")
+	fs.StringVar(&opts.fifo, "fifo", "", "")
+	fs.StringVar(&opts.logCSV, "log-csv", "", "")
+	fs.StringVar(&opts.record, "record", "", "")
+	fs.BoolVar(&opts.headless, "headless", false, "")
+	fs.BoolVar(&opts.debug, "debug", false, "")
+	fs.BoolVar(&opts.json, "json", false, "")
+	fs.StringVar(&opts.web, "web", "", "")
+	fs.DurationVar(&opts.stressFor, "stress-duration", 0, "")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unknown argument: %s", fs.Arg(0))
+	}
+	return opts, nil
+}
+
+// Main is the entry point of the kkperf TUI. Parses flags, loads the config
+// file, creates a new Monitor instance, sets up cleanup handling, and starts
+// the monitoring loop.
+func Main() {
+	// Subcommands take their own flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		case "snmp":
+			os.Exit(runSNMP(os.Args[2:]))
+		case "report":
+			os.Exit(runReport(os.Args[2:]))
+		case "certify":
+			os.Exit(runCertify(os.Args[2:]))
+		case "diff":
+			os.Exit(runDiff(os.Args[2:]))
+		case "merge":
+			os.Exit(runMerge(os.Args[2:]))
+		case "fleet":
+			os.Exit(runFleet(os.Args[2:]))
+		case "connect":
+			os.Exit(runConnect(os.Args[2:]))
+		case "helper":
+			os.Exit(runHelper(os.Args[2:]))
+		}
+	}
+
+	// Handle command-line arguments
+	opts, err := parseOptions(os.Args[1:])
+	if err == flag.ErrHelp {
+		showUsage()
+		return
+	}
+	if err != nil {
+		fmt.Printf("%v\n\n", err)
+		showUsage()
+		os.Exit(1)
+	}
+	if opts.showVersion {
+		showVersion()
+		return
+	}
+
+	cfg, err := loadConfig(opts.configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.accessible {
+		cfg.Accessible = true
+	}
+	if opts.readOnly {
+		cfg.ReadOnly = true
+	}
+	if opts.theme != "" {
+		cfg.Theme = opts.theme
+	}
+	if opts.title {
+		cfg.TerminalTitle = true
+	}
+	if opts.listen != "" {
+		cfg.HTTP.Listen = opts.listen
+	}
+	if opts.web != "" {
+		cfg.Web.Listen = opts.web
+	}
+	if opts.socket != "" {
+		cfg.Socket.Path = opts.socket
+	}
+	if opts.fifo != "" {
+		cfg.FIFO.Path = opts.fifo
+	}
+	if opts.logCSV != "" {
+		cfg.CSV.Path = opts.logCSV
+	}
+	if opts.record != "" {
+		cfg.Record.Path = opts.record
+	}
+	activeLocale = resolveLocale(cfg)
+	if err := applyTheme(cfg.Theme); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if opts.format != "" {
+		if err := runFormat(cfg, opts.format); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Exporter-only modes run without the TUI
+	headless := opts.headless || opts.json
+	if opts.textfile != "" {
+		cfg.Prometheus.Textfile = opts.textfile
+		headless = true
+	}
+	if err := cfg.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.debug {
+		// Before NewMonitor, whose samplers log what they discover
+		if err := openDebugLog(cfg.Debug.File, cfg.Debug.Level); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer closeDebugLog()
+		logInfo("kkperf started", "version", version.Version, "config", opts.configPath)
+	}
+
+	monitor := NewMonitor(cfg)
+	if err := monitor.openSinks(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.json {
+		if opts.telegraf != "" {
+			fmt.Fprintln(os.Stderr, "Error: --json and --telegraf both write to stdout")
+			os.Exit(1)
+		}
+		monitor.sinks = append(monitor.sinks, newJSONSink(os.Stdout))
+	}
+	if opts.telegraf != "" {
+		if err := monitor.runTelegraf(opts.telegraf); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if headless {
+		if len(monitor.sinks) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: no exporter is enabled; pass --json, --textfile, --listen, --web, --socket, --fifo or --log-csv, or configure one in the config file")
+			os.Exit(1)
+		}
+		checkOrphans(nil, os.Stderr, false)
+		monitor.runHeadless()
+		return
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		// Under systemd or cron there is no terminal to draw on
+		fmt.Fprintln(os.Stderr, "Error: standard input is not a terminal; use --headless to run without the TUI")
+		os.Exit(1)
+	}
+	checkOrphans(os.Stdin, os.Stdout, true)
+	if cfg.Record.Path != "" {
+		if err := monitor.startRecording(cfg.Record.Path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if opts.stressFor != 0 {
+		if opts.stressFor < 0 || !monitor.stressAvailable {
+			fmt.Fprintln(os.Stderr, "Error: --stress-duration needs a positive duration and an available stress backend")
+			os.Exit(1)
+		}
+		monitor.timedQuit = true
+		monitor.startTimedStress(opts.stressFor)
+	}
+	defer monitor.cleanup()
+	monitor.run()
+}
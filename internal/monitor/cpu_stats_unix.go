@@ -0,0 +1,78 @@
+//go:build !windows
+
+package monitor
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// getCPUStats reads and parses CPU usage statistics from /proc/stat.
+// Returns an array of CPUStats where index 0 is total CPU and subsequent
+// indices represent individual CPU cores. Falls back to cached data on error.
+func (m *Monitor) getCPUStats() []CPUStats {
+	file, err := os.Open(filepath.Join(procDir, "stat"))
+	if err != nil {
+		logWarn("CPU statistics not readable", "err", err)
+		return m.lastCPUStats
+	}
+	defer file.Close()
+
+	stats := m.spareCPUStats
+	if len(stats) != m.cores+1 {
+		stats = make([]CPUStats, m.cores+1)
+	}
+	for i := range stats {
+		stats[i] = CPUStats{}
+	}
+	scanner := bufio.NewScanner(file)
+	cpuIndex := 0
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "cpu") {
+			break
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			continue
+		}
+
+		// Skip the "cpu" label and parse values
+		for i := 1; i <= 7 && i < len(fields); i++ {
+			val, _ := strconv.ParseUint(fields[i], 10, 64)
+			switch i {
+			case 1:
+				stats[cpuIndex].user = val
+			case 2:
+				stats[cpuIndex].nice = val
+			case 3:
+				stats[cpuIndex].system = val
+			case 4:
+				stats[cpuIndex].idle = val
+			case 5:
+				stats[cpuIndex].iowait = val
+			case 6:
+				stats[cpuIndex].irq = val
+			case 7:
+				stats[cpuIndex].soft = val
+			}
+		}
+
+		cpuIndex++
+		if cpuIndex > m.cores {
+			break
+		}
+	}
+
+	return stats
+}
+
+// cpuStatsSource names what getCPUStats reads, for the startup checks.
+func cpuStatsSource() string {
+	return filepath.Join(procDir, "stat")
+}
@@ -0,0 +1,181 @@
+//go:build windows
+
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	pdh                       = windows.NewLazySystemDLL("pdh.dll")
+	procPdhOpenQuery          = pdh.NewProc("PdhOpenQueryW")
+	procPdhAddEnglishCounter  = pdh.NewProc("PdhAddEnglishCounterW")
+	procPdhCollectQueryData   = pdh.NewProc("PdhCollectQueryData")
+	procPdhGetRawCounterArray = pdh.NewProc("PdhGetRawCounterArrayW")
+)
+
+const pdhMoreData = 0x800007D2 // PDH_MORE_DATA
+
+// pdhRawCounter is PDH_RAW_COUNTER. For the 100 ns timer counters used
+// here, FirstValue is the time counted and SecondValue the timestamp.
+type pdhRawCounter struct {
+	CStatus     uint32
+	TimeStamp   windows.Filetime
+	FirstValue  int64
+	SecondValue int64
+	MultiCount  uint32
+}
+
+// pdhRawCounterItem is PDH_RAW_COUNTER_ITEM_W.
+type pdhRawCounterItem struct {
+	Name     *uint16
+	RawValue pdhRawCounter
+}
+
+// cpuCounters is a PDH query for the per-processor counters that map onto
+// the /proc/stat fields: idle time, and interrupt and DPC time, the
+// Windows counterparts of hard and soft IRQs.
+type cpuCounters struct {
+	query                     windows.Handle
+	processor, interrupt, dpc windows.Handle
+}
+
+var (
+	cpuCountersOnce sync.Once
+	cpuQuery        *cpuCounters // nil when PDH is unavailable
+)
+
+// openCPUCounters opens the PDH query on first use. The Processor
+// Information object names instances "group,number", so machines with
+// more than 64 logical CPUs are covered.
+func openCPUCounters() *cpuCounters {
+	cpuCountersOnce.Do(func() {
+		c := &cpuCounters{}
+		if r, _, _ := procPdhOpenQuery.Call(0, 0, uintptr(unsafe.Pointer(&c.query))); r != 0 {
+			return
+		}
+		for _, counter := range []struct {
+			path   string
+			handle *windows.Handle
+		}{
+			{`\Processor Information(*)\% Processor Time`, &c.processor},
+			{`\Processor Information(*)\% Interrupt Time`, &c.interrupt},
+			{`\Processor Information(*)\% DPC Time`, &c.dpc},
+		} {
+			path, _ := windows.UTF16PtrFromString(counter.path)
+			if r, _, _ := procPdhAddEnglishCounter.Call(uintptr(c.query), uintptr(unsafe.Pointer(path)), 0, uintptr(unsafe.Pointer(counter.handle))); r != 0 {
+				return
+			}
+		}
+		cpuQuery = c
+	})
+	return cpuQuery
+}
+
+// rawCounters returns the raw values of a counter by instance name.
+func rawCounters(counter windows.Handle) map[string]pdhRawCounter {
+	var size, count uint32
+	r, _, _ := procPdhGetRawCounterArray.Call(uintptr(counter), uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&count)), 0)
+	if uint32(r) != pdhMoreData || size == 0 {
+		return nil
+	}
+	buf := make([]uint64, (size+7)/8) // 8-byte aligned for the int64 fields
+	r, _, _ = procPdhGetRawCounterArray.Call(uintptr(counter), uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&count)), uintptr(unsafe.Pointer(&buf[0])))
+	if r != 0 {
+		return nil
+	}
+	items := unsafe.Slice((*pdhRawCounterItem)(unsafe.Pointer(&buf[0])), count)
+	values := make(map[string]pdhRawCounter, count)
+	for _, item := range items {
+		values[windows.UTF16PtrToString(item.Name)] = item.RawValue
+	}
+	return values
+}
+
+// processorInstances returns the "group,number" instances in CPU order,
+// leaving out the _Total instances.
+func processorInstances(values map[string]pdhRawCounter) []string {
+	var names []string
+	for name := range values {
+		if !strings.Contains(name, "_Total") {
+			names = append(names, name)
+		}
+	}
+	key := func(name string) (int, int) {
+		parts := strings.SplitN(name, ",", 2)
+		if len(parts) != 2 {
+			n, _ := strconv.Atoi(name)
+			return 0, n
+		}
+		group, _ := strconv.Atoi(parts[0])
+		n, _ := strconv.Atoi(parts[1])
+		return group, n
+	}
+	sort.Slice(names, func(i, j int) bool {
+		gi, ni := key(names[i])
+		gj, nj := key(names[j])
+		return gi < gj || gi == gj && ni < nj
+	})
+	return names
+}
+
+// getCPUStats reads the processor counters through PDH and converts them
+// into CPUStats in 100 ns units: idle time, interrupt time as irq, DPC
+// time as soft, and the rest of the busy time as system. Index 0 is the
+// sum over all cores. Falls back to cached data on error.
+func (m *Monitor) getCPUStats() []CPUStats {
+	c := openCPUCounters()
+	if c == nil {
+		logWarn("PDH processor counters not available")
+		return m.lastCPUStats
+	}
+	if r, _, _ := procPdhCollectQueryData.Call(uintptr(c.query)); r != 0 {
+		logWarn("PDH query failed", "status", fmt.Sprintf("%#x", r))
+		return m.lastCPUStats
+	}
+	processor := rawCounters(c.processor)
+	interrupt, dpc := rawCounters(c.interrupt), rawCounters(c.dpc)
+	if processor == nil {
+		return m.lastCPUStats
+	}
+
+	stats := m.spareCPUStats
+	if len(stats) != m.cores+1 {
+		stats = make([]CPUStats, m.cores+1)
+	}
+	for i := range stats {
+		stats[i] = CPUStats{}
+	}
+	for i, name := range processorInstances(processor) {
+		if i >= m.cores {
+			break
+		}
+		p := processor[name]
+		// % Processor Time is an inverse timer: it counts idle time
+		idle, busy := uint64(p.FirstValue), uint64(p.SecondValue-p.FirstValue)
+		irq, soft := uint64(interrupt[name].FirstValue), uint64(dpc[name].FirstValue)
+		system := uint64(0)
+		if irq+soft < busy {
+			system = busy - irq - soft
+		}
+		stats[i+1] = CPUStats{idle: idle, irq: irq, soft: soft, system: system}
+
+		stats[0].idle += stats[i+1].idle
+		stats[0].irq += irq
+		stats[0].soft += soft
+		stats[0].system += stats[i+1].system
+	}
+	return stats
+}
+
+// cpuStatsSource names what getCPUStats reads, for the startup checks.
+func cpuStatsSource() string {
+	return "PDH processor counters"
+}
@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// csvTimeFormat is RFC 3339 with milliseconds.
+const csvTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// csvSink appends one row per poll to a CSV file: the time, total CPU
+// usage, the usage of every core, the package temperature and the read
+// and write throughput of all disks. The file is
+// flushed after every row so a crash loses at most the current poll.
+type csvSink struct {
+	f      *os.File
+	w      *csv.Writer
+	header bool // Whether the header row has been written
+}
+
+// newCSVSink opens path for appending, creating it if needed. The header
+// is only written to an empty file, so a session can be continued in the
+// same file.
+func newCSVSink(path string) (*csvSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("csv: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("csv: %v", err)
+	}
+	return &csvSink{f: f, w: csv.NewWriter(f), header: info.Size() > 0}, nil
+}
+
+// write appends the sample as a row. The temperature column is empty
+// when no sensor was readable.
+func (c *csvSink) write(s *Sample) error {
+	if !c.header {
+		row := []string{"time", "cpu_percent"}
+		for i := range s.Cores {
+			row = append(row, fmt.Sprintf("core%d_percent", i))
+		}
+		c.w.Write(append(row, "temperature_c", "disk_read_bytes_per_sec", "disk_write_bytes_per_sec"))
+		c.header = true
+	}
+
+	row := []string{s.Time.Format(csvTimeFormat), strconv.FormatFloat(roundTo(s.CPU, 2), 'f', -1, 64)}
+	for _, usage := range s.Cores {
+		row = append(row, strconv.FormatFloat(roundTo(usage, 2), 'f', -1, 64))
+	}
+	temp := ""
+	if s.Temp > 0 {
+		temp = strconv.FormatFloat(roundTo(s.Temp, 2), 'f', -1, 64)
+	}
+	read, write, _ := diskTotals(s.Disks)
+	c.w.Write(append(row, temp, strconv.FormatFloat(roundTo(read, 0), 'f', -1, 64), strconv.FormatFloat(roundTo(write, 0), 'f', -1, 64)))
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return fmt.Errorf("csv: %v", err)
+	}
+	return nil
+}
+
+// close closes the file; every row has already been flushed.
+func (c *csvSink) close() error {
+	return c.f.Close()
+}
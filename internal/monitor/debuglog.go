@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logLevel orders the severities of the debug log.
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+// logLevelNames are the level names of the log lines and of [debug] level.
+var logLevelNames = map[string]logLevel{"debug": levelDebug, "info": levelInfo, "warn": levelWarn, "error": levelError}
+
+// String returns the level as written in log lines.
+func (l logLevel) String() string {
+	return [...]string{"DEBUG", "INFO", "WARN", "ERROR"}[l]
+}
+
+// logRepeatInterval is how long an identical line is held back, so an
+// error repeated at every poll does not flood the file.
+const logRepeatInterval = time.Minute
+
+// debugLogger writes leveled lines in the key=value text format of
+// log/slog, which needs a newer Go than the module targets:
+//
+//	time=2026-10-16T14:28:43.758Z level=WARN msg="exporter write failed" err="csv: disk full"
+//
+// It is off until --debug opens it.
+type debugLogger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	file   *os.File
+	min    logLevel
+	shown  map[string]time.Time // When each line was last written
+	pruned time.Time            // When lines no longer held back were last dropped from shown
+}
+
+// debugLog is the process-wide debug log.
+var debugLog = &debugLogger{}
+
+// stateDir returns the directory of the monitor's state under
+// $XDG_STATE_HOME, or "" without a home directory.
+func stateDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "kkperf")
+}
+
+// defaultDebugFile returns the debug log path in the state directory.
+func defaultDebugFile() string {
+	dir := stateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "debug.log")
+}
+
+// openDebugLog starts writing lines at level and above to the file at
+// path, appending to an earlier session's.
+func openDebugLog(path, level string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("debug log: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("debug log: %v", err)
+	}
+	debugLog.start(f, logLevelNames[level])
+	debugLog.file = f
+	return nil
+}
+
+// start directs the log to w.
+func (l *debugLogger) start(w io.Writer, min logLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w, l.min, l.shown, l.pruned = w, min, map[string]time.Time{}, time.Time{}
+}
+
+// closeDebugLog stops the log and closes its file.
+func closeDebugLog() {
+	debugLog.mu.Lock()
+	defer debugLog.mu.Unlock()
+	if debugLog.file != nil {
+		debugLog.file.Close()
+	}
+	debugLog.w, debugLog.file = nil, nil
+}
+
+// log writes msg with the key/value pairs in args at level.
+func (l *debugLogger) log(level logLevel, msg string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.w == nil || level < l.min {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "level=%s msg=%s", level, logValue(msg))
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, " %v=%s", args[i], logValue(fmt.Sprint(args[i+1])))
+	}
+	line := b.String()
+	now := timeNow()
+	if last, ok := l.shown[line]; ok && now.Sub(last) < logRepeatInterval {
+		return
+	}
+	// Lines carrying a changing value are all different; forget the ones
+	// past the interval so shown does not grow for as long as the agent runs
+	if now.Sub(l.pruned) >= logRepeatInterval {
+		for old, last := range l.shown {
+			if now.Sub(last) >= logRepeatInterval {
+				delete(l.shown, old)
+			}
+		}
+		l.pruned = now
+	}
+	l.shown[line] = now
+	fmt.Fprintf(l.w, "time=%s %s\n", now.Format("2006-01-02T15:04:05.000Z07:00"), line)
+}
+
+// logValue quotes a value that would not read back as one word.
+func logValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// logDebug logs the details behind a decision, such as each sensor found.
+func logDebug(msg string, args ...interface{}) { debugLog.log(levelDebug, msg, args...) }
+
+// logInfo logs a decision, such as the temperature source chosen.
+func logInfo(msg string, args ...interface{}) { debugLog.log(levelInfo, msg, args...) }
+
+// logWarn logs a source that failed and was worked around.
+func logWarn(msg string, args ...interface{}) { debugLog.log(levelWarn, msg, args...) }
+
+// logError logs a failure that loses data, such as an exporter write.
+func logError(msg string, args ...interface{}) { debugLog.log(levelError, msg, args...) }
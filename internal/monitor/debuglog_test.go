@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDebugLog checks the format of debug log lines, the level filter and
+// that a repeated line is held back.
+func TestDebugLog(t *testing.T) {
+	var buf bytes.Buffer
+	debugLog.start(&buf, levelInfo)
+	t.Cleanup(closeDebugLog)
+	logDebug("sensor found", "id", "k10temp/Tctl")
+	for i := 0; i < 3; i++ {
+		logError("exporter write failed", "err", "csv: disk full")
+	}
+	logInfo("RAPL domains", "names", "package-0")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		`level=ERROR msg="exporter write failed" err="csv: disk full"`,
+		`level=INFO msg="RAPL domains" names=package-0`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "time=") || !strings.HasSuffix(line, " "+want[i]) {
+			t.Errorf("line %d = %q, want time=... %s", i, line, want[i])
+		}
+	}
+}
+
+// TestDebugLogForgets checks that lines past the repeat interval are
+// forgotten, so lines carrying a changing value do not pile up.
+func TestDebugLogForgets(t *testing.T) {
+	savedTimeNow := timeNow
+	t.Cleanup(func() { timeNow = savedTimeNow })
+	clock := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return clock }
+	var buf bytes.Buffer
+	debugLog.start(&buf, levelDebug)
+	t.Cleanup(closeDebugLog)
+
+	for i := 0; i < 100; i++ {
+		logDebug("sensor read", "value", i)
+		clock = clock.Add(time.Second)
+	}
+	clock = clock.Add(logRepeatInterval)
+	logDebug("sensor read", "value", 100)
+	if n := len(debugLog.shown); n != 1 {
+		t.Errorf("%d lines remembered after the repeat interval; want 1", n)
+	}
+}
@@ -0,0 +1,45 @@
+package monitor
+
+import (
+	"expvar"
+	"time"
+)
+
+// Self-diagnostic counters, served at /debug/vars when the HTTP server is
+// enabled. They help tell whether the monitor itself is the cause of a
+// sluggish display or missing samples.
+var (
+	statSamples       = expvar.NewInt("samples_collected") // Samples taken by all collectors
+	statPollTime      = expvar.NewFloat("last_poll_ms")    // Duration of the most recent poll
+	statFrames        = expvar.NewInt("frames_rendered")   // Frames drawn by the TUI
+	statDroppedFrames = expvar.NewInt("frames_dropped")    // Render ticks missed because a frame overran or the terminal was still taking one
+	statRenderTime    = expvar.NewFloat("last_render_ms")  // Duration of the most recent frame
+	statRenderTimeMax = expvar.NewFloat("max_render_ms")   // Slowest frame since startup
+	statWriteTime     = expvar.NewFloat("last_write_ms")   // Time the terminal took to take the most recent frame
+	statSinkErrors    = expvar.NewInt("sink_errors")       // Failed exporter writes
+	statStartTime     = expvar.NewString("start_time")     // When the process started
+)
+
+// frameInterval is the render tick period (~60fps).
+const frameInterval = 16 * time.Millisecond
+
+func init() {
+	statStartTime.Set(time.Now().Format(time.RFC3339))
+}
+
+// recordFrame updates the render counters for a frame drawn between start
+// and end. Gaps since the previous frame longer than one tick mean the
+// ticker dropped ticks while an earlier frame was still being drawn.
+func recordFrame(start, end, previous time.Time) {
+	statFrames.Add(1)
+	elapsed := float64(end.Sub(start)) / float64(time.Millisecond)
+	statRenderTime.Set(elapsed)
+	if elapsed > statRenderTimeMax.Value() {
+		statRenderTimeMax.Set(elapsed)
+	}
+	if !previous.IsZero() {
+		if missed := int64(start.Sub(previous)/frameInterval) - 1; missed > 0 {
+			statDroppedFrames.Add(missed)
+		}
+	}
+}
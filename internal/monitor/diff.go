@@ -0,0 +1,248 @@
+package monitor
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Exit codes of the diff subcommand, as diff(1) uses them.
+const (
+	diffSame    = 0 // Within the limits
+	diffChanged = 1 // A --max-* limit was exceeded
+	diffTrouble = 2 // Bad arguments or unreadable files
+)
+
+// diffLimits holds the regression limits given to the diff subcommand.
+// Zero means the limit is not set.
+type diffLimits struct {
+	tempRise float64 // °C the max temperature of B may exceed A's by
+	cpuRise  float64 // Percentage points the mean CPU usage of B may exceed A's by
+	iopsDrop float64 // Percent the fio IOPS of B may fall below A's
+}
+
+// diffInput is one side of a comparison: a history store file, or
+// snapshots printed with --format '{{json .}}'.
+type diffInput struct {
+	path    string
+	records []historyRecord
+	sum     reportSummary
+}
+
+// runDiff implements the "diff" subcommand: it compares two sessions or
+// snapshots, prints the change from the first to the second, and returns
+// diffChanged when a limit is exceeded, so CI jobs can fail on a thermal
+// or performance regression.
+func runDiff(args []string) int {
+	var limits diffLimits
+	path := configPath()
+
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&path, "c", path, "")
+	fs.StringVar(&path, "config", path, "")
+	fs.Float64Var(&limits.tempRise, "max-temp-rise", 0, "")
+	fs.Float64Var(&limits.cpuRise, "max-cpu-rise", 0, "")
+	fs.Float64Var(&limits.iopsDrop, "max-iops-drop", 0, "")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Println(diffUsage)
+			return diffSame
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return diffTrouble
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "Error: diff takes two files, e.g. kkperf diff before.json after.json")
+		return diffTrouble
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return diffTrouble
+	}
+	activeLocale = resolveLocale(cfg)
+
+	var inputs [2]*diffInput
+	for i, file := range fs.Args() {
+		if inputs[i], err = readDiffInput(file); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return diffTrouble
+		}
+	}
+	text, failures := formatDiff(inputs[0], inputs[1], limits)
+	fmt.Print(text)
+	if len(failures) > 0 {
+		return diffChanged
+	}
+	return diffSame
+}
+
+// readDiffInput reads the JSON values of a file: history records, which
+// have a "cpu_avg" field, or samples, which have "CPU" and are turned into
+// one-poll records. Values may be on one line each or pretty-printed.
+func readDiffInput(path string) (*diffInput, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	in := &diffInput{path: path}
+	decoder := json.NewDecoder(f)
+	for n := 1; ; n++ {
+		var fields map[string]json.RawMessage
+		if err := decoder.Decode(&fields); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: value %d: %v", path, n, err)
+		}
+		data, _ := json.Marshal(fields)
+		switch {
+		case fields["cpu_avg"] != nil:
+			var r historyRecord
+			if err := json.Unmarshal(data, &r); err != nil {
+				return nil, fmt.Errorf("%s: value %d: %v", path, n, err)
+			}
+			in.records = append(in.records, r)
+		case fields["CPU"] != nil:
+			var s Sample
+			if err := json.Unmarshal(data, &s); err != nil {
+				return nil, fmt.Errorf("%s: value %d: %v", path, n, err)
+			}
+			in.records = append(in.records, snapshotRecord(&s))
+		default:
+			return nil, fmt.Errorf("%s: value %d is neither a history record nor a snapshot", path, n)
+		}
+	}
+	if len(in.records) == 0 {
+		return nil, fmt.Errorf("%s: no history records or snapshots", path)
+	}
+	sort.SliceStable(in.records, func(i, j int) bool { return in.records[i].Time.Before(in.records[j].Time) })
+	from, to := in.records[0].Time, in.records[len(in.records)-1].Time
+	in.sum = summarizeHistory(in.records, from, to, 0)
+	return in, nil
+}
+
+// snapshotRecord turns a sample into a history record of one poll.
+func snapshotRecord(s *Sample) historyRecord {
+	r := historyRecord{Time: s.Time, Samples: 1, CPUAvg: s.CPU, CPUMax: s.CPU, TempAvg: s.Temp, TempMax: s.Temp,
+		Stress: s.Stress, FioIOPS: s.DiskIOPS, FioLatency: s.DiskLatency}
+	if s.Throttled {
+		r.Throttled = 1
+	}
+	r.DiskRead, r.DiskWrite, _ = diskTotals(s.Disks)
+	return r
+}
+
+// formatDiff renders the comparison of a and b as a plain-text report and
+// returns the limits b exceeds.
+func formatDiff(a, b *diffInput, limits diffLimits) (string, []string) {
+	var out strings.Builder
+	const stamp = "2006-01-02 15:04"
+	fmt.Fprintf(&out, "Kode Kronical Perf Monitor diff\n")
+	for i, in := range []*diffInput{a, b} {
+		polls := 0
+		for _, r := range in.records {
+			polls += r.Samples
+		}
+		fmt.Fprintf(&out, "%s: %s, %s to %s, %d records of %d polls\n", bookmarkNames[i], in.path,
+			in.sum.from.Format(stamp), in.sum.to.Format(stamp), len(in.records), polls)
+	}
+	fmt.Fprintf(&out, "\n%s%s%s%s\n", padRight("", 20), padRight("A", 16), padRight("B", 16), "B - A")
+
+	row := func(label string, va, vb float64, ok bool, format, delta func(float64) string) {
+		if !ok {
+			return
+		}
+		sign := ""
+		if vb >= va {
+			sign = "+"
+		}
+		fmt.Fprintf(&out, "%s%s%s%s\n", padRight(label, 20), padRight(format(va), 16), padRight(format(vb), 16), sign+delta(vb-va))
+	}
+	percent := func(v float64) string { return formatPercent(v, 1) }
+	temp := func(v float64) string { return formatTemp(v, 1) }
+	tempDelta := func(v float64) string { return formatTempDelta(v, 1) }
+	count := func(v float64) string { return formatNumber(v, 0) }
+	rate := func(v float64) string { return unitBytesRate.format(v, 0) }
+	rateDelta := func(v float64) string {
+		if v < 0 {
+			return "-" + rate(-v)
+		}
+		return rate(v)
+	}
+
+	sa, sb := a.sum, b.sum
+	row("CPU avg", sa.cpuAvg, sb.cpuAvg, true, percent, percent)
+	row("CPU max", sa.cpuMax, sb.cpuMax, true, percent, percent)
+	row("Temperature avg", sa.tempAvg, sb.tempAvg, sa.tempMax > 0 && sb.tempMax > 0, temp, tempDelta)
+	row("Temperature max", sa.tempMax, sb.tempMax, sa.tempMax > 0 && sb.tempMax > 0, temp, tempDelta)
+	row("Throttle events", float64(sa.throttled), float64(sb.throttled), true, count, count)
+	readA, writeA := meanDiskRates(a.records)
+	readB, writeB := meanDiskRates(b.records)
+	row("Disk read", readA, readB, readA > 0 || readB > 0, rate, rateDelta)
+	row("Disk write", writeA, writeB, writeA > 0 || writeB > 0, rate, rateDelta)
+	row("fio IOPS", sa.fioIOPS, sb.fioIOPS, sa.fioMin > 0 && sb.fioMin > 0, count, count)
+	row("fio latency (µs)", sa.fioLatency, sb.fioLatency, sa.fioMin > 0 && sb.fioMin > 0, count, count)
+
+	var failures []string
+	if limits.tempRise > 0 && sa.tempMax > 0 && sb.tempMax-sa.tempMax > limits.tempRise {
+		failures = append(failures, fmt.Sprintf("max temperature rose by %s, more than %s",
+			formatTempDelta(sb.tempMax-sa.tempMax, 1), formatTempDelta(limits.tempRise, 1)))
+	}
+	if limits.cpuRise > 0 && sb.cpuAvg-sa.cpuAvg > limits.cpuRise {
+		failures = append(failures, fmt.Sprintf("mean CPU usage rose by %s, more than %s",
+			formatPercent(sb.cpuAvg-sa.cpuAvg, 1), formatPercent(limits.cpuRise, 1)))
+	}
+	if limits.iopsDrop > 0 && sa.fioIOPS > 0 && sb.fioMin > 0 {
+		if drop := (sa.fioIOPS - sb.fioIOPS) / sa.fioIOPS * 100; drop > limits.iopsDrop {
+			failures = append(failures, fmt.Sprintf("fio IOPS fell by %s, more than %s",
+				formatPercent(drop, 1), formatPercent(limits.iopsDrop, 1)))
+		}
+	}
+	out.WriteString("\n")
+	if len(failures) == 0 {
+		out.WriteString("Result: within limits\n")
+	}
+	for _, f := range failures {
+		fmt.Fprintf(&out, "Regression: %s\n", f)
+	}
+	return out.String(), failures
+}
+
+// meanDiskRates returns the mean read and write rates of records, weighted
+// by their polls.
+func meanDiskRates(records []historyRecord) (read, write float64) {
+	polls := 0
+	for _, r := range records {
+		read += r.DiskRead * float64(r.Samples)
+		write += r.DiskWrite * float64(r.Samples)
+		polls += r.Samples
+	}
+	if polls == 0 {
+		return 0, 0
+	}
+	return read / float64(polls), write / float64(polls)
+}
+
+// diffUsage documents the diff subcommand.
+const diffUsage = `Usage: kkperf diff [options] A B
+
+Compare two sessions or snapshots and print the change from A to B.
+A and B are history store files (YYYY-MM-DD.jsonl) or snapshots saved
+with kkperf --format '{{json .}}', one or many to a file.
+
+Options:
+  --max-temp-rise DEG  Exit 1 if B's max temperature exceeds A's by more than DEG °C
+  --max-cpu-rise PCT   Exit 1 if B's mean CPU usage exceeds A's by more than PCT points
+  --max-iops-drop PCT  Exit 1 if B's fio IOPS fall more than PCT percent below A's
+  -c, --config PATH    Use an alternate config file
+
+Options go before the files. The exit status is 0 within the limits,
+1 when one is exceeded and 2 on errors.`
@@ -0,0 +1,45 @@
+package monitor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestDiff compares a session of history records with a snapshot and
+// checks the regression limits.
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	session := `{"time":"` + at.Format(time.RFC3339) + `","samples":120,"cpu_avg":70,"cpu_max":90,"temp_avg":80,"temp_max":85,"throttled":0,"fio_iops":40000,"fio_latency_us":200}
+{"time":"` + at.Add(time.Minute).Format(time.RFC3339) + `","samples":120,"cpu_avg":72,"cpu_max":95,"temp_avg":82,"temp_max":86,"throttled":0,"fio_iops":42000,"fio_latency_us":190}
+`
+	snapshot, err := json.Marshal(Sample{Time: at.Add(24 * time.Hour), CPU: 71, Temp: 91, DiskIOPS: 30000, DiskLatency: 260})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "a.jsonl"), []byte(session), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "b.json"), snapshot, 0644)
+
+	a, err := readDiffInput(filepath.Join(dir, "a.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := readDiffInput(filepath.Join(dir, "b.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.sum.cpuAvg != 71 || a.sum.tempMax != 86 || a.sum.fioIOPS != 41000 || b.sum.tempMax != 91 {
+		t.Errorf("summaries: A CPU %.1f%%, max %.1f°C, %.0f IOPS; B max %.1f°C", a.sum.cpuAvg, a.sum.tempMax, a.sum.fioIOPS, b.sum.tempMax)
+	}
+
+	if _, failures := formatDiff(a, b, diffLimits{tempRise: 10, iopsDrop: 50}); len(failures) != 0 {
+		t.Errorf("within limits, got %q", failures)
+	}
+	text, failures := formatDiff(a, b, diffLimits{tempRise: 3, iopsDrop: 10})
+	if len(failures) != 2 {
+		t.Errorf("want temperature and IOPS regressions, got %q\n%s", failures, text)
+	}
+}
@@ -0,0 +1,152 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// diskSectorBytes is the unit of the sector counts in /proc/diskstats,
+// whatever the device's own sector size.
+const diskSectorBytes = 512
+
+// DiskIO is the throughput of one whole disk since the previous sample.
+type DiskIO struct {
+	Device     string  // e.g. "nvme0n1" or "sda"
+	ReadBytes  float64 // Bytes read per second
+	WriteBytes float64 // Bytes written per second
+	ReadIOPS   float64 // Reads completed per second
+	WriteIOPS  float64 // Writes completed per second
+}
+
+// diskCounters are the cumulative counters of one disk.
+type diskCounters struct {
+	reads, readSectors, writes, writeSectors uint64
+}
+
+// diskIOSampler turns the /proc/diskstats counters of whole disks into
+// per-second rates.
+type diskIOSampler struct {
+	last     map[string]diskCounters
+	lastTime time.Time
+}
+
+// newDiskIOSampler takes the first reading.
+func newDiskIOSampler() *diskIOSampler {
+	return &diskIOSampler{last: readDiskCounters(), lastTime: timeNow()}
+}
+
+// sample returns the rates of every whole disk since the previous call,
+// by device name, or nil when the file cannot be read.
+func (d *diskIOSampler) sample() []DiskIO {
+	now := timeNow()
+	elapsed := now.Sub(d.lastTime).Seconds()
+	counters := readDiskCounters()
+	var disks []DiskIO
+	for _, name := range sortedDiskNames(counters) {
+		c, prev := counters[name], d.last[name]
+		io := DiskIO{Device: name}
+		if _, ok := d.last[name]; ok && elapsed > 0 && c.readSectors >= prev.readSectors && c.writeSectors >= prev.writeSectors {
+			io.ReadBytes = float64(c.readSectors-prev.readSectors) * diskSectorBytes / elapsed
+			io.WriteBytes = float64(c.writeSectors-prev.writeSectors) * diskSectorBytes / elapsed
+			io.ReadIOPS = float64(c.reads-prev.reads) / elapsed
+			io.WriteIOPS = float64(c.writes-prev.writes) / elapsed
+		}
+		disks = append(disks, io)
+	}
+	d.last, d.lastTime = counters, now
+	return disks
+}
+
+// readDiskCounters reads the completed reads and writes and the sectors
+// transferred (fields 4, 6, 8 and 10) of every whole disk in
+// /proc/diskstats, skipping partitions, loop and RAM devices as the disk
+// utilization does.
+func readDiskCounters() map[string]diskCounters {
+	counters := map[string]diskCounters{}
+	file, err := os.Open(filepath.Join(procDir, "diskstats"))
+	if err != nil {
+		return counters
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || !isWholeDisk(fields[2]) {
+			continue
+		}
+		var values [4]uint64
+		for i, field := range []int{3, 5, 7, 9} {
+			values[i], _ = strconv.ParseUint(fields[field], 10, 64)
+		}
+		counters[fields[2]] = diskCounters{reads: values[0], readSectors: values[1], writes: values[2], writeSectors: values[3]}
+	}
+	return counters
+}
+
+// sortedDiskNames returns the disk names in order.
+func sortedDiskNames(counters map[string]diskCounters) []string {
+	names := make([]string, 0, len(counters))
+	for name := range counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// diskTotals sums the throughput and IOPS of all disks.
+func diskTotals(disks []DiskIO) (read, write, iops float64) {
+	for _, d := range disks {
+		read += d.ReadBytes
+		write += d.WriteBytes
+		iops += d.ReadIOPS + d.WriteIOPS
+	}
+	return read, write, iops
+}
+
+// sparkBlocks are the heights of a one-row sparkline.
+var sparkBlocks = []string{"▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// drawDiskRow draws the disk I/O row under the memory graph: read and
+// write throughput, IOPS and iowait of the last poll, then a sparkline of
+// the combined throughput column for column with the CPU graph, scaled to
+// the busiest column in the window, which the first line gives as the
+// peak. Columns where writes outweigh reads are magenta, the others cyan,
+// so a CPU spike that lines up with a write burst stands out. It draws
+// nothing without /proc/diskstats.
+func (m *Monitor) drawDiskRow() {
+	if len(m.disks) == 0 {
+		return
+	}
+	peak := 0.0
+	for _, p := range m.displayBuffer {
+		peak = math.Max(peak, p.diskRead+p.diskWrite)
+	}
+	read, write, iops := diskTotals(m.disks)
+	fmt.Fprintf(m.out, "%s%s%s %s %s  %s %s  %s %s  %s %s%s%s  %s %s\r\n", colorBlue, tr("Disk I/O"), colorReset,
+		tr("Read"), unitBytesRate.format(read, 0), tr("Write"), unitBytesRate.format(write, 0), formatNumber(iops, 0), tr("IOPS"),
+		tr("iowait"), getUsageColor(m.iowaitUsage*4), formatPercent(m.iowaitUsage, 1), colorReset, tr("Peak"), unitBytesRate.format(peak, 0))
+
+	fmt.Fprintf(m.out, "%s%s%s", colorCyan, padRight(tr("Disk"), 7), colorReset)
+	for _, p := range m.displayBuffer {
+		total := p.diskRead + p.diskWrite
+		if peak == 0 || total == 0 {
+			fmt.Fprint(m.out, " ")
+			continue
+		}
+		color := colorCyan
+		if p.diskWrite > p.diskRead {
+			color = colorMagenta
+		}
+		level := int(total / peak * float64(len(sparkBlocks)-1))
+		fmt.Fprintf(m.out, "%s%s%s", color, sparkBlocks[level], colorReset)
+	}
+	fmt.Fprint(m.out, "\r\n")
+}
@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+)
+
+// tempAxisRange picks the temperature range for the right-hand axis of the
+// dual-axis graph: the buffered readings rounded out to multiples of 10°C,
+// at least 20°C tall so small fluctuations don't fill the whole graph.
+func (m *Monitor) tempAxisRange() (lo, hi float64) {
+	lo, hi = math.MaxFloat64, 0
+	for _, p := range m.displayBuffer {
+		if p.temp <= 0 {
+			continue
+		}
+		lo = math.Min(lo, p.temp)
+		hi = math.Max(hi, p.temp)
+	}
+	if hi == 0 {
+		return 30, 90 // No readings yet
+	}
+	lo = math.Floor(lo/10) * 10
+	hi = math.Ceil(hi/10) * 10
+	if hi-lo < 20 {
+		hi = lo + 20
+	}
+	return lo, hi
+}
+
+// drawDualAxisGraph renders CPU usage as filled bars against the left axis
+// and temperature as a line of ° glyphs against its own right-hand axis,
+// so absolute temperatures can be read directly instead of only via color.
+func (m *Monitor) drawDualAxisGraph(currentCpu, currentTemp float64) {
+	const rows = 5
+	fmt.Fprintf(m.out, "%s%s%s %s %s%s%s / %s%s%s%*s\r\n",
+		colorCyan, tr("CPU Usage & Temperature Graph"), colorReset, tr("Current:"),
+		colorYellow, formatPercent(currentCpu, 1), colorReset, colorYellow, formatTemp(currentTemp, 1), colorReset, 20, "")
+
+	lo, hi := m.tempAxisRange()
+	rowSpan := (hi - lo) / rows
+	axis := m.cpuAxis()
+	ranges := cpuAxisLabels(axis)
+
+	// Plot both series on their own scales, then overlay temperature on CPU
+	cpuValues := make([]float64, baseGraphWidth)
+	cpuColors := m.seriesColors(m.cfg.DualCPUGraphColor)
+	tempValues := make([]float64, baseGraphWidth)
+	tempColors := m.seriesColors(m.cfg.TempGraphColor)
+	for i, p := range m.displayBuffer {
+		cpuValues[i] = axis.fraction(p.cpu)
+		tempValues[i] = -1
+		if p.temp > 0 {
+			tempValues[i] = math.Max(0, math.Min(1, (p.temp-lo)/(hi-lo)))
+		}
+	}
+	cpuGrid := plotSeries(cpuValues, cpuColors, rows, m.cfg.DualCPUGraphStyle)
+	lows, highs := m.cpuEnvelope(axis)
+	plotEnvelope(cpuGrid, lows, highs, cpuColors)
+	tempGrid := plotSeries(tempValues, tempColors, rows, m.cfg.TempGraphStyle)
+
+	for row := rows - 1; row >= 0; row-- {
+		fmt.Fprintf(m.out, "%s%s%s", colorCyan, ranges[rows-1-row], colorReset)
+
+		for i := 0; i < baseGraphWidth; i++ {
+			bar, dot := cpuGrid[row][i], tempGrid[row][i]
+			switch {
+			case dot.glyph != "" && bar.glyph == "█":
+				// Temperature over a solid bar: keep the bar visible as the background
+				fmt.Fprintf(m.out, "%s%s%s%s", colorToBackground(bar.color), dot.color, dot.glyph, colorReset)
+			case dot.glyph != "":
+				fmt.Fprintf(m.out, "%s%s%s", dot.color, dot.glyph, colorReset)
+			case bar.glyph != "":
+				fmt.Fprintf(m.out, "%s%s%s", bar.color, bar.glyph, colorReset)
+			default:
+				fmt.Fprint(m.out, " ")
+			}
+		}
+
+		// Right-hand temperature axis, labeled with each row's upper bound
+		fmt.Fprintf(m.out, " %s%s%s\r\n", colorCyan, formatTemp(lo+rowSpan*float64(row+1), 0), colorReset)
+	}
+
+	fmt.Fprintf(m.out, "        %s%s%s\r\n", colorYellow, m.zoomHint(), colorReset)
+	fmt.Fprintf(m.out, "        %s%-10s%s\r\n", colorCyan, formatWindow(m.window), colorReset)
+}
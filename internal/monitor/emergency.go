@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// emergencySink runs the [emergency] action when the temperature stays at
+// or above the critical temperature for the configured time: a watchdog
+// for machines whose cooling cannot keep up. It is a sink so it also
+// guards headless and agent runs.
+type emergencySink struct {
+	cfg    *Config
+	since  time.Time // When the temperature reached the limit, zero below it
+	fired  bool      // Whether the action ran in the current episode
+	failed string    // Why the action could not be started, "" when it could
+}
+
+// newEmergencySink creates the watchdog for the [emergency] config.
+func newEmergencySink(cfg *Config) *emergencySink {
+	return &emergencySink{cfg: cfg}
+}
+
+// write tracks how long the temperature has been critical and runs the
+// action once per episode. Dropping below the limit, such as after
+// resuming from suspend, rearms it. An action that fails to start is
+// tried again with every sample, as it may still be needed.
+func (e *emergencySink) write(s *Sample) error {
+	c := e.cfg.Emergency
+	if s.Temp < c.Temp {
+		e.since, e.fired, e.failed = time.Time{}, false, ""
+		return nil
+	}
+	if e.since.IsZero() {
+		e.since = s.Time
+	}
+	if e.fired || s.Time.Sub(e.since) < c.For {
+		return nil
+	}
+	reason := fmt.Sprintf("temperature %.1f°C >= %g°C for %s", s.Temp, c.Temp, s.Time.Sub(e.since).Round(time.Second))
+
+	var cmd *exec.Cmd
+	switch c.Action {
+	case "suspend":
+		cmd = exec.Command("systemctl", "suspend")
+	case "shutdown":
+		cmd = exec.Command("systemctl", "poweroff")
+	default:
+		cmd = exec.Command(shellName, shellFlag, c.Command)
+		cmd.Env = append(os.Environ(), fmt.Sprintf("KKPERF_TEMP=%.1f", s.Temp))
+	}
+	if err := cmd.Start(); err != nil {
+		if e.failed == "" {
+			appendSafetyLog(e.cfg, s.Time, fmt.Sprintf("emergency %s failed: %v; %s", c.Action, err, reason))
+		}
+		e.failed = err.Error()
+		return fmt.Errorf("emergency %s: %v", c.Action, err)
+	}
+	e.fired, e.failed = true, ""
+	appendSafetyLog(e.cfg, s.Time, fmt.Sprintf("emergency %s: %s", c.Action, reason))
+	go cmd.Wait()
+	return nil
+}
+
+// emergencyStatus returns the status line tag of an emergency action that
+// could not be started, which the debug log alone would hide.
+func (m *Monitor) emergencyStatus() string {
+	if m.emergency == nil || m.emergency.failed == "" {
+		return ""
+	}
+	return fmt.Sprintf("  %s[%s: %s]%s", colorRed, tr("EMERGENCY FAILED"), m.emergency.failed, colorReset)
+}
+
+// close is a no-op; a running action is left to finish.
+func (e *emergencySink) close() error {
+	return nil
+}
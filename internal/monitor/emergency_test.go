@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEmergency checks that the action runs once the temperature has been
+// critical for the configured time, once per episode, that dropping below
+// the limit rearms it, and that an action failing to start is retried and
+// shown on the status line.
+func TestEmergency(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Safety.Log = filepath.Join(t.TempDir(), "safety.log")
+	cfg.Emergency.Action = "command"
+	cfg.Emergency.Command = "true"
+	cfg.Emergency.Temp = 95
+	cfg.Emergency.For = 10 * time.Second
+	e := newEmergencySink(cfg)
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	poll := func(after time.Duration, temp float64) {
+		t.Helper()
+		if err := e.write(&Sample{Time: start.Add(after), Temp: temp}); err != nil {
+			t.Fatalf("at %s: %v", after, err)
+		}
+	}
+	fired := func() int {
+		data, _ := ioutil.ReadFile(cfg.Safety.Log)
+		return strings.Count(string(data), "emergency command: temperature")
+	}
+
+	poll(0, 96)
+	poll(9*time.Second, 97)
+	if fired() != 0 {
+		t.Fatal("fired before emergency.for elapsed")
+	}
+	poll(10*time.Second, 96)
+	poll(20*time.Second, 96)
+	if fired() != 1 {
+		t.Fatalf("fired %d times in one episode; want 1", fired())
+	}
+	poll(21*time.Second, 90) // Rearms
+	poll(22*time.Second, 96)
+	poll(31*time.Second, 96)
+	if fired() != 1 {
+		t.Fatal("fired again before emergency.for elapsed in the new episode")
+	}
+	poll(32*time.Second, 96)
+	if fired() != 2 {
+		t.Fatalf("fired %d times after rearming; want 2", fired())
+	}
+
+	// An action that cannot start is not counted as fired
+	m := &Monitor{emergency: e}
+	path := os.Getenv("PATH")
+	t.Setenv("PATH", "")
+	poll(40*time.Second, 90)
+	poll(41*time.Second, 96)
+	if err := e.write(&Sample{Time: start.Add(51 * time.Second), Temp: 96}); err == nil || e.fired {
+		t.Fatalf("failed start: err %v, fired %v", err, e.fired)
+	}
+	if status := m.emergencyStatus(); !strings.Contains(status, "EMERGENCY FAILED") {
+		t.Errorf("status line tag = %q; want the failure", status)
+	}
+	os.Setenv("PATH", path)
+	poll(52*time.Second, 96)
+	if !e.fired || m.emergencyStatus() != "" || fired() != 3 {
+		t.Errorf("retry: fired %v, status %q, %d actions; want the action run", e.fired, m.emergencyStatus(), fired())
+	}
+}
@@ -0,0 +1,122 @@
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FanReading is the speed of one fan.
+type FanReading struct {
+	Sensor string  // Sensor id, "<chip>/<label>"
+	RPM    float64 // Revolutions per minute
+}
+
+// fanChannel is one discovered fan tachometer.
+type fanChannel struct {
+	id, path string
+	spun     bool // Whether it ever read above 0 RPM
+}
+
+// fanSampler reads the fan tachometers of every hwmon chip, leaving out
+// the pump and flow channels of liquid-cooling controllers, which the
+// loop line shows.
+type fanSampler struct {
+	channels []*fanChannel
+}
+
+// newFanSampler discovers the fan*_input channels the sensor filters
+// allow.
+func newFanSampler(cfg *Config) *fanSampler {
+	f := &fanSampler{}
+	chips, _ := filepath.Glob(filepath.Join(hwmonDir, "hwmon*"))
+	sort.Slice(chips, func(i, j int) bool { return naturalLess(chips[i], chips[j]) })
+	seen := map[string]int{}
+	for _, dir := range chips {
+		name := readSysfsString(filepath.Join(dir, "name"))
+		if name == "" {
+			name = filepath.Base(dir)
+		}
+		seen[name]++
+		chip := name
+		if seen[name] > 1 {
+			chip = name + strconv.Itoa(seen[name]) // As in discoverSensors
+		}
+
+		inputs, _ := filepath.Glob(filepath.Join(dir, "fan*_input"))
+		sort.Slice(inputs, func(i, j int) bool { return naturalLess(inputs[i], inputs[j]) })
+		for _, input := range inputs {
+			channel := strings.TrimSuffix(filepath.Base(input), "_input")
+			label := readSysfsString(filepath.Join(dir, channel+"_label"))
+			if label == "" {
+				label = channel
+			}
+			if kind, _ := classifyCoolingChannel(coolingChips[name], false, label); kind != "" {
+				continue
+			}
+			if id := chip + "/" + label; cfg.sensorAllowed(id) {
+				f.channels = append(f.channels, &fanChannel{id: id, path: input})
+			}
+		}
+	}
+	return f
+}
+
+// sample reads every fan. Headers that have never spun, which is how
+// unconnected ones read, are left out; a fan that stops later reads 0.
+func (f *fanSampler) sample() []FanReading {
+	var readings []FanReading
+	for _, ch := range f.channels {
+		rpm, err := strconv.ParseFloat(readSysfsString(ch.path), 64)
+		if err != nil {
+			continue
+		}
+		ch.spun = ch.spun || rpm > 0
+		if ch.spun {
+			readings = append(readings, FanReading{Sensor: ch.id, RPM: rpm})
+		}
+	}
+	return readings
+}
+
+// fanRange is the lowest and highest speed of a fan since startup.
+type fanRange struct {
+	min, max float64
+}
+
+// updateFanRanges tracks the range of every fan, as updateMinMax does the
+// temperature.
+func (m *Monitor) updateFanRanges(fans []FanReading) {
+	for _, f := range fans {
+		r, ok := m.fanRanges[f.Sensor]
+		if !ok || f.RPM < r.min {
+			r.min = f.RPM
+		}
+		if !ok || f.RPM > r.max {
+			r.max = f.RPM
+		}
+		m.fanRanges[f.Sensor] = r
+	}
+}
+
+// displayFans prints the fan speeds under the status line, each with its
+// range since startup, so fans ramping against the temperature can be
+// watched during a stress run. Stopped fans are red.
+func (m *Monitor) displayFans() {
+	if len(m.fans) == 0 {
+		return
+	}
+	parts := make([]string, 0, len(m.fans))
+	for _, f := range m.fans {
+		color := colorYellow
+		if f.RPM == 0 {
+			color = colorRed
+		}
+		r := m.fanRanges[f.Sensor]
+		parts = append(parts, fmt.Sprintf("%s%s%s %s%s%s (%s-%s)", colorBlue, f.Sensor, colorReset,
+			color, unitRPM.format(f.RPM, 0), colorReset, formatNumber(r.min, 0), formatNumber(r.max, 0)))
+	}
+	fmt.Fprintf(m.out, "%s %s\r\n\r\n", tr("Fans:"), strings.Join(parts, "  "))
+}
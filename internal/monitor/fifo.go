@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// fifoMetrics are the fields of the default FIFO line, in order.
+var fifoMetrics = []string{"cpu", "temp", "headroom", "iowait", "load", "gpu", "disk", "disk_read", "disk_write", "net", "mem", "power", "stress", "throttled"}
+
+// fifoSink writes every sample as one line to a named pipe, for status
+// bars such as xmobar or polybar that read a FIFO. Nothing is written
+// while no reader has the FIFO open, and a line a slow reader has no room
+// for is dropped, so the reader always gets the latest sample and never
+// holds up the polling loop.
+type fifoSink struct {
+	path string
+	tmpl *template.Template // nil for the default key=value line
+	fd   int                // Write end, -1 while no reader is attached
+}
+
+// newFIFOSink creates the FIFO at path unless it exists. A non-empty
+// format is a --format template rendered for every sample.
+func newFIFOSink(path, format string) (*fifoSink, error) {
+	f := &fifoSink{path: path, fd: -1}
+	if format != "" {
+		format = strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(format)
+		tmpl, err := template.New("fifo").Funcs(formatFuncs).Parse(format)
+		if err != nil {
+			return nil, fmt.Errorf("fifo: template: %v", err)
+		}
+		f.tmpl = tmpl
+	}
+	if err := makeFIFO(path); err != nil {
+		return nil, fmt.Errorf("fifo: %v", err)
+	}
+	return f, nil
+}
+
+// write sends the sample to the reader, if one is attached.
+func (f *fifoSink) write(s *Sample) error {
+	line, err := f.line(s)
+	if err != nil {
+		return fmt.Errorf("fifo: template: %v", err)
+	}
+	if err := f.send(line); err != nil {
+		return fmt.Errorf("fifo: %v", err)
+	}
+	return nil
+}
+
+// line renders a sample: the template, or "key=value" pairs such as
+// "cpu=42.5 temp=61.0 ... stress=0 throttled=0" with the values of the
+// socket protocol, which are locale independent.
+func (f *fifoSink) line(s *Sample) ([]byte, error) {
+	var out bytes.Buffer
+	if f.tmpl != nil {
+		if err := f.tmpl.Execute(&out, s); err != nil {
+			return nil, err
+		}
+	} else {
+		for i, metric := range fifoMetrics {
+			if i > 0 {
+				out.WriteByte(' ')
+			}
+			value, _ := socketValue(s, nil, metric)
+			fmt.Fprintf(&out, "%s=%s", metric, value)
+		}
+	}
+	if !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,62 @@
+//go:build !windows
+
+package monitor
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// makeFIFO creates a FIFO readable by the current user only, or checks
+// that the existing file is one.
+func makeFIFO(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return unix.Mkfifo(path, 0600)
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		return fmt.Errorf("%s exists and is not a FIFO", path)
+	}
+	return nil
+}
+
+// send writes a line without blocking. The FIFO is opened once a reader
+// is attached and reopened after the reader goes away. The raw descriptor
+// is used, as an os.File would park on a full pipe instead of failing.
+func (f *fifoSink) send(line []byte) error {
+	if f.fd < 0 {
+		fd, err := unix.Open(f.path, unix.O_WRONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+		if err == unix.ENXIO { // No reader
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		f.fd = fd
+	}
+	_, err := unix.Write(f.fd, line)
+	switch err {
+	case nil, unix.EAGAIN: // A full pipe drops the line
+		return nil
+	case unix.EPIPE: // The reader went away
+		unix.Close(f.fd)
+		f.fd = -1
+		return nil
+	}
+	return err
+}
+
+// close closes the write end. The FIFO itself is left for the reader.
+func (f *fifoSink) close() error {
+	if f.fd < 0 {
+		return nil
+	}
+	err := unix.Close(f.fd)
+	f.fd = -1
+	return err
+}
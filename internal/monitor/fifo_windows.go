@@ -0,0 +1,20 @@
+//go:build windows
+
+package monitor
+
+import "errors"
+
+// makeFIFO fails: Windows has no FIFOs in the file system.
+func makeFIFO(path string) error {
+	return errors.New("FIFOs are not available on Windows; use [socket] or [http] instead")
+}
+
+// send is never called, as newFIFOSink fails.
+func (f *fifoSink) send(line []byte) error {
+	return nil
+}
+
+// close has nothing to release.
+func (f *fifoSink) close() error {
+	return nil
+}
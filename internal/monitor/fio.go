@@ -0,0 +1,197 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fioJob is a job template for the disk stress mode.
+type fioJob struct {
+	rw      string // fio --rw value
+	bs      string // Block size
+	iodepth int
+}
+
+// fioJobs are the templates selectable with [fio] job.
+var fioJobs = map[string]fioJob{
+	"seqread":   {"read", "1M", 8},
+	"seqwrite":  {"write", "1M", 8},
+	"randread":  {"randread", "4k", 32},
+	"randwrite": {"randwrite", "4k", 32},
+}
+
+// fioStatus is the part of fio's JSON status output that is read. With
+// --status-interval fio prints one such object per interval, with
+// counters covering the whole run so far.
+type fioStatus struct {
+	Jobs []struct {
+		Runtime int64       `json:"job_runtime"` // ms
+		Read    fioDirStats `json:"read"`
+		Write   fioDirStats `json:"write"`
+	} `json:"jobs"`
+}
+
+// fioDirStats holds the counters of one I/O direction.
+type fioDirStats struct {
+	TotalIOs uint64 `json:"total_ios"`
+	Clat     struct {
+		Mean float64 `json:"mean"` // ns
+	} `json:"clat_ns"`
+}
+
+// diskStress runs fio with one of the job templates and tracks IOPS and
+// completion latency per status interval.
+type diskStress struct {
+	cmd  *exec.Cmd
+	file string // Test file, removed when the run ends
+
+	mu      sync.Mutex
+	running bool
+	job     string
+	iops    float64 // IOPS over the latest interval
+	latency float64 // Mean completion latency over the latest interval in µs
+	err     string  // Why the last run ended early
+}
+
+// fioAvailable reports whether disk stress can be started.
+func fioAvailable() bool {
+	_, err := exec.LookPath("fio")
+	return err == nil
+}
+
+// startDiskStress launches fio with the configured job until it is
+// stopped or [fio] runtime elapses.
+func (m *Monitor) startDiskStress() {
+	d := m.diskStress
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.running || !fioAvailable() {
+		return
+	}
+
+	cfg := m.cfg.Fio
+	job := fioJobs[cfg.Job]
+	runtime := int(cfg.Runtime.Seconds())
+	if runtime <= 0 {
+		runtime = 7 * 24 * 3600 // Until stopped
+	}
+	file := filepath.Join(cfg.Dir, "kkperf-fio.tmp")
+	cmd := exec.Command("fio", "--name=kkperf-"+cfg.Job, "--filename="+file, "--size="+cfg.Size,
+		"--rw="+job.rw, "--bs="+job.bs, "--iodepth="+strconv.Itoa(job.iodepth),
+		"--ioengine=libaio", "--direct=1", "--time_based", "--runtime="+strconv.Itoa(runtime),
+		"--status-interval=1", "--output-format=json")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		d.err = err.Error()
+		return
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	setProcessGroup(cmd) // fio forks a process per job
+	if err := cmd.Start(); err != nil {
+		d.err = err.Error()
+		return
+	}
+	children.add(cmd)
+	d.cmd, d.file, d.running, d.job = cmd, file, true, cfg.Job
+	d.iops, d.latency, d.err = 0, 0, ""
+
+	go func() {
+		var lastIOs uint64
+		var lastRuntime int64
+		var lastLatSum float64
+		decoder := json.NewDecoder(stdout)
+		for {
+			var status fioStatus
+			if err := decoder.Decode(&status); err != nil || len(status.Jobs) == 0 {
+				break
+			}
+			j := status.Jobs[0]
+			ios := j.Read.TotalIOs + j.Write.TotalIOs
+			latSum := j.Read.Clat.Mean*float64(j.Read.TotalIOs) + j.Write.Clat.Mean*float64(j.Write.TotalIOs)
+			if ios > lastIOs && j.Runtime > lastRuntime {
+				d.mu.Lock()
+				d.iops = float64(ios-lastIOs) / (float64(j.Runtime-lastRuntime) / 1000)
+				// Interval mean from the change in the cumulative means
+				d.latency = (latSum - lastLatSum) / float64(ios-lastIOs) / 1000
+				d.mu.Unlock()
+			}
+			lastIOs, lastRuntime, lastLatSum = ios, j.Runtime, latSum
+		}
+		err := cmd.Wait()
+		children.remove(cmd)
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.cmd != cmd {
+			return // Stopped on purpose
+		}
+		os.Remove(file)
+		d.cmd, d.running, d.iops, d.latency = nil, false, 0, 0
+		if err != nil {
+			// fio explains failures such as a missing ioengine or an
+			// unwritable directory on stderr
+			lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+			d.err = strings.TrimSpace(lines[len(lines)-1])
+			if d.err == "" {
+				d.err = fmt.Sprintf("fio: %v", err)
+			}
+		}
+	}()
+}
+
+// stopDiskStress terminates a running fio and removes its test file.
+func (m *Monitor) stopDiskStress() {
+	d := m.diskStress
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cmd != nil {
+		killProcessGroup(d.cmd)
+		// fio may still hold the file briefly; unlinking is safe regardless
+		os.Remove(d.file)
+	}
+	d.cmd, d.running, d.iops, d.latency = nil, false, 0, 0
+}
+
+// reading returns the latest IOPS and latency in µs, both 0 while fio is
+// not running.
+func (d *diskStress) reading() (iops, latency float64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.iops, d.latency
+}
+
+// isRunning reports whether fio is running.
+func (d *diskStress) isRunning() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.running
+}
+
+// lastError returns why the last run ended early, "" when it did not.
+func (d *diskStress) lastError() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+// diskStressStatus formats the disk stress part of the status line.
+func (m *Monitor) diskStressStatus() string {
+	d := m.diskStress
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch {
+	case d.running:
+		return fmt.Sprintf("  %s[FIO %s %.0f IOPS %.0f µs]%s", colorRed, d.job, d.iops, d.latency, colorReset)
+	case d.err != "":
+		return fmt.Sprintf("  %s[%s]%s", colorDarkYellow, d.err, colorReset)
+	}
+	return ""
+}
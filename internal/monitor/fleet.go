@@ -0,0 +1,376 @@
+package monitor
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	fleetHostWidth = 24              // Width of the host column of the fleet table
+	fleetTimeout   = 3 * time.Second // Time an agent has to answer a poll
+)
+
+// fleetSortKeys are the columns the fleet table can be sorted by, in the
+// order the sort key cycles through them. Temperature comes first, as the
+// table is mostly opened during a heat event.
+var fleetSortKeys = []string{"temp", "cpu", "power", "alerts", "host"}
+
+// fleetReport is the body of an agent's /sample endpoint.
+type fleetReport struct {
+	Host   string  `json:"host"`
+	Sample *Sample `json:"sample"`
+}
+
+// fleetAgent is the latest state of one agent of the fleet table.
+type fleetAgent struct {
+	addr   string            // Address as given, "host:port"
+	host   string            // Hostname the agent reports, addr until it answers
+	sample *Sample           // Latest sample, nil while unreachable
+	alerts map[string]string // Alerts the sample raises
+	err    error             // Why the last poll failed
+}
+
+// fleetPower returns the package power of an agent, summed over sockets.
+func (a *fleetAgent) fleetPower() (float64, bool) {
+	return packagePower(a.sample)
+}
+
+// pollFleetAgent fetches the latest sample of an agent, sending token
+// when it is set. A failed poll clears the sample, so a host that went
+// down is not shown with stale readings.
+func pollFleetAgent(client *http.Client, token string, a *fleetAgent) {
+	url := a.addr
+	if !strings.Contains(url, "://") {
+		url = "http://" + url
+	}
+	a.sample, a.alerts, a.err = nil, nil, nil
+	req, err := http.NewRequest("GET", strings.TrimSuffix(url, "/")+"/sample", nil)
+	if err != nil {
+		a.err = err
+		return
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if urlErr, ok := err.(*neturl.Error); ok {
+			err = urlErr.Err // The URL is in the row already
+		}
+		a.err = err
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		a.err = fmt.Errorf("%s", resp.Status)
+		return
+	}
+	var report fleetReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil || report.Sample == nil {
+		a.err = fmt.Errorf("not a kkperf agent")
+		return
+	}
+	if report.Host != "" {
+		a.host = report.Host
+	}
+	a.sample, a.alerts = report.Sample, sampleAlerts(report.Sample)
+}
+
+// pollFleet polls every agent at once.
+func pollFleet(client *http.Client, token string, agents []*fleetAgent) {
+	var wg sync.WaitGroup
+	for _, a := range agents {
+		wg.Add(1)
+		go func(a *fleetAgent) {
+			defer wg.Done()
+			pollFleetAgent(client, token, a)
+		}(a)
+	}
+	wg.Wait()
+}
+
+// sortFleet orders agents by a column of fleetSortKeys: numbers from the
+// highest, hosts by name. Unreachable agents go last, by name.
+func sortFleet(agents []*fleetAgent, key string) {
+	value := func(a *fleetAgent) float64 {
+		switch key {
+		case "cpu":
+			return a.sample.CPU
+		case "temp":
+			return a.sample.Temp
+		case "power":
+			watts, _ := a.fleetPower()
+			return watts
+		case "alerts":
+			return float64(len(a.alerts))
+		}
+		return 0
+	}
+	sort.SliceStable(agents, func(i, j int) bool {
+		a, b := agents[i], agents[j]
+		if (a.sample == nil) != (b.sample == nil) {
+			return a.sample != nil
+		}
+		if a.sample == nil || key == "host" {
+			return naturalLess(a.host, b.host)
+		}
+		if va, vb := value(a), value(b); va != vb {
+			return va > vb
+		}
+		return naturalLess(a.host, b.host)
+	})
+}
+
+// fleetWorst returns the worst offender of the fleet whatever the sort:
+// the agent with the most alerts, the hottest among those, then the
+// busiest. It returns nil when no agent is reachable.
+func fleetWorst(agents []*fleetAgent) *fleetAgent {
+	var worst *fleetAgent
+	for _, a := range agents {
+		if a.sample == nil {
+			continue
+		}
+		switch {
+		case worst == nil:
+		case len(a.alerts) != len(worst.alerts):
+			if len(a.alerts) < len(worst.alerts) {
+				continue
+			}
+		case a.sample.Temp != worst.sample.Temp:
+			if a.sample.Temp < worst.sample.Temp {
+				continue
+			}
+		case a.sample.CPU <= worst.sample.CPU:
+			continue
+		}
+		worst = a
+	}
+	return worst
+}
+
+// formatFleetTable renders the fleet table: a header naming the sort
+// column, then one row per agent with its CPU usage, temperature, package
+// power and alerts. The worst offender is marked and its host is red.
+func formatFleetTable(agents []*fleetAgent, key string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%sKode Kronical Fleet%s  %d %s, %s %s  %s(%s)%s\033[K\r\n\r\n", colorCyan, colorReset,
+		len(agents), tr("agents"), tr("sorted by"), key, colorYellow, tr("S: sort, Q: quit"), colorReset)
+
+	heading := func(column, label string, width int) string {
+		if column == key {
+			return colorYellow + padRight(label+" ▼", width) + colorReset
+		}
+		return padRight(label, width)
+	}
+	fmt.Fprintf(&b, "  %s %s %s %s %s\033[K\r\n", heading("host", tr("Host"), fleetHostWidth), heading("cpu", "CPU", 9),
+		heading("temp", tr("Temp"), 10), heading("power", tr("Power"), 10), heading("alerts", tr("Alerts"), 0))
+
+	worst := fleetWorst(agents)
+	for _, a := range agents {
+		host := []rune(a.host)
+		if len(host) > fleetHostWidth {
+			host = append(host[:fleetHostWidth-1], '…')
+		}
+		marker, hostColor := " ", ""
+		if a == worst && (len(a.alerts) > 0 || len(agents) > 1) {
+			marker, hostColor = colorBrightRed+"▶"+colorReset, colorBrightRed
+		}
+		fmt.Fprintf(&b, "%s %s%s%s", marker, hostColor, padRight(string(host), fleetHostWidth), colorReset)
+		if a.sample == nil {
+			reason := "unreachable"
+			if a.err != nil {
+				reason += ": " + a.err.Error()
+			}
+			fmt.Fprintf(&b, " %s%s%s\033[K\r\n", colorRed, reason, colorReset)
+			continue
+		}
+		s := a.sample
+		fmt.Fprintf(&b, " %s%s%s", getUsageColor(s.CPU), padRight(formatPercent(s.CPU, 1), 9), colorReset)
+		if s.Temp > 0 {
+			fmt.Fprintf(&b, " %s%s%s", getTempColor(s.Temp), padRight(formatTemp(s.Temp, 1), 10), colorReset)
+		} else {
+			fmt.Fprintf(&b, " %s", padRight("--", 10))
+		}
+		if watts, ok := a.fleetPower(); ok {
+			fmt.Fprintf(&b, " %s", padRight(unitWatts.format(watts, 1), 10))
+		} else {
+			fmt.Fprintf(&b, " %s", padRight("--", 10))
+		}
+		if len(a.alerts) > 0 {
+			fmt.Fprintf(&b, " %s%s%s", colorRed, strings.Join(sortedKeys(a.alerts), ", "), colorReset)
+		} else {
+			fmt.Fprintf(&b, " %s-%s", colorGreen, colorReset)
+		}
+		b.WriteString("\033[K\r\n")
+	}
+	return b.String()
+}
+
+// runFleet implements the "fleet" subcommand: a table of every agent's
+// CPU usage, temperature, power and alerts, polled from the /sample
+// endpoint kkperf-agent --listen serves, for triaging a rack at a glance.
+// Without a terminal it prints the table once.
+func runFleet(args []string) int {
+	path := configPath()
+	interval := time.Duration(0)
+	key := ""
+
+	fs := flag.NewFlagSet("fleet", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&path, "c", path, "")
+	fs.StringVar(&path, "config", path, "")
+	fs.DurationVar(&interval, "interval", 0, "")
+	fs.StringVar(&key, "sort", "", "")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Println(fleetUsage)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	activeLocale = resolveLocale(cfg)
+	if err := applyTheme(cfg.Theme); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if interval == 0 {
+		interval = cfg.Fleet.Interval
+	}
+	if interval < time.Second {
+		fmt.Fprintln(os.Stderr, "Error: --interval must be at least 1s")
+		return 1
+	}
+	sortIndex := 0
+	if key != "" {
+		sortIndex = -1
+		for i, k := range fleetSortKeys {
+			if k == key {
+				sortIndex = i
+			}
+		}
+		if sortIndex < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --sort must be one of %s\n", strings.Join(fleetSortKeys, ", "))
+			return 1
+		}
+	}
+
+	addrs := fs.Args()
+	if len(addrs) == 0 {
+		addrs = cfg.Fleet.Agents
+	}
+	if len(addrs) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no agents; give their addresses or set agents in [fleet]")
+		return 1
+	}
+	agents := make([]*fleetAgent, len(addrs))
+	for i, addr := range addrs {
+		agents[i] = &fleetAgent{addr: addr, host: addr}
+	}
+	client := &http.Client{Timeout: fleetTimeout}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		pollFleet(client, cfg.Fleet.Token, agents)
+		sortFleet(agents, fleetSortKeys[sortIndex])
+		fmt.Print(strings.NewReplacer("\r\n", "\n", "\033[K", "").Replace(formatFleetTable(agents, fleetSortKeys[sortIndex])))
+		return 0
+	}
+
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	enableVirtualTerminal()
+	fmt.Print(clearScreen + hideCursor)
+	defer func() {
+		fmt.Print(colorReset + showCursor + "\r\n")
+		term.Restore(int(os.Stdin.Fd()), oldState)
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	inputChan := make(chan byte, 1)
+	go func() {
+		for {
+			var b [1]byte
+			if _, err := os.Stdin.Read(b[:]); err != nil {
+				return
+			}
+			inputChan <- b[0]
+		}
+	}()
+	polled := make(chan []*fleetAgent, 1)
+	go func() {
+		for {
+			pollFleet(client, cfg.Fleet.Token, agents)
+			snapshot := make([]*fleetAgent, len(agents))
+			for i, a := range agents {
+				copied := *a
+				snapshot[i] = &copied
+			}
+			polled <- snapshot
+			time.Sleep(interval)
+		}
+	}()
+
+	// The poller owns agents; the table is drawn from its latest copy
+	var latest []*fleetAgent
+	for {
+		select {
+		case <-sigChan:
+			return 0
+		case key := <-inputChan:
+			switch key {
+			case 's', 'S':
+				sortIndex = (sortIndex + 1) % len(fleetSortKeys)
+			case 'q', 'Q', 27, 3:
+				return 0
+			default:
+				continue
+			}
+		case latest = <-polled:
+		}
+		if latest == nil {
+			continue
+		}
+		sortFleet(latest, fleetSortKeys[sortIndex])
+		fmt.Print(moveCursor + formatFleetTable(latest, fleetSortKeys[sortIndex]))
+	}
+}
+
+// fleetUsage documents the fleet subcommand.
+const fleetUsage = `Usage: kkperf fleet [options] [HOST:PORT...]
+
+Show the CPU usage, temperature, package power and alerts of every agent
+in one table, for triaging a rack at a glance. Agents are kkperf-agent
+(or kkperf) instances started with --listen; without addresses the
+agents of the [fleet] config section are polled.
+
+Options:
+  --sort COLUMN        Initial sort: temp (default), cpu, power, alerts or host
+  --interval DURATION  Time between polls (default from [fleet], 2s)
+  -c, --config PATH    Use an alternate config file
+
+Keys: S cycles the sort column, Q quits. The worst offender, the agent
+with the most alerts and then the highest temperature, is marked in red.
+Without a terminal the table is printed once.`
@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFleet polls agents' /sample endpoints and checks the sort of the
+// fleet table and its worst offender, which is the throttling host even
+// when a cooler one is busier.
+func TestFleet(t *testing.T) {
+	var urls []string
+	for _, s := range []*Sample{
+		{Time: time.Now(), CPU: 95, Temp: 70},
+		{Time: time.Now(), CPU: 40, Temp: 88, Throttled: true, Power: []DomainPower{{Domain: "package-0", Watts: 120}}},
+	} {
+		h := &httpSink{}
+		h.write(s)
+		server := httptest.NewServer(http.HandlerFunc(h.serveSample))
+		defer server.Close()
+		urls = append(urls, server.URL)
+	}
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	var agents []*fleetAgent
+	for _, url := range append(urls, down.URL) {
+		agents = append(agents, &fleetAgent{addr: url, host: url})
+	}
+	pollFleet(&http.Client{Timeout: fleetTimeout}, "", agents)
+	if agents[2].sample != nil || agents[2].err == nil {
+		t.Fatalf("closed agent answered: %+v", agents[2])
+	}
+	if agents[1].sample == nil || agents[1].alerts["throttle"] == "" {
+		t.Fatalf("throttling agent: %+v", agents[1])
+	}
+	if watts, ok := agents[1].fleetPower(); !ok || watts != 120 {
+		t.Errorf("power %.1f W, %v", watts, ok)
+	}
+
+	sortFleet(agents, "cpu")
+	if agents[0].sample.CPU != 95 || agents[2].sample != nil {
+		t.Errorf("sorted by CPU: %.0f%% first, unreachable last: %v", agents[0].sample.CPU, agents[2].sample == nil)
+	}
+	if worst := fleetWorst(agents); worst == nil || !worst.sample.Throttled {
+		t.Errorf("worst offender %+v", worst)
+	}
+	table := formatFleetTable(agents, "cpu")
+	if !strings.Contains(table, "unreachable") || !strings.Contains(table, "throttle") {
+		t.Errorf("table:\n%s", table)
+	}
+
+	h := &httpSink{}
+	h.write(&Sample{Time: time.Now(), CPU: 10})
+	guarded := httptest.NewServer(requireToken("s3cret", http.HandlerFunc(h.serveSample)))
+	defer guarded.Close()
+	agent := &fleetAgent{addr: guarded.URL}
+	if pollFleetAgent(http.DefaultClient, "wrong", agent); agent.sample != nil {
+		t.Error("agent answered a wrong token")
+	}
+	if pollFleetAgent(http.DefaultClient, "s3cret", agent); agent.sample == nil {
+		t.Errorf("agent refused its token: %v", agent.err)
+	}
+}
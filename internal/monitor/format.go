@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// formatFuncs are the helper functions available to --format templates.
+var formatFuncs = template.FuncMap{
+	// number formats a value with the given decimals in the active locale
+	"number": formatNumber,
+	// percent formats a percentage, e.g. {{percent .CPU 0}} -> "42%"
+	"percent": formatPercent,
+	// temp formats a Celsius reading in the configured unit, e.g. "61.0°C"
+	"temp": formatTemp,
+	// bar returns a single eighth-block character for a 0-100% value
+	"bar": func(v float64) string {
+		level := int(v / 12.5)
+		if level < 1 {
+			level = 1
+		}
+		if level > 8 {
+			level = 8
+		}
+		return barChars[level]
+	},
+	// json encodes a value, e.g. {{json .}} for a snapshot kkperf diff reads
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// runFormat collects one sample, renders it through a text/template, prints
+// the result, and returns. This lets desktop widgets (Conky, GenMon, i3bar
+// scripts) reuse the collectors without running the TUI.
+func runFormat(cfg *Config, format string) error {
+	// Allow escapes such as \n and \t in the template given on the command line
+	format = strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(format)
+
+	tmpl, err := template.New("format").Funcs(formatFuncs).Parse(format)
+	if err != nil {
+		return err
+	}
+
+	m := NewMonitor(cfg)
+	sample := m.takeSample(500 * time.Millisecond)
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, sample); err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	_, err = os.Stdout.Write(out.Bytes())
+	return err
+}
+
+// formatUsage documents the fields and functions available to --format.
+const formatUsage = `Template fields: .Time .CPU .Cores .CoreTemps .Temp .GPU .GPUs .Disk .Net .IOWait .Blocked .Load .Running .Tasks .Container .CPULimit .CPUThrottled .Stress .Throttled .RawTemp .TjMax .Headroom .Limited .Power .Disks .DiskIOPS .DiskLatency .Ambient .HasAmbient .Fans .Batteries .Cooling .CoolingFailure .PSUInput .PSUOutput .PSURails .MemUsed .MemTotal .SwapUsed .SwapTotal .Zombies .Threads .ThreadMax .FDs .FDMax .HealthWarning .Entropy .EntropyPool .ClockSource .ClockSynced .ClockOffset .ClockStep .ClockSteps .Alerts
+Template functions: number, percent, temp (value, decimals), bar (value), json (value)
+Example: --format '{{percent .CPU 0}} {{temp .Temp 0}}'`
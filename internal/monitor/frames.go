@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// frameWriter hands the output of the TUI to the terminal from a
+// goroutine of its own, so a terminal that cannot keep up, such as one on
+// a slow SSH link, holds up neither the polls nor the keys. Everything
+// written between two flushes goes out as one write. While a frame is
+// still on its way the render loop skips drawing the next, and what else
+// was written, such as a clear screen after a key, joins the next frame
+// that is drawn. Write and flush are called from the main loop only.
+type frameWriter struct {
+	out    io.Writer
+	buf    []byte
+	frames chan []byte
+	done   chan struct{}
+	busy   int32 // 1 while a frame is being written; accessed atomically
+}
+
+// newFrameWriter starts writing frames to out.
+func newFrameWriter(out io.Writer) *frameWriter {
+	f := &frameWriter{out: out, frames: make(chan []byte, 1), done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		for frame := range f.frames {
+			start := time.Now()
+			f.out.Write(frame)
+			statWriteTime.Set(float64(time.Since(start)) / float64(time.Millisecond))
+			atomic.StoreInt32(&f.busy, 0)
+		}
+	}()
+	return f
+}
+
+// Write adds p to the frame being built.
+func (f *frameWriter) Write(p []byte) (int, error) {
+	f.buf = append(f.buf, p...)
+	return len(p), nil
+}
+
+// ready reports whether the terminal has taken the previous frame.
+func (f *frameWriter) ready() bool {
+	return atomic.LoadInt32(&f.busy) == 0
+}
+
+// flush sends the frame built so far, unless the previous one is still
+// being written; then it is kept and sent with the next.
+func (f *frameWriter) flush() {
+	if len(f.buf) == 0 || !f.ready() {
+		return
+	}
+	atomic.StoreInt32(&f.busy, 1)
+	f.frames <- f.buf
+	f.buf = nil
+}
+
+// close sends what is left and waits until the terminal has taken it.
+func (f *frameWriter) close() {
+	if len(f.buf) > 0 {
+		f.frames <- f.buf // Room is left: flush sends only once the goroutine has taken the previous frame
+		f.buf = nil
+	}
+	close(f.frames)
+	<-f.done
+}
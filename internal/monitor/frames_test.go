@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowTerminal is a terminal that takes each write only when the test
+// lets it.
+type slowTerminal struct {
+	proceed chan bool
+	writes  []string
+}
+
+// Write waits for the test, then records p.
+func (s *slowTerminal) Write(p []byte) (int, error) {
+	<-s.proceed
+	s.writes = append(s.writes, string(p))
+	return len(p), nil
+}
+
+// TestFrameWriter checks that a frame is not queued behind one the
+// terminal is still taking, and that what is written meanwhile goes out
+// with the next frame.
+func TestFrameWriter(t *testing.T) {
+	term := &slowTerminal{proceed: make(chan bool)}
+	f := newFrameWriter(term)
+	if !f.ready() {
+		t.Fatal("not ready before the first frame")
+	}
+	fmt.Fprint(f, "frame 1")
+	f.flush()
+	if f.ready() {
+		t.Error("ready while the terminal takes frame 1")
+	}
+	fmt.Fprint(f, clearScreen)
+	f.flush() // Kept: frame 1 is still on its way
+	fmt.Fprint(f, "frame 2")
+	term.proceed <- true
+	for !f.ready() {
+		time.Sleep(time.Millisecond)
+	}
+	f.flush()
+	term.proceed <- true
+	fmt.Fprint(f, "bye")
+	go func() { term.proceed <- true }()
+	f.close()
+
+	want := []string{"frame 1", clearScreen + "frame 2", "bye"}
+	if strings.Join(term.writes, "|") != strings.Join(want, "|") {
+		t.Errorf("writes %q, want %q", term.writes, want)
+	}
+}
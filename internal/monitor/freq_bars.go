@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+)
+
+// clockKHz returns the clock of a core, preferring the effective clock
+// over the requested one, or 0 when unknown.
+func (c *clockSampler) clockKHz(core int) float64 {
+	if c.cores[core].effectiveKHz > 0 {
+		return c.cores[core].effectiveKHz
+	}
+	return c.cores[core].curKHz
+}
+
+// scaleKHz returns the clock a full frequency bar stands for: the highest
+// boost clock, or the highest clock seen when the CPU does not report one.
+func (c *clockSampler) scaleKHz() float64 {
+	return math.Max(c.maxKHz, c.peakKHz)
+}
+
+// coreBarHeights returns the height of each core bar in percent: the
+// usage, or with the frequency bars on, the clock as a share of scaleKHz.
+// Bar colors follow the estimated core temperature either way.
+func (m *Monitor) coreBarHeights(coreUsages []float64) []float64 {
+	scale := m.clocks.scaleKHz()
+	if !m.freqBars || scale == 0 {
+		return coreUsages
+	}
+	heights := make([]float64, len(coreUsages))
+	for i := range heights {
+		if i < len(m.clocks.cores) {
+			heights[i] = math.Min(m.clocks.clockKHz(i)/scale*100, 100)
+		}
+	}
+	return heights
+}
+
+// coreBarsTitle returns the heading of the core bars. With the frequency
+// bars on it carries the average, lowest and highest core clock, so a
+// package that throttles under stress shows at a glance.
+func (m *Monitor) coreBarsTitle() string {
+	if !m.freqBars {
+		return fmt.Sprintf(tr("CPU Cores (%d cores):"), m.cores)
+	}
+	low, high := 0.0, 0.0
+	for i := range m.clocks.cores {
+		clock := m.clocks.clockKHz(i)
+		if clock > 0 && (low == 0 || clock < low) {
+			low = clock
+		}
+		high = math.Max(high, clock)
+	}
+	return fmt.Sprintf(tr("CPU Core Clocks (%d cores): avg %s, min %s, max %s"), m.cores,
+		formatMHz(m.clocks.meanKHz()), formatMHz(low), formatMHz(high))
+}
+
+// toggleFreqBars switches the core bars between usage and clock. It does
+// nothing when no clocks are known.
+func (m *Monitor) toggleFreqBars() {
+	if !m.clocks.available {
+		return
+	}
+	m.freqBars = !m.freqBars
+	fmt.Fprint(m.out, clearScreen)
+}
@@ -0,0 +1,259 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	scatterCapacity = 3600 // Polls kept, 30 minutes
+	scatterWidth    = 60   // Plot columns
+	scatterHeight   = 16   // Plot rows
+	scatterBins     = 9    // Most columns of the throttle curve table
+)
+
+// scatterPoint is one poll of the frequency scatter: the mean core clock
+// together with the package temperature and power at the time.
+type scatterPoint struct {
+	temp  float64 // Package temperature in °C
+	mhz   float64 // Mean core clock in MHz
+	watts float64 // Package power in W, 0 when unknown
+}
+
+// meanKHz returns the mean clock of all cores, preferring effective
+// clocks, or 0 when no clock is known.
+func (c *clockSampler) meanKHz() float64 {
+	sum, n := 0.0, 0
+	for i := range c.cores {
+		if clock := c.clockKHz(i); clock > 0 {
+			sum += clock
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// packageWatts returns the power of the CPU packages from RAPL, or the
+// AMD package power tracking reading, or 0 when neither is available.
+func (m *Monitor) packageWatts(power []DomainPower) float64 {
+	watts := 0.0
+	for _, p := range power {
+		if strings.HasPrefix(p.Domain, "package") {
+			watts += p.Watts
+		}
+	}
+	if watts == 0 {
+		watts = m.smu.ppt
+	}
+	return watts
+}
+
+// recordScatter adds a poll to the frequency scatter, dropping the oldest
+// point when it is full. Polls without a temperature or clock are left out.
+func (m *Monitor) recordScatter(temp float64, power []DomainPower) {
+	mhz := m.clocks.meanKHz() / 1000
+	if temp <= 0 || mhz <= 0 {
+		return
+	}
+	if len(m.scatter) >= scatterCapacity {
+		copy(m.scatter, m.scatter[1:])
+		m.scatter = m.scatter[:len(m.scatter)-1]
+	}
+	m.scatter = append(m.scatter, scatterPoint{temp: temp, mhz: mhz, watts: m.packageWatts(power)})
+}
+
+// scatterAxis returns the range of v over the points, widened to whole
+// steps and to at least one step.
+func scatterAxis(points []scatterPoint, v func(scatterPoint) float64, step float64) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		lo, hi = math.Min(lo, v(p)), math.Max(hi, v(p))
+	}
+	lo, hi = math.Floor(lo/step)*step, math.Ceil(hi/step)*step
+	if hi <= lo {
+		hi = lo + step
+	}
+	return lo, hi
+}
+
+// correlation returns the Pearson correlation coefficient of x and y over
+// the points, and false when either does not vary.
+func correlation(points []scatterPoint, x, y func(scatterPoint) float64) (float64, bool) {
+	n := float64(len(points))
+	var sx, sy, sxx, syy, sxy float64
+	for _, p := range points {
+		a, b := x(p), y(p)
+		sx, sy = sx+a, sy+b
+		sxx, syy, sxy = sxx+a*a, syy+b*b, sxy+a*b
+	}
+	vx, vy := sxx-sx*sx/n, syy-sy*sy/n
+	if vx <= 0 || vy <= 0 {
+		return 0, false
+	}
+	return (sxy - sx*sy/n) / math.Sqrt(vx*vy), true
+}
+
+// handleScatterKey processes a key press while the frequency scatter page
+// is shown. It returns false when the application should quit.
+func (m *Monitor) handleScatterKey(key byte) bool {
+	switch m.pageAction(key, actionScatter) {
+	case actionStress:
+		if m.stressRunning {
+			m.stopStress()
+		} else {
+			m.startStress()
+		}
+		return true
+	case actionClose:
+		m.showScatter = false
+		fmt.Fprint(m.out, clearScreen)
+		return true
+	case actionQuit:
+		return false
+	}
+	switch key {
+	case 'p', 'P':
+		m.scatterPower = !m.scatterPower
+		fmt.Fprint(m.out, clearScreen)
+	case 'r', 'R':
+		m.scatter = m.scatter[:0]
+		fmt.Fprint(m.out, clearScreen)
+	}
+	return true
+}
+
+// displayScatterPage plots the mean core clock of every poll against the
+// package temperature, or against package power after P, so the throttle
+// curve of the chip can be read off after a stress run. Points are colored
+// by the other of the two, and a table below gives the mean clock per
+// temperature or power band.
+func (m *Monitor) displayScatterPage() {
+	points := m.scatter
+	hasPower := false
+	for _, p := range points {
+		hasPower = hasPower || p.watts > 0
+	}
+	byPower := m.scatterPower && hasPower
+	title := tr("Frequency vs Temperature")
+	if byPower {
+		title = tr("Frequency vs Power")
+	}
+	fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor - %s ===%s\r\n\r\n", colorGreen, title, colorReset)
+	footer := tr("SPACE: stress  P: temperature/power  R: reset  X/ESC: close")
+
+	if len(points) < 2 {
+		fmt.Fprintf(m.out, "  %s\r\n", tr("Waiting for samples with both a temperature and a core clock."))
+		if !m.clocks.available {
+			fmt.Fprintf(m.out, "  %s\r\n", tr("CPU frequency information is not available (no cpufreq in /sys/devices/system/cpu)"))
+		}
+		fmt.Fprintf(m.out, "\r\n%s%s%s\r\n", colorYellow, footer, colorReset)
+		return
+	}
+
+	temp := func(p scatterPoint) float64 { return p.temp }
+	watts := func(p scatterPoint) float64 { return p.watts }
+	mhz := func(p scatterPoint) float64 { return p.mhz }
+	x, color, xStep := temp, watts, 5.0
+	xLabel := func(v float64) string { return formatTemp(v, 0) }
+	colorLabel := func(v float64) string { return unitWatts.format(v, 0) }
+	colorOf := func(v, lo, hi float64) string { return getUsageColor((v - lo) / (hi - lo) * 100) }
+	if m.scatterPower && !hasPower {
+		fmt.Fprintf(m.out, "%s%s%s\r\n", colorDarkYellow, tr("Package power is not available; plotting against temperature."), colorReset)
+	} else if byPower {
+		x, color, xStep = watts, temp, 10.0
+		xLabel, colorLabel = colorLabel, xLabel
+		colorOf = func(v, lo, hi float64) string { return getTempColor(v) }
+	}
+	colored := hasPower // Without power readings the points have one color
+
+	xLo, xHi := scatterAxis(points, x, xStep)
+	yLo, yHi := scatterAxis(points, mhz, 100)
+	cLo, cHi := scatterAxis(points, color, 1)
+	counts := make([][]int, scatterHeight)
+	colorSums := make([][]float64, scatterHeight)
+	for row := range counts {
+		counts[row] = make([]int, scatterWidth)
+		colorSums[row] = make([]float64, scatterWidth)
+	}
+	for _, p := range points {
+		col := int((x(p) - xLo) / (xHi - xLo) * (scatterWidth - 1))
+		row := scatterHeight - 1 - int((p.mhz-yLo)/(yHi-yLo)*(scatterHeight-1))
+		counts[row][col]++
+		colorSums[row][col] += color(p)
+	}
+
+	if r, ok := correlation(points, x, mhz); ok {
+		fmt.Fprintf(m.out, "%s  %s\r\n", fmt.Sprintf(tr("%d samples"), len(points)), fmt.Sprintf(tr("correlation r = %.2f"), r))
+	} else {
+		fmt.Fprintf(m.out, "%s\r\n", fmt.Sprintf(tr("%d samples"), len(points)))
+	}
+	for row := 0; row < scatterHeight; row++ {
+		label := ""
+		if row%5 == 0 || row == scatterHeight-1 {
+			label = fmt.Sprintf("%.0f MHz", yHi-float64(row)/(scatterHeight-1)*(yHi-yLo))
+		}
+		var line strings.Builder
+		for col, n := range counts[row] {
+			if n == 0 {
+				line.WriteByte(' ')
+				continue
+			}
+			dot := "·"
+			if n >= 5 {
+				dot = "●"
+			} else if n >= 2 {
+				dot = "•"
+			}
+			if colored {
+				line.WriteString(colorOf(colorSums[row][col]/float64(n), cLo, cHi) + dot + colorReset)
+			} else {
+				line.WriteString(colorCyan + dot + colorReset)
+			}
+		}
+		fmt.Fprintf(m.out, "%9s │%s\r\n", label, line.String())
+	}
+	axis := []rune(strings.Repeat(" ", scatterWidth))
+	lo, hi := []rune(xLabel(xLo)), []rune(xLabel(xHi))
+	copy(axis, lo)
+	copy(axis[scatterWidth-len(hi):], hi)
+	fmt.Fprintf(m.out, "%9s └%s\r\n", "", strings.Repeat("─", scatterWidth))
+	fmt.Fprintf(m.out, "%9s  %s\r\n", "", string(axis))
+	if colored {
+		fmt.Fprintf(m.out, "%9s  %s %s%s%s … %s%s%s\r\n", "", tr("Color:"),
+			colorOf(cLo, cLo, cHi), colorLabel(cLo), colorReset, colorOf(cHi, cLo, cHi), colorLabel(cHi), colorReset)
+	}
+	fmt.Fprint(m.out, "\r\n")
+
+	// Mean clock per band of the x axis: the throttle curve
+	step := xStep
+	for (xHi-xLo)/step > scatterBins {
+		step *= 2
+	}
+	bands := int(math.Ceil((xHi - xLo) / step))
+	sums := make([]float64, bands)
+	ns := make([]int, bands)
+	for _, p := range points {
+		b := int((x(p) - xLo) / step)
+		if b >= bands {
+			b = bands - 1
+		}
+		sums[b] += p.mhz
+		ns[b]++
+	}
+	var heads, values strings.Builder
+	for b := 0; b < bands; b++ {
+		if ns[b] == 0 {
+			continue
+		}
+		heads.WriteString(fmt.Sprintf("%8s", "≥"+xLabel(xLo+float64(b)*step)))
+		values.WriteString(fmt.Sprintf("%8.0f", sums[b]/float64(ns[b])))
+	}
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, tr("Mean clock (MHz):"), colorReset)
+	fmt.Fprintf(m.out, "%s\r\n%s\r\n", heads.String(), values.String())
+
+	fmt.Fprintf(m.out, "\r\n%s%s%s\r\n", colorYellow, footer, colorReset)
+}
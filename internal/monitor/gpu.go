@@ -0,0 +1,244 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	gpuNameWidth    = 24   // Width of the name column of the GPU panel
+	gpuBusyBarWidth = 8    // Cells of the utilization bar
+	nvidiaSMIPeriod = 1000 // Milliseconds between nvidia-smi reports
+)
+
+// nvidiaSMIQuery are the nvidia-smi fields the sampler reads, in the
+// order parseNvidiaSMI expects them.
+const nvidiaSMIQuery = "index,name,utilization.gpu,temperature.gpu,memory.used,memory.total"
+
+// GPUReading is the state of one graphics card.
+type GPUReading struct {
+	Index     int     // Card number: the DRM card for amdgpu, the nvidia-smi index for NVIDIA
+	Name      string  // Marketing name when the driver reports one
+	Driver    string  // "amdgpu" or "nvidia"
+	Busy      float64 // Utilization (0-100%), -1 when not reported
+	Temp      float64 // Temperature in °C, 0 when not reported
+	VRAMUsed  uint64  // Video memory in use in bytes
+	VRAMTotal uint64  // Video memory in bytes, 0 when not reported
+}
+
+// gpuSampler reads AMD cards from the amdgpu sysfs attributes at every
+// poll, and NVIDIA cards from an nvidia-smi process that reports in a
+// loop, as starting one per poll would cost more than it measures.
+type gpuSampler struct {
+	amd []string // Device directories of amdgpu cards
+
+	mu     sync.Mutex
+	nvidia map[int]GPUReading // Latest nvidia-smi report by index
+}
+
+// newGPUSampler finds the amdgpu cards and starts nvidiaSMI when it is
+// installed; an empty nvidiaSMI skips NVIDIA cards. It returns nil when
+// there is neither.
+func newGPUSampler(nvidiaSMI string) *gpuSampler {
+	g := &gpuSampler{nvidia: map[int]GPUReading{}}
+	busy, _ := filepath.Glob(filepath.Join(sysDir, "class", "drm", "card*", "device", "gpu_busy_percent"))
+	for _, path := range busy {
+		g.amd = append(g.amd, filepath.Dir(path))
+	}
+	sort.Strings(g.amd)
+
+	started := false
+	if nvidiaSMI != "" {
+		if path, err := exec.LookPath(nvidiaSMI); err == nil {
+			started = g.startNvidiaSMI(path)
+		}
+	}
+	if len(g.amd) == 0 && !started {
+		return nil
+	}
+	return g
+}
+
+// startNvidiaSMI runs nvidia-smi in its loop mode and keeps the latest
+// line of every card. Readings are dropped when it exits, e.g. after a
+// driver reload.
+func (g *gpuSampler) startNvidiaSMI(path string) bool {
+	cmd := exec.Command(path, "--query-gpu="+nvidiaSMIQuery, "--format=csv,noheader,nounits",
+		"-lms", strconv.Itoa(nvidiaSMIPeriod))
+	stdout, err := cmd.StdoutPipe()
+	if err != nil || cmd.Start() != nil {
+		return false
+	}
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if r, ok := parseNvidiaSMI(scanner.Text()); ok {
+				g.mu.Lock()
+				g.nvidia[r.Index] = r
+				g.mu.Unlock()
+			}
+		}
+		cmd.Wait()
+		g.mu.Lock()
+		g.nvidia = map[int]GPUReading{}
+		g.mu.Unlock()
+	}()
+	return true
+}
+
+// parseNvidiaSMI reads one line of nvidia-smi CSV output, e.g.
+// "0, NVIDIA GeForce RTX 3080, 45, 62, 3072, 10240" with memory in MiB.
+// Fields a card does not support read "[N/A]" or "[Not Supported]".
+func parseNvidiaSMI(line string) (GPUReading, bool) {
+	fields := strings.Split(line, ",")
+	if len(fields) != 6 {
+		return GPUReading{}, false
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	index, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return GPUReading{}, false
+	}
+	number := func(s string) (float64, bool) {
+		v, err := strconv.ParseFloat(s, 64)
+		return v, err == nil
+	}
+	r := GPUReading{Index: index, Name: fields[1], Driver: "nvidia", Busy: -1}
+	if v, ok := number(fields[2]); ok {
+		r.Busy = v
+	}
+	if v, ok := number(fields[3]); ok {
+		r.Temp = v
+	}
+	if v, ok := number(fields[4]); ok {
+		r.VRAMUsed = uint64(v) << 20
+	}
+	if v, ok := number(fields[5]); ok {
+		r.VRAMTotal = uint64(v) << 20
+	}
+	return r, true
+}
+
+// sample returns the readings of all cards, AMD first.
+func (g *gpuSampler) sample() []GPUReading {
+	if g == nil {
+		return nil
+	}
+	var gpus []GPUReading
+	for _, dir := range g.amd {
+		gpus = append(gpus, readAMDGPU(dir))
+	}
+	g.mu.Lock()
+	indices := make([]int, 0, len(g.nvidia))
+	for i := range g.nvidia {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	for _, i := range indices {
+		gpus = append(gpus, g.nvidia[i])
+	}
+	g.mu.Unlock()
+	return gpus
+}
+
+// readAMDGPU reads an amdgpu card from its device directory: utilization,
+// VRAM, the edge temperature of its hwmon, and the product name some
+// boards report.
+func readAMDGPU(dir string) GPUReading {
+	r := GPUReading{Name: "AMD GPU", Driver: "amdgpu", Busy: -1}
+	fmt.Sscanf(filepath.Base(filepath.Dir(dir)), "card%d", &r.Index)
+	read := func(name string) (float64, bool) {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return 0, false
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+		return v, err == nil
+	}
+	if v, ok := read("gpu_busy_percent"); ok {
+		r.Busy = v
+	}
+	if v, ok := read("mem_info_vram_used"); ok {
+		r.VRAMUsed = uint64(v)
+	}
+	if v, ok := read("mem_info_vram_total"); ok {
+		r.VRAMTotal = uint64(v)
+	}
+	temps, _ := filepath.Glob(filepath.Join(dir, "hwmon", "hwmon*", "temp1_input"))
+	if len(temps) > 0 {
+		if data, err := ioutil.ReadFile(temps[0]); err == nil {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64); err == nil {
+				r.Temp = v / 1000
+			}
+		}
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "product_name")); err == nil && strings.TrimSpace(string(data)) != "" {
+		r.Name = strings.TrimSpace(string(data))
+	}
+	return r
+}
+
+// busiestGPU returns the highest utilization of gpus, or -1 when none
+// reports one.
+func busiestGPU(gpus []GPUReading) float64 {
+	busiest := -1.0
+	for _, g := range gpus {
+		if g.Busy > busiest {
+			busiest = g.Busy
+		}
+	}
+	return busiest
+}
+
+// gpuPanelRows returns the height of the GPU panel, which changes when
+// nvidia-smi starts or stops reporting.
+func (m *Monitor) gpuPanelRows() int {
+	if !m.cfg.GPU.Panel || len(m.gpuReadings) == 0 {
+		return 0
+	}
+	return len(m.gpuReadings) + 2
+}
+
+// displayGPUs draws the GPU panel: one row per card with its utilization
+// bar, VRAM and temperature, colored like the CPU's. It draws nothing
+// without a GPU or with the panel turned off.
+func (m *Monitor) displayGPUs() {
+	if m.gpuPanelRows() == 0 {
+		return
+	}
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, tr("GPUs:"), colorReset)
+	for _, g := range m.gpuReadings {
+		name := fmt.Sprintf("%d %s", g.Index, g.Name)
+		if len([]rune(name)) > gpuNameWidth {
+			name = string([]rune(name)[:gpuNameWidth-1]) + "…"
+		}
+		busy := "--"
+		if g.Busy >= 0 {
+			filled := int(g.Busy/100*gpuBusyBarWidth + 0.5)
+			if filled > gpuBusyBarWidth {
+				filled = gpuBusyBarWidth
+			}
+			busy = fmt.Sprintf("%s%s%s%s %6s", getUsageColor(g.Busy), strings.Repeat("■", filled), colorReset,
+				strings.Repeat("·", gpuBusyBarWidth-filled), formatPercent(g.Busy, 1))
+		}
+		fmt.Fprintf(m.out, "  %s %s", padRight(name, gpuNameWidth), busy)
+		if g.VRAMTotal > 0 {
+			fmt.Fprintf(m.out, "  %s%s%s %s", colorBlue, tr("VRAM"), colorReset,
+				memBar(float64(g.VRAMUsed)/float64(g.VRAMTotal)*100, g.VRAMUsed, g.VRAMTotal))
+		}
+		if g.Temp > 0 {
+			fmt.Fprintf(m.out, "  %s%s%s", getTempColor(g.Temp), formatTemp(g.Temp, 1), colorReset)
+		}
+		fmt.Fprint(m.out, "    \r\n") // Blanks what a wider reading left
+	}
+	fmt.Fprint(m.out, "\r\n")
+}
@@ -0,0 +1,21 @@
+package monitor
+
+import (
+	"testing"
+)
+
+// TestNvidiaSMI checks the parsing of nvidia-smi's CSV lines, including
+// fields a card does not support.
+func TestNvidiaSMI(t *testing.T) {
+	r, ok := parseNvidiaSMI("0, NVIDIA GeForce RTX 3080, 45, 62, 3072, 10240")
+	if !ok || r.Name != "NVIDIA GeForce RTX 3080" || r.Busy != 45 || r.Temp != 62 || r.VRAMUsed != 3072<<20 || r.VRAMTotal != 10240<<20 {
+		t.Errorf("parsed %+v, %v", r, ok)
+	}
+	r, ok = parseNvidiaSMI("1, Tesla K80, [Not Supported], 40, [N/A], [N/A]")
+	if !ok || r.Index != 1 || r.Busy != -1 || r.Temp != 40 || r.VRAMTotal != 0 {
+		t.Errorf("parsed %+v, %v", r, ok)
+	}
+	if _, ok := parseNvidiaSMI("NVIDIA-SMI has failed because it couldn't communicate with the NVIDIA driver."); ok {
+		t.Error("parsed an error message")
+	}
+}
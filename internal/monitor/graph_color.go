@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// colorSource is what drives the colors of a series in the history graph.
+type colorSource int
+
+const (
+	colorByTemp  colorSource = iota // Temperature gradient, from the temperature at each point
+	colorByUsage                    // Green-to-red gradient of CPU usage
+	colorByPower                    // Green-to-red gradient of package power, up to the peak in the window
+	colorByFixed                    // One color for the whole series
+)
+
+// graphColor is the parsed form of a [graph_color] setting.
+type graphColor struct {
+	source  colorSource
+	r, g, b int // Color of colorByFixed
+}
+
+// fixedColors are the names a fixed series color can be given by.
+var fixedColors = map[string][3]int{
+	"red": {255, 0, 0}, "green": {0, 200, 0}, "yellow": {255, 255, 0}, "blue": {80, 120, 255},
+	"magenta": {255, 0, 255}, "cyan": {0, 255, 255}, "white": {255, 255, 255}, "gray": {128, 128, 128},
+}
+
+// parseGraphColor parses a [graph_color] setting: "temp", "usage",
+// "power", a color name of fixedColors, or "#rrggbb".
+func parseGraphColor(name string) (graphColor, error) {
+	switch name {
+	case "temp":
+		return graphColor{source: colorByTemp}, nil
+	case "usage":
+		return graphColor{source: colorByUsage}, nil
+	case "power":
+		return graphColor{source: colorByPower}, nil
+	}
+	if rgb, ok := fixedColors[strings.ToLower(name)]; ok {
+		return graphColor{source: colorByFixed, r: rgb[0], g: rgb[1], b: rgb[2]}, nil
+	}
+	var c graphColor
+	if len(name) == 7 && name[0] == '#' {
+		if _, err := fmt.Sscanf(name[1:], "%02x%02x%02x", &c.r, &c.g, &c.b); err == nil {
+			c.source = colorByFixed
+			return c, nil
+		}
+	}
+	return c, fmt.Errorf("must be temp, usage, power, a color name, or \"#rrggbb\"")
+}
+
+// seriesColors returns the colors of the display buffer's columns for a
+// series colored by c. Power is scaled to the peak of the window, as on
+// the power graph; without RAPL it falls back to the usage colors.
+func (m *Monitor) seriesColors(c graphColor) []string {
+	colors := make([]string, len(m.displayBuffer))
+	peak := 0.0
+	if c.source == colorByPower {
+		for _, p := range m.displayBuffer {
+			peak = math.Max(peak, m.packagePower(p))
+		}
+	}
+	for i, p := range m.displayBuffer {
+		switch {
+		case c.source == colorByTemp:
+			colors[i] = getTempColor(p.temp)
+		case c.source == colorByPower && peak > 0:
+			colors[i] = getUsageColor(math.Max(0, m.packagePower(p)) / peak * 100)
+		case c.source == colorByFixed:
+			colors[i] = fmt.Sprintf("%s\033[38;2;%d;%d;%dm", activeTheme.prefix, c.r, c.g, c.b)
+		default:
+			colors[i] = getUsageColor(p.cpu)
+		}
+	}
+	return colors
+}
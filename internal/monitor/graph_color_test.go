@@ -0,0 +1,42 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestGraphColor checks the [graph_color] settings and the colors each
+// source gives the columns of the graph.
+func TestGraphColor(t *testing.T) {
+	for name, want := range map[string]graphColor{
+		"temp":    {source: colorByTemp},
+		"power":   {source: colorByPower},
+		"Cyan":    {source: colorByFixed, r: 0, g: 255, b: 255},
+		"#ff8000": {source: colorByFixed, r: 255, g: 128, b: 0},
+	} {
+		if got, err := parseGraphColor(name); err != nil || got != want {
+			t.Errorf("parseGraphColor(%q) = %+v, %v; want %+v", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"", "heat", "#ff80", "#gg0000"} {
+		if _, err := parseGraphColor(name); err == nil {
+			t.Errorf("parseGraphColor(%q) accepted", name)
+		}
+	}
+
+	m := &Monitor{rapl: &raplSampler{domains: []raplDomain{{name: "package-0"}}}}
+	m.displayBuffer = []historyPoint{{cpu: 10, temp: 90, power: []float64{50}}, {cpu: 90, temp: 40, power: []float64{100}}}
+	for _, c := range []struct {
+		color graphColor
+		want  []string
+	}{
+		{graphColor{source: colorByTemp}, []string{getTempColor(90), getTempColor(40)}},
+		{graphColor{source: colorByUsage}, []string{getUsageColor(10), getUsageColor(90)}},
+		{graphColor{source: colorByPower}, []string{getUsageColor(50), getUsageColor(100)}},
+		{graphColor{source: colorByFixed, r: 1, g: 2, b: 3}, []string{"\033[38;2;1;2;3m", "\033[38;2;1;2;3m"}},
+	} {
+		if got := m.seriesColors(c.color); strings.Join(got, "|") != strings.Join(c.want, "|") {
+			t.Errorf("seriesColors(%+v) = %q, want %q", c.color, got, c.want)
+		}
+	}
+}
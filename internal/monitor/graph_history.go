@@ -0,0 +1,264 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultPollInterval = 500 * time.Millisecond // Time between polls of the main loop
+	minWindow           = 15 * time.Second       // Shortest graph window
+	maxWindow           = 24 * time.Hour         // Longest graph window
+	defaultWindow       = 30 * time.Second       // Graph window at startup
+	historyLevelSize    = 128                    // Points per level; level n keeps one point every 2^n polls
+)
+
+// graphHistory retains the graph history at several resolutions, like a
+// round-robin database: level 0 keeps the last 128 polls, level 1 one
+// point for every two polls of the last 256, and so on, with as many
+// levels as the last one needs to span maxWindow. A graph of any
+// window takes each column from the finest level that still reaches back
+// to the column's time, so zooming never loses data and the right edge is
+// always the latest poll.
+type graphHistory struct {
+	levels   [][]historyPoint // Oldest first
+	pending  []historyRange   // Polls of each level's next point
+	polls    int              // Points added since startup
+	interval time.Duration    // Time between polls
+}
+
+// historyRange aggregates the series that a coarse point or a graph
+// column summarizes rather than samples: CPU usage as its mean and range,
+// so brief spikes survive downsampling, wakeup latency as its mean and
+// worst case, and whether the CPU throttled or was limited at all.
+type historyRange struct {
+	n                      int
+	cpuSum, cpuMin, cpuMax float64
+	latencySum, latencyMax float64
+	throttled, limited     bool
+}
+
+// add folds a point into the range.
+func (r *historyRange) add(p historyPoint) {
+	if r.n == 0 || p.cpuMin < r.cpuMin {
+		r.cpuMin = p.cpuMin
+	}
+	if r.n == 0 || p.cpuMax > r.cpuMax {
+		r.cpuMax = p.cpuMax
+	}
+	if p.latencyMax > r.latencyMax {
+		r.latencyMax = p.latencyMax
+	}
+	r.throttled = r.throttled || p.throttled
+	r.limited = r.limited || p.limited
+	r.n++
+	r.cpuSum += p.cpu
+	r.latencySum += p.latencyAvg
+}
+
+// apply stores the aggregates in p, which carries the sampled series.
+func (r *historyRange) apply(p historyPoint) historyPoint {
+	p.cpu, p.cpuMin, p.cpuMax = r.cpuSum/float64(r.n), r.cpuMin, r.cpuMax
+	p.latencyAvg, p.latencyMax = r.latencySum/float64(r.n), r.latencyMax
+	p.throttled, p.limited = r.throttled, r.limited
+	return p
+}
+
+// newGraphHistory returns an empty history of polls interval apart.
+func newGraphHistory(interval time.Duration) *graphHistory {
+	n := historyLevelCount(interval)
+	return &graphHistory{levels: make([][]historyPoint, n), pending: make([]historyRange, n), interval: interval}
+}
+
+// historyLevelCount returns how many levels the history of polls interval
+// apart needs for the last one to span maxWindow: 12 at the default
+// interval, whose last level spans 36 hours, and 14 at the shortest.
+func historyLevelCount(interval time.Duration) int {
+	n := 1
+	for time.Duration(historyLevelSize<<(n-1))*interval < maxWindow {
+		n++
+	}
+	return n
+}
+
+// add records the point of one poll in every level it is due in.
+func (h *graphHistory) add(p historyPoint) {
+	h.polls++
+	p.cpuMin, p.cpuMax = p.cpu, p.cpu
+	for n := range h.levels {
+		h.pending[n].add(p)
+		if h.polls%(1<<n) != 0 {
+			continue
+		}
+		point := h.pending[n].apply(p)
+		h.pending[n] = historyRange{}
+
+		if level := h.levels[n]; len(level) == historyLevelSize {
+			copy(level, level[1:])
+			level[len(level)-1] = point
+		} else {
+			h.levels[n] = append(level, point)
+		}
+	}
+}
+
+// at returns the point recorded age polls ago, or the nearest one in the
+// finest level that reaches back that far. ok is false when the history
+// does not reach back that far.
+func (h *graphHistory) at(age int) (p historyPoint, ok bool) {
+	target := h.polls - age
+	if target < 1 {
+		return historyPoint{}, false
+	}
+	for n, level := range h.levels {
+		if len(level) == 0 {
+			break
+		}
+		step := 1 << n
+		newest := h.polls / step * step
+		oldest := newest - (len(level)-1)*step
+		if target < oldest {
+			continue
+		}
+		back := (newest - target + step/2) / step
+		if back < 0 {
+			back = 0
+		}
+		return level[len(level)-1-back], true
+	}
+	return historyPoint{}, false
+}
+
+// span summarizes the polls between newer and older polls ago, including
+// newer, from the finest level that reaches back that far: the latest of
+// its points in the span with the CPU and latency aggregates of all of
+// them. A span narrower than the level's points gets the nearest point.
+func (h *graphHistory) span(newer, older int) (p historyPoint, ok bool) {
+	first, last := h.polls-older+1, h.polls-newer // Poll numbers covered
+	if last < 1 {
+		return historyPoint{}, false
+	}
+	if first < 1 {
+		first = 1
+	}
+	for n, level := range h.levels {
+		if len(level) == 0 {
+			break
+		}
+		step := 1 << n
+		newest := h.polls / step * step
+		if first <= newest-len(level)*step {
+			continue
+		}
+		var r historyRange
+		for k := len(level) - 1; k >= 0; k-- {
+			at := newest - (len(level)-1-k)*step
+			if at < first {
+				break
+			}
+			if at <= last {
+				if r.n == 0 {
+					p = level[k]
+				}
+				r.add(level[k])
+			}
+		}
+		if r.n == 0 {
+			return h.at(newer)
+		}
+		return r.apply(p), true
+	}
+	return h.at(newer)
+}
+
+// columns fills buf with the history over window, one column per element
+// and the latest poll in the last. Columns before the first recorded poll
+// get a zero point; the index of the first column with data is returned.
+func (h *graphHistory) columns(buf []historyPoint, window time.Duration) int {
+	return h.columnsBefore(buf, window, 0)
+}
+
+// columnsBefore is columns with the latest skip polls left out, so the
+// last column is the poll skip polls ago.
+func (h *graphHistory) columnsBefore(buf []historyPoint, window time.Duration, skip int) int {
+	perColumn := float64(window/h.interval) / float64(len(buf))
+	first := len(buf)
+	for i := len(buf) - 1; i >= 0; i-- {
+		newer := skip + int(float64(len(buf)-1-i)*perColumn+0.5)
+		older := skip + int(float64(len(buf)-i)*perColumn+0.5)
+		if older <= newer {
+			older = newer + 1
+		}
+		p, ok := h.span(newer, older)
+		buf[i] = p
+		if ok {
+			first = i
+		}
+	}
+	return first
+}
+
+// clearSensors drops the secondary sensor readings from every point.
+func (h *graphHistory) clearSensors() {
+	for _, level := range h.levels {
+		for i := range level {
+			level[i].sensors = nil
+		}
+	}
+}
+
+// zoomWindow halves (in) or doubles a graph window, within minWindow and
+// maxWindow.
+func zoomWindow(window time.Duration, in bool) time.Duration {
+	if in {
+		window /= 2
+	} else {
+		window *= 2
+	}
+	if window < minWindow {
+		return minWindow
+	}
+	if window > maxWindow {
+		return maxWindow
+	}
+	return window
+}
+
+// zoomHint returns the line under a graph telling the keys that zoom it.
+func (m *Monitor) zoomHint() string {
+	keys := m.cfg.KeyBindings
+	return fmt.Sprintf(tr("Press %s to zoom in, %s to zoom out"), keys.label(actionZoomIn), keys.label(actionZoomOut))
+}
+
+// formatWindow names a graph window in seconds, minutes or hours, with a
+// decimal where zooming from an odd start leaves a fraction, e.g. "15s",
+// "2.5min" or "1.1h".
+func formatWindow(window time.Duration) string {
+	value, unit := window.Seconds(), "s"
+	switch {
+	case window >= time.Hour:
+		value, unit = window.Hours(), "h"
+	case window >= time.Minute:
+		value, unit = window.Minutes(), "min"
+	}
+	prec := 1
+	if value == float64(int(value)) {
+		prec = 0
+	}
+	return formatNumber(value, prec) + unit
+}
+
+// refreshGraph resamples the display buffer for the current window.
+func (m *Monitor) refreshGraph() {
+	m.graphStart = m.history.columnsBefore(m.displayBuffer, m.window, m.graphSkip())
+}
+
+// cpuEnvelope returns the range of CPU usage in each display column as
+// fractions of axis, for plotEnvelope.
+func (m *Monitor) cpuEnvelope(axis yAxis) (lows, highs []float64) {
+	lows, highs = make([]float64, len(m.displayBuffer)), make([]float64, len(m.displayBuffer))
+	for i, p := range m.displayBuffer {
+		lows[i], highs[i] = axis.fraction(p.cpuMin), axis.fraction(p.cpuMax)
+	}
+	return lows, highs
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestGraphHistory checks that every window takes its columns from the
+// right polls, and that downsampled columns keep the range of CPU usage
+// and the worst latency they cover.
+func TestGraphHistory(t *testing.T) {
+	h := newGraphHistory(defaultPollInterval)
+	const polls = 100000
+	for i := 1; i <= polls; i++ {
+		h.add(historyPoint{cpu: float64(i), latencyAvg: 1, latencyMax: float64(i % 1000)})
+	}
+	buf := make([]historyPoint, 60)
+	for window := minWindow; window <= maxWindow; window = zoomWindow(window, false) {
+		if first := h.columns(buf, window); first != 0 && window <= 12*time.Hour {
+			t.Errorf("%v: first column %d, want 0", window, first)
+		}
+		perColumn := float64(window/defaultPollInterval) / float64(len(buf))
+		for col := len(buf) - 1; col >= 0 && buf[col].cpu > 0; col-- {
+			p := buf[col]
+			want := float64(polls) - (float64(len(buf)-1-col)+0.5)*perColumn
+			if diff := math.Abs(p.cpu - want); diff > perColumn+1 {
+				t.Errorf("%v column %d: poll %.0f, want about %.0f", window, col, p.cpu, want)
+			}
+			if p.cpuMin > p.cpu || p.cpuMax < p.cpu || p.cpuMax-p.cpuMin < perColumn/2-1 {
+				t.Errorf("%v column %d: range %.0f-%.0f around %.0f, want at least %.0f wide", window, col, p.cpuMin, p.cpuMax, p.cpu, perColumn/2-1)
+			}
+		}
+		if window == maxWindow {
+			break
+		}
+	}
+	if p, _ := h.at(5000); p.latencyMax < 900 || p.latencyAvg != 1 {
+		t.Errorf("coarse point latency = %.0f max, %.1f avg; want the worst of its polls", p.latencyMax, p.latencyAvg)
+	}
+}
+
+// TestGraphHistorySpan checks that the history reaches back the longest
+// window at every poll interval, not only at the default one.
+func TestGraphHistorySpan(t *testing.T) {
+	for _, interval := range []time.Duration{100 * time.Millisecond, defaultPollInterval, 5 * time.Second} {
+		h := newGraphHistory(interval)
+		polls := int(maxWindow / interval)
+		for i := 1; i <= polls; i++ {
+			h.add(historyPoint{cpu: float64(i)})
+		}
+		buf := make([]historyPoint, 60)
+		if first := h.columns(buf, maxWindow); first != 0 || buf[0].cpu > float64(polls)/60 {
+			t.Errorf("%v polls: %v window starts at column %d with poll %.0f; want column 0 near the first poll",
+				interval, maxWindow, first, buf[0].cpu)
+		}
+	}
+}
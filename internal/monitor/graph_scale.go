@@ -0,0 +1,123 @@
+package monitor
+
+import "math"
+
+// graphScale selects how the y axis of a history graph is fitted to the
+// data.
+type graphScale int
+
+const (
+	scaleAuto  graphScale = iota // From 0 to the peak in the window, rounded up
+	scaleFixed                   // The full range of the metric, 0-100% for shares
+	scaleLog                     // Logarithmic over the decades the window spans
+)
+
+// graphScaleNames maps config names to graph scales.
+var graphScaleNames = map[string]graphScale{
+	"auto":  scaleAuto,
+	"fixed": scaleFixed,
+	"log":   scaleLog,
+}
+
+// logDecades bounds how many decades a log axis spans, so near-zero
+// readings do not squeeze the rest of the graph into the top rows.
+const logDecades = 4
+
+// yAxis maps values onto the height of a graph.
+type yAxis struct {
+	lo, hi float64
+	log    bool
+}
+
+// newYAxis fits an axis to values, ignoring the negative ones that mark
+// missing data. full is the top of a fixed axis, and step what an auto
+// axis is rounded up to; an axis without a full range falls back to auto.
+// A log axis runs from the decade of the smallest positive value to the
+// decade above the peak, over at least one and at most logDecades decades.
+func newYAxis(scale graphScale, values []float64, full, step float64) yAxis {
+	peak, least := 0.0, math.Inf(1)
+	for _, v := range values {
+		peak = math.Max(peak, v)
+		if v > 0 {
+			least = math.Min(least, v)
+		}
+	}
+	switch {
+	case scale == scaleFixed && full > 0:
+		return yAxis{lo: 0, hi: full}
+	case scale == scaleLog:
+		if peak <= 0 {
+			peak, least = step, step
+		}
+		hi := math.Pow(10, math.Ceil(math.Log10(peak)))
+		lo := math.Pow(10, math.Floor(math.Log10(least)))
+		lo = math.Min(math.Max(lo, hi/math.Pow(10, logDecades)), hi/10)
+		return yAxis{lo: lo, hi: hi, log: true}
+	}
+	return yAxis{lo: 0, hi: math.Max(math.Ceil(peak/step)*step, step)}
+}
+
+// fraction returns where v falls between the bottom (0) and top (1) of
+// the axis, or -1 for a missing value. Values outside the axis are clamped.
+func (a yAxis) fraction(v float64) float64 {
+	if v < 0 {
+		return -1
+	}
+	var f float64
+	if a.log {
+		if v <= a.lo {
+			return 0
+		}
+		f = math.Log10(v/a.lo) / math.Log10(a.hi/a.lo)
+	} else {
+		f = (v - a.lo) / (a.hi - a.lo)
+	}
+	return math.Max(0, math.Min(1, f))
+}
+
+// value is the inverse of fraction: the value at a fraction of the
+// height.
+func (a yAxis) value(f float64) float64 {
+	if a.log {
+		return a.lo * math.Pow(a.hi/a.lo, f)
+	}
+	return a.lo + (a.hi-a.lo)*f
+}
+
+// top returns the value at the top of a row, counting rows from 0 at the
+// bottom.
+func (a yAxis) top(row, rows int) float64 {
+	return a.value(float64(row+1) / float64(rows))
+}
+
+// axisPrecision returns the decimals an axis label needs: one for
+// fractional values below 10, as a log axis has, otherwise none.
+func axisPrecision(v float64) int {
+	if v < 10 && math.Abs(v-math.Round(v)) >= 0.05 {
+		return 1
+	}
+	return 0
+}
+
+// cpuAxis fits the axis of CPU usage in the combined and dual-axis graphs.
+func (m *Monitor) cpuAxis() yAxis {
+	values := make([]float64, len(m.displayBuffer))
+	for i, p := range m.displayBuffer {
+		values[i] = p.cpu
+	}
+	return newYAxis(m.cfg.CPUGraphScale, values, 100, 5) // Auto rounds up to whole percents per row
+}
+
+// cpuAxisLabels returns the 7-column labels of the five CPU rows, top row
+// first: the bands of a fixed axis, otherwise the top of each row.
+func cpuAxisLabels(axis yAxis) []string {
+	if !axis.log && axis.lo == 0 && axis.hi == 100 {
+		return []string{"81-100%", "61-80% ", "41-60% ", "21-40% ", "0-20%  "}
+	}
+	labels := make([]string, 5)
+	for row := range labels {
+		top := axis.top(4-row, 5)
+		labels[row] = padRight(formatPercent(top, axisPrecision(top)), 7)
+	}
+	return labels
+}
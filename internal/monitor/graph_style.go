@@ -0,0 +1,211 @@
+package monitor
+
+import "math"
+
+// graphStyle selects how a series is drawn in the history graph.
+type graphStyle int
+
+const (
+	styleBlocks graphStyle = iota // One eighth-block glyph at the value's height (the classic look)
+	styleFilled                   // Solid bars from the bottom up to the value, eighth-block tops
+	styleLine                     // Braille line with four dots of resolution per row
+	styleStep                     // Box-drawing step outline
+	stylePoints                   // A single ° glyph per column
+)
+
+// graphStyleNames maps config names to graph styles.
+var graphStyleNames = map[string]graphStyle{
+	"blocks": styleBlocks,
+	"filled": styleFilled,
+	"line":   styleLine,
+	"step":   styleStep,
+	"points": stylePoints,
+}
+
+// graphCell is one character of a plotted series. An empty glyph means
+// the series does not occupy the cell.
+type graphCell struct {
+	glyph string
+	color string // Foreground escape sequence
+}
+
+// plotSeries renders a series into a rows x len(values) grid of cells,
+// with row 0 at the bottom. Values are fractions of the graph height
+// (0..1); negative values mark missing data and leave the column empty.
+func plotSeries(values []float64, colors []string, rows int, style graphStyle) [][]graphCell {
+	grid := make([][]graphCell, rows)
+	for r := range grid {
+		grid[r] = make([]graphCell, len(values))
+	}
+
+	switch style {
+	case styleLine:
+		plotBraille(grid, values, colors)
+		return grid
+	case styleStep:
+		plotStep(grid, values, colors)
+		return grid
+	}
+
+	for i, v := range values {
+		if v < 0 {
+			continue
+		}
+		if style == stylePoints {
+			grid[valueRow(v, rows)][i] = graphCell{"°", colors[i]}
+			continue
+		}
+
+		// Blocks and bars end in an eighth-block character, giving eight
+		// levels of vertical resolution per row
+		top, eighths := valueLevel(v, rows)
+		grid[top][i] = graphCell{barChars[eighths], colors[i]}
+		if style == styleFilled {
+			for r := 0; r < top; r++ {
+				grid[r][i] = graphCell{"█", colors[i]}
+			}
+		}
+	}
+	return grid
+}
+
+// plotEnvelope shades the empty cells between lows and highs of each
+// column with ░ in the column's color, so the range of a column that
+// summarizes many polls shows around its average and brief spikes are not
+// averaged away. Values are fractions as for plotSeries.
+func plotEnvelope(grid [][]graphCell, lows, highs []float64, colors []string) {
+	rows := len(grid)
+	for i := range lows {
+		if lows[i] < 0 || highs[i] <= lows[i] {
+			continue
+		}
+		for r := valueRow(lows[i], rows); r <= valueRow(highs[i], rows); r++ {
+			if grid[r][i].glyph == "" {
+				grid[r][i] = graphCell{"░", colors[i]}
+			}
+		}
+	}
+}
+
+// valueLevel returns the row holding the top of v and how many eighths of
+// that row it fills (1-8). Values of zero still show a one-eighth sliver.
+func valueLevel(v float64, rows int) (row, eighths int) {
+	total := int(math.Round(v * float64(rows*8)))
+	if total < 1 {
+		total = 1
+	}
+	if total > rows*8 {
+		total = rows * 8
+	}
+	row = (total - 1) / 8
+	return row, total - row*8
+}
+
+// valueRow returns the row whose band contains v, so that with five rows
+// 0-20% lands in row 0 and 80-100% in row 4.
+func valueRow(v float64, rows int) int {
+	r := int(math.Ceil(v*float64(rows))) - 1
+	if r < 0 {
+		r = 0
+	}
+	if r >= rows {
+		r = rows - 1
+	}
+	return r
+}
+
+// plotBraille draws a continuous line using braille dots: two dot columns
+// and four dot rows per cell. The left dot column holds the sample itself,
+// the right one the midpoint to the next sample, and vertical runs are
+// filled in so steep changes stay connected.
+func plotBraille(grid [][]graphCell, values []float64, colors []string) {
+	rows := len(grid)
+	dotRows := rows * 4
+	leftBits := [4]rune{0x40, 0x04, 0x02, 0x01}  // Dots 7, 3, 2, 1 from the bottom
+	rightBits := [4]rune{0x80, 0x20, 0x10, 0x08} // Dots 8, 6, 5, 4 from the bottom
+
+	bits := make([][]rune, rows)
+	for r := range bits {
+		bits[r] = make([]rune, len(values))
+	}
+	dotY := func(v float64) int {
+		y := int(math.Round(v * float64(dotRows-1)))
+		if y < 0 {
+			y = 0
+		}
+		if y >= dotRows {
+			y = dotRows - 1
+		}
+		return y
+	}
+	set := func(col, from, to int, mask [4]rune) {
+		if from > to {
+			from, to = to, from
+		}
+		for y := from; y <= to; y++ {
+			bits[y/4][col] |= mask[y%4]
+		}
+	}
+
+	prev := -1
+	for i, v := range values {
+		if v < 0 {
+			prev = -1
+			continue
+		}
+		y0 := dotY(v)
+		if prev < 0 {
+			prev = y0
+		}
+		set(i, prev, y0, leftBits)
+
+		y1 := y0
+		if i+1 < len(values) && values[i+1] >= 0 {
+			y1 = dotY((v + values[i+1]) / 2)
+		}
+		set(i, y0, y1, rightBits)
+		prev = y1
+	}
+
+	for r := range bits {
+		for i, b := range bits[r] {
+			if b != 0 {
+				grid[r][i] = graphCell{string(0x2800 + b), colors[i]}
+			}
+		}
+	}
+}
+
+// plotStep draws the series as a step outline with box-drawing characters:
+// horizontal runs at each value's row joined by vertical risers.
+func plotStep(grid [][]graphCell, values []float64, colors []string) {
+	rows := len(grid)
+	prev := -1
+	for i, v := range values {
+		if v < 0 {
+			prev = -1
+			continue
+		}
+		row := valueRow(v, rows)
+		color := colors[i]
+		switch {
+		case prev < 0 || prev == row:
+			grid[row][i] = graphCell{"─", color}
+		case row > prev:
+			// Rising: leave the old level, climb, and turn right at the new one
+			grid[prev][i] = graphCell{"┘", color}
+			for r := prev + 1; r < row; r++ {
+				grid[r][i] = graphCell{"│", color}
+			}
+			grid[row][i] = graphCell{"┌", color}
+		default:
+			// Falling: turn down from the old level and out at the new one
+			grid[prev][i] = graphCell{"┐", color}
+			for r := row + 1; r < prev; r++ {
+				grid[r][i] = graphCell{"│", color}
+			}
+			grid[row][i] = graphCell{"└", color}
+		}
+		prev = row
+	}
+}
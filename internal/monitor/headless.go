@@ -0,0 +1,171 @@
+package monitor
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jeremycharlesgillespie/kode_kronical_perf_monitor/internal/version"
+)
+
+// runHeadless samples at the polling interval and feeds the configured
+// sinks without touching the terminal, until SIGINT or SIGTERM.
+func (m *Monitor) runHeadless() {
+	m.headless = true
+	defer m.closeSinks()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	pollTicker := time.NewTicker(m.cfg.PollInterval)
+	defer pollTicker.Stop()
+
+	for {
+		select {
+		case <-sigChan:
+			return
+		case <-pollTicker.C:
+			sample := m.pollSample()
+			if sample.Temp > 0 {
+				m.updateMinMax(sample.Temp)
+			}
+			m.writeSinks(&sample)
+		case <-m.resets:
+			m.resetSession()
+		}
+	}
+}
+
+// AgentMain is the entry point of kkperf-agent, the headless collector. It
+// feeds the exporters enabled in the config or on the command line and
+// also serves the check, snmp and helper subcommands.
+func AgentMain() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		case "snmp":
+			os.Exit(runSNMP(os.Args[2:]))
+		case "helper":
+			os.Exit(runHelper(os.Args[2:]))
+		}
+	}
+
+	showVersion, debug, jsonOut := false, false, false
+	path := configPath()
+	textfile, listen, web, socket, fifo, telegraf := "", "", "", "", "", ""
+	fs := flag.NewFlagSet("kkperf-agent", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&showVersion, "v", false, "")
+	fs.BoolVar(&showVersion, "version", false, "")
+	fs.StringVar(&path, "c", path, "")
+	fs.StringVar(&path, "config", path, "")
+	fs.StringVar(&textfile, "textfile", "", "")
+	fs.StringVar(&listen, "listen", "", "")
+	fs.StringVar(&web, "web", "", "")
+	fs.StringVar(&socket, "socket", "", "")
+	fs.StringVar(&fifo, "fifo", "", "")
+	fs.StringVar(&telegraf, "telegraf", "", "")
+	fs.BoolVar(&debug, "debug", false, "")
+	fs.BoolVar(&jsonOut, "json", false, "")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Println(agentUsage)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n%s\n", err, agentUsage)
+		os.Exit(1)
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unknown argument: %s\n\n%s\n", fs.Arg(0), agentUsage)
+		os.Exit(1)
+	}
+	if showVersion {
+		version.Print("Kode Kronical Perf Agent")
+		return
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	if textfile != "" {
+		cfg.Prometheus.Textfile = textfile
+	}
+	if listen != "" {
+		cfg.HTTP.Listen = listen
+	}
+	if web != "" {
+		cfg.Web.Listen = web
+	}
+	if socket != "" {
+		cfg.Socket.Path = socket
+	}
+	if fifo != "" {
+		cfg.FIFO.Path = fifo
+	}
+	activeLocale = resolveLocale(cfg)
+	if err := cfg.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if debug {
+		if err := openDebugLog(cfg.Debug.File, cfg.Debug.Level); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer closeDebugLog()
+		logInfo("kkperf-agent started", "version", version.Version, "config", path)
+	}
+
+	m := NewMonitor(cfg)
+	if err := m.openSinks(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if jsonOut {
+		if telegraf != "" {
+			fmt.Fprintln(os.Stderr, "Error: --json and --telegraf both write to stdout")
+			os.Exit(1)
+		}
+		m.sinks = append(m.sinks, newJSONSink(os.Stdout))
+	}
+	if telegraf != "" {
+		if err := m.runTelegraf(telegraf); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(m.sinks) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no exporter is enabled; pass --json, --textfile, --listen, --web, --socket or --fifo, or configure one in the config file")
+		os.Exit(1)
+	}
+	m.runHeadless()
+}
+
+// agentUsage documents the kkperf-agent command.
+const agentUsage = `Usage: kkperf-agent [options]
+       kkperf-agent check [options]   (Nagios/Icinga plugin; see check --help)
+       kkperf-agent snmp [options]    (net-snmp pass_persist handler; see snmp --help)
+       kkperf-agent helper [options]  (run as root to read root-only sensors for users; see helper --help)
+
+Collect samples without the TUI and feed the exporters enabled in the
+config file ([prometheus], [http], [web], [socket], [fifo], [zabbix], [mqtt], [history], [report]).
+
+Options:
+  --textfile PATH   Write Prometheus metrics to PATH (node_exporter textfile collector)
+  --listen ADDR     Serve /metrics, /debug/pprof/ and /debug/vars on ADDR
+  --web ADDR        Serve a live dashboard for browsers on ADDR
+  --socket PATH     Answer GET and SUBSCRIBE requests on a UNIX socket at PATH
+  --fifo PATH       Write every poll as one line to a FIFO at PATH
+  --json            Write every poll to stdout as one line of JSON
+  --telegraf MODE   Act as a Telegraf input: "exec" (one sample) or "execd" (long-running)
+  --debug           Log sensor discovery, parse errors and exporter failures to the [debug] file
+  -c, --config PATH Use an alternate config file
+  -v, --version     Show version information`
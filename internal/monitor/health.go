@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// healthReading counts the kernel resources that run out quietly: zombie
+// processes, threads and open files.
+type healthReading struct {
+	zombies            int
+	threads, threadMax int    // threadMax is 0 when unknown
+	fds, fdMax         uint64 // fdMax is 0 when unknown
+}
+
+// readHealth scans the process table for zombies and threads and reads
+// the system-wide limits. Every thread takes a PID, so the thread limit is
+// the lower of kernel.threads-max and kernel.pid_max.
+func readHealth() healthReading {
+	var h healthReading
+	entries, _ := ioutil.ReadDir(procDir)
+	for _, e := range entries {
+		if _, err := strconv.Atoi(e.Name()); err != nil {
+			continue
+		}
+		stat := parseProcStat(readSysfsString(filepath.Join(procDir, e.Name(), "stat")))
+		if len(stat) < 18 {
+			continue
+		}
+		if stat[0] == "Z" {
+			h.zombies++
+		}
+		threads, _ := strconv.Atoi(stat[17])
+		h.threads += threads
+	}
+
+	for _, name := range []string{"threads-max", "pid_max"} {
+		if v, err := strconv.Atoi(readSysfsString(filepath.Join(procDir, "sys", "kernel", name))); err == nil && v > 0 {
+			if h.threadMax == 0 || v < h.threadMax {
+				h.threadMax = v
+			}
+		}
+	}
+
+	// file-nr holds allocated handles, unused allocated handles (always 0
+	// since 2.6) and the maximum
+	if fields := strings.Fields(readSysfsString(filepath.Join(procDir, "sys", "fs", "file-nr"))); len(fields) == 3 {
+		allocated, _ := strconv.ParseUint(fields[0], 10, 64)
+		unused, _ := strconv.ParseUint(fields[1], 10, 64)
+		if unused <= allocated {
+			h.fds = allocated - unused
+		}
+		h.fdMax, _ = strconv.ParseUint(fields[2], 10, 64)
+	}
+	return h
+}
+
+// healthPercent returns used as a share of limit, or 0 when the limit is
+// unknown.
+func healthPercent(used, limit float64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return used / limit * 100
+}
+
+// healthWarning checks a reading against the [health] limits and
+// describes every resource over its limit, or returns "" when all are
+// fine. translate localizes the message for the screen; exporters pass
+// untranslated to keep it in English.
+func (cfg *Config) healthWarning(h healthReading, translate func(string) string) string {
+	c := cfg.Health
+	var problems []string
+	if c.MaxZombies > 0 && h.zombies >= c.MaxZombies {
+		problems = append(problems, fmt.Sprintf(translate("zombie processes: %d"), h.zombies))
+	}
+	if p := healthPercent(float64(h.threads), float64(h.threadMax)); c.MaxThreads > 0 && p >= c.MaxThreads {
+		problems = append(problems, fmt.Sprintf(translate("threads at %.0f%% of the limit"), p))
+	}
+	if p := healthPercent(float64(h.fds), float64(h.fdMax)); c.MaxFDs > 0 && p >= c.MaxFDs {
+		problems = append(problems, fmt.Sprintf(translate("open files at %.0f%% of the limit"), p))
+	}
+	return strings.Join(problems, ", ")
+}
+
+// displayHealth prints the zombie, thread and open file counts on one
+// line, with the threads and files as shares of their limits. Counts over
+// a [health] limit are shown in red, with the warning on a line below.
+func (m *Monitor) displayHealth() {
+	h := m.health
+	c := m.cfg.Health
+	color := func(over bool) string {
+		if over {
+			return colorRed
+		}
+		return colorReset
+	}
+	usage := func(used, limit, max float64) string {
+		p := healthPercent(used, limit)
+		text := strconv.FormatFloat(used, 'f', 0, 64)
+		if limit > 0 {
+			text += " (" + formatPercent(p, 1) + ")"
+		}
+		return color(max > 0 && p >= max) + text + colorReset
+	}
+	fmt.Fprintf(m.out, "%s%s%s %s %s%d%s  %s %s  %s %s\r\n", colorBlue, tr("Health:"), colorReset,
+		tr("Zombies"), color(c.MaxZombies > 0 && h.zombies >= c.MaxZombies), h.zombies, colorReset,
+		tr("Threads"), usage(float64(h.threads), float64(h.threadMax), c.MaxThreads),
+		tr("Open files"), usage(float64(h.fds), float64(h.fdMax), c.MaxFDs))
+	if warning := m.cfg.healthWarning(h, tr); warning != "" {
+		fmt.Fprintf(m.out, "%s%s%s\r\n", colorRed, warning, colorReset)
+	}
+	fmt.Fprint(m.out, "\r\n")
+}
@@ -0,0 +1,148 @@
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	heatmapAutoCores = 64 // More cores than this select the heatmap when core_view is not set
+	heatmapChunk     = 16 // Cores per group when the cache topology is unknown
+	heatmapWidth     = 78 // Columns available for group blocks
+	heatmapPageRows  = 8  // Lines of groups per page
+)
+
+// coreGroup is a set of cores sharing a last-level cache (a CCX on AMD,
+// a socket or tile on Intel). The heatmap draws each group as one block.
+type coreGroup struct {
+	name  string
+	cores []int
+}
+
+// l3Domains returns the L3 cache of each core, numbered in order of the
+// first core sharing it, or nil without cache topology.
+func l3Domains(cores int) []int {
+	domains := make([]int, cores)
+	index := map[string]int{} // shared_cpu_list to cache
+	for cpu := 0; cpu < cores; cpu++ {
+		list := readSysfsString(filepath.Join(cpuDir, fmt.Sprintf("cpu%d", cpu), "cache", "index3", "shared_cpu_list"))
+		if list == "" {
+			return nil
+		}
+		i, ok := index[list]
+		if !ok {
+			i = len(index)
+			index[list] = i
+		}
+		domains[cpu] = i
+	}
+	return domains
+}
+
+// discoverCoreGroups groups cores by the L3 cache they share. Without
+// cache topology, or when all cores share one cache, cores are grouped in
+// runs of heatmapChunk instead so the rows stay readable.
+func discoverCoreGroups(cores int) []coreGroup {
+	var groups []coreGroup
+	for cpu, i := range l3Domains(cores) {
+		if i == len(groups) {
+			groups = append(groups, coreGroup{name: fmt.Sprintf("L3 %d", i)})
+		}
+		groups[i].cores = append(groups[i].cores, cpu)
+	}
+	if len(groups) > 1 {
+		return groups
+	}
+	return chunkCoreGroups(cores)
+}
+
+// chunkCoreGroups groups the cores in runs of heatmapChunk.
+func chunkCoreGroups(cores int) []coreGroup {
+	var groups []coreGroup
+	for first := 0; first < cores; first += heatmapChunk {
+		last := first + heatmapChunk - 1
+		if last >= cores {
+			last = cores - 1
+		}
+		g := coreGroup{name: fmt.Sprintf("%d-%d", first, last)}
+		for cpu := first; cpu <= last; cpu++ {
+			g.cores = append(g.cores, cpu)
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// displayHeatmap draws one cell per core, grouped by shared cache, with
+// the group's average usage after its cells. Cell height follows usage, or
+// the clock with the frequency bars on, and color follows the estimated
+// core temperature, as in the grid. Groups
+// fill lines left to right; lines beyond heatmapPageRows go to further
+// pages, selected with [ and ].
+func (m *Monitor) displayHeatmap(coreUsages []float64, currentTemp float64) {
+	groups := m.coreGroups
+	temps := m.coreTemperatures(coreUsages, currentTemp)
+	heights := m.coreBarHeights(coreUsages)
+	labelWidth, cellWidth := 0, 0
+	for _, g := range groups {
+		if n := utf8.RuneCountInString(g.name); n > labelWidth {
+			labelWidth = n
+		}
+		if len(g.cores) > cellWidth {
+			cellWidth = len(g.cores)
+		}
+	}
+	block := labelWidth + 1 + cellWidth + 5 // Label, cells, " 100%"
+	perLine := (heatmapWidth + 2) / (block + 2)
+	if perLine < 1 {
+		perLine = 1
+	}
+	lines := (len(groups) + perLine - 1) / perLine
+	pages := (lines + heatmapPageRows - 1) / heatmapPageRows
+	if m.heatmapPage >= pages {
+		m.heatmapPage = pages - 1
+	}
+	if m.heatmapPage < 0 {
+		m.heatmapPage = 0
+	}
+
+	first := m.heatmapPage * heatmapPageRows
+	for line := first; line < lines && line < first+heatmapPageRows; line++ {
+		fmt.Fprint(m.out, "  ")
+		for i := line * perLine; i < len(groups) && i < (line+1)*perLine; i++ {
+			g := groups[i]
+			if i > line*perLine {
+				fmt.Fprint(m.out, "  ")
+			}
+			fmt.Fprintf(m.out, "%s%s%s ", colorCyan, padRight(g.name, labelWidth), colorReset)
+			sum, clockSum := 0.0, 0.0
+			for _, cpu := range g.cores {
+				sum += coreUsages[cpu]
+				if m.freqBars {
+					clockSum += m.clocks.clockKHz(cpu)
+				}
+				level := int(heights[cpu] / 12.5)
+				if level > 8 {
+					level = 8
+				}
+				if level < 1 {
+					level = 1 // Idle cores stay visible
+				}
+				fmt.Fprintf(m.out, "%s%s%s", getTempColor(temps[cpu]), barChars[level], colorReset)
+			}
+			avg := sum / float64(len(g.cores))
+			color, value := getUsageColor(avg), formatPercent(avg, 0)
+			if m.freqBars {
+				// Average clock in GHz
+				color, value = colorCyan, formatNumber(clockSum/float64(len(g.cores))/1e6, 1)+"G"
+			}
+			fmt.Fprintf(m.out, "%s %s%4s%s", strings.Repeat(" ", cellWidth-len(g.cores)), color, value, colorReset)
+		}
+		fmt.Fprint(m.out, "\r\n")
+	}
+	if pages > 1 {
+		fmt.Fprintf(m.out, "  %s"+tr("Page %d/%d")+"  %s%s\r\n", colorYellow, m.heatmapPage+1, pages, tr("[ ]: page"), colorReset)
+	}
+}
@@ -0,0 +1,338 @@
+package monitor
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultHelperSocket = "/run/kkperf-helper.sock"
+	helperTimeout       = time.Second      // Bound on one request to the helper
+	helperRetry         = 10 * time.Second // Wait before dialing again after a failed dial or request
+)
+
+// helperMSRs are the registers the helper reads: the clock counters, the
+// Intel thermal registers and the AMD energy registers.
+var helperMSRs = map[int64]bool{
+	msrMPERF: true, msrAPERF: true, msrPlatformInfo: true,
+	msrThermStatus: true, msrTemperatureTarget: true, msrPackageThermStatus: true,
+	msrAMDPowerUnit: true, msrAMDCoreEnergy: true,
+}
+
+// helperFiles are patterns of the root-only files the helper reads: RAPL
+// energy counters, root-only since kernel 5.10, and the ryzen_smu power
+// management table.
+func helperFiles() []string {
+	return []string{
+		filepath.Join(raplDir, "intel-rapl:*", "energy_uj"),
+		filepath.Join(smuDir, "pm_table"),
+	}
+}
+
+// helperAllowed reports whether the helper may read path. Paths must be
+// clean, so ".." cannot leave the allowed directories.
+func helperAllowed(path string) bool {
+	if path != filepath.Clean(path) {
+		return false
+	}
+	for _, pattern := range helperFiles() {
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// privHelper is the client of the privilege helper, nil when [helper] is
+// disabled. Reads of root-only sources fall back to it when they are
+// denied.
+var privHelper *helperClient
+
+// helperClient asks a helper running as root for the readings the user
+// cannot take: "MSR <cpu> <register>", "MSRS <cpus> <register>" for a
+// register of CPUs 0 to cpus-1 at once, and "READ <path>", each answered
+// with "OK <value>" or "ERR <message>" on one line. The connection is kept
+// open between polls.
+type helperClient struct {
+	path string
+
+	mu    sync.Mutex
+	conn  net.Conn
+	in    *bufio.Reader
+	retry time.Time // No dial before this, after a failed dial or request
+}
+
+// newHelperClient returns a client of the helper at path, or nil for an
+// empty path. It does not connect until the first denied read.
+func newHelperClient(path string) *helperClient {
+	if path == "" {
+		return nil
+	}
+	return &helperClient{path: path}
+}
+
+// request sends one request and returns the value of the answer. A
+// failed dial or request is not retried for helperRetry, so a monitor
+// whose helper is missing or hung does not wait for it on every read.
+func (h *helperClient) request(line string) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn == nil {
+		if time.Now().Before(h.retry) {
+			return "", errors.New("helper not available")
+		}
+		conn, err := net.DialTimeout("unix", h.path, helperTimeout)
+		if err != nil {
+			logWarn("privilege helper not reachable", "socket", h.path, "err", err)
+			h.retry = time.Now().Add(helperRetry)
+			return "", err
+		}
+		h.conn, h.in = conn, bufio.NewReader(conn)
+	}
+	h.conn.SetDeadline(time.Now().Add(helperTimeout))
+	var answer string
+	_, err := fmt.Fprintf(h.conn, "%s\n", line)
+	if err == nil {
+		answer, err = h.in.ReadString('\n')
+	}
+	if err != nil {
+		logWarn("privilege helper request failed", "socket", h.path, "err", err)
+		h.conn.Close()
+		h.conn = nil
+		h.retry = time.Now().Add(helperRetry)
+		return "", err
+	}
+	answer = strings.TrimSpace(answer)
+	if strings.HasPrefix(answer, "OK ") {
+		return strings.TrimPrefix(answer, "OK "), nil
+	}
+	return "", errors.New(strings.TrimPrefix(answer, "ERR "))
+}
+
+// readMSR reads a register through the helper.
+func (h *helperClient) readMSR(cpu int, reg int64) (uint64, bool) {
+	value, err := h.request(fmt.Sprintf("MSR %d %#x", cpu, reg))
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(value, 10, 64)
+	return v, err == nil
+}
+
+// readMSRs reads a register of CPUs 0 to n-1 through the helper, in one
+// request rather than one per CPU. ok reports per CPU whether it was read.
+func (h *helperClient) readMSRs(n int, reg int64) (values []uint64, ok []bool) {
+	values, ok = make([]uint64, n), make([]bool, n)
+	answer, err := h.request(fmt.Sprintf("MSRS %d %#x", n, reg))
+	if err != nil {
+		return values, ok
+	}
+	for i, field := range strings.Fields(answer) {
+		if i < n {
+			values[i], err = strconv.ParseUint(field, 10, 64)
+			ok[i] = err == nil
+		}
+	}
+	return values, ok
+}
+
+// readFile reads a file through the helper.
+func (h *helperClient) readFile(path string) ([]byte, error) {
+	value, err := h.request("READ " + path)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
+
+// readPrivileged reads a root-only file, through the helper when reading
+// it directly is denied.
+func readPrivileged(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsPermission(err) && privHelper != nil {
+		data, err = privHelper.readFile(path)
+		if err != nil {
+			logDebug("root-only file not read through the helper", "path", path, "err", err)
+		}
+	}
+	return data, err
+}
+
+// readPrivilegedString reads a root-only sysfs value like
+// readSysfsString.
+func readPrivilegedString(path string) string {
+	data, err := readPrivileged(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// runHelper implements "kkperf helper": a small server, run as root, that
+// reads the allowed MSRs and files for unprivileged monitors. It listens
+// on the socket systemd passes it or creates one that the given group can
+// use.
+func runHelper(args []string) int {
+	path, group := defaultHelperSocket, "kkperf"
+	fs := flag.NewFlagSet("helper", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&path, "socket", path, "")
+	fs.StringVar(&group, "group", group, "")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Println(helperUsage)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if runtime.GOOS != "linux" {
+		fmt.Fprintln(os.Stderr, "Error: the helper only runs on Linux")
+		return 1
+	}
+	if os.Geteuid() != 0 {
+		fmt.Fprintln(os.Stderr, "Error: the helper must run as root")
+		return 1
+	}
+
+	ln, created, err := helperListener(path, group)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if created {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			ln.Close()
+		}()
+		defer os.Remove(path)
+	}
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return 0
+		}
+		go serveHelper(conn)
+	}
+}
+
+// helperListener returns the socket systemd passed in (LISTEN_FDS), or
+// creates one at path owned by root and group with mode 0660. created
+// reports whether the socket is the helper's to remove. The umask makes
+// the socket 0660 from the start, so until it is handed to the group
+// only root can connect.
+func helperListener(path, group string) (ln net.Listener, created bool, err error) {
+	if os.Getenv("LISTEN_PID") == strconv.Itoa(os.Getpid()) && os.Getenv("LISTEN_FDS") == "1" {
+		ln, err := net.FileListener(os.NewFile(3, "systemd"))
+		return ln, false, err
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return nil, false, fmt.Errorf("helper: group %s: %v (create it with groupadd --system %s)", group, err, group)
+	}
+	gid, _ := strconv.Atoi(g.Gid)
+	os.Remove(path)
+	mask := setUmask(0117)
+	ln, err = net.Listen("unix", path)
+	setUmask(mask)
+	if err != nil {
+		return nil, false, fmt.Errorf("helper: %v", err)
+	}
+	if err := os.Chown(path, 0, gid); err != nil {
+		ln.Close()
+		return nil, false, fmt.Errorf("helper: %v", err)
+	}
+	return ln, true, nil
+}
+
+// serveHelper answers the requests of one client until it disconnects.
+func serveHelper(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		value, err := helperAnswer(strings.Fields(scanner.Text()))
+		if err != nil {
+			fmt.Fprintf(conn, "ERR %v\n", err)
+		} else {
+			fmt.Fprintf(conn, "OK %s\n", value)
+		}
+	}
+}
+
+// helperAnswer reads what a request asks for, if it is allowed.
+func helperAnswer(fields []string) (string, error) {
+	switch {
+	case len(fields) == 3 && fields[0] == "MSR":
+		cpu, err := strconv.Atoi(fields[1])
+		if err != nil || cpu < 0 {
+			return "", fmt.Errorf("invalid CPU %q", fields[1])
+		}
+		reg, err := strconv.ParseInt(fields[2], 0, 64)
+		if err != nil || !helperMSRs[reg] {
+			return "", fmt.Errorf("register %s not allowed", fields[2])
+		}
+		v, ok := readMSR(cpu, reg)
+		if !ok {
+			return "", fmt.Errorf("cannot read register %s of CPU %d", fields[2], cpu)
+		}
+		return strconv.FormatUint(v, 10), nil
+	case len(fields) == 3 && fields[0] == "MSRS":
+		cpus, err := strconv.Atoi(fields[1])
+		if err != nil || cpus < 1 || cpus > numCPU() {
+			return "", fmt.Errorf("invalid CPU count %q", fields[1])
+		}
+		reg, err := strconv.ParseInt(fields[2], 0, 64)
+		if err != nil || !helperMSRs[reg] {
+			return "", fmt.Errorf("register %s not allowed", fields[2])
+		}
+		values := make([]string, cpus)
+		for cpu := range values {
+			values[cpu] = "-" // Unreadable, e.g. offline
+			if v, ok := readMSR(cpu, reg); ok {
+				values[cpu] = strconv.FormatUint(v, 10)
+			}
+		}
+		return strings.Join(values, " "), nil
+	case len(fields) == 2 && fields[0] == "READ":
+		if !helperAllowed(fields[1]) {
+			return "", fmt.Errorf("%s not allowed", fields[1])
+		}
+		data, err := ioutil.ReadFile(fields[1])
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	return "", errors.New("usage: MSR <cpu> <register> | MSRS <cpus> <register> | READ <path>")
+}
+
+// helperUsage documents the helper subcommand.
+const helperUsage = `Usage: kkperf helper [options]
+
+Run as root to let unprivileged monitors read MSRs (effective clocks, AMD
+core power), RAPL energy counters and the ryzen_smu power table. Members
+of the group can use the socket; set [helper] socket in their config if
+it is not the default.
+
+Options:
+  --socket PATH  Socket to listen on (default /run/kkperf-helper.sock);
+                 ignored under systemd socket activation
+  --group NAME   Group allowed to use the socket (default kkperf)`
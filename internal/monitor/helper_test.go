@@ -0,0 +1,147 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHelperAnswer checks which files and registers the helper hands out
+// and that everything else is refused.
+func TestHelperAnswer(t *testing.T) {
+	dir := t.TempDir()
+	savedRAPL, savedSMU, savedMSR, savedNumCPU, savedHelper := raplDir, smuDir, msrDir, numCPU, privHelper
+	t.Cleanup(func() {
+		raplDir, smuDir, msrDir, numCPU, privHelper = savedRAPL, savedSMU, savedMSR, savedNumCPU, savedHelper
+	})
+	raplDir, smuDir, msrDir, privHelper = filepath.Join(dir, "powercap"), filepath.Join(dir, "smu"), filepath.Join(dir, "msr"), nil
+	numCPU = func() int { return 4 }
+
+	energy := filepath.Join(raplDir, "intel-rapl:0", "energy_uj")
+	os.MkdirAll(filepath.Dir(energy), 0755)
+	ioutil.WriteFile(energy, []byte("123456\n"), 0644)
+	ioutil.WriteFile(filepath.Join(raplDir, "intel-rapl:0", "name"), []byte("package-0\n"), 0644)
+	for _, tc := range []struct {
+		path string
+		ok   bool
+	}{
+		{energy, true},
+		{filepath.Join(smuDir, "pm_table"), true},
+		{filepath.Join(raplDir, "intel-rapl:0", "name"), false},
+		{raplDir + "/intel-rapl:0/../../../etc/shadow", false},
+		{"/etc/shadow", false},
+	} {
+		if got := helperAllowed(tc.path); got != tc.ok {
+			t.Errorf("helperAllowed(%s) = %v; want %v", tc.path, got, tc.ok)
+		}
+	}
+	if value, err := helperAnswer([]string{"READ", energy}); err != nil || value != "MTIzNDU2Cg==" {
+		t.Errorf("READ energy_uj = %q, %v", value, err)
+	}
+	if _, err := helperAnswer([]string{"READ", "/etc/passwd"}); err == nil {
+		t.Error("READ of a file outside the allowed ones answered")
+	}
+
+	// CPUs 0 and 1 have an MSR device; APERF reads 768 plus the CPU number
+	for cpu := 0; cpu < 2; cpu++ {
+		os.MkdirAll(filepath.Join(msrDir, strconv.Itoa(cpu)), 0755)
+		f, err := os.Create(filepath.Join(msrDir, strconv.Itoa(cpu), "msr"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteAt([]byte{byte(cpu), 0x03, 0, 0, 0, 0, 0, 0}, msrAPERF)
+		f.Close()
+	}
+	for _, tc := range []struct {
+		request string
+		want    string // "" when refused
+	}{
+		{"MSR 1 0xe8", "769"},
+		{"MSR 0 0x10", ""},  // TSC, not allowed
+		{"MSR -1 0xe8", ""}, // Invalid CPU
+		{"MSR 3 0xe8", ""},  // No device
+		{"MSRS 3 0xe8", "768 769 -"},
+		{"MSRS 5 0xe8", ""}, // More CPUs than the machine has
+		{"MSRS 2 0x10", ""},
+		{"WRITE 0 0xe8 1", ""},
+	} {
+		value, err := helperAnswer(strings.Fields(tc.request))
+		if tc.want == "" && err == nil {
+			t.Errorf("%s answered %q; want it refused", tc.request, value)
+		} else if tc.want != "" && (err != nil || value != tc.want) {
+			t.Errorf("%s = %q, %v; want %q", tc.request, value, err, tc.want)
+		}
+	}
+}
+
+// TestHelperClient checks that the client reads a register of every CPU
+// in one request, and that it stops asking a helper that failed.
+func TestHelperClient(t *testing.T) {
+	dir := t.TempDir()
+	savedMSR, savedNumCPU := msrDir, numCPU
+	t.Cleanup(func() { msrDir, numCPU = savedMSR, savedNumCPU })
+	msrDir = filepath.Join(dir, "msr")
+	numCPU = func() int { return 2 }
+	for cpu := 0; cpu < 2; cpu++ {
+		os.MkdirAll(filepath.Join(msrDir, strconv.Itoa(cpu)), 0755)
+		f, _ := os.Create(filepath.Join(msrDir, strconv.Itoa(cpu), "msr"))
+		f.WriteAt([]byte{byte(10 + cpu), 0, 0, 0, 0, 0, 0, 0}, msrMPERF)
+		f.Close()
+	}
+
+	path := filepath.Join(dir, "helper.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skip(err)
+	}
+	requests := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 256)
+				for {
+					n, err := conn.Read(buf)
+					if err != nil {
+						return
+					}
+					line := strings.TrimSpace(string(buf[:n]))
+					requests <- line
+					if line == "MSRS 2 0xe7" {
+						value, _ := helperAnswer(strings.Fields(line))
+						conn.Write([]byte("OK " + value + "\n"))
+					} // Anything else hangs until the client gives up
+				}
+			}()
+		}
+	}()
+
+	h := newHelperClient(path)
+	values, ok := h.readMSRs(2, msrMPERF)
+	if len(requests) != 1 || !ok[0] || !ok[1] || values[0] != 10 || values[1] != 11 {
+		t.Errorf("readMSRs = %v %v in %d requests; want [10 11] in 1", values, ok, len(requests))
+	}
+	<-requests
+
+	start := time.Now()
+	if _, ok := h.readMSR(0, msrAPERF); ok {
+		t.Fatal("unanswered request read")
+	}
+	<-requests
+	if _, err := h.readFile("/sys/x"); err == nil || time.Since(start) > 2*helperTimeout {
+		t.Errorf("request after a timeout = %v after %s; want an immediate failure", err, time.Since(start))
+	}
+	ln.Close()
+	if len(requests) != 0 {
+		t.Errorf("asked the failed helper again: %q", <-requests)
+	}
+}
@@ -0,0 +1,196 @@
+package monitor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// historyRecord is one minute of aggregated samples in the persistent
+// history store.
+type historyRecord struct {
+	Time      time.Time `json:"time"`               // Start of the minute
+	Samples   int       `json:"samples"`            // Polls aggregated into this record
+	CPUAvg    float64   `json:"cpu_avg"`            // Mean total CPU usage
+	CPUMax    float64   `json:"cpu_max"`            // Highest total CPU usage
+	TempAvg   float64   `json:"temp_avg,omitempty"` // Mean package temperature (°C), omitted when unavailable
+	TempMax   float64   `json:"temp_max,omitempty"` // Highest package temperature (°C)
+	Throttled int       `json:"throttled"`          // Polls during which the CPU throttled
+	Stress    bool      `json:"stress,omitempty"`   // Whether the stress test ran during the minute
+
+	FioIOPS    float64 `json:"fio_iops,omitempty"`       // Mean IOPS of the fio disk stress, omitted when it did not run
+	FioLatency float64 `json:"fio_latency_us,omitempty"` // Mean fio completion latency (µs)
+
+	DiskRead  float64 `json:"disk_read_bps,omitempty"`  // Mean bytes read per second over all disks
+	DiskWrite float64 `json:"disk_write_bps,omitempty"` // Mean bytes written per second over all disks
+
+	ClockStep float64 `json:"clock_step_s,omitempty"` // Seconds the wall clock jumped during the minute, omitted when it did not
+}
+
+// historyStore is a sink that appends one record per minute to daily
+// JSON Lines files (YYYY-MM-DD.jsonl) so statistics survive restarts.
+// Files older than the retention period are removed at startup.
+type historyStore struct {
+	dir     string
+	current historyRecord
+	cpuSum  float64
+	tempSum float64
+	tempN   int
+	fioIOPS float64 // Sums over polls with fio running
+	fioLat  float64
+	fioN    int
+
+	diskRead, diskWrite float64 // Sums over all polls
+}
+
+// defaultHistoryDir returns $XDG_DATA_HOME/kkperf/history, falling back
+// to ~/.local/share/kkperf/history.
+func defaultHistoryDir() string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "kkperf", "history")
+}
+
+// newHistoryStore creates the store directory and prunes expired files.
+func newHistoryStore(dir string, retention time.Duration) (*historyStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("history: %v", err)
+	}
+	if retention > 0 {
+		cutoff := time.Now().Add(-retention).Format("2006-01-02")
+		files, _ := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+		for _, file := range files {
+			if day := strings.TrimSuffix(filepath.Base(file), ".jsonl"); day < cutoff {
+				os.Remove(file)
+			}
+		}
+	}
+	return &historyStore{dir: dir}, nil
+}
+
+// write adds a sample to the current minute, flushing the previous
+// minute when a new one starts.
+func (h *historyStore) write(s *Sample) error {
+	minute := s.Time.Truncate(time.Minute)
+	var err error
+	if !h.current.Time.Equal(minute) {
+		err = h.flush()
+		h.current = historyRecord{Time: minute}
+		h.cpuSum, h.tempSum, h.tempN = 0, 0, 0
+		h.fioIOPS, h.fioLat, h.fioN = 0, 0, 0
+		h.diskRead, h.diskWrite = 0, 0
+	}
+
+	r := &h.current
+	r.Samples++
+	h.cpuSum += s.CPU
+	r.CPUAvg = h.cpuSum / float64(r.Samples)
+	if s.CPU > r.CPUMax {
+		r.CPUMax = s.CPU
+	}
+	if s.Temp > 0 {
+		h.tempSum += s.Temp
+		h.tempN++
+		r.TempAvg = h.tempSum / float64(h.tempN)
+		if s.Temp > r.TempMax {
+			r.TempMax = s.Temp
+		}
+	}
+	if s.Throttled {
+		r.Throttled++
+	}
+	if s.Stress {
+		r.Stress = true
+	}
+	if s.DiskIOPS > 0 {
+		h.fioIOPS += s.DiskIOPS
+		h.fioLat += s.DiskLatency
+		h.fioN++
+		r.FioIOPS = h.fioIOPS / float64(h.fioN)
+		r.FioLatency = h.fioLat / float64(h.fioN)
+	}
+	read, write, _ := diskTotals(s.Disks)
+	h.diskRead += read
+	h.diskWrite += write
+	r.DiskRead = h.diskRead / float64(r.Samples)
+	r.DiskWrite = h.diskWrite / float64(r.Samples)
+	r.ClockStep += s.ClockStep
+	return err
+}
+
+// close writes the partial minute in progress.
+func (h *historyStore) close() error {
+	return h.flush()
+}
+
+// flush appends the current record to its day's file.
+func (h *historyStore) flush() error {
+	if h.current.Samples == 0 {
+		return nil
+	}
+	r := h.current
+	r.CPUAvg = roundTo(r.CPUAvg, 2)
+	r.CPUMax = roundTo(r.CPUMax, 2)
+	r.TempAvg = roundTo(r.TempAvg, 2)
+	r.TempMax = roundTo(r.TempMax, 2)
+	r.FioIOPS = roundTo(r.FioIOPS, 0)
+	r.FioLatency = roundTo(r.FioLatency, 1)
+	r.ClockStep = roundTo(r.ClockStep, 3)
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(h.dir, r.Time.Format("2006-01-02")+".jsonl")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("history: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("history: %v", err)
+	}
+	return nil
+}
+
+// readHistory returns the records in [from, to) in time order. Lines that
+// fail to parse (for example a record cut short by a crash) are skipped.
+func readHistory(dir string, from, to time.Time) ([]historyRecord, error) {
+	var records []historyRecord
+	// Files are per local calendar day; start a day early in case the
+	// range begins just after midnight in another offset
+	start := time.Date(from.Year(), from.Month(), from.Day()-1, 0, 0, 0, 0, time.Local)
+	for day := start; !day.After(to); day = day.AddDate(0, 0, 1) {
+		f, err := os.Open(filepath.Join(dir, day.Format("2006-01-02")+".jsonl"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var r historyRecord
+			if json.Unmarshal(scanner.Bytes(), &r) != nil {
+				continue
+			}
+			if !r.Time.Before(from) && r.Time.Before(to) {
+				records = append(records, r)
+			}
+		}
+		f.Close()
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, nil
+}
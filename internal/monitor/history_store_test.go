@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHistoryStore checks the records fixed samples aggregate into, one
+// JSON line per minute in the day's file, and that reading them back
+// skips a line cut short by a crash.
+func TestHistoryStore(t *testing.T) {
+	dir := t.TempDir()
+	h, err := newHistoryStore(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.Local)
+	disk := []DiskIO{{Device: "sda", ReadBytes: 1e6, WriteBytes: 3e6}}
+	for _, s := range []Sample{
+		{Time: start, CPU: 10, Temp: 60, Disks: disk},
+		{Time: start.Add(20 * time.Second), CPU: 20, Temp: 0, Throttled: true},
+		{Time: start.Add(40 * time.Second), CPU: 30.123, Temp: 70.005, Stress: true, DiskIOPS: 1000, DiskLatency: 250.04, ClockStep: 1.5},
+		{Time: start.Add(time.Minute), CPU: 50, GPU: -1},
+	} {
+		if err := h.write(&s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "2025-10-01.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	minute := func(m time.Time) string {
+		stamp, _ := json.Marshal(m)
+		return string(stamp)
+	}
+	want := []string{
+		`{"time":` + minute(start) + `,"samples":3,"cpu_avg":20.04,"cpu_max":30.12,"temp_avg":65,"temp_max":70.01,"throttled":1,"stress":true,` +
+			`"fio_iops":1000,"fio_latency_us":250,"disk_read_bps":333333.3333333333,"disk_write_bps":1000000,"clock_step_s":1.5}`,
+		`{"time":` + minute(start.Add(time.Minute)) + `,"samples":1,"cpu_avg":50,"cpu_max":50,"throttled":0}`,
+	}
+	if got := strings.Split(strings.TrimSpace(string(data)), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("history file:\n%s\nwant:\n%s", data, strings.Join(want, "\n"))
+	}
+
+	f, _ := os.OpenFile(filepath.Join(dir, "2025-10-01.jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"time":"2025-10-01T12:02:00`)
+	f.Close()
+	records, err := readHistory(dir, start, start.Add(time.Hour))
+	if err != nil || len(records) != 2 || records[0].Samples != 3 || records[1].CPUMax != 50 {
+		t.Errorf("readHistory = %+v, %v; want the two minutes", records, err)
+	}
+	if records, _ := readHistory(dir, start.Add(time.Minute), start.Add(time.Hour)); len(records) != 1 {
+		t.Errorf("readHistory from 12:01 = %d records; want 1", len(records))
+	}
+}
@@ -0,0 +1,260 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeremycharlesgillespie/kode_kronical_perf_monitor/internal/version"
+)
+
+// mqttSink publishes samples as JSON to an MQTT broker. With Home
+// Assistant discovery enabled it also publishes retained discovery
+// payloads on every connect, so the sensors appear as entities of one
+// device without any YAML configuration.
+//
+// Topics, with <node> derived from the hostname:
+//
+//	<prefix>/<node>/state         JSON state, one message per interval
+//	<prefix>/<node>/availability  "online", or "offline" via the last will
+type mqttSink struct {
+	cfg        *Config
+	node       string
+	throttling bool // Whether throttle events can be detected on this system
+	lastSend   time.Time
+	pending    chan Sample
+
+	mu      sync.Mutex
+	lastErr error
+	done    chan struct{}
+}
+
+// mqttNodeChars matches characters not allowed in topic levels and
+// discovery object IDs.
+var mqttNodeChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// newMQTTSink starts the background publisher for the [mqtt] config.
+func newMQTTSink(cfg *Config, throttling bool) *mqttSink {
+	node := cfg.MQTT.NodeID
+	if node == "" {
+		node, _ = os.Hostname()
+	}
+	node = mqttNodeChars.ReplaceAllString(node, "_")
+	if node == "" {
+		node = "kkperf"
+	}
+
+	s := &mqttSink{
+		cfg:        cfg,
+		node:       node,
+		throttling: throttling,
+		pending:    make(chan Sample, 1),
+		done:       make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// topic returns a topic under this node.
+func (s *mqttSink) topic(name string) string {
+	return fmt.Sprintf("%s/%s/%s", s.cfg.MQTT.TopicPrefix, s.node, name)
+}
+
+// loop publishes queued states, connecting (and reconnecting after
+// errors) as needed.
+func (s *mqttSink) loop() {
+	defer close(s.done)
+	var client *mqttClient
+	for sample := range s.pending {
+		var err error
+		if client == nil {
+			client, err = s.connect(&sample)
+		}
+		if client != nil {
+			if err = client.publish(s.topic("state"), s.state(&sample), false); err != nil {
+				client.conn.Close()
+				client = nil
+			}
+		}
+		s.mu.Lock()
+		s.lastErr = err
+		s.mu.Unlock()
+	}
+	if client != nil {
+		client.publish(s.topic("availability"), []byte("offline"), true)
+		client.close()
+	}
+}
+
+// connect opens the broker connection and announces the node. The
+// sample decides which sensors are announced.
+func (s *mqttSink) connect(sample *Sample) (*mqttClient, error) {
+	client, err := mqttDial(s.cfg.MQTT.Broker, mqttConnectOptions{
+		clientID:    "kkperf-" + s.node,
+		username:    s.cfg.MQTT.Username,
+		password:    s.cfg.MQTT.Password,
+		keepAlive:   2*s.cfg.MQTT.Interval + 30*time.Second,
+		willTopic:   s.topic("availability"),
+		willMessage: "offline",
+		willRetain:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mqtt: %v", err)
+	}
+
+	if s.cfg.MQTT.Discovery {
+		for topic, payload := range s.discoveryPayloads(sample) {
+			if err := client.publish(topic, payload, true); err != nil {
+				client.conn.Close()
+				return nil, fmt.Errorf("mqtt: %v", err)
+			}
+		}
+	}
+	if err := client.publish(s.topic("availability"), []byte("online"), true); err != nil {
+		client.conn.Close()
+		return nil, fmt.Errorf("mqtt: %v", err)
+	}
+	return client, nil
+}
+
+// write queues the sample if the interval has elapsed and returns the
+// error from the previous publish, if any.
+func (s *mqttSink) write(sample *Sample) error {
+	if sample.Time.Sub(s.lastSend) < s.cfg.MQTT.Interval {
+		return nil
+	}
+	s.lastSend = sample.Time
+
+	select {
+	case s.pending <- *sample:
+	default:
+		// Previous publish still in progress; skip this one
+	}
+
+	s.mu.Lock()
+	err := s.lastErr
+	s.lastErr = nil
+	s.mu.Unlock()
+	return err
+}
+
+// close marks the node offline and disconnects.
+func (s *mqttSink) close() error {
+	close(s.pending)
+	<-s.done
+	return nil
+}
+
+// state renders the JSON state message. Unavailable values are null.
+func (s *mqttSink) state(sample *Sample) []byte {
+	optional := func(v float64, ok bool) interface{} {
+		if !ok {
+			return nil
+		}
+		return roundTo(v, 1)
+	}
+	throttled := "OFF"
+	if sample.Throttled {
+		throttled = "ON"
+	}
+	stress := "OFF"
+	if sample.Stress {
+		stress = "ON"
+	}
+	read, write, _ := diskTotals(sample.Disks)
+	data, _ := json.Marshal(map[string]interface{}{
+		"cpu":         roundTo(sample.CPU, 1),
+		"temperature": optional(sample.Temp, sample.Temp > 0),
+		"gpu":         optional(sample.GPU, sample.GPU >= 0),
+		"disk":        optional(sample.Disk, sample.Disk >= 0),
+		"disk_read":   optional(unitBytesRate.exported(read), sample.Disks != nil),
+		"disk_write":  optional(unitBytesRate.exported(write), sample.Disks != nil),
+		"network":     optional(sample.Net, sample.Net >= 0),
+		"throttled":   throttled,
+		"stress":      stress,
+	})
+	return data
+}
+
+// discoveryPayloads returns the retained Home Assistant discovery config
+// messages, keyed by topic. Sensors whose source is unavailable on this
+// system are not announced.
+func (s *mqttSink) discoveryPayloads(sample *Sample) map[string][]byte {
+	host, _ := os.Hostname()
+	device := map[string]interface{}{
+		"identifiers":  []string{"kkperf_" + s.node},
+		"name":         host,
+		"manufacturer": "kode_kronical_perf_monitor",
+		"model":        "CPU Performance Monitor",
+		"sw_version":   version.Version,
+	}
+
+	type entity struct {
+		component   string
+		key         string
+		name        string
+		deviceClass string
+		unit        string
+		icon        string
+		available   bool
+	}
+	entities := []entity{
+		{"sensor", "cpu", "CPU usage", "", unitPercent.symbol(), "mdi:cpu-64-bit", true},
+		{"sensor", "temperature", "CPU temperature", "temperature", unitCelsius.symbol(), "", sample.Temp > 0},
+		{"sensor", "gpu", "GPU usage", "", unitPercent.symbol(), "mdi:expansion-card", sample.GPU >= 0},
+		{"sensor", "disk", "Disk usage", "", unitPercent.symbol(), "mdi:harddisk", sample.Disk >= 0},
+		{"sensor", "disk_read", "Disk read", "data_rate", unitBytesRate.symbol(), "", sample.Disks != nil},
+		{"sensor", "disk_write", "Disk write", "data_rate", unitBytesRate.symbol(), "", sample.Disks != nil},
+		{"sensor", "network", "Network usage", "", unitPercent.symbol(), "mdi:network", sample.Net >= 0},
+		{"binary_sensor", "stress", "Stress test", "running", "", "", true},
+		{"binary_sensor", "throttled", "CPU throttling", "problem", "", "", s.throttling},
+	}
+
+	payloads := map[string][]byte{}
+	for _, e := range entities {
+		if !e.available {
+			continue
+		}
+		config := map[string]interface{}{
+			"name":               e.name,
+			"unique_id":          fmt.Sprintf("kkperf_%s_%s", s.node, e.key),
+			"object_id":          fmt.Sprintf("%s_%s", strings.ToLower(s.node), e.key),
+			"state_topic":        s.topic("state"),
+			"value_template":     fmt.Sprintf("{{ value_json.%s }}", e.key),
+			"availability_topic": s.topic("availability"),
+			"device":             device,
+		}
+		if e.deviceClass != "" {
+			config["device_class"] = e.deviceClass
+		}
+		if e.unit != "" {
+			config["unit_of_measurement"] = e.unit
+		}
+		if e.icon != "" {
+			config["icon"] = e.icon
+		}
+		if e.component == "sensor" {
+			config["state_class"] = "measurement"
+		}
+		data, _ := json.Marshal(config)
+		topic := fmt.Sprintf("%s/%s/kkperf_%s/%s/config", s.cfg.MQTT.DiscoveryPrefix, e.component, s.node, e.key)
+		payloads[topic] = data
+	}
+	return payloads
+}
+
+// roundTo rounds v to the given number of decimals, for compact JSON.
+func roundTo(v float64, decimals int) float64 {
+	scale := 1.0
+	for i := 0; i < decimals; i++ {
+		scale *= 10
+	}
+	if v < 0 {
+		return float64(int64(v*scale-0.5)) / scale
+	}
+	return float64(int64(v*scale+0.5)) / scale
+}
@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestMQTTPayloads checks the JSON state of fixed samples, with null for
+// unavailable sources, and the Home Assistant discovery configs announced
+// for them.
+func TestMQTTPayloads(t *testing.T) {
+	cfg := defaultConfig()
+	s := &mqttSink{cfg: cfg, node: "bench"}
+
+	for _, tc := range []struct {
+		name       string
+		throttling bool
+		sample     Sample
+		state      string
+		entities   []string // Discovery topics under homeassistant/
+	}{
+		{
+			name:       "all sources",
+			throttling: true,
+			sample: Sample{Time: time.Unix(1700000000, 0), CPU: 12.34, Temp: 71.26, GPU: 40, Disk: 0, Net: 5.55, Stress: true, Throttled: true,
+				Disks: []DiskIO{{Device: "sda", ReadBytes: 1.5e6, WriteBytes: 2.25e6}}},
+			state: `{"cpu":12.3,"disk":0,"disk_read":1.5,"disk_write":2.3,"gpu":40,"network":5.6,"stress":"ON","temperature":71.3,"throttled":"ON"}`,
+			entities: []string{
+				"sensor/kkperf_bench/cpu", "sensor/kkperf_bench/temperature", "sensor/kkperf_bench/gpu", "sensor/kkperf_bench/disk",
+				"sensor/kkperf_bench/disk_read", "sensor/kkperf_bench/disk_write", "sensor/kkperf_bench/network",
+				"binary_sensor/kkperf_bench/stress", "binary_sensor/kkperf_bench/throttled",
+			},
+		},
+		{
+			name:     "unavailable sources",
+			sample:   Sample{Time: time.Unix(1700000000, 0), CPU: 5, GPU: -1, Disk: -1, Net: -1},
+			state:    `{"cpu":5,"disk":null,"disk_read":null,"disk_write":null,"gpu":null,"network":null,"stress":"OFF","temperature":null,"throttled":"OFF"}`,
+			entities: []string{"sensor/kkperf_bench/cpu", "binary_sensor/kkperf_bench/stress"},
+		},
+	} {
+		s.throttling = tc.throttling
+		if got := string(s.state(&tc.sample)); got != tc.state {
+			t.Errorf("%s: state = %s\nwant %s", tc.name, got, tc.state)
+		}
+		payloads := s.discoveryPayloads(&tc.sample)
+		if len(payloads) != len(tc.entities) {
+			t.Errorf("%s: %d discovery configs; want %d", tc.name, len(payloads), len(tc.entities))
+		}
+		for _, entity := range tc.entities {
+			if _, ok := payloads["homeassistant/"+entity+"/config"]; !ok {
+				t.Errorf("%s: no discovery config for %s", tc.name, entity)
+			}
+		}
+	}
+
+	var temp map[string]interface{}
+	json.Unmarshal(s.discoveryPayloads(&Sample{Temp: 60, GPU: -1, Disk: -1, Net: -1})["homeassistant/sensor/kkperf_bench/temperature/config"], &temp)
+	for key, want := range map[string]string{
+		"unique_id":           "kkperf_bench_temperature",
+		"object_id":           "bench_temperature",
+		"state_topic":         "kkperf/bench/state",
+		"availability_topic":  "kkperf/bench/availability",
+		"value_template":      "{{ value_json.temperature }}",
+		"device_class":        "temperature",
+		"unit_of_measurement": "°C",
+		"state_class":         "measurement",
+	} {
+		if temp[key] != want {
+			t.Errorf("temperature discovery %s = %v; want %q", key, temp[key], want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package monitor
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// httpSink serves the latest sample over HTTP: Prometheus metrics at
+// /metrics, the sample as JSON at /sample for kkperf fleet, a session
+// reset at /reset, plus Go's pprof profiles at /debug/pprof/ and the expvar
+// self-diagnostics at /debug/vars for troubleshooting the monitor itself.
+type httpSink struct {
+	server *http.Server
+	reset  func() error // Requests a session reset from the main loop
+
+	mu     sync.Mutex
+	latest *Sample
+}
+
+// newHTTPSink starts listening on addr. The listener is opened before
+// returning so a busy port is reported at startup. A non-empty token must
+// be sent with every request, as an "Authorization: Bearer" header or a
+// token query parameter. reset is called on a POST to /reset.
+func newHTTPSink(addr, token string, reset func() error) (*httpSink, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("http: %v", err)
+	}
+
+	h := &httpSink{reset: reset}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", h.serveMetrics)
+	mux.HandleFunc("/sample", h.serveSample)
+	mux.HandleFunc("/reset", h.serveReset)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	h.server = &http.Server{Handler: requireToken(token, mux), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := h.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "http: %v\n", err)
+		}
+	}()
+	return h, nil
+}
+
+// requireToken wraps handler so that requests without the token are
+// refused. Everything served but the session reset is read-only, so the
+// token is all that stands between the network and the samples, the
+// reset and pprof's process internals. An empty token lets every request
+// through.
+func requireToken(token string, handler http.Handler) http.Handler {
+	if token == "" {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			given = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="kkperf"`)
+			http.Error(w, "missing or wrong token", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// write stores the sample for the next scrape.
+func (h *httpSink) write(s *Sample) error {
+	h.mu.Lock()
+	h.latest = s
+	h.mu.Unlock()
+	return nil
+}
+
+// close stops the server.
+func (h *httpSink) close() error {
+	return h.server.Close()
+}
+
+// serveMetrics writes the latest sample in the Prometheus text format.
+func (h *httpSink) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	s := h.latest
+	h.mu.Unlock()
+	if s == nil {
+		http.Error(w, "no sample collected yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprint(w, prometheusMetrics(s))
+}
+
+// serveSample writes the latest sample as JSON with the hostname, which
+// the fleet table polls.
+func (h *httpSink) serveSample(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	s := h.latest
+	h.mu.Unlock()
+	if s == nil {
+		http.Error(w, "no sample collected yet", http.StatusServiceUnavailable)
+		return
+	}
+	host, _ := os.Hostname()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fleetReport{Host: host, Sample: s})
+}
+
+// serveReset resets the session on a POST, so a test script can start
+// each iteration from a clean slate. The reset happens at the next turn
+// of the main loop.
+func (h *httpSink) serveReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := h.reset(); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,208 @@
+package monitor
+
+import (
+	"io"
+	"time"
+)
+
+// Keys that send escape sequences are passed on as single bytes above the
+// ASCII range, so the page handlers and the key bindings can treat them
+// like any other key. Other bytes above ASCII, from multi-byte
+// characters, are dropped; no binding could match them.
+const (
+	keyUp byte = 0x80 + iota
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyInsert
+	keyDelete
+	keyPageUp
+	keyPageDown
+	keyF1
+	keyF2
+	keyF3
+	keyF4
+	keyF5
+	keyF6
+	keyF7
+	keyF8
+	keyF9
+	keyF10
+	keyF11
+	keyF12
+)
+
+// keyEsc is the ESC key, which closes pages.
+const keyEsc byte = 27
+
+// keyNames are the names the [keys] section binds the named keys by.
+var keyNames = map[string]byte{
+	"up": keyUp, "down": keyDown, "right": keyRight, "left": keyLeft,
+	"home": keyHome, "end": keyEnd, "insert": keyInsert, "delete": keyDelete,
+	"pgup": keyPageUp, "pgdn": keyPageDown,
+	"f1": keyF1, "f2": keyF2, "f3": keyF3, "f4": keyF4, "f5": keyF5, "f6": keyF6,
+	"f7": keyF7, "f8": keyF8, "f9": keyF9, "f10": keyF10, "f11": keyF11, "f12": keyF12,
+}
+
+// keyLabels are how the help page shows the named keys.
+var keyLabels = map[byte]string{
+	keyUp: "↑", keyDown: "↓", keyRight: "→", keyLeft: "←",
+	keyHome: "Home", keyEnd: "End", keyInsert: "Ins", keyDelete: "Del",
+	keyPageUp: "PgUp", keyPageDown: "PgDn",
+	keyF1: "F1", keyF2: "F2", keyF3: "F3", keyF4: "F4", keyF5: "F5", keyF6: "F6",
+	keyF7: "F7", keyF8: "F8", keyF9: "F9", keyF10: "F10", keyF11: "F11", keyF12: "F12",
+}
+
+// escTimeout is how long a lone ESC waits for the rest of an escape
+// sequence before it counts as the ESC key. Terminals send a sequence in
+// one write, but a slow SSH link can split it.
+const escTimeout = 50 * time.Millisecond
+
+// csiKeys are the keys of CSI sequences ending in a letter, e.g. ESC [ A,
+// or ESC [ 1 ; 5 A with a modifier, and of SS3 sequences, e.g. ESC O P.
+var csiKeys = map[byte]byte{
+	'A': keyUp, 'B': keyDown, 'C': keyRight, 'D': keyLeft, 'H': keyHome, 'F': keyEnd,
+	'P': keyF1, 'Q': keyF2, 'R': keyF3, 'S': keyF4,
+}
+
+// tildeKeys are the keys of CSI sequences ending in '~', by their first
+// parameter, e.g. ESC [ 5 ~ for Page Up.
+var tildeKeys = map[int]byte{
+	1: keyHome, 2: keyInsert, 3: keyDelete, 4: keyEnd, 5: keyPageUp, 6: keyPageDown, 7: keyHome, 8: keyEnd,
+	11: keyF1, 12: keyF2, 13: keyF3, 14: keyF4, 15: keyF5, 17: keyF6, 18: keyF7, 19: keyF8,
+	20: keyF9, 21: keyF10, 23: keyF11, 24: keyF12,
+}
+
+// parseKeys splits terminal input into keys. An escape sequence that is
+// cut off at the end is returned as rest, to be completed by the next
+// read, unless final is set, which makes its ESC the ESC key. Sequences
+// of keys it does not know, such as mouse reports, are dropped whole.
+func parseKeys(in []byte, final bool) (keys []byte, rest []byte) {
+	for len(in) > 0 {
+		if in[0] != keyEsc {
+			if in[0] < 0x80 {
+				keys = append(keys, in[0])
+			}
+			in = in[1:]
+			continue
+		}
+		key, n := parseEscape(in)
+		if n == 0 { // Cut off
+			if !final {
+				return keys, in
+			}
+			key, n = keyEsc, 1
+		}
+		if key != 0 {
+			keys = append(keys, key)
+		}
+		in = in[n:]
+	}
+	return keys, nil
+}
+
+// parseEscape parses the escape sequence at the start of in, which begins
+// with ESC. It returns the key, 0 for an unknown sequence, and the bytes
+// the sequence takes; 0 bytes when it is cut off. ESC ESC is two presses
+// of ESC.
+func parseEscape(in []byte) (key byte, n int) {
+	if len(in) < 2 {
+		return 0, 0
+	}
+	switch in[1] {
+	case 'O': // SS3: ESC O and a letter
+		if len(in) < 3 {
+			return 0, 0
+		}
+		return csiKeys[in[2]], 3
+	case '[':
+	case keyEsc:
+		return keyEsc, 1
+	default:
+		return 0, 1 // Alt and a key: the key is passed on without the ESC
+	}
+
+	// CSI: ESC [, parameter bytes, and a final byte from '@' to '~'
+	if len(in) >= 3 && in[2] == '[' { // Linux console F1 to F5: ESC [ [ A to E
+		if len(in) < 4 {
+			return 0, 0
+		}
+		if in[3] >= 'A' && in[3] <= 'E' {
+			return keyF1 + in[3] - 'A', 4
+		}
+		return 0, 4
+	}
+	param, first := 0, true
+	for i := 2; i < len(in); i++ {
+		c := in[i]
+		switch {
+		case c >= '0' && c <= '9':
+			if first {
+				param = param*10 + int(c-'0')
+			}
+		case c == ';':
+			first = false // Modifiers, such as Shift or Ctrl, are ignored
+		case c >= 0x20 && c <= 0x3f:
+			// Other parameter and intermediate bytes, e.g. '<' of mouse reports
+		case c >= '@' && c <= '~':
+			if c == '~' {
+				return tildeKeys[param], i + 1
+			}
+			return csiKeys[c], i + 1
+		default:
+			return 0, i // Not a sequence after all; the byte starts the next key
+		}
+	}
+	return 0, 0
+}
+
+// readKeys reads keys from the terminal in the background. Bytes are
+// collected until they form whole keys; a sequence still incomplete after
+// escTimeout is taken as the ESC key followed by what came with it. At the
+// end of the input, the channel stays open and silent.
+func readKeys(r io.Reader) <-chan byte {
+	chunks := make(chan []byte)
+	go func() {
+		for {
+			buf := make([]byte, 64)
+			n, err := r.Read(buf)
+			if n > 0 {
+				chunks <- buf[:n]
+			}
+			if err != nil {
+				close(chunks)
+				return
+			}
+		}
+	}()
+
+	keys := make(chan byte, 1)
+	go func() {
+		var pending []byte
+		var timeout <-chan time.Time
+		for chunks != nil || pending != nil {
+			final := false
+			select {
+			case chunk, ok := <-chunks:
+				if !ok {
+					chunks, final = nil, true
+				}
+				pending = append(pending, chunk...)
+			case <-timeout:
+				final = true
+			}
+			var parsed []byte
+			parsed, pending = parseKeys(pending, final)
+			for _, k := range parsed {
+				keys <- k
+			}
+			timeout = nil
+			if pending != nil {
+				timeout = time.After(escTimeout)
+			}
+		}
+	}()
+	return keys
+}
@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+// TestParseKeys checks that escape sequences become single keys, that a
+// split sequence is completed by the next read, and that a lone ESC is
+// passed on once escTimeout has gone by.
+func TestParseKeys(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want []byte
+		rest string
+	}{
+		{"q\x1b[A\x1b[B\x1bOC\x1b[1;5D", []byte{'q', keyUp, keyDown, keyRight, keyLeft}, ""},
+		{"\x1b[5~\x1b[6~\x1bOP\x1b[24~\x1b[[E", []byte{keyPageUp, keyPageDown, keyF1, keyF12, keyF5}, ""},
+		{"\x1b\x1bx\x1bq", []byte{keyEsc, 'x', 'q'}, ""},  // ESC, then Alt+x and Alt+q
+		{"a\x1b[<0;12;5Mb\xc3\xa9", []byte{'a', 'b'}, ""}, // Mouse report and é dropped
+		{"w\x1b[1", []byte{'w'}, "\x1b[1"},                // Cut off
+		{"\x1b", nil, "\x1b"},
+	} {
+		keys, rest := parseKeys([]byte(c.in), false)
+		if !bytes.Equal(keys, c.want) || string(rest) != c.rest {
+			t.Errorf("parseKeys(%q) = %v, %q; want %v, %q", c.in, keys, rest, c.want, c.rest)
+		}
+	}
+	if keys, rest := parseKeys([]byte("\x1b[1"), true); !bytes.Equal(keys, []byte{keyEsc, '[', '1'}) || rest != nil {
+		t.Errorf("final parseKeys = %v, %q; want ESC [ 1", keys, rest)
+	}
+
+	r, w := io.Pipe()
+	defer w.Close()
+	keys := readKeys(r)
+	w.Write([]byte("\x1b["))
+	w.Write([]byte("6~"))
+	if k := <-keys; k != keyPageDown {
+		t.Errorf("split sequence read as %d, want Page Down", k)
+	}
+	start := time.Now()
+	w.Write([]byte("\x1b"))
+	if k := <-keys; k != keyEsc || time.Since(start) < escTimeout {
+		t.Errorf("lone ESC read as %d after %s", k, time.Since(start))
+	}
+
+	b, err := newKeyBindings(map[string]string{"zoom_in": "pgup", "zoom_out": "PgDn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b[keyPageUp] != actionZoomIn || b.label(actionZoomOut) != "PgDn" {
+		t.Errorf("bindings %v, zoom out labeled %q", b, b.label(actionZoomOut))
+	}
+}
@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// iperfRateRe matches the bitrate of an iperf3 interval report line,
+// e.g. "[  5]   1.00-2.00   sec   112 MBytes   941 Mbits/sec".
+var iperfRateRe = regexp.MustCompile(`sec\s+[\d.]+\s+\w?Bytes\s+([\d.]+)\s+Mbits/sec`)
+
+// netStress runs iperf3 as a client against the configured server to
+// load the network while throughput is graphed next to CPU and interrupt
+// usage.
+type netStress struct {
+	cmd *exec.Cmd
+
+	mu       sync.Mutex
+	running  bool
+	mbps     float64 // Throughput of the latest interval in Mbit/s
+	capacity float64 // Link capacity in Mbit/s when the run started
+	err      string  // Why the last run ended early, shown in the status line
+}
+
+// iperfAvailable reports whether network stress can be started.
+func (m *Monitor) iperfAvailable() bool {
+	if m.cfg.Iperf3.Target == "" {
+		return false
+	}
+	_, err := exec.LookPath("iperf3")
+	return err == nil
+}
+
+// startNetStress launches iperf3 until it is stopped. The run has no
+// time limit (-t 0) and reports every second.
+func (m *Monitor) startNetStress() {
+	n := m.netStress
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.running || !m.iperfAvailable() {
+		return
+	}
+
+	cfg := m.cfg.Iperf3
+	args := []string{"-c", cfg.Target, "-p", strconv.Itoa(cfg.Port), "-t", "0", "-i", "1",
+		"-f", "m", "--forceflush", "-P", strconv.Itoa(cfg.Parallel)}
+	if cfg.Reverse {
+		args = append(args, "-R")
+	}
+	cmd := exec.Command("iperf3", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		n.err = err.Error()
+		return
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		n.err = err.Error()
+		return
+	}
+	children.add(cmd)
+	n.cmd, n.running, n.mbps, n.err = cmd, true, 0, ""
+	n.capacity = linkCapacityMbps(m.cfg.NetworkCapacityMbps)
+
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			line := scanner.Text()
+			// With parallel streams only the [SUM] lines cover all of them
+			if cfg.Parallel > 1 && !strings.HasPrefix(line, "[SUM]") {
+				continue
+			}
+			if match := iperfRateRe.FindStringSubmatch(line); match != nil {
+				mbps, _ := strconv.ParseFloat(match[1], 64)
+				n.mu.Lock()
+				n.mbps = mbps
+				n.mu.Unlock()
+			}
+		}
+		cmd.Wait()
+		children.remove(cmd)
+
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if n.cmd != cmd {
+			return // Stopped on purpose
+		}
+		n.cmd, n.running, n.mbps = nil, false, 0
+		// iperf3 reports failures such as a busy or unreachable server
+		// as "iperf3: error - ..."
+		n.err = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(stderr.String()), "iperf3: "))
+		if n.err == "" {
+			n.err = tr("iperf3 exited")
+		}
+	}()
+}
+
+// stopNetStress terminates a running iperf3 client.
+func (m *Monitor) stopNetStress() {
+	n := m.netStress
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.cmd != nil {
+		n.cmd.Process.Kill()
+	}
+	n.cmd, n.running, n.mbps = nil, false, 0
+}
+
+// throughput returns the latest iperf3 throughput in Mbit/s and whether
+// network stress is running.
+func (n *netStress) throughput() (float64, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.mbps, n.running
+}
+
+// netStressStatus formats the network stress part of the status line.
+func (m *Monitor) netStressStatus() string {
+	n := m.netStress
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	switch {
+	case n.running:
+		return fmt.Sprintf("  %s[NET %.0f Mbit/s]%s", colorRed, n.mbps, colorReset)
+	case n.err != "":
+		return fmt.Sprintf("  %s[NET: %s]%s", colorDarkYellow, n.err, colorReset)
+	}
+	return ""
+}
+
+// drawNetworkGraph plots iperf3 throughput relative to link capacity
+// together with total CPU and interrupt (hard and soft IRQ) usage, so
+// offload and interrupt affinity problems show up as CPU cost per bit.
+func (m *Monitor) drawNetworkGraph() {
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, tr("Network Stress Graph"), colorReset)
+	if !m.iperfAvailable() {
+		fmt.Fprintf(m.out, "        %s\r\n", fmt.Sprintf(tr("Set [iperf3] target in the config file and install iperf3 to run network stress (%s)"), m.cfg.KeyBindings.label(actionNetStress)))
+	}
+
+	var latest historyPoint
+	if len(m.displayBuffer) > 0 {
+		latest = m.displayBuffer[len(m.displayBuffer)-1]
+	}
+	m.netStress.mu.Lock()
+	capacity := m.netStress.capacity
+	m.netStress.mu.Unlock()
+	value := func(p historyPoint, series int) float64 {
+		switch series {
+		case 0:
+			if p.iperf == 0 || capacity <= 0 {
+				return -1
+			}
+			return p.iperf / capacity * 100
+		case 1:
+			return p.cpu
+		}
+		return p.irq
+	}
+	m.drawSeriesGraph(seriesGraph{
+		names: []string{tr("Throughput"), tr("CPU"), tr("IRQ")},
+		value: value,
+		scale: newYAxis(m.cfg.NetworkGraphScale, m.seriesValues(3, value), 100, 8), // Auto rounds up to whole percents per row
+		unit:  unitPercent,
+		legend: func(series int) string {
+			switch series {
+			case 0:
+				if latest.iperf == 0 {
+					return "--"
+				}
+				return fmt.Sprintf("%.0f Mbit/s", latest.iperf)
+			case 1:
+				return formatPercent(latest.cpu, 0)
+			}
+			return formatPercent(latest.irq, 1)
+		},
+	})
+}
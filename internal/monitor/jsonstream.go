@@ -0,0 +1,32 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonSink writes every sample as one line of JSON, with the fields of
+// --format '{{json .}}', so the stream can be piped into jq or a log
+// shipper, and saved for kkperf diff.
+type jsonSink struct {
+	enc *json.Encoder
+}
+
+// newJSONSink creates a sink writing to w.
+func newJSONSink(w io.Writer) *jsonSink {
+	return &jsonSink{enc: json.NewEncoder(w)}
+}
+
+// write encodes the sample followed by a newline.
+func (j *jsonSink) write(s *Sample) error {
+	if err := j.enc.Encode(s); err != nil {
+		return fmt.Errorf("json: %v", err)
+	}
+	return nil
+}
+
+// close does nothing; lines are written whole.
+func (j *jsonSink) close() error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestJSONSink checks that every sample becomes one line of JSON that
+// decodes back into a Sample.
+func TestJSONSink(t *testing.T) {
+	var buf bytes.Buffer
+	j := newJSONSink(&buf)
+	for _, cpu := range []float64{12.5, 97} {
+		if err := j.write(&Sample{CPU: cpu, Cores: []float64{cpu, cpu}, Temp: 61.5, Stress: cpu > 50}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	var s Sample
+	if err := json.Unmarshal([]byte(lines[1]), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.CPU != 97 || len(s.Cores) != 2 || s.Temp != 61.5 || !s.Stress {
+		t.Errorf("decoded %+v", s)
+	}
+}
@@ -0,0 +1,401 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// keyAction is something a key does in the main view.
+type keyAction int
+
+const (
+	actionNone keyAction = iota
+	actionStress
+	actionNetStress
+	actionDiskStress
+	actionZoomIn
+	actionZoomOut
+	actionCoreView
+	actionFreqBars
+	actionHeatmapPrev
+	actionHeatmapNext
+	actionGraph
+	actionSplit
+	actionSplitFocus
+	actionSensors
+	actionOverclock
+	actionBandwidth
+	actionCoreHistory
+	actionScatter
+	actionWakeups
+	actionAttribution
+	actionTopProcs
+	actionMark
+	actionCompare
+	actionTimedStress
+	actionBaseline
+	actionPause
+	actionReset
+	actionHelp
+	actionQuit
+	actionClose // Leave a page; ESC on every page, not bindable
+)
+
+// keyActionNames maps the names used in the [keys] config section to
+// actions.
+var keyActionNames = map[string]keyAction{
+	"stress":       actionStress,
+	"net_stress":   actionNetStress,
+	"disk_stress":  actionDiskStress,
+	"zoom_in":      actionZoomIn,
+	"zoom_out":     actionZoomOut,
+	"core_view":    actionCoreView,
+	"freq_bars":    actionFreqBars,
+	"heatmap_prev": actionHeatmapPrev,
+	"heatmap_next": actionHeatmapNext,
+	"graph":        actionGraph,
+	"split":        actionSplit,
+	"split_focus":  actionSplitFocus,
+	"sensors":      actionSensors,
+	"overclock":    actionOverclock,
+	"bandwidth":    actionBandwidth,
+	"core_history": actionCoreHistory,
+	"scatter":      actionScatter,
+	"wakeups":      actionWakeups,
+	"attribution":  actionAttribution,
+	"processes":    actionTopProcs,
+	"mark":         actionMark,
+	"compare":      actionCompare,
+	"timed_stress": actionTimedStress,
+	"baseline":     actionBaseline,
+	"pause":        actionPause,
+	"reset":        actionReset,
+	"help":         actionHelp,
+	"quit":         actionQuit,
+}
+
+// defaultKeys are the bindings of actions the [keys] section leaves out.
+var defaultKeys = map[keyAction]string{
+	actionStress:      "space",
+	actionNetStress:   "n",
+	actionDiskStress:  "d",
+	actionZoomIn:      "w",
+	actionZoomOut:     "s",
+	actionCoreView:    "v",
+	actionFreqBars:    "f",
+	actionHeatmapPrev: "[",
+	actionHeatmapNext: "]",
+	actionGraph:       "g",
+	actionSplit:       "l",
+	actionSplitFocus:  "tab",
+	actionSensors:     "t",
+	actionOverclock:   "o",
+	actionBandwidth:   "b",
+	actionCoreHistory: "c",
+	actionScatter:     "x",
+	actionWakeups:     "i",
+	actionAttribution: "u",
+	actionTopProcs:    "p",
+	actionMark:        "m",
+	actionCompare:     "a",
+	actionTimedStress: "k",
+	actionBaseline:    "y",
+	actionPause:       "e",
+	actionReset:       "r",
+	actionHelp:        "h",
+	actionQuit:        "q",
+}
+
+// keyBindings maps key presses to actions. Letters are bound in both
+// cases.
+type keyBindings map[byte]keyAction
+
+// parseKey turns a key name from the config into the byte readKeys
+// passes on: "space", "tab", a named key of keyNames such as "up" or
+// "f5", or a single printable character.
+func parseKey(name string) (byte, error) {
+	switch strings.ToLower(name) {
+	case "space":
+		return ' ', nil
+	case "tab":
+		return '\t', nil
+	}
+	if key, ok := keyNames[strings.ToLower(name)]; ok {
+		return key, nil
+	}
+	if len(name) != 1 || name[0] <= ' ' || name[0] > '~' {
+		return 0, fmt.Errorf("invalid key %q: use \"space\", \"tab\", a key name such as \"up\", \"pgdn\" or \"f1\", or a single printable character", name)
+	}
+	return name[0], nil
+}
+
+// newKeyBindings builds the binding table from the [keys] section, which
+// maps action names to keys, falling back to defaultKeys for the rest. A
+// key bound to two actions is an error, since only one of them could ever
+// run.
+func newKeyBindings(keys map[string]string) (keyBindings, error) {
+	names := map[keyAction]string{}
+	for action, key := range defaultKeys {
+		names[action] = key
+	}
+	for name, key := range keys {
+		action, ok := keyActionNames[name]
+		if !ok {
+			return nil, fmt.Errorf("keys: unknown action %q", name)
+		}
+		names[action] = key
+	}
+
+	b := keyBindings{}
+	bound := map[byte]string{}
+	for _, name := range sortedActionNames() {
+		key, err := parseKey(names[keyActionNames[name]])
+		if err != nil {
+			return nil, fmt.Errorf("keys: %s: %v", name, err)
+		}
+		for _, k := range []byte{key, caseSwap(key)} {
+			if other, ok := bound[k]; ok && other != name {
+				return nil, fmt.Errorf("keys: %s and %s are both bound to %q", other, name, names[keyActionNames[name]])
+			}
+			bound[k] = name
+			b[k] = keyActionNames[name]
+		}
+	}
+	return b, nil
+}
+
+// sortedActionNames returns the action names in order, so errors about
+// conflicting bindings do not change from run to run.
+func sortedActionNames() []string {
+	names := make([]string, 0, len(keyActionNames))
+	for name := range keyActionNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// caseSwap returns the other case of a letter, or the byte unchanged.
+func caseSwap(key byte) byte {
+	switch {
+	case key >= 'a' && key <= 'z':
+		return key - 'a' + 'A'
+	case key >= 'A' && key <= 'Z':
+		return key - 'A' + 'a'
+	}
+	return key
+}
+
+// label returns how the help page shows the key of an action, e.g.
+// "SPACE", "W" or "PgUp".
+func (b keyBindings) label(action keyAction) string {
+	var keys []byte
+	for k, a := range b {
+		if a == action {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return "-"
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] }) // Upper case first
+	switch keys[0] {
+	case ' ':
+		return "SPACE"
+	case '\t':
+		return "Tab"
+	}
+	if label, ok := keyLabels[keys[0]]; ok {
+		return label
+	}
+	return string(keys[0])
+}
+
+// readOnlyBlocked are the actions read-only mode ignores: the stress
+// toggles and timed runs, the sensor picker, which saves its choice to the
+// config file, the bandwidth page, which creates resctrl groups, and the
+// session reset and the baseline, which would change what the other
+// viewers are looking at.
+var readOnlyBlocked = map[keyAction]bool{
+	actionStress: true, actionNetStress: true, actionDiskStress: true, actionSensors: true, actionBandwidth: true,
+	actionReset: true, actionTimedStress: true, actionBaseline: true,
+}
+
+// readOnlyStatus returns the status line tag of read-only mode.
+func (m *Monitor) readOnlyStatus() string {
+	if !m.cfg.ReadOnly {
+		return ""
+	}
+	return fmt.Sprintf("  %s[%s]%s", colorCyan, tr("READ-ONLY"), colorReset)
+}
+
+// pageAction maps a key pressed on a page to the actions every page
+// shares: the stress binding toggles stress, ESC, the quit binding and
+// the binding that opened the page close it, and Ctrl+C quits. Other keys
+// are actionNone and left to the page.
+func (m *Monitor) pageAction(key byte, page keyAction) keyAction {
+	if key == 3 { // Ctrl+C
+		return actionQuit
+	}
+	switch action := m.cfg.KeyBindings[key]; {
+	case key == keyEsc || action == actionQuit || action == page:
+		return actionClose
+	case action == actionStress && !m.cfg.ReadOnly && m.remote == nil:
+		return actionStress
+	}
+	return actionNone
+}
+
+// handleMainKey runs the action bound to a key pressed in the main view.
+// While the split view is on, zooming and switching graphs act on the
+// focused pane. Pages and the split view are left out in accessible mode,
+// which does not draw them. It returns false when the application should
+// quit.
+func (m *Monitor) handleMainKey(key byte) bool {
+	if key == 3 { // Ctrl+C
+		return false
+	}
+	drawn := !m.cfg.Accessible
+	if m.cfg.ReadOnly && readOnlyBlocked[m.cfg.KeyBindings[key]] {
+		return true
+	}
+	if m.remote != nil && remoteBlocked[m.cfg.KeyBindings[key]] {
+		return true
+	}
+	switch m.cfg.KeyBindings[key] {
+	case actionStress:
+		if m.stressRunning {
+			m.stopStress()
+		} else {
+			m.startStress()
+		}
+		if m.cfg.Accessible {
+			m.announceStress()
+		}
+	case actionNetStress:
+		if _, running := m.netStress.throughput(); running {
+			m.stopNetStress()
+		} else {
+			m.startNetStress()
+		}
+	case actionDiskStress:
+		if m.diskStress.isRunning() {
+			m.stopDiskStress()
+		} else {
+			m.startDiskStress()
+		}
+	case actionZoomIn, actionZoomOut:
+		in := m.cfg.KeyBindings[key] == actionZoomIn
+		if m.split.on {
+			m.zoomSplitPane(in)
+		} else {
+			// Halve or double the window
+			m.window = zoomWindow(m.window, in)
+			m.refreshGraph()
+		}
+	case actionCoreView:
+		m.coreView = (m.coreView + 1) % coreViewCount
+		fmt.Fprint(m.out, clearScreen) // Frame height changes with the view
+	case actionFreqBars:
+		if drawn {
+			m.toggleFreqBars()
+		}
+	case actionHeatmapPrev, actionHeatmapNext:
+		if m.coreView == coreViewHeatmap {
+			// displayHeatmap clamps the page
+			if m.cfg.KeyBindings[key] == actionHeatmapPrev {
+				m.heatmapPage--
+			} else {
+				m.heatmapPage++
+			}
+			fmt.Fprint(m.out, clearScreen)
+		}
+	case actionGraph:
+		if m.split.on {
+			m.cycleSplitMetric()
+		} else {
+			m.graphMode = (m.graphMode + 1) % graphModeCount
+			fmt.Fprint(m.out, clearScreen)
+		}
+	case actionSplitFocus:
+		if m.split.on {
+			m.split.focus = 1 - m.split.focus
+		}
+	case actionSplit:
+		if drawn {
+			m.split.on = !m.split.on
+			fmt.Fprint(m.out, clearScreen)
+		}
+	case actionSensors:
+		if drawn {
+			m.openSensorPicker()
+		}
+	case actionOverclock:
+		if drawn {
+			m.showOverclock = true
+			fmt.Fprint(m.out, clearScreen)
+		}
+	case actionBandwidth:
+		if drawn {
+			m.openBandwidthPage()
+		}
+	case actionCoreHistory:
+		if drawn {
+			m.showCoreHistory = true
+			fmt.Fprint(m.out, clearScreen)
+		}
+	case actionScatter:
+		if drawn {
+			m.showScatter = true
+			fmt.Fprint(m.out, clearScreen)
+		}
+	case actionWakeups:
+		if drawn {
+			m.openWakeupsPage()
+		}
+	case actionAttribution:
+		if drawn {
+			m.openAttributionPage()
+		}
+	case actionTopProcs:
+		if drawn {
+			m.toggleTopProcs()
+		}
+	case actionMark:
+		if drawn {
+			m.bookmarks.toggle()
+		}
+	case actionPause:
+		m.togglePause()
+	case actionReset:
+		m.resetSession()
+	case actionCompare:
+		if drawn {
+			m.showBookmarks = true
+			fmt.Fprint(m.out, clearScreen)
+		}
+	case actionBaseline:
+		m.startBaseline()
+	case actionTimedStress:
+		if m.timed != nil {
+			m.stopStress()
+			m.endTimedStress(tr("stopped by hand"))
+		} else if !m.stressRunning {
+			m.startTimedStress(m.cfg.Stress.Duration)
+		}
+		if m.cfg.Accessible {
+			m.announceStress()
+		}
+	case actionHelp:
+		if m.cfg.Accessible {
+			m.announceHelp()
+		} else {
+			m.showHelp = true
+			fmt.Fprint(m.out, clearScreen)
+		}
+	case actionQuit:
+		return false
+	}
+	return true
+}
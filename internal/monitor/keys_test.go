@@ -0,0 +1,40 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestReadOnly checks that read-only mode ignores the stress and settings
+// keys, tags the status line, and leaves the views alone.
+func TestReadOnly(t *testing.T) {
+	m, usage, temp := fixtureMonitor(t, "4cores", func(cfg *Config) { cfg.ReadOnly = true }, nil)
+	m.out = newScreenBuffer(goldenWidth, goldenHeight)
+	m.handleMainKey('t')
+	if m.showSensors {
+		t.Error("sensor picker opened in read-only mode")
+	}
+	m.handleMainKey('g')
+	if m.graphMode == m.cfg.GraphMode {
+		t.Error("graph key ignored in read-only mode")
+	}
+	if frame := renderFrame(m, usage, temp); !strings.Contains(frame, "[READ-ONLY]") {
+		t.Errorf("status line without the read-only tag:\n%s", frame)
+	}
+}
+
+// TestKeyHints checks that the hints on screen name the keys of the
+// [keys] section rather than the default ones.
+func TestKeyHints(t *testing.T) {
+	m, usage, temp := fixtureMonitor(t, "4cores", func(cfg *Config) {
+		cfg.Keys = map[string]string{"zoom_in": "+", "zoom_out": "-", "mark": "j", "help": "?"}
+	}, nil)
+	m.out = newScreenBuffer(goldenWidth, goldenHeight)
+	if frame := renderFrame(m, usage, temp); !strings.Contains(frame, "Press + to zoom in, - to zoom out") || !strings.Contains(frame, "Press ? for help") {
+		t.Errorf("main view without the configured keys:\n%s", frame)
+	}
+	m.showBookmarks = true
+	if frame := renderFrame(m, usage, temp); !strings.Contains(frame, "Press J to start a range and J again to end it") {
+		t.Errorf("comparison page without the configured mark key:\n%s", frame)
+	}
+}
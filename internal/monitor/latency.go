@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// latencyProbe is a cyclictest-style wakeup latency probe: a thread at
+// real-time priority sleeps until absolute deadlines one interval apart
+// and records how late each wakeup is. It runs from the first time the
+// latency graph is shown until the monitor exits.
+type latencyProbe struct {
+	interval time.Duration
+
+	mu         sync.Mutex
+	running    bool
+	stopped    chan struct{} // Closed by stop to end the probe thread
+	done       chan struct{} // Closed when the probe thread has ended
+	err        error         // Why the probe thread failed; it is not restarted then
+	realtime   bool          // Whether real-time priority could be set (needs root or CAP_SYS_NICE on Linux)
+	count      int           // Wakeups in the current window
+	sum, max   float64       // Latency sum and maximum of the current window in µs
+	overallMax float64       // Maximum since the probe started in µs
+}
+
+// newLatencyProbe creates a stopped probe waking every interval.
+func newLatencyProbe(interval time.Duration) *latencyProbe {
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	return &latencyProbe{interval: interval}
+}
+
+// start launches the probe thread if it is not running yet and has not
+// failed.
+func (l *latencyProbe) start() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running || l.err != nil {
+		return
+	}
+	l.running = true
+	stopped, done := make(chan struct{}), make(chan struct{})
+	l.stopped, l.done = stopped, done
+	go func() {
+		defer close(done)
+		err := l.run(stopped)
+		l.mu.Lock()
+		l.running, l.err = false, err
+		l.mu.Unlock()
+		if err != nil {
+			logWarn("wakeup latency probe stopped", "err", err)
+		}
+	}()
+}
+
+// stop ends the probe thread, if it runs, and waits for it.
+func (l *latencyProbe) stop() {
+	l.mu.Lock()
+	stopped, done := l.stopped, l.done
+	l.stopped, l.done = nil, nil
+	l.mu.Unlock()
+	if stopped == nil {
+		return
+	}
+	close(stopped)
+	<-done
+}
+
+// take returns the average and maximum latency in µs since the previous
+// call and starts a new window. ok is false when no wakeup was recorded.
+func (l *latencyProbe) take() (avg, max float64, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.count == 0 {
+		return 0, 0, false
+	}
+	avg, max = l.sum/float64(l.count), l.max
+	l.count, l.sum, l.max = 0, 0, 0
+	return avg, max, true
+}
+
+// resetMax clears the maximum since start, as a session reset does.
+func (l *latencyProbe) resetMax() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.overallMax = 0
+}
+
+// drawLatencyGraph plots the average and maximum wakeup latency of the
+// probe on a shared microseconds axis, starting the probe on first use.
+// The probe measures this machine, so it stays off when connected to a
+// remote monitor, leaving the graph empty.
+func (m *Monitor) drawLatencyGraph() {
+	if m.remote == nil {
+		m.latency.start()
+	}
+	m.latency.mu.Lock()
+	realtime, overallMax, err := m.latency.realtime, m.latency.overallMax, m.latency.err
+	m.latency.mu.Unlock()
+
+	note := ""
+	switch {
+	case err != nil:
+		note = colorDarkYellow + " (" + err.Error() + ")" + colorReset
+	case !realtime:
+		note = colorDarkYellow + " " + tr("(not real-time: needs root or CAP_SYS_NICE)") + colorReset
+	}
+	fmt.Fprintf(m.out, "%s%s%s %s %.0f µs%s\r\n", colorCyan, tr("Wakeup Latency Graph"), colorReset,
+		tr("Max since start:"), overallMax, note)
+
+	value := func(p historyPoint, series int) float64 {
+		if p.latencyMax == 0 {
+			return -1
+		}
+		if series == 0 {
+			return p.latencyMax
+		}
+		return p.latencyAvg
+	}
+
+	// An auto axis is rounded up to a power of ten step of the peak maximum
+	hi := 0.0
+	for _, p := range m.displayBuffer {
+		hi = math.Max(hi, p.latencyMax)
+	}
+	step := math.Pow(10, math.Floor(math.Log10(math.Max(hi, 10))))
+
+	var latest historyPoint
+	if len(m.displayBuffer) > 0 {
+		latest = m.displayBuffer[len(m.displayBuffer)-1]
+	}
+	m.drawSeriesGraph(seriesGraph{
+		names: []string{tr("max"), tr("avg")},
+		value: value,
+		scale: newYAxis(m.cfg.LatencyGraphScale, m.seriesValues(2, value), 0, step),
+		unit:  unitMicroseconds,
+		legend: func(series int) string {
+			if latest.latencyMax == 0 {
+				return "--"
+			}
+			if series == 0 {
+				return unitMicroseconds.format(latest.latencyMax, 0)
+			}
+			return unitMicroseconds.format(latest.latencyAvg, 0)
+		},
+	})
+}
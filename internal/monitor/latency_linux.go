@@ -0,0 +1,60 @@
+//go:build linux
+
+package monitor
+
+import (
+	"math"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// latencyPriority is the SCHED_FIFO priority of the probe thread, the
+// same default as cyclictest's -p80 in common RT validation guides.
+const latencyPriority = 80
+
+// run is the probe loop, until stopped is closed. It keeps its OS thread
+// for itself so the scheduling policy applies to the measured wakeups
+// only; the thread ends with it rather than going back to the runtime.
+func (l *latencyProbe) run(stopped <-chan struct{}) error {
+	runtime.LockOSThread()
+	attr := unix.SchedAttr{
+		Size:     unix.SizeofSchedAttr,
+		Policy:   unix.SCHED_FIFO,
+		Priority: latencyPriority,
+	}
+	realtime := unix.SchedSetAttr(0, &attr, 0) == nil
+	l.mu.Lock()
+	l.realtime = realtime
+	l.mu.Unlock()
+
+	var next unix.Timespec
+	unix.ClockGettime(unix.CLOCK_MONOTONIC, &next)
+	for {
+		select {
+		case <-stopped:
+			return nil
+		default:
+		}
+		next = unix.NsecToTimespec(next.Nano() + l.interval.Nanoseconds())
+		if err := unix.ClockNanosleep(unix.CLOCK_MONOTONIC, unix.TIMER_ABSTIME, &next, nil); err != nil && err != unix.EINTR {
+			return err
+		}
+		var now unix.Timespec
+		unix.ClockGettime(unix.CLOCK_MONOTONIC, &now)
+		latency := float64(now.Nano()-next.Nano()) / 1000
+
+		l.mu.Lock()
+		l.count++
+		l.sum += latency
+		l.max = math.Max(l.max, latency)
+		l.overallMax = math.Max(l.overallMax, latency)
+		l.mu.Unlock()
+
+		// After a long stall, resynchronize instead of firing a burst
+		// of late wakeups for the missed deadlines
+		if latency > float64(l.interval.Microseconds())*100 {
+			next = now
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !windows
+
+package monitor
+
+import "errors"
+
+// run fails: the probe needs the absolute-deadline sleep of Linux or the
+// timer resolution control of Windows, so the latency graph stays empty.
+func (l *latencyProbe) run(stopped <-chan struct{}) error {
+	return errors.New("the wakeup latency probe is not supported on this platform")
+}
@@ -0,0 +1,37 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLatencyProbeStop checks that a stopped probe thread ends and is no
+// longer counted as running, so it could be started again.
+func TestLatencyProbeStop(t *testing.T) {
+	l := newLatencyProbe(time.Millisecond)
+	l.start()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, _, ok := l.take(); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no wakeup recorded: %v", l.err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	l.stop()
+	l.mu.Lock()
+	running := l.running
+	l.mu.Unlock()
+	if running {
+		t.Error("probe still running after stop")
+	}
+	l.take()
+	time.Sleep(20 * time.Millisecond)
+	if _, _, ok := l.take(); ok {
+		t.Error("wakeups recorded after stop")
+	}
+	l.stop() // Stopping twice is harmless
+}
@@ -0,0 +1,61 @@
+//go:build windows
+
+package monitor
+
+import (
+	"math"
+	"runtime"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+const threadPriorityTimeCritical = 15 // THREAD_PRIORITY_TIME_CRITICAL
+
+var (
+	winmm               = windows.NewLazySystemDLL("winmm.dll")
+	procTimeBeginPeriod = winmm.NewProc("timeBeginPeriod")
+	procTimeEndPeriod   = winmm.NewProc("timeEndPeriod")
+	procSetThreadPrio   = kernel32.NewProc("SetThreadPriority")
+)
+
+// run is the probe loop. Windows has no absolute-deadline sleep, so the
+// thread sleeps for the rest of each interval at time-critical priority,
+// with the system timer raised to 1 ms resolution, and the lateness is
+// measured against the monotonic clock. It runs until stopped is closed.
+func (l *latencyProbe) run(stopped <-chan struct{}) error {
+	runtime.LockOSThread()
+	procTimeBeginPeriod.Call(1)
+	defer procTimeEndPeriod.Call(1)
+	thread, _ := windows.GetCurrentThread()
+	ok, _, _ := procSetThreadPrio.Call(uintptr(thread), threadPriorityTimeCritical)
+	l.mu.Lock()
+	l.realtime = ok != 0
+	l.mu.Unlock()
+
+	next := time.Now()
+	for {
+		select {
+		case <-stopped:
+			return nil
+		default:
+		}
+		next = next.Add(l.interval)
+		time.Sleep(time.Until(next))
+		now := time.Now()
+		latency := float64(now.Sub(next).Nanoseconds()) / 1000
+
+		l.mu.Lock()
+		l.count++
+		l.sum += latency
+		l.max = math.Max(l.max, latency)
+		l.overallMax = math.Max(l.overallMax, latency)
+		l.mu.Unlock()
+
+		// After a long stall, resynchronize instead of firing a burst
+		// of late wakeups for the missed deadlines
+		if latency > float64(l.interval.Microseconds())*100 {
+			next = now
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// loadAverage is the content of /proc/loadavg: the 1, 5 and 15-minute
+// load averages and the runnable and total task counts.
+type loadAverage struct {
+	avg            [3]float64
+	running, tasks int
+	ok             bool // Whether /proc/loadavg was readable
+}
+
+// readLoadAverage parses /proc/loadavg, e.g. "0.52 0.58 0.59 3/812 12345".
+func readLoadAverage() loadAverage {
+	var l loadAverage
+	fields := strings.Fields(readSysfsString(filepath.Join(procDir, "loadavg")))
+	if len(fields) < 4 {
+		return l
+	}
+	for i := range l.avg {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return loadAverage{}
+		}
+		l.avg[i] = v
+	}
+	if parts := strings.SplitN(fields[3], "/", 2); len(parts) == 2 {
+		l.running, _ = strconv.Atoi(parts[0])
+		l.tasks, _ = strconv.Atoi(parts[1])
+	}
+	l.ok = true
+	return l
+}
+
+// displayLoad prints the load averages and task counts, such as "Load:
+// 7.92 5.10 2.33  Tasks: 9 running, 1 blocked, 812 total". Loads and
+// runnable tasks are colored by their share of the cores, so a load above
+// the core count shows as critical.
+func (m *Monitor) displayLoad() {
+	l := m.load
+	if !l.ok {
+		return
+	}
+	color := func(v float64) string { return getUsageColor(v / float64(m.cores) * 100) }
+	fmt.Fprintf(m.out, "%s%s%s", colorBlue, tr("Load:"), colorReset)
+	for _, avg := range l.avg {
+		fmt.Fprintf(m.out, " %s%s%s", color(avg), formatNumber(avg, 2), colorReset)
+	}
+	fmt.Fprintf(m.out, "  %s%s%s %s%s%s\r\n\r\n", colorBlue, tr("Tasks:"), colorReset, color(float64(l.running)),
+		fmt.Sprintf(tr("%d running, %d blocked, %d total"), l.running, m.blocked.count, l.tasks), colorReset)
+}
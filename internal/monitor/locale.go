@@ -0,0 +1,1087 @@
+package monitor
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// locale controls how numbers, units, and UI strings are presented.
+// It is resolved once at startup from the config file or the standard
+// LC_ALL / LC_NUMERIC / LC_MESSAGES / LANG environment variables.
+type locale struct {
+	decimalSep string            // Decimal separator for formatted numbers
+	unitSpace  string            // Separator between a number and its unit ("" or " ")
+	tempUnit   string            // "C" or "F"
+	messages   map[string]string // UI translations keyed by the English text
+}
+
+// activeLocale is used by the formatting helpers and tr. It defaults to
+// plain English formatting until main resolves the user's locale.
+var activeLocale = &locale{decimalSep: ".", tempUnit: "C"}
+
+// commaDecimalLanguages lists languages that use a comma as the decimal separator.
+var commaDecimalLanguages = map[string]bool{
+	"bg": true, "ca": true, "cs": true, "da": true, "de": true, "el": true,
+	"es": true, "et": true, "fi": true, "fr": true, "hr": true, "hu": true,
+	"id": true, "it": true, "lt": true, "lv": true, "nb": true, "nl": true,
+	"nn": true, "pl": true, "pt": true, "ro": true, "ru": true, "sk": true,
+	"sl": true, "sr": true, "sv": true, "tr": true, "uk": true,
+}
+
+// spacedUnitLanguages lists languages whose typography separates a number
+// from its unit or percent sign with a space ("42 %").
+var spacedUnitLanguages = map[string]bool{
+	"cs": true, "de": true, "fi": true, "fr": true, "nb": true, "pl": true,
+	"ru": true, "sk": true, "sv": true,
+}
+
+// newLocale builds a locale from the numeric and message locale names
+// (e.g. "de_DE.UTF-8") and the configured temperature unit.
+func newLocale(numeric, messages, tempUnit string) *locale {
+	loc := &locale{decimalSep: ".", tempUnit: tempUnit}
+	lang := localeLanguage(numeric)
+	if commaDecimalLanguages[lang] {
+		loc.decimalSep = ","
+	}
+	if spacedUnitLanguages[lang] {
+		loc.unitSpace = " "
+	}
+	loc.messages = translations[localeLanguage(messages)]
+	return loc
+}
+
+// resolveLocale determines the active locale from the config, falling back
+// to the environment using the usual POSIX precedence.
+func resolveLocale(cfg *Config) *locale {
+	if cfg.Locale != "" {
+		return newLocale(cfg.Locale, cfg.Locale, cfg.TemperatureUnit)
+	}
+	return newLocale(envLocale("LC_NUMERIC"), envLocale("LC_MESSAGES"), cfg.TemperatureUnit)
+}
+
+// envLocale returns the locale name for a category: LC_ALL wins, then the
+// category variable itself, then LANG.
+func envLocale(category string) string {
+	for _, name := range []string{"LC_ALL", category, "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return "C"
+}
+
+// localeLanguage extracts the lowercase language code from a locale name,
+// dropping territory, encoding, and modifier ("de_DE.UTF-8@euro" -> "de").
+func localeLanguage(name string) string {
+	if i := strings.IndexAny(name, "_.@-"); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ToLower(name)
+}
+
+// tr returns the translation of an English UI string for the active
+// locale, or the string itself when no translation exists.
+func tr(s string) string {
+	if t, ok := activeLocale.messages[s]; ok {
+		return t
+	}
+	return s
+}
+
+// formatNumber formats v with prec decimals using the locale's decimal separator.
+func formatNumber(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if activeLocale.decimalSep != "." {
+		s = strings.Replace(s, ".", activeLocale.decimalSep, 1)
+	}
+	return s
+}
+
+// formatPercent formats a percentage such as "42.5%" or "42,5 %".
+func formatPercent(v float64, prec int) string {
+	return formatNumber(v, prec) + activeLocale.unitSpace + "%"
+}
+
+// convertTemp converts a Celsius reading into the configured display unit.
+func convertTemp(celsius float64) float64 {
+	if activeLocale.tempUnit == "F" {
+		return celsius*9/5 + 32
+	}
+	return celsius
+}
+
+// formatTemp formats a Celsius reading in the display unit, e.g. "61.0°C".
+func formatTemp(celsius float64, prec int) string {
+	return formatNumber(convertTemp(celsius), prec) + activeLocale.unitSpace + "°" + activeLocale.tempUnit
+}
+
+// formatTempDelta formats a temperature difference in the display unit.
+// Unlike formatTemp no offset is applied: 10°C apart is 18°F apart.
+func formatTempDelta(celsius float64, prec int) string {
+	if activeLocale.tempUnit == "F" {
+		celsius = celsius * 9 / 5
+	}
+	return formatNumber(celsius, prec) + activeLocale.unitSpace + "°" + activeLocale.tempUnit
+}
+
+// padRight pads s with spaces to width terminal columns, counting runes
+// rather than bytes so translated strings line up.
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// padLeft right-aligns s in width terminal columns, counting runes like
+// padRight so labels with °C or µs line up.
+func padLeft(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return strings.Repeat(" ", width-n) + s
+	}
+	return s
+}
+
+// translations maps a language code to its UI string catalog.
+var translations = map[string]map[string]string{
+	"de": {
+		"Press %s for help":                   "%s drücken für Hilfe",
+		"Status:":                             "Status:",
+		"Current:":                            "Aktuell:",
+		"Min:":                                "Min:",
+		"Max:":                                "Max:",
+		"CPU Cores (%d cores):":               "CPU-Kerne (%d Kerne):",
+		"Temperature Legend:":                 "Temperaturlegende:",
+		"Cool":                                "Kühl",
+		"Normal":                              "Normal",
+		"Warm":                                "Warm",
+		"Hot":                                 "Heiß",
+		"Very Hot":                            "Sehr heiß",
+		"Critical":                            "Kritisch",
+		"CPU Usage & Temperature Graph":       "CPU-Last & Temperaturverlauf",
+		"Press %s to zoom in, %s to zoom out": "%s vergrößert, %s verkleinert",
+		"Help":                                "Hilfe",
+		"Controls:":                           "Steuerung:",
+		"Toggle stress test ON/OFF":           "Stresstest EIN/AUS",
+		"Toggle stress test (stress command not available)":              "Stresstest (stress-Befehl nicht verfügbar)",
+		"Zoom in (shorter time scale)":                                   "Vergrößern (kürzerer Zeitraum)",
+		"Zoom out (longer time scale)":                                   "Verkleinern (längerer Zeitraum)",
+		"Toggle this help page":                                          "Diese Hilfeseite ein-/ausblenden",
+		"Exit help or quit application":                                  "Hilfe verlassen oder Programm beenden",
+		"Quit application":                                               "Programm beenden",
+		"Time Scales:":                                                   "Zeiträume:",
+		"CPU Core Bars:":                                                 "CPU-Kernbalken:",
+		"Height - CPU usage (0-100%)":                                    "Höhe  - CPU-Last (0-100 %)",
+		"Color  - Core temperature (estimated where no per-core sensor)": "Farbe - Kerntemperatur (geschätzt ohne Sensor pro Kern)",
+		"Bars:  - ▁▂▃▄▅▆▇█ (0% to 100%)":                                 "Balken - ▁▂▃▄▅▆▇█ (0 % bis 100 %)",
+		"Press %s, ESC, or %s to return to main view":                    "%s, ESC oder %s führt zurück zur Hauptansicht",
+		"Exiting...":                                                     "Beenden...",
+		"Kode Kronical Perf Monitor started. Press %s for help.":         "Kode Kronical Perf Monitor gestartet. %s drücken für Hilfe.",
+		"CPU %s percent, temperature %s degrees, %s":                     "CPU %s Prozent, Temperatur %s Grad, %s",
+		"CPU %s percent, temperature unavailable":                        "CPU %s Prozent, Temperatur nicht verfügbar",
+		"rising":                    "steigend",
+		"falling":                   "fallend",
+		"steady":                    "gleichbleibend",
+		"Stress test on":            "Stresstest an",
+		"Stress test off":           "Stresstest aus",
+		"Stress test not available": "Stresstest nicht verfügbar",
+		"Keys: %s toggles the stress test, %s repeats this help, %s quits.":                    "Tasten: %s schaltet den Stresstest, %s wiederholt diese Hilfe, %s beendet.",
+		"Switch core view (grid/vertical bars/heatmap)":                                        "Kernansicht wechseln (Raster/vertikale Balken/Heatmap)",
+		"Switch graph (temperature/stacked/dual-axis/sensors/power/latency/network/PSU/clock)": "Diagramm wechseln (Temperatur/gestapelt/zwei Achsen/Sensoren/Leistung/Latenz/Netzwerk/Netzteil/Takt)",
+		"Stacked Activity Graph": "Gestapelte Systemaktivität",
+		"Temperature Sensors":    "Temperatursensoren",
+		"No temperature sensors found in /sys/class/hwmon or /sys/class/thermal": "Keine Temperatursensoren in /sys/class/hwmon oder /sys/class/thermal gefunden",
+		"(automatic)":  "(automatisch)",
+		"Main sensor:": "Hauptsensor:",
+		"J/K: move  ENTER: main sensor  SPACE: secondary  A: automatic  T/ESC: close": "J/K: bewegen  ENTER: Hauptsensor  LEERTASTE: zusätzlich  A: automatisch  T/ESC: schließen",
+		"Sensors:":                   "Sensoren:",
+		"Choose temperature sensors": "Temperatursensoren wählen",
+		"raw":                        "roh",
+		"Temperature Sensors Graph":  "Temperatursensoren-Diagramm",
+		"Δ to max:":                  "Δ zum Maximum:",
+		"Δ to TjMax:":                "Δ zu TjMax:",
+		"Overclocking":               "Übertaktung",
+		"CPU frequency information is not available (no cpufreq in /sys/devices/system/cpu)": "Keine CPU-Frequenzdaten verfügbar (kein cpufreq in /sys/devices/system/cpu)",
+		"O/ESC: close":                 "O/ESC: schließen",
+		"off":                          "aus",
+		"running":                      "läuft",
+		"Driver:":                      "Treiber:",
+		"Bus clock:":                   "Bustakt:",
+		"Max boost:":                   "Max. Boost:",
+		"Stress:":                      "Stresstest:",
+		"Boost residency (all cores):": "Boost-Anteil (alle Kerne):",
+		"Effective clocks need read access to /dev/cpu/*/msr (run as root or start kkperf helper, with the msr module loaded)": "Effektive Takte erfordern Lesezugriff auf /dev/cpu/*/msr (als root oder mit kkperf helper ausführen, msr-Modul geladen)",
+		"Clock":     "Takt",
+		"Mult":      "Multi",
+		"Effective": "Effektiv",
+		"SPACE: stress  R: reset residency  O/ESC: close":            "LEERTASTE: Stresstest  R: Boost-Anteil zurücksetzen  O/ESC: schließen",
+		"Overclocking detail (clocks, multipliers, boost residency)": "Übertaktungsdetails (Takte, Multiplikatoren, Boost-Anteil)",
+		"Base clock:": "Basistakt:",
+		"Core:":       "Kerne:",
+		"SoC:":        "SoC:",
+		"Power:":      "Leistung:",
+		"Power":       "Leistung",
+		"Power Graph": "Leistungsdiagramm",
+		"RAPL power counters are not available (needs an Intel CPU and root to read energy_uj)": "RAPL-Leistungszähler nicht verfügbar (erfordert eine Intel-CPU und root zum Lesen von energy_uj)",
+		"resctrl memory bandwidth monitoring is not available.":                                 "Die Überwachung der Speicherbandbreite über resctrl ist nicht verfügbar.",
+		"It needs Intel RDT or AMD QoS and: mount -t resctrl resctrl /sys/fs/resctrl":           "Erforderlich sind Intel RDT oder AMD QoS und: mount -t resctrl resctrl /sys/fs/resctrl",
+		"B/ESC: close":                "B/ESC: schließen",
+		"Group":                       "Gruppe",
+		"Total":                       "Gesamt",
+		"Local":                       "Lokal",
+		"all":                         "alle",
+		"SPACE: stress  B/ESC: close": "LEERTASTE: Stresstest  B/ESC: schließen",
+		"Memory bandwidth and cache occupancy per resctrl group": "Speicherbandbreite und Cache-Belegung je resctrl-Gruppe",
+		"Memory Bandwidth and Cache":                             "Speicherbandbreite und Cache",
+		"no running process":                                     "kein laufender Prozess",
+		"(not real-time: needs root or CAP_SYS_NICE)":            "(nicht echtzeitfähig: erfordert root oder CAP_SYS_NICE)",
+		"Wakeup Latency Graph":                                   "Aufweck-Latenz",
+		"Max since start:":                                       "Max. seit Start:",
+		"max":                                                    "max.",
+		"avg":                                                    "Ø",
+		"iperf3 exited":                                          "iperf3 beendet",
+		"Network Stress Graph":                                   "Netzwerk-Stresstest",
+		"Set [iperf3] target in the config file and install iperf3 to run network stress (%s)": "Für den Netzwerk-Stresstest (%s) [iperf3] target in der Konfiguration setzen und iperf3 installieren",
+		"Throughput":                          "Durchsatz",
+		"Toggle iperf3 network stress ON/OFF": "iperf3-Netzwerk-Stresstest EIN/AUS",
+		"Toggle fio disk stress ON/OFF":       "fio-Festplatten-Stresstest EIN/AUS",
+		"Previous/next heatmap page":          "Vorherige/nächste Heatmap-Seite",
+		"Page %d/%d":                          "Seite %d/%d",
+		"[ ]: page":                           "[ ]: Seite",
+		"Core history (usage or temperature per core over time)": "Kernverlauf (Auslastung oder Temperatur je Kern über die Zeit)",
+		"Core History":                            "Kernverlauf",
+		"Usage per core":                          "Auslastung je Kern",
+		"Estimated temperature per core":          "Geschätzte Temperatur je Kern",
+		"one column per %ds, newest on the right": "eine Spalte je %d s, neueste rechts",
+		"now": "jetzt",
+		"T: usage/temperature  SPACE: stress  C/ESC: close":             "T: Auslastung/Temperatur  LEERTASTE: Stresstest  C/ESC: schließen",
+		"Clock against temperature or power (throttle curve)":           "Takt über Temperatur oder Leistung (Drosselkurve)",
+		"Frequency vs Temperature":                                      "Frequenz über Temperatur",
+		"Frequency vs Power":                                            "Frequenz über Leistung",
+		"SPACE: stress  P: temperature/power  R: reset  X/ESC: close":   "LEERTASTE: Stresstest  P: Temperatur/Leistung  R: zurücksetzen  X/ESC: schließen",
+		"Waiting for samples with both a temperature and a core clock.": "Warte auf Messwerte mit Temperatur und Kerntakt.",
+		"Package power is not available; plotting against temperature.": "Package-Leistung ist nicht verfügbar; Darstellung über der Temperatur.",
+		"%d samples":           "%d Messwerte",
+		"correlation r = %.2f": "Korrelation r = %.2f",
+		"Color:":               "Farbe:",
+		"Mean clock (MHz):":    "Mittlerer Takt (MHz):",
+		"Wakeups per core and process (what keeps cores out of deep idle)": "Aufweckvorgänge je Kern und Prozess (was Kerne aus dem Tiefschlaf holt)",
+		"Wakeups": "Aufweckvorgänge",
+		"Interrupt counters are not available (cannot read /proc/interrupts).": "Interrupt-Zähler sind nicht verfügbar (/proc/interrupts nicht lesbar).",
+		"I/ESC: close":                 "I/ESC: schließen",
+		"All cores:":                   "Alle Kerne:",
+		"All/s":                        "Alle/s",
+		"Process":                      "Prozess",
+		"Wakeups/s":                    "Weckrufe/s",
+		"SPACE: stress  I/ESC: close":  "LEERTASTE: Stresstest  I/ESC: schließen",
+		"Ambient:":                     "Umgebung:",
+		"Δ over ambient:":              "Δ über Umgebung:",
+		"Loop:":                        "Kreislauf:",
+		"Pump failure: %s at %.0f RPM": "Pumpenausfall: %s bei %.0f U/min",
+		"Low flow: %s at %.0f L/h":     "Geringer Durchfluss: %s bei %.0f L/h",
+		"Coolant too hot: %s at %s":    "Kühlmittel zu heiß: %s bei %s",
+		"%.0f W in":                    "%.0f W auf",
+		"%.0f W out":                   "%.0f W ab",
+		"%s efficiency":                "%s Wirkungsgrad",
+		"PSU:":                         "Netzteil:",
+		"PSU Power Graph":              "Netzteil-Leistung",
+		"No power supply with a hwmon driver found (corsair-psu or PMBus)": "Kein Netzteil mit hwmon-Treiber gefunden (corsair-psu oder PMBus)",
+		"Input":                                  "Eingang",
+		"Output":                                 "Ausgang",
+		"Stress stopped by the safety limit: %s": "Stresstest durch Sicherheitsgrenze gestoppt: %s",
+		"By user":                                "Nach Benutzer",
+		"By cgroup":                              "Nach Cgroup",
+		"User":                                   "Benutzer",
+		"Procs":                                  "Proz.",
+		"No CPU time used":                       "Keine CPU-Zeit verbraucht",
+		"SPACE: stress  U/ESC: close":            "LEERTASTE: Stresstest  U/ESC: schließen",
+		"CPU by user, cgroup and priority (who is behind the load)": "CPU nach Benutzer, Cgroup und Priorität (wer die Last erzeugt)",
+		"CPU Attribution":                   "CPU-Zuordnung",
+		"By priority":                       "Nach Priorität",
+		"Class":                             "Klasse",
+		"Real-time (FIFO/RR/deadline)":      "Echtzeit (FIFO/RR/Deadline)",
+		"Raised (nice < 0)":                 "Erhöht (nice < 0)",
+		"Low (nice > 0)":                    "Niedrig (nice > 0)",
+		"Batch/idle policy":                 "Batch-/Idle-Richtlinie",
+		"I/O wait:":                         "E/A-Wartezeit:",
+		"D state:":                          "Zustand D:",
+		"Health:":                           "Zustand:",
+		"Open files":                        "Offene Dateien",
+		"zombie processes: %d":              "Zombie-Prozesse: %d",
+		"threads at %.0f%% of the limit":    "Threads bei %.0f %% des Limits",
+		"open files at %.0f%% of the limit": "offene Dateien bei %.0f %% des Limits",
+		"Entropy:":                          "Entropie:",
+		"bits":                              "Bit",
+		"Jitter entropy:":                   "Jitter-Entropie:",
+		"none":                              "keine",
+		"Temperature per core":              "Temperatur je Kern",
+		"Clock:":                            "Uhr:",
+		"synchronized":                      "synchronisiert",
+		"not synchronized":                  "nicht synchronisiert",
+		"offset":                            "Abweichung",
+		"stepped %s at %s":                  "Sprung %s um %s",
+		"CPU usage":                         "CPU-Last",
+		"Temperature":                       "Temperatur",
+		"Package power":                     "Package-Leistung",
+		"Memory":                            "Speicher",
+		"PSU input":                         "Netzteil-Eingang",
+		"%s switches pane, %s changes its graph, %s/%s zoom it, %s leaves": "%s wechselt die Hälfte, %s ihren Graphen, %s/%s zoomen, %s beendet",
+		"Split view: two graphs side by side (%s switches pane)":           "Geteilte Ansicht: zwei Graphen nebeneinander (%s wechselt)",
+		"%s halves and %s doubles the window, which always ends now":       "%s halbiert und %s verdoppelt den Zeitraum, der immer jetzt endet",
+		"MARK":            "MARKE",
+		"A/B Comparison":  "A/B-Vergleich",
+		"Range":           "Bereich",
+		"B - A":           "B - A",
+		"Duration":        "Dauer",
+		"Avg CPU":         "CPU Mittel",
+		"Max CPU":         "CPU Max",
+		"Avg temperature": "Temperatur Mittel",
+		"Max temperature": "Temperatur Max",
+		"Avg power":       "Leistung Mittel",
+		"Max power":       "Leistung Max",
+		"Marking range %s: %s so far, %s ends it":             "Bereich %s wird markiert: bisher %s, %s beendet ihn",
+		"Press %s to start a range and %s again to end it":    "%s startet einen Bereich, erneutes %s beendet ihn",
+		"A new range replaces the older of the two":           "Ein neuer Bereich ersetzt den älteren der beiden",
+		"M: start/end range  SPACE: stress  A/ESC: close":     "M: Bereich starten/beenden  LEERTASTE: Stresstest  A/ESC: schließen",
+		"Start/end a bookmarked range (A and B)":              "Lesezeichen-Bereich starten/beenden (A und B)",
+		"Compare bookmarked ranges A and B side by side":      "Bereiche A und B nebeneinander vergleichen",
+		"Core bars show usage or clock frequency":             "Kernbalken zeigen Auslastung oder Taktfrequenz",
+		"CPU Core Clocks (%d cores): avg %s, min %s, max %s":  "CPU-Kerntakte (%d Kerne): Mittel %s, Min. %s, Max. %s",
+		"Multipliers (x%.0f MHz)":                             "Multiplikatoren (x%.0f MHz)",
+		"Core Clock & Throttling Graph":                       "Kerntakt- und Drosselungsdiagramm",
+		"THROTTLED":                                           "GEDROSSELT",
+		"base clock unknown":                                  "Basistakt unbekannt",
+		"base %s":                                             "Basis %s",
+		"normal":                                              "normal",
+		"below base under load (%s)":                          "unter Basistakt bei Last (%s)",
+		"thermal throttling":                                  "thermische Drosselung",
+		"Core clock":                                          "Kerntakt",
+		"Load:":                                               "Last:",
+		"Tasks:":                                              "Tasks:",
+		"%d running, %d blocked, %d total":                    "%d laufend, %d blockiert, %d gesamt",
+		"Container":                                           "Container",
+		"Memory limit":                                        "Speicherlimit",
+		"of %s CPUs":                                          "von %s CPUs",
+		"%s throttled":                                        "%s gedrosselt",
+		"core bars: host":                                     "Kernbalken: Host",
+		"Show/hide the busiest processes under the core bars": "Aktivste Prozesse unter den Kernbalken ein/aus",
+		"Top %d Processes by CPU:":                            "Top %d Prozesse nach CPU:",
+		"PID":                                                 "PID",
+		"Command":                                             "Befehl",
+		"RSS":                                                 "RSS",
+		"Disk I/O":                                            "Datenträger-E/A",
+		"Read":                                                "Lesen",
+		"Write":                                               "Schreiben",
+		"IOPS":                                                "IOPS",
+		"iowait":                                              "iowait",
+		"Peak":                                                "Spitze",
+		"Disk":                                                "Platte",
+		"GPUs:":                                               "GPUs:",
+		"VRAM":                                                "VRAM",
+		"Idle":                                                "Leerlauf",
+		"Ramp":                                                "Anstieg",
+		"Steady":                                              "Konstant",
+		"Cooldown":                                            "Abkühlung",
+		"Phase":                                               "Phase",
+		"Fans:":                                               "Lüfter:",
+		"agents":                                              "Agenten",
+		"sorted by":                                           "sortiert nach",
+		"S: sort, Q: quit":                                    "S: sortieren, Q: beenden",
+		"Host":                                                "Host",
+		"Temp":                                                "Temp.",
+		"Alerts":                                              "Alarme",
+		"Now":                                                 "Jetzt",
+		"Avg":                                                 "Mittel",
+		"Used":                                                "Verbraucht",
+		"Battery:":                                            "Akku:",
+		"Charging":                                            "Lädt",
+		"Discharging":                                         "Entlädt",
+		"Full":                                                "Voll",
+		"Not charging":                                        "Lädt nicht",
+		"READ-ONLY":                                           "NUR LESEN",
+		"PAUSED":                                              "PAUSIERT",
+		"Pause/resume the graphs to inspect a spike": "Graphen anhalten/fortsetzen, um eine Spitze zu untersuchen",
+		"CPU statistics": "CPU-Statistik",
+		"%s not readable; CPU usage will read 0%%": "%s nicht lesbar; CPU-Auslastung zeigt 0%%",
+		"%d cores": "%d Kerne",
+		"no plausible reading (%s); temperatures will read 0":  "kein plausibler Messwert (%s); Temperaturen zeigen 0",
+		"no CPU sensor found; choose one in the sensor picker": "kein CPU-Sensor gefunden; im Sensorwähler einen wählen",
+		"no sensor found; temperatures will read 0":            "kein Sensor gefunden; Temperaturen zeigen 0",
+		"Stress engine":  "Stresstest",
+		"stress command": "stress-Befehl",
+		"stress command not found; stress testing is disabled": "stress-Befehl nicht gefunden; Stresstest deaktiviert",
+		"built-in worker did not stop":                         "eingebauter Worker hielt nicht an",
+		"built-in worker did not run":                          "eingebauter Worker lief nicht",
+		"built-in engine":                                      "eingebaute Engine",
+		"RAPL energy":                                          "RAPL-Energie",
+		"counters are root-only; run kkperf helper as root to show power": "Zähler nur für root; kkperf helper als root starten, um die Leistung zu zeigen",
+		"no counters found; power is not shown":                           "keine Zähler gefunden; Leistung wird nicht angezeigt",
+		"degraded":                                                        "eingeschränkt",
+		"Startup Checks":                                                  "Startprüfungen",
+		"OK":                                                              "OK",
+		"DEGRADED":                                                        "EINGESCHR.",
+		"Press any key to continue":                                       "Beliebige Taste zum Fortfahren",
+		"Reset min/max, history and statistics":                           "Min./Max., Verlauf und Statistiken zurücksetzen",
+		"Session reset.":                                                  "Sitzung zurückgesetzt.",
+		"REMOTE":                                                          "ENTFERNT",
+		"UNREACHABLE":                                                     "NICHT ERREICHBAR",
+		"ALERT:":                                                          "ALARM:",
+		"Alert: %s":                                                       "Alarm: %s",
+		"STRESS STOPPED:":                                                 "STRESSTEST GESTOPPT:",
+		"restart allowed in":                                              "Neustart möglich in",
+		"Stress is locked for another %s after the safety stop": "Stresstest nach dem Sicherheitsstopp noch %s gesperrt",
+		"completed":                            "abgeschlossen",
+		"stopped after %s: %s":                 "nach %s gestoppt: %s",
+		"Timed stress run of %s, %s":           "Zeitgesteuerter Stresstest von %s, %s",
+		"Started":                              "Gestartet",
+		"Average temperature":                  "Mittlere Temperatur",
+		"Average CPU usage":                    "Mittlere CPU-Last",
+		"Time at ≥ %s":                         "Zeit bei ≥ %s",
+		"Throttle events":                      "Drosselungen",
+		"n/a":                                  "k. A.",
+		"safety limit, %s":                     "Sicherheitsgrenze, %s",
+		"stopped by hand":                      "von Hand gestoppt",
+		"TIMED":                                "ZEIT",
+		"Timed Stress Report":                  "Bericht zum zeitgesteuerten Stresstest",
+		"Timed stress run of %s with a report": "Zeitgesteuerter Stresstest von %s mit Bericht",
+		"Stop the stress test to measure the idle baseline.": "Stresstest stoppen, um die Leerlauf-Basislinie zu messen.",
+		"Measuring the idle baseline for %s.":                "Leerlauf-Basislinie wird %s lang gemessen.",
+		"Idle baseline: CPU %s, temperature %s.":             "Leerlauf-Basislinie: CPU %s, Temperatur %s.",
+		"BASELINE":                                           "BASISLINIE",
+		"Above idle baseline:":                               "Über Leerlauf-Basislinie:",
+		"Cores":                                              "Kerne",
+		"Measure the idle baseline the readings are compared against": "Leerlauf-Basislinie messen, mit der die Werte verglichen werden",
+		"EMERGENCY FAILED":         "NOTFALL FEHLGESCHLAGEN",
+		"not a valid process name": "kein gültiger Prozessname",
+		"SENSORS NOT SAVED":        "SENSOREN NICHT GESPEICHERT",
+	},
+	"fr": {
+		"Press %s for help":                   "%s pour l'aide",
+		"Status:":                             "État :",
+		"Current:":                            "Actuelle :",
+		"Min:":                                "Min :",
+		"Max:":                                "Max :",
+		"CPU Cores (%d cores):":               "Cœurs CPU (%d cœurs) :",
+		"Temperature Legend:":                 "Légende des températures :",
+		"Cool":                                "Frais",
+		"Normal":                              "Normal",
+		"Warm":                                "Tiède",
+		"Hot":                                 "Chaud",
+		"Very Hot":                            "Très chaud",
+		"Critical":                            "Critique",
+		"CPU Usage & Temperature Graph":       "Utilisation CPU et température",
+		"Press %s to zoom in, %s to zoom out": "%s pour zoomer, %s pour dézoomer",
+		"Help":                                "Aide",
+		"Controls:":                           "Commandes :",
+		"Toggle stress test ON/OFF":           "Activer/désactiver le test de charge",
+		"Toggle stress test (stress command not available)":              "Test de charge (commande stress indisponible)",
+		"Zoom in (shorter time scale)":                                   "Zoom avant (période plus courte)",
+		"Zoom out (longer time scale)":                                   "Zoom arrière (période plus longue)",
+		"Toggle this help page":                                          "Afficher/masquer cette aide",
+		"Exit help or quit application":                                  "Quitter l'aide ou l'application",
+		"Quit application":                                               "Quitter l'application",
+		"Time Scales:":                                                   "Échelles de temps :",
+		"CPU Core Bars:":                                                 "Barres des cœurs :",
+		"Height - CPU usage (0-100%)":                                    "Hauteur - Utilisation CPU (0-100 %)",
+		"Color  - Core temperature (estimated where no per-core sensor)": "Couleur - Température du cœur (estimée sans capteur par cœur)",
+		"Bars:  - ▁▂▃▄▅▆▇█ (0% to 100%)":                                 "Barres  - ▁▂▃▄▅▆▇█ (0 % à 100 %)",
+		"Press %s, ESC, or %s to return to main view":                    "%s, Échap ou %s pour revenir à la vue principale",
+		"Exiting...":                                                     "Fermeture...",
+		"Kode Kronical Perf Monitor started. Press %s for help.":         "Kode Kronical Perf Monitor démarré. Appuyez sur %s pour l'aide.",
+		"CPU %s percent, temperature %s degrees, %s":                     "CPU %s pour cent, température %s degrés, %s",
+		"CPU %s percent, temperature unavailable":                        "CPU %s pour cent, température indisponible",
+		"rising":                    "en hausse",
+		"falling":                   "en baisse",
+		"steady":                    "stable",
+		"Stress test on":            "Test de charge activé",
+		"Stress test off":           "Test de charge désactivé",
+		"Stress test not available": "Test de charge indisponible",
+		"Keys: %s toggles the stress test, %s repeats this help, %s quits.":                    "Touches : %s active le test de charge, %s répète cette aide, %s quitte.",
+		"Switch core view (grid/vertical bars/heatmap)":                                        "Changer la vue des cœurs (grille/barres verticales/carte thermique)",
+		"Switch graph (temperature/stacked/dual-axis/sensors/power/latency/network/PSU/clock)": "Changer de graphique (température/empilé/double axe/capteurs/puissance/latence/réseau/alim/fréquence)",
+		"Stacked Activity Graph": "Activité système empilée",
+		"Temperature Sensors":    "Capteurs de température",
+		"No temperature sensors found in /sys/class/hwmon or /sys/class/thermal": "Aucun capteur de température trouvé dans /sys/class/hwmon ou /sys/class/thermal",
+		"(automatic)":  "(automatique)",
+		"Main sensor:": "Capteur principal :",
+		"J/K: move  ENTER: main sensor  SPACE: secondary  A: automatic  T/ESC: close": "J/K : déplacer  ENTRÉE : capteur principal  ESPACE : secondaire  A : automatique  T/ÉCHAP : fermer",
+		"Sensors:":                   "Capteurs :",
+		"Choose temperature sensors": "Choisir les capteurs de température",
+		"raw":                        "brut",
+		"Temperature Sensors Graph":  "Graphique des capteurs de température",
+		"Δ to max:":                  "Δ au max :",
+		"Δ to TjMax:":                "Δ à TjMax :",
+		"Overclocking":               "Overclocking",
+		"CPU frequency information is not available (no cpufreq in /sys/devices/system/cpu)": "Fréquences CPU indisponibles (pas de cpufreq dans /sys/devices/system/cpu)",
+		"O/ESC: close":                 "O/ESC : fermer",
+		"off":                          "arrêté",
+		"running":                      "en cours",
+		"Driver:":                      "Pilote :",
+		"Bus clock:":                   "Horloge bus :",
+		"Max boost:":                   "Boost max :",
+		"Stress:":                      "Stress :",
+		"Boost residency (all cores):": "Temps en boost (tous les cœurs) :",
+		"Effective clocks need read access to /dev/cpu/*/msr (run as root or start kkperf helper, with the msr module loaded)": "Les fréquences effectives nécessitent l’accès en lecture à /dev/cpu/*/msr (exécuter en root ou lancer kkperf helper, avec le module msr chargé)",
+		"Clock":     "Fréq.",
+		"Mult":      "Mult.",
+		"Effective": "Effective",
+		"SPACE: stress  R: reset residency  O/ESC: close":            "ESPACE : stress  R : remettre à zéro le boost  O/ESC : fermer",
+		"Overclocking detail (clocks, multipliers, boost residency)": "Détail overclocking (fréquences, multiplicateurs, temps en boost)",
+		"Base clock:": "Fréquence de base :",
+		"Core:":       "Cœurs :",
+		"SoC:":        "SoC :",
+		"Power:":      "Puissance :",
+		"Power":       "Puiss.",
+		"Power Graph": "Graphique de puissance",
+		"RAPL power counters are not available (needs an Intel CPU and root to read energy_uj)": "Compteurs RAPL indisponibles (nécessite un processeur Intel et root pour lire energy_uj)",
+		"resctrl memory bandwidth monitoring is not available.":                                 "La mesure de bande passante mémoire via resctrl est indisponible.",
+		"It needs Intel RDT or AMD QoS and: mount -t resctrl resctrl /sys/fs/resctrl":           "Elle nécessite Intel RDT ou AMD QoS et : mount -t resctrl resctrl /sys/fs/resctrl",
+		"B/ESC: close":                "B/ESC : fermer",
+		"Group":                       "Groupe",
+		"Local":                       "Locale",
+		"all":                         "tous",
+		"SPACE: stress  B/ESC: close": "ESPACE : stress  B/ESC : fermer",
+		"Memory bandwidth and cache occupancy per resctrl group": "Bande passante mémoire et occupation du cache par groupe resctrl",
+		"Memory Bandwidth and Cache":                             "Bande passante mémoire et cache",
+		"no running process":                                     "aucun processus en cours",
+		"(not real-time: needs root or CAP_SYS_NICE)":            "(pas en temps réel : nécessite root ou CAP_SYS_NICE)",
+		"Wakeup Latency Graph":                                   "Latence de réveil",
+		"Max since start:":                                       "Max depuis le début :",
+		"avg":                                                    "moy.",
+		"iperf3 exited":                                          "iperf3 s’est arrêté",
+		"Network Stress Graph":                                   "Stress réseau",
+		"Set [iperf3] target in the config file and install iperf3 to run network stress (%s)": "Définir [iperf3] target dans la configuration et installer iperf3 pour le stress réseau (%s)",
+		"Throughput":                          "Débit",
+		"Toggle iperf3 network stress ON/OFF": "Activer/désactiver le stress réseau iperf3",
+		"Toggle fio disk stress ON/OFF":       "Activer/désactiver le stress disque fio",
+		"Previous/next heatmap page":          "Page précédente/suivante de la carte thermique",
+		"[ ]: page":                           "[ ] : page",
+		"Core history (usage or temperature per core over time)": "Historique des cœurs (utilisation ou température par cœur dans le temps)",
+		"Core History":                            "Historique des cœurs",
+		"Usage per core":                          "Utilisation par cœur",
+		"Estimated temperature per core":          "Température estimée par cœur",
+		"one column per %ds, newest on the right": "une colonne par %d s, la plus récente à droite",
+		"now": "maintenant",
+		"T: usage/temperature  SPACE: stress  C/ESC: close":             "T : utilisation/température  ESPACE : stress  C/ESC : fermer",
+		"Clock against temperature or power (throttle curve)":           "Fréquence selon la température ou la puissance (courbe de bridage)",
+		"Frequency vs Temperature":                                      "Fréquence selon la température",
+		"Frequency vs Power":                                            "Fréquence selon la puissance",
+		"SPACE: stress  P: temperature/power  R: reset  X/ESC: close":   "ESPACE : stress  P : température/puissance  R : réinitialiser  X/ESC : fermer",
+		"Waiting for samples with both a temperature and a core clock.": "En attente de mesures avec température et fréquence des cœurs.",
+		"Package power is not available; plotting against temperature.": "Puissance du package indisponible ; tracé selon la température.",
+		"%d samples":           "%d mesures",
+		"correlation r = %.2f": "corrélation r = %.2f",
+		"Color:":               "Couleur :",
+		"Mean clock (MHz):":    "Fréquence moyenne (MHz) :",
+		"Wakeups per core and process (what keeps cores out of deep idle)": "Réveils par cœur et par processus (ce qui empêche la veille profonde)",
+		"Wakeups": "Réveils",
+		"Interrupt counters are not available (cannot read /proc/interrupts).": "Les compteurs d’interruptions ne sont pas disponibles (/proc/interrupts illisible).",
+		"I/ESC: close":                 "I/ESC : fermer",
+		"All cores:":                   "Tous les cœurs :",
+		"Timer:":                       "Minuterie :",
+		"Rescheduling:":                "Replanification :",
+		"Timer/s":                      "Minut./s",
+		"Resched/s":                    "Replan./s",
+		"All/s":                        "Total/s",
+		"Process":                      "Processus",
+		"Wakeups/s":                    "Réveils/s",
+		"SPACE: stress  I/ESC: close":  "ESPACE : stress  I/ESC : fermer",
+		"Ambient:":                     "Ambiante :",
+		"Δ over ambient:":              "Δ sur ambiante :",
+		"Loop:":                        "Boucle :",
+		"Pump failure: %s at %.0f RPM": "Panne de pompe : %s à %.0f tr/min",
+		"Low flow: %s at %.0f L/h":     "Débit faible : %s à %.0f L/h",
+		"Coolant too hot: %s at %s":    "Liquide trop chaud : %s à %s",
+		"%.0f W in":                    "%.0f W entrée",
+		"%.0f W out":                   "%.0f W sortie",
+		"%s efficiency":                "rendement %s",
+		"PSU:":                         "Alim :",
+		"PSU Power Graph":              "Puissance de l'alimentation",
+		"No power supply with a hwmon driver found (corsair-psu or PMBus)": "Aucune alimentation avec un pilote hwmon (corsair-psu ou PMBus)",
+		"Input":                                  "Entrée",
+		"Output":                                 "Sortie",
+		"Stress stopped by the safety limit: %s": "Stress arrêté par la limite de sécurité : %s",
+		"By user":                                "Par utilisateur",
+		"By cgroup":                              "Par cgroup",
+		"User":                                   "Utilisateur",
+		"Procs":                                  "Proc.",
+		"No CPU time used":                       "Aucun temps CPU utilisé",
+		"SPACE: stress  U/ESC: close":            "ESPACE : stress  U/ESC : fermer",
+		"CPU by user, cgroup and priority (who is behind the load)": "CPU par utilisateur, cgroup et priorité (qui génère la charge)",
+		"CPU Attribution":                   "Répartition du CPU",
+		"By priority":                       "Par priorité",
+		"Class":                             "Classe",
+		"Real-time (FIFO/RR/deadline)":      "Temps réel (FIFO/RR/deadline)",
+		"Raised (nice < 0)":                 "Élevée (nice < 0)",
+		"Normal (nice 0)":                   "Normale (nice 0)",
+		"Low (nice > 0)":                    "Basse (nice > 0)",
+		"Batch/idle policy":                 "Politique batch/idle",
+		"I/O wait:":                         "Attente E/S :",
+		"D state:":                          "État D :",
+		"Swap":                              "Échange",
+		"Health:":                           "Santé :",
+		"Open files":                        "Fichiers ouverts",
+		"zombie processes: %d":              "processus zombies : %d",
+		"threads at %.0f%% of the limit":    "threads à %.0f %% de la limite",
+		"open files at %.0f%% of the limit": "fichiers ouverts à %.0f %% de la limite",
+		"Entropy:":                          "Entropie :",
+		"Jitter entropy:":                   "Entropie de gigue :",
+		"none":                              "aucune",
+		"Temperature per core":              "Température par cœur",
+		"Clock:":                            "Horloge :",
+		"synchronized":                      "synchronisée",
+		"not synchronized":                  "non synchronisée",
+		"offset":                            "décalage",
+		"stepped %s at %s":                  "saut de %s à %s",
+		"CPU usage":                         "Charge CPU",
+		"Temperature":                       "Température",
+		"Package power":                     "Puissance du package",
+		"Memory":                            "Mémoire",
+		"PSU input":                         "Entrée alim.",
+		"%s switches pane, %s changes its graph, %s/%s zoom it, %s leaves": "%s change de volet, %s son graphique, %s/%s zoom, %s quitte",
+		"Split view: two graphs side by side (%s switches pane)":           "Vue partagée : deux graphiques côte à côte (%s change de volet)",
+		"%s halves and %s doubles the window, which always ends now":       "%s divise et %s double la période, qui se termine toujours maintenant",
+		"MARK":            "REPÈRE",
+		"A/B Comparison":  "Comparaison A/B",
+		"Range":           "Plage",
+		"B - A":           "B - A",
+		"Duration":        "Durée",
+		"Avg CPU":         "CPU moyen",
+		"Max CPU":         "CPU max",
+		"Avg temperature": "Température moy.",
+		"Max temperature": "Température max",
+		"Avg power":       "Puissance moy.",
+		"Max power":       "Puissance max",
+		"Marking range %s: %s so far, %s ends it":             "Plage %s en cours : %s jusqu'ici, %s la termine",
+		"Press %s to start a range and %s again to end it":    "%s démarre une plage, %s à nouveau la termine",
+		"A new range replaces the older of the two":           "Une nouvelle plage remplace la plus ancienne des deux",
+		"M: start/end range  SPACE: stress  A/ESC: close":     "M : début/fin de plage  ESPACE : stress  A/ESC : fermer",
+		"Start/end a bookmarked range (A and B)":              "Début/fin d'une plage repérée (A et B)",
+		"Compare bookmarked ranges A and B side by side":      "Comparer côte à côte les plages A et B",
+		"Core bars show usage or clock frequency":             "Barres des cœurs : utilisation ou fréquence",
+		"CPU Core Clocks (%d cores): avg %s, min %s, max %s":  "Fréquences des cœurs CPU (%d cœurs) : moy. %s, min %s, max %s",
+		"Multipliers (x%.0f MHz)":                             "Multiplicateurs (x%.0f MHz)",
+		"Core Clock & Throttling Graph":                       "Graphique fréquence des cœurs et bridage",
+		"THROTTLED":                                           "BRIDÉ",
+		"base clock unknown":                                  "fréquence de base inconnue",
+		"base %s":                                             "base %s",
+		"normal":                                              "normal",
+		"below base under load (%s)":                          "sous la base en charge (%s)",
+		"thermal throttling":                                  "bridage thermique",
+		"Core clock":                                          "Fréquence des cœurs",
+		"Load:":                                               "Charge :",
+		"Tasks:":                                              "Tâches :",
+		"%d running, %d blocked, %d total":                    "%d en cours, %d bloquées, %d au total",
+		"Container":                                           "Conteneur",
+		"Memory limit":                                        "Limite mémoire",
+		"of %s CPUs":                                          "de %s CPU",
+		"%s throttled":                                        "%s bridé",
+		"core bars: host":                                     "barres des cœurs : hôte",
+		"Show/hide the busiest processes under the core bars": "Afficher/masquer les processus les plus actifs sous les barres des cœurs",
+		"Top %d Processes by CPU:":                            "Top %d des processus par CPU :",
+		"PID":                                                 "PID",
+		"Command":                                             "Commande",
+		"RSS":                                                 "RSS",
+		"Disk I/O":                                            "E/S disque",
+		"Read":                                                "Lecture",
+		"Write":                                               "Écriture",
+		"IOPS":                                                "IOPS",
+		"iowait":                                              "iowait",
+		"Peak":                                                "Pic",
+		"Disk":                                                "Disque",
+		"GPUs:":                                               "GPU :",
+		"VRAM":                                                "VRAM",
+		"Idle":                                                "Repos",
+		"Ramp":                                                "Montée",
+		"Steady":                                              "Stable",
+		"Cooldown":                                            "Refroid.",
+		"Phase":                                               "Phase",
+		"Fans:":                                               "Ventilateurs :",
+		"agents":                                              "agents",
+		"sorted by":                                           "tri par",
+		"S: sort, Q: quit":                                    "S : trier, Q : quitter",
+		"Host":                                                "Hôte",
+		"Temp":                                                "Temp.",
+		"Alerts":                                              "Alertes",
+		"Now":                                                 "Actuel",
+		"Avg":                                                 "Moy.",
+		"Used":                                                "Consommé",
+		"Battery:":                                            "Batterie :",
+		"Charging":                                            "En charge",
+		"Discharging":                                         "En décharge",
+		"Full":                                                "Pleine",
+		"Not charging":                                        "Pas en charge",
+		"READ-ONLY":                                           "LECTURE SEULE",
+		"PAUSED":                                              "EN PAUSE",
+		"Pause/resume the graphs to inspect a spike": "Figer/reprendre les graphes pour examiner un pic",
+		"CPU statistics": "Statistiques CPU",
+		"%s not readable; CPU usage will read 0%%": "%s illisible ; l'utilisation CPU affichera 0 %%",
+		"%d cores": "%d cœurs",
+		"no plausible reading (%s); temperatures will read 0":  "aucune lecture plausible (%s) ; les températures afficheront 0",
+		"no CPU sensor found; choose one in the sensor picker": "aucun capteur CPU trouvé ; choisissez-en un dans le sélecteur",
+		"no sensor found; temperatures will read 0":            "aucun capteur trouvé ; les températures afficheront 0",
+		"Stress engine":  "Moteur de stress",
+		"stress command": "commande stress",
+		"stress command not found; stress testing is disabled": "commande stress introuvable ; test de stress désactivé",
+		"built-in worker did not stop":                         "le worker intégré ne s'est pas arrêté",
+		"built-in worker did not run":                          "le worker intégré n'a pas tourné",
+		"built-in engine":                                      "moteur intégré",
+		"RAPL energy":                                          "Énergie RAPL",
+		"counters are root-only; run kkperf helper as root to show power": "compteurs réservés à root ; lancez kkperf helper en root pour afficher la puissance",
+		"no counters found; power is not shown":                           "aucun compteur trouvé ; puissance non affichée",
+		"degraded":                                                        "dégradé",
+		"Startup Checks":                                                  "Vérifications au démarrage",
+		"OK":                                                              "OK",
+		"DEGRADED":                                                        "DÉGRADÉ",
+		"Press any key to continue":                                       "Appuyez sur une touche pour continuer",
+		"Reset min/max, history and statistics":                           "Réinitialiser min/max, historique et statistiques",
+		"Session reset.":                                                  "Session réinitialisée.",
+		"REMOTE":                                                          "DISTANT",
+		"UNREACHABLE":                                                     "INJOIGNABLE",
+		"ALERT:":                                                          "ALERTE :",
+		"Alert: %s":                                                       "Alerte : %s",
+		"STRESS STOPPED:":                                                 "STRESS ARRÊTÉ :",
+		"restart allowed in":                                              "redémarrage possible dans",
+		"Stress is locked for another %s after the safety stop": "Stress verrouillé encore %s après l’arrêt de sécurité",
+		"completed":                            "terminé",
+		"stopped after %s: %s":                 "arrêté après %s : %s",
+		"Timed stress run of %s, %s":           "Stress chronométré de %s, %s",
+		"Started":                              "Début",
+		"Average temperature":                  "Température moyenne",
+		"Average CPU usage":                    "Charge CPU moyenne",
+		"Time at ≥ %s":                         "Temps à ≥ %s",
+		"Throttle events":                      "Bridages",
+		"n/a":                                  "n/d",
+		"safety limit, %s":                     "limite de sécurité, %s",
+		"stopped by hand":                      "arrêté à la main",
+		"TIMED":                                "CHRONO",
+		"Timed Stress Report":                  "Rapport de stress chronométré",
+		"Timed stress run of %s with a report": "Stress chronométré de %s avec rapport",
+		"Stop the stress test to measure the idle baseline.": "Arrêtez le stress pour mesurer la référence au repos.",
+		"Measuring the idle baseline for %s.":                "Mesure de la référence au repos pendant %s.",
+		"Idle baseline: CPU %s, temperature %s.":             "Référence au repos : CPU %s, température %s.",
+		"BASELINE":                                           "RÉFÉRENCE",
+		"Above idle baseline:":                               "Au-dessus de la référence au repos :",
+		"Cores":                                              "Cœurs",
+		"Measure the idle baseline the readings are compared against": "Mesurer la référence au repos à laquelle les mesures sont comparées",
+		"EMERGENCY FAILED":         "ÉCHEC D’URGENCE",
+		"not a valid process name": "nom de processus non valide",
+		"SENSORS NOT SAVED":        "CAPTEURS NON ENREGISTRÉS",
+	},
+	"es": {
+		"Press %s for help":                   "Pulse %s para ayuda",
+		"Status:":                             "Estado:",
+		"Current:":                            "Actual:",
+		"Min:":                                "Mín:",
+		"Max:":                                "Máx:",
+		"CPU Cores (%d cores):":               "Núcleos de CPU (%d núcleos):",
+		"Temperature Legend:":                 "Leyenda de temperatura:",
+		"Cool":                                "Fresco",
+		"Normal":                              "Normal",
+		"Warm":                                "Templado",
+		"Hot":                                 "Caliente",
+		"Very Hot":                            "Muy caliente",
+		"Critical":                            "Crítico",
+		"CPU Usage & Temperature Graph":       "Uso de CPU y temperatura",
+		"Press %s to zoom in, %s to zoom out": "%s para acercar, %s para alejar",
+		"Help":                                "Ayuda",
+		"Controls:":                           "Controles:",
+		"Toggle stress test ON/OFF":           "Activar/desactivar prueba de estrés",
+		"Toggle stress test (stress command not available)":              "Prueba de estrés (comando stress no disponible)",
+		"Zoom in (shorter time scale)":                                   "Acercar (escala de tiempo más corta)",
+		"Zoom out (longer time scale)":                                   "Alejar (escala de tiempo más larga)",
+		"Toggle this help page":                                          "Mostrar/ocultar esta ayuda",
+		"Exit help or quit application":                                  "Salir de la ayuda o de la aplicación",
+		"Quit application":                                               "Salir de la aplicación",
+		"Time Scales:":                                                   "Escalas de tiempo:",
+		"CPU Core Bars:":                                                 "Barras de núcleos:",
+		"Height - CPU usage (0-100%)":                                    "Altura - Uso de CPU (0-100 %)",
+		"Color  - Core temperature (estimated where no per-core sensor)": "Color  - Temperatura del núcleo (estimada sin sensor por núcleo)",
+		"Bars:  - ▁▂▃▄▅▆▇█ (0% to 100%)":                                 "Barras - ▁▂▃▄▅▆▇█ (0 % a 100 %)",
+		"Press %s, ESC, or %s to return to main view":                    "%s, ESC o %s para volver a la vista principal",
+		"Exiting...":                                                     "Saliendo...",
+		"Kode Kronical Perf Monitor started. Press %s for help.":         "Kode Kronical Perf Monitor iniciado. Pulse %s para ayuda.",
+		"CPU %s percent, temperature %s degrees, %s":                     "CPU %s por ciento, temperatura %s grados, %s",
+		"CPU %s percent, temperature unavailable":                        "CPU %s por ciento, temperatura no disponible",
+		"rising":                    "subiendo",
+		"falling":                   "bajando",
+		"steady":                    "estable",
+		"Stress test on":            "Prueba de estrés activada",
+		"Stress test off":           "Prueba de estrés desactivada",
+		"Stress test not available": "Prueba de estrés no disponible",
+		"Keys: %s toggles the stress test, %s repeats this help, %s quits.":                    "Teclas: %s activa la prueba de estrés, %s repite esta ayuda, %s sale.",
+		"Switch core view (grid/vertical bars/heatmap)":                                        "Cambiar vista de núcleos (cuadrícula/barras verticales/mapa de calor)",
+		"Switch graph (temperature/stacked/dual-axis/sensors/power/latency/network/PSU/clock)": "Cambiar gráfico (temperatura/apilado/doble eje/sensores/potencia/latencia/red/fuente/frecuencia)",
+		"Stacked Activity Graph": "Actividad del sistema apilada",
+		"Temperature Sensors":    "Sensores de temperatura",
+		"No temperature sensors found in /sys/class/hwmon or /sys/class/thermal": "No se encontraron sensores de temperatura en /sys/class/hwmon ni en /sys/class/thermal",
+		"(automatic)":  "(automático)",
+		"Main sensor:": "Sensor principal:",
+		"J/K: move  ENTER: main sensor  SPACE: secondary  A: automatic  T/ESC: close": "J/K: mover  ENTER: sensor principal  ESPACIO: secundario  A: automático  T/ESC: cerrar",
+		"Sensors:":                   "Sensores:",
+		"Choose temperature sensors": "Elegir sensores de temperatura",
+		"raw":                        "sin corregir",
+		"Temperature Sensors Graph":  "Gráfico de sensores de temperatura",
+		"Δ to max:":                  "Δ al máximo:",
+		"Δ to TjMax:":                "Δ a TjMax:",
+		"Overclocking":               "Overclocking",
+		"CPU frequency information is not available (no cpufreq in /sys/devices/system/cpu)": "No hay información de frecuencia de CPU (sin cpufreq en /sys/devices/system/cpu)",
+		"O/ESC: close":                 "O/ESC: cerrar",
+		"off":                          "apagado",
+		"running":                      "en marcha",
+		"Driver:":                      "Controlador:",
+		"Bus clock:":                   "Reloj de bus:",
+		"Max boost:":                   "Boost máx.:",
+		"Stress:":                      "Estrés:",
+		"Boost residency (all cores):": "Tiempo en boost (todos los núcleos):",
+		"Effective clocks need read access to /dev/cpu/*/msr (run as root or start kkperf helper, with the msr module loaded)": "Las frecuencias efectivas requieren acceso de lectura a /dev/cpu/*/msr (ejecutar como root o iniciar kkperf helper, con el módulo msr cargado)",
+		"Clock":     "Frec.",
+		"Mult":      "Mult.",
+		"Effective": "Efectiva",
+		"SPACE: stress  R: reset residency  O/ESC: close":            "ESPACIO: estrés  R: reiniciar tiempo en boost  O/ESC: cerrar",
+		"Overclocking detail (clocks, multipliers, boost residency)": "Detalle de overclocking (frecuencias, multiplicadores, tiempo en boost)",
+		"Base clock:": "Frecuencia base:",
+		"Core:":       "Núcleos:",
+		"SoC:":        "SoC:",
+		"Power:":      "Potencia:",
+		"Power":       "Potencia",
+		"Power Graph": "Gráfico de potencia",
+		"RAPL power counters are not available (needs an Intel CPU and root to read energy_uj)": "Contadores RAPL no disponibles (requiere una CPU Intel y root para leer energy_uj)",
+		"resctrl memory bandwidth monitoring is not available.":                                 "La medición de ancho de banda de memoria con resctrl no está disponible.",
+		"It needs Intel RDT or AMD QoS and: mount -t resctrl resctrl /sys/fs/resctrl":           "Requiere Intel RDT o AMD QoS y: mount -t resctrl resctrl /sys/fs/resctrl",
+		"B/ESC: close":                "B/ESC: cerrar",
+		"Group":                       "Grupo",
+		"Local":                       "Local",
+		"all":                         "todos",
+		"SPACE: stress  B/ESC: close": "ESPACIO: estrés  B/ESC: cerrar",
+		"Memory bandwidth and cache occupancy per resctrl group": "Ancho de banda de memoria y ocupación de caché por grupo resctrl",
+		"Memory Bandwidth and Cache":                             "Ancho de banda de memoria y caché",
+		"no running process":                                     "ningún proceso en ejecución",
+		"(not real-time: needs root or CAP_SYS_NICE)":            "(sin tiempo real: requiere root o CAP_SYS_NICE)",
+		"Wakeup Latency Graph":                                   "Latencia de despertar",
+		"Max since start:":                                       "Máx. desde el inicio:",
+		"max":                                                    "máx.",
+		"avg":                                                    "media",
+		"iperf3 exited":                                          "iperf3 terminó",
+		"Network Stress Graph":                                   "Estrés de red",
+		"Set [iperf3] target in the config file and install iperf3 to run network stress (%s)": "Defina [iperf3] target en la configuración e instale iperf3 para el estrés de red (%s)",
+		"Throughput":                          "Rendimiento",
+		"Toggle iperf3 network stress ON/OFF": "Activar/desactivar estrés de red iperf3",
+		"Toggle fio disk stress ON/OFF":       "Activar/desactivar estrés de disco fio",
+		"Previous/next heatmap page":          "Página anterior/siguiente del mapa de calor",
+		"Page %d/%d":                          "Página %d/%d",
+		"[ ]: page":                           "[ ]: página",
+		"Core history (usage or temperature per core over time)": "Historial de núcleos (uso o temperatura por núcleo en el tiempo)",
+		"Core History":                            "Historial de núcleos",
+		"Usage per core":                          "Uso por núcleo",
+		"Estimated temperature per core":          "Temperatura estimada por núcleo",
+		"one column per %ds, newest on the right": "una columna cada %d s, la más reciente a la derecha",
+		"now": "ahora",
+		"T: usage/temperature  SPACE: stress  C/ESC: close":             "T: uso/temperatura  ESPACIO: estrés  C/ESC: cerrar",
+		"Clock against temperature or power (throttle curve)":           "Reloj según temperatura o potencia (curva de limitación)",
+		"Frequency vs Temperature":                                      "Frecuencia frente a temperatura",
+		"Frequency vs Power":                                            "Frecuencia frente a potencia",
+		"SPACE: stress  P: temperature/power  R: reset  X/ESC: close":   "ESPACIO: estrés  P: temperatura/potencia  R: reiniciar  X/ESC: cerrar",
+		"Waiting for samples with both a temperature and a core clock.": "Esperando muestras con temperatura y reloj de núcleo.",
+		"Package power is not available; plotting against temperature.": "La potencia del paquete no está disponible; se representa frente a la temperatura.",
+		"%d samples":           "%d muestras",
+		"correlation r = %.2f": "correlación r = %.2f",
+		"Mean clock (MHz):":    "Reloj medio (MHz):",
+		"Wakeups per core and process (what keeps cores out of deep idle)": "Despertares por núcleo y proceso (lo que impide el reposo profundo)",
+		"Wakeups": "Despertares",
+		"Interrupt counters are not available (cannot read /proc/interrupts).": "Los contadores de interrupciones no están disponibles (no se puede leer /proc/interrupts).",
+		"I/ESC: close":                 "I/ESC: cerrar",
+		"All cores:":                   "Todos los núcleos:",
+		"Timer:":                       "Temporizador:",
+		"Rescheduling:":                "Replanificación:",
+		"Timer/s":                      "Tempor./s",
+		"Resched/s":                    "Replan./s",
+		"All/s":                        "Total/s",
+		"Process":                      "Proceso",
+		"Wakeups/s":                    "Despert./s",
+		"SPACE: stress  I/ESC: close":  "ESPACIO: estrés  I/ESC: cerrar",
+		"Ambient:":                     "Ambiente:",
+		"Δ over ambient:":              "Δ sobre ambiente:",
+		"Loop:":                        "Circuito:",
+		"Pump failure: %s at %.0f RPM": "Fallo de bomba: %s a %.0f RPM",
+		"Low flow: %s at %.0f L/h":     "Caudal bajo: %s a %.0f L/h",
+		"Coolant too hot: %s at %s":    "Refrigerante demasiado caliente: %s a %s",
+		"%.0f W in":                    "%.0f W entrada",
+		"%.0f W out":                   "%.0f W salida",
+		"%s efficiency":                "eficiencia %s",
+		"PSU:":                         "Fuente:",
+		"PSU Power Graph":              "Potencia de la fuente",
+		"No power supply with a hwmon driver found (corsair-psu or PMBus)": "No se encontró una fuente con controlador hwmon (corsair-psu o PMBus)",
+		"Input":                                  "Entrada",
+		"Output":                                 "Salida",
+		"Stress stopped by the safety limit: %s": "Estrés detenido por el límite de seguridad: %s",
+		"By user":                                "Por usuario",
+		"By cgroup":                              "Por cgroup",
+		"User":                                   "Usuario",
+		"No CPU time used":                       "Sin tiempo de CPU usado",
+		"SPACE: stress  U/ESC: close":            "ESPACIO: estrés  U/ESC: cerrar",
+		"CPU by user, cgroup and priority (who is behind the load)": "CPU por usuario, cgroup y prioridad (quién genera la carga)",
+		"CPU Attribution":                   "Reparto de CPU",
+		"By priority":                       "Por prioridad",
+		"Class":                             "Clase",
+		"Real-time (FIFO/RR/deadline)":      "Tiempo real (FIFO/RR/deadline)",
+		"Raised (nice < 0)":                 "Elevada (nice < 0)",
+		"Low (nice > 0)":                    "Baja (nice > 0)",
+		"Batch/idle policy":                 "Política batch/idle",
+		"I/O wait:":                         "Espera de E/S:",
+		"D state:":                          "Estado D:",
+		"Swap":                              "Intercambio",
+		"Health:":                           "Salud:",
+		"Zombies":                           "Zombis",
+		"Threads":                           "Hilos",
+		"Open files":                        "Archivos abiertos",
+		"zombie processes: %d":              "procesos zombi: %d",
+		"threads at %.0f%% of the limit":    "hilos al %.0f %% del límite",
+		"open files at %.0f%% of the limit": "archivos abiertos al %.0f %% del límite",
+		"Entropy:":                          "Entropía:",
+		"Jitter entropy:":                   "Entropía de jitter:",
+		"none":                              "ninguna",
+		"Temperature per core":              "Temperatura por núcleo",
+		"Clock:":                            "Reloj:",
+		"synchronized":                      "sincronizado",
+		"not synchronized":                  "no sincronizado",
+		"offset":                            "desfase",
+		"stepped %s at %s":                  "salto de %s a las %s",
+		"CPU usage":                         "Uso de CPU",
+		"Temperature":                       "Temperatura",
+		"Package power":                     "Potencia del paquete",
+		"Memory":                            "Memoria",
+		"PSU input":                         "Entrada de la PSU",
+		"%s switches pane, %s changes its graph, %s/%s zoom it, %s leaves": "%s cambia de panel, %s su gráfico, %s/%s zoom, %s sale",
+		"Split view: two graphs side by side (%s switches pane)":           "Vista dividida: dos gráficos lado a lado (%s cambia de panel)",
+		"%s halves and %s doubles the window, which always ends now":       "%s divide a la mitad y %s duplica el periodo, que siempre termina ahora",
+		"MARK":            "MARCA",
+		"A/B Comparison":  "Comparación A/B",
+		"Range":           "Rango",
+		"B - A":           "B - A",
+		"Duration":        "Duración",
+		"Avg CPU":         "CPU media",
+		"Max CPU":         "CPU máx.",
+		"Avg temperature": "Temperatura media",
+		"Max temperature": "Temperatura máx.",
+		"Avg power":       "Potencia media",
+		"Max power":       "Potencia máx.",
+		"Marking range %s: %s so far, %s ends it":             "Marcando rango %s: %s hasta ahora, %s lo termina",
+		"Press %s to start a range and %s again to end it":    "%s inicia un rango y %s de nuevo lo termina",
+		"A new range replaces the older of the two":           "Un rango nuevo reemplaza al más antiguo de los dos",
+		"M: start/end range  SPACE: stress  A/ESC: close":     "M: iniciar/terminar rango  ESPACIO: estrés  A/ESC: cerrar",
+		"Start/end a bookmarked range (A and B)":              "Iniciar/terminar un rango marcado (A y B)",
+		"Compare bookmarked ranges A and B side by side":      "Comparar los rangos A y B lado a lado",
+		"Core bars show usage or clock frequency":             "Barras de núcleos: uso o frecuencia de reloj",
+		"CPU Core Clocks (%d cores): avg %s, min %s, max %s":  "Frecuencias de núcleos CPU (%d núcleos): media %s, mín %s, máx %s",
+		"Multipliers (x%.0f MHz)":                             "Multiplicadores (x%.0f MHz)",
+		"Core Clock & Throttling Graph":                       "Gráfico de frecuencia de núcleos y limitación",
+		"THROTTLED":                                           "LIMITADO",
+		"base clock unknown":                                  "frecuencia base desconocida",
+		"base %s":                                             "base %s",
+		"normal":                                              "normal",
+		"below base under load (%s)":                          "bajo la base con carga (%s)",
+		"thermal throttling":                                  "limitación térmica",
+		"Core clock":                                          "Frecuencia de núcleos",
+		"Load:":                                               "Carga:",
+		"Tasks:":                                              "Tareas:",
+		"%d running, %d blocked, %d total":                    "%d en ejecución, %d bloqueadas, %d en total",
+		"Container":                                           "Contenedor",
+		"Memory limit":                                        "Límite de memoria",
+		"of %s CPUs":                                          "de %s CPU",
+		"%s throttled":                                        "%s limitado",
+		"core bars: host":                                     "barras de núcleos: host",
+		"Show/hide the busiest processes under the core bars": "Mostrar/ocultar los procesos más activos bajo las barras de núcleos",
+		"Top %d Processes by CPU:":                            "Top %d procesos por CPU:",
+		"PID":                                                 "PID",
+		"Command":                                             "Comando",
+		"RSS":                                                 "RSS",
+		"Disk I/O":                                            "E/S de disco",
+		"Read":                                                "Lectura",
+		"Write":                                               "Escritura",
+		"IOPS":                                                "IOPS",
+		"iowait":                                              "iowait",
+		"Peak":                                                "Pico",
+		"Disk":                                                "Disco",
+		"GPUs:":                                               "GPU:",
+		"VRAM":                                                "VRAM",
+		"Idle":                                                "Reposo",
+		"Ramp":                                                "Subida",
+		"Steady":                                              "Estable",
+		"Cooldown":                                            "Enfriado",
+		"Phase":                                               "Fase",
+		"Fans:":                                               "Ventiladores:",
+		"agents":                                              "agentes",
+		"sorted by":                                           "ordenado por",
+		"S: sort, Q: quit":                                    "S: ordenar, Q: salir",
+		"Host":                                                "Host",
+		"Temp":                                                "Temp.",
+		"Alerts":                                              "Alertas",
+		"Now":                                                 "Ahora",
+		"Avg":                                                 "Media",
+		"Used":                                                "Consumido",
+		"Battery:":                                            "Batería:",
+		"Charging":                                            "Cargando",
+		"Discharging":                                         "Descargando",
+		"Full":                                                "Llena",
+		"Not charging":                                        "Sin cargar",
+		"READ-ONLY":                                           "SOLO LECTURA",
+		"PAUSED":                                              "EN PAUSA",
+		"Pause/resume the graphs to inspect a spike": "Pausar/reanudar los gráficos para examinar un pico",
+		"CPU statistics": "Estadísticas de CPU",
+		"%s not readable; CPU usage will read 0%%": "%s no legible; el uso de CPU mostrará 0%%",
+		"%d cores": "%d núcleos",
+		"no plausible reading (%s); temperatures will read 0":  "ninguna lectura plausible (%s); las temperaturas mostrarán 0",
+		"no CPU sensor found; choose one in the sensor picker": "no se encontró sensor de CPU; elija uno en el selector",
+		"no sensor found; temperatures will read 0":            "no se encontró sensor; las temperaturas mostrarán 0",
+		"Stress engine":  "Motor de estrés",
+		"stress command": "comando stress",
+		"stress command not found; stress testing is disabled": "comando stress no encontrado; prueba de estrés desactivada",
+		"built-in worker did not stop":                         "el worker integrado no se detuvo",
+		"built-in worker did not run":                          "el worker integrado no se ejecutó",
+		"built-in engine":                                      "motor integrado",
+		"RAPL energy":                                          "Energía RAPL",
+		"counters are root-only; run kkperf helper as root to show power": "contadores solo para root; ejecute kkperf helper como root para mostrar la potencia",
+		"no counters found; power is not shown":                           "no se encontraron contadores; no se muestra la potencia",
+		"degraded":                                                        "degradado",
+		"Startup Checks":                                                  "Comprobaciones de inicio",
+		"OK":                                                              "OK",
+		"DEGRADED":                                                        "DEGRADADO",
+		"Press any key to continue":                                       "Pulse una tecla para continuar",
+		"Reset min/max, history and statistics":                           "Restablecer mín./máx., historial y estadísticas",
+		"Session reset.":                                                  "Sesión restablecida.",
+		"REMOTE":                                                          "REMOTO",
+		"UNREACHABLE":                                                     "INALCANZABLE",
+		"ALERT:":                                                          "ALERTA:",
+		"Alert: %s":                                                       "Alerta: %s",
+		"STRESS STOPPED:":                                                 "ESTRÉS DETENIDO:",
+		"restart allowed in":                                              "reinicio posible en",
+		"Stress is locked for another %s after the safety stop": "Estrés bloqueado %s más tras la parada de seguridad",
+		"completed":                            "completado",
+		"stopped after %s: %s":                 "detenido tras %s: %s",
+		"Timed stress run of %s, %s":           "Estrés cronometrado de %s, %s",
+		"Started":                              "Inicio",
+		"Average temperature":                  "Temperatura media",
+		"Average CPU usage":                    "Uso medio de CPU",
+		"Time at ≥ %s":                         "Tiempo a ≥ %s",
+		"Throttle events":                      "Limitaciones térmicas",
+		"n/a":                                  "n/d",
+		"safety limit, %s":                     "límite de seguridad, %s",
+		"stopped by hand":                      "detenido a mano",
+		"TIMED":                                "CRONO",
+		"Timed Stress Report":                  "Informe de estrés cronometrado",
+		"Timed stress run of %s with a report": "Estrés cronometrado de %s con informe",
+		"Stop the stress test to measure the idle baseline.": "Detén el estrés para medir la referencia en reposo.",
+		"Measuring the idle baseline for %s.":                "Midiendo la referencia en reposo durante %s.",
+		"Idle baseline: CPU %s, temperature %s.":             "Referencia en reposo: CPU %s, temperatura %s.",
+		"BASELINE":                                           "REFERENCIA",
+		"Above idle baseline:":                               "Sobre la referencia en reposo:",
+		"Cores":                                              "Núcleos",
+		"Measure the idle baseline the readings are compared against": "Medir la referencia en reposo con la que se comparan las lecturas",
+		"EMERGENCY FAILED":         "FALLO DE EMERGENCIA",
+		"not a valid process name": "nombre de proceso no válido",
+		"SENSORS NOT SAVED":        "SENSORES NO GUARDADOS",
+	},
+}
@@ -0,0 +1,148 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	memBarWidth   = 16 // Width of the RAM and swap usage bars
+	memGraphRows  = 3  // Height of the memory history graph
+	bytesPerGiB   = 1 << 30
+	kibibyteBytes = 1024
+)
+
+// memInfo is one reading of /proc/meminfo, in bytes.
+type memInfo struct {
+	total, used         uint64
+	swapTotal, swapUsed uint64
+}
+
+// readMemInfo parses /proc/meminfo. Used memory is what is not available
+// to new programs, so the page cache does not count; kernels before 3.14
+// lack MemAvailable and fall back to free, buffers and cache. A zero total
+// means the file could not be read.
+func readMemInfo() memInfo {
+	var mi memInfo
+	f, err := os.Open(filepath.Join(procDir, "meminfo"))
+	if err != nil {
+		return mi
+	}
+	defer f.Close()
+
+	values := map[string]uint64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		values[strings.TrimSuffix(fields[0], ":")] = v * kibibyteBytes
+	}
+
+	mi.total = values["MemTotal"]
+	available, ok := values["MemAvailable"]
+	if !ok {
+		available = values["MemFree"] + values["Buffers"] + values["Cached"]
+	}
+	if available < mi.total {
+		mi.used = mi.total - available
+	}
+	mi.swapTotal = values["SwapTotal"]
+	if free := values["SwapFree"]; free < mi.swapTotal {
+		mi.swapUsed = mi.swapTotal - free
+	}
+	return mi
+}
+
+// ramPercent returns the share of RAM in use (0-100%).
+func (mi memInfo) ramPercent() float64 {
+	if mi.total == 0 {
+		return 0
+	}
+	return float64(mi.used) / float64(mi.total) * 100
+}
+
+// swapPercent returns the share of swap in use (0-100%), or -1 when the
+// machine has no swap.
+func (mi memInfo) swapPercent() float64 {
+	if mi.swapTotal == 0 {
+		return -1
+	}
+	return float64(mi.swapUsed) / float64(mi.swapTotal) * 100
+}
+
+// memBar renders a usage bar of memBarWidth cells followed by the share
+// and amount in use, e.g. "■■■■■■·········· 38.2% 6.0/15.6 GiB".
+func memBar(percent float64, used, total uint64) string {
+	filled := int(percent/100*memBarWidth + 0.5)
+	if filled > memBarWidth {
+		filled = memBarWidth
+	}
+	return fmt.Sprintf("%s%s%s%s %s %s/%s GiB", getUsageColor(percent), strings.Repeat("■", filled), colorReset,
+		strings.Repeat("·", memBarWidth-filled), formatPercent(percent, 1),
+		formatNumber(float64(used)/bytesPerGiB, 1), formatNumber(float64(total)/bytesPerGiB, 1))
+}
+
+// drawMemoryGraph draws the RAM and swap usage bars and, under them, RAM
+// usage over time as filled bars with swap usage as ° points, column for
+// column with the CPU graph above so spikes in one can be matched to the
+// other. It draws nothing when /proc/meminfo cannot be read.
+func (m *Monitor) drawMemoryGraph() {
+	mi := m.mem
+	if mi.total == 0 {
+		return
+	}
+	fmt.Fprintf(m.out, "%s%s%s %s", colorBlue, tr("RAM"), colorReset, memBar(mi.ramPercent(), mi.used, mi.total))
+	if mi.swapTotal > 0 {
+		fmt.Fprintf(m.out, "  %s%s%s %s", colorBlue, tr("Swap"), colorReset, memBar(mi.swapPercent(), mi.swapUsed, mi.swapTotal))
+	}
+	fmt.Fprint(m.out, "\r\n")
+
+	ramValues := make([]float64, baseGraphWidth)
+	ramColors := make([]string, baseGraphWidth)
+	swapValues := make([]float64, baseGraphWidth)
+	swapColors := make([]string, baseGraphWidth)
+	for i, p := range m.displayBuffer {
+		// Columns from before the first reading stay empty
+		ramValues[i], swapValues[i] = -1, -1
+		if p.mem > 0 {
+			ramValues[i] = p.mem / 100
+			ramColors[i] = getUsageColor(p.mem)
+		}
+		if p.swap > 0 {
+			swapValues[i] = p.swap / 100
+			swapColors[i] = colorMagenta
+		}
+	}
+	ramGrid := plotSeries(ramValues, ramColors, memGraphRows, styleFilled)
+	swapGrid := plotSeries(swapValues, swapColors, memGraphRows, stylePoints)
+
+	ranges := []string{"67-100%", "34-66% ", "0-33%  "}
+	for row := memGraphRows - 1; row >= 0; row-- {
+		fmt.Fprintf(m.out, "%s%s%s", colorCyan, ranges[memGraphRows-1-row], colorReset)
+		for i := 0; i < baseGraphWidth; i++ {
+			bar, dot := ramGrid[row][i], swapGrid[row][i]
+			switch {
+			case dot.glyph != "" && bar.glyph == "█":
+				// Swap over a solid bar: keep the bar visible as the background
+				fmt.Fprintf(m.out, "%s%s%s%s", colorToBackground(bar.color), dot.color, dot.glyph, colorReset)
+			case dot.glyph != "":
+				fmt.Fprintf(m.out, "%s%s%s", dot.color, dot.glyph, colorReset)
+			case bar.glyph != "":
+				fmt.Fprintf(m.out, "%s%s%s", bar.color, bar.glyph, colorReset)
+			default:
+				fmt.Fprint(m.out, " ")
+			}
+		}
+		fmt.Fprint(m.out, "\r\n")
+	}
+}
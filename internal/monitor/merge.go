@@ -0,0 +1,426 @@
+package monitor
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mergeSteps are the row intervals merge picks from when --step is not
+// given.
+var mergeSteps = []time.Duration{time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+	time.Minute, 5 * time.Minute, 15 * time.Minute, time.Hour, 6 * time.Hour, 24 * time.Hour}
+
+// mergeRows is how many timeline rows the automatic step aims for.
+const mergeRows = 60
+
+// mergeMaxRows caps the timeline a --step may ask for; every row takes a
+// bucket pointer per machine.
+const mergeMaxRows = 1000000
+
+// mergeMachine is one machine of a merged recording.
+type mergeMachine struct {
+	name  string
+	in    *diffInput
+	shift time.Duration // Added to its timestamps, for a clock that was off
+}
+
+// mergeBucket aggregates the records of one machine within one row of
+// the timeline.
+type mergeBucket struct {
+	polls             int
+	cpuSum, cpuMax    float64
+	tempSum, tempMax  float64
+	tempPolls         int
+	throttled         int
+	stress            bool
+	readSum, writeSum float64
+}
+
+// add adds a record, weighted by its polls.
+func (b *mergeBucket) add(r historyRecord) {
+	n := float64(r.Samples)
+	b.polls += r.Samples
+	b.cpuSum += r.CPUAvg * n
+	if r.CPUMax > b.cpuMax {
+		b.cpuMax = r.CPUMax
+	}
+	if r.TempMax > 0 {
+		b.tempSum += r.TempAvg * n
+		b.tempPolls += r.Samples
+		if r.TempMax > b.tempMax {
+			b.tempMax = r.TempMax
+		}
+	}
+	b.throttled += r.Throttled
+	b.stress = b.stress || r.Stress
+	b.readSum += r.DiskRead * n
+	b.writeSum += r.DiskWrite * n
+}
+
+// mergeShifts collects the --shift NAME=DURATION options.
+type mergeShifts map[string]time.Duration
+
+// String implements flag.Value.
+func (s mergeShifts) String() string { return "" }
+
+// Set implements flag.Value.
+func (s mergeShifts) Set(v string) error {
+	i := strings.Index(v, "=")
+	if i <= 0 {
+		return fmt.Errorf("--shift takes NAME=DURATION, e.g. server=-1.5s")
+	}
+	d, err := time.ParseDuration(v[i+1:])
+	if err != nil {
+		return fmt.Errorf("--shift %s: %v", v, err)
+	}
+	s[v[:i]] = d
+	return nil
+}
+
+// runMerge implements the "merge" subcommand: it lines up the recordings
+// of several machines, such as the client and the server of a distributed
+// load test, on their wall-clock timestamps and prints them as one
+// timeline, or as one CSV dataset.
+func runMerge(args []string) int {
+	path := configPath()
+	var step time.Duration
+	csvOut := false
+	shifts := mergeShifts{}
+
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&path, "c", path, "")
+	fs.StringVar(&path, "config", path, "")
+	fs.DurationVar(&step, "step", 0, "")
+	fs.BoolVar(&csvOut, "csv", false, "")
+	fs.Var(shifts, "shift", "")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Println(mergeUsage)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if fs.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "Error: merge takes two or more files, e.g. kkperf merge client=client.json server=server.json")
+		return 1
+	}
+	if step < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --step must not be negative")
+		return 1
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	activeLocale = resolveLocale(cfg)
+
+	machines, err := readMergeInputs(fs.Args(), shifts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if step == 0 {
+		step = mergeStep(machines)
+	} else if err := checkMergeStep(machines, step); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if csvOut {
+		err = writeMergeCSV(os.Stdout, machines, step)
+	} else {
+		_, err = io.WriteString(os.Stdout, formatMerge(machines, step))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// readMergeInputs reads the [NAME=]FILE arguments of merge. A machine is
+// named after its file unless named explicitly; the names must differ,
+// and every --shift must name one of them.
+func readMergeInputs(args []string, shifts mergeShifts) ([]*mergeMachine, error) {
+	var machines []*mergeMachine
+	seen := map[string]bool{}
+	for _, arg := range args {
+		name, file := "", arg
+		if i := strings.Index(arg, "="); i > 0 {
+			name, file = arg[:i], arg[i+1:]
+		} else {
+			name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("two files are named %s; name them, e.g. client=%s", name, file)
+		}
+		seen[name] = true
+		in, err := readDiffInput(file)
+		if err != nil {
+			return nil, err
+		}
+		machines = append(machines, &mergeMachine{name: name, in: in, shift: shifts[name]})
+	}
+	for name := range shifts {
+		if !seen[name] {
+			return nil, fmt.Errorf("--shift %s: no file is named %s", name, name)
+		}
+	}
+	return machines, nil
+}
+
+// span returns the first and last timestamp of a machine, shifted.
+func (m *mergeMachine) span() (from, to time.Time) {
+	records := m.in.records
+	return records[0].Time.Add(m.shift), records[len(records)-1].Time.Add(m.shift)
+}
+
+// mergeSpan returns the time covered by any machine and the time covered
+// by all of them; the overlap is empty when to is before from.
+func mergeSpan(machines []*mergeMachine) (from, to, overlapFrom, overlapTo time.Time) {
+	for i, m := range machines {
+		f, t := m.span()
+		if i == 0 || f.Before(from) {
+			from = f
+		}
+		if i == 0 || t.After(to) {
+			to = t
+		}
+		if i == 0 || f.After(overlapFrom) {
+			overlapFrom = f
+		}
+		if i == 0 || t.Before(overlapTo) {
+			overlapTo = t
+		}
+	}
+	return from, to, overlapFrom, overlapTo
+}
+
+// mergeInterval returns the finest row interval every machine has a
+// record for: the largest of their recording intervals, or 0 when no
+// recording has two records.
+func mergeInterval(machines []*mergeMachine) time.Duration {
+	var finest time.Duration
+	for _, m := range machines {
+		var gap time.Duration
+		for i := 1; i < len(m.in.records); i++ {
+			if d := m.in.records[i].Time.Sub(m.in.records[i-1].Time); d > 0 && (gap == 0 || d < gap) {
+				gap = d
+			}
+		}
+		if gap > finest {
+			finest = gap
+		}
+	}
+	return finest
+}
+
+// checkMergeStep rejects a --step finer than the recordings, which only
+// adds empty rows, or one that makes more than mergeMaxRows rows.
+func checkMergeStep(machines []*mergeMachine, step time.Duration) error {
+	if finest := mergeInterval(machines); step < finest {
+		return fmt.Errorf("--step %s is finer than the recording interval of %s", step, finest)
+	}
+	from, to, _, _ := mergeSpan(machines)
+	if rows := to.Sub(from.Truncate(step)) / step; rows >= mergeMaxRows {
+		return fmt.Errorf("--step %s makes more than %d rows; use a coarser step", step, mergeMaxRows)
+	}
+	return nil
+}
+
+// mergeStep picks the row interval: the first of mergeSteps no finer than
+// any machine's recording interval that fits the timeline in about
+// mergeRows rows.
+func mergeStep(machines []*mergeMachine) time.Duration {
+	finest := mergeInterval(machines)
+	from, to, _, _ := mergeSpan(machines)
+	for _, step := range mergeSteps {
+		if step >= finest && to.Sub(from)/step < mergeRows {
+			return step
+		}
+	}
+	return mergeSteps[len(mergeSteps)-1]
+}
+
+// mergeBuckets groups the records of every machine into rows of step,
+// aligned to the wall clock. It returns the row times and, per machine,
+// the bucket of each row, nil where the machine has no record.
+func mergeBuckets(machines []*mergeMachine, step time.Duration) ([]time.Time, [][]*mergeBucket) {
+	from, to, _, _ := mergeSpan(machines)
+	start := from.Truncate(step)
+	rows := int(to.Sub(start)/step) + 1
+	times := make([]time.Time, rows)
+	for i := range times {
+		times[i] = start.Add(time.Duration(i) * step)
+	}
+	buckets := make([][]*mergeBucket, len(machines))
+	for i, m := range machines {
+		buckets[i] = make([]*mergeBucket, rows)
+		for _, r := range m.in.records {
+			row := int(r.Time.Add(m.shift).Sub(start) / step)
+			if buckets[i][row] == nil {
+				buckets[i][row] = &mergeBucket{}
+			}
+			buckets[i][row].add(r)
+		}
+	}
+	return times, buckets
+}
+
+// formatMerge renders the merged recording as a plain-text report: the
+// machines and the window they overlap in, a summary of each over that
+// window, and the timeline with a column pair per machine. Rows without
+// any record are left out.
+func formatMerge(machines []*mergeMachine, step time.Duration) string {
+	var out strings.Builder
+	const stamp = "2006-01-02 15:04:05"
+	fmt.Fprintf(&out, "Kode Kronical Perf Monitor merge\n")
+	for _, m := range machines {
+		from, to := m.span()
+		polls := 0
+		for _, r := range m.in.records {
+			polls += r.Samples
+		}
+		shift := ""
+		if m.shift != 0 {
+			shift = fmt.Sprintf(", shifted %s", m.shift)
+		}
+		fmt.Fprintf(&out, "%s: %s, %s to %s, %d polls%s\n", m.name, m.in.path, from.Format(stamp), to.Format(stamp), polls, shift)
+	}
+
+	_, _, overlapFrom, overlapTo := mergeSpan(machines)
+	if overlapTo.Before(overlapFrom) {
+		out.WriteString("Overlap: none; the recordings do not share any time\n")
+	} else {
+		fmt.Fprintf(&out, "Overlap: %s to %s (%s)\n\n", overlapFrom.Format(stamp), overlapTo.Format(stamp), overlapTo.Sub(overlapFrom))
+		fmt.Fprintf(&out, "%s%s%s%s%s%s\n", padRight("", 16), padRight("CPU avg", 10), padRight("CPU max", 10),
+			padRight("Temp avg", 10), padRight("Temp max", 10), "Throttle events")
+		for _, m := range machines {
+			var window []historyRecord
+			for _, r := range m.in.records {
+				if t := r.Time.Add(m.shift); !t.Before(overlapFrom) && !t.After(overlapTo) {
+					window = append(window, r)
+				}
+			}
+			sum := summarizeHistory(window, overlapFrom, overlapTo, 0)
+			temp, tempMax := "-", "-"
+			if sum.tempMax > 0 {
+				temp, tempMax = unitCelsius.format(sum.tempAvg, 1), unitCelsius.format(sum.tempMax, 1)
+			}
+			fmt.Fprintf(&out, "%s%s%s%s%s%d\n", padRight(m.name, 16), padRight(unitPercent.format(sum.cpuAvg, 1), 10),
+				padRight(unitPercent.format(sum.cpuMax, 1), 10), padRight(temp, 10), padRight(tempMax, 10), sum.throttled)
+		}
+	}
+
+	names, columns := padRight("", 10), padRight("", 10)
+	for _, m := range machines {
+		names += padRight(m.name, 20)
+		columns += padRight("CPU", 10) + padRight("Temp", 10)
+	}
+	fmt.Fprintf(&out, "\nTimeline, %s per row; * marks a running stress test\n%s\n%s\n", step,
+		strings.TrimRight(names, " "), strings.TrimRight(columns, " "))
+	layout := "15:04:05"
+	if step >= 24*time.Hour {
+		layout = "2006-01-02"
+	}
+	times, buckets := mergeBuckets(machines, step)
+	for row, t := range times {
+		var line strings.Builder
+		found := false
+		for i := range machines {
+			b := buckets[i][row]
+			if b == nil {
+				line.WriteString(padRight("-", 10) + padRight("-", 10))
+				continue
+			}
+			found = true
+			cpu := unitPercent.format(b.cpuSum/float64(b.polls), 1)
+			if b.stress {
+				cpu += "*"
+			}
+			temp := "-"
+			if b.tempPolls > 0 {
+				temp = unitCelsius.format(b.tempSum/float64(b.tempPolls), 1)
+			}
+			line.WriteString(padRight(cpu, 10) + padRight(temp, 10))
+		}
+		if found {
+			fmt.Fprintf(&out, "%s%s\n", padRight(t.Format(layout), 10), strings.TrimRight(line.String(), " "))
+		}
+	}
+	return out.String()
+}
+
+// writeMergeCSV writes the merged recording as CSV: a row per step with
+// the wall-clock time and, per machine, the mean and peak CPU usage and
+// temperature, the throttled polls, the stress state and the disk
+// throughput. The cells of a machine without a record in a row are empty.
+func writeMergeCSV(w io.Writer, machines []*mergeMachine, step time.Duration) error {
+	c := csv.NewWriter(w)
+	header := []string{"time"}
+	for _, m := range machines {
+		for _, col := range []string{"cpu_avg", "cpu_max", "temp_avg_c", "temp_max_c", "throttled", "stress", "disk_read_bytes_per_sec", "disk_write_bytes_per_sec"} {
+			header = append(header, m.name+"_"+col)
+		}
+	}
+	c.Write(header)
+
+	number := func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) }
+	times, buckets := mergeBuckets(machines, step)
+	for row, t := range times {
+		record := []string{t.UTC().Format(time.RFC3339Nano)}
+		found := false
+		for i := range machines {
+			b := buckets[i][row]
+			if b == nil {
+				record = append(record, "", "", "", "", "", "", "", "")
+				continue
+			}
+			found = true
+			n := float64(b.polls)
+			temp, tempMax := "", ""
+			if b.tempPolls > 0 {
+				temp, tempMax = number(b.tempSum/float64(b.tempPolls)), number(b.tempMax)
+			}
+			stress := "0"
+			if b.stress {
+				stress = "1"
+			}
+			record = append(record, number(b.cpuSum/n), number(b.cpuMax), temp, tempMax, strconv.Itoa(b.throttled), stress,
+				strconv.FormatFloat(b.readSum/n, 'f', 0, 64), strconv.FormatFloat(b.writeSum/n, 'f', 0, 64))
+		}
+		if found {
+			c.Write(record)
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// mergeUsage documents the merge subcommand.
+const mergeUsage = `Usage: kkperf merge [options] [NAME=]FILE [NAME=]FILE...
+
+Line up the recordings of several machines, such as the client and the
+server of a distributed load test, on their wall-clock timestamps and
+print them as one timeline. The files are history store files
+(YYYY-MM-DD.jsonl), kkperf --json streams, or snapshots saved with
+kkperf --format '{{json .}}'. Each machine is named after its file unless
+NAME= is given.
+
+Options:
+  --step DURATION      Time per row, e.g. 10s, no finer than the recordings; picked to fit about 60 rows by default
+  --shift NAME=DUR     Add DUR to the timestamps of NAME, for a clock that was off; repeatable
+  --csv                Print the merged dataset as CSV instead of the report
+  -c, --config PATH    Use an alternate config file
+
+Options go before the files.`
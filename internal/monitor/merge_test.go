@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMerge checks that the recordings of two machines are lined up on
+// their timestamps, with a shift for a clock that was off, and that rows
+// where a machine has no record leave its cells empty.
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	write := func(name string, from time.Duration, polls int, cpu float64) string {
+		var b strings.Builder
+		for i := 0; i < polls; i++ {
+			data, _ := json.Marshal(Sample{Time: start.Add(from + time.Duration(i)*time.Second), CPU: cpu, Temp: cpu / 2})
+			b.Write(append(data, '\n'))
+		}
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(b.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	client := write("client.json", 0, 20, 40)
+	server := write("server.json", 12*time.Second, 20, 80) // Its clock was 2s ahead
+
+	machines, err := readMergeInputs([]string{client, "srv=" + server}, mergeShifts{"srv": -2 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step := mergeStep(machines); step != time.Second {
+		t.Errorf("step %s, want 1s", step)
+	}
+	for _, tc := range []struct {
+		step time.Duration
+		ok   bool
+	}{
+		{time.Second, true},
+		{time.Hour, true},
+		{time.Millisecond, false}, // Finer than the recordings
+		{time.Nanosecond, false},
+	} {
+		if err := checkMergeStep(machines, tc.step); (err == nil) != tc.ok {
+			t.Errorf("--step %s: %v; want valid %v", tc.step, err, tc.ok)
+		}
+	}
+	// One record a day apart: no interval to compare with, but too many rows
+	day := []*mergeMachine{
+		{name: "a", in: &diffInput{records: []historyRecord{{Time: start, Samples: 1}}}},
+		{name: "b", in: &diffInput{records: []historyRecord{{Time: start.Add(24 * time.Hour), Samples: 1}}}},
+	}
+	if err := checkMergeStep(day, time.Millisecond); err == nil {
+		t.Error("--step 1ms over a day accepted")
+	}
+	var out bytes.Buffer
+	if err := writeMergeCSV(&out, machines, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	rows := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{
+		"2025-10-01T12:00:00Z,40.0,40.0,20.0,20.0,0,0,0,0,,,,,,,,",
+		"2025-10-01T12:00:10Z,40.0,40.0,20.0,20.0,0,0,0,0,80.0,80.0,40.0,40.0,0,0,0,0",
+		"2025-10-01T12:00:20Z,,,,,,,,,80.0,80.0,40.0,40.0,0,0,0,0",
+	}
+	if !strings.HasPrefix(rows[0], "time,client_cpu_avg,") || !strings.Contains(rows[0], ",srv_cpu_avg,") {
+		t.Errorf("header %s", rows[0])
+	}
+	if strings.Join(rows[1:], "\n") != strings.Join(want, "\n") {
+		t.Errorf("rows\n%s\nwant\n%s", strings.Join(rows[1:], "\n"), strings.Join(want, "\n"))
+	}
+	if !strings.Contains(formatMerge(machines, 10*time.Second), "Overlap: 2025-10-01 12:00:10 to 2025-10-01 12:00:19 (9s)") {
+		t.Errorf("report:\n%s", formatMerge(machines, 10*time.Second))
+	}
+}
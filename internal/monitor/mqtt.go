@@ -0,0 +1,145 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// mqttClient is a minimal MQTT 3.1.1 publisher: it connects, publishes
+// QoS 0 messages, and disconnects. That is all the exporters need, so no
+// subscriptions or acknowledged delivery are implemented.
+type mqttClient struct {
+	conn net.Conn
+}
+
+// mqttConnectOptions holds the CONNECT packet fields.
+type mqttConnectOptions struct {
+	clientID    string
+	username    string
+	password    string
+	keepAlive   time.Duration
+	willTopic   string // Last will, published by the broker if the connection drops
+	willMessage string
+	willRetain  bool
+}
+
+// mqttDial connects to broker ("host[:port]", port 1883 by default) and
+// completes the MQTT handshake.
+func mqttDial(broker string, opts mqttConnectOptions) (*mqttClient, error) {
+	if _, _, err := net.SplitHostPort(broker); err != nil {
+		broker = net.JoinHostPort(broker, "1883")
+	}
+	conn, err := net.DialTimeout("tcp", broker, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	mqttWriteString(&body, "MQTT")
+	body.WriteByte(4) // Protocol level 3.1.1
+
+	flags := byte(0x02) // Clean session
+	if opts.willTopic != "" {
+		flags |= 0x04
+		if opts.willRetain {
+			flags |= 0x20
+		}
+	}
+	if opts.username != "" {
+		flags |= 0x80
+		if opts.password != "" {
+			flags |= 0x40
+		}
+	}
+	body.WriteByte(flags)
+	binary.Write(&body, binary.BigEndian, uint16(opts.keepAlive/time.Second))
+
+	mqttWriteString(&body, opts.clientID)
+	if opts.willTopic != "" {
+		mqttWriteString(&body, opts.willTopic)
+		mqttWriteString(&body, opts.willMessage)
+	}
+	if opts.username != "" {
+		mqttWriteString(&body, opts.username)
+		if opts.password != "" {
+			mqttWriteString(&body, opts.password)
+		}
+	}
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if err := mqttWritePacket(conn, 0x10, body.Bytes()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// CONNACK: 0x20, length 2, session present, return code
+	ack := make([]byte, 4)
+	if _, err := io.ReadFull(bufio.NewReader(conn), ack); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if ack[0] != 0x20 {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected reply from broker")
+	}
+	if ack[3] != 0 {
+		conn.Close()
+		return nil, fmt.Errorf("connection refused by broker (code %d)", ack[3])
+	}
+	conn.SetDeadline(time.Time{})
+
+	return &mqttClient{conn: conn}, nil
+}
+
+// publish sends a QoS 0 message.
+func (c *mqttClient) publish(topic string, payload []byte, retain bool) error {
+	var body bytes.Buffer
+	mqttWriteString(&body, topic)
+	body.Write(payload)
+
+	header := byte(0x30)
+	if retain {
+		header |= 0x01
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return mqttWritePacket(c.conn, header, body.Bytes())
+}
+
+// close sends DISCONNECT, which tells the broker not to publish the last
+// will, and closes the connection.
+func (c *mqttClient) close() error {
+	c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	mqttWritePacket(c.conn, 0xE0, nil)
+	return c.conn.Close()
+}
+
+// mqttWritePacket writes a control packet with its variable-length
+// remaining-length header.
+func mqttWritePacket(w io.Writer, header byte, body []byte) error {
+	packet := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	_, err := w.Write(append(packet, body...))
+	return err
+}
+
+// mqttWriteString writes a length-prefixed UTF-8 string.
+func mqttWriteString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
@@ -0,0 +1,44 @@
+package monitor
+
+import "strings"
+
+// Intel thermal model-specific registers
+const (
+	msrThermStatus        = 0x19C // IA32_THERM_STATUS: bits 22:16 hold the core's distance to TjMax
+	msrTemperatureTarget  = 0x1A2 // MSR_TEMPERATURE_TARGET: bits 23:16 hold TjMax
+	msrPackageThermStatus = 0x1B1 // IA32_PACKAGE_THERM_STATUS: as IA32_THERM_STATUS for the package
+)
+
+// msrSensorID is the sensor id of temperatures read from the thermal MSRs.
+const msrSensorID = "msr/package"
+
+// msrThermalValid is the reading-valid bit of the thermal status MSRs.
+const msrThermalValid = 1 << 31
+
+// readMSRTemperature reads the package temperature from the Intel thermal
+// MSRs, for minimal containers and rescue systems that have /dev/cpu/N/msr
+// but no coretemp driver. The registers report the distance to TjMax, which
+// MSR_TEMPERATURE_TARGET provides; when the package register is missing,
+// as on older CPUs, the first core's is used. AMD CPUs report their
+// temperature over SMN rather than MSRs, so ok is false there.
+func readMSRTemperature() (temp, tjMax float64, ok bool) {
+	if !strings.Contains(cpuVendor(), "Intel") {
+		return 0, 0, false
+	}
+	target, ok := readMSR(0, msrTemperatureTarget)
+	if !ok {
+		return 0, 0, false
+	}
+	tjMax = float64((target >> 16) & 0xff)
+	if tjMax == 0 {
+		return 0, 0, false
+	}
+	status, ok := readMSR(0, msrPackageThermStatus)
+	if !ok || status&msrThermalValid == 0 {
+		status, ok = readMSR(0, msrThermStatus)
+	}
+	if !ok || status&msrThermalValid == 0 {
+		return 0, 0, false
+	}
+	return tjMax - float64((status>>16)&0x7f), tjMax, true
+}
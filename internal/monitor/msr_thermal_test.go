@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMSRFallback checks the telemetry of a system with neither cpufreq
+// nor hwmon: clocks from APERF/MPERF against the base clock of the model
+// name, and the package temperature from the thermal MSRs.
+func TestMSRFallback(t *testing.T) {
+	dir := t.TempDir()
+	savedProc, savedCPU, savedMSR, savedHelper := procDir, cpuDir, msrDir, privHelper
+	t.Cleanup(func() { procDir, cpuDir, msrDir, privHelper = savedProc, savedCPU, savedMSR, savedHelper })
+	procDir, cpuDir, msrDir, privHelper = filepath.Join(dir, "proc"), filepath.Join(dir, "cpu"), filepath.Join(dir, "dev"), nil
+
+	os.MkdirAll(procDir, 0755)
+	cpuinfo := "vendor_id\t: GenuineIntel\nmodel name\t: Intel(R) Core(TM) i7-8700 CPU @ 3.20GHz\n"
+	if err := ioutil.WriteFile(filepath.Join(procDir, "cpuinfo"), []byte(cpuinfo), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeMSR := func(reg int64, v uint64) {
+		os.MkdirAll(filepath.Join(msrDir, "0"), 0755)
+		f, err := os.OpenFile(filepath.Join(msrDir, "0", "msr"), os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		var buf [8]byte
+		for i := range buf {
+			buf[i] = byte(v >> (8 * i))
+		}
+		f.WriteAt(buf[:], reg)
+	}
+	writeMSR(msrTemperatureTarget, 100<<16)
+	writeMSR(msrPackageThermStatus, msrThermalValid|38<<16)
+	// Registers are offsets into the device, so the one-apart clock
+	// counters overlap in a plain file; only their presence is checked
+	writeMSR(msrMPERF, 1000)
+
+	if temp, tjMax, ok := readMSRTemperature(); !ok || temp != 62 || tjMax != 100 {
+		t.Errorf("MSR temperature = %.0f°C, TjMax %.0f°C, %v; want 62°C, 100°C, true", temp, tjMax, ok)
+	}
+
+	c := newClockSampler(1)
+	if !c.available || !c.msrOnly || c.cores[0].baseKHz != 3200000 {
+		t.Errorf("clock sampler available %v, MSR only %v, base %.0f kHz; want true, true, 3200000", c.available, c.msrOnly, c.cores[0].baseKHz)
+	}
+}
@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// multiTempMarkers are the glyphs used for each series of the
+// multi-sensor temperature graph, main sensor first. Distinct shapes keep
+// the series apart even where colors are hard to tell apart.
+var multiTempMarkers = []string{"●", "◆", "▲", "■", "✖", "★"}
+
+// multiTempColors returns the series colors, read at draw time so they
+// follow the active theme.
+func multiTempColors() []string {
+	return []string{colorRed, colorCyan, colorGreen, colorMagenta, colorOrange, colorBlue}
+}
+
+// drawMultiTempGraph plots the main temperature and every secondary
+// sensor on one shared temperature axis, with a legend mapping each
+// marker and color to its sensor.
+func (m *Monitor) drawMultiTempGraph(currentTemp float64) {
+	const rows = 8
+	fmt.Fprintf(m.out, "%s%s%s %s %s%s%s%*s\r\n",
+		colorCyan, tr("Temperature Sensors Graph"), colorReset, tr("Current:"),
+		colorYellow, formatTemp(currentTemp, 1), colorReset, 20, "")
+
+	// Series 0 is the main temperature, the rest follow secondary_sensors
+	names := append([]string{m.mainSensorName()}, m.cfg.SecondarySensors...)
+	if len(names) > len(multiTempMarkers) {
+		names = names[:len(multiTempMarkers)]
+	}
+	value := func(p historyPoint, series int) float64 {
+		if series == 0 {
+			return p.temp
+		}
+		if series-1 < len(p.sensors) {
+			return p.sensors[series-1]
+		}
+		return 0
+	}
+
+	// Shared axis over all series, rounded out to 10°C
+	lo, hi := math.MaxFloat64, 0.0
+	for _, p := range m.displayBuffer {
+		for s := range names {
+			if v := value(p, s); v > 0 {
+				lo, hi = math.Min(lo, v), math.Max(hi, v)
+			}
+		}
+	}
+	if hi == 0 {
+		lo, hi = 30, 90
+	}
+	lo, hi = math.Floor(lo/10)*10, math.Ceil(hi/10)*10
+	if hi-lo < 20 {
+		hi = lo + 20
+	}
+
+	// Draw the main series last so it stays on top where series overlap
+	grid := make([][]string, rows)
+	for r := range grid {
+		grid[r] = make([]string, baseGraphWidth)
+	}
+	colors := multiTempColors()
+	for s := len(names) - 1; s >= 0; s-- {
+		marker := colors[s] + multiTempMarkers[s] + colorReset
+		for i, p := range m.displayBuffer {
+			if v := value(p, s); v > 0 {
+				grid[valueRow((v-lo)/(hi-lo), rows)][i] = marker
+			}
+		}
+	}
+
+	rowSpan := (hi - lo) / rows
+	for row := rows - 1; row >= 0; row-- {
+		fmt.Fprintf(m.out, "%s%7s%s ", colorCyan, formatTemp(lo+rowSpan*float64(row+1), 0), colorReset)
+		for _, cell := range grid[row] {
+			if cell == "" {
+				cell = " "
+			}
+			fmt.Fprint(m.out, cell)
+		}
+		fmt.Fprint(m.out, "\r\n")
+	}
+
+	legend := make([]string, len(names))
+	for s, name := range names {
+		legend[s] = fmt.Sprintf("%s%s%s %s", colors[s], multiTempMarkers[s], colorReset, name)
+	}
+	fmt.Fprintf(m.out, "        %s\r\n", strings.Join(legend, "  "))
+	fmt.Fprintf(m.out, "        %s%s%s\r\n", colorYellow, m.zoomHint(), colorReset)
+	fmt.Fprintf(m.out, "        %s%-10s%s\r\n", colorCyan, formatWindow(m.window), colorReset)
+}
+
+// mainSensorName labels the main temperature series: the chosen sensor
+// id, or a generic name under automatic selection.
+func (m *Monitor) mainSensorName() string {
+	if m.cfg.Sensor != "" {
+		return m.cfg.Sensor
+	}
+	return tr("CPU")
+}
@@ -0,0 +1,433 @@
+package monitor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Roots of the cpufreq sysfs tree and the msr character devices
+var (
+	cpuDir = "/sys/devices/system/cpu"
+	msrDir = "/dev/cpu"
+)
+
+// Model-specific registers used for effective clocks
+const (
+	msrMPERF        = 0xE7 // Counts at the base (TSC) frequency while in C0
+	msrAPERF        = 0xE8 // Counts at the actual frequency while in C0
+	msrPlatformInfo = 0xCE // Intel: bits 15:8 hold the maximum non-turbo ratio
+)
+
+// busClockMHz is the reference clock of current Intel and AMD desktop and
+// server parts. Multipliers are derived from it; BCLK overclocks skew them.
+const busClockMHz = 100.0
+
+// coreClock holds the clock state of one logical CPU.
+type coreClock struct {
+	curKHz       float64 // Requested frequency from cpufreq
+	baseKHz      float64 // Base (non-boost) frequency, 0 when unknown
+	effectiveKHz float64 // Average frequency while busy from APERF/MPERF, 0 when unknown
+	aperf, mperf uint64  // Previous MSR readings
+	boost        uint64  // Samples spent above the base frequency
+	samples      uint64  // Samples taken
+}
+
+// clockSampler reads per-core clocks for the overclocking detail page.
+// Frequencies come from cpufreq sysfs; effective clocks need read access to
+// /dev/cpu/N/msr (root and the msr module).
+type clockSampler struct {
+	available bool   // Whether cpufreq, /proc/cpuinfo or the MSRs report clocks
+	cpuinfo   bool   // Whether clocks come from /proc/cpuinfo, for lack of cpufreq
+	msrOnly   bool   // Whether only the APERF/MPERF counters report clocks
+	msr       bool   // Whether the APERF/MPERF counters are readable
+	driver    string // cpufreq scaling driver, e.g. intel_pstate or amd-pstate-epp
+	maxKHz    float64
+	peakKHz   float64 // Highest clock seen
+	cores     []coreClock
+}
+
+// newClockSampler discovers the cpufreq directories and base frequencies
+// of the given number of CPUs and takes the first MSR reading. Without
+// cpufreq, as in most virtual machines, the clocks in /proc/cpuinfo are
+// used; without either, as in minimal containers and rescue systems, the
+// effective clocks from the APERF/MPERF counters are the only ones.
+func newClockSampler(cores int) *clockSampler {
+	c := &clockSampler{cores: make([]coreClock, cores)}
+	if _, err := os.Stat(filepath.Join(cpuDir, "cpu0", "cpufreq")); err != nil {
+		if len(readCPUInfoKHz()) > 0 {
+			c.cpuinfo = true
+		} else if _, _, ok := readClockCounters(0); ok {
+			c.msrOnly = true
+		} else {
+			return c
+		}
+	}
+	c.available = true
+	c.driver = readSysfsString(filepath.Join(cpuDir, "cpu0", "cpufreq", "scaling_driver"))
+	if c.cpuinfo {
+		c.driver = "/proc/cpuinfo"
+	} else if c.msrOnly {
+		c.driver = "APERF/MPERF"
+	}
+
+	// amd-pstate reports the highest boost clock separately
+	c.maxKHz = readKHz(filepath.Join(cpuDir, "cpu0", "cpufreq", "amd_pstate_max_freq"))
+	if c.maxKHz == 0 {
+		c.maxKHz = readKHz(filepath.Join(cpuDir, "cpu0", "cpufreq", "cpuinfo_max_freq"))
+	}
+
+	fallback := modelBaseKHz()
+	for i := range c.cores {
+		c.cores[i].baseKHz = baseFrequency(i)
+		if c.cores[i].baseKHz == 0 {
+			c.cores[i].baseKHz = fallback
+		}
+	}
+
+	if _, _, ok := readClockCounters(0); ok {
+		c.msr = true
+		if fallback == 0 {
+			fallback = platformBaseKHz()
+		}
+		aperfs, _ := readMSRs(len(c.cores), msrAPERF)
+		mperfs, _ := readMSRs(len(c.cores), msrMPERF)
+		for i := range c.cores {
+			if c.cores[i].baseKHz == 0 {
+				c.cores[i].baseKHz = fallback
+			}
+			c.cores[i].aperf, c.cores[i].mperf = aperfs[i], mperfs[i]
+		}
+	}
+	// The counters only give a ratio to the base clock
+	if c.msrOnly && c.cores[0].baseKHz == 0 {
+		c.available = false
+	}
+	return c
+}
+
+// sample refreshes every core's clocks and boost residency.
+func (c *clockSampler) sample() {
+	if !c.available {
+		return
+	}
+	var cpuinfo []float64
+	if c.cpuinfo {
+		cpuinfo = readCPUInfoKHz()
+	}
+	var aperfs, mperfs []uint64
+	var aperfOK, mperfOK []bool
+	if c.msr {
+		aperfs, aperfOK = readMSRs(len(c.cores), msrAPERF)
+		mperfs, mperfOK = readMSRs(len(c.cores), msrMPERF)
+	}
+	for i := range c.cores {
+		core := &c.cores[i]
+		if c.cpuinfo {
+			core.curKHz = 0
+			if i < len(cpuinfo) {
+				core.curKHz = cpuinfo[i]
+			}
+		} else if !c.msrOnly {
+			core.curKHz = readKHz(filepath.Join(cpuDir, fmt.Sprintf("cpu%d", i), "cpufreq", "scaling_cur_freq"))
+		}
+
+		if c.msr {
+			aperf, mperf, ok := aperfs[i], mperfs[i], aperfOK[i] && mperfOK[i]
+			if ok && mperf > core.mperf && core.baseKHz > 0 {
+				core.effectiveKHz = core.baseKHz * float64(aperf-core.aperf) / float64(mperf-core.mperf)
+			}
+			core.aperf, core.mperf = aperf, mperf
+		}
+
+		clock := c.clockKHz(i)
+		if clock > c.peakKHz {
+			c.peakKHz = clock
+		}
+		if clock == 0 || core.baseKHz == 0 {
+			continue
+		}
+		core.samples++
+		// Allow for rounding in the reported frequencies
+		if clock > core.baseKHz*1.01 {
+			core.boost++
+		}
+	}
+}
+
+// resetResidency clears the boost residency counters.
+func (c *clockSampler) resetResidency() {
+	for i := range c.cores {
+		c.cores[i].boost = 0
+		c.cores[i].samples = 0
+	}
+}
+
+// residency returns the share of samples a core spent boosting, in percent.
+func (core *coreClock) residency() (float64, bool) {
+	if core.samples == 0 {
+		return 0, false
+	}
+	return float64(core.boost) / float64(core.samples) * 100, true
+}
+
+// baseFrequency reads the base frequency of one CPU from intel_pstate
+// (base_frequency) or ACPI CPPC (nominal_freq, used by amd-pstate).
+func baseFrequency(cpu int) float64 {
+	dir := filepath.Join(cpuDir, fmt.Sprintf("cpu%d", cpu))
+	if khz := readKHz(filepath.Join(dir, "cpufreq", "base_frequency")); khz > 0 {
+		return khz
+	}
+	// CPPC reports MHz
+	return readKHz(filepath.Join(dir, "acpi_cppc", "nominal_freq")) * 1000
+}
+
+// modelBaseRe matches the rated clock in Intel model names, e.g. "@ 3.60GHz".
+var modelBaseRe = regexp.MustCompile(`@\s*([0-9.]+)\s*GHz`)
+
+// modelBaseKHz takes the base frequency from the model name in
+// /proc/cpuinfo, or returns 0.
+func modelBaseKHz() float64 {
+	data, err := ioutil.ReadFile(filepath.Join(procDir, "cpuinfo"))
+	if err != nil {
+		return 0
+	}
+	match := modelBaseRe.FindSubmatch(data)
+	if match == nil {
+		return 0
+	}
+	ghz, _ := strconv.ParseFloat(string(match[1]), 64)
+	return ghz * 1e6
+}
+
+// readCPUInfoKHz returns the "cpu MHz" lines of /proc/cpuinfo in kHz, one
+// per logical CPU in order.
+func readCPUInfoKHz() []float64 {
+	data, _ := ioutil.ReadFile(filepath.Join(procDir, "cpuinfo"))
+	var clocks []float64
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) != "cpu MHz" {
+			continue
+		}
+		mhz, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			continue
+		}
+		clocks = append(clocks, mhz*1000)
+	}
+	return clocks
+}
+
+// platformBaseKHz derives the base frequency from the maximum non-turbo
+// ratio in MSR_PLATFORM_INFO (Intel only), or returns 0.
+func platformBaseKHz() float64 {
+	if !strings.Contains(cpuVendor(), "Intel") {
+		return 0
+	}
+	info, ok := readMSR(0, msrPlatformInfo)
+	if !ok {
+		return 0
+	}
+	return float64((info>>8)&0xff) * busClockMHz * 1000
+}
+
+// cpuVendor returns the vendor_id line of /proc/cpuinfo.
+func cpuVendor() string {
+	data, _ := ioutil.ReadFile(filepath.Join(procDir, "cpuinfo"))
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "vendor_id") {
+			return line
+		}
+	}
+	return ""
+}
+
+// readClockCounters reads APERF and MPERF of one CPU.
+func readClockCounters(cpu int) (aperf, mperf uint64, ok bool) {
+	if aperf, ok = readMSR(cpu, msrAPERF); !ok {
+		return 0, 0, false
+	}
+	mperf, ok = readMSR(cpu, msrMPERF)
+	return aperf, mperf, ok
+}
+
+// readMSR reads one 64-bit model-specific register through /dev/cpu/N/msr,
+// or through the privilege helper when the device is root-only.
+func readMSR(cpu int, reg int64) (uint64, bool) {
+	f, err := os.Open(filepath.Join(msrDir, strconv.Itoa(cpu), "msr"))
+	if os.IsPermission(err) && privHelper != nil {
+		return privHelper.readMSR(cpu, reg)
+	}
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	var buf [8]byte
+	if _, err := f.ReadAt(buf[:], reg); err != nil {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(buf[:]), true
+}
+
+// readMSRs reads one register of CPUs 0 to n-1. Through the privilege
+// helper they are read in one request instead of one per CPU.
+func readMSRs(n int, reg int64) (values []uint64, ok []bool) {
+	if privHelper != nil {
+		f, err := os.Open(filepath.Join(msrDir, "0", "msr"))
+		if os.IsPermission(err) {
+			return privHelper.readMSRs(n, reg)
+		}
+		if err == nil {
+			f.Close()
+		}
+	}
+	values, ok = make([]uint64, n), make([]bool, n)
+	for i := range values {
+		values[i], ok[i] = readMSR(i, reg)
+	}
+	return values, ok
+}
+
+// readKHz reads a frequency file, returning 0 when it is missing.
+func readKHz(path string) float64 {
+	v, err := strconv.ParseFloat(readSysfsString(path), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// formatMHz formats a kHz frequency as whole MHz, or "--" when unknown.
+func formatMHz(khz float64) string {
+	if khz <= 0 {
+		return "--"
+	}
+	return fmt.Sprintf("%.0f MHz", khz/1000)
+}
+
+// displayOverclockPage draws the overclocking detail page: per-core clock,
+// multiplier, effective clock and boost residency, in columns of up to 32
+// cores.
+func (m *Monitor) displayOverclockPage() {
+	fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor - %s ===%s\r\n\r\n", colorGreen, tr("Overclocking"), colorReset)
+
+	c := m.clocks
+	if !c.available {
+		fmt.Fprintf(m.out, "  %s\r\n\r\n", tr("CPU frequency information is not available (no cpufreq in /sys/devices/system/cpu)"))
+		fmt.Fprintf(m.out, "%s%s%s\r\n", colorYellow, tr("O/ESC: close"), colorReset)
+		return
+	}
+
+	stress := tr("off")
+	if m.stressRunning {
+		stress = colorRed + tr("running") + colorReset
+	}
+	base := 0.0
+	if len(c.cores) > 0 {
+		base = c.cores[0].baseKHz
+	}
+	fmt.Fprintf(m.out, "%s %s   %s %.0f MHz   %s %s   %s %s   %s %s\r\n", tr("Driver:"), c.driver,
+		tr("Bus clock:"), busClockMHz, tr("Base clock:"), formatMHz(base), tr("Max boost:"), formatMHz(c.maxKHz), tr("Stress:"), stress)
+
+	var boost, samples uint64
+	for i := range c.cores {
+		boost += c.cores[i].boost
+		samples += c.cores[i].samples
+	}
+	overall := "--"
+	if samples > 0 {
+		overall = fmt.Sprintf("%.0f%%", float64(boost)/float64(samples)*100)
+	}
+	fmt.Fprintf(m.out, "%s %s\r\n", tr("Boost residency (all cores):"), overall)
+	if !c.msr {
+		fmt.Fprintf(m.out, "%s%s%s\r\n", colorDarkYellow, tr("Effective clocks need read access to /dev/cpu/*/msr (run as root or start kkperf helper, with the msr module loaded)"), colorReset)
+	}
+	fmt.Fprint(m.out, "\r\n")
+
+	const rowsPerColumn = 32
+	columns := (len(c.cores) + rowsPerColumn - 1) / rowsPerColumn
+	rows := len(c.cores)
+	if rows > rowsPerColumn {
+		rows = rowsPerColumn
+	}
+
+	header := fmt.Sprintf("%-5s %9s %7s %10s %6s", tr("CPU"), tr("Clock"), tr("Mult"), tr("Effective"), tr("Boost"))
+	if m.smu.energyUnit > 0 {
+		header += fmt.Sprintf(" %7s", tr("Power"))
+	}
+	headers := make([]string, columns)
+	for i := range headers {
+		headers[i] = header
+	}
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, strings.Join(headers, "   "), colorReset)
+
+	for row := 0; row < rows; row++ {
+		cells := make([]string, 0, columns)
+		for col := 0; col < columns; col++ {
+			i := col*rowsPerColumn + row
+			if i >= len(c.cores) {
+				break
+			}
+			cells = append(cells, m.overclockCell(i))
+		}
+		fmt.Fprintf(m.out, "%s\r\n", strings.Join(cells, "   "))
+	}
+
+	fmt.Fprintf(m.out, "\r\n%s%s%s\r\n", colorYellow, tr("SPACE: stress  R: reset residency  O/ESC: close"), colorReset)
+}
+
+// overclockCell formats one core's row of the overclocking page. Clocks
+// above the base frequency are highlighted.
+func (m *Monitor) overclockCell(i int) string {
+	core := &m.clocks.cores[i]
+	mult := "--"
+	if core.curKHz > 0 {
+		mult = fmt.Sprintf("%.1fx", core.curKHz/1000/busClockMHz)
+	}
+	color := ""
+	if core.baseKHz > 0 && core.curKHz > core.baseKHz*1.01 {
+		color = colorGreen
+	}
+	residency := "--"
+	if r, ok := core.residency(); ok {
+		residency = fmt.Sprintf("%.0f%%", r)
+	}
+	cell := fmt.Sprintf("%-5d %s%9s%s %7s %10s %6s", i, color, formatMHz(core.curKHz), colorReset,
+		mult, formatMHz(core.effectiveKHz), residency)
+	if m.smu.energyUnit > 0 && i < len(m.smu.cpuPower) {
+		// AMD core energy counters; SMT siblings report their shared core
+		cell += fmt.Sprintf(" %5.1f W", m.smu.cpuPower[i])
+	}
+	return cell
+}
+
+// handleOverclockKey processes a key press while the overclocking page is
+// shown. It returns false when the application should quit.
+func (m *Monitor) handleOverclockKey(key byte) bool {
+	switch m.pageAction(key, actionOverclock) {
+	case actionStress:
+		if m.stressRunning {
+			m.stopStress()
+		} else {
+			m.startStress()
+		}
+		return true
+	case actionClose:
+		m.showOverclock = false
+		fmt.Fprint(m.out, clearScreen)
+		return true
+	case actionQuit:
+		return false
+	}
+	switch key {
+	case 'r', 'R':
+		m.clocks.resetResidency()
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+)
+
+// pauseState freezes the graphs on a moment, so a spike can be inspected
+// before it scrolls out of the window.
+type pauseState struct {
+	on    bool
+	since time.Time
+	polls int // History polls when the pause started
+}
+
+// togglePause freezes or resumes the graphs. Polling carries on while
+// paused unless [pause] suspend_polling is set, and the graphs catch up
+// with the polls taken meanwhile on resume. Suspended polling leaves a
+// gap the history cannot show, so it restarts empty instead.
+func (m *Monitor) togglePause() {
+	if !m.pause.on {
+		m.pause = pauseState{on: true, since: timeNow(), polls: m.history.polls}
+		return
+	}
+	m.pause.on = false
+	if m.cfg.Pause.SuspendPolling {
+		m.history = newGraphHistory(m.cfg.PollInterval)
+	}
+	m.refreshGraph()
+}
+
+// pollingSuspended reports whether the main loop should skip polls: while
+// paused with [pause] suspend_polling, or when connected to a remote
+// monitor, until its next sample arrives.
+func (m *Monitor) pollingSuspended() bool {
+	if m.remote != nil && !m.remote.fresh() {
+		return true
+	}
+	return m.pause.on && m.cfg.Pause.SuspendPolling
+}
+
+// graphSkip returns the polls taken since the graphs were frozen, which
+// they leave out so they stay on the moment of the pause. Zooming while
+// paused keeps that moment at the right edge.
+func (m *Monitor) graphSkip() int {
+	if !m.pause.on {
+		return 0
+	}
+	return m.history.polls - m.pause.polls
+}
+
+// pauseStatus returns the status line tag of a pause, with its length.
+func (m *Monitor) pauseStatus() string {
+	if !m.pause.on {
+		return ""
+	}
+	return fmt.Sprintf("  %s[%s %s]%s", colorYellow, tr("PAUSED"), timeNow().Sub(m.pause.since).Round(time.Second), colorReset)
+}
@@ -0,0 +1,25 @@
+package monitor
+
+import (
+	"testing"
+)
+
+// TestPause checks that pausing freezes the graph on its moment while
+// polls go on, and that resuming catches up with them.
+func TestPause(t *testing.T) {
+	m, _, _ := fixtureMonitor(t, "4cores", nil, nil)
+	m.out = newScreenBuffer(goldenWidth, goldenHeight)
+	m.handleMainKey('e')
+	frozen := m.displayBuffer[len(m.displayBuffer)-1].cpu
+	for i := 0; i < 10; i++ {
+		m.history.add(historyPoint{cpu: 99, temp: 80})
+		m.refreshGraph()
+	}
+	if last := m.displayBuffer[len(m.displayBuffer)-1].cpu; last != frozen {
+		t.Errorf("paused graph moved from %.1f%% to %.1f%%", frozen, last)
+	}
+	m.handleMainKey('e')
+	if last := m.displayBuffer[len(m.displayBuffer)-1].cpu; last != 99 {
+		t.Errorf("resumed graph ends at %.1f%%, want the polls taken while paused", last)
+	}
+}
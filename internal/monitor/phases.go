@@ -0,0 +1,215 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// workloadPhase is what the machine was doing over a stretch of history.
+type workloadPhase int
+
+const (
+	phaseNone     workloadPhase = iota // No data
+	phaseIdle                          // Low load at a stable temperature
+	phaseRamp                          // Load or temperature rising
+	phaseSteady                        // Sustained load at a stable temperature
+	phaseCooldown                      // Load gone, temperature falling
+)
+
+// phaseNames are the labels of the phases on the graph and in reports.
+var phaseNames = [...]string{"", "Idle", "Ramp", "Steady", "Cooldown"}
+
+// phaseColors are the colors of the phases on the graph.
+var phaseColors = [...]string{"", colorBlue, colorOrange, colorGreen, colorLightBlue}
+
+const (
+	phaseIdleCPU    = 20.0             // Mean CPU usage below which a stretch is idle
+	phaseTempRate   = 3.0              // °C per minute a temperature must change by to count as a ramp or cooldown
+	phaseCPURate    = 60.0             // Percentage points per minute a load must change by to count as a ramp or cooldown
+	phaseSpan       = 30 * time.Second // Time the slopes are measured over, so sensor noise is not a phase
+	phaseMinLength  = 3                // Points a phase must last; shorter ones join the phase before
+	reportPhaseRows = 12               // Loaded phases a report lists
+)
+
+// phaseSegment is a run of points in one phase.
+type phaseSegment struct {
+	phase      workloadPhase
+	start, end int // Index of the first point and one past the last
+}
+
+// detectPhases labels each point of a series step apart from the slopes
+// of CPU usage and temperature around it and the load level. Points
+// before start have no data. A temperature of 0 is a missing reading, and
+// the phases then follow the CPU usage alone.
+func detectPhases(cpu, temp []float64, start int, step time.Duration) []workloadPhase {
+	phases := make([]workloadPhase, len(cpu))
+	if start >= len(cpu) || step <= 0 {
+		return phases
+	}
+	half := int(math.Ceil(float64(phaseSpan) / float64(step) / 2))
+	if half < 1 {
+		half = 1
+	}
+	for i := start; i < len(cpu); i++ {
+		lo, hi := i-half, i+half
+		if lo < start {
+			lo = start
+		}
+		if hi > len(cpu)-1 {
+			hi = len(cpu) - 1
+		}
+		level := 0.0
+		for _, c := range cpu[lo : hi+1] {
+			level += c
+		}
+		level /= float64(hi - lo + 1)
+
+		var tempSlope, cpuSlope float64
+		if hi > lo {
+			minutes := float64(hi-lo) * step.Minutes()
+			cpuSlope = (cpu[hi] - cpu[lo]) / minutes
+			if temp[lo] > 0 && temp[hi] > 0 {
+				tempSlope = (temp[hi] - temp[lo]) / minutes
+			}
+		}
+		switch {
+		case cpuSlope > phaseCPURate || tempSlope > phaseTempRate && level >= phaseIdleCPU:
+			phases[i] = phaseRamp
+		case cpuSlope < -phaseCPURate || tempSlope < -phaseTempRate:
+			phases[i] = phaseCooldown
+		case level < phaseIdleCPU:
+			phases[i] = phaseIdle
+		default:
+			phases[i] = phaseSteady
+		}
+	}
+	smoothPhases(phases[start:])
+	return phases
+}
+
+// smoothPhases merges phases shorter than phaseMinLength into the phase
+// before them, or the one after at the start, so a single noisy point
+// does not split a phase.
+func smoothPhases(phases []workloadPhase) {
+	for _, s := range phaseSegments(phases) {
+		if s.end-s.start >= phaseMinLength || s.end-s.start == len(phases) {
+			continue
+		}
+		fill := workloadPhase(0)
+		if s.start > 0 {
+			fill = phases[s.start-1]
+		} else {
+			fill = phases[s.end]
+		}
+		for i := s.start; i < s.end; i++ {
+			phases[i] = fill
+		}
+	}
+}
+
+// phaseSegments splits labelled points into runs of one phase, leaving
+// out points without data.
+func phaseSegments(phases []workloadPhase) []phaseSegment {
+	var segments []phaseSegment
+	for i, p := range phases {
+		if p == phaseNone {
+			continue
+		}
+		if n := len(segments); n > 0 && segments[n-1].phase == p && segments[n-1].end == i {
+			segments[n-1].end = i + 1
+			continue
+		}
+		segments = append(segments, phaseSegment{phase: p, start: i, end: i + 1})
+	}
+	return segments
+}
+
+// drawPhaseRow labels the columns of the graph above with their phases,
+// each run as its name followed by a line to its end, in the color of the
+// phase. Runs too narrow for their name show as much of it as fits.
+func (m *Monitor) drawPhaseRow() {
+	if !m.cfg.PhaseLabels {
+		return
+	}
+	cpu, temp := make([]float64, len(m.displayBuffer)), make([]float64, len(m.displayBuffer))
+	for i, p := range m.displayBuffer {
+		cpu[i], temp[i] = p.cpu, p.temp
+	}
+	step := m.window / time.Duration(len(m.displayBuffer))
+	phases := detectPhases(cpu, temp, m.graphStart, step)
+
+	var row strings.Builder
+	row.WriteString(strings.Repeat(" ", m.graphStart))
+	for _, s := range phaseSegments(phases) {
+		label := []rune(tr(phaseNames[s.phase]))
+		width := s.end - s.start
+		if len(label) > width {
+			label = label[:width]
+		}
+		fmt.Fprintf(&row, "%s%s%s%s", phaseColors[s.phase], string(label), strings.Repeat("─", width-len(label)), colorReset)
+	}
+	fmt.Fprintf(m.out, "%s%s%s%s\r\n", colorCyan, padRight(tr("Phase"), 7), colorReset, row.String())
+}
+
+// formatPhases summarizes the phases of a report period: the minutes
+// spent in each, and the loaded phases in order with their times, the
+// latest reportPhaseRows of them.
+func formatPhases(records []historyRecord) string {
+	if len(records) == 0 {
+		return ""
+	}
+	cpu, temp := make([]float64, len(records)), make([]float64, len(records))
+	for i, r := range records {
+		cpu[i], temp[i] = r.CPUAvg, r.TempAvg
+	}
+	phases := detectPhases(cpu, temp, 0, time.Minute)
+	var minutes [len(phaseNames)]int
+	var loaded []phaseSegment
+	for _, s := range phaseSegments(phases) {
+		minutes[s.phase] += s.end - s.start
+		if s.phase != phaseIdle {
+			loaded = append(loaded, s)
+		}
+	}
+
+	var b strings.Builder
+	var totals []string
+	for p := phaseIdle; p <= phaseCooldown; p++ {
+		totals = append(totals, fmt.Sprintf("%s %d min", strings.ToLower(phaseNames[p]), minutes[p]))
+	}
+	fmt.Fprintf(&b, "\nPhases: %s\n", strings.Join(totals, ", "))
+	if len(loaded) > reportPhaseRows {
+		loaded = loaded[len(loaded)-reportPhaseRows:]
+	}
+	const stamp = "01-02 15:04"
+	for _, s := range loaded {
+		first, last := records[s.start], records[s.end-1]
+		fmt.Fprintf(&b, "  %s-%s  %-9s avg CPU %s", first.Time.Format(stamp), last.Time.Add(time.Minute).Format("15:04"),
+			phaseNames[s.phase], formatPercent(phaseMeanCPU(records[s.start:s.end]), 1))
+		if peak := phaseMaxTemp(records[s.start:s.end]); peak > 0 {
+			fmt.Fprintf(&b, ", max %s", formatTemp(peak, 1))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// phaseMeanCPU returns the mean CPU usage of records.
+func phaseMeanCPU(records []historyRecord) float64 {
+	sum := 0.0
+	for _, r := range records {
+		sum += r.CPUAvg
+	}
+	return sum / float64(len(records))
+}
+
+// phaseMaxTemp returns the highest temperature of records, 0 without one.
+func phaseMaxTemp(records []historyRecord) float64 {
+	peak := 0.0
+	for _, r := range records {
+		peak = math.Max(peak, r.TempMax)
+	}
+	return peak
+}
@@ -0,0 +1,33 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPhaseDetection segments a stress run sampled every 10 seconds: two
+// idle minutes, a load that heats the CPU for two minutes and holds it for
+// three, then two minutes of cooling and idle again.
+func TestPhaseDetection(t *testing.T) {
+	var cpu, temp []float64
+	add := func(n int, c, from, to float64) {
+		for i := 0; i < n; i++ {
+			cpu = append(cpu, c)
+			temp = append(temp, from+(to-from)*float64(i)/float64(n))
+		}
+	}
+	add(12, 3, 40, 40)
+	add(12, 100, 40, 80)
+	add(18, 100, 80, 81)
+	add(12, 3, 81, 45)
+	add(12, 3, 45, 44)
+
+	var got []string
+	for _, s := range phaseSegments(detectPhases(cpu, temp, 0, 10*time.Second)) {
+		got = append(got, phaseNames[s.phase])
+	}
+	if want := "Idle Ramp Steady Cooldown Idle"; strings.Join(got, " ") != want {
+		t.Errorf("phases %q, want %q", strings.Join(got, " "), want)
+	}
+}
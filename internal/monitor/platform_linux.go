@@ -0,0 +1,12 @@
+//go:build linux
+
+package monitor
+
+import "golang.org/x/sys/unix"
+
+// pinThread binds the calling OS thread to cpu.
+func pinThread(cpu int) error {
+	var set unix.CPUSet
+	set.Set(cpu)
+	return unix.SchedSetaffinity(0, &set)
+}
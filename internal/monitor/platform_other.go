@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package monitor
+
+import "errors"
+
+// pinThread fails: thread affinity is only set on Linux and Windows.
+func pinThread(cpu int) error {
+	return errors.New("thread affinity is not supported on this platform")
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package monitor
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Shell that runs configured commands.
+const (
+	shellName = "sh"
+	shellFlag = "-c"
+)
+
+// sampleSignals request an extra sample in Telegraf execd mode.
+var sampleSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2}
+
+// setProcessGroup starts cmd in a process group of its own, so
+// killProcessGroup also stops the workers it spawns.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills cmd and every process in its group. It fails
+// when cmd was not started in a group of its own.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// setUmask sets the file mode creation mask and returns the previous one.
+func setUmask(mask int) int {
+	return syscall.Umask(mask)
+}
+
+// enableVirtualTerminal prepares the terminal for escape sequences, which
+// Unix terminals always understand.
+func enableVirtualTerminal() {}
@@ -0,0 +1,76 @@
+//go:build windows
+
+package monitor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// Shell that runs configured commands.
+const (
+	shellName = "cmd"
+	shellFlag = "/C"
+)
+
+// sampleSignals request an extra sample in Telegraf execd mode. Windows
+// has none, so Telegraf has to use signal = "STDIN".
+var sampleSignals []os.Signal
+
+// setProcessGroup starts cmd in a process group of its own.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: windows.CREATE_NEW_PROCESS_GROUP}
+}
+
+// killProcessGroup kills cmd and every process it started. Windows
+// process groups only route console signals, so the tree is walked by
+// taskkill.
+func killProcessGroup(cmd *exec.Cmd) error {
+	if exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run() != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
+
+var (
+	kernel32                  = windows.NewLazySystemDLL("kernel32.dll")
+	procSetConsoleOutputCP    = kernel32.NewProc("SetConsoleOutputCP")
+	procSetThreadAffinityMask = kernel32.NewProc("SetThreadAffinityMask")
+)
+
+// pinThread binds the calling OS thread to cpu. Affinity masks cover the
+// first 64 CPUs of the processor group; threads on later CPUs stay
+// unpinned.
+func pinThread(cpu int) error {
+	if cpu >= 64 {
+		return errors.New("CPUs beyond the first 64 cannot be pinned")
+	}
+	thread, _ := windows.GetCurrentThread()
+	if r, _, err := procSetThreadAffinityMask.Call(uintptr(thread), uintptr(1)<<uint(cpu)); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// setUmask does nothing: Windows has no file mode creation mask, and the
+// helper that uses it only runs on Linux.
+func setUmask(mask int) int {
+	return 0
+}
+
+// enableVirtualTerminal makes the console interpret the escape sequences
+// the monitor draws with and print its UTF-8 output, as Windows Terminal
+// and the console host since Windows 10 can.
+func enableVirtualTerminal() {
+	out := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if windows.GetConsoleMode(out, &mode) == nil {
+		windows.SetConsoleMode(out, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+	}
+	procSetConsoleOutputCP.Call(65001) // CP_UTF8
+}
@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+)
+
+// drawPowerGraph plots the power of every RAPL domain (package, core,
+// uncore, DRAM) as its own series on a shared watts axis, with a legend
+// showing each domain's current draw.
+func (m *Monitor) drawPowerGraph() {
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, tr("Power Graph"), colorReset)
+
+	names := m.rapl.domainNames()
+	if len(names) == 0 {
+		fmt.Fprintf(m.out, "        %s\r\n", tr("RAPL power counters are not available (needs an Intel CPU and root to read energy_uj)"))
+		fmt.Fprintf(m.out, "        %s%-10s%s\r\n", colorCyan, formatWindow(m.window), colorReset)
+		return
+	}
+	if len(names) > len(multiTempMarkers) {
+		names = names[:len(multiTempMarkers)]
+	}
+	value := func(p historyPoint, series int) float64 {
+		if series < len(p.power) {
+			return p.power[series]
+		}
+		return -1
+	}
+
+	var latest []float64
+	if len(m.displayBuffer) > 0 {
+		latest = m.displayBuffer[len(m.displayBuffer)-1].power
+	}
+	m.drawSeriesGraph(seriesGraph{
+		names: names,
+		value: value,
+		scale: newYAxis(m.cfg.PowerGraphScale, m.seriesValues(len(names), value), 0, 10), // Auto rounds up to 10 W
+		unit:  unitWatts,
+		legend: func(s int) string {
+			if s < len(latest) {
+				return unitWatts.format(latest[s], 1)
+			}
+			return "--"
+		},
+	})
+}
+
+// drawPowerRow draws package power under the combined graph: the current
+// draw, the mean since startup with the energy used, and the peak of the
+// window, over a sparkline lined up with the CPU graph above so a load
+// step can be matched to what it costs. It draws nothing without RAPL.
+func (m *Monitor) drawPowerRow() {
+	if !m.rapl.hasPackage() {
+		return
+	}
+	current, peak := 0.0, 0.0
+	watts := make([]float64, len(m.displayBuffer))
+	for i, p := range m.displayBuffer {
+		watts[i] = m.packagePower(p)
+		peak = math.Max(peak, watts[i])
+	}
+	if len(watts) > 0 {
+		current = math.Max(watts[len(watts)-1], 0)
+	}
+	average, used := m.rapl.averageWatts()
+	fmt.Fprintf(m.out, "%s%s%s %s %s%s%s  %s %s  %s %s Wh  %s %s    \r\n", colorBlue, tr("Package power"), colorReset,
+		tr("Now"), colorYellow, unitWatts.format(current, 1), colorReset, tr("Avg"), unitWatts.format(average, 1),
+		tr("Used"), formatNumber(used, 2), tr("Peak"), unitWatts.format(peak, 1))
+
+	fmt.Fprintf(m.out, "%s%s%s", colorCyan, padRight(tr("Power"), 7), colorReset)
+	for _, w := range watts {
+		if peak == 0 || w <= 0 {
+			fmt.Fprint(m.out, " ")
+			continue
+		}
+		level := int(w / peak * float64(len(sparkBlocks)-1))
+		fmt.Fprintf(m.out, "%s%s%s", getUsageColor(w/peak*100), sparkBlocks[level], colorReset)
+	}
+	fmt.Fprint(m.out, "\r\n")
+}
+
+// domainWatts returns the watts of each domain for the history.
+func domainWatts(power []DomainPower) []float64 {
+	if len(power) == 0 {
+		return nil
+	}
+	watts := make([]float64, len(power))
+	for i, p := range power {
+		watts[i] = p.Watts
+	}
+	return watts
+}
@@ -0,0 +1,319 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// textfileSink writes metrics in the Prometheus exposition format to a
+// file for node_exporter's textfile collector. Files are replaced
+// atomically so node_exporter never reads a partial write.
+type textfileSink struct {
+	path      string
+	interval  time.Duration
+	lastWrite time.Time
+}
+
+// newTextfileSink creates a sink writing to path at most once per interval.
+func newTextfileSink(path string, interval time.Duration) *textfileSink {
+	return &textfileSink{path: path, interval: interval}
+}
+
+// write renders the sample and replaces the .prom file if the write
+// interval has elapsed.
+func (t *textfileSink) write(s *Sample) error {
+	if s.Time.Sub(t.lastWrite) < t.interval {
+		return nil
+	}
+	t.lastWrite = s.Time
+
+	// Write to a temporary file in the same directory, then rename over
+	// the target; node_exporter ignores files not ending in .prom
+	tmp, err := os.CreateTemp(filepath.Dir(t.path), ".kkperf-*.tmp")
+	if err != nil {
+		return fmt.Errorf("textfile: %v", err)
+	}
+	if _, err := tmp.WriteString(prometheusMetrics(s)); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("textfile: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("textfile: %v", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("textfile: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), t.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("textfile: %v", err)
+	}
+	return nil
+}
+
+// close is a no-op; the last written file stays in place for scraping.
+func (t *textfileSink) close() error {
+	return nil
+}
+
+// prometheusMetrics renders a sample in the Prometheus text exposition format.
+func prometheusMetrics(s *Sample) string {
+	var b strings.Builder
+	gauge := func(name, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+	counter := func(name, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	}
+
+	gauge("kkperf_cpu_usage_percent", "Total CPU usage.")
+	fmt.Fprintf(&b, "kkperf_cpu_usage_percent %g\n", s.CPU)
+	if s.Container != "" {
+		gauge("kkperf_container_cpu_limit", "CPUs the container may use, from its CFS quota or cpuset.")
+		fmt.Fprintf(&b, "kkperf_container_cpu_limit{runtime=%q} %g\n", s.Container, s.CPULimit)
+		gauge("kkperf_container_throttled_percent", "Share of CFS periods the container's quota throttled.")
+		fmt.Fprintf(&b, "kkperf_container_throttled_percent %g\n", s.CPUThrottled)
+	}
+
+	gauge("kkperf_core_usage_percent", "Per-core CPU usage.")
+	for i, usage := range s.Cores {
+		fmt.Fprintf(&b, "kkperf_core_usage_percent{core=\"%d\"} %g\n", i, usage)
+	}
+	if s.CoreTemps != nil {
+		gauge("kkperf_core_temperature_celsius", "Per-core temperature from coretemp or the k10temp CCD sensors.")
+		for i, temp := range s.CoreTemps {
+			if temp > 0 {
+				fmt.Fprintf(&b, "kkperf_core_temperature_celsius{core=\"%d\"} %g\n", i, temp)
+			}
+		}
+	}
+
+	if s.Temp > 0 {
+		gauge("kkperf_temperature_celsius", "CPU package temperature.")
+		fmt.Fprintf(&b, "kkperf_temperature_celsius %g\n", s.Temp)
+		gauge("kkperf_temperature_raw_celsius", "CPU package temperature before calibration.")
+		fmt.Fprintf(&b, "kkperf_temperature_raw_celsius %g\n", s.RawTemp)
+	}
+	if s.Limited {
+		gauge("kkperf_thermal_headroom_celsius", "Degrees below the sensor's thermal limit.")
+		fmt.Fprintf(&b, "kkperf_thermal_headroom_celsius %g\n", s.Headroom)
+	}
+	if s.TjMax > 0 {
+		gauge("kkperf_tjmax_celsius", "Temperature at which the CPU throttles.")
+		fmt.Fprintf(&b, "kkperf_tjmax_celsius %g\n", s.TjMax)
+	}
+	if s.HasAmbient {
+		gauge("kkperf_ambient_temperature_celsius", "Room temperature from the ambient source.")
+		fmt.Fprintf(&b, "kkperf_ambient_temperature_celsius %g\n", s.Ambient)
+	}
+	if s.MemTotal > 0 {
+		gauge("kkperf_memory_used_bytes", "RAM in use, not counting reclaimable cache.")
+		fmt.Fprintf(&b, "kkperf_memory_used_bytes %d\n", s.MemUsed)
+		gauge("kkperf_memory_total_bytes", "Installed RAM.")
+		fmt.Fprintf(&b, "kkperf_memory_total_bytes %d\n", s.MemTotal)
+		gauge("kkperf_swap_used_bytes", "Swap in use.")
+		fmt.Fprintf(&b, "kkperf_swap_used_bytes %d\n", s.SwapUsed)
+		gauge("kkperf_swap_total_bytes", "Swap space.")
+		fmt.Fprintf(&b, "kkperf_swap_total_bytes %d\n", s.SwapTotal)
+	}
+	gauge("kkperf_zombie_processes", "Zombie processes.")
+	fmt.Fprintf(&b, "kkperf_zombie_processes %d\n", s.Zombies)
+	gauge("kkperf_threads", "Threads of all processes.")
+	fmt.Fprintf(&b, "kkperf_threads %d\n", s.Threads)
+	if s.ThreadMax > 0 {
+		gauge("kkperf_threads_limit", "Thread limit, the lower of kernel.threads-max and kernel.pid_max.")
+		fmt.Fprintf(&b, "kkperf_threads_limit %d\n", s.ThreadMax)
+	}
+	gauge("kkperf_open_files", "Open file handles system-wide.")
+	fmt.Fprintf(&b, "kkperf_open_files %d\n", s.FDs)
+	if s.FDMax > 0 {
+		gauge("kkperf_open_files_limit", "System-wide file handle limit (fs.file-max).")
+		fmt.Fprintf(&b, "kkperf_open_files_limit %d\n", s.FDMax)
+	}
+	warning := 0
+	if s.HealthWarning != "" {
+		warning = 1
+	}
+	gauge("kkperf_health_warning", "Whether zombies, threads or open files exceed a limit of [health].")
+	fmt.Fprintf(&b, "kkperf_health_warning %d\n", warning)
+	if s.Entropy >= 0 {
+		gauge("kkperf_entropy_bits", "Kernel entropy estimate.")
+		fmt.Fprintf(&b, "kkperf_entropy_bits %d\n", s.Entropy)
+	}
+	if s.ClockSource != "" {
+		synced := 0
+		if s.ClockSynced {
+			synced = 1
+		}
+		gauge("kkperf_clock_synced", "Whether the time daemon considers the clock synchronized.")
+		fmt.Fprintf(&b, "kkperf_clock_synced{source=%q} %d\n", s.ClockSource, synced)
+		gauge("kkperf_clock_offset_seconds", "Offset of the clock from its time sources, positive when ahead.")
+		fmt.Fprintf(&b, "kkperf_clock_offset_seconds %g\n", s.ClockOffset)
+	}
+	counter("kkperf_clock_steps_total", "Wall clock steps seen since startup.")
+	fmt.Fprintf(&b, "kkperf_clock_steps_total %d\n", s.ClockSteps)
+	gauge("kkperf_iowait_percent", "Share of CPU time spent idle waiting for I/O.")
+	fmt.Fprintf(&b, "kkperf_iowait_percent %g\n", s.IOWait)
+	gauge("kkperf_blocked_tasks", "Tasks in uninterruptible sleep (D state).")
+	fmt.Fprintf(&b, "kkperf_blocked_tasks %d\n", s.Blocked)
+	if len(s.Load) == 3 {
+		gauge("kkperf_load_average", "Load average over the period in minutes.")
+		for i, period := range []string{"1", "5", "15"} {
+			fmt.Fprintf(&b, "kkperf_load_average{period=%q} %g\n", period, s.Load[i])
+		}
+		gauge("kkperf_runnable_tasks", "Tasks running or waiting for a CPU.")
+		fmt.Fprintf(&b, "kkperf_runnable_tasks %d\n", s.Running)
+	}
+	if s.GPU >= 0 {
+		gauge("kkperf_gpu_busy_percent", "Busiest GPU utilization.")
+		fmt.Fprintf(&b, "kkperf_gpu_busy_percent %g\n", s.GPU)
+	}
+	if len(s.GPUs) > 0 {
+		gauge("kkperf_gpu_utilization_percent", "Utilization per GPU.")
+		for _, g := range s.GPUs {
+			if g.Busy >= 0 {
+				fmt.Fprintf(&b, "kkperf_gpu_utilization_percent{gpu=\"%d\",driver=%q,name=%q} %g\n", g.Index, g.Driver, g.Name, g.Busy)
+			}
+		}
+		gauge("kkperf_gpu_temperature_celsius", "Temperature per GPU.")
+		for _, g := range s.GPUs {
+			if g.Temp > 0 {
+				fmt.Fprintf(&b, "kkperf_gpu_temperature_celsius{gpu=\"%d\",driver=%q,name=%q} %g\n", g.Index, g.Driver, g.Name, g.Temp)
+			}
+		}
+		gauge("kkperf_gpu_memory_used_bytes", "Video memory in use per GPU.")
+		for _, g := range s.GPUs {
+			if g.VRAMTotal > 0 {
+				fmt.Fprintf(&b, "kkperf_gpu_memory_used_bytes{gpu=\"%d\",driver=%q,name=%q} %d\n", g.Index, g.Driver, g.Name, g.VRAMUsed)
+			}
+		}
+		gauge("kkperf_gpu_memory_total_bytes", "Video memory per GPU.")
+		for _, g := range s.GPUs {
+			if g.VRAMTotal > 0 {
+				fmt.Fprintf(&b, "kkperf_gpu_memory_total_bytes{gpu=\"%d\",driver=%q,name=%q} %d\n", g.Index, g.Driver, g.Name, g.VRAMTotal)
+			}
+		}
+	}
+	if s.Disk >= 0 {
+		gauge("kkperf_disk_busy_percent", "Busiest disk utilization.")
+		fmt.Fprintf(&b, "kkperf_disk_busy_percent %g\n", s.Disk)
+	}
+	if len(s.Disks) > 0 {
+		gauge("kkperf_disk_read_bytes_per_second", "Disk read throughput per whole disk.")
+		for _, d := range s.Disks {
+			fmt.Fprintf(&b, "kkperf_disk_read_bytes_per_second{device=%q} %g\n", d.Device, d.ReadBytes)
+		}
+		gauge("kkperf_disk_write_bytes_per_second", "Disk write throughput per whole disk.")
+		for _, d := range s.Disks {
+			fmt.Fprintf(&b, "kkperf_disk_write_bytes_per_second{device=%q} %g\n", d.Device, d.WriteBytes)
+		}
+		gauge("kkperf_disk_iops", "Completed disk operations per second per whole disk.")
+		for _, d := range s.Disks {
+			fmt.Fprintf(&b, "kkperf_disk_iops{device=%q,op=\"read\"} %g\n", d.Device, d.ReadIOPS)
+			fmt.Fprintf(&b, "kkperf_disk_iops{device=%q,op=\"write\"} %g\n", d.Device, d.WriteIOPS)
+		}
+	}
+	if s.Net >= 0 {
+		gauge("kkperf_network_utilization_percent", "Network throughput relative to link capacity.")
+		fmt.Fprintf(&b, "kkperf_network_utilization_percent %g\n", s.Net)
+	}
+
+	if len(s.Power) > 0 {
+		gauge("kkperf_power_watts", "RAPL power per domain.")
+		for _, p := range s.Power {
+			fmt.Fprintf(&b, "kkperf_power_watts{domain=%q} %g\n", p.Domain, p.Watts)
+		}
+	}
+
+	if len(s.Fans) > 0 {
+		gauge("kkperf_fan_rpm", "Fan speed.")
+		for _, f := range s.Fans {
+			fmt.Fprintf(&b, "kkperf_fan_rpm{sensor=%q} %g\n", f.Sensor, f.RPM)
+		}
+	}
+
+	if len(s.Batteries) > 0 {
+		gauge("kkperf_battery_charge_percent", "Battery charge.")
+		for _, bat := range s.Batteries {
+			if bat.Percent >= 0 {
+				fmt.Fprintf(&b, "kkperf_battery_charge_percent{battery=%q} %g\n", bat.Name, bat.Percent)
+			}
+		}
+		gauge("kkperf_battery_discharge_watts", "Battery discharge rate, 0 while not discharging.")
+		for _, bat := range s.Batteries {
+			watts := 0.0
+			if bat.Status == "Discharging" {
+				watts = bat.Watts
+			}
+			fmt.Fprintf(&b, "kkperf_battery_discharge_watts{battery=%q} %g\n", bat.Name, watts)
+		}
+	}
+
+	if len(s.Cooling) > 0 {
+		metrics := map[string]string{
+			coolingCoolant: "kkperf_coolant_temperature_celsius",
+			coolingPump:    "kkperf_pump_rpm",
+			coolingFlow:    "kkperf_coolant_flow_lph",
+		}
+		helps := map[string]string{
+			coolingCoolant: "Liquid-cooling loop temperature.",
+			coolingPump:    "Liquid-cooling pump speed.",
+			coolingFlow:    "Liquid-cooling flow rate in liters per hour.",
+		}
+		for _, kind := range []string{coolingCoolant, coolingPump, coolingFlow} {
+			declared := false
+			for _, r := range s.Cooling {
+				if r.Kind != kind {
+					continue
+				}
+				if !declared {
+					gauge(metrics[kind], helps[kind])
+					declared = true
+				}
+				fmt.Fprintf(&b, "%s{sensor=%q} %g\n", metrics[kind], r.Sensor, r.Value)
+			}
+		}
+		failure := 0
+		if s.CoolingFailure != "" {
+			failure = 1
+		}
+		gauge("kkperf_cooling_failure", "Whether a pump, flow or coolant limit of [cooling] is exceeded.")
+		fmt.Fprintf(&b, "kkperf_cooling_failure %d\n", failure)
+	}
+
+	if s.PSUInput > 0 {
+		gauge("kkperf_psu_input_watts", "Power drawn by the power supplies.")
+		fmt.Fprintf(&b, "kkperf_psu_input_watts %g\n", s.PSUInput)
+	}
+	if s.PSUOutput > 0 {
+		gauge("kkperf_psu_output_watts", "Power delivered by the power supplies.")
+		fmt.Fprintf(&b, "kkperf_psu_output_watts %g\n", s.PSUOutput)
+	}
+	if eff := psuEfficiency(s.PSUInput, s.PSUOutput); eff > 0 {
+		gauge("kkperf_psu_efficiency_ratio", "PSU output over input power.")
+		fmt.Fprintf(&b, "kkperf_psu_efficiency_ratio %g\n", eff)
+	}
+	if len(s.PSURails) > 0 {
+		gauge("kkperf_psu_rail_volts", "PSU output rail voltage.")
+		for _, r := range s.PSURails {
+			fmt.Fprintf(&b, "kkperf_psu_rail_volts{rail=%q} %g\n", r.Rail, r.Volts)
+		}
+	}
+
+	stress := 0
+	if s.Stress {
+		stress = 1
+	}
+	gauge("kkperf_stress_running", "Whether the built-in stress test is running.")
+	fmt.Fprintf(&b, "kkperf_stress_running %d\n", stress)
+
+	gauge("kkperf_last_sample_timestamp_seconds", "Unix time of the sample.")
+	fmt.Fprintf(&b, "kkperf_last_sample_timestamp_seconds %d\n", s.Time.Unix())
+
+	return b.String()
+}
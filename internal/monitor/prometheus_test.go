@@ -0,0 +1,128 @@
+package monitor
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPrometheusMetrics checks the exposition text of fixed samples: the
+// values and labels node_exporter serves, metric families left out when
+// their source is unavailable, and a HELP and TYPE line before every
+// family, once.
+func TestPrometheusMetrics(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		sample Sample
+		want   []string // Lines that must be present
+		absent []string // Metric families that must not be
+	}{
+		{
+			name:   "minimal",
+			sample: Sample{Time: time.Unix(1700000000, 0), CPU: 12.5, Cores: []float64{10, 15}, GPU: -1, Disk: -1, Net: -1, Entropy: -1},
+			want: []string{
+				"# HELP kkperf_cpu_usage_percent Total CPU usage.",
+				"# TYPE kkperf_cpu_usage_percent gauge",
+				"kkperf_cpu_usage_percent 12.5",
+				`kkperf_core_usage_percent{core="0"} 10`,
+				`kkperf_core_usage_percent{core="1"} 15`,
+				"# TYPE kkperf_clock_steps_total counter",
+				"kkperf_clock_steps_total 0",
+				"kkperf_stress_running 0",
+				"kkperf_health_warning 0",
+				"kkperf_last_sample_timestamp_seconds 1700000000",
+			},
+			absent: []string{
+				"kkperf_temperature_celsius", "kkperf_core_temperature_celsius", "kkperf_container_cpu_limit",
+				"kkperf_memory_used_bytes", "kkperf_gpu_busy_percent", "kkperf_disk_busy_percent",
+				"kkperf_network_utilization_percent", "kkperf_entropy_bits", "kkperf_load_average",
+				"kkperf_power_watts", "kkperf_cooling_failure", "kkperf_clock_synced",
+			},
+		},
+		{
+			name: "full",
+			sample: Sample{
+				Time: time.Unix(1700000060, 0), CPU: 99.5, Cores: []float64{99, 100}, CoreTemps: []float64{88, 0},
+				Temp: 91.25, RawTemp: 93.25, TjMax: 100, Limited: true, Headroom: 8.75, Stress: true,
+				Container: "docker", CPULimit: 1.5, CPUThrottled: 20,
+				GPU: 40, GPUs: []GPUReading{{Index: 0, Driver: "amdgpu", Name: "RX 7900", Busy: 40, Temp: 65, VRAMUsed: 1 << 30, VRAMTotal: 1 << 34}},
+				Disk: 30, Disks: []DiskIO{{Device: "nvme0n1", ReadBytes: 1.5e6, WriteBytes: 2e6, ReadIOPS: 100, WriteIOPS: 50}},
+				Net: 5, Load: []float64{1.5, 1, 0.5}, Running: 3, Entropy: 256,
+				Power:   []DomainPower{{Domain: "package-0", Watts: 150}},
+				Cooling: []CoolingReading{{Sensor: "d5next/Coolant", Kind: coolingCoolant, Value: 50}}, CoolingFailure: "Coolant too hot",
+				MemUsed: 4 << 30, MemTotal: 16 << 30, HealthWarning: "zombies",
+				ClockSource: "chrony", ClockSynced: true, ClockOffset: -0.002,
+			},
+			want: []string{
+				"kkperf_cpu_usage_percent 99.5",
+				`kkperf_container_cpu_limit{runtime="docker"} 1.5`,
+				"kkperf_container_throttled_percent 20",
+				`kkperf_core_temperature_celsius{core="0"} 88`,
+				"kkperf_temperature_celsius 91.25",
+				"kkperf_temperature_raw_celsius 93.25",
+				"kkperf_thermal_headroom_celsius 8.75",
+				"kkperf_tjmax_celsius 100",
+				"kkperf_memory_used_bytes 4294967296",
+				"kkperf_memory_total_bytes 17179869184",
+				"kkperf_health_warning 1",
+				"kkperf_entropy_bits 256",
+				`kkperf_clock_synced{source="chrony"} 1`,
+				"kkperf_clock_offset_seconds -0.002",
+				`kkperf_load_average{period="15"} 0.5`,
+				"kkperf_runnable_tasks 3",
+				`kkperf_gpu_utilization_percent{gpu="0",driver="amdgpu",name="RX 7900"} 40`,
+				`kkperf_gpu_memory_total_bytes{gpu="0",driver="amdgpu",name="RX 7900"} 17179869184`,
+				`kkperf_disk_read_bytes_per_second{device="nvme0n1"} 1.5e+06`,
+				`kkperf_disk_iops{device="nvme0n1",op="write"} 50`,
+				"kkperf_network_utilization_percent 5",
+				`kkperf_power_watts{domain="package-0"} 150`,
+				`kkperf_coolant_temperature_celsius{sensor="d5next/Coolant"} 50`,
+				"kkperf_cooling_failure 1",
+				"kkperf_stress_running 1",
+			},
+			absent: []string{`kkperf_core_temperature_celsius{core="1"}`, "kkperf_pump_rpm", "kkperf_battery_charge_percent"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			text := prometheusMetrics(&tc.sample)
+			lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+			present := map[string]bool{}
+			for _, line := range lines {
+				present[line] = true
+			}
+			for _, line := range tc.want {
+				if !present[line] {
+					t.Errorf("missing %q", line)
+				}
+			}
+			for _, family := range tc.absent {
+				if strings.Contains(text, family) {
+					t.Errorf("%s present without its source", family)
+				}
+			}
+
+			declared := map[string]bool{}
+			for _, line := range lines {
+				fields := strings.Fields(line)
+				switch {
+				case strings.HasPrefix(line, "# TYPE "):
+					if declared[fields[2]] {
+						t.Errorf("%s declared twice", fields[2])
+					}
+					declared[fields[2]] = true
+				case strings.HasPrefix(line, "#"):
+				default:
+					// Label values may hold spaces; the value is after the last
+					name, value := line[:strings.LastIndexByte(line, ' ')+1], fields[len(fields)-1]
+					if i := strings.IndexAny(name, "{ "); i >= 0 {
+						name = name[:i]
+					}
+					if _, err := strconv.ParseFloat(value, 64); !declared[name] || err != nil {
+						t.Errorf("undeclared or malformed sample line %q", line)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PSURail is the voltage of one PSU output rail.
+type PSURail struct {
+	Rail  string  // Rail name, e.g. "+12V", or the PMBus label ("vout1")
+	Volts float64 // Measured voltage
+}
+
+// psuChip is one power supply found in hwmon, with the channels that
+// matter here. Empty paths are channels the PSU does not report.
+type psuChip struct {
+	powerIn, powerOut  string   // Input power ("pin"), total output power ("power total")
+	voltsIn, currentIn string   // For computing input power when it is not reported
+	powerOuts          []string // Per-output power ("pout1", ...), summed without a total
+	rails              []string // Output voltages
+	railNames          []string
+}
+
+// psuSampler reads input and output power and rail voltages from power
+// supplies with a hwmon driver: corsair-psu, which calls its channels
+// "power total", "v_in" and "v_out +12v", and the PMBus drivers, which
+// use the PMBus names "pin", "pout1", "vin" and "vout1".
+type psuSampler struct {
+	chips []psuChip
+}
+
+// psuReading is one reading of the power supplies, with power summed
+// over all of them.
+type psuReading struct {
+	input, output float64 // Watts, 0 when not reported
+	rails         []PSURail
+}
+
+// newPSUSampler finds the power supplies among the hwmon chips.
+func newPSUSampler() *psuSampler {
+	p := &psuSampler{}
+	chips, _ := filepath.Glob(filepath.Join(hwmonDir, "hwmon*"))
+	sort.Slice(chips, func(i, j int) bool { return naturalLess(chips[i], chips[j]) })
+	for _, dir := range chips {
+		var chip psuChip
+		inputs, _ := filepath.Glob(filepath.Join(dir, "*_input"))
+		sort.Slice(inputs, func(i, j int) bool { return naturalLess(inputs[i], inputs[j]) })
+		for _, input := range inputs {
+			channel := strings.TrimSuffix(filepath.Base(input), "_input")
+			label := strings.ToLower(readSysfsString(filepath.Join(dir, channel+"_label")))
+			switch {
+			case strings.HasPrefix(channel, "power") && label == "pin":
+				chip.powerIn = input
+			case strings.HasPrefix(channel, "power") && label == "power total":
+				chip.powerOut = input
+			case strings.HasPrefix(channel, "power") && strings.HasPrefix(label, "pout"):
+				chip.powerOuts = append(chip.powerOuts, input)
+			case strings.HasPrefix(channel, "in") && (label == "vin" || label == "v_in"):
+				chip.voltsIn = input
+			case strings.HasPrefix(channel, "curr") && (label == "iin" || label == "curr in"):
+				chip.currentIn = input
+			case strings.HasPrefix(channel, "in") && strings.HasPrefix(label, "v_out "):
+				chip.rails = append(chip.rails, input)
+				chip.railNames = append(chip.railNames, strings.ToUpper(strings.TrimPrefix(label, "v_out ")))
+			case strings.HasPrefix(channel, "in") && strings.HasPrefix(label, "vout"):
+				chip.rails = append(chip.rails, input)
+				chip.railNames = append(chip.railNames, label)
+			}
+		}
+		// Voltage regulators on PMBus report vout too; only chips with a
+		// power reading are supplies worth showing
+		if chip.powerIn != "" || chip.powerOut != "" || len(chip.powerOuts) > 0 {
+			p.chips = append(p.chips, chip)
+		}
+	}
+	return p
+}
+
+// available reports whether a power supply was found.
+func (p *psuSampler) available() bool {
+	return len(p.chips) > 0
+}
+
+// readHwmonScaled reads a hwmon value in milli- or micro-units and
+// converts it, returning 0 when it cannot be read.
+func readHwmonScaled(path string, scale float64) float64 {
+	if path == "" {
+		return 0
+	}
+	v, err := strconv.ParseFloat(readSysfsString(path), 64)
+	if err != nil {
+		return 0
+	}
+	return v * scale
+}
+
+// sample reads the power supplies. Input power that is not reported is
+// computed from the input voltage and current.
+func (p *psuSampler) sample() psuReading {
+	var r psuReading
+	for _, chip := range p.chips {
+		in := readHwmonScaled(chip.powerIn, 1e-6)
+		if in == 0 {
+			in = readHwmonScaled(chip.voltsIn, 1e-3) * readHwmonScaled(chip.currentIn, 1e-3)
+		}
+		out := readHwmonScaled(chip.powerOut, 1e-6)
+		if chip.powerOut == "" {
+			for _, path := range chip.powerOuts {
+				out += readHwmonScaled(path, 1e-6)
+			}
+		}
+		r.input += in
+		r.output += out
+		for i, path := range chip.rails {
+			if v := readHwmonScaled(path, 1e-3); v > 0 {
+				r.rails = append(r.rails, PSURail{Rail: chip.railNames[i], Volts: v})
+			}
+		}
+	}
+	return r
+}
+
+// psuEfficiency returns output over input power, or 0 when either is
+// unknown or the readings are implausible.
+func psuEfficiency(input, output float64) float64 {
+	if input <= 0 || output <= 0 || output > input {
+		return 0
+	}
+	return output / input
+}
+
+// displayPSU prints the power supply readings on one line when a PSU
+// with a hwmon driver is present.
+func (m *Monitor) displayPSU() {
+	if !m.psu.available() {
+		return
+	}
+	r := m.psuReading
+	var parts []string
+	if r.input > 0 {
+		parts = append(parts, fmt.Sprintf(tr("%.0f W in"), r.input))
+	}
+	if r.output > 0 {
+		parts = append(parts, fmt.Sprintf(tr("%.0f W out"), r.output))
+	}
+	if eff := psuEfficiency(r.input, r.output); eff > 0 {
+		parts = append(parts, fmt.Sprintf(tr("%s efficiency"), formatPercent(eff*100, 1)))
+	}
+	for _, rail := range r.rails {
+		parts = append(parts, fmt.Sprintf("%s %.2f V", rail.Rail, rail.Volts))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "--")
+	}
+	fmt.Fprintf(m.out, "%s%s%s %s\r\n\r\n", colorBlue, tr("PSU:"), colorReset, strings.Join(parts, "  "))
+}
+
+// drawPSUGraph plots PSU input and output power with total CPU usage, so
+// the wall-side cost of a load can be read off during a stress run. CPU
+// usage is drawn against the same height, 100% at the top of the axis.
+func (m *Monitor) drawPSUGraph() {
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, tr("PSU Power Graph"), colorReset)
+	if !m.psu.available() {
+		fmt.Fprintf(m.out, "        %s\r\n", tr("No power supply with a hwmon driver found (corsair-psu or PMBus)"))
+		fmt.Fprintf(m.out, "        %s%-10s%s\r\n", colorCyan, formatWindow(m.window), colorReset)
+		return
+	}
+
+	// The axis fits the power readings, rounded up to 50 W on an auto axis
+	var readings []float64
+	for _, p := range m.displayBuffer {
+		readings = append(readings, p.psuIn, p.psuOut)
+	}
+	axis := newYAxis(m.cfg.PSUGraphScale, readings, 0, 50)
+
+	var latest historyPoint
+	if len(m.displayBuffer) > 0 {
+		latest = m.displayBuffer[len(m.displayBuffer)-1]
+	}
+	watts := func(w float64) string {
+		if w <= 0 {
+			return "--"
+		}
+		return unitWatts.format(w, 0)
+	}
+	m.drawSeriesGraph(seriesGraph{
+		names: []string{tr("Input"), tr("Output"), tr("CPU")},
+		value: func(p historyPoint, series int) float64 {
+			switch series {
+			case 0:
+				if p.psuIn <= 0 {
+					return -1
+				}
+				return p.psuIn
+			case 1:
+				if p.psuOut <= 0 {
+					return -1
+				}
+				return p.psuOut
+			}
+			return axis.value(p.cpu / 100)
+		},
+		scale: axis,
+		unit:  unitWatts,
+		legend: func(series int) string {
+			switch series {
+			case 0:
+				return watts(latest.psuIn)
+			case 1:
+				return watts(latest.psuOut)
+			}
+			return formatPercent(latest.cpu, 0)
+		},
+	})
+}
@@ -0,0 +1,181 @@
+package monitor
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// raplDir is the powercap tree holding the Intel RAPL energy counters.
+var raplDir = "/sys/class/powercap"
+
+// DomainPower is the average power of one RAPL domain since the previous
+// sample.
+type DomainPower struct {
+	Domain string  // e.g. "package-0", "core", "uncore", "dram", "psys"
+	Watts  float64 // Average power in W
+}
+
+// raplDomain is one energy counter in the powercap tree.
+type raplDomain struct {
+	name     string
+	path     string  // energy_uj file
+	maxRange float64 // Counter range in µJ, for wraparound
+	last     float64 // Previous reading in µJ
+}
+
+// raplSampler turns the RAPL energy counters into per-domain power.
+// Since kernel 5.10 energy_uj is readable by root only, so it is read
+// through the privilege helper when denied; domains that cannot be read
+// are left out.
+type raplSampler struct {
+	domains  []raplDomain
+	lastTime time.Time
+
+	packageJoules float64 // Package energy since startup, summed over sockets
+	elapsed       float64 // Seconds the energy was measured over
+}
+
+// newRAPLSampler discovers the package domains and their core, uncore
+// and DRAM subdomains and takes the first reading. AMD processors appear
+// in the powercap tree too since kernel 5.8; on older kernels the
+// amd_energy hwmon driver stands in.
+func newRAPLSampler() *raplSampler {
+	r := &raplSampler{lastTime: timeNow()}
+	zones, _ := filepath.Glob(filepath.Join(raplDir, "intel-rapl:*"))
+	sort.Slice(zones, func(i, j int) bool { return naturalLess(zones[i], zones[j]) })
+
+	packages := 0
+	for _, zone := range zones {
+		if strings.Count(filepath.Base(zone), ":") == 1 && strings.HasPrefix(readSysfsString(filepath.Join(zone, "name")), "package") {
+			packages++
+		}
+	}
+
+	for _, zone := range zones {
+		energy, err := strconv.ParseFloat(readPrivilegedString(filepath.Join(zone, "energy_uj")), 64)
+		if err != nil {
+			logInfo("RAPL zone not readable", "zone", filepath.Base(zone))
+			continue
+		}
+		name := readSysfsString(filepath.Join(zone, "name"))
+		if name == "" {
+			continue
+		}
+		// Subdomains share names across sockets; tag them with the socket
+		if parts := strings.Split(filepath.Base(zone), ":"); len(parts) == 3 && packages > 1 {
+			name += "-" + parts[1]
+		}
+		maxRange, _ := strconv.ParseFloat(readSysfsString(filepath.Join(zone, "max_energy_range_uj")), 64)
+		r.domains = append(r.domains, raplDomain{
+			name: name, path: filepath.Join(zone, "energy_uj"), maxRange: maxRange, last: energy,
+		})
+	}
+	if len(r.domains) == 0 {
+		r.discoverAMDEnergy()
+	}
+	if len(r.domains) == 0 {
+		logInfo("no RAPL or amd_energy counters readable")
+	} else {
+		logInfo("RAPL domains", "names", strings.Join(r.domainNames(), ","))
+	}
+	return r
+}
+
+// discoverAMDEnergy adds the socket counters of the amd_energy hwmon
+// driver as package domains. Its energy*_input files count µJ like
+// energy_uj, in 64 bits that do not wrap; the per-core counters beside
+// them are left out, as there are hundreds on large EPYC parts.
+func (r *raplSampler) discoverAMDEnergy() {
+	chips, _ := filepath.Glob(filepath.Join(hwmonDir, "hwmon*"))
+	sort.Slice(chips, func(i, j int) bool { return naturalLess(chips[i], chips[j]) })
+	for _, dir := range chips {
+		if readSysfsString(filepath.Join(dir, "name")) != "amd_energy" {
+			continue
+		}
+		inputs, _ := filepath.Glob(filepath.Join(dir, "energy*_input"))
+		sort.Slice(inputs, func(i, j int) bool { return naturalLess(inputs[i], inputs[j]) })
+		for _, input := range inputs {
+			label := readSysfsString(strings.TrimSuffix(input, "_input") + "_label")
+			if !strings.HasPrefix(label, "Esocket") {
+				continue
+			}
+			energy, err := strconv.ParseFloat(readPrivilegedString(input), 64)
+			if err != nil {
+				continue
+			}
+			r.domains = append(r.domains, raplDomain{
+				name: "package-" + strings.TrimPrefix(label, "Esocket"), path: input, last: energy,
+			})
+		}
+	}
+}
+
+// sample returns each domain's average power since the previous call,
+// or nil when RAPL is not available.
+func (r *raplSampler) sample() []DomainPower {
+	if len(r.domains) == 0 {
+		return nil
+	}
+	now := timeNow()
+	dt := now.Sub(r.lastTime).Seconds()
+	r.lastTime = now
+
+	power := make([]DomainPower, len(r.domains))
+	for i := range r.domains {
+		d := &r.domains[i]
+		power[i].Domain = d.name
+		energy, err := strconv.ParseFloat(readPrivilegedString(d.path), 64)
+		if err != nil {
+			continue
+		}
+		delta := energy - d.last
+		if delta < 0 {
+			delta += d.maxRange
+		}
+		if delta < 0 {
+			delta = 0 // A counter without a range was reset
+		}
+		d.last = energy
+		if dt > 0 {
+			power[i].Watts = delta / 1e6 / dt
+		}
+		if strings.HasPrefix(d.name, "package") {
+			r.packageJoules += delta / 1e6
+		}
+	}
+	if dt > 0 {
+		r.elapsed += dt
+	}
+	return power
+}
+
+// hasPackage reports whether a package domain was found.
+func (r *raplSampler) hasPackage() bool {
+	for _, d := range r.domains {
+		if strings.HasPrefix(d.name, "package") {
+			return true
+		}
+	}
+	return false
+}
+
+// averageWatts returns the mean package power since startup and the
+// energy it adds up to in Wh, 0 before the second reading.
+func (r *raplSampler) averageWatts() (watts, wattHours float64) {
+	if r.elapsed == 0 {
+		return 0, 0
+	}
+	return r.packageJoules / r.elapsed, r.packageJoules / 3600
+}
+
+// domainNames lists the RAPL domains in sample order.
+func (r *raplSampler) domainNames() []string {
+	names := make([]string, len(r.domains))
+	for i, d := range r.domains {
+		names[i] = d.name
+	}
+	return names
+}
@@ -0,0 +1,126 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// castRecorder records the frames drawn to the terminal as an asciinema
+// v2 cast: a JSON header line, then one [seconds, "o", text] line per
+// frame. Frames are kept at most fps times a second: as each frame
+// redraws every line from the top, one that is superseded before it is
+// due can be dropped, unless it clears the screen, which the next frame
+// then keeps in front of its own output.
+type castRecorder struct {
+	file     *os.File
+	w        *bufio.Writer
+	start    time.Time
+	last     time.Time     // When the last frame was written
+	interval time.Duration // Time between written frames
+	frame    []byte        // Output of the frame being drawn
+	pending  []byte        // Latest complete frame not yet written
+}
+
+// newCastRecorder creates the cast file at path with a header for a
+// terminal of the given size.
+func newCastRecorder(path string, width, height, fps int) (*castRecorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("record: %v", err)
+	}
+	r := &castRecorder{file: f, w: bufio.NewWriter(f), start: time.Now(), interval: time.Second / time.Duration(fps)}
+	host, _ := os.Hostname()
+	header, _ := json.Marshal(map[string]interface{}{
+		"version": 2, "width": width, "height": height, "timestamp": r.start.Unix(),
+		"title": "kkperf on " + host, "env": map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	})
+	r.w.Write(append(header, '\n'))
+	return r, nil
+}
+
+// Write collects the output of the frame being drawn.
+func (r *castRecorder) Write(p []byte) (int, error) {
+	r.frame = append(r.frame, p...)
+	return len(p), nil
+}
+
+// endFrame ends a frame drawn at now and writes the latest one when it is
+// due.
+func (r *castRecorder) endFrame(now time.Time) {
+	if len(r.frame) == 0 {
+		return
+	}
+	if bytes.Contains(r.pending, []byte(clearScreen)) || bytes.Contains(r.frame, []byte(clearScreen)) {
+		r.pending = append(r.pending, r.frame...)
+	} else {
+		r.pending = append(r.pending[:0], r.frame...)
+	}
+	r.frame = r.frame[:0]
+	if now.Sub(r.last) >= r.interval {
+		r.flush(now)
+	}
+}
+
+// flush writes the pending frame as an event at now.
+func (r *castRecorder) flush(now time.Time) {
+	if len(r.pending) == 0 {
+		return
+	}
+	event, _ := json.Marshal([]interface{}{now.Sub(r.start).Seconds(), "o", string(r.pending)})
+	r.w.Write(append(event, '\n'))
+	r.pending = r.pending[:0]
+	r.last = now
+}
+
+// close writes what is left, including output after the last frame such
+// as the exit message, and closes the file.
+func (r *castRecorder) close() error {
+	r.endFrame(time.Now())
+	r.flush(time.Now())
+	if err := r.w.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// startRecording records everything drawn to the terminal to a cast file
+// at path, at the size of the terminal.
+func (m *Monitor) startRecording(path string) error {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 40
+	}
+	r, err := newCastRecorder(path, width, height, m.cfg.Record.FPS)
+	if err != nil {
+		return err
+	}
+	m.recorder = r
+	m.out = io.MultiWriter(m.out, r)
+	return nil
+}
+
+// recordCastFrame ends a frame in the recording, if there is one.
+func (m *Monitor) recordCastFrame(now time.Time) {
+	if m.recorder != nil {
+		m.recorder.endFrame(now)
+	}
+}
+
+// stopRecording closes the recording, if there is one.
+func (m *Monitor) stopRecording() {
+	if m.recorder == nil {
+		return
+	}
+	if err := m.recorder.close(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+	m.recorder = nil
+}
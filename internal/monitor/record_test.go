@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCastRecorder checks the asciinema cast of a few frames: a frame
+// superseded before it is due is dropped, but not the screen clear it
+// carried.
+func TestCastRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.cast")
+	r, err := newCastRecorder(path, 100, 40, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := r.start
+	for i, frame := range []string{moveCursor + "one", clearScreen + "two", moveCursor + "three", moveCursor + "four"} {
+		r.Write([]byte(frame))
+		r.endFrame(at.Add(time.Duration(i) * 40 * time.Millisecond))
+	}
+	if err := r.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var header struct{ Version, Width, Height int }
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil || header.Version != 2 || header.Width != 100 {
+		t.Fatalf("header %s: %v", lines[0], err)
+	}
+	var output []string
+	for _, line := range lines[1:] {
+		var event []interface{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil || len(event) != 3 || event[1] != "o" {
+			t.Fatalf("event %s: %v", line, err)
+		}
+		output = append(output, event[2].(string))
+	}
+	// "one" is due at once; "two" and "three" come within 100ms of it
+	// and are held, "three" behind the clear of "two", until "four" is
+	// due at 120ms
+	want := []string{moveCursor + "one", clearScreen + "two" + moveCursor + "three" + moveCursor + "four"}
+	if strings.Join(output, "|") != strings.Join(want, "|") {
+		t.Errorf("events %q, want %q", output, want)
+	}
+}
@@ -0,0 +1,249 @@
+package monitor
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// remoteSource follows the monitor of another machine for kkperf connect.
+// It polls the /sample endpoint of the remote's HTTP server in the
+// background, as the fleet table does, so a slow network never holds up
+// the display; the main loop takes each new sample as it arrives.
+type remoteSource struct {
+	client *http.Client
+	token  string
+	stop   chan struct{}
+
+	mu     sync.Mutex
+	agent  fleetAgent // Latest successful poll; err is set while the remote does not answer
+	failed time.Time  // When the remote stopped answering
+
+	last Sample // Latest sample the display took
+}
+
+// newRemoteSource starts polling the agent every interval. The agent must
+// have answered once, so the display knows the remote's cores.
+func newRemoteSource(client *http.Client, token string, agent *fleetAgent, interval time.Duration) *remoteSource {
+	r := &remoteSource{client: client, token: token, stop: make(chan struct{}), agent: *agent}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+			}
+			a := fleetAgent{addr: agent.addr, host: agent.host}
+			pollFleetAgent(client, token, &a)
+			r.mu.Lock()
+			if a.sample != nil {
+				r.agent, r.failed = a, time.Time{}
+			} else {
+				if r.agent.err == nil {
+					r.failed = timeNow()
+				}
+				r.agent.err = a.err
+			}
+			r.mu.Unlock()
+		}
+	}()
+	return r
+}
+
+// fresh reports whether a sample has arrived since the display took the
+// last one.
+func (r *remoteSource) fresh() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.agent.sample != nil && !r.agent.sample.Time.Equal(r.last.Time)
+}
+
+// take returns the latest sample and marks it taken.
+func (r *remoteSource) take() Sample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last = *r.agent.sample
+	return r.last
+}
+
+// status returns the remote's hostname and, while it does not answer, why
+// and since when.
+func (r *remoteSource) status() (host string, err error, since time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.agent.host, r.agent.err, r.failed
+}
+
+// close stops polling.
+func (r *remoteSource) close() {
+	close(r.stop)
+}
+
+// remoteBlocked are the actions ignored while connected to a remote
+// monitor: the load generators and the sensor picker would act on this
+// machine, and the clock bars and scatter and the detail pages need
+// readings the remote's samples do not carry.
+var remoteBlocked = map[keyAction]bool{
+	actionStress: true, actionNetStress: true, actionDiskStress: true, actionSensors: true,
+	actionFreqBars: true, actionOverclock: true, actionBandwidth: true, actionScatter: true,
+	actionWakeups: true, actionAttribution: true, actionTopProcs: true, actionTimedStress: true,
+}
+
+// connect makes the monitor display the samples of r instead of this
+// machine. The samplers whose state the frame reads directly are replaced
+// by empty ones, so nothing local shows up next to the remote's readings;
+// the ones the samples stand in for are filled in by applyRemote.
+func (m *Monitor) connect(r *remoteSource) {
+	m.remote = r
+	m.stressAvailable = true
+	m.container, m.ambient = nil, nil
+	m.sensors, m.tempSensorID, m.cfg.SecondarySensors = nil, "", nil
+	m.clocks = &clockSampler{cores: make([]coreClock, m.cores)}
+	m.smu = &smuSampler{}
+	m.rapl = &raplSampler{}
+	m.psu = &psuSampler{}
+	m.cooling = &coolingSampler{}
+	m.coreTemps = &coreTempSampler{}
+	m.entropy = &entropySampler{}
+	m.blocked = &blockedTracker{}
+	m.clock = &clockWatch{}
+	m.coreGroups = chunkCoreGroups(m.cores) // The cache layout is this machine's
+}
+
+// nextSample polls the collectors, or takes the latest sample of the
+// remote monitor when connected to one.
+func (m *Monitor) nextSample() Sample {
+	if m.remote == nil {
+		return m.pollSample()
+	}
+	s := m.remote.take()
+	m.applyRemote(&s)
+	return s
+}
+
+// applyRemote updates the state the frame reads outside the sample: the
+// stress state, the I/O wait line, the entropy pool, the clock line, and
+// the RAPL domains with the energy behind the average power.
+func (m *Monitor) applyRemote(s *Sample) {
+	m.stressRunning = s.Stress
+	m.rawTemp = s.RawTemp
+	m.iowaitUsage, m.blocked.count = s.IOWait, s.Blocked
+	m.entropy.poolSize = s.EntropyPool
+
+	m.clock.status = clockStatus{source: s.ClockSource, synced: s.ClockSynced, offset: s.ClockOffset, hasOffset: s.ClockOffset != 0}
+	m.clock.steps = s.ClockSteps
+	if s.ClockStep != 0 {
+		m.clock.lastStep, m.clock.stepAt = time.Duration(s.ClockStep*float64(time.Second)), s.Time
+	}
+
+	if len(m.rapl.domains) != len(s.Power) {
+		m.rapl.domains = make([]raplDomain, len(s.Power))
+	}
+	for i, p := range s.Power {
+		m.rapl.domains[i].name = p.Domain
+	}
+	if watts, ok := packagePower(s); ok && !m.rapl.lastTime.IsZero() {
+		dt := s.Time.Sub(m.rapl.lastTime).Seconds()
+		m.rapl.packageJoules += watts * dt
+		m.rapl.elapsed += dt
+	}
+	m.rapl.lastTime = s.Time
+}
+
+// remoteStatus returns the status line tag of a remote monitor: its
+// hostname, or how long it has not answered.
+func (m *Monitor) remoteStatus() string {
+	if m.remote == nil {
+		return ""
+	}
+	host, err, since := m.remote.status()
+	if err != nil {
+		return fmt.Sprintf("  %s[%s %s %s]%s", colorRed, host, tr("UNREACHABLE"), timeNow().Sub(since).Round(time.Second), colorReset)
+	}
+	return fmt.Sprintf("  %s[%s %s]%s", colorCyan, tr("REMOTE"), host, colorReset)
+}
+
+// runConnect implements the "connect" subcommand: the TUI drawn from the
+// samples of a monitor on another machine, polled from the /sample
+// endpoint of kkperf-agent --listen, so a headless server can be watched
+// without running the TUI on it.
+func runConnect(args []string) int {
+	path := configPath()
+	fs := flag.NewFlagSet("connect", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&path, "c", path, "")
+	fs.StringVar(&path, "config", path, "")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Println(connectUsage)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Error: give the address of one agent, HOST:PORT")
+		return 1
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	activeLocale = resolveLocale(cfg)
+	if err := applyTheme(cfg.Theme); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := cfg.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintln(os.Stderr, "Error: standard input is not a terminal; use kkperf fleet to print a remote's readings")
+		return 1
+	}
+
+	client := &http.Client{Timeout: fleetTimeout}
+	agent := &fleetAgent{addr: fs.Arg(0), host: fs.Arg(0)}
+	pollFleetAgent(client, cfg.Fleet.Token, agent)
+	if agent.sample == nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", agent.addr, agent.err)
+		return 1
+	}
+	if len(agent.sample.Cores) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s has not polled its cores yet; try again\n", agent.addr)
+		return 1
+	}
+
+	cfg.StartupChecks = false // They would check this machine
+	m := newMonitor(cfg, len(agent.sample.Cores))
+	m.connect(newRemoteSource(client, cfg.Fleet.Token, agent, cfg.PollInterval))
+	defer m.cleanup()
+	m.run()
+	return 0
+}
+
+const connectUsage = `Usage: kkperf connect [options] HOST:PORT
+
+Show the display of another machine, drawn from the samples of a
+kkperf-agent (or kkperf) started there with --listen, so a headless server
+can be watched without running the TUI on it. The remote is polled at the
+poll_interval of this machine's config, with the token of its [fleet]
+section.
+
+Options:
+  -c, --config PATH    Use an alternate config file
+
+The stress keys, the sensor picker, the clock bars and the detail pages
+that need readings the samples do not carry are disabled. The status line
+shows the remote's hostname, or how long it has not answered.`
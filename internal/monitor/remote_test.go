@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestConnect checks that a monitor connected to a remote one shows the
+// remote's samples, tags the status line and ignores the stress key, and
+// that the remote running stress above the local safety limit does not
+// trigger a safety stop here.
+func TestConnect(t *testing.T) {
+	safetyLog := filepath.Join(t.TempDir(), "safety.log")
+	m, _, _ := fixtureMonitor(t, "4cores", func(cfg *Config) { cfg.Safety.Log = safetyLog }, nil)
+	h := &httpSink{}
+	h.write(&Sample{Time: time.Unix(100, 0), CPU: 88, Cores: []float64{88, 88, 88, 88}, Temp: 96, Stress: true,
+		Power: []DomainPower{{Domain: "package-0", Watts: 150}}})
+	server := httptest.NewServer(http.HandlerFunc(h.serveSample))
+	defer server.Close()
+	client := &http.Client{Timeout: fleetTimeout}
+	agent := &fleetAgent{addr: server.URL, host: server.URL}
+	if pollFleetAgent(client, "", agent); agent.sample == nil {
+		t.Fatalf("remote did not answer: %v", agent.err)
+	}
+	r := newRemoteSource(client, "", agent, time.Hour)
+	defer r.close()
+	m.connect(r)
+
+	if m.pollingSuspended() {
+		t.Fatal("remote sample not taken")
+	}
+	usage, temp := m.pollTick()
+	if temp != 96 || !m.stressRunning || !m.pollingSuspended() {
+		t.Errorf("after the first sample: %.1f°C, stress %v, next poll suspended %v", temp, m.stressRunning, m.pollingSuspended())
+	}
+	if _, err := os.Stat(safetyLog); m.safetyStop != "" || err == nil {
+		t.Errorf("remote at 96°C: safety stop %q, safety log written %v; want neither", m.safetyStop, err == nil)
+	}
+	if names := m.rapl.domainNames(); len(names) != 1 || names[0] != "package-0" {
+		t.Errorf("RAPL domains %v, want the remote's", names)
+	}
+	m.handleMainKey(' ')
+	if !m.stressRunning || m.stressEngine != nil {
+		t.Error("stress key acted on this machine")
+	}
+	if frame := renderFrame(m, usage, temp); !strings.Contains(frame, "[REMOTE ") || !strings.Contains(frame, "[STRESS ON]") {
+		t.Errorf("frame without the remote's state:\n%s", frame)
+	}
+}
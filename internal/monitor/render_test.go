@@ -0,0 +1,366 @@
+package monitor
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// Screen size the golden frames are rendered at: wider and taller than
+// the 80x40 minimum so that overflowing layouts show up in the output
+// instead of scrolling away.
+const (
+	goldenWidth  = 100
+	goldenHeight = 60
+)
+
+// fixtureMonitor points the collectors at a copy of a fixture tree from
+// testdata/fixtures and returns a monitor that has polled every frame of
+// it, with the total CPU usage and temperature of the last poll. open
+// runs before the first poll, for pages that sample while they are open.
+//
+// A fixture holds proc/ and sys/ files as of the first poll. Each
+// frames/N directory holds the files that change for poll N and is laid
+// over the copy before that poll.
+func fixtureMonitor(t *testing.T, fixture string, setup func(cfg *Config), open func(m *Monitor)) (*Monitor, float64, float64) {
+	t.Helper()
+	src := filepath.Join("testdata", "fixtures", fixture)
+	dir := t.TempDir()
+	copyTree(t, src, dir, "frames")
+
+	saved := []*string{&procDir, &sysDir, &hwmonDir, &thermalDir, &cpuDir, &msrDir, &raplDir, &smuDir, &resctrlDir, &passwdFile, &cgroupDir, &containerRoot}
+	values := make([]string, len(saved))
+	for i, p := range saved {
+		values[i] = *p
+	}
+	savedNumCPU, savedTimeNow, savedClockQuery := numCPU, timeNow, clockQuery
+	t.Cleanup(func() {
+		for i, p := range saved {
+			*p = values[i]
+		}
+		numCPU, timeNow, clockQuery = savedNumCPU, savedTimeNow, savedClockQuery
+	})
+	// No time daemon, whatever the machine running the test has
+	clockQuery = func() clockStatus { return clockStatus{} }
+	// Polls are exactly 500ms apart
+	clock := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return clock }
+
+	procDir = filepath.Join(dir, "proc")
+	sysDir = filepath.Join(dir, "sys")
+	hwmonDir = filepath.Join(sysDir, "class", "hwmon")
+	thermalDir = filepath.Join(sysDir, "class", "thermal")
+	cpuDir = filepath.Join(sysDir, "devices", "system", "cpu")
+	msrDir = filepath.Join(dir, "dev", "cpu")
+	raplDir = filepath.Join(sysDir, "class", "powercap")
+	smuDir = filepath.Join(sysDir, "kernel", "ryzen_smu_drv")
+	resctrlDir = filepath.Join(sysDir, "fs", "resctrl")
+	passwdFile = filepath.Join(dir, "etc", "passwd")
+	cgroupDir = filepath.Join(sysDir, "fs", "cgroup")
+	containerRoot = dir
+	cores := fixtureCores(t, filepath.Join(procDir, "stat"))
+	numCPU = func() int { return cores }
+
+	cfg := defaultConfig()
+	cfg.Safety.Log = filepath.Join(dir, "safety.log")
+	cfg.Helper.Socket = "" // Root-only sources stay unreadable whatever the machine runs
+	cfg.Container = "off"  // Host-wide figures, even when the tests run in a container
+	cfg.WSLTemperature = false
+	cfg.GPU.NvidiaSMI = ""
+	if setup != nil {
+		setup(cfg)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	m := NewMonitor(cfg)
+	m.stressAvailable = false // Independent of the machine running the test
+	if open != nil {
+		open(m)
+	}
+
+	var usage, temp float64
+	frames, _ := filepath.Glob(filepath.Join(src, "frames", "*"))
+	for n := 1; n <= len(frames); n++ {
+		copyTree(t, filepath.Join(src, "frames", strconv.Itoa(n)), dir, "")
+		clock = clock.Add(500 * time.Millisecond)
+		usage, temp = m.pollTick()
+	}
+	// Settle the core bar animation on the polled values
+	copy(m.currentCoreUsages, m.calculateRollingAverage())
+	return m, usage, temp
+}
+
+// fixtureCores counts the per-core lines of a fixture's /proc/stat.
+func fixtureCores(t *testing.T, path string) int {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cores := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "cpu") && !strings.HasPrefix(line, "cpu ") {
+			cores++
+		}
+	}
+	return cores
+}
+
+// copyTree copies the files below src into dst, skipping the top-level
+// entry named skip.
+func copyTree(t *testing.T, src, dst, skip string) {
+	t.Helper()
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if skip != "" && rel == skip {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, data, 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// renderFrame draws one frame of m into a fresh screen buffer.
+func renderFrame(m *Monitor, usage, temp float64) string {
+	screen := newScreenBuffer(goldenWidth, goldenHeight)
+	m.out = screen
+	fmt.Fprint(m.out, clearScreen)
+	m.drawFrame(usage, temp)
+	return screen.String()
+}
+
+// checkGolden compares got with testdata/golden/name.txt, or rewrites the
+// file when the test runs with -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".txt")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("frame differs from %s (run go test -update to accept)\n--- got ---\n%s--- want ---\n%s", path, got, want)
+	}
+}
+
+// TestRenderGolden renders the main views of the fixture machines and
+// compares them with the golden frames.
+func TestRenderGolden(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fixtures are Linux procfs and sysfs trees")
+	}
+	tests := []struct {
+		name    string
+		fixture string
+		setup   func(cfg *Config)
+		open    func(m *Monitor) // Before the polls
+		page    func(m *Monitor) // After the polls
+	}{
+		{name: "4cores-grid", fixture: "4cores"},
+		{name: "16cores-grid", fixture: "16cores"},
+		{name: "64cores-grid", fixture: "64cores"},
+		{name: "128cores-grid", fixture: "128cores", setup: func(cfg *Config) { cfg.CoreViewName = "grid" }},
+		{name: "16cores-heatmap", fixture: "16cores", setup: func(cfg *Config) { cfg.CoreViewName = "heatmap" }},
+		{name: "64cores-heatmap", fixture: "64cores", setup: func(cfg *Config) { cfg.CoreViewName = "heatmap" }},
+		{name: "128cores-heatmap", fixture: "128cores"},
+		{name: "4cores-vertical", fixture: "4cores", setup: func(cfg *Config) { cfg.CoreViewName = "vertical" }},
+		{name: "16cores-vertical", fixture: "16cores", setup: func(cfg *Config) { cfg.CoreViewName = "vertical" }},
+		{name: "4cores-freq-bars", fixture: "4cores", page: func(m *Monitor) { m.freqBars = true }},
+		{name: "4cores-top-procs", fixture: "4cores", open: func(m *Monitor) { m.topProcs = newTopSampler() }},
+		{name: "4cores-container", fixture: "4cores", setup: func(cfg *Config) { cfg.Container = "on" }},
+		{name: "4cores-clock-graph", fixture: "4cores", setup: func(cfg *Config) { cfg.GraphModeName = "clock" }, page: throttleHistory},
+		{name: "4cores-vertical-freq", fixture: "4cores", setup: func(cfg *Config) { cfg.CoreViewName = "vertical" }, page: func(m *Monitor) {
+			m.freqBars = true
+		}},
+		{name: "16cores-dual-axis", fixture: "16cores", setup: func(cfg *Config) { cfg.GraphModeName = "dual" }},
+		{name: "4cores-multi-temp", fixture: "4cores", setup: func(cfg *Config) {
+			cfg.GraphModeName = "temps"
+			cfg.SecondarySensors = []string{"coretemp/Core 0", "coretemp/Core 3"}
+		}},
+		{name: "16cores-help", fixture: "16cores", page: func(m *Monitor) { m.showHelp = true }},
+		{name: "16cores-help-keys", fixture: "16cores", setup: func(cfg *Config) {
+			cfg.Keys = map[string]string{"zoom_in": "+", "zoom_out": "-", "stress": "z", "help": "?", "split_focus": "space"}
+		}, page: func(m *Monitor) { m.showHelp = true }},
+		{name: "16cores-core-history", fixture: "16cores", page: func(m *Monitor) {
+			m.showCoreHistory = true
+			migrateThread(m, 64)
+		}},
+		{name: "16cores-core-history-temp", fixture: "16cores", page: func(m *Monitor) {
+			m.showCoreHistory, m.coreHistoryTemp = true, true
+			migrateThread(m, 64)
+		}},
+		{name: "16cores-scatter", fixture: "16cores", page: func(m *Monitor) {
+			m.showScatter = true
+			throttleRun(m)
+		}},
+		{name: "16cores-scatter-power", fixture: "16cores", page: func(m *Monitor) {
+			m.showScatter, m.scatterPower = true, true
+			throttleRun(m)
+		}},
+		{name: "16cores-ambient", fixture: "16cores", setup: func(cfg *Config) {
+			cfg.Ambient.Command = "echo 'sensor=usb0 temp=74.3F'"
+			cfg.Ambient.Pattern = `temp=([0-9.]+)`
+			cfg.Ambient.Unit = "F"
+		}, page: waitAmbient},
+		{name: "4cores-wakeups", fixture: "4cores", open: func(m *Monitor) { m.openWakeupsPage() }},
+		{name: "16cores-wakeups", fixture: "16cores", open: func(m *Monitor) { m.openWakeupsPage() }},
+		{name: "16cores-attribution", fixture: "16cores", open: func(m *Monitor) { m.openAttributionPage() }},
+		{name: "128cores-core-history", fixture: "128cores", page: func(m *Monitor) { m.showCoreHistory = true }},
+		{name: "8cores-loop", fixture: "8cores"},
+		{name: "8cores-psu", fixture: "8cores", setup: func(cfg *Config) { cfg.GraphModeName = "psu" }},
+		{name: "8cores-safety-stop", fixture: "8cores", setup: func(cfg *Config) { cfg.Safety.MaxTemp = 65 }, open: fakeStress},
+		{name: "16cores-health", fixture: "16cores", setup: func(cfg *Config) { cfg.Health.MaxZombies = 1 }},
+		{name: "4cores-split", fixture: "4cores", setup: func(cfg *Config) {
+			cfg.Split.Enabled, cfg.Split.Right, cfg.Split.RightScale = true, "memory", time.Minute
+		}},
+		{name: "8cores-log-scale", fixture: "8cores", setup: func(cfg *Config) { cfg.GraphScale.CPU = "log" }},
+		{name: "8cores-envelope", fixture: "8cores", page: func(m *Monitor) {
+			// Half an hour at 15% with a one-poll spike every 100 seconds
+			for i := 0; i < 3600; i++ {
+				cpu := 15.0
+				if i%200 == 0 {
+					cpu = 95
+				}
+				m.history.add(historyPoint{cpu: cpu, temp: 55})
+			}
+			m.window = 30 * time.Minute
+			m.refreshGraph()
+		}},
+		{name: "8cores-bookmarks", fixture: "8cores", page: func(m *Monitor) {
+			// An idle minute and a loaded one, and a third range under way
+			for i, cpu := range []float64{12, 88, 40} {
+				m.bookmarks.toggle()
+				for poll := 0; poll < 120; poll++ {
+					m.bookmarks.add(cpu+float64(poll%10), 45+cpu/4, 20+cpu)
+				}
+				if i < 2 {
+					m.bookmarks.toggle()
+				}
+			}
+			m.showBookmarks = true
+		}},
+		{name: "8cores-startup-checks", fixture: "8cores", page: func(m *Monitor) {
+			// No RAPL counters in this fixture
+			m.startChecks()
+		}},
+		{name: "4cores-clock", fixture: "4cores", setup: func(cfg *Config) {
+			clockQuery = func() clockStatus {
+				return clockStatus{source: "chrony", synced: true, offset: 182e-6, hasOffset: true}
+			}
+		}, open: func(m *Monitor) {
+			m.clock.steps, m.clock.lastStep = 1, 3200*time.Millisecond
+			m.clock.stepAt = time.Date(2025, 10, 1, 11, 58, 41, 0, time.UTC)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, usage, temp := fixtureMonitor(t, tt.fixture, tt.setup, tt.open)
+			if tt.page != nil {
+				tt.page(m)
+			}
+			checkGolden(t, tt.name, renderFrame(m, usage, temp))
+		})
+	}
+}
+
+// migrateThread adds core history columns with one busy thread that
+// moves to the next core every few columns.
+func migrateThread(m *Monitor, temp float64) {
+	cores := make([]float64, m.cores)
+	for col := 0; col < 40; col++ {
+		for i := range cores {
+			cores[i] = 2
+		}
+		cores[(col/6)%m.cores] = 100
+		for poll := 0; poll < m.coreHistory.step; poll++ {
+			m.coreHistory.add(cores, m.coreTemperatures(cores, temp))
+		}
+	}
+}
+
+// throttleRun fills the frequency scatter with a stress run that heats
+// up from 45°C to 95°C, holding its clock until 80°C and then throttling.
+func throttleRun(m *Monitor) {
+	m.scatter = m.scatter[:0]
+	for i := 0; i <= 200; i++ {
+		temp := 45 + float64(i)/4
+		mhz, watts := 4800.0, 65+float64(i)/4
+		if temp > 80 {
+			mhz -= (temp - 80) * 60
+			watts -= (temp - 80) * 3
+		}
+		m.scatter = append(m.scatter, scatterPoint{temp: temp, mhz: mhz + float64(i%3)*25, watts: watts})
+	}
+}
+
+// throttleHistory records a stress run that holds its boost clock, then
+// hits thermal throttling and ends below the base clock.
+func throttleHistory(m *Monitor) {
+	for i := 0; i < 60; i++ {
+		p := historyPoint{cpu: 100, temp: 70 + float64(i)/3, mhz: 3400}
+		switch {
+		case i >= 45:
+			p.mhz, p.limited = 1400, true
+		case i >= 30:
+			p.mhz, p.throttled = 3400-float64(i-30)*100, true
+		}
+		m.history.add(p)
+	}
+	m.refreshGraph()
+}
+
+// waitAmbient waits for the first reading of the ambient source.
+func waitAmbient(m *Monitor) {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, ok := m.ambient.reading(); ok {
+			return
+		}
+	}
+}
+
+// fakeStress runs a sleep in place of the stress command, so the safety
+// limiter has a workload to stop.
+func fakeStress(m *Monitor) {
+	m.stressAvailable = true
+	m.stressCmd = exec.Command("sleep", "60")
+	setProcessGroup(m.stressCmd) // As startStress does, so stopStress kills it at once
+	if m.stressCmd.Start() == nil {
+		m.stressRunning = true
+	}
+}
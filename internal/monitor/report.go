@@ -0,0 +1,368 @@
+package monitor
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeremycharlesgillespie/kode_kronical_perf_monitor/internal/version"
+)
+
+// reportSummary holds the statistics of one report period.
+type reportSummary struct {
+	from, to     time.Time
+	minutes      int       // Minutes with recorded data
+	cpuAvg       float64   // Mean of the per-minute averages
+	cpuMax       float64   // Highest total CPU usage
+	cpuMaxTime   time.Time // When cpuMax occurred
+	tempAvg      float64   // Mean temperature, 0 when never available
+	tempMax      float64
+	tempMaxTime  time.Time
+	hotMinutes   int         // Minutes whose peak reached the hot threshold
+	throttled    int         // Polls during which the CPU throttled
+	throttledMin int         // Minutes with any throttling
+	stressMin    int         // Minutes with the stress test running
+	fioMin       int         // Minutes with the fio disk stress running
+	fioIOPS      float64     // Mean fio IOPS over those minutes
+	fioIOPSMax   float64     // Best minute
+	fioLatency   float64     // Mean fio completion latency in µs
+	clockSteps   []time.Time // Minutes in which the wall clock stepped
+	clockStepMax float64     // Largest step in seconds, by size
+	busiestHours []hourAvg   // Hours of day with the highest mean CPU usage
+	phases       string      // Time in each workload phase and the loaded phases, from formatPhases
+}
+
+// hourAvg is the mean CPU usage for one hour of the day.
+type hourAvg struct {
+	hour int
+	avg  float64
+}
+
+// summarizeHistory computes the report statistics from history records.
+func summarizeHistory(records []historyRecord, from, to time.Time, hotTemp float64) reportSummary {
+	sum := reportSummary{from: from, to: to, minutes: len(records)}
+	var cpuSum, tempSum float64
+	tempN := 0
+	var hourSum [24]float64
+	var hourN [24]int
+
+	for _, r := range records {
+		cpuSum += r.CPUAvg
+		if r.CPUMax > sum.cpuMax {
+			sum.cpuMax, sum.cpuMaxTime = r.CPUMax, r.Time
+		}
+		if r.TempAvg > 0 {
+			tempSum += r.TempAvg
+			tempN++
+			if r.TempMax > sum.tempMax {
+				sum.tempMax, sum.tempMaxTime = r.TempMax, r.Time
+			}
+			if hotTemp > 0 && r.TempMax >= hotTemp {
+				sum.hotMinutes++
+			}
+		}
+		if r.Throttled > 0 {
+			sum.throttled += r.Throttled
+			sum.throttledMin++
+		}
+		if r.Stress {
+			sum.stressMin++
+		}
+		if r.FioIOPS > 0 {
+			sum.fioMin++
+			sum.fioIOPS += r.FioIOPS
+			sum.fioLatency += r.FioLatency
+			if r.FioIOPS > sum.fioIOPSMax {
+				sum.fioIOPSMax = r.FioIOPS
+			}
+		}
+		if r.ClockStep != 0 {
+			sum.clockSteps = append(sum.clockSteps, r.Time)
+			if math.Abs(r.ClockStep) > math.Abs(sum.clockStepMax) {
+				sum.clockStepMax = r.ClockStep
+			}
+		}
+		hourSum[r.Time.Hour()] += r.CPUAvg
+		hourN[r.Time.Hour()]++
+	}
+
+	if len(records) > 0 {
+		sum.cpuAvg = cpuSum / float64(len(records))
+	}
+	if tempN > 0 {
+		sum.tempAvg = tempSum / float64(tempN)
+	}
+	if sum.fioMin > 0 {
+		sum.fioIOPS /= float64(sum.fioMin)
+		sum.fioLatency /= float64(sum.fioMin)
+	}
+	for h := 0; h < 24; h++ {
+		if hourN[h] > 0 {
+			sum.busiestHours = append(sum.busiestHours, hourAvg{h, hourSum[h] / float64(hourN[h])})
+		}
+	}
+	sort.SliceStable(sum.busiestHours, func(i, j int) bool {
+		return sum.busiestHours[i].avg > sum.busiestHours[j].avg
+	})
+	if len(sum.busiestHours) > 3 {
+		sum.busiestHours = sum.busiestHours[:3]
+	}
+	sum.phases = formatPhases(records)
+	return sum
+}
+
+// formatReport renders a summary as a plain-text report.
+func formatReport(sum reportSummary, hotTemp float64) string {
+	var b strings.Builder
+	host, _ := os.Hostname()
+	const stamp = "2006-01-02 15:04"
+
+	fmt.Fprintf(&b, "Kode Kronical Perf Monitor report for %s\n", host)
+	fmt.Fprintf(&b, "Period: %s to %s\n", sum.from.Format(stamp), sum.to.Format(stamp))
+	period := sum.to.Sub(sum.from).Minutes()
+	fmt.Fprintf(&b, "Coverage: %d of %.0f minutes recorded\n\n", sum.minutes, period)
+
+	if sum.minutes == 0 {
+		b.WriteString("No history was recorded in this period.\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "CPU usage:    avg %s, max %s at %s\n",
+		formatPercent(sum.cpuAvg, 1), formatPercent(sum.cpuMax, 1), sum.cpuMaxTime.Format(stamp))
+	if sum.tempMax > 0 {
+		fmt.Fprintf(&b, "Temperature:  avg %s, max %s at %s\n",
+			formatTemp(sum.tempAvg, 1), formatTemp(sum.tempMax, 1), sum.tempMaxTime.Format(stamp))
+		if hotTemp > 0 {
+			fmt.Fprintf(&b, "Hot minutes:  %d at or above %s\n", sum.hotMinutes, formatTemp(hotTemp, 0))
+		}
+	} else {
+		b.WriteString("Temperature:  not available\n")
+	}
+	fmt.Fprintf(&b, "Throttling:   %d events in %d minutes\n", sum.throttled, sum.throttledMin)
+	fmt.Fprintf(&b, "Stress test:  %d minutes\n", sum.stressMin)
+	if sum.fioMin > 0 {
+		fmt.Fprintf(&b, "Disk stress:  %d minutes, avg %.0f IOPS (best minute %.0f), avg latency %.0f µs\n",
+			sum.fioMin, sum.fioIOPS, sum.fioIOPSMax, sum.fioLatency)
+	}
+	if len(sum.clockSteps) > 0 {
+		// Timestamps around a step do not line up
+		fmt.Fprintf(&b, "Clock steps:  %d, largest %s, first at %s\n",
+			len(sum.clockSteps), formatClockOffset(sum.clockStepMax), sum.clockSteps[0].Format(stamp))
+	}
+
+	b.WriteString(sum.phases)
+	b.WriteString("\nBusiest hours (mean CPU usage):\n")
+	for _, h := range sum.busiestHours {
+		fmt.Fprintf(&b, "  %02d:00-%02d:59  %s\n", h.hour, h.hour, formatPercent(h.avg, 1))
+	}
+	return b.String()
+}
+
+// reportPeriod returns the length of a "daily" or "weekly" report.
+func reportPeriod(schedule string) time.Duration {
+	if schedule == "weekly" {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// nextReportTime returns the first scheduled report time after now.
+func nextReportTime(cfg *Config, now time.Time) time.Time {
+	at, _ := time.Parse("15:04", cfg.Report.At)
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	if cfg.Report.Schedule == "weekly" {
+		for next.Weekday() != cfg.Report.Weekday {
+			next = next.AddDate(0, 0, 1)
+		}
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// reportScheduler is a sink that generates the scheduled report when the
+// report time passes. Reports cover the period ending at the report time
+// and are built from the history store in the background.
+type reportScheduler struct {
+	cfg  *Config
+	next time.Time
+	wg   sync.WaitGroup
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+// newReportScheduler schedules the first report.
+func newReportScheduler(cfg *Config) *reportScheduler {
+	return &reportScheduler{cfg: cfg, next: nextReportTime(cfg, time.Now())}
+}
+
+// write starts report generation once the scheduled time has passed and
+// returns the error from the previous report, if any.
+func (r *reportScheduler) write(s *Sample) error {
+	if s.Time.Before(r.next) {
+		r.mu.Lock()
+		err := r.lastErr
+		r.lastErr = nil
+		r.mu.Unlock()
+		return err
+	}
+
+	to := r.next
+	from := to.Add(-reportPeriod(r.cfg.Report.Schedule))
+	r.next = nextReportTime(r.cfg, s.Time)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		err := deliverReport(r.cfg, from, to)
+		r.mu.Lock()
+		r.lastErr = err
+		r.mu.Unlock()
+	}()
+	return nil
+}
+
+// close waits for a report in progress.
+func (r *reportScheduler) close() error {
+	r.wg.Wait()
+	return nil
+}
+
+// deliverReport builds the report for [from, to) and writes it to the
+// report directory and/or emails it, as configured.
+func deliverReport(cfg *Config, from, to time.Time) error {
+	records, err := readHistory(cfg.History.Dir, from, to)
+	if err != nil {
+		return fmt.Errorf("report: %v", err)
+	}
+	text := formatReport(summarizeHistory(records, from, to, cfg.Report.HotTemp), cfg.Report.HotTemp)
+
+	if cfg.Report.Dir != "" {
+		if err := os.MkdirAll(cfg.Report.Dir, 0755); err != nil {
+			return fmt.Errorf("report: %v", err)
+		}
+		name := fmt.Sprintf("kkperf-%s-%s.txt", cfg.Report.Schedule, to.Format("2006-01-02"))
+		if err := os.WriteFile(filepath.Join(cfg.Report.Dir, name), []byte(text), 0644); err != nil {
+			return fmt.Errorf("report: %v", err)
+		}
+	}
+	if len(cfg.Report.Email.To) > 0 {
+		if err := emailReport(cfg, to, text); err != nil {
+			return fmt.Errorf("report: %v", err)
+		}
+	}
+	return nil
+}
+
+// emailReport sends the report through the configured SMTP server.
+// Authentication is used when a username is set; net/smtp only sends
+// credentials over TLS or to localhost.
+func emailReport(cfg *Config, to time.Time, text string) error {
+	e := cfg.Report.Email
+	host, _ := os.Hostname()
+	subject := fmt.Sprintf("kkperf %s report for %s, %s", cfg.Report.Schedule, host, to.Format("2006-01-02"))
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if e.Username != "" {
+		smtpHost := e.Server
+		if i := strings.LastIndex(smtpHost, ":"); i >= 0 {
+			smtpHost = smtpHost[:i]
+		}
+		auth = smtp.PlainAuth("", e.Username, e.Password, smtpHost)
+	}
+	return smtp.SendMail(e.Server, auth, e.From, e.To, []byte(msg.String()))
+}
+
+// ReportMain is the entry point of kkperf-report, which takes the options
+// of the report subcommand directly.
+func ReportMain() {
+	if len(os.Args) > 1 && (os.Args[1] == "-v" || os.Args[1] == "--version") {
+		version.Print("Kode Kronical Perf Report")
+		return
+	}
+	os.Exit(runReport(os.Args[1:]))
+}
+
+// runReport implements the "report" subcommand, which prints a summary
+// of the history store for the period ending now (or at --end).
+func runReport(args []string) int {
+	path := configPath()
+	period := "daily"
+	end := ""
+
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&path, "c", path, "")
+	fs.StringVar(&path, "config", path, "")
+	fs.StringVar(&period, "period", period, "")
+	fs.StringVar(&end, "end", end, "")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Println(reportUsage)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if period != "daily" && period != "weekly" {
+		fmt.Fprintf(os.Stderr, "Error: --period must be \"daily\" or \"weekly\"\n")
+		return 1
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	activeLocale = resolveLocale(cfg)
+
+	to := time.Now()
+	if end != "" {
+		if to, err = time.ParseInLocation("2006-01-02", end, time.Local); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --end must be a date (YYYY-MM-DD)\n")
+			return 1
+		}
+	}
+	from := to.Add(-reportPeriod(period))
+
+	records, err := readHistory(cfg.History.Dir, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Print(formatReport(summarizeHistory(records, from, to, cfg.Report.HotTemp), cfg.Report.HotTemp))
+	return 0
+}
+
+// reportUsage documents the report subcommand.
+const reportUsage = `Usage: kkperf report [options]
+       kkperf-report [options]
+
+Print a summary of the persistent history store.
+
+Options:
+  --period P        "daily" (default) or "weekly"
+  --end DATE        End of the period (YYYY-MM-DD, midnight); default now
+  -c, --config PATH Use an alternate config file`
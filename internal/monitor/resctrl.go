@@ -0,0 +1,286 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// resctrlDir is where the resctrl filesystem is mounted.
+var resctrlDir = "/sys/fs/resctrl"
+
+// resctrlNamePattern is what the process names of [resctrl] processes may
+// hold, as they become part of a directory name under mon_groups.
+var resctrlNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// resctrlDomain is one L3 monitoring domain of a resctrl group. A domain
+// covers the cores sharing one last-level cache, e.g. one CCX or socket.
+type resctrlDomain struct {
+	id               string // Domain number from mon_L3_NN
+	dir              string
+	total, local     float64 // Previous mbm_total_bytes and mbm_local_bytes readings
+	totalBW, localBW float64 // Bandwidth since the previous reading in bytes/s, -1 when unavailable
+	occupancy        float64 // LLC occupancy in bytes, -1 when unavailable
+}
+
+// resctrlGroup is a control or monitoring group with its L3 domains.
+type resctrlGroup struct {
+	name    string
+	domains []resctrlDomain
+}
+
+// resctrlSampler reads memory bandwidth (MBM) counters from resctrl on
+// CPUs with Intel RDT or AMD platform QoS.
+type resctrlSampler struct {
+	mounted  bool
+	groups   []resctrlGroup
+	lastTime time.Time
+	created  []string // Monitoring groups made for configured processes, removed on close
+	notes    []string // Processes that could not be monitored, with the reason
+}
+
+// newResctrlSampler creates monitoring groups for the named processes,
+// discovers the default group, every control group and every monitoring
+// group, and takes the first reading.
+func newResctrlSampler(processes []string) *resctrlSampler {
+	r := &resctrlSampler{lastTime: time.Now()}
+	if _, err := ioutil.ReadDir(filepath.Join(resctrlDir, "mon_data")); err != nil {
+		return r
+	}
+	r.mounted = true
+	for _, name := range processes {
+		if err := r.monitorProcess(name); err != nil {
+			r.notes = append(r.notes, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	r.addGroup("default", resctrlDir)
+	entries, _ := ioutil.ReadDir(resctrlDir)
+	for _, e := range entries {
+		switch e.Name() {
+		case "info", "mon_data", "mon_groups":
+			continue
+		}
+		if e.IsDir() {
+			r.addGroup(e.Name(), filepath.Join(resctrlDir, e.Name()))
+		}
+	}
+	// Monitoring groups live under the default group and each control group
+	monGroups, _ := filepath.Glob(filepath.Join(resctrlDir, "mon_groups", "*"))
+	more, _ := filepath.Glob(filepath.Join(resctrlDir, "*", "mon_groups", "*"))
+	for _, dir := range append(monGroups, more...) {
+		name := filepath.Base(dir)
+		if parent := filepath.Base(filepath.Dir(filepath.Dir(dir))); parent != filepath.Base(resctrlDir) {
+			name = parent + "/" + name
+		}
+		r.addGroup(name, dir)
+	}
+	r.sample()
+	return r
+}
+
+// addGroup records a group and its L3 monitoring domains.
+func (r *resctrlSampler) addGroup(name, dir string) {
+	domains, _ := filepath.Glob(filepath.Join(dir, "mon_data", "mon_L3_*"))
+	sort.Slice(domains, func(i, j int) bool { return naturalLess(domains[i], domains[j]) })
+	g := resctrlGroup{name: name}
+	for _, d := range domains {
+		id := strings.TrimLeft(strings.TrimPrefix(filepath.Base(d), "mon_L3_"), "0")
+		if id == "" {
+			id = "0"
+		}
+		g.domains = append(g.domains, resctrlDomain{id: id, dir: d, total: -1, local: -1})
+	}
+	if len(g.domains) > 0 {
+		r.groups = append(r.groups, g)
+	}
+}
+
+// sample refreshes the bandwidth of every group and domain.
+func (r *resctrlSampler) sample() {
+	now := time.Now()
+	dt := now.Sub(r.lastTime).Seconds()
+	r.lastTime = now
+	for gi := range r.groups {
+		for di := range r.groups[gi].domains {
+			d := &r.groups[gi].domains[di]
+			d.totalBW = counterRate(filepath.Join(d.dir, "mbm_total_bytes"), &d.total, dt)
+			d.localBW = counterRate(filepath.Join(d.dir, "mbm_local_bytes"), &d.local, dt)
+			d.occupancy = -1
+			if v, err := strconv.ParseFloat(readSysfsString(filepath.Join(d.dir, "llc_occupancy")), 64); err == nil {
+				d.occupancy = v
+			}
+		}
+	}
+}
+
+// monitorProcess moves every thread of the processes with the given
+// command name into a new monitoring group "kkperf-<name>". Children
+// forked later inherit the group.
+func (r *resctrlSampler) monitorProcess(name string) error {
+	if !resctrlNamePattern.MatchString(name) {
+		return errors.New(tr("not a valid process name"))
+	}
+	pids := findProcesses(name)
+	if len(pids) == 0 {
+		return errors.New(tr("no running process"))
+	}
+	dir := filepath.Join(resctrlDir, "mon_groups", "kkperf-"+name)
+	if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+		return err
+	}
+	r.created = append(r.created, dir)
+	for _, pid := range pids {
+		tids, _ := ioutil.ReadDir(filepath.Join(procDir, pid, "task"))
+		for _, tid := range tids {
+			// The tasks file takes one thread id per write; threads that
+			// exited in the meantime are skipped
+			err := ioutil.WriteFile(filepath.Join(dir, "tasks"), []byte(tid.Name()), 0644)
+			if err != nil && !errors.Is(err, syscall.ESRCH) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// close removes the monitoring groups created for processes, which
+// returns their tasks to the parent group.
+func (r *resctrlSampler) close() {
+	for _, dir := range r.created {
+		os.Remove(dir)
+	}
+	r.created = nil
+}
+
+// findProcesses returns the PIDs whose command name is name.
+func findProcesses(name string) []string {
+	entries, _ := ioutil.ReadDir(procDir)
+	var pids []string
+	for _, e := range entries {
+		if _, err := strconv.Atoi(e.Name()); err != nil {
+			continue
+		}
+		if readSysfsString(filepath.Join(procDir, e.Name(), "comm")) == name {
+			pids = append(pids, e.Name())
+		}
+	}
+	return pids
+}
+
+// counterRate reads a byte counter and returns its rate since the
+// previous reading stored in last, or -1 when it cannot be computed.
+// resctrl reports "Unavailable" while a counter is being reassigned.
+func counterRate(path string, last *float64, dt float64) float64 {
+	v, err := strconv.ParseFloat(readSysfsString(path), 64)
+	if err != nil {
+		*last = -1
+		return -1
+	}
+	prev := *last
+	*last = v
+	if prev < 0 || v < prev || dt <= 0 {
+		return -1
+	}
+	return (v - prev) / dt
+}
+
+// addRate adds two rates where -1 marks an unavailable value, which makes
+// the sum unavailable too.
+func addRate(a, b float64) float64 {
+	if a < 0 || b < 0 {
+		return -1
+	}
+	return a + b
+}
+
+// formatMiB formats an occupancy in MiB, or "--" when unavailable.
+func formatMiB(bytes float64) string {
+	if bytes < 0 {
+		return "--"
+	}
+	return fmt.Sprintf("%.1f MiB", bytes/(1<<20))
+}
+
+// formatGBps formats a bytes/s rate in GB/s, or "--" when unavailable.
+func formatGBps(bytes float64) string {
+	if bytes < 0 {
+		return "--"
+	}
+	return fmt.Sprintf("%.2f GB/s", bytes/1e9)
+}
+
+// openBandwidthPage rediscovers the resctrl groups and shows the page.
+func (m *Monitor) openBandwidthPage() {
+	m.resctrl = newResctrlSampler(m.cfg.Resctrl.Processes)
+	m.showBandwidth = true
+	fmt.Fprint(m.out, clearScreen)
+}
+
+// handleBandwidthKey processes a key press while the memory bandwidth
+// page is shown. It returns false when the application should quit.
+func (m *Monitor) handleBandwidthKey(key byte) bool {
+	switch m.pageAction(key, actionBandwidth) {
+	case actionStress:
+		if m.stressRunning {
+			m.stopStress()
+		} else {
+			m.startStress()
+		}
+		return true
+	case actionClose:
+		m.showBandwidth = false
+		m.resctrl.close()
+		fmt.Fprint(m.out, clearScreen)
+		return true
+	case actionQuit:
+		return false
+	}
+	return true
+}
+
+// displayBandwidthPage draws memory bandwidth and LLC occupancy per
+// resctrl group and L3 domain, with the group total on the first row of
+// each group.
+func (m *Monitor) displayBandwidthPage() {
+	fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor - %s ===%s\r\n\r\n", colorGreen, tr("Memory Bandwidth and Cache"), colorReset)
+
+	r := m.resctrl
+	if !r.mounted || len(r.groups) == 0 {
+		fmt.Fprintf(m.out, "  %s\r\n", tr("resctrl memory bandwidth monitoring is not available."))
+		fmt.Fprintf(m.out, "  %s\r\n\r\n", tr("It needs Intel RDT or AMD QoS and: mount -t resctrl resctrl /sys/fs/resctrl"))
+		fmt.Fprintf(m.out, "%s%s%s\r\n", colorYellow, tr("B/ESC: close"), colorReset)
+		return
+	}
+
+	fmt.Fprintf(m.out, "%s%-24s %-6s %12s %12s %12s%s\r\n", colorCyan, tr("Group"), tr("L3"), tr("Total"), tr("Local"), tr("LLC"), colorReset)
+	for _, g := range r.groups {
+		total, local, occupancy := 0.0, 0.0, 0.0
+		for _, d := range g.domains {
+			total = addRate(total, d.totalBW)
+			local = addRate(local, d.localBW)
+			occupancy = addRate(occupancy, d.occupancy)
+		}
+		fmt.Fprintf(m.out, "%s%-24s%s %-6s %12s %12s %12s\r\n", colorBlue, g.name, colorReset, tr("all"),
+			formatGBps(total), formatGBps(local), formatMiB(occupancy))
+		if len(g.domains) > 1 {
+			for _, d := range g.domains {
+				fmt.Fprintf(m.out, "%-24s %-6s %12s %12s %12s\r\n", "", d.id,
+					formatGBps(d.totalBW), formatGBps(d.localBW), formatMiB(d.occupancy))
+			}
+		}
+	}
+	for _, note := range r.notes {
+		fmt.Fprintf(m.out, "%s%s%s\r\n", colorDarkYellow, note, colorReset)
+	}
+
+	fmt.Fprintf(m.out, "\r\n%s%s%s\r\n", colorYellow, tr("SPACE: stress  B/ESC: close"), colorReset)
+}
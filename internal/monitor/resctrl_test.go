@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestResctrlProcess checks that a process name cannot reach outside
+// mon_groups, and that a failed move of its threads is reported.
+func TestResctrlProcess(t *testing.T) {
+	dir := t.TempDir()
+	savedProc, savedResctrl := procDir, resctrlDir
+	t.Cleanup(func() { procDir, resctrlDir = savedProc, savedResctrl })
+	procDir, resctrlDir = filepath.Join(dir, "proc"), filepath.Join(dir, "resctrl")
+	os.MkdirAll(filepath.Join(procDir, "100", "task", "101"), 0755)
+	ioutil.WriteFile(filepath.Join(procDir, "100", "comm"), []byte("../../evil\n"), 0644)
+	os.MkdirAll(filepath.Join(resctrlDir, "mon_groups"), 0755)
+
+	r := &resctrlSampler{}
+	if err := r.monitorProcess("../../evil"); err == nil || len(r.created) != 0 {
+		t.Errorf("path in the process name accepted: %v, created %v", err, r.created)
+	}
+	cfg := defaultConfig()
+	cfg.Resctrl.Processes = []string{"a/b"}
+	if err := cfg.validate(); err == nil {
+		t.Error("config validated a process name with a slash")
+	}
+
+	ioutil.WriteFile(filepath.Join(procDir, "100", "comm"), []byte("stress-ng\n"), 0644)
+	// A tasks directory instead of the resctrl file fails every write
+	os.MkdirAll(filepath.Join(resctrlDir, "mon_groups", "kkperf-stress-ng", "tasks"), 0755)
+	err := r.monitorProcess("stress-ng")
+	if err == nil || !strings.Contains(err.Error(), "tasks") {
+		t.Errorf("failed tasks write = %v; want the error", err)
+	}
+}
@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// resetSession starts the session over without restarting the monitor:
+// the minimum and maximum temperature, the graph and core histories, the
+// frequency scatter, the fan ranges, the average package power, the
+// latency maximum and the A/B bookmarks are cleared, and a pause is
+// lifted. Running load generators are left alone, so a test iteration can
+// be started from a clean slate while the machine is already loaded.
+func (m *Monitor) resetSession() {
+	m.minTemp, m.maxTemp = 999.0, 0.0
+	m.history = newGraphHistory(m.cfg.PollInterval)
+	m.coreHistory = newCoreHistory(m.cores, m.cfg.PollInterval)
+	m.scatter = m.scatter[:0]
+	m.fanRanges = map[string]fanRange{}
+	m.rapl.packageJoules, m.rapl.elapsed = 0, 0
+	m.latency.resetMax()
+	m.bookmarks = bookmarks{}
+	m.pause = pauseState{}
+	m.refreshGraph()
+	logInfo("session reset")
+
+	switch {
+	case m.headless:
+	case m.cfg.Accessible:
+		m.say(tr("Session reset."))
+	default:
+		fmt.Fprint(m.out, clearScreen)
+	}
+}
+
+// requestReset asks the main loop to reset the session. The HTTP endpoint
+// and the UNIX socket call it from their own goroutines; a reset already
+// pending absorbs the request. Read-only mode refuses it, as it does the
+// reset key.
+func (m *Monitor) requestReset() error {
+	if m.cfg.ReadOnly {
+		return errors.New("read-only mode")
+	}
+	select {
+	case m.resets <- struct{}{}:
+	default:
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestSessionReset checks that the reset key clears the min/max and the
+// history, and that /reset requests a reset from the main loop, except in
+// read-only mode.
+func TestSessionReset(t *testing.T) {
+	m, _, _ := fixtureMonitor(t, "4cores", nil, nil)
+	m.out = newScreenBuffer(goldenWidth, goldenHeight)
+	m.bookmarks.toggle()
+	if m.maxTemp == 0 || m.history.polls == 0 {
+		t.Fatalf("fixture polled nothing: max %.1f, %d polls", m.maxTemp, m.history.polls)
+	}
+	m.handleMainKey('r')
+	if m.minTemp != 999 || m.maxTemp != 0 || m.history.polls != 0 || m.bookmarks.open != nil {
+		t.Errorf("after reset: min %.1f, max %.1f, %d polls, bookmark open %v", m.minTemp, m.maxTemp, m.history.polls, m.bookmarks.open != nil)
+	}
+
+	h := &httpSink{reset: m.requestReset}
+	rec := httptest.NewRecorder()
+	h.serveReset(rec, httptest.NewRequest("GET", "/reset", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /reset = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	rec = httptest.NewRecorder()
+	h.serveReset(rec, httptest.NewRequest("POST", "/reset", nil))
+	if rec.Code != http.StatusAccepted || len(m.resets) != 1 {
+		t.Errorf("POST /reset = %d with %d resets pending, want %d with 1", rec.Code, len(m.resets), http.StatusAccepted)
+	}
+
+	<-m.resets
+	m.cfg.ReadOnly = true
+	rec = httptest.NewRecorder()
+	h.serveReset(rec, httptest.NewRequest("POST", "/reset", nil))
+	if rec.Code != http.StatusForbidden || len(m.resets) != 0 {
+		t.Errorf("read-only POST /reset = %d with %d resets pending, want %d with none", rec.Code, len(m.resets), http.StatusForbidden)
+	}
+}
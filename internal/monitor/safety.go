@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// throttleGap is how long throttle events may pause before throttling
+// counts as over. The counters only advance when a core enters the
+// throttled state, so sustained throttling shows as a series of events.
+const throttleGap = 2 * time.Second
+
+// defaultSafetyLog returns $XDG_DATA_HOME/kkperf/safety.log, falling
+// back to ~/.local/share/kkperf/safety.log.
+func defaultSafetyLog() string {
+	dir := defaultHistoryDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(dir), "safety.log")
+}
+
+// checkStressSafety stops the stress test when the temperature reaches
+// [safety] max_temp or throttling has gone on for throttle_for, so an
+// unattended run cannot cook a badly cooled machine. The reason stays in
+// the status line until stress is started again, and is appended to the
+// safety log; for [safety] cooldown, a banner shows it and stress cannot
+// be restarted.
+func (m *Monitor) checkStressSafety(s *Sample) {
+	now := timeNow()
+	if !m.stressRunning {
+		m.throttleStart = time.Time{}
+		if !m.safetyStopAt.IsZero() && m.safetyCooldown() == 0 {
+			m.safetyStopAt = time.Time{}
+			if !m.cfg.Accessible {
+				fmt.Fprint(m.out, clearScreen) // The banner goes
+			}
+		}
+		return
+	}
+	if s.Throttled {
+		if m.throttleStart.IsZero() || now.Sub(m.lastThrottle) > throttleGap {
+			m.throttleStart = now
+		}
+		m.lastThrottle = now
+	} else if !m.throttleStart.IsZero() && now.Sub(m.lastThrottle) > throttleGap {
+		m.throttleStart = time.Time{}
+	}
+
+	c := m.cfg.Safety
+	var reason, shown string
+	switch {
+	case c.MaxTemp > 0 && s.Temp >= c.MaxTemp:
+		reason = fmt.Sprintf("temperature %.1f°C >= %g°C", s.Temp, c.MaxTemp)
+		shown = fmt.Sprintf("%s ≥ %s", formatTemp(s.Temp, 1), formatTemp(c.MaxTemp, 0))
+	case c.ThrottleFor > 0 && !m.throttleStart.IsZero() && now.Sub(m.throttleStart) >= c.ThrottleFor:
+		reason = fmt.Sprintf("throttling for %s", now.Sub(m.throttleStart).Round(time.Second))
+		shown = fmt.Sprintf("throttled %s", now.Sub(m.throttleStart).Round(time.Second))
+	default:
+		return
+	}
+
+	m.stopStress()
+	m.throttleStart = time.Time{}
+	m.safetyStop = shown
+	if c.Cooldown > 0 {
+		m.safetyStopAt = now
+	}
+	appendSafetyLog(m.cfg, now, "stress stopped: "+reason)
+	if m.cfg.Accessible {
+		m.say(tr("Stress stopped by the safety limit: %s"), shown)
+	} else {
+		fmt.Fprint(m.out, "\a"+clearScreen)
+	}
+}
+
+// safetyCooldown returns how long stress stays locked after the safety
+// limiter stopped it, or 0 when it may be started.
+func (m *Monitor) safetyCooldown() time.Duration {
+	if m.safetyStopAt.IsZero() {
+		return 0
+	}
+	left := m.safetyStopAt.Add(m.cfg.Safety.Cooldown).Sub(timeNow())
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// displaySafetyStop draws a banner under the status line while stress is
+// locked after a safety stop, with the reason and the time left.
+func (m *Monitor) displaySafetyStop() {
+	left := m.safetyCooldown()
+	if left == 0 {
+		return
+	}
+	fmt.Fprintf(m.out, "%s\033[7m ⚠ %s %s — %s %s %s\r\n\r\n", colorRed, tr("STRESS STOPPED:"), m.safetyStop,
+		tr("restart allowed in"), left.Round(time.Second), colorReset)
+}
+
+// appendSafetyLog appends a timestamped line to the safety log. Errors
+// are ignored: the protective action matters, not its record.
+func appendSafetyLog(cfg *Config, now time.Time, event string) {
+	path := cfg.Safety.Log
+	if path == "" {
+		path = defaultSafetyLog()
+	}
+	if path == "" {
+		return
+	}
+	os.MkdirAll(filepath.Dir(path), 0755)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s %s\n", now.Format(time.RFC3339), event)
+}
+
+// safetyStatus returns the status line note for a stress test stopped by
+// the safety limiter, or "" when there is none.
+func (m *Monitor) safetyStatus() string {
+	if m.safetyStop == "" || m.stressRunning {
+		return ""
+	}
+	return fmt.Sprintf("  %s[SAFETY STOP: %s]%s", colorRed, m.safetyStop, colorReset)
+}
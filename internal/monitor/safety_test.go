@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStressCooldown checks that stress cannot be restarted for [safety]
+// cooldown after the safety limiter stopped it, and that the banner shows
+// the time left.
+func TestStressCooldown(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Safety.Log = filepath.Join(t.TempDir(), "safety.log")
+	cfg.Safety.Cooldown = time.Minute
+	cfg.Stress.Workers = 1
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	savedTimeNow := timeNow
+	timeNow = func() time.Time { return clock }
+	t.Cleanup(func() { timeNow = savedTimeNow })
+
+	var out bytes.Buffer
+	m := &Monitor{cfg: cfg, out: &out, stressAvailable: true, stressRunning: true}
+	m.checkStressSafety(&Sample{Temp: 96})
+	if m.stressRunning {
+		t.Fatal("stress still running at 96°C")
+	}
+
+	clock = clock.Add(20 * time.Second)
+	m.startStress()
+	if m.stressRunning {
+		t.Fatal("stress restarted during the cooldown")
+	}
+	out.Reset()
+	m.displaySafetyStop()
+	if want := "STRESS STOPPED: 96.0°C ≥ 95°C — restart allowed in 40s"; !strings.Contains(out.String(), want) {
+		t.Errorf("banner %q, want %q", out.String(), want)
+	}
+
+	clock = clock.Add(40 * time.Second)
+	m.checkStressSafety(&Sample{Temp: 60})
+	out.Reset()
+	m.displaySafetyStop()
+	if out.Len() != 0 {
+		t.Errorf("banner %q after the cooldown", out.String())
+	}
+	m.startStress()
+	if !m.stressRunning {
+		t.Fatal("stress not restarted after the cooldown")
+	}
+	m.stopStress()
+}
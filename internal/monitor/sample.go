@@ -0,0 +1,170 @@
+package monitor
+
+import "time"
+
+// timeNow is the clock of samplers that turn counters into rates. Tests
+// replace it to step through fixture frames at the poll interval.
+var timeNow = time.Now
+
+// Roots of procfs and sysfs for collectors not tied to one subsystem.
+// Like hwmonDir and cpuDir, tests point them at fixture trees.
+var (
+	procDir = "/proc"
+	sysDir  = "/sys"
+)
+
+// Sample is a snapshot of the collected metrics at one point in time.
+// It is the common currency of the one-shot output and exporters, so
+// its fields are exported for use in templates and encoders.
+type Sample struct {
+	Time      time.Time    // When the sample was taken
+	CPU       float64      // Total CPU usage (0-100%)
+	Cores     []float64    // Per-core usage (0-100%)
+	CoreTemps []float64    // Per-core temperature in °C from coretemp or k10temp CCD sensors, 0 for cores without one, nil without sensors
+	Temp      float64      // Package temperature in °C, 0 when unavailable
+	GPU       float64      // Busiest GPU utilization (0-100%), -1 when unavailable
+	GPUs      []GPUReading // Utilization, VRAM and temperature of every AMD and NVIDIA card, nil without any
+	Disk      float64      // Busiest disk utilization (0-100%), -1 when unavailable
+	Net       float64      // Network throughput relative to link capacity (0-100%), -1 when unavailable
+	Stress    bool         // Whether the stress test is running
+	IOWait    float64      // Share of CPU time spent idle waiting for I/O (0-100%)
+	Blocked   int          // Tasks in uninterruptible sleep (D state)
+	Load      []float64    // 1, 5 and 15-minute load averages, nil when unavailable
+	Running   int          // Runnable tasks
+	Tasks     int          // Tasks of all processes, threads included
+
+	Container    string  // Container runtime when CPU and memory are scoped to the monitor's cgroup, empty on the host
+	CPULimit     float64 // CPUs the container may use; only valid with Container
+	CPUThrottled float64 // Share of CFS periods the quota throttled (0-100%); only valid with Container
+
+	Throttled bool    // Whether the CPU throttled since the previous sample (Intel only)
+	RawTemp   float64 // Temperature before calibration offsets, 0 when unavailable
+	TjMax     float64 // Throttle temperature of the CPU sensor in °C, 0 when not reported
+	Headroom  float64 // Degrees below the sensor's thermal limit; only valid when Limited
+	Limited   bool    // Whether a thermal limit is known for the sensor
+
+	Power []DomainPower // RAPL power per domain, nil when unavailable
+
+	Disks []DiskIO // Throughput of every whole disk, nil without /proc/diskstats
+
+	DiskIOPS    float64 // IOPS of the fio disk stress, 0 when it is not running
+	DiskLatency float64 // Mean fio completion latency in µs
+
+	Ambient    float64 // Room temperature in °C from the [ambient] source; only valid when HasAmbient
+	HasAmbient bool    // Whether an ambient reading is current
+
+	Fans []FanReading // Speed of every fan that has spun, nil without fan tachometers
+
+	Batteries []BatteryReading // Laptop batteries, nil without one
+
+	Cooling        []CoolingReading // Coolant temperatures, pump speeds and flow rates of liquid-cooling controllers
+	CoolingFailure string           // Pump, flow or coolant problem per [cooling], empty when the loop is fine
+
+	PSUInput  float64   // Power drawn by the power supplies in W, 0 when unavailable
+	PSUOutput float64   // Power delivered by the power supplies in W, 0 when unavailable
+	PSURails  []PSURail // Output rail voltages
+
+	MemUsed   uint64 // RAM in use in bytes, not counting reclaimable cache
+	MemTotal  uint64 // Installed RAM in bytes, 0 when unavailable
+	SwapUsed  uint64 // Swap in use in bytes
+	SwapTotal uint64 // Swap space in bytes, 0 without swap
+
+	Zombies       int    // Zombie processes
+	Threads       int    // Threads of all processes
+	ThreadMax     int    // Thread limit (the lower of threads-max and pid_max), 0 when unknown
+	FDs           uint64 // Open file handles system-wide
+	FDMax         uint64 // System-wide file handle limit (fs.file-max), 0 when unknown
+	HealthWarning string // Zombie, thread and file counts over the [health] limits, empty when fine
+
+	Entropy     int // Kernel entropy estimate in bits, -1 when unavailable
+	EntropyPool int // Size of the entropy pool in bits: 4096 before Linux 5.18, 256 since
+
+	ClockSource string  // Time daemon: "chrony", "ntpd" or "timesyncd"; empty when none answered
+	ClockSynced bool    // Whether the time daemon considers the clock synchronized
+	ClockOffset float64 // Seconds the clock is ahead of its time sources, 0 when not reported
+	ClockStep   float64 // Seconds the wall clock jumped since the previous sample, 0 when it did not
+	ClockSteps  int     // Clock steps seen since startup
+
+	Alerts []string // [alerts] rules raised, e.g. "temp > 90 for 10s"; nil when none
+}
+
+// takeSample measures CPU usage over interval and returns a complete
+// Sample. It is used by one-shot modes that run without the polling loop.
+func (m *Monitor) takeSample(interval time.Duration) Sample {
+	time.Sleep(interval)
+	return m.pollSample()
+}
+
+// pollSample reads all collectors. CPU usage and utilization rates cover
+// the time since the previous poll.
+func (m *Monitor) pollSample() Sample {
+	start := time.Now()
+	defer func() {
+		statSamples.Add(1)
+		statPollTime.Set(float64(time.Since(start)) / float64(time.Millisecond))
+	}()
+
+	total, cores := m.calculateCPUUsage()
+	activity := m.activity.sample()
+	temp := m.getTemperature()
+	m.blocked.sample()
+	headroom, limited := m.headroom(temp)
+	s := Sample{
+		Time:      time.Now(),
+		CPU:       total,
+		Cores:     cores,
+		CoreTemps: m.coreTemps.sample(),
+		Temp:      temp,
+		GPU:       activity.gpu,
+		Disk:      activity.disk,
+		Net:       activity.net,
+		Stress:    m.stressRunning,
+		IOWait:    m.iowaitUsage,
+		Blocked:   m.blocked.count,
+
+		Throttled: m.throttle.sample(),
+		RawTemp:   m.rawTemp,
+		TjMax:     m.tjMax(),
+		Headroom:  headroom,
+		Limited:   limited,
+
+		Power: m.rapl.sample(),
+		Disks: m.diskIO.sample(),
+	}
+	s.DiskIOPS, s.DiskLatency = m.diskStress.reading()
+	s.GPUs = m.gpus.sample()
+	if s.GPU < 0 {
+		// amdgpu cards are counted already; NVIDIA cards report through nvidia-smi
+		s.GPU = busiestGPU(s.GPUs)
+	}
+	s.Ambient, s.HasAmbient = m.ambient.reading()
+	s.Cooling = m.cooling.sample()
+	s.Fans = m.fanSensors.sample()
+	s.Batteries = m.batterySensors.sample()
+	s.CoolingFailure = m.cfg.coolingFailure(s.Cooling, false)
+	psu := m.psu.sample()
+	s.PSUInput, s.PSUOutput, s.PSURails = psu.input, psu.output, psu.rails
+	health := readHealth()
+	s.Zombies, s.Threads, s.ThreadMax, s.FDs, s.FDMax = health.zombies, health.threads, health.threadMax, health.fds, health.fdMax
+	s.HealthWarning = m.cfg.healthWarning(health, untranslated)
+	s.Entropy, s.EntropyPool = m.entropy.sample(), m.entropy.poolSize
+	clock := m.clock.reading()
+	s.ClockSource, s.ClockSynced, s.ClockOffset = clock.source, clock.synced, clock.offset
+	s.ClockStep, s.ClockSteps = m.clock.step(s.Time).Seconds(), m.clock.steps
+	if load := readLoadAverage(); load.ok {
+		s.Load, s.Running, s.Tasks = load.avg[:], load.running, load.tasks
+	}
+	mem := readMemInfo()
+	s.MemUsed, s.MemTotal, s.SwapUsed, s.SwapTotal = mem.used, mem.total, mem.swapUsed, mem.swapTotal
+	if c := m.container; c != nil {
+		// Host-wide figures are misleading inside a container
+		c.sample()
+		s.Container, s.CPU, s.CPULimit, s.CPUThrottled = c.runtime, c.cpu, c.limit, c.throttledShare
+		s.MemUsed = c.memUsed
+		if c.memLimit > 0 && c.memLimit < s.MemTotal {
+			s.MemTotal = c.memLimit
+		}
+	}
+	s.Alerts = m.checkAlertRules(&s)
+	return s
+}
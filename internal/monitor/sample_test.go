@@ -0,0 +1,34 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestFirstSample checks that the first sample of a new monitor measures
+// from its start rather than averaging over the time since boot, which
+// the one-shot modes such as --format and check show on their own.
+func TestFirstSample(t *testing.T) {
+	m, _, _ := fixtureMonitor(t, "4cores", nil, nil)
+	m = NewMonitor(m.cfg) // Starts from the counters of the last frame
+	// Half of each core's ticks busy since then
+	stat := "cpu  11044 0 2301 201491 448 58 58 0 0 0\n" +
+		"cpu0 1379 0 608 50167 148 24 24 0 0 0\n" +
+		"cpu1 2230 0 566 50430 100 12 12 0 0 0\n" +
+		"cpu2 3175 0 551 50508 100 8 8 0 0 0\n" +
+		"cpu3 4260 0 576 50386 100 14 14 0 0 0\n"
+	if err := ioutil.WriteFile(filepath.Join(procDir, "stat"), []byte(stat), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.pollSample()
+	if s.CPU != 50 {
+		t.Errorf("first sample CPU = %.1f%%; want 50%% since the start", s.CPU)
+	}
+	for i, c := range s.Cores {
+		if c != 50 {
+			t.Errorf("first sample core %d = %.1f%%; want 50%%", i, c)
+		}
+	}
+}
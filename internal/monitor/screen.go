@@ -0,0 +1,204 @@
+package monitor
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// screenBuffer is an in-memory terminal. Frames written to it have their
+// escape sequences interpreted (cursor positioning, clearing, colors and
+// title changes are understood; colors are dropped), so a rendered frame
+// can be inspected as plain text. Text past the last column wraps and
+// text past the last row scrolls, as on a real terminal.
+type screenBuffer struct {
+	width, height int
+	cells         [][]rune
+	row, col      int
+	pending       []byte // Incomplete escape sequence or rune from the last Write
+}
+
+// newScreenBuffer creates a blank screen of the given size.
+func newScreenBuffer(width, height int) *screenBuffer {
+	s := &screenBuffer{width: width, height: height}
+	s.clear()
+	return s
+}
+
+// clear blanks the screen without moving the cursor.
+func (s *screenBuffer) clear() {
+	s.cells = make([][]rune, s.height)
+	for i := range s.cells {
+		s.cells[i] = s.blankRow()
+	}
+}
+
+// blankRow returns a row of spaces.
+func (s *screenBuffer) blankRow() []rune {
+	row := make([]rune, s.width)
+	for i := range row {
+		row[i] = ' '
+	}
+	return row
+}
+
+// Write interprets p as terminal output.
+func (s *screenBuffer) Write(p []byte) (int, error) {
+	data := append(s.pending, p...)
+	s.pending = nil
+	for len(data) > 0 {
+		n := s.consume(data)
+		if n == 0 {
+			// Keep the incomplete tail for the next Write
+			s.pending = append([]byte(nil), data...)
+			break
+		}
+		data = data[n:]
+	}
+	return len(p), nil
+}
+
+// consume handles one control character, escape sequence or rune at the
+// start of data and returns the bytes used, or 0 when data ends early.
+func (s *screenBuffer) consume(data []byte) int {
+	switch data[0] {
+	case '\r':
+		s.col = 0
+		return 1
+	case '\n':
+		s.lineFeed()
+		return 1
+	case '\a':
+		return 1 // Bell
+	case '\033':
+		return s.escape(data)
+	}
+	if !utf8.FullRune(data) {
+		return 0
+	}
+	r, n := utf8.DecodeRune(data)
+	s.put(r)
+	return n
+}
+
+// escape handles a CSI or OSC sequence and returns its length, or 0 when
+// it is incomplete.
+func (s *screenBuffer) escape(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+	switch data[1] {
+	case '[':
+		for i := 2; i < len(data); i++ {
+			if data[i] >= 0x40 && data[i] <= 0x7e {
+				s.csi(string(data[2:i]), data[i])
+				return i + 1
+			}
+		}
+		return 0
+	case ']':
+		// Operating system command, e.g. a window title; ends with BEL or ESC \
+		for i := 2; i < len(data); i++ {
+			if data[i] == '\a' {
+				return i + 1
+			}
+			if data[i] == '\033' && i+1 < len(data) && data[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return 0
+	}
+	return 2
+}
+
+// csi applies a control sequence with its parameters and final byte.
+func (s *screenBuffer) csi(params string, final byte) {
+	if strings.HasPrefix(params, "?") {
+		return // Private modes such as cursor visibility
+	}
+	args := strings.Split(params, ";")
+	arg := func(i, def int) int {
+		if i < len(args) {
+			if v, err := strconv.Atoi(args[i]); err == nil && v > 0 {
+				return v
+			}
+		}
+		return def
+	}
+	switch final {
+	case 'H', 'f':
+		s.row, s.col = s.clamp(arg(0, 1)-1, s.height), s.clamp(arg(1, 1)-1, s.width)
+	case 'A':
+		s.row = s.clamp(s.row-arg(0, 1), s.height)
+	case 'B':
+		s.row = s.clamp(s.row+arg(0, 1), s.height)
+	case 'C':
+		s.col = s.clamp(s.col+arg(0, 1), s.width)
+	case 'D':
+		s.col = s.clamp(s.col-arg(0, 1), s.width)
+	case 'J':
+		switch params {
+		case "2", "3":
+			s.clear()
+		default:
+			s.clearRow(s.row, s.col)
+			for r := s.row + 1; r < s.height; r++ {
+				s.cells[r] = s.blankRow()
+			}
+		}
+	case 'K':
+		s.clearRow(s.row, s.col)
+	}
+	// 'm' (colors) and 't' (title stack) do not change the text
+}
+
+// clamp limits v to [0, n).
+func (s *screenBuffer) clamp(v, n int) int {
+	if v < 0 {
+		return 0
+	}
+	if v >= n {
+		return n - 1
+	}
+	return v
+}
+
+// clearRow blanks a row from column col to its end.
+func (s *screenBuffer) clearRow(row, col int) {
+	for c := col; c < s.width; c++ {
+		s.cells[row][c] = ' '
+	}
+}
+
+// put writes a rune at the cursor, wrapping at the right edge.
+func (s *screenBuffer) put(r rune) {
+	if s.col >= s.width {
+		s.col = 0
+		s.lineFeed()
+	}
+	s.cells[s.row][s.col] = r
+	s.col++
+}
+
+// lineFeed moves the cursor down a row, scrolling at the bottom.
+func (s *screenBuffer) lineFeed() {
+	if s.row < s.height-1 {
+		s.row++
+		return
+	}
+	s.cells = append(s.cells[1:], s.blankRow())
+}
+
+// String returns the screen contents with trailing spaces and trailing
+// blank rows removed.
+func (s *screenBuffer) String() string {
+	rows := make([]string, len(s.cells))
+	last := -1
+	for i, row := range s.cells {
+		rows[i] = strings.TrimRight(string(row), " ")
+		if rows[i] != "" {
+			last = i
+		}
+	}
+	return strings.Join(rows[:last+1], "\n") + "\n"
+}
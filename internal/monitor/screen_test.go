@@ -0,0 +1,22 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestScreenBuffer checks the escape sequences the monitor relies on.
+func TestScreenBuffer(t *testing.T) {
+	s := newScreenBuffer(10, 3)
+	fmt.Fprint(s, clearScreen+"\033[0;31mred\033[0m\r\nline 2\033[K")
+	fmt.Fprint(s, moveCursor+"R")
+	fmt.Fprint(s, "\033[3;1H0123456789AB") // Wraps and scrolls
+	// Split inside an escape sequence and inside a multi-byte rune
+	fmt.Fprint(s, "\033[")
+	fmt.Fprint(s, "2;1H\xe2\x96")
+	fmt.Fprint(s, "\x88")
+	want := "line 2\n█123456789\nAB\n"
+	if got := s.String(); got != want {
+		t.Errorf("screen = %q, want %q", got, want)
+	}
+}
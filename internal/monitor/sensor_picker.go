@@ -0,0 +1,201 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// openSensorPicker rediscovers sensors and shows the picker screen.
+func (m *Monitor) openSensorPicker() {
+	m.rediscoverSensors()
+	m.readSensorPicker()
+	m.showSensors = true
+	m.sensorsChanged = false
+	if m.sensorCursor >= len(m.sensors) {
+		m.sensorCursor = 0
+	}
+	fmt.Fprint(m.out, clearScreen)
+}
+
+// closeSensorPicker returns to the main view, saving the selection to the
+// config file if it changed.
+func (m *Monitor) closeSensorPicker() {
+	m.showSensors = false
+	if m.sensorsChanged {
+		// Recorded secondary readings no longer match the selection
+		m.history.clearSensors()
+		for i := range m.displayBuffer {
+			m.displayBuffer[i].sensors = nil
+		}
+	}
+	if m.sensorsChanged && m.cfg.path != "" {
+		secondary := make([]string, len(m.cfg.SecondarySensors))
+		for i, id := range m.cfg.SecondarySensors {
+			secondary[i] = tomlQuote(id)
+		}
+		// The selection still applies to this session if it is not saved
+		m.sensorSaveErr = saveConfigValues(m.cfg.path, map[string]string{
+			"sensor":            tomlQuote(m.cfg.Sensor),
+			"secondary_sensors": "[" + strings.Join(secondary, ", ") + "]",
+		})
+		if m.sensorSaveErr != nil {
+			logWarn("sensor selection not saved", "path", m.cfg.path, "err", m.sensorSaveErr)
+		}
+	}
+	m.readSecondarySensors()
+	fmt.Fprint(m.out, clearScreen)
+}
+
+// sensorSaveStatus returns the status line tag of a sensor selection that
+// could not be saved, so it is not lost unnoticed at exit.
+func (m *Monitor) sensorSaveStatus() string {
+	if m.sensorSaveErr == nil {
+		return ""
+	}
+	return fmt.Sprintf("  %s[%s: %v]%s", colorDarkYellow, tr("SENSORS NOT SAVED"), m.sensorSaveErr, colorReset)
+}
+
+// handleSensorPickerKey processes a key press while the picker is shown.
+// It returns false when the application should quit.
+func (m *Monitor) handleSensorPickerKey(key byte) bool {
+	switch m.pageAction(key, actionSensors) {
+	case actionClose:
+		m.closeSensorPicker()
+		return true
+	case actionQuit:
+		return false
+	}
+	switch key { // SPACE selects here rather than toggling stress
+	case 'j', 'J', keyDown:
+		if m.sensorCursor < len(m.sensors)-1 {
+			m.sensorCursor++
+		}
+	case 'k', 'K', keyUp:
+		if m.sensorCursor > 0 {
+			m.sensorCursor--
+		}
+	case '\r', '\n':
+		// Make the highlighted sensor drive the main graph
+		if m.sensorCursor < len(m.sensors) {
+			m.cfg.Sensor = m.sensors[m.sensorCursor].id
+			m.sensorsChanged = true
+		}
+	case ' ':
+		// Toggle the highlighted sensor as a secondary series
+		if m.sensorCursor < len(m.sensors) {
+			id := m.sensors[m.sensorCursor].id
+			if i := indexOf(m.cfg.SecondarySensors, id); i >= 0 {
+				m.cfg.SecondarySensors = append(m.cfg.SecondarySensors[:i:i], m.cfg.SecondarySensors[i+1:]...)
+			} else {
+				m.cfg.SecondarySensors = append(m.cfg.SecondarySensors, id)
+			}
+			m.sensorsChanged = true
+			fmt.Fprint(m.out, clearScreen)
+		}
+	case 'a', 'A':
+		// Back to automatic selection of the main sensor
+		m.cfg.Sensor = ""
+		m.sensorsChanged = true
+	}
+	return true
+}
+
+// readSensorPicker refreshes the live readings shown in the picker.
+// Readings are calibrated; the raw value is kept alongside.
+func (m *Monitor) readSensorPicker() {
+	m.sensorReadings = make([]float64, len(m.sensors))
+	m.sensorRawReadings = make([]float64, len(m.sensors))
+	for i := range m.sensors {
+		if t, ok := m.readSensor(&m.sensors[i]); ok {
+			m.sensorRawReadings[i] = t
+			m.sensorReadings[i] = m.cfg.calibrate(m.sensors[i].id, t)
+		}
+	}
+}
+
+// readSecondarySensors refreshes the readings of the secondary sensors
+// shown below the status line.
+func (m *Monitor) readSecondarySensors() {
+	m.secondaryTemps = m.secondaryTemps[:0]
+	for _, id := range m.cfg.SecondarySensors {
+		temp := 0.0
+		if s := findSensor(m.sensors, id); s != nil {
+			if raw, ok := m.readSensor(s); ok {
+				temp = m.cfg.calibrate(id, raw)
+			}
+		}
+		m.secondaryTemps = append(m.secondaryTemps, temp)
+	}
+}
+
+// displaySensorPicker draws the sensor selection screen: every discovered
+// temperature source with its live reading, marking the main (P) and
+// secondary (S) sensors.
+func (m *Monitor) displaySensorPicker() {
+	fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor - %s ===%s\r\n\r\n", colorGreen, tr("Temperature Sensors"), colorReset)
+
+	if len(m.sensors) == 0 {
+		fmt.Fprintf(m.out, "  %s\r\n\r\n", tr("No temperature sensors found in /sys/class/hwmon or /sys/class/thermal"))
+	}
+
+	auto := ""
+	if m.cfg.Sensor == "" {
+		auto = " " + tr("(automatic)")
+	}
+	fmt.Fprintf(m.out, "%s%s%s%s\r\n", colorCyan, tr("Main sensor:"), colorReset, auto)
+
+	for i, s := range m.sensors {
+		cursor := "  "
+		if i == m.sensorCursor {
+			cursor = colorYellow + "> " + colorReset
+		}
+		primary := " "
+		if s.id == m.cfg.Sensor {
+			primary = colorGreen + "P" + colorReset
+		}
+		secondary := " "
+		if indexOf(m.cfg.SecondarySensors, s.id) >= 0 {
+			secondary = colorBlue + "S" + colorReset
+		}
+		reading, color, raw := "--", "", ""
+		if i < len(m.sensorReadings) && m.sensorReadings[i] != 0 {
+			reading = formatTemp(m.sensorReadings[i], 1)
+			color = getTempColor(m.sensorReadings[i])
+			if _, calibrated := m.cfg.Calibration[s.id]; calibrated {
+				raw = fmt.Sprintf("  (%s %s)", tr("raw"), formatTemp(m.sensorRawReadings[i], 1))
+			}
+		}
+		fmt.Fprintf(m.out, "%s[%s%s] %s %s%s%s%s\r\n", cursor, primary, secondary, padRight(s.id, 40), color, reading, colorReset, raw)
+	}
+
+	fmt.Fprintf(m.out, "\r\n%s%s%s\r\n", colorYellow,
+		tr("J/K: move  ENTER: main sensor  SPACE: secondary  A: automatic  T/ESC: close"), colorReset)
+}
+
+// displaySecondarySensors prints the secondary sensor readings on one line.
+func (m *Monitor) displaySecondarySensors() {
+	if len(m.cfg.SecondarySensors) == 0 {
+		return
+	}
+	parts := make([]string, 0, len(m.cfg.SecondarySensors))
+	for i, id := range m.cfg.SecondarySensors {
+		reading := "--"
+		color := ""
+		if i < len(m.secondaryTemps) && m.secondaryTemps[i] > 0 {
+			reading = formatTemp(m.secondaryTemps[i], 1)
+			color = getTempColor(m.secondaryTemps[i])
+		}
+		parts = append(parts, fmt.Sprintf("%s%s%s %s%s%s", colorBlue, id, colorReset, color, reading, colorReset))
+	}
+	fmt.Fprintf(m.out, "%s %s\r\n\r\n", tr("Sensors:"), strings.Join(parts, "  "))
+}
+
+// indexOf returns the position of s in list, or -1.
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
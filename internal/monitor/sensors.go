@@ -0,0 +1,350 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sysfs locations of temperature sources. They are variables so the
+// discovery code can be pointed at a copy of another machine's sysfs.
+var (
+	hwmonDir   = "/sys/class/hwmon"
+	thermalDir = "/sys/class/thermal"
+)
+
+// tempSensor is one temperature source discovered in sysfs.
+type tempSensor struct {
+	id    string  // Stable identifier used in the config, e.g. "k10temp/Tctl"
+	chip  string  // hwmon chip name, or "thermal" for thermal zones
+	label string  // Channel label, e.g. "Tctl", "Package id 0", "Composite"
+	path  string  // File holding the reading in millidegrees Celsius
+	crit  float64 // Critical temperature from temp*_crit in °C, 0 if not reported
+	max   float64 // High/warning temperature from temp*_max in °C, 0 if not reported
+}
+
+// discoverSensors lists every hwmon temperature channel and thermal zone.
+// Sensor ids are "<chip>/<label>"; a repeated chip name gets a numeric
+// suffix ("nvme", "nvme2") so two identical devices stay distinguishable.
+func discoverSensors() []tempSensor {
+	var sensors []tempSensor
+
+	chips, _ := filepath.Glob(filepath.Join(hwmonDir, "hwmon*"))
+	sort.Slice(chips, func(i, j int) bool { return naturalLess(chips[i], chips[j]) })
+	seen := map[string]int{}
+	for _, dir := range chips {
+		name := readSysfsString(filepath.Join(dir, "name"))
+		if name == "" {
+			name = filepath.Base(dir)
+		}
+		seen[name]++
+		chip := name
+		if seen[name] > 1 {
+			chip = name + strconv.Itoa(seen[name])
+		}
+
+		inputs, _ := filepath.Glob(filepath.Join(dir, "temp*_input"))
+		sort.Slice(inputs, func(i, j int) bool { return naturalLess(inputs[i], inputs[j]) })
+		for _, input := range inputs {
+			channel := strings.TrimSuffix(filepath.Base(input), "_input")
+			label := readSysfsString(filepath.Join(dir, channel+"_label"))
+			if label == "" {
+				label = channel
+			}
+			crit, _ := strconv.ParseFloat(readSysfsString(filepath.Join(dir, channel+"_crit")), 64)
+			max, _ := strconv.ParseFloat(readSysfsString(filepath.Join(dir, channel+"_max")), 64)
+			sensors = append(sensors, tempSensor{
+				id: chip + "/" + label, chip: chip, label: label, path: input,
+				crit: crit / 1000, max: max / 1000,
+			})
+			logDebug("sensor found", "id", chip+"/"+label, "path", input)
+		}
+	}
+
+	zones, _ := filepath.Glob(filepath.Join(thermalDir, "thermal_zone*"))
+	sort.Slice(zones, func(i, j int) bool { return naturalLess(zones[i], zones[j]) })
+	for _, dir := range zones {
+		label := readSysfsString(filepath.Join(dir, "type"))
+		if label == "" {
+			label = filepath.Base(dir)
+		}
+		crit, max := thermalZoneTrips(dir)
+		sensors = append(sensors, tempSensor{
+			id: "thermal/" + label, chip: "thermal", label: label, path: filepath.Join(dir, "temp"),
+			crit: crit, max: max,
+		})
+		logDebug("sensor found", "id", "thermal/"+label, "path", filepath.Join(dir, "temp"))
+	}
+
+	return sensors
+}
+
+// thermalZoneTrips returns a thermal zone's critical trip point and its
+// lowest hot or passive (throttling) trip point, in °C.
+func thermalZoneTrips(dir string) (crit, max float64) {
+	types, _ := filepath.Glob(filepath.Join(dir, "trip_point_*_type"))
+	for _, typeFile := range types {
+		milli, err := strconv.ParseFloat(readSysfsString(strings.TrimSuffix(typeFile, "_type")+"_temp"), 64)
+		if err != nil || milli <= 0 {
+			continue
+		}
+		temp := milli / 1000
+		switch readSysfsString(typeFile) {
+		case "critical":
+			crit = temp
+		case "hot", "passive":
+			if max == 0 || temp < max {
+				max = temp
+			}
+		}
+	}
+	return crit, max
+}
+
+// read returns the sensor's current temperature in °C.
+func (s *tempSensor) read() (float64, bool) {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		logDebug("sensor not readable", "sensor", s.id, "err", err)
+		return 0, false
+	}
+	milli, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil {
+		logWarn("sensor reading not a number", "sensor", s.id, "value", strings.TrimSpace(string(data)))
+		return 0, false
+	}
+	return milli / 1000.0, true
+}
+
+// rediscoverSensors refreshes m.sensors, dropping sensors excluded by the
+// [sensors] allow and deny lists. Paths of excluded sensors are remembered
+// so the automatic fallback does not read them either.
+func (m *Monitor) rediscoverSensors() {
+	m.sensors = m.sensors[:0]
+	m.deniedSensorPaths = map[string]bool{}
+	for _, s := range discoverSensors() {
+		if m.cfg.sensorAllowed(s.id) {
+			m.sensors = append(m.sensors, s)
+		} else {
+			logDebug("sensor excluded by [sensors] allow/deny", "sensor", s.id)
+			m.deniedSensorPaths[s.path] = true
+		}
+	}
+}
+
+// readSensor reads a sensor, rejecting values outside the plausible range
+// such as the -127°C or 255°C that disconnected or flaky sensors report.
+func (m *Monitor) readSensor(s *tempSensor) (float64, bool) {
+	temp, ok := s.read()
+	if ok && !m.cfg.validReading(temp) {
+		logDebug("sensor reading out of the valid range", "sensor", s.id, "value", temp)
+	}
+	if !ok || !m.cfg.validReading(temp) {
+		return 0, false
+	}
+	return temp, true
+}
+
+// sensorAllowed reports whether a sensor id passes the allow and deny
+// lists. Patterns use shell glob syntax, where "*" does not cross "/"
+// (so "nvme*/*" matches every NVMe channel). An empty allow list allows
+// everything; deny wins over allow.
+func (cfg *Config) sensorAllowed(id string) bool {
+	for _, pattern := range cfg.Sensors.Deny {
+		if ok, _ := path.Match(pattern, id); ok {
+			return false
+		}
+	}
+	if len(cfg.Sensors.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range cfg.Sensors.Allow {
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validReading reports whether a temperature in °C is plausible.
+func (cfg *Config) validReading(temp float64) bool {
+	return temp >= cfg.Sensors.MinValid && temp <= cfg.Sensors.MaxValid
+}
+
+// cpuSensorPreference lists the CPU temperature channels tried by automatic
+// sensor selection, best first. On AMD, Tdie is the real die temperature
+// while Tctl may carry a fan-control offset, so Tdie wins where the driver
+// reports both. On Intel, coretemp's package sensor covers all cores.
+var cpuSensorPreference = []struct{ chip, label string }{
+	{"k10temp", "Tdie"},
+	{"zenpower", "Tdie"},
+	{"k10temp", "Tctl"},
+	{"zenpower", "Tctl"},
+	{"coretemp", "Package id 0"},
+	{"coretemp", "Physical id 0"}, // Kernels before 3.x
+	{"cpu_thermal", "temp1"},      // Raspberry Pi and other ARM boards
+}
+
+// autoCPUSensor returns the preferred CPU temperature sensor among the
+// discovered ones, or nil when none of the known channels exists.
+func (m *Monitor) autoCPUSensor() *tempSensor {
+	for _, pref := range cpuSensorPreference {
+		for i := range m.sensors {
+			if m.sensors[i].chip == pref.chip && m.sensors[i].label == pref.label {
+				return &m.sensors[i]
+			}
+		}
+	}
+	return nil
+}
+
+// tjMax returns TjMax, the temperature at which the CPU starts throttling,
+// for the sensor the main temperature currently comes from. Intel's
+// coretemp driver reports it as the critical temperature, and so does
+// MSR_TEMPERATURE_TARGET when the temperature comes from the thermal MSRs;
+// other drivers do not expose it, in which case 0 is returned. A remote
+// monitor reports its own.
+func (m *Monitor) tjMax() float64 {
+	if m.remote != nil {
+		return m.remote.last.TjMax
+	}
+	if m.tempSensorID == msrSensorID {
+		return m.msrTjMax
+	}
+	if s := findSensor(m.sensors, m.tempSensorID); s != nil && s.chip == "coretemp" {
+		return s.crit
+	}
+	return 0
+}
+
+// limit returns the temperature the sensor should stay below, used for
+// the headroom display. coretemp's critical value is TjMax; k10temp's
+// temp1_max is a fixed placeholder, so only its critical value counts;
+// for other chips the warning (max) level comes first.
+func (s *tempSensor) limit() float64 {
+	switch s.chip {
+	case "coretemp", "k10temp":
+		return s.crit
+	}
+	if s.max > 0 {
+		return s.max
+	}
+	return s.crit
+}
+
+// thermalLimit returns the limit for the main temperature and whether it
+// is expressed in raw sensor terms (a hardware trip point) rather than in
+// calibrated terms (the thermal_limit setting).
+func (m *Monitor) thermalLimit() (limit float64, raw bool) {
+	if m.cfg.ThermalLimit > 0 {
+		return m.cfg.ThermalLimit, false
+	}
+	if s := findSensor(m.sensors, m.tempSensorID); s != nil {
+		return s.limit(), true
+	}
+	if m.tempSensorID == msrSensorID {
+		return m.msrTjMax, true
+	}
+	return 0, false
+}
+
+// headroom returns the distance from the current temperature to the
+// thermal limit. Hardware trip points apply to the sensor's own reading,
+// so they are compared against the uncalibrated value. A remote monitor
+// reports its own.
+func (m *Monitor) headroom(temp float64) (float64, bool) {
+	if m.remote != nil {
+		return m.remote.last.Headroom, m.remote.last.Limited
+	}
+	limit, raw := m.thermalLimit()
+	if limit <= 0 || temp <= 0 {
+		return 0, false
+	}
+	if raw {
+		return limit - m.rawTemp, true
+	}
+	return limit - temp, true
+}
+
+// headroomColor colors the headroom readout: comfortable above 20°C,
+// turning yellow, orange and red as the limit approaches.
+func headroomColor(headroom float64) string {
+	switch {
+	case headroom >= 20:
+		return colorGreen
+	case headroom >= 10:
+		return colorYellow
+	case headroom >= 5:
+		return colorOrange
+	}
+	return colorRed
+}
+
+// findSensor returns the sensor with the given id, or nil.
+func findSensor(sensors []tempSensor, id string) *tempSensor {
+	for i := range sensors {
+		if sensors[i].id == id {
+			return &sensors[i]
+		}
+	}
+	return nil
+}
+
+// configuredSensor returns the sensor chosen in the config or sensor
+// picker, by id or by the absolute path of a file holding millidegrees
+// Celsius. A path need not belong to a discovered sensor, so readings that
+// drivers publish elsewhere can be used too; its id is the path. Returns
+// nil when the sensor is missing or denied.
+func (m *Monitor) configuredSensor() *tempSensor {
+	if s := findSensor(m.sensors, m.cfg.Sensor); s != nil {
+		return s
+	}
+	if !filepath.IsAbs(m.cfg.Sensor) || m.deniedSensorPaths[m.cfg.Sensor] {
+		return nil
+	}
+	if id := sensorIDForPath(m.sensors, m.cfg.Sensor); id != "" {
+		return findSensor(m.sensors, id)
+	}
+	return &tempSensor{id: m.cfg.Sensor, chip: "file", label: filepath.Base(m.cfg.Sensor), path: m.cfg.Sensor}
+}
+
+// sensorIDForPath returns the id of the sensor read from path, or "".
+func sensorIDForPath(sensors []tempSensor, path string) string {
+	for _, s := range sensors {
+		if s.path == path {
+			return s.id
+		}
+	}
+	return ""
+}
+
+// readSysfsString reads a small sysfs attribute, trimmed.
+func readSysfsString(path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// naturalLess orders paths so that "hwmon10" sorts after "hwmon2".
+func naturalLess(a, b string) bool {
+	trail := func(s string) (string, int) {
+		i := len(s)
+		for i > 0 && s[i-1] >= '0' && s[i-1] <= '9' {
+			i--
+		}
+		n, _ := strconv.Atoi(s[i:])
+		return s[:i], n
+	}
+	a, b = strings.TrimSuffix(filepath.Base(a), "_input"), strings.TrimSuffix(filepath.Base(b), "_input")
+	pa, na := trail(a)
+	pb, nb := trail(b)
+	if pa != pb {
+		return pa < pb
+	}
+	return na < nb
+}
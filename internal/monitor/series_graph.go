@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// seriesGraph describes a graph of several series sharing one axis that
+// starts at zero, drawn with the multi-sensor graph's markers and colors.
+type seriesGraph struct {
+	names  []string                                 // Series labels for the legend, at most len(multiTempMarkers)
+	value  func(p historyPoint, series int) float64 // Value of a series at a point, negative when missing
+	scale  yAxis                                    // Fitted to the series by newYAxis
+	unit   unit                                     // Formats the axis labels, 8 columns wide
+	legend func(series int) string                  // Current reading shown in the legend
+}
+
+// drawSeriesGraph plots the series over the display buffer, the first
+// series drawn last so it stays on top where series overlap.
+func (m *Monitor) drawSeriesGraph(g seriesGraph) {
+	const rows = 8
+
+	grid := make([][]string, rows)
+	for r := range grid {
+		grid[r] = make([]string, baseGraphWidth)
+	}
+	colors := multiTempColors()
+	for s := len(g.names) - 1; s >= 0; s-- {
+		marker := colors[s] + multiTempMarkers[s] + colorReset
+		for i, p := range m.displayBuffer {
+			if v := g.value(p, s); v >= 0 {
+				grid[valueRow(g.scale.fraction(v), rows)][i] = marker
+			}
+		}
+	}
+
+	for row := rows - 1; row >= 0; row-- {
+		fmt.Fprintf(m.out, "%s%s%s ", colorCyan, padLeft(g.unit.axis(g.scale.top(row, rows)), 8), colorReset)
+		for _, cell := range grid[row] {
+			if cell == "" {
+				cell = " "
+			}
+			fmt.Fprint(m.out, cell)
+		}
+		fmt.Fprint(m.out, "\r\n")
+	}
+
+	legend := make([]string, len(g.names))
+	for s, name := range g.names {
+		legend[s] = fmt.Sprintf("%s%s%s %s %s", colors[s], multiTempMarkers[s], colorReset, name, g.legend(s))
+	}
+	fmt.Fprintf(m.out, "         %s\r\n", strings.Join(legend, "  "))
+	fmt.Fprintf(m.out, "         %s%s%s\r\n", colorYellow, m.zoomHint(), colorReset)
+	fmt.Fprintf(m.out, "         %s%-10s%s\r\n", colorCyan, formatWindow(m.window), colorReset)
+}
+
+// seriesValues collects the values of every series over the display
+// buffer, for fitting the axis.
+func (m *Monitor) seriesValues(series int, value func(p historyPoint, series int) float64) []float64 {
+	var values []float64
+	for _, p := range m.displayBuffer {
+		for s := 0; s < series; s++ {
+			values = append(values, value(p, s))
+		}
+	}
+	return values
+}
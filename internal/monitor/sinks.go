@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+)
+
+// sink is an exporter that receives every sample taken by the polling
+// loop. Sinks decide for themselves how often to act on samples.
+type sink interface {
+	write(s *Sample) error
+	close() error
+}
+
+// openSinks creates the exporters enabled in the config.
+func (m *Monitor) openSinks() error {
+	if m.cfg.HTTP.Listen != "" {
+		h, err := newHTTPSink(m.cfg.HTTP.Listen, m.cfg.HTTP.Token, m.requestReset)
+		if err != nil {
+			return err
+		}
+		m.sinks = append(m.sinks, h)
+	}
+	if m.cfg.Web.Listen != "" {
+		w, err := newWebSink(m.cfg.Web.Listen, m.cfg.Web.Token)
+		if err != nil {
+			return err
+		}
+		m.sinks = append(m.sinks, w)
+	}
+	if m.cfg.Socket.Path != "" {
+		s, err := newSocketSink(m.cfg.Socket.Path, m.requestReset)
+		if err != nil {
+			return err
+		}
+		m.sinks = append(m.sinks, s)
+	}
+	if m.cfg.FIFO.Path != "" {
+		f, err := newFIFOSink(m.cfg.FIFO.Path, m.cfg.FIFO.Template)
+		if err != nil {
+			return err
+		}
+		m.sinks = append(m.sinks, f)
+	}
+	if m.cfg.Prometheus.Textfile != "" {
+		m.sinks = append(m.sinks, newTextfileSink(m.cfg.Prometheus.Textfile, m.cfg.Prometheus.Interval))
+	}
+	if m.cfg.Zabbix.Server != "" {
+		m.sinks = append(m.sinks, newZabbixSink(m.cfg))
+	}
+	if m.cfg.MQTT.Broker != "" {
+		m.sinks = append(m.sinks, newMQTTSink(m.cfg, m.throttle.available))
+	}
+	if m.cfg.CSV.Path != "" {
+		c, err := newCSVSink(m.cfg.CSV.Path)
+		if err != nil {
+			return err
+		}
+		m.sinks = append(m.sinks, c)
+	}
+	if m.cfg.History.Enabled {
+		h, err := newHistoryStore(m.cfg.History.Dir, m.cfg.History.Retention)
+		if err != nil {
+			return err
+		}
+		m.sinks = append(m.sinks, h)
+	}
+	if m.cfg.Report.Schedule != "" {
+		m.sinks = append(m.sinks, newReportScheduler(m.cfg))
+	}
+	if m.cfg.Emergency.Action != "" {
+		m.emergency = newEmergencySink(m.cfg)
+		m.sinks = append(m.sinks, m.emergency)
+	}
+	return nil
+}
+
+// writeSinks passes a sample to every sink. Errors are only reported when
+// running headless; in the TUI they would corrupt the display.
+func (m *Monitor) writeSinks(s *Sample) {
+	for _, sk := range m.sinks {
+		if err := sk.write(s); err != nil {
+			statSinkErrors.Add(1)
+			logError("exporter write failed", "err", err)
+			if m.headless {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+			}
+		}
+	}
+}
+
+// closeSinks flushes and closes all sinks.
+func (m *Monitor) closeSinks() {
+	for _, sk := range m.sinks {
+		sk.close()
+	}
+	m.sinks = nil
+}
@@ -0,0 +1,218 @@
+package monitor
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultSNMPBaseOID is under NET-SNMP's netSnmpPlaypen subtree, which is
+// reserved for local experiments; sites with their own enterprise number
+// should set [snmp] base_oid instead.
+const defaultSNMPBaseOID = ".1.3.6.1.4.1.8072.9999.9999.1"
+
+// snmpVar is one variable exposed through pass_persist.
+type snmpVar struct {
+	oid   []int
+	typ   string
+	value string
+}
+
+// runSNMP implements the "snmp" subcommand, a net-snmp pass_persist
+// handler. snmpd starts it once and sends PING/get/getnext/set commands
+// on stdin; a background loop keeps a recent sample so requests are
+// answered immediately.
+//
+// Layout under the base OID (SNMP has no floats, so fractional values are
+// scaled integers):
+//
+//	.1.0      cpu usage, hundredths of a percent (Gauge32)
+//	.2.0      package temperature, tenths of °C (INTEGER), absent if unknown
+//	.3.0      stress test running, 1/0 (INTEGER)
+//	.4.0      number of cores (Gauge32)
+//	.5.1.N    usage of core N-1, hundredths of a percent (Gauge32)
+//	.6.0-.8.0 GPU, disk, network utilization, hundredths of a percent, absent if unknown
+func runSNMP(args []string) int {
+	path := configPath()
+	fs := flag.NewFlagSet("snmp", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&path, "c", path, "")
+	fs.StringVar(&path, "config", path, "")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Println(snmpUsage)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	base, err := parseOID(cfg.SNMP.BaseOID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: snmp.base_oid: %v\n", err)
+		return 1
+	}
+
+	m := NewMonitor(cfg)
+	var mu sync.Mutex
+	latest := m.takeSample(500 * time.Millisecond)
+	go func() {
+		for range time.Tick(cfg.SNMP.Interval) {
+			sample := m.pollSample()
+			mu.Lock()
+			latest = sample
+			mu.Unlock()
+		}
+	}()
+
+	in := bufio.NewScanner(os.Stdin)
+	out := bufio.NewWriter(os.Stdout)
+	readLine := func() (string, bool) {
+		if !in.Scan() {
+			return "", false
+		}
+		return strings.TrimSpace(in.Text()), true
+	}
+
+	for {
+		cmd, ok := readLine()
+		if !ok || cmd == "" {
+			return 0
+		}
+
+		switch strings.ToLower(cmd) {
+		case "ping":
+			out.WriteString("PONG\n")
+		case "get", "getnext":
+			line, ok := readLine()
+			if !ok {
+				return 0
+			}
+			mu.Lock()
+			vars := snmpVars(base, &latest)
+			mu.Unlock()
+			oid, err := parseOID(line)
+			if v := lookupSNMP(vars, oid, cmd == "getnext"); err == nil && v != nil {
+				fmt.Fprintf(out, "%s\n%s\n%s\n", formatOID(v.oid), v.typ, v.value)
+			} else {
+				out.WriteString("NONE\n")
+			}
+		case "set":
+			// OID and value lines follow; everything here is read-only
+			readLine()
+			readLine()
+			out.WriteString("not-writable\n")
+		default:
+			out.WriteString("NONE\n")
+		}
+		out.Flush()
+	}
+}
+
+// snmpVars builds the sorted variable list for a sample.
+func snmpVars(base []int, s *Sample) []snmpVar {
+	var vars []snmpVar
+	add := func(typ string, value int64, suffix ...int) {
+		oid := append(append([]int{}, base...), suffix...)
+		vars = append(vars, snmpVar{oid, typ, strconv.FormatInt(value, 10)})
+	}
+	hundredths := func(v float64) int64 {
+		return int64(v*100 + 0.5)
+	}
+
+	add("gauge", hundredths(s.CPU), 1, 0)
+	if s.Temp > 0 {
+		add("integer", int64(s.Temp*10+0.5), 2, 0)
+	}
+	stress := int64(0)
+	if s.Stress {
+		stress = 1
+	}
+	add("integer", stress, 3, 0)
+	add("gauge", int64(len(s.Cores)), 4, 0)
+	for i, usage := range s.Cores {
+		add("gauge", hundredths(usage), 5, 1, i+1)
+	}
+	for i, v := range []float64{s.GPU, s.Disk, s.Net} {
+		if v >= 0 {
+			add("gauge", hundredths(v), 6+i, 0)
+		}
+	}
+
+	sort.Slice(vars, func(i, j int) bool { return compareOID(vars[i].oid, vars[j].oid) < 0 })
+	return vars
+}
+
+// lookupSNMP returns the variable at oid, or with next set the first
+// variable after it in lexicographic OID order.
+func lookupSNMP(vars []snmpVar, oid []int, next bool) *snmpVar {
+	for i := range vars {
+		c := compareOID(vars[i].oid, oid)
+		if (!next && c == 0) || (next && c > 0) {
+			return &vars[i]
+		}
+	}
+	return nil
+}
+
+// compareOID orders OIDs component by component, shorter prefixes first.
+func compareOID(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+// parseOID parses a dotted numeric OID such as ".1.3.6.1".
+func parseOID(s string) ([]int, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), ".")
+	if s == "" {
+		return nil, fmt.Errorf("empty OID")
+	}
+	var oid []int
+	for _, part := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid OID component %q", part)
+		}
+		oid = append(oid, n)
+	}
+	return oid, nil
+}
+
+// formatOID renders an OID in the leading-dot form snmpd expects.
+func formatOID(oid []int) string {
+	var b strings.Builder
+	for _, n := range oid {
+		b.WriteByte('.')
+		b.WriteString(strconv.Itoa(n))
+	}
+	return b.String()
+}
+
+// snmpUsage documents the snmp subcommand.
+const snmpUsage = `Usage: kkperf snmp [options]
+
+net-snmp pass_persist handler. Add to snmpd.conf:
+
+  pass_persist .1.3.6.1.4.1.8072.9999.9999.1 /usr/local/bin/kkperf-agent snmp
+
+Options:
+  -c, --config PATH Use an alternate config file`
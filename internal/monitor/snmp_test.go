@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSNMPVars checks the variables served for a fixed sample, get and
+// getnext in OID order, and the OIDs snmpd passes in.
+func TestSNMPVars(t *testing.T) {
+	base := []int{1, 3, 6, 1, 4, 1, 8072, 9999, 9999, 1}
+	s := Sample{Time: time.Unix(1700000000, 0), CPU: 12.345, Cores: []float64{50, 99.99}, Temp: 71.26, GPU: -1, Disk: 30, Net: -1, Stress: true}
+	vars := snmpVars(base, &s)
+
+	const prefix = ".1.3.6.1.4.1.8072.9999.9999.1"
+	for _, tc := range []struct {
+		oid  string
+		next bool
+		want string // "oid typ value", or "" for NONE
+	}{
+		{prefix + ".1.0", false, prefix + ".1.0 gauge 1235"},
+		{prefix + ".2.0", false, prefix + ".2.0 integer 713"},
+		{prefix + ".3.0", false, prefix + ".3.0 integer 1"},
+		{prefix + ".4.0", false, prefix + ".4.0 gauge 2"},
+		{prefix + ".5.1.2", false, prefix + ".5.1.2 gauge 9999"},
+		{prefix + ".6.0", false, ""}, // GPU unavailable
+		{prefix + ".7.0", false, prefix + ".7.0 gauge 3000"},
+		{prefix + ".5", false, ""},
+		{prefix, true, prefix + ".1.0 gauge 1235"},
+		{prefix + ".4.0", true, prefix + ".5.1.1 gauge 5000"},
+		{prefix + ".5.1.2", true, prefix + ".7.0 gauge 3000"},
+		{prefix + ".7.0", true, ""},
+		{".1.3.6.1.4.1.8072.9999.9999.2", true, ""},
+		{".1.3", true, prefix + ".1.0 gauge 1235"},
+	} {
+		oid, err := parseOID(tc.oid)
+		if err != nil {
+			t.Fatalf("parseOID(%s): %v", tc.oid, err)
+		}
+		got := ""
+		if v := lookupSNMP(vars, oid, tc.next); v != nil {
+			got = formatOID(v.oid) + " " + v.typ + " " + v.value
+		}
+		if got != tc.want {
+			t.Errorf("lookup %s (next %v) = %q; want %q", tc.oid, tc.next, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"", ".", "1.3.x", ".1..3", "1.-3"} {
+		if oid, err := parseOID(bad); err == nil {
+			t.Errorf("parseOID(%q) = %v; want an error", bad, oid)
+		}
+	}
+	if oid, err := parseOID(" 1.3.6 \n"); err != nil || formatOID(oid) != ".1.3.6" {
+		t.Errorf("parseOID without the leading dot = %v, %v", oid, err)
+	}
+}
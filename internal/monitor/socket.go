@@ -0,0 +1,300 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// socketQueue is how many lines a subscriber may fall behind before it is
+// disconnected, so a stuck widget cannot hold up the polling loop.
+const socketQueue = 64
+
+// socketSink serves the latest sample on a UNIX domain socket with a line
+// protocol: "GET <metric>" answers with one line, "SUBSCRIBE <metric>"
+// streams the metric on every poll, "SUBSCRIBE alerts" streams alerts
+// as they are raised and cleared, and "RESET" resets the session. Values are plain numbers with temperatures
+// in °C, whatever the display settings, so scripts need no parsing.
+type socketSink struct {
+	ln    net.Listener
+	path  string
+	reset func() error // Requests a session reset from the main loop
+
+	mu          sync.Mutex
+	latest      *Sample
+	alerts      map[string]string // Active alerts by name
+	subscribers map[chan string]string
+}
+
+// newSocketSink creates the socket at path, readable by the current user
+// only. A socket left behind by a monitor that did not exit cleanly is
+// replaced; one another monitor still answers on is an error. reset is
+// called on a RESET request.
+func newSocketSink(path string, reset func() error) (*socketSink, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("socket: %s is in use by another monitor", path)
+		}
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("socket: %v", err)
+	}
+	os.Chmod(path, 0600)
+
+	s := &socketSink{ln: ln, path: path, reset: reset, alerts: map[string]string{}, subscribers: map[chan string]string{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s, nil
+}
+
+// serve answers the requests of one client until it disconnects.
+func (s *socketSink) serve(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		command, metric := strings.ToUpper(fields[0]), ""
+		if len(fields) > 1 {
+			metric = strings.ToLower(fields[1])
+		}
+		switch {
+		case command == "GET" && len(fields) == 2:
+			s.mu.Lock()
+			latest := s.latest
+			if latest == nil {
+				latest = &Sample{}
+			}
+			value, err := socketValue(latest, s.alerts, metric)
+			s.mu.Unlock()
+			if err != nil {
+				value = "ERR " + err.Error()
+			}
+			fmt.Fprintf(conn, "%s\n", value)
+		case command == "SUBSCRIBE" && len(fields) == 2:
+			if _, err := socketValue(&Sample{}, nil, metric); err != nil {
+				fmt.Fprintf(conn, "ERR %v\n", err)
+				continue
+			}
+			s.stream(conn, metric)
+			return
+		case command == "RESET" && len(fields) == 1:
+			if err := s.reset(); err != nil {
+				fmt.Fprintf(conn, "ERR %v\n", err)
+				continue
+			}
+			fmt.Fprintf(conn, "OK\n")
+		case command == "QUIT":
+			return
+		default:
+			fmt.Fprintf(conn, "ERR usage: GET <metric> | SUBSCRIBE <metric> | SUBSCRIBE alerts | RESET | QUIT\n")
+		}
+	}
+}
+
+// stream sends a subscription to conn until the client disconnects or
+// falls more than socketQueue lines behind. A subscription to alerts
+// starts with the alerts already active.
+func (s *socketSink) stream(conn net.Conn, metric string) {
+	lines := make(chan string, socketQueue)
+	s.mu.Lock()
+	if metric == "alerts" {
+		for _, name := range sortedKeys(s.alerts) {
+			lines <- fmt.Sprintf("ALERT %s %s", name, s.alerts[name])
+		}
+	}
+	s.subscribers[lines] = metric
+	s.mu.Unlock()
+
+	// A closed connection is noticed by the read, as a subscription
+	// expects no more requests
+	gone := make(chan struct{})
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				close(gone)
+				return
+			}
+		}
+	}()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, lines)
+		s.mu.Unlock()
+	}()
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+			if _, err := fmt.Fprintf(conn, "%s\n", line); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
+
+// write stores the sample, updates the alerts and notifies subscribers.
+func (s *socketSink) write(sample *Sample) error {
+	alerts := sampleAlerts(sample)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest = sample
+
+	var events []string
+	for _, name := range sortedKeys(alerts) {
+		if s.alerts[name] != alerts[name] {
+			events = append(events, fmt.Sprintf("ALERT %s %s", name, alerts[name]))
+		}
+	}
+	for _, name := range sortedKeys(s.alerts) {
+		if _, ok := alerts[name]; !ok {
+			events = append(events, "CLEAR "+name)
+		}
+	}
+	s.alerts = alerts
+
+	for lines, metric := range s.subscribers {
+		send := events
+		if metric != "alerts" {
+			value, _ := socketValue(sample, alerts, metric)
+			send = []string{value}
+		}
+		for _, line := range send {
+			select {
+			case lines <- line:
+				continue
+			default:
+			}
+			// Too far behind: drop the subscriber
+			close(lines)
+			delete(s.subscribers, lines)
+			break
+		}
+	}
+	return nil
+}
+
+// close removes the socket and disconnects the subscribers.
+func (s *socketSink) close() error {
+	err := s.ln.Close()
+	s.mu.Lock()
+	for lines := range s.subscribers {
+		close(lines)
+		delete(s.subscribers, lines)
+	}
+	s.mu.Unlock()
+	os.Remove(s.path)
+	return err
+}
+
+// sampleAlerts returns the alerts a sample raises by name: throttling,
+// liquid-cooling failures, the [health] limits and the [alerts] rules.
+func sampleAlerts(s *Sample) map[string]string {
+	alerts := map[string]string{}
+	if s.Throttled {
+		alerts["throttle"] = "thermal throttling"
+	}
+	if s.CoolingFailure != "" {
+		alerts["cooling"] = s.CoolingFailure
+	}
+	if s.HealthWarning != "" {
+		alerts["health"] = s.HealthWarning
+	}
+	if len(s.Alerts) > 0 {
+		alerts["threshold"] = strings.Join(s.Alerts, "; ")
+	}
+	return alerts
+}
+
+// socketValue formats a metric of the sample. Readings the machine does
+// not provide are "n/a", and so is everything before the first poll.
+func socketValue(s *Sample, alerts map[string]string, metric string) (string, error) {
+	flag := func(b bool) string {
+		if s.Time.IsZero() {
+			return "n/a"
+		}
+		if b {
+			return "1"
+		}
+		return "0"
+	}
+	if u, ok := metricUnits[metric]; ok {
+		v, ok := metricValue(s, metric)
+		if !ok || s.Time.IsZero() {
+			return "n/a", nil
+		}
+		return u.export(v, 1), nil
+	}
+	switch metric {
+	case "cores":
+		if s.Time.IsZero() {
+			return "n/a", nil
+		}
+		cores := make([]string, len(s.Cores))
+		for i, c := range s.Cores {
+			cores[i] = strconv.FormatFloat(c, 'f', 1, 64)
+		}
+		return strings.Join(cores, " "), nil
+	case "load":
+		if len(s.Load) == 0 || s.Time.IsZero() {
+			return "n/a", nil
+		}
+		return strconv.FormatFloat(s.Load[0], 'f', 2, 64), nil
+	case "stress":
+		return flag(s.Stress), nil
+	case "throttled":
+		return flag(s.Throttled), nil
+	case "alerts":
+		return strings.Join(sortedKeys(alerts), " "), nil
+	}
+	return "", fmt.Errorf("unknown metric: %s", metric)
+}
+
+// packagePower returns the RAPL package power of a sample summed over
+// sockets, and whether it has any.
+func packagePower(s *Sample) (float64, bool) {
+	total, found := 0.0, false
+	if s == nil {
+		return total, found
+	}
+	for _, p := range s.Power {
+		if strings.HasPrefix(p.Domain, "package") {
+			total += p.Watts
+			found = true
+		}
+	}
+	return total, found
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,203 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	splitWidth = 30 // Columns per pane graph
+	splitRows  = 5  // Rows per pane graph
+	splitAxis  = 7  // Axis label and the space after it
+	splitGap   = 4  // Columns between the panes
+)
+
+// splitMetric is one series a pane of the split view can plot.
+type splitMetric struct {
+	name  string                                   // Config value
+	title string                                   // Pane title, translated when drawn
+	value func(m *Monitor, p historyPoint) float64 // Reading at a point, negative when missing
+	unit  unit                                     // Formats readings, in at most 6 columns for the axis
+	color func(p historyPoint, v, max float64) string
+	fixed float64 // Top of a fixed axis; 0 for metrics without a full range
+	floor bool    // Start the axis at the lowest reading instead of 0, whatever the scale
+}
+
+// usageColor colors a share by getUsageColor.
+func usageColor(p historyPoint, v, max float64) string { return getUsageColor(v / max * 100) }
+
+// percentOrMissing maps the -1 of unmeasured subsystems and the 0 of
+// points recorded before the first reading to missing.
+func percentOrMissing(v float64) float64 {
+	if v <= 0 {
+		return -1
+	}
+	return v
+}
+
+// splitMetrics lists the pane graphs in the order G cycles through them.
+var splitMetrics = []splitMetric{
+	{name: "cpu", title: "CPU usage", fixed: 100, unit: unitPercent,
+		value: func(m *Monitor, p historyPoint) float64 { return p.cpu },
+		color: func(p historyPoint, v, max float64) string { return getTempColor(p.temp) }},
+	{name: "temp", title: "Temperature", floor: true, unit: unitCelsius,
+		value: func(m *Monitor, p historyPoint) float64 { return percentOrMissing(p.temp) },
+		color: func(p historyPoint, v, max float64) string { return getTempColor(v) }},
+	{name: "power", title: "Package power", unit: unitWatts, color: usageColor,
+		value: func(m *Monitor, p historyPoint) float64 { return m.packagePower(p) }},
+	{name: "memory", title: "Memory", fixed: 100, unit: unitPercent, color: usageColor,
+		value: func(m *Monitor, p historyPoint) float64 { return percentOrMissing(p.mem) }},
+	{name: "gpu", title: "GPU", fixed: 100, unit: unitPercent, color: usageColor,
+		value: func(m *Monitor, p historyPoint) float64 { return p.gpu }},
+	{name: "disk", title: "Disk", fixed: 100, unit: unitPercent, color: usageColor,
+		value: func(m *Monitor, p historyPoint) float64 { return p.disk }},
+	{name: "net", title: "Net", fixed: 100, unit: unitPercent, color: usageColor,
+		value: func(m *Monitor, p historyPoint) float64 { return p.net }},
+	{name: "psu", title: "PSU input", unit: unitWatts, color: usageColor,
+		value: func(m *Monitor, p historyPoint) float64 { return percentOrMissing(p.psuIn) }},
+	{name: "clock", title: "Core clock", unit: unitMHz,
+		value: func(m *Monitor, p historyPoint) float64 { return percentOrMissing(p.mhz) },
+		color: func(p historyPoint, v, max float64) string { return throttleColor(p) }},
+}
+
+// splitMetricIndex returns the position of a metric in splitMetrics.
+func splitMetricIndex(name string) (int, bool) {
+	for i, metric := range splitMetrics {
+		if metric.name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// packagePower sums the RAPL package domains of a point, or returns -1
+// without RAPL.
+func (m *Monitor) packagePower(p historyPoint) float64 {
+	total, found := 0.0, false
+	for i, name := range m.rapl.domainNames() {
+		if i < len(p.power) && strings.HasPrefix(name, "package") {
+			total += p.power[i]
+			found = true
+		}
+	}
+	if !found {
+		return -1
+	}
+	return total
+}
+
+// splitPane is one half of the split view: a metric over its own window,
+// so the two halves can show different resolutions side by side.
+type splitPane struct {
+	metric int           // Index into splitMetrics
+	window time.Duration // Time span of the pane
+}
+
+// splitView holds the two panes and which of them the keys act on.
+type splitView struct {
+	on    bool
+	focus int // Pane G, W and S change
+	panes [2]splitPane
+}
+
+// newSplitView sets up the panes from the [split] settings.
+func newSplitView(cfg *Config) splitView {
+	s := splitView{on: cfg.Split.Enabled}
+	left, _ := splitMetricIndex(cfg.Split.Left)
+	right, _ := splitMetricIndex(cfg.Split.Right)
+	s.panes[0] = splitPane{metric: left, window: cfg.Split.LeftScale}
+	s.panes[1] = splitPane{metric: right, window: cfg.Split.RightScale}
+	return s
+}
+
+// zoomSplitPane halves (in) or doubles the window of the focused pane.
+func (m *Monitor) zoomSplitPane(in bool) {
+	p := &m.split.panes[m.split.focus]
+	p.window = zoomWindow(p.window, in)
+}
+
+// cycleSplitMetric switches the focused pane to the next metric.
+func (m *Monitor) cycleSplitMetric() {
+	p := &m.split.panes[m.split.focus]
+	p.metric = (p.metric + 1) % len(splitMetrics)
+}
+
+// renderSplitPane draws a pane into lines of exactly splitAxis+splitWidth
+// columns: the title with the latest reading and time scale, then the
+// graph with its axis. The focused pane's title is marked with ▶.
+func (m *Monitor) renderSplitPane(i int) []string {
+	p := m.split.panes[i]
+	metric := splitMetrics[p.metric]
+	history := make([]historyPoint, splitWidth)
+	first := m.history.columnsBefore(history, p.window, m.graphSkip())
+
+	values := make([]float64, splitWidth)
+	lo, hi := math.Inf(1), 0.0
+	for col := range values {
+		values[col] = -1
+		if col >= first {
+			values[col] = metric.value(m, history[col])
+		}
+		if values[col] >= 0 {
+			lo, hi = math.Min(lo, values[col]), math.Max(hi, values[col])
+		}
+	}
+	current := "--"
+	if latest, ok := m.history.at(0); ok && metric.value(m, latest) >= 0 {
+		current = metric.unit.axis(metric.value(m, latest))
+	}
+	// Rows in steps of 5 so the axis labels stay round
+	axis := newYAxis(m.cfg.SplitGraphScale, values, metric.fixed, 5*splitRows)
+	if metric.floor {
+		if math.IsInf(lo, 1) {
+			lo = 0
+		}
+		lo = math.Floor(lo/10) * 10
+		axis = yAxis{lo: lo, hi: lo + math.Ceil(math.Max(hi-lo, 1)/splitRows/5)*5*splitRows}
+	}
+
+	colors := make([]string, splitWidth)
+	fractions := make([]float64, splitWidth)
+	for col, v := range values {
+		fractions[col] = axis.fraction(v)
+		if v >= 0 {
+			colors[col] = metric.color(history[col], v, axis.hi)
+		}
+	}
+	grid := plotSeries(fractions, colors, splitRows, m.cfg.CPUGraphStyle)
+
+	marker, titleColor := "  ", colorCyan
+	if i == m.split.focus {
+		marker, titleColor = "▶ ", colorYellow
+	}
+	title := fmt.Sprintf("%s%s %s  %s", marker, tr(metric.title), current, formatWindow(p.window))
+	lines := []string{titleColor + padRight(title, splitAxis+splitWidth) + colorReset}
+	for row := splitRows - 1; row >= 0; row-- {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s%s%s ", colorCyan, padLeft(metric.unit.axis(axis.top(row, splitRows)), 6), colorReset)
+		for _, cell := range grid[row] {
+			if cell.glyph != "" {
+				fmt.Fprintf(&b, "%s%s%s", cell.color, cell.glyph, colorReset)
+			} else {
+				b.WriteString(" ")
+			}
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// drawSplitView draws the two panes side by side in place of the history
+// graph, for correlating two metrics at different resolutions.
+func (m *Monitor) drawSplitView() {
+	left, right := m.renderSplitPane(0), m.renderSplitPane(1)
+	for i := range left {
+		fmt.Fprintf(m.out, "%s%s%s\r\n", left[i], strings.Repeat(" ", splitGap), right[i])
+	}
+	keys := m.cfg.KeyBindings
+	hint := fmt.Sprintf(tr("%s switches pane, %s changes its graph, %s/%s zoom it, %s leaves"),
+		keys.label(actionSplitFocus), keys.label(actionGraph), keys.label(actionZoomIn), keys.label(actionZoomOut), keys.label(actionSplit))
+	fmt.Fprintf(m.out, "        %s%s%s\r\n", colorYellow, hint, colorReset)
+}
@@ -0,0 +1,162 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// graphMode selects which history graph is drawn under the core bars.
+type graphMode int
+
+const (
+	graphCombined  graphMode = iota // CPU usage height colored by temperature
+	graphStacked                    // Normalized subsystem utilization stacked as areas
+	graphDualAxis                   // CPU bars with a ° temperature line on its own axis
+	graphMultiTemp                  // Main and secondary temperature sensors on one axis
+	graphPower                      // RAPL power per domain on one axis
+	graphLatency                    // Wakeup latency of the probe thread
+	graphNetwork                    // iperf3 throughput with CPU and interrupt usage
+	graphPSU                        // PSU input and output power with CPU usage
+	graphClock                      // Mean core clock colored by throttle state
+	graphModeCount
+)
+
+// parseGraphMode converts a config value into a graphMode.
+func parseGraphMode(name string) (graphMode, bool) {
+	switch name {
+	case "", "combined":
+		return graphCombined, true
+	case "stacked":
+		return graphStacked, true
+	case "dual":
+		return graphDualAxis, true
+	case "temps":
+		return graphMultiTemp, true
+	case "power":
+		return graphPower, true
+	case "latency":
+		return graphLatency, true
+	case "network":
+		return graphNetwork, true
+	case "psu":
+		return graphPSU, true
+	case "clock":
+		return graphClock, true
+	}
+	return graphCombined, false
+}
+
+// stackedSeries describes one layer of the stacked activity graph.
+type stackedSeries struct {
+	label   string
+	r, g, b int
+	value   func(p historyPoint) float64
+}
+
+// stackedSeriesList defines the layers of the stacked graph, bottom first.
+var stackedSeriesList = []stackedSeries{
+	{"CPU", 80, 160, 255, func(p historyPoint) float64 { return p.cpu }},
+	{"GPU", 190, 90, 255, func(p historyPoint) float64 { return p.gpu }},
+	{"Disk", 255, 170, 0, func(p historyPoint) float64 { return p.disk }},
+	{"Net", 60, 220, 120, func(p historyPoint) float64 { return p.net }},
+}
+
+// activeStackedSeries returns the layers that can currently be measured.
+// Subsystems without a data source report -1 and are hidden.
+func (m *Monitor) activeStackedSeries() []stackedSeries {
+	last := m.activity.last
+	probe := historyPoint{gpu: last.gpu, disk: last.disk, net: last.net}
+
+	var active []stackedSeries
+	for _, series := range stackedSeriesList {
+		if series.value(probe) >= 0 {
+			active = append(active, series)
+		}
+	}
+	return active
+}
+
+// drawStackedGraph renders CPU, GPU, disk, and network utilization as
+// stacked colored areas so whole-system activity reads as one picture.
+// Each series gets an equal share of the height, so the graph is full only
+// when every subsystem is at 100%. Boundaries inside a cell are drawn with
+// eighth blocks using the lower layer as foreground and the upper as background.
+func (m *Monitor) drawStackedGraph() {
+	series := m.activeStackedSeries()
+	const rows = 5
+
+	// Title line doubles as the legend with current values
+	latest := m.displayBuffer[len(m.displayBuffer)-1]
+	var legend strings.Builder
+	for _, s := range series {
+		fmt.Fprintf(&legend, " \033[38;2;%d;%d;%dm█%s %s %s", s.r, s.g, s.b, colorReset, s.label, formatPercent(s.value(latest), 0))
+	}
+	fmt.Fprintf(m.out, "%s%s%s%s%*s\r\n", colorCyan, tr("Stacked Activity Graph"), colorReset, legend.String(), 10, "")
+
+	// Cumulative layer boundaries per column, in eighths of a row
+	total := float64(rows * 8)
+	bounds := make([][]int, baseGraphWidth)
+	for i := 0; i < baseGraphWidth; i++ {
+		top := 0.0
+		bounds[i] = make([]int, len(series))
+		for j, s := range series {
+			v := s.value(m.displayBuffer[i])
+			if v < 0 {
+				v = 0
+			}
+			top += v / 100 / float64(len(series)) * total
+			bounds[i][j] = int(top + 0.5)
+		}
+	}
+
+	labels := []string{"100%   ", "       ", "       ", "       ", "0%     "}
+	for row := rows - 1; row >= 0; row-- {
+		fmt.Fprintf(m.out, "%s%s%s", colorCyan, labels[rows-1-row], colorReset)
+		for i := 0; i < baseGraphWidth; i++ {
+			fmt.Fprint(m.out, stackedCell(series, bounds[i], row*8))
+		}
+		fmt.Fprint(m.out, "\r\n")
+	}
+
+	fmt.Fprintf(m.out, "        %s%s%s\r\n", colorYellow, m.zoomHint(), colorReset)
+	fmt.Fprintf(m.out, "        %s%-10s%s\r\n", colorCyan, formatWindow(m.window), colorReset)
+}
+
+// stackedCell renders one character cell whose bottom edge is at eighth
+// base. The layer covering the bottom of the cell is drawn as a partial
+// block in its color, and the next layer up (if any) fills the rest via
+// the background color.
+func stackedCell(series []stackedSeries, bounds []int, base int) string {
+	// Find the layer at the bottom of this cell
+	lower := -1
+	for j, b := range bounds {
+		if b > base {
+			lower = j
+			break
+		}
+	}
+	if lower < 0 {
+		return " " // Above the top of the stack
+	}
+
+	level := bounds[lower] - base
+	if level >= 8 {
+		s := series[lower]
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm█%s", s.r, s.g, s.b, colorReset)
+	}
+
+	lo := series[lower]
+	cell := fmt.Sprintf("\033[38;2;%d;%d;%dm", lo.r, lo.g, lo.b)
+	// The next non-empty layer fills the upper part of the cell
+	for j := lower + 1; j < len(bounds); j++ {
+		if bounds[j] > bounds[lower] {
+			up := series[j]
+			cell += fmt.Sprintf("\033[48;2;%d;%d;%dm", up.r, up.g, up.b)
+			break
+		}
+	}
+	if level < 1 {
+		level = 1
+	}
+	return cell + barChars[level] + colorReset
+}
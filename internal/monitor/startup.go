@@ -0,0 +1,175 @@
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// startupCheck is the outcome of one sanity check run at launch.
+type startupCheck struct {
+	name   string
+	ok     bool
+	detail string // What was found, or what will be missing when degraded
+}
+
+// runStartupChecks checks the sources the display depends on: the CPU
+// statistics, a plausible temperature reading, the stress backend, and
+// the RAPL energy counters. A source that fails otherwise shows up only
+// as zeros or a missing row.
+func (m *Monitor) runStartupChecks() []startupCheck {
+	return []startupCheck{m.checkCPUStats(), m.checkTemperature(), m.checkStressEngine(), m.checkRAPL()}
+}
+
+// checkCPUStats checks that the per-core CPU times can be read.
+func (m *Monitor) checkCPUStats() startupCheck {
+	c := startupCheck{name: tr("CPU statistics")}
+	stats := m.getCPUStats()
+	if len(stats) != m.cores+1 || stats[0].user+stats[0].system+stats[0].idle == 0 {
+		c.detail = fmt.Sprintf(tr("%s not readable; CPU usage will read 0%%"), cpuStatsSource())
+		return c
+	}
+	c.ok = true
+	c.detail = fmt.Sprintf(tr("%d cores"), m.cores)
+	return c
+}
+
+// checkTemperature checks that the main temperature reads within the
+// [sensors] valid range, and names the sensors that read outside it when
+// it does not.
+func (m *Monitor) checkTemperature() startupCheck {
+	c := startupCheck{name: tr("Temperature")}
+	if temp := m.getTemperature(); temp > 0 {
+		c.ok = true
+		c.detail = strings.TrimSpace(m.tempSensorID + " " + formatTemp(temp, 1))
+		return c
+	}
+	var implausible []string
+	for i := range m.sensors {
+		if temp, ok := m.sensors[i].read(); ok && !m.cfg.validReading(temp) {
+			implausible = append(implausible, fmt.Sprintf("%s %s", m.sensors[i].id, formatTemp(temp, 1)))
+		}
+	}
+	switch {
+	case len(implausible) > 0:
+		c.detail = fmt.Sprintf(tr("no plausible reading (%s); temperatures will read 0"), strings.Join(implausible, ", "))
+	case len(m.sensors) > 0:
+		c.detail = tr("no CPU sensor found; choose one in the sensor picker")
+	default:
+		c.detail = tr("no sensor found; temperatures will read 0")
+	}
+	return c
+}
+
+// checkStressEngine checks that SPACE can start a stress test: the
+// external command must be installed, and a built-in worker must run and
+// stop within a second.
+func (m *Monitor) checkStressEngine() startupCheck {
+	c := startupCheck{name: tr("Stress engine")}
+	if m.cfg.Stress.Backend == "stress" {
+		c.ok = stressBinaryAvailable()
+		c.detail = tr("stress command")
+		if !c.ok {
+			c.detail = tr("stress command not found; stress testing is disabled")
+		}
+		return c
+	}
+	before := atomic.LoadUint64(&stressSink)
+	e := startStressEngine(1, "int")
+	time.Sleep(20 * time.Millisecond)
+	stopped := make(chan struct{})
+	go func() {
+		e.close()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		c.detail = tr("built-in worker did not stop")
+		return c
+	}
+	if atomic.LoadUint64(&stressSink) == before {
+		c.detail = tr("built-in worker did not run")
+		return c
+	}
+	c.ok = true
+	c.detail = tr("built-in engine")
+	return c
+}
+
+// checkRAPL checks that the energy counters behind the package power are
+// readable, telling counters the kernel keeps from root apart from none.
+func (m *Monitor) checkRAPL() startupCheck {
+	c := startupCheck{name: tr("RAPL energy")}
+	if names := m.rapl.domainNames(); len(names) > 0 {
+		c.ok = true
+		c.detail = strings.Join(names, ", ")
+		return c
+	}
+	if zones, _ := filepath.Glob(filepath.Join(raplDir, "intel-rapl:*")); len(zones) > 0 {
+		c.detail = tr("counters are root-only; run kkperf helper as root to show power")
+	} else {
+		c.detail = tr("no counters found; power is not shown")
+	}
+	return c
+}
+
+// startupDegraded reports whether any check failed.
+func startupDegraded(checks []startupCheck) bool {
+	for _, c := range checks {
+		if !c.ok {
+			return true
+		}
+	}
+	return false
+}
+
+// startChecks runs the startup checks and, when something is degraded,
+// opens the diagnostics page, or in accessible mode announces what is
+// degraded.
+func (m *Monitor) startChecks() {
+	m.startupChecks = m.runStartupChecks()
+	for _, c := range m.startupChecks {
+		logInfo("startup check", "check", c.name, "ok", c.ok, "detail", c.detail)
+	}
+	if !startupDegraded(m.startupChecks) {
+		return
+	}
+	if m.cfg.Accessible {
+		for _, c := range m.startupChecks {
+			if !c.ok {
+				m.say("%s %s: %s", c.name, tr("degraded"), c.detail)
+			}
+		}
+		return
+	}
+	m.showStartupChecks = true
+}
+
+// handleStartupChecksKey closes the diagnostics page on any key. It
+// returns false when the application should quit.
+func (m *Monitor) handleStartupChecksKey(key byte) bool {
+	if m.pageAction(key, actionHelp) == actionQuit {
+		return false
+	}
+	m.showStartupChecks = false
+	fmt.Fprint(m.out, clearScreen)
+	return true
+}
+
+// displayStartupChecksPage lists what works and what is degraded, with
+// what the display will be missing.
+func (m *Monitor) displayStartupChecksPage() {
+	fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor - %s ===%s\r\n\r\n", colorGreen, tr("Startup Checks"), colorReset)
+	for _, c := range m.startupChecks {
+		status := fmt.Sprintf("%s%s%s", colorGreen, padRight(tr("OK"), 10), colorReset)
+		if !c.ok {
+			status = fmt.Sprintf("%s%s%s", colorYellow, padRight(tr("DEGRADED"), 10), colorReset)
+		}
+		fmt.Fprintf(m.out, "  %s%s%s%s%s\r\n", status, colorBlue, padRight(c.name, 18), colorReset, c.detail)
+	}
+	fmt.Fprint(m.out, "\r\n")
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorYellow, tr("Press any key to continue"), colorReset)
+}
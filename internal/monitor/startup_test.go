@@ -0,0 +1,32 @@
+package monitor
+
+import (
+	"testing"
+)
+
+// TestStartupChecks checks that the startup checks pass on a complete
+// machine and report unreadable CPU statistics and a missing stress
+// command.
+func TestStartupChecks(t *testing.T) {
+	m, _, _ := fixtureMonitor(t, "4cores", nil, nil)
+	for _, c := range m.runStartupChecks() {
+		if !c.ok {
+			t.Errorf("%s degraded: %s", c.name, c.detail)
+		}
+	}
+
+	procDir = t.TempDir() // Restored by fixtureMonitor
+	m.lastCPUStats = nil
+	m.cfg.Stress.Backend = "stress"
+	t.Setenv("PATH", "")
+	checks := m.runStartupChecks()
+	if cpu := checks[0]; cpu.ok {
+		t.Errorf("CPU statistics OK without /proc/stat: %s", cpu.detail)
+	}
+	if stress := checks[2]; stress.ok {
+		t.Errorf("stress backend OK without the stress command: %s", stress.detail)
+	}
+	if !startupDegraded(checks) {
+		t.Error("startup not degraded")
+	}
+}
@@ -0,0 +1,163 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// stressPatterns names the workloads of the built-in stress engine.
+var stressPatterns = map[string]bool{"int": true, "fpu": true, "memory": true, "mixed": true}
+
+// stressBufferSize is the size of each of the two buffers a memory worker
+// copies between, well beyond the last-level cache of desktop CPUs.
+const stressBufferSize = 32 << 20
+
+// stressSink keeps the compiler from optimizing the workloads away.
+var stressSink uint64
+
+// stressEngine is the built-in stress test: one worker per CPU, each on
+// an OS thread of its own pinned to its CPU, running a workload in short
+// batches until it is stopped. It needs no external tools.
+type stressEngine struct {
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// startStressEngine starts workers running pattern. The "mixed" pattern
+// alternates integer and FPU workers and puts every fourth on memory.
+func startStressEngine(workers int, pattern string) *stressEngine {
+	e := &stressEngine{stop: make(chan struct{})}
+	for i := 0; i < workers; i++ {
+		work := pattern
+		if pattern == "mixed" {
+			work = []string{"int", "fpu", "int", "memory"}[i%4]
+		}
+		e.wg.Add(1)
+		go e.worker(i, work)
+	}
+	return e
+}
+
+// worker runs one workload on cpu until the engine stops. A batch takes
+// about a millisecond, so stopping is quick.
+func (e *stressEngine) worker(cpu int, pattern string) {
+	defer e.wg.Done()
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := pinThread(cpu % numCPU()); err != nil && cpu == 0 {
+		logInfo("stress workers not pinned to their CPUs", "err", err)
+	}
+
+	var src, dst []byte
+	if pattern == "memory" {
+		src, dst = make([]byte, stressBufferSize), make([]byte, stressBufferSize)
+	}
+	x, f, offset := uint64(cpu)+1, float64(cpu)+1.5, 0
+	for {
+		select {
+		case <-e.stop:
+			atomic.AddUint64(&stressSink, x+uint64(f))
+			return
+		default:
+		}
+		switch pattern {
+		case "fpu":
+			for i := 0; i < 100000; i++ {
+				f = math.Sqrt(f*f+1.000001) * 0.999999
+			}
+		case "memory":
+			copy(dst[offset:offset+1<<20], src[offset:offset+1<<20])
+			offset = (offset + 1<<20) % stressBufferSize
+			x += uint64(dst[offset])
+		default:
+			// xorshift64
+			for i := 0; i < 300000; i++ {
+				x ^= x << 13
+				x ^= x >> 7
+				x ^= x << 17
+			}
+		}
+	}
+}
+
+// close stops the workers and waits for them to exit.
+func (e *stressEngine) close() {
+	close(e.stop)
+	e.wg.Wait()
+}
+
+// stressBinaryAvailable reports whether the external 'stress' command is
+// installed.
+func stressBinaryAvailable() bool {
+	_, err := exec.LookPath("stress")
+	return err == nil
+}
+
+// checkStressAvailable reports whether SPACE can start a stress test: the
+// built-in engine always can, the "stress" backend needs the command.
+func (m *Monitor) checkStressAvailable() bool {
+	return m.cfg.Stress.Backend != "stress" || stressBinaryAvailable()
+}
+
+// startStress starts the stress test on the configured backend: the
+// built-in engine with [stress] workers running its pattern, or the
+// external 'stress' command with one CPU worker per core. Only starts if
+// stress testing is available and not already running, and not while the
+// [safety] cooldown after a safety stop lasts.
+func (m *Monitor) startStress() {
+	if m.stressRunning || !m.stressAvailable {
+		return
+	}
+	if m.safetyCooldown() > 0 {
+		if !m.cfg.Accessible {
+			fmt.Fprint(m.out, "\a") // Accessible mode says why instead
+		}
+		return
+	}
+	if m.cfg.Stress.Backend == "stress" {
+		m.stressCmd = exec.Command("stress", "--cpu", strconv.Itoa(m.cores))
+		// Killing stress alone would leave its workers spinning
+		setProcessGroup(m.stressCmd)
+		if m.stressCmd.Start() != nil {
+			m.stressCmd = nil
+			return
+		}
+		children.add(m.stressCmd)
+	} else {
+		workers := m.cfg.Stress.Workers
+		if workers == 0 {
+			workers = runtime.GOMAXPROCS(0)
+		}
+		m.stressEngine = startStressEngine(workers, m.cfg.Stress.Pattern)
+	}
+	m.stressRunning = true
+	m.safetyStop = ""
+}
+
+// stopStress stops a running stress test on either backend.
+func (m *Monitor) stopStress() {
+	if !m.stressRunning {
+		return
+	}
+	if m.stressCmd != nil {
+		// Without the group, at least the command itself must go, or
+		// Wait would block until it ends on its own
+		if err := killProcessGroup(m.stressCmd); err != nil {
+			logWarn("stress process group not killed", "err", err)
+			m.stressCmd.Process.Kill()
+		}
+		m.stressCmd.Wait()
+		children.remove(m.stressCmd)
+		m.stressCmd = nil
+	}
+	if m.stressEngine != nil {
+		m.stressEngine.close()
+		m.stressEngine = nil
+	}
+	m.stressRunning = false
+}
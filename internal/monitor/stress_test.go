@@ -0,0 +1,32 @@
+package monitor
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// TestStopStress checks that stopping a stress command not running in a
+// process group of its own still kills it instead of waiting for it.
+func TestStopStress(t *testing.T) {
+	m := &Monitor{cfg: defaultConfig()}
+	m.stressCmd = exec.Command("sleep", "60")
+	if err := m.stressCmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	m.stressRunning = true
+
+	done := make(chan bool)
+	go func() {
+		m.stopStress()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("stopStress waited for the command to end")
+	}
+	if m.stressRunning || m.stressCmd != nil {
+		t.Errorf("still running after stopStress: %v, %v", m.stressRunning, m.stressCmd)
+	}
+}
@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// influxLines renders a sample in InfluxDB line protocol: one "kkperf"
+// line with the system-wide fields and one "kkperf_core" line per core.
+// Telegraf adds the host tag itself, so none is written here.
+func influxLines(s *Sample) string {
+	var b strings.Builder
+	ts := s.Time.UnixNano()
+
+	fields := []string{fmt.Sprintf("cpu_usage=%g", s.CPU), fmt.Sprintf("iowait=%g", s.IOWait), fmt.Sprintf("blocked=%di", s.Blocked)}
+	if s.Container != "" {
+		fields = append(fields, fmt.Sprintf("cpu_limit=%g", s.CPULimit), fmt.Sprintf("cpu_throttled=%g", s.CPUThrottled))
+	}
+	if len(s.Load) == 3 {
+		fields = append(fields, fmt.Sprintf("load1=%g", s.Load[0]), fmt.Sprintf("load5=%g", s.Load[1]),
+			fmt.Sprintf("load15=%g", s.Load[2]), fmt.Sprintf("running=%di", s.Running))
+	}
+	if s.Temp > 0 {
+		fields = append(fields, fmt.Sprintf("temperature=%g", s.Temp), fmt.Sprintf("temperature_raw=%g", s.RawTemp))
+	}
+	if s.Limited {
+		fields = append(fields, fmt.Sprintf("headroom=%g", s.Headroom))
+	}
+	if s.HasAmbient {
+		fields = append(fields, fmt.Sprintf("ambient=%g", s.Ambient))
+	}
+	fields = append(fields, fmt.Sprintf("zombies=%di", s.Zombies), fmt.Sprintf("threads=%di", s.Threads), fmt.Sprintf("open_files=%di", s.FDs))
+	if s.Entropy >= 0 {
+		fields = append(fields, fmt.Sprintf("entropy=%di", s.Entropy))
+	}
+	if s.ClockSource != "" {
+		fields = append(fields, fmt.Sprintf("clock_synced=%t", s.ClockSynced), fmt.Sprintf("clock_offset=%g", s.ClockOffset))
+	}
+	fields = append(fields, fmt.Sprintf("clock_steps=%di", s.ClockSteps))
+	if s.MemTotal > 0 {
+		fields = append(fields, fmt.Sprintf("mem_used=%di", s.MemUsed), fmt.Sprintf("mem_total=%di", s.MemTotal),
+			fmt.Sprintf("swap_used=%di", s.SwapUsed), fmt.Sprintf("swap_total=%di", s.SwapTotal))
+	}
+	if s.PSUInput > 0 {
+		fields = append(fields, fmt.Sprintf("psu_input=%g", s.PSUInput))
+	}
+	if s.PSUOutput > 0 {
+		fields = append(fields, fmt.Sprintf("psu_output=%g", s.PSUOutput))
+	}
+	if s.GPU >= 0 {
+		fields = append(fields, fmt.Sprintf("gpu_busy=%g", s.GPU))
+	}
+	if s.Disk >= 0 {
+		fields = append(fields, fmt.Sprintf("disk_busy=%g", s.Disk))
+	}
+	if s.Net >= 0 {
+		fields = append(fields, fmt.Sprintf("net_utilization=%g", s.Net))
+	}
+	fields = append(fields, fmt.Sprintf("stress=%t", s.Stress))
+	fmt.Fprintf(&b, "kkperf %s %d\n", strings.Join(fields, ","), ts)
+
+	for i, usage := range s.Cores {
+		if i < len(s.CoreTemps) && s.CoreTemps[i] > 0 {
+			fmt.Fprintf(&b, "kkperf_core,core=%d usage=%g,temperature=%g %d\n", i, usage, s.CoreTemps[i], ts)
+			continue
+		}
+		fmt.Fprintf(&b, "kkperf_core,core=%d usage=%g %d\n", i, usage, ts)
+	}
+	for _, p := range s.Power {
+		fmt.Fprintf(&b, "kkperf_power,domain=%s watts=%g %d\n", p.Domain, p.Watts, ts)
+	}
+	for _, d := range s.Disks {
+		fmt.Fprintf(&b, "kkperf_disk,device=%s read_bytes=%g,write_bytes=%g,read_iops=%g,write_iops=%g %d\n",
+			d.Device, d.ReadBytes, d.WriteBytes, d.ReadIOPS, d.WriteIOPS, ts)
+	}
+	for _, g := range s.GPUs {
+		var gpu []string
+		if g.Busy >= 0 {
+			gpu = append(gpu, fmt.Sprintf("busy=%g", g.Busy))
+		}
+		if g.Temp > 0 {
+			gpu = append(gpu, fmt.Sprintf("temperature=%g", g.Temp))
+		}
+		if g.VRAMTotal > 0 {
+			gpu = append(gpu, fmt.Sprintf("vram_used=%di", g.VRAMUsed), fmt.Sprintf("vram_total=%di", g.VRAMTotal))
+		}
+		if len(gpu) > 0 {
+			fmt.Fprintf(&b, "kkperf_gpu,gpu=%d,driver=%s %s %d\n", g.Index, g.Driver, strings.Join(gpu, ","), ts)
+		}
+	}
+	for _, r := range s.PSURails {
+		fmt.Fprintf(&b, "kkperf_psu_rail,rail=%s volts=%g %d\n", r.Rail, r.Volts, ts)
+	}
+	for _, f := range s.Fans {
+		// Tag values escape spaces, commas and equals signs
+		sensor := strings.NewReplacer(" ", "\\ ", ",", "\\,", "=", "\\=").Replace(f.Sensor)
+		fmt.Fprintf(&b, "kkperf_fan,sensor=%s rpm=%g %d\n", sensor, f.RPM, ts)
+	}
+	for _, bat := range s.Batteries {
+		fields := []string{fmt.Sprintf("status=%q", bat.Status), fmt.Sprintf("watts=%g", bat.Watts)}
+		if bat.Percent >= 0 {
+			fields = append(fields, fmt.Sprintf("percent=%g", bat.Percent))
+		}
+		fmt.Fprintf(&b, "kkperf_battery,battery=%s %s %d\n", bat.Name, strings.Join(fields, ","), ts)
+	}
+	for _, r := range s.Cooling {
+		sensor := strings.NewReplacer(" ", "\\ ", ",", "\\,", "=", "\\=").Replace(r.Sensor)
+		fmt.Fprintf(&b, "kkperf_cooling,sensor=%s,kind=%s value=%g %d\n", sensor, r.Kind, r.Value, ts)
+	}
+	return b.String()
+}
+
+// runTelegraf serves Telegraf's exec and execd input plugins.
+//
+// In "exec" mode one sample is printed and the process exits. In "execd"
+// mode the process stays running and prints a sample whenever Telegraf
+// signals it: a newline on stdin (signal = "STDIN"), SIGHUP, SIGUSR1 or
+// SIGUSR2. With [telegraf] interval set, samples are also printed on that
+// schedule for signal = "none". Usage covers the time since the previous
+// sample. The process exits when stdin closes or on SIGINT/SIGTERM.
+func (m *Monitor) runTelegraf(mode string) error {
+	m.headless = true
+	defer m.closeSinks()
+
+	emit := func() error {
+		sample := m.pollSample()
+		m.writeSinks(&sample)
+		_, err := os.Stdout.WriteString(influxLines(&sample))
+		return err
+	}
+
+	switch mode {
+	case "exec":
+		time.Sleep(500 * time.Millisecond)
+		return emit()
+	case "execd":
+	default:
+		return fmt.Errorf("--telegraf must be \"exec\" or \"execd\"")
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, append([]os.Signal{syscall.SIGINT, syscall.SIGTERM}, sampleSignals...)...)
+
+	// Each line on stdin is a request for one sample; EOF means Telegraf
+	// is shutting the plugin down
+	stdinLines := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			stdinLines <- struct{}{}
+		}
+		close(stdinLines)
+	}()
+
+	var tick <-chan time.Time
+	if m.cfg.Telegraf.Interval > 0 {
+		ticker := time.NewTicker(m.cfg.Telegraf.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case sig := <-sigChan:
+			if sig == syscall.SIGINT || sig == syscall.SIGTERM {
+				return nil
+			}
+		case _, ok := <-stdinLines:
+			if !ok {
+				return nil
+			}
+		case <-tick:
+		}
+		if err := emit(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,76 @@
+//go:build !windows
+
+package monitor
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// wslTemps follows the host's CPU temperature under WSL, through the
+// Windows PowerShell that WSL's interop runs.
+var wslTemps = &wmiTemps{shell: "powershell.exe"}
+
+// fallbackTemperature reads the CPU temperature when no known CPU sensor
+// was found: AMD k10temp via the 'sensors' command, then the first hwmon
+// and thermal zone inputs, then the Intel thermal MSRs, and under WSL the
+// host's sensors. Returns 0 if none is readable.
+func (m *Monitor) fallbackTemperature() (float64, string) {
+	// Try k10temp using sensors command first (most accurate for AMD)
+	output, err := exec.Command("sensors", "k10temp-pci-00c3").Output()
+	if err == nil && m.cfg.sensorAllowed("k10temp/Tctl") {
+		lines := strings.Split(string(output), "\n")
+		for _, line := range lines {
+			if strings.Contains(line, "Tctl:") {
+				fields := strings.Fields(line)
+				if len(fields) >= 2 {
+					tempStr := strings.TrimSuffix(strings.TrimPrefix(fields[1], "+"), "°C")
+					temp, err := strconv.ParseFloat(tempStr, 64)
+					if err == nil && m.cfg.validReading(temp) {
+						return temp, "k10temp/Tctl"
+					}
+				}
+			}
+		}
+	}
+
+	// Fallback to sys sensors if k10temp fails
+	sensors := []string{
+		filepath.Join(hwmonDir, "hwmon0", "temp1_input"),
+		filepath.Join(hwmonDir, "hwmon1", "temp1_input"),
+		filepath.Join(hwmonDir, "hwmon2", "temp1_input"),
+		filepath.Join(hwmonDir, "hwmon3", "temp1_input"),
+		filepath.Join(hwmonDir, "hwmon4", "temp1_input"),
+		filepath.Join(thermalDir, "thermal_zone0", "temp"),
+	}
+
+	for _, sensor := range sensors {
+		if m.deniedSensorPaths[sensor] {
+			continue
+		}
+		data, err := ioutil.ReadFile(sensor)
+		if err == nil {
+			temp, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+			if err == nil && m.cfg.validReading(temp/1000.0) {
+				// Convert from millidegrees to degrees
+				return temp / 1000.0, sensorIDForPath(m.sensors, sensor)
+			}
+		}
+	}
+
+	if temp, tjMax, ok := readMSRTemperature(); ok && m.cfg.sensorAllowed(msrSensorID) && m.cfg.validReading(temp) {
+		m.msrTjMax = tjMax
+		return temp, msrSensorID
+	}
+
+	if m.cfg.WSLTemperature && isWSL() {
+		temp, id := wslTemps.read()
+		if id = "wsl/" + id; temp > 0 && m.cfg.sensorAllowed(id) && m.cfg.validReading(temp) {
+			return temp, id
+		}
+	}
+	return 0, ""
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package monitor
+
+// windowsTemps follows the CPU temperature through PowerShell.
+var windowsTemps = &wmiTemps{shell: "powershell"}
+
+// fallbackTemperature reads the CPU temperature from LibreHardwareMonitor
+// or OpenHardwareMonitor when one is running, since they read the CPU's
+// own sensors, and otherwise from the ACPI thermal zones, which need an
+// elevated prompt and often report the motherboard rather than the CPU.
+// Returns 0 until the first query answers or if neither source is
+// available.
+func (m *Monitor) fallbackTemperature() (float64, string) {
+	temp, id := windowsTemps.read()
+	if temp == 0 || !m.cfg.sensorAllowed(id) || !m.cfg.validReading(temp) {
+		return 0, ""
+	}
+	return temp, id
+}
@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// theme controls how colors are rendered for the viewing environment.
+type theme struct {
+	banded bool   // Replace smooth gradients with a few solid color bands
+	prefix string // Escape sequences emitted before every gradient color
+}
+
+// activeTheme is consulted by getTempColor and getUsageColor.
+var activeTheme = &theme{}
+
+// colorBand is one solid color covering values below limit.
+type colorBand struct {
+	limit   float64
+	r, g, b int
+}
+
+// Bands for the high-contrast theme: fully saturated colors with large
+// steps so they stay distinguishable on washed-out projectors.
+var (
+	highContrastTempBands = []colorBand{
+		{45, 0, 255, 255},  // Cool: cyan
+		{60, 0, 255, 0},    // Normal: green
+		{75, 255, 255, 0},  // Warm: yellow
+		{85, 255, 128, 0},  // Hot: orange
+		{95, 255, 0, 0},    // Very hot: red
+		{1e9, 255, 0, 255}, // Critical: magenta
+	}
+	highContrastUsageBands = []colorBand{
+		{20, 0, 255, 255},
+		{40, 0, 255, 0},
+		{60, 255, 255, 0},
+		{80, 255, 128, 0},
+		{1e9, 255, 0, 0},
+	}
+)
+
+// applyTheme activates a theme by name. The high-contrast theme uses bold
+// glyphs on a black background, bright basic colors, and banded gradients.
+func applyTheme(name string) error {
+	switch name {
+	case "", "default":
+		activeTheme = &theme{}
+	case "high-contrast":
+		activeTheme = &theme{
+			banded: true,
+			prefix: "\033[1m\033[48;2;0;0;0m", // Bold on a dark background
+		}
+		colorRed = "\033[1;91m"
+		colorBrightRed = "\033[1;91m"
+		colorGreen = "\033[1;92m"
+		colorYellow = "\033[1;93m"
+		colorDarkYellow = "\033[1;33m"
+		colorBlue = "\033[1;94m"
+		colorMagenta = "\033[1;95m"
+		colorCyan = "\033[1;96m"
+	default:
+		return fmt.Errorf("unknown theme %q (expected \"default\" or \"high-contrast\")", name)
+	}
+	return nil
+}
+
+// bandColor returns the escape sequence for the band containing v.
+func (t *theme) bandColor(v float64, bands []colorBand) string {
+	for _, band := range bands {
+		if v < band.limit {
+			return fmt.Sprintf("%s\033[38;2;%d;%d;%dm", t.prefix, band.r, band.g, band.b)
+		}
+	}
+	last := bands[len(bands)-1]
+	return fmt.Sprintf("%s\033[38;2;%d;%d;%dm", t.prefix, last.r, last.g, last.b)
+}
+
+// colorToBackground converts the 24-bit foreground color in an escape
+// sequence produced by getTempColor or getUsageColor into the matching
+// background sequence. Theme prefixes before the color are ignored.
+func colorToBackground(fg string) string {
+	i := strings.Index(fg, "\033[38;2;")
+	if i < 0 {
+		return ""
+	}
+	var r, g, b int
+	if _, err := fmt.Sscanf(fg[i:], "\033[38;2;%d;%d;%dm", &r, &g, &b); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\033[48;2;%d;%d;%dm", r, g, b)
+}
@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// throttleSampler detects thermal throttling from the per-CPU
+// thermal_throttle event counters the Intel thermal driver exposes in
+// sysfs. Other platforms have no equivalent counters.
+type throttleSampler struct {
+	available bool   // Whether the counters exist on this system
+	lastCount uint64 // Sum of all core and package throttle events
+}
+
+// newThrottleSampler takes the initial counter reading.
+func newThrottleSampler() *throttleSampler {
+	count, ok := readThrottleCount()
+	return &throttleSampler{available: ok, lastCount: count}
+}
+
+// sample reports whether any throttle event occurred since the previous call.
+func (t *throttleSampler) sample() bool {
+	if !t.available {
+		return false
+	}
+	count, ok := readThrottleCount()
+	if !ok {
+		return false
+	}
+	throttled := count > t.lastCount
+	t.lastCount = count
+	return throttled
+}
+
+// readThrottleCount sums core_throttle_count and package_throttle_count
+// over all CPUs.
+func readThrottleCount() (uint64, bool) {
+	dirs, _ := filepath.Glob(filepath.Join(cpuDir, "cpu[0-9]*", "thermal_throttle"))
+	var total uint64
+	found := false
+	for _, dir := range dirs {
+		for _, name := range []string{"core_throttle_count", "package_throttle_count"} {
+			data, err := ioutil.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			if n, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64); err == nil {
+				total += n
+				found = true
+			}
+		}
+	}
+	return total, found
+}
@@ -0,0 +1,192 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// timedThresholds are the temperatures a timed run reports the time
+// spent at or above: Hot, Very Hot and Critical of the temperature legend.
+var timedThresholds = [...]float64{75, 85, 95}
+
+// timedRun is a stress run of fixed length, for repeatable burn-in tests,
+// and the statistics of its report.
+type timedRun struct {
+	start      time.Time
+	duration   time.Duration
+	throttleAt uint64 // Throttle counter at the start
+	throttleOK bool   // Whether the machine has throttle counters
+	lastPoll   time.Time
+	polls      int
+	cpuSum     float64
+	tempSum    float64
+	tempN      int
+	maxTemp    float64
+	above      [len(timedThresholds)]time.Duration
+}
+
+// add takes one poll into the statistics. The time between two polls
+// counts as above a threshold when the later one is.
+func (r *timedRun) add(s *Sample) {
+	dt := s.Time.Sub(r.lastPoll)
+	if r.lastPoll.IsZero() {
+		dt = s.Time.Sub(r.start)
+	}
+	r.lastPoll = s.Time
+	r.polls++
+	r.cpuSum += s.CPU
+	if s.Temp <= 0 {
+		return
+	}
+	r.tempSum += s.Temp
+	r.tempN++
+	if s.Temp > r.maxTemp {
+		r.maxTemp = s.Temp
+	}
+	for i, t := range timedThresholds {
+		if s.Temp >= t {
+			r.above[i] += dt
+		}
+	}
+}
+
+// report returns the summary of the run as lines of plain text, for the
+// report page and the terminal after exit. stopped is why the run ended
+// early, or "" when it ran its course.
+func (r *timedRun) report(end time.Time, stopped string) []string {
+	outcome := tr("completed")
+	if stopped != "" {
+		outcome = fmt.Sprintf(tr("stopped after %s: %s"), end.Sub(r.start).Round(time.Second), stopped)
+	}
+	row := func(label, value string) string { return padRight(label, 21) + " " + value }
+	lines := []string{
+		fmt.Sprintf(tr("Timed stress run of %s, %s"), r.duration, outcome),
+		"",
+		row(tr("Started"), r.start.Format("2006-01-02 15:04:05")),
+	}
+	na := tr("n/a")
+	maxTemp, avgTemp, avgCPU := na, na, na
+	if r.tempN > 0 {
+		maxTemp, avgTemp = formatTemp(r.maxTemp, 1), formatTemp(r.tempSum/float64(r.tempN), 1)
+	}
+	if r.polls > 0 {
+		avgCPU = formatPercent(r.cpuSum/float64(r.polls), 1)
+	}
+	lines = append(lines,
+		row(tr("Max temperature"), maxTemp),
+		row(tr("Average temperature"), avgTemp),
+		row(tr("Average CPU usage"), avgCPU))
+	for i, t := range timedThresholds {
+		above := na
+		if r.tempN > 0 {
+			above = r.above[i].Round(time.Second).String()
+		}
+		lines = append(lines, row(fmt.Sprintf(tr("Time at ≥ %s"), formatTemp(t, 0)), above))
+	}
+	throttle := na
+	if r.throttleOK {
+		count, _ := readThrottleCount()
+		throttle = strconv.FormatUint(count-r.throttleAt, 10)
+	}
+	return append(lines, row(tr("Throttle events"), throttle))
+}
+
+// startTimedStress starts stress for d, after which checkTimedStress
+// stops it and shows the report.
+func (m *Monitor) startTimedStress(d time.Duration) {
+	m.startStress()
+	if !m.stressRunning {
+		return
+	}
+	r := &timedRun{start: timeNow(), duration: d}
+	r.throttleAt, r.throttleOK = readThrottleCount()
+	m.timed = r
+}
+
+// checkTimedStress takes a poll into the timed run, and ends the run once
+// its time is up or stress was stopped in the meantime, by hand or by the
+// safety limiter.
+func (m *Monitor) checkTimedStress(s *Sample) {
+	r := m.timed
+	switch {
+	case r == nil:
+		return
+	case !m.stressRunning && m.safetyStop != "":
+		m.endTimedStress(fmt.Sprintf(tr("safety limit, %s"), m.safetyStop))
+	case !m.stressRunning:
+		m.endTimedStress(tr("stopped by hand"))
+	default:
+		r.add(s)
+		if timeNow().Sub(r.start) >= r.duration {
+			m.stopStress()
+			m.endTimedStress("")
+		}
+	}
+}
+
+// endTimedStress ends the timed run and shows its report: on the report
+// page, or read out in accessible mode.
+func (m *Monitor) endTimedStress(stopped string) {
+	m.timedReport = m.timed.report(timeNow(), stopped)
+	m.timed = nil
+	if m.headless {
+		return
+	}
+	if m.cfg.Accessible {
+		for _, line := range m.timedReport {
+			if line != "" {
+				m.say("%s", line)
+			}
+		}
+		return
+	}
+	m.showTimedReport = true
+	fmt.Fprint(m.out, "\a"+clearScreen)
+}
+
+// timedStatus returns the status line tag of a timed run, with the time
+// it has left.
+func (m *Monitor) timedStatus() string {
+	if m.timed == nil {
+		return ""
+	}
+	left := m.timed.duration - timeNow().Sub(m.timed.start)
+	if left < 0 {
+		left = 0
+	}
+	return fmt.Sprintf("  %s[%s %s]%s", colorMagenta, tr("TIMED"), left.Round(time.Second), colorReset)
+}
+
+// handleTimedReportKey processes a key press while the report of a timed
+// run is shown. It returns false when the application should quit.
+func (m *Monitor) handleTimedReportKey(key byte) bool {
+	switch m.pageAction(key, actionTimedStress) {
+	case actionStress:
+		if m.stressRunning {
+			m.stopStress()
+		} else {
+			m.startStress()
+		}
+	case actionClose:
+		m.showTimedReport = false
+		fmt.Fprint(m.out, clearScreen)
+	case actionQuit:
+		return false
+	}
+	return true
+}
+
+// displayTimedReportPage shows the report of the last timed run.
+func (m *Monitor) displayTimedReportPage() {
+	fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor - %s ===%s\r\n\r\n", colorGreen, tr("Timed Stress Report"), colorReset)
+	for i, line := range m.timedReport {
+		if i == 0 {
+			fmt.Fprintf(m.out, "  %s%s%s\r\n", colorCyan, line, colorReset)
+		} else {
+			fmt.Fprintf(m.out, "  %s\r\n", line)
+		}
+	}
+	keys := m.cfg.KeyBindings
+	fmt.Fprintf(m.out, "\r\n%s"+tr("Press %s, ESC, or %s to return to main view")+"%s\r\n", colorYellow, keys.label(actionTimedStress), keys.label(actionQuit), colorReset)
+}
@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTimedStress checks that a timed run stops stress once its time is
+// up and reports the temperatures, the usage and the throttle events.
+func TestTimedStress(t *testing.T) {
+	savedCPU, savedTimeNow := cpuDir, timeNow
+	t.Cleanup(func() { cpuDir, timeNow = savedCPU, savedTimeNow })
+	cpuDir = t.TempDir()
+	counter := filepath.Join(cpuDir, "cpu0", "thermal_throttle", "core_throttle_count")
+	os.MkdirAll(filepath.Dir(counter), 0755)
+	ioutil.WriteFile(counter, []byte("5\n"), 0644)
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	clock := start
+	timeNow = func() time.Time { return clock }
+
+	cfg := defaultConfig()
+	cfg.Stress.Workers = 1
+	cfg.Stress.Duration = time.Minute
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	m := &Monitor{cfg: cfg, out: &out, stressAvailable: true}
+	m.startTimedStress(cfg.Stress.Duration)
+	if !m.stressRunning || m.timed == nil {
+		t.Fatal("timed run not started")
+	}
+	for i, temp := range []float64{70, 80, 90, 80, 70, 60} {
+		clock = start.Add(time.Duration(i+1) * 10 * time.Second)
+		if i == 5 {
+			ioutil.WriteFile(counter, []byte("8\n"), 0644)
+		}
+		m.checkTimedStress(&Sample{Time: clock, CPU: 90 + float64(i), Temp: temp})
+	}
+	if m.stressRunning || m.timed != nil || !m.showTimedReport {
+		t.Fatalf("stress running %v after the run, report shown %v", m.stressRunning, m.showTimedReport)
+	}
+	want := []string{
+		"Timed stress run of 1m0s, completed",
+		"",
+		"Started               2025-10-01 12:00:00",
+		"Max temperature       90.0°C",
+		"Average temperature   75.0°C",
+		"Average CPU usage     92.5%",
+		"Time at ≥ 75°C        30s",
+		"Time at ≥ 85°C        10s",
+		"Time at ≥ 95°C        0s",
+		"Throttle events       3",
+	}
+	if got := strings.Join(m.timedReport, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("report:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+
+	m.startTimedStress(cfg.Stress.Duration)
+	m.stopStress()
+	m.checkTimedStress(&Sample{Time: clock, Temp: 60})
+	if want := "Timed stress run of 1m0s, stopped after 0s: stopped by hand"; m.timedReport[0] != want {
+		t.Errorf("report of a stopped run starts %q, want %q", m.timedReport[0], want)
+	}
+}
@@ -0,0 +1,26 @@
+package monitor
+
+import "fmt"
+
+const (
+	saveTitle    = "\033[22;0t" // Push the current window/icon title onto the terminal's stack
+	restoreTitle = "\033[23;0t" // Pop it back
+)
+
+// updateTitle sets the terminal window/tab title to a compact live summary
+// such as "58% 72°C" so the status is visible when the tab isn't focused.
+// The title is only rewritten when the rounded values change.
+func (m *Monitor) updateTitle(totalUsage, temp float64) {
+	title := formatPercent(totalUsage, 0)
+	if temp > 0 {
+		title += " " + formatTemp(temp, 0)
+	}
+	if m.stressRunning {
+		title += " [STRESS]"
+	}
+	if title == m.lastTitle {
+		return
+	}
+	m.lastTitle = title
+	fmt.Fprintf(m.out, "\033]0;%s\007", title)
+}
@@ -0,0 +1,140 @@
+package monitor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	topCommandWidth = 40 // Width of the command column
+	topRowWidth     = 68 // Width of a row, so shorter rows blank out longer ones
+)
+
+// topProc is one row of the process pane.
+type topProc struct {
+	pid     int
+	command string
+	cpu     float64 // Share of one core, as top shows it (0-100% per core)
+	rss     uint64  // Resident memory in bytes
+}
+
+// topSampler ranks processes by the CPU time they used since the previous
+// poll, from the utime and stime fields of /proc/<pid>/stat.
+type topSampler struct {
+	jiffies  map[int]uint64 // Previous utime + stime by PID
+	procs    []topProc      // Busiest first
+	lastTime time.Time
+}
+
+// newTopSampler takes the first reading, so the pane has rates from the
+// next poll on.
+func newTopSampler() *topSampler {
+	t := &topSampler{}
+	t.sample()
+	return t
+}
+
+// sample refreshes the ranking. Processes that started since the
+// previous reading count with all of their CPU time.
+func (t *topSampler) sample() {
+	now := timeNow()
+	dt := now.Sub(t.lastTime).Seconds()
+	first := t.lastTime.IsZero()
+	t.lastTime = now
+
+	jiffies := map[int]uint64{}
+	t.procs = t.procs[:0]
+	entries, _ := ioutil.ReadDir(procDir)
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		data := readSysfsString(filepath.Join(procDir, e.Name(), "stat"))
+		stat := parseProcStat(data)
+		if len(stat) < 22 {
+			continue
+		}
+		utime, _ := strconv.ParseUint(stat[11], 10, 64)
+		stime, _ := strconv.ParseUint(stat[12], 10, 64)
+		jiffies[pid] = utime + stime
+		prev := t.jiffies[pid]
+		if first || dt <= 0 || utime+stime <= prev {
+			continue
+		}
+		pages, _ := strconv.ParseUint(stat[21], 10, 64)
+		t.procs = append(t.procs, topProc{
+			pid:     pid,
+			command: procCommand(filepath.Join(procDir, e.Name()), data),
+			cpu:     float64(utime+stime-prev) / clockTicks / dt * 100,
+			rss:     pages * uint64(os.Getpagesize()),
+		})
+	}
+	t.jiffies = jiffies
+	sort.Slice(t.procs, func(i, j int) bool {
+		if t.procs[i].cpu != t.procs[j].cpu {
+			return t.procs[i].cpu > t.procs[j].cpu
+		}
+		return t.procs[i].pid < t.procs[j].pid
+	})
+}
+
+// procCommand returns the command line of a process, or its name in
+// brackets for kernel threads, which have none, as ps shows them.
+func procCommand(dir, stat string) string {
+	if cmdline := readSysfsString(filepath.Join(dir, "cmdline")); cmdline != "" {
+		return strings.TrimSpace(strings.ReplaceAll(cmdline, "\x00", " "))
+	}
+	start, end := strings.IndexByte(stat, '('), strings.LastIndexByte(stat, ')')
+	if start < 0 || end < start {
+		return "?"
+	}
+	return "[" + stat[start+1:end] + "]"
+}
+
+// toggleTopProcs shows or hides the process pane. Processes are only read
+// while it is shown.
+func (m *Monitor) toggleTopProcs() {
+	if m.topProcs == nil {
+		m.topProcs = newTopSampler()
+	} else {
+		m.topProcs = nil
+	}
+	fmt.Fprint(m.out, clearScreen) // Frame height changes with the pane
+}
+
+// displayTopProcs draws the pane of the [top_processes] busiest processes
+// under the core bars, with PID, command line, CPU usage and resident
+// memory, so the process behind a spike in the graph can be read off
+// without opening htop. CPU usage is a share of one core, as in top, so a
+// process running on several cores exceeds 100%. The pane keeps its
+// height when fewer processes used CPU time.
+func (m *Monitor) displayTopProcs() {
+	t := m.topProcs
+	if t == nil {
+		return
+	}
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, fmt.Sprintf(tr("Top %d Processes by CPU:"), m.cfg.TopProcesses), colorReset)
+	fmt.Fprintf(m.out, "%s%7s  %s %7s %9s%s\r\n", colorBlue, tr("PID"), padRight(tr("Command"), topCommandWidth),
+		tr("CPU"), tr("RSS"), colorReset)
+	for i := 0; i < m.cfg.TopProcesses; i++ {
+		if i >= len(t.procs) {
+			fmt.Fprintf(m.out, "%s\r\n", padRight("", topRowWidth))
+			continue
+		}
+		p := t.procs[i]
+		command := p.command
+		if len([]rune(command)) > topCommandWidth {
+			command = string([]rune(command)[:topCommandWidth-1]) + "…"
+		}
+		fmt.Fprintf(m.out, "%7d  %s %s%7s%s %9s\r\n", p.pid, padRight(command, topCommandWidth),
+			getUsageColor(p.cpu), formatPercent(p.cpu, 1), colorReset, formatMiB(float64(p.rss)))
+	}
+	fmt.Fprint(m.out, "\r\n")
+}
@@ -0,0 +1,119 @@
+package monitor
+
+import "strconv"
+
+// unit is what a metric is measured in. Readings are kept and compared
+// against the config's thresholds in the unit's base, such as °C or bytes
+// per second; the unit formats them for the screen, the graph axes and
+// the exports, so a new collector declares its unit rather than building
+// format strings of its own.
+type unit int
+
+const (
+	unitPercent      unit = iota
+	unitCelsius           // Shown in the configured temperature unit
+	unitCelsiusDelta      // A temperature difference, converted without the offset
+	unitWatts
+	unitBytesRate // Bytes per second, shown in kB/s, MB/s or GB/s
+	unitRPM
+	unitLitersPerHour
+	unitMHz
+	unitMicroseconds
+)
+
+// unitSymbols are the symbols exports label values with.
+var unitSymbols = [...]string{"%", "°C", "°C", "W", "MB/s", "RPM", "L/h", "MHz", "µs"}
+
+// metricUnits are the units of the numeric metrics the UNIX socket and
+// the FIFO line export, by name.
+var metricUnits = map[string]unit{
+	"cpu": unitPercent, "temp": unitCelsius, "headroom": unitCelsiusDelta, "iowait": unitPercent,
+	"gpu": unitPercent, "disk": unitPercent, "disk_read": unitBytesRate, "disk_write": unitBytesRate,
+	"net": unitPercent, "mem": unitPercent, "power": unitWatts,
+}
+
+// metricValue returns a metric of metricUnits from the sample in the
+// metric's base unit, and whether the machine provides it.
+func metricValue(s *Sample, metric string) (float64, bool) {
+	switch metric {
+	case "cpu":
+		return s.CPU, true
+	case "temp":
+		return s.Temp, s.Temp > 0
+	case "headroom":
+		return s.Headroom, s.Limited
+	case "iowait":
+		return s.IOWait, true
+	case "gpu":
+		return s.GPU, s.GPU >= 0
+	case "disk":
+		return s.Disk, s.Disk >= 0
+	case "disk_read", "disk_write":
+		read, write, _ := diskTotals(s.Disks)
+		if metric == "disk_write" {
+			read = write
+		}
+		return read, s.Disks != nil
+	case "net":
+		return s.Net, s.Net >= 0
+	case "power":
+		return packagePower(s)
+	case "mem":
+		return float64(s.MemUsed) / float64(s.MemTotal) * 100, s.MemTotal > 0
+	}
+	return 0, false
+}
+
+// format formats v with prec decimals for the screen, in the active
+// locale and temperature unit, e.g. "42.5%", "61.0°C" or "18.6 W".
+// Throughput picks its unit and decimals by magnitude instead.
+func (u unit) format(v float64, prec int) string {
+	switch u {
+	case unitPercent:
+		return formatPercent(v, prec)
+	case unitCelsius:
+		return formatTemp(v, prec)
+	case unitCelsiusDelta:
+		return formatTempDelta(v, prec)
+	case unitBytesRate:
+		// kB/s, MB/s or GB/s by magnitude, e.g. "12.3 MB/s"
+		switch {
+		case v >= 1e9:
+			return formatNumber(v/1e9, 2) + " GB/s"
+		case v >= 1e6:
+			return formatNumber(v/1e6, 1) + " MB/s"
+		}
+		return formatNumber(v/1e3, 0) + " kB/s"
+	}
+	return formatNumber(v, prec) + " " + unitSymbols[u]
+}
+
+// axis formats v as a graph axis label, with the decimals axisPrecision
+// gives. Clocks are labeled in GHz to fit narrow axes.
+func (u unit) axis(v float64) string {
+	if u == unitMHz {
+		return formatNumber(v/1000, 1) + "GHz"
+	}
+	return u.format(v, axisPrecision(v))
+}
+
+// symbol returns the symbol exports label values of the unit with.
+// Exports are in °C and MB/s whatever the display settings, so their
+// consumers need no configuration.
+func (u unit) symbol() string {
+	return unitSymbols[u]
+}
+
+// exported converts v to the unit of symbol.
+func (u unit) exported(v float64) float64 {
+	if u == unitBytesRate {
+		return v / 1e6
+	}
+	return v
+}
+
+// export formats v in the unit of symbol with prec decimals, without the
+// locale's decimal separator, for scripts to parse.
+func (u unit) export(v float64, prec int) string {
+	return strconv.FormatFloat(u.exported(v), 'f', prec, 64)
+}
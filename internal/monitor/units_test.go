@@ -0,0 +1,44 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestUnits checks that a unit formats readings for the screen in the
+// active locale and temperature unit, while the exports stay in °C and
+// MB/s with a decimal point. The cooling alert follows the same split.
+func TestUnits(t *testing.T) {
+	defer func(l *locale) { activeLocale = l }(activeLocale)
+	activeLocale = newLocale("de_DE.UTF-8", "de_DE.UTF-8", "F")
+
+	for _, c := range []struct {
+		got, want string
+	}{
+		{unitPercent.format(42.5, 1), "42,5 %"},
+		{unitCelsius.format(60, 1), "140,0 °F"},
+		{unitCelsiusDelta.format(10, 0), "18 °F"},
+		{unitWatts.format(18.62, 1), "18,6 W"},
+		{unitBytesRate.format(2.5e6, 0), "2,5 MB/s"},
+		{unitMHz.axis(4300), "4,3GHz"},
+		{unitWatts.axis(2.5), "2,5 W"},
+		{unitWatts.axis(150), "150 W"},
+		{metricUnits["temp"].export(60, 1), "60.0"},
+		{metricUnits["disk_read"].export(2.5e6, 1), "2.5"},
+		{metricUnits["disk_read"].symbol(), "MB/s"},
+	} {
+		if c.got != c.want {
+			t.Errorf("got %q, want %q", c.got, c.want)
+		}
+	}
+
+	cfg := defaultConfig()
+	cfg.Cooling.MaxCoolantTemp = 45
+	readings := []CoolingReading{{Sensor: "d5next/Coolant", Kind: coolingCoolant, Value: 50}}
+	if got := cfg.coolingFailure(readings, true); !strings.HasSuffix(got, " 122,0 °F") {
+		t.Errorf("coolant alert on screen = %q, want it in °F", got)
+	}
+	if got, want := cfg.coolingFailure(readings, false), "Coolant too hot: d5next/Coolant at 50.0 °C"; got != want {
+		t.Errorf("exported coolant alert = %q, want %q", got, want)
+	}
+}
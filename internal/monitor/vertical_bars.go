@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// coreView selects how per-core usage is drawn in the main view.
+type coreView int
+
+const (
+	coreViewGrid     coreView = iota // One bar character per core, arranged in a grid
+	coreViewVertical                 // Tall multi-row columns per core, htop style
+	coreViewHeatmap                  // One cell per core grouped by shared cache, for high core counts
+	coreViewCount
+)
+
+// parseCoreView converts a config value into a coreView.
+func parseCoreView(name string) (coreView, bool) {
+	switch name {
+	case "", "grid":
+		return coreViewGrid, true
+	case "vertical":
+		return coreViewVertical, true
+	case "heatmap":
+		return coreViewHeatmap, true
+	}
+	return coreViewGrid, false
+}
+
+// displayVerticalCores renders each core as a vertical column several rows
+// tall, using eighth-block characters for the partially filled top cell.
+// Columns are two cells wide with core numbers and percentages underneath
+// when they fit the graph width; otherwise they collapse to one cell.
+func (m *Monitor) displayVerticalCores(coreUsages []float64, currentTemp float64) {
+	height := m.cfg.VerticalBarHeight
+	wide := m.cores*3 <= baseGraphWidth+6
+
+	colors := make([]string, m.cores)
+	for i, temp := range m.coreTemperatures(coreUsages, currentTemp) {
+		colors[i] = getTempColor(temp)
+	}
+
+	heights := m.coreBarHeights(coreUsages)
+	for row := height - 1; row >= 0; row-- {
+		fmt.Fprint(m.out, "  ")
+		for i, usage := range heights {
+			// Eighths of this cell covered by the bar
+			level := int(usage/100*float64(height*8)) - row*8
+			if level < 0 {
+				level = 0
+			}
+			if level > 8 {
+				level = 8
+			}
+			if row == 0 && level == 0 {
+				level = 1 // Always show at least ▁ so idle cores stay visible
+			}
+
+			cell := barChars[level]
+			if wide {
+				cell = strings.Repeat(cell, 2) + " "
+			}
+			fmt.Fprintf(m.out, "%s%s%s", colors[i], cell, colorReset)
+		}
+		fmt.Fprint(m.out, "\r\n")
+	}
+
+	if !wide {
+		return
+	}
+
+	// Core numbers and usage percentages under the columns; with the
+	// frequency bars on, multipliers, as a clock in MHz does not fit
+	fmt.Fprint(m.out, "  ")
+	for i := range coreUsages {
+		fmt.Fprintf(m.out, "%-3d", i%100)
+	}
+	fmt.Fprint(m.out, "\r\n  ")
+	for i, usage := range coreUsages {
+		if m.freqBars {
+			usage = m.clocks.clockKHz(i) / 1000 / busClockMHz
+		}
+		// Rounded before clamping, so the label is at most two characters
+		// and never joins the next column: not "100" or "-0"
+		fmt.Fprintf(m.out, "%-3.0f", math.Max(math.Min(math.Round(usage), 99), 0))
+	}
+	fmt.Fprint(m.out, "\r\n")
+	if m.freqBars {
+		fmt.Fprintf(m.out, "  %s\r\n", fmt.Sprintf(tr("Multipliers (x%.0f MHz)"), busClockMHz))
+	}
+}
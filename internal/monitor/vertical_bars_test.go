@@ -0,0 +1,21 @@
+package monitor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestVerticalCoreLabels checks that usage under the columns rounds and
+// clamps to two digits, so no label runs into the next column.
+func TestVerticalCoreLabels(t *testing.T) {
+	m, _, temp := fixtureMonitor(t, "4cores", func(cfg *Config) { cfg.CoreViewName = "vertical" }, nil)
+	var out bytes.Buffer
+	m.out = &out
+	m.displayVerticalCores([]float64{99.6, 49.5, -0.4, 100}, temp)
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\r\n"), "\r\n")
+	if got, want := lines[len(lines)-1], "  99 50 0  99 "; got != want {
+		t.Errorf("usage labels = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	wakeupPageRows = 16 // Rows of cores per page, in two columns
+	wakeupTopProcs = 10 // Processes listed below the cores
+)
+
+// procWakeups is the wakeup rate of one process.
+type procWakeups struct {
+	pid  int
+	name string
+	rate float64 // Times its threads were scheduled in per second
+}
+
+// wakeupSampler turns the interrupt counters of /proc/interrupts into
+// per-core rates, and the per-thread run counts of schedstat into
+// per-process wakeup rates. A core that takes a timer interrupt or an
+// IPI leaves its idle state, so these are what keep it out of deep
+// C-states.
+type wakeupSampler struct {
+	available bool
+	timer     []float64 // Local timer interrupts per second by CPU
+	resched   []float64 // Rescheduling IPIs per second by CPU
+	all       []float64 // All interrupts per second by CPU
+	last      [3][]uint64
+	procs     map[int]uint64 // Previous summed run counts by PID
+	top       []procWakeups  // Busiest processes, most wakeups first
+	lastTime  time.Time
+}
+
+// newWakeupSampler takes the first reading for the given number of CPUs.
+func newWakeupSampler(cores int) *wakeupSampler {
+	w := &wakeupSampler{
+		timer:   make([]float64, cores),
+		resched: make([]float64, cores),
+		all:     make([]float64, cores),
+		procs:   map[int]uint64{},
+	}
+	for i := range w.last {
+		w.last[i] = make([]uint64, cores)
+	}
+	w.sample()
+	return w
+}
+
+// readInterrupts parses /proc/interrupts into per-CPU local timer,
+// rescheduling IPI and total counts. The header names the CPU of each
+// column, as offline CPUs have none.
+func readInterrupts(cores int) (counts [3][]uint64, ok bool) {
+	f, err := os.Open(filepath.Join(procDir, "interrupts"))
+	if err != nil {
+		return counts, false
+	}
+	defer f.Close()
+	for i := range counts {
+		counts[i] = make([]uint64, cores)
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // Lines grow with the CPU count
+	if !scanner.Scan() {
+		return counts, false
+	}
+	var columns []int
+	for _, field := range strings.Fields(scanner.Text()) {
+		cpu, _ := strconv.Atoi(strings.TrimPrefix(field, "CPU"))
+		columns = append(columns, cpu)
+	}
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		name := strings.TrimSuffix(fields[0], ":")
+		for i, field := range fields[1:] {
+			if i >= len(columns) {
+				break
+			}
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				break // ERR and MIS have one column; text follows the counts
+			}
+			cpu := columns[i]
+			if cpu >= cores {
+				continue
+			}
+			counts[2][cpu] += v
+			switch name {
+			case "LOC":
+				counts[0][cpu] += v
+			case "RES":
+				counts[1][cpu] += v
+			}
+		}
+	}
+	return counts, true
+}
+
+// sample refreshes the per-core and per-process rates.
+func (w *wakeupSampler) sample() {
+	now := timeNow()
+	dt := now.Sub(w.lastTime).Seconds()
+	first := w.lastTime.IsZero()
+	w.lastTime = now
+
+	counts, ok := readInterrupts(len(w.timer))
+	w.available = ok
+	if ok {
+		rates := [3][]float64{w.timer, w.resched, w.all}
+		for k := range counts {
+			for cpu, v := range counts[k] {
+				rates[k][cpu] = 0
+				if !first && dt > 0 && v >= w.last[k][cpu] {
+					rates[k][cpu] = float64(v-w.last[k][cpu]) / dt
+				}
+			}
+			w.last[k] = counts[k]
+		}
+	}
+
+	// Processes: each time a thread is scheduled in after sleeping is a wakeup
+	procs := map[int]uint64{}
+	w.top = w.top[:0]
+	entries, _ := ioutil.ReadDir(procDir)
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		runs := processRunCount(filepath.Join(procDir, e.Name()))
+		procs[pid] = runs
+		if prev, seen := w.procs[pid]; seen && !first && dt > 0 && runs > prev {
+			w.top = append(w.top, procWakeups{
+				pid:  pid,
+				name: readSysfsString(filepath.Join(procDir, e.Name(), "comm")),
+				rate: float64(runs-prev) / dt,
+			})
+		}
+	}
+	w.procs = procs
+	sort.Slice(w.top, func(i, j int) bool { return w.top[i].rate > w.top[j].rate })
+	if len(w.top) > wakeupTopProcs {
+		w.top = w.top[:wakeupTopProcs]
+	}
+}
+
+// processRunCount sums how often the threads of a process were scheduled
+// in, the third field of each thread's schedstat.
+func processRunCount(dir string) uint64 {
+	stats, _ := filepath.Glob(filepath.Join(dir, "task", "*", "schedstat"))
+	var total uint64
+	for _, path := range stats {
+		fields := strings.Fields(readSysfsString(path))
+		if len(fields) >= 3 {
+			runs, _ := strconv.ParseUint(fields[2], 10, 64)
+			total += runs
+		}
+	}
+	return total
+}
+
+// openWakeupsPage starts sampling wakeups and shows the page.
+func (m *Monitor) openWakeupsPage() {
+	m.wakeups = newWakeupSampler(m.cores)
+	m.showWakeups = true
+	fmt.Fprint(m.out, clearScreen)
+}
+
+// handleWakeupsKey processes a key press while the wakeups page is shown.
+// It returns false when the application should quit.
+func (m *Monitor) handleWakeupsKey(key byte) bool {
+	switch m.pageAction(key, actionWakeups) {
+	case actionStress:
+		if m.stressRunning {
+			m.stopStress()
+		} else {
+			m.startStress()
+		}
+		return true
+	case actionClose:
+		m.showWakeups = false
+		m.wakeups = nil
+		fmt.Fprint(m.out, clearScreen)
+		return true
+	case actionQuit:
+		return false
+	}
+	switch key {
+	case '[':
+		m.wakeupsPage-- // Clamped when drawn
+		fmt.Fprint(m.out, clearScreen)
+	case ']':
+		m.wakeupsPage++
+		fmt.Fprint(m.out, clearScreen)
+	}
+	return true
+}
+
+// displayWakeupsPage draws per-core timer interrupts, rescheduling IPIs
+// and all interrupts per second, in two columns, followed by the
+// processes waking up most often.
+func (m *Monitor) displayWakeupsPage() {
+	fmt.Fprintf(m.out, "%s=== Kode Kronical Perf Monitor - %s ===%s\r\n\r\n", colorGreen, tr("Wakeups"), colorReset)
+	w := m.wakeups
+	if !w.available {
+		fmt.Fprintf(m.out, "  %s\r\n\r\n", tr("Interrupt counters are not available (cannot read /proc/interrupts)."))
+		fmt.Fprintf(m.out, "%s%s%s\r\n", colorYellow, tr("I/ESC: close"), colorReset)
+		return
+	}
+
+	var timer, resched, all float64
+	for cpu := range w.all {
+		timer, resched, all = timer+w.timer[cpu], resched+w.resched[cpu], all+w.all[cpu]
+	}
+	fmt.Fprintf(m.out, "%s %s%.0f/s%s   %s %.0f/s   %s %.0f/s\r\n\r\n", tr("All cores:"),
+		colorYellow, all, colorReset, tr("Timer:"), timer, tr("Rescheduling:"), resched)
+
+	perPage := 2 * wakeupPageRows
+	pages := (m.cores + perPage - 1) / perPage
+	if m.wakeupsPage >= pages {
+		m.wakeupsPage = pages - 1
+	}
+	if m.wakeupsPage < 0 {
+		m.wakeupsPage = 0
+	}
+	first := m.wakeupsPage * perPage
+	onPage := m.cores - first
+	if onPage > perPage {
+		onPage = perPage
+	}
+	rows := (onPage + 1) / 2
+	header := fmt.Sprintf("%-5s %9s %9s %9s", tr("CPU"), tr("Timer/s"), tr("Resched/s"), tr("All/s"))
+	if onPage > 1 {
+		header += "   " + header
+	}
+	fmt.Fprintf(m.out, "%s%s%s\r\n", colorCyan, header, colorReset)
+	for row := 0; row < rows; row++ {
+		var cells []string
+		for col := 0; col < 2; col++ {
+			cpu := first + col*rows + row
+			if cpu >= first+onPage {
+				break
+			}
+			// Timer rates are colored up to 1000/s, the highest tick rate
+			cells = append(cells, fmt.Sprintf("%-5d %s%9.0f%s %9.0f %9.0f", cpu,
+				getUsageColor(w.timer[cpu]/10), w.timer[cpu], colorReset, w.resched[cpu], w.all[cpu]))
+		}
+		fmt.Fprintf(m.out, "%s\r\n", strings.Join(cells, "   "))
+	}
+	if pages > 1 {
+		fmt.Fprintf(m.out, "%s"+tr("Page %d/%d")+"  %s%s\r\n", colorYellow, m.wakeupsPage+1, pages, tr("[ ]: page"), colorReset)
+	}
+
+	fmt.Fprintf(m.out, "\r\n%s%-8s %-16s %10s%s\r\n", colorCyan, tr("PID"), tr("Process"), tr("Wakeups/s"), colorReset)
+	for _, p := range w.top {
+		fmt.Fprintf(m.out, "%-8d %-16s %10.0f\r\n", p.pid, p.name, p.rate)
+	}
+
+	fmt.Fprintf(m.out, "\r\n%s%s%s\r\n", colorYellow, tr("SPACE: stress  I/ESC: close"), colorReset)
+}
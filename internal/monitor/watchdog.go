@@ -0,0 +1,247 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// trackedChild is a load generator started by a monitor: the stress
+// command, iperf3 or fio.
+type trackedChild struct {
+	pid   int
+	start string // Start time from /proc/<pid>/stat, so a reused PID does not match
+	name  string // Command name, which the workers it forks share
+}
+
+// childTracker keeps a pidfile of the load generators this process has
+// running, so a later session can find the ones a crash left behind. The
+// first line of the file is the monitor itself. Each monitor has a file of
+// its own, named by its PID, in the children directory of the state
+// directory.
+type childTracker struct {
+	mu       sync.Mutex
+	path     string
+	children map[int]trackedChild
+}
+
+// children tracks the load generators of this process.
+var children = &childTracker{children: map[int]trackedChild{}}
+
+// childDir returns the directory of the pidfiles.
+func childDir() string {
+	dir := stateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "children")
+}
+
+// procStart returns the start time of a process, or "" when it is gone.
+func procStart(pid int) string {
+	data, err := ioutil.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return ""
+	}
+	if fields := parseProcStat(string(data)); len(fields) > 19 {
+		return fields[19]
+	}
+	return ""
+}
+
+// add records a started command.
+func (c *childTracker) add(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pid := cmd.Process.Pid
+	start := procStart(pid)
+	if start == "" {
+		return // Exited already, or no procfs to track it with
+	}
+	c.children[pid] = trackedChild{pid: pid, start: start, name: filepath.Base(cmd.Path)}
+	c.save()
+}
+
+// remove forgets a command that has exited or been killed.
+func (c *childTracker) remove(cmd *exec.Cmd) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.children[cmd.Process.Pid]; ok {
+		delete(c.children, cmd.Process.Pid)
+		c.save()
+	}
+}
+
+// save rewrites the pidfile, or removes it when no child is left.
+func (c *childTracker) save() {
+	if c.path == "" {
+		dir := childDir()
+		if dir == "" || os.MkdirAll(dir, 0755) != nil {
+			return
+		}
+		c.path = filepath.Join(dir, strconv.Itoa(os.Getpid())+".pids")
+	}
+	if len(c.children) == 0 {
+		os.Remove(c.path)
+		return
+	}
+	pids := make([]int, 0, len(c.children))
+	for pid := range c.children {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s kkperf\n", os.Getpid(), procStart(os.Getpid()))
+	for _, pid := range pids {
+		ch := c.children[pid]
+		fmt.Fprintf(&b, "%d %s %s\n", ch.pid, ch.start, ch.name)
+	}
+	if err := ioutil.WriteFile(c.path, []byte(b.String()), 0644); err != nil {
+		logWarn("pidfile not written", "path", c.path, "err", err)
+	}
+}
+
+// close removes the pidfile on a clean exit.
+func (c *childTracker) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.children = map[int]trackedChild{}
+	if c.path != "" {
+		os.Remove(c.path)
+	}
+}
+
+// readPidfile parses a pidfile into the monitor and its children.
+func readPidfile(path string) (owner trackedChild, tracked []trackedChild, ok bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return owner, nil, false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		ch := trackedChild{pid: pid, start: fields[1], name: fields[2]}
+		if !ok {
+			owner, ok = ch, true
+		} else {
+			tracked = append(tracked, ch)
+		}
+	}
+	return owner, tracked, ok
+}
+
+// orphanedChildren finds the load generators left running by monitors
+// that exited without stopping them, and the pidfiles of those monitors.
+// A tracked process matches by PID and start time; the workers it forked,
+// which outlive it when it is killed alone, match by its process group
+// and its name.
+func orphanedChildren() (orphans []trackedChild, pidfiles []string) {
+	if procStart(os.Getpid()) == "" {
+		return nil, nil // No procfs to tell the living from the dead
+	}
+	files, _ := filepath.Glob(filepath.Join(childDir(), "*.pids"))
+	var tracked []trackedChild
+	for _, path := range files {
+		owner, list, ok := readPidfile(path)
+		if ok && owner.start != "" && procStart(owner.pid) == owner.start {
+			continue // That monitor is still running
+		}
+		tracked = append(tracked, list...)
+		pidfiles = append(pidfiles, path)
+	}
+	if len(tracked) == 0 {
+		return nil, pidfiles
+	}
+
+	dirs, _ := filepath.Glob(filepath.Join(procDir, "[0-9]*"))
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(filepath.Base(dir))
+		if err != nil {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, "stat"))
+		if err != nil {
+			continue
+		}
+		fields := parseProcStat(string(data))
+		if len(fields) <= 19 {
+			continue
+		}
+		name := ""
+		if i, j := strings.IndexByte(string(data), '('), strings.LastIndexByte(string(data), ')'); i >= 0 && j > i {
+			name = string(data[i+1 : j])
+		}
+		pgrp, _ := strconv.Atoi(fields[2])
+		for _, ch := range tracked {
+			if (pid == ch.pid && fields[19] == ch.start) || (pgrp == ch.pid && name == ch.name) {
+				orphans = append(orphans, trackedChild{pid: pid, start: fields[19], name: name})
+				break
+			}
+		}
+	}
+	return orphans, pidfiles
+}
+
+// describeOrphans lists orphaned processes as "stress (1234), ...".
+func describeOrphans(orphans []trackedChild) string {
+	parts := make([]string, len(orphans))
+	for i, o := range orphans {
+		parts[i] = fmt.Sprintf("%s (%d)", o.name, o.pid)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// killOrphans kills the given processes.
+func killOrphans(orphans []trackedChild) {
+	for _, o := range orphans {
+		if p, err := os.FindProcess(o.pid); err == nil {
+			p.Kill()
+		}
+	}
+}
+
+// checkOrphans looks for load generators a crashed session left running
+// and asks on in whether to kill them; headless, it only warns, as no one
+// is there to answer. Pidfiles of sessions with nothing left running, or
+// whose processes were killed, are removed.
+func checkOrphans(in io.Reader, out io.Writer, ask bool) {
+	orphans, pidfiles := orphanedChildren()
+	if len(orphans) > 0 {
+		logWarn("orphaned load generators found", "processes", describeOrphans(orphans))
+		if !ask {
+			fmt.Fprintf(out, "Warning: load generators left by a crashed session are still running: %s\n", describeOrphans(orphans))
+			return
+		}
+		fmt.Fprintf(out, "Load generators left by a crashed session are still running: %s\nKill them? [Y/n] ", describeOrphans(orphans))
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a == "n" || a == "no" {
+			return
+		}
+		killOrphans(orphans)
+	}
+	for _, path := range pidfiles {
+		os.Remove(path)
+	}
+}
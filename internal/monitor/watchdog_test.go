@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestOrphans checks that the stress workers of a crashed session are
+// found by the process group of the command it tracked, while the
+// children of a monitor still running are left alone.
+func TestOrphans(t *testing.T) {
+	dir := t.TempDir()
+	savedProc := procDir
+	t.Cleanup(func() { procDir = savedProc })
+	procDir = filepath.Join(dir, "proc")
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	proc := func(pid, pgrp int, name, start string) {
+		os.MkdirAll(filepath.Join(procDir, strconv.Itoa(pid)), 0755)
+		stat := fmt.Sprintf("%d (%s) S 1 %d%s %s 0 0\n", pid, name, pgrp, strings.Repeat(" 0", 16), start)
+		ioutil.WriteFile(filepath.Join(procDir, strconv.Itoa(pid), "stat"), []byte(stat), 0644)
+	}
+	proc(os.Getpid(), os.Getpid(), "kkperf", "100")
+	proc(4002, 4001, "stress", "510") // Worker of a killed stress command
+	proc(4003, 4003, "bash", "520")
+	proc(4100, 4100, "kkperf", "700") // A monitor still running
+	proc(4101, 4101, "stress", "710")
+	os.MkdirAll(childDir(), 0755)
+	ioutil.WriteFile(filepath.Join(childDir(), "4000.pids"), []byte("4000 400 kkperf\n4001 500 stress\n"), 0644)
+	ioutil.WriteFile(filepath.Join(childDir(), "4100.pids"), []byte("4100 700 kkperf\n4101 710 stress\n"), 0644)
+
+	var out bytes.Buffer
+	checkOrphans(strings.NewReader("n\n"), &out, true)
+	if !strings.Contains(out.String(), "still running: stress (4002)\n") {
+		t.Errorf("prompt = %q, want the worker 4002 only", out.String())
+	}
+	orphans, pidfiles := orphanedChildren()
+	if len(orphans) != 1 || len(pidfiles) != 1 || filepath.Base(pidfiles[0]) != "4000.pids" {
+		t.Errorf("after declining: orphans %v, pidfiles %v; want the crashed session kept", orphans, pidfiles)
+	}
+}
@@ -0,0 +1,235 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// webHistory is how many polls a dashboard opened later starts with, five
+// minutes at the default poll interval.
+const webHistory = 600
+
+// webPoint is one poll as the dashboard plots it.
+type webPoint struct {
+	Time   int64             `json:"t"` // Unix milliseconds
+	CPU    float64           `json:"cpu"`
+	Cores  []float64         `json:"cores"`
+	Temp   float64           `json:"temp"`            // °C, 0 when unavailable
+	Power  float64           `json:"power,omitempty"` // Package power in W
+	Stress bool              `json:"stress"`
+	Alerts map[string]string `json:"alerts,omitempty"`
+}
+
+// webSink serves a dashboard with live charts for a phone or another
+// machine's browser: the page at /, the recent polls at /history and a
+// Server-Sent Events stream of every new poll at /events. The page is
+// self-contained, so it works without internet access.
+type webSink struct {
+	server *http.Server
+	host   string
+
+	mu          sync.Mutex
+	history     []webPoint
+	subscribers map[chan []byte]bool
+}
+
+// newWebSink starts serving the dashboard on addr, guarded by token as the
+// HTTP endpoint is.
+func newWebSink(addr, token string) (*webSink, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("web: %v", err)
+	}
+	w := &webSink{subscribers: map[chan []byte]bool{}}
+	w.host, _ = os.Hostname()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", w.serveDashboard)
+	mux.HandleFunc("/history", w.serveHistory)
+	mux.HandleFunc("/events", w.serveEvents)
+
+	w.server = &http.Server{Handler: requireToken(token, mux), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := w.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "web: %v\n", err)
+		}
+	}()
+	return w, nil
+}
+
+// write adds the sample to the history and sends it to every open
+// dashboard. A dashboard too far behind, on a stalled connection, is
+// dropped; the browser reconnects by itself.
+func (w *webSink) write(s *Sample) error {
+	p := webPoint{Time: s.Time.UnixNano() / int64(time.Millisecond), CPU: s.CPU, Cores: s.Cores, Temp: s.Temp, Stress: s.Stress}
+	p.Power, _ = packagePower(s)
+	if alerts := sampleAlerts(s); len(alerts) > 0 {
+		p.Alerts = alerts
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("web: %v", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.history = append(w.history, p)
+	if len(w.history) > webHistory {
+		w.history = w.history[len(w.history)-webHistory:]
+	}
+	for events := range w.subscribers {
+		select {
+		case events <- data:
+		default:
+			close(events)
+			delete(w.subscribers, events)
+		}
+	}
+	return nil
+}
+
+// close stops the server and ends the event streams.
+func (w *webSink) close() error {
+	w.mu.Lock()
+	for events := range w.subscribers {
+		close(events)
+		delete(w.subscribers, events)
+	}
+	w.mu.Unlock()
+	return w.server.Close()
+}
+
+// serveDashboard writes the dashboard page.
+func (w *webSink) serveDashboard(rw http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(rw, r)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	webTemplate.Execute(rw, struct{ Host, Token string }{w.host, r.URL.Query().Get("token")})
+}
+
+// serveHistory writes the recent polls as a JSON array.
+func (w *webSink) serveHistory(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	history := append([]webPoint{}, w.history...)
+	w.mu.Unlock()
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(history)
+}
+
+// serveEvents streams every new poll as a Server-Sent Event until the
+// browser goes away or the monitor exits.
+func (w *webSink) serveEvents(rw http.ResponseWriter, r *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	events := make(chan []byte, socketQueue)
+	w.mu.Lock()
+	w.subscribers[events] = true
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		if w.subscribers[events] {
+			delete(w.subscribers, events)
+		}
+		w.mu.Unlock()
+	}()
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case data, ok := <-events:
+			if !ok {
+				return
+			}
+			if _, err := fmt.Fprintf(rw, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// webTemplate is the dashboard: CPU usage, temperature and package power
+// charts over the last minutes, the per-core usage, and the alerts. The
+// charts are drawn on canvases by a few lines of script, with no library
+// to fetch.
+var webTemplate = template.Must(template.New("web").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">
+<title>kkperf: {{.Host}}</title>
+<style>
+body { font-family: sans-serif; margin: 0; padding: 1em; background: #111; color: #ddd }
+h1 { font-size: 1.2em; margin: 0 0 0.5em } .stats { display: flex; flex-wrap: wrap; gap: 1em; margin-bottom: 1em }
+.stat b { display: block; font-size: 1.6em; color: #fff } .stat span { font-size: 0.8em; color: #999 }
+.stress { color: #f55 } .alert { color: #f55; font-weight: bold } canvas { width: 100%; height: 140px; display: block; margin-bottom: 0.8em; background: #1a1a1a }
+#cores { display: grid; grid-template-columns: repeat(auto-fill, minmax(3em, 1fr)); gap: 3px }
+#cores div { height: 2.2em; background: #222; position: relative; font-size: 0.7em; text-align: center }
+#cores i { position: absolute; left: 0; bottom: 0; width: 100%; background: #3a7 } #cores em { position: relative; font-style: normal }
+</style></head><body>
+<h1>kkperf: {{.Host}} <span id="state"></span></h1>
+<div class="stats">
+<div class="stat"><span>CPU</span><b id="cpu">-</b></div>
+<div class="stat"><span>Temperature</span><b id="temp">-</b></div>
+<div class="stat"><span>Package power</span><b id="power">-</b></div>
+</div>
+<div id="alerts"></div>
+<canvas id="cpuChart"></canvas><canvas id="tempChart"></canvas><canvas id="powerChart"></canvas>
+<div id="cores"></div>
+<script>
+const token = {{.Token}}, query = token ? "?token=" + encodeURIComponent(token) : "";
+const limit = 600, points = [];
+const charts = [
+  {id: "cpuChart", key: "cpu", label: "CPU %", color: "#3a7", max: () => 100},
+  {id: "tempChart", key: "temp", label: "°C", color: "#e83", max: () => Math.max(100, ...points.map(p => p.temp))},
+  {id: "powerChart", key: "power", label: "W", color: "#59e", max: () => Math.max(1, ...points.map(p => p.power || 0)) * 1.1},
+];
+function draw(c) {
+  const canvas = document.getElementById(c.id), ratio = window.devicePixelRatio || 1;
+  canvas.width = canvas.clientWidth * ratio; canvas.height = canvas.clientHeight * ratio;
+  const g = canvas.getContext("2d"), w = canvas.width, h = canvas.height, max = c.max();
+  g.strokeStyle = c.color; g.lineWidth = 2 * ratio; g.beginPath();
+  points.forEach((p, i) => {
+    const x = w - (points.length - 1 - i) * w / (limit - 1), y = h - (p[c.key] || 0) / max * h;
+    i ? g.lineTo(x, y) : g.moveTo(x, y);
+  });
+  g.stroke();
+  g.fillStyle = "#999"; g.font = 12 * ratio + "px sans-serif"; g.fillText(c.label + " (max " + max.toFixed(0) + ")", 6 * ratio, 16 * ratio);
+}
+function show(p) {
+  document.getElementById("cpu").textContent = p.cpu.toFixed(1) + "%";
+  document.getElementById("temp").textContent = p.temp ? p.temp.toFixed(1) + "°C" : "n/a";
+  document.getElementById("power").textContent = p.power ? p.power.toFixed(1) + " W" : "n/a";
+  document.getElementById("state").innerHTML = p.stress ? '<span class="stress">[STRESS ON]</span>' : "";
+  const alerts = document.getElementById("alerts");
+  alerts.innerHTML = "";
+  Object.entries(p.alerts || {}).forEach(([name, text]) => {
+    const div = document.createElement("div"); div.className = "alert"; div.textContent = name + ": " + text; alerts.appendChild(div);
+  });
+  const cores = document.getElementById("cores");
+  while (cores.children.length < p.cores.length) cores.appendChild(document.createElement("div"));
+  p.cores.forEach((u, i) => { cores.children[i].innerHTML = '<i style="height:' + u + '%"></i><em>' + i + '<br>' + u.toFixed(0) + '%</em>'; });
+  charts.forEach(draw);
+}
+function add(p) { points.push(p); if (points.length > limit) points.shift(); show(p); }
+fetch("/history" + query).then(r => r.json()).then(history => {
+  history.forEach(p => points.push(p));
+  if (points.length) show(points[points.length - 1]);
+  new EventSource("/events" + query).onmessage = e => add(JSON.parse(e.data));
+});
+window.addEventListener("resize", () => charts.forEach(draw));
+</script>
+</body></html>
+`))
@@ -0,0 +1,45 @@
+package monitor
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestWebSink checks the dashboard's history and event stream.
+func TestWebSink(t *testing.T) {
+	w := &webSink{subscribers: map[chan []byte]bool{}, host: "bench"}
+	w.write(&Sample{Time: time.Unix(1, 0), CPU: 20, Cores: []float64{20}, Temp: 50})
+	srv := httptest.NewServer(http.HandlerFunc(w.serveEvents))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	for registered := false; !registered; time.Sleep(time.Millisecond) {
+		// Until the stream is registered
+		w.mu.Lock()
+		registered = len(w.subscribers) > 0
+		w.mu.Unlock()
+	}
+	w.write(&Sample{Time: time.Unix(2, 0), CPU: 95, Cores: []float64{95}, Temp: 88, Stress: true,
+		Power: []DomainPower{{Domain: "package-0", Watts: 120}}})
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `data: {"t":2000,"cpu":95,"cores":[95],"temp":88,"power":120,"stress":true}` + "\n"; line != want {
+		t.Errorf("event = %q, want %q", line, want)
+	}
+
+	rec := httptest.NewRecorder()
+	w.serveHistory(rec, httptest.NewRequest("GET", "/history", nil))
+	var history []webPoint
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil || len(history) != 2 || history[0].CPU != 20 {
+		t.Errorf("history = %s (%v), want both polls", rec.Body.String(), err)
+	}
+}
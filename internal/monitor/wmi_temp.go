@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	wmiTempInterval = 2 * time.Second // How often the temperature is queried
+	wmiTempTimeout  = 5 * time.Second // Bound on one PowerShell query
+)
+
+// PowerShell queries for the temperature sources, one "id=name=value" or
+// "id=value" line per sensor. String interpolation formats numbers in the
+// invariant culture, whatever the system locale.
+const (
+	lhmQuery = `foreach ($ns in 'root/LibreHardwareMonitor', 'root/OpenHardwareMonitor') {
+  Get-CimInstance -Namespace $ns -ClassName Sensor -Filter "SensorType='Temperature'" -ErrorAction SilentlyContinue |
+    ForEach-Object { "$($_.Identifier)=$($_.Name)=$($_.Value)" } }`
+	acpiQuery = `Get-CimInstance -Namespace root/WMI -ClassName MSAcpi_ThermalZoneTemperature -ErrorAction SilentlyContinue |
+  ForEach-Object { "$($_.InstanceName)=$($_.CurrentTemperature)" }`
+)
+
+// wmiTemps follows the CPU temperature of a Windows machine in the
+// background, as a CIM query through PowerShell takes longer than a poll.
+// Windows runs "powershell"; WSL runs the host's "powershell.exe" through
+// its interop.
+type wmiTemps struct {
+	shell string
+
+	once sync.Once
+	mu   sync.Mutex
+	temp float64 // °C, 0 when no source answered
+	id   string
+}
+
+// read starts the background queries on first use and returns the latest
+// answer. The temperature is 0 until the first query answers or if
+// neither source is available.
+func (w *wmiTemps) read() (float64, string) {
+	w.once.Do(func() {
+		go func() {
+			for {
+				temp, id := queryWMITemperature(w.shell)
+				w.mu.Lock()
+				w.temp, w.id = temp, id
+				w.mu.Unlock()
+				time.Sleep(wmiTempInterval)
+			}
+		}()
+	})
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.temp, w.id
+}
+
+// queryWMITemperature asks the hardware monitor bridge, then the ACPI
+// thermal zones, through shell.
+func queryWMITemperature(shell string) (float64, string) {
+	run := func(script string) string {
+		ctx, cancel := context.WithTimeout(context.Background(), wmiTempTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, shell, "-NoProfile", "-NonInteractive", "-Command", script).Output()
+		if err != nil {
+			return ""
+		}
+		return string(out)
+	}
+	if temp, id, ok := parseLHMSensors(run(lhmQuery)); ok {
+		return temp, id
+	}
+	if temp, id, ok := parseACPIZones(run(acpiQuery)); ok {
+		return temp, id
+	}
+	return 0, ""
+}
+
+// isWSL reports whether the monitor runs under the Windows Subsystem for
+// Linux, whose kernel release names Microsoft and which has no thermal
+// sysfs of its own.
+func isWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	data, err := ioutil.ReadFile(filepath.Join(procDir, "sys", "kernel", "osrelease"))
+	return err == nil && strings.Contains(strings.ToLower(string(data)), "microsoft")
+}
+
+// lhmCPUNames are the CPU temperature sensors LibreHardwareMonitor
+// reports, in order of preference: the Intel package, then the AMD die.
+var lhmCPUNames = []string{"CPU Package", "Core (Tctl/Tdie)", "Core (Tdie)", "Core (Tctl)", "CPU Cores"}
+
+// parseLHMSensors picks the CPU temperature from "identifier=name=value"
+// lines such as "/amdcpu/0/temperature/2=Core (Tctl/Tdie)=54.5". The id
+// is "lhm" followed by the identifier.
+func parseLHMSensors(out string) (float64, string, bool) {
+	best, bestRank := "", len(lhmCPUNames)
+	temps := map[string]float64{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "=", 3)
+		if len(parts) != 3 || !strings.Contains(parts[0], "cpu/") {
+			continue
+		}
+		temp, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil || temp <= 0 {
+			continue
+		}
+		temps[parts[0]] = temp
+		rank := len(lhmCPUNames)
+		for i, name := range lhmCPUNames {
+			if parts[1] == name {
+				rank = i
+			}
+		}
+		if best == "" || rank < bestRank {
+			best, bestRank = parts[0], rank
+		}
+	}
+	if best == "" {
+		return 0, "", false
+	}
+	return temps[best], "lhm" + best, true
+}
+
+// parseACPIZones reads "instance=tenths of kelvin" lines and returns the
+// hottest zone. The id is "acpi/" followed by the instance name.
+func parseACPIZones(out string) (float64, string, bool) {
+	hottest, id := 0.0, ""
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		tenths, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || tenths <= 0 {
+			continue
+		}
+		if temp := tenths/10 - 273.15; temp > hottest {
+			hottest, id = temp, "acpi/"+parts[0]
+		}
+	}
+	return hottest, id, id != ""
+}
@@ -0,0 +1,37 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWSLTemperature checks WSL detection from the kernel release and the
+// parsing of the host's PowerShell answers.
+func TestWSLTemperature(t *testing.T) {
+	dir := t.TempDir()
+	savedProc := procDir
+	t.Cleanup(func() { procDir = savedProc })
+	procDir = dir
+	t.Setenv("WSL_DISTRO_NAME", "")
+
+	if isWSL() {
+		t.Error("WSL detected without a kernel release")
+	}
+	os.MkdirAll(filepath.Join(dir, "sys", "kernel"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "sys", "kernel", "osrelease"), []byte("5.15.153.1-microsoft-standard-WSL2\n"), 0644)
+	if !isWSL() {
+		t.Error("WSL2 kernel release not detected")
+	}
+
+	lhm := "/intelcpu/0/temperature/0=CPU Core #1=58\r\n/intelcpu/0/temperature/8=CPU Package=61.5\r\n/nvidiagpu/0/temperature/0=GPU Core=45\r\n"
+	if temp, id, ok := parseLHMSensors(lhm); !ok || temp != 61.5 || id != "lhm/intelcpu/0/temperature/8" {
+		t.Errorf("LibreHardwareMonitor = %.1f°C %q %v; want 61.5°C lhm/intelcpu/0/temperature/8", temp, id, ok)
+	}
+	acpi := "ACPI\\ThermalZone\\TZ00_0=3032\r\nACPI\\ThermalZone\\TZ01_0=3182\r\n"
+	if temp, id, ok := parseACPIZones(acpi); !ok || math.Abs(temp-45.05) > 0.01 || id != `acpi/ACPI\ThermalZone\TZ01_0` {
+		t.Errorf("ACPI zones = %.2f°C %q %v; want 45.05°C on TZ01_0", temp, id, ok)
+	}
+}
@@ -0,0 +1,193 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// zabbixItem is one value in a Zabbix sender request.
+type zabbixItem struct {
+	Host  string `json:"host"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Clock int64  `json:"clock"`
+}
+
+// zabbixSink pushes samples to a Zabbix server or proxy as trapper items
+// using the sender protocol. Sends run in the background so a slow or
+// unreachable server never stalls the polling loop; a send that is still
+// in progress causes the next batch to be skipped.
+type zabbixSink struct {
+	cfg      *Config
+	lastSend time.Time
+	pending  chan []zabbixItem
+
+	mu      sync.Mutex
+	lastErr error
+	done    chan struct{}
+}
+
+// newZabbixSink starts the background sender for the [zabbix] config.
+func newZabbixSink(cfg *Config) *zabbixSink {
+	z := &zabbixSink{
+		cfg:     cfg,
+		pending: make(chan []zabbixItem, 1),
+		done:    make(chan struct{}),
+	}
+	go z.loop()
+	return z
+}
+
+// loop sends queued batches until the sink is closed.
+func (z *zabbixSink) loop() {
+	defer close(z.done)
+	for items := range z.pending {
+		err := z.send(items)
+		z.mu.Lock()
+		z.lastErr = err
+		z.mu.Unlock()
+	}
+}
+
+// write queues the sample for sending if the interval has elapsed. It
+// returns the error from the previous send, if any.
+func (z *zabbixSink) write(s *Sample) error {
+	if s.Time.Sub(z.lastSend) < z.cfg.Zabbix.Interval {
+		return nil
+	}
+	z.lastSend = s.Time
+
+	select {
+	case z.pending <- z.items(s):
+	default:
+		// Previous send still in progress; skip this batch
+	}
+
+	z.mu.Lock()
+	err := z.lastErr
+	z.lastErr = nil
+	z.mu.Unlock()
+	return err
+}
+
+// close waits for any in-flight send to finish.
+func (z *zabbixSink) close() error {
+	close(z.pending)
+	<-z.done
+	return nil
+}
+
+// items converts a sample into trapper items using the configured keys.
+// Metrics with an empty key or an unavailable source are left out.
+func (z *zabbixSink) items(s *Sample) []zabbixItem {
+	keys := z.cfg.Zabbix.Keys
+	clock := s.Time.Unix()
+	var items []zabbixItem
+	add := func(key, value string, available bool) {
+		if key == "" || !available {
+			return
+		}
+		items = append(items, zabbixItem{Host: z.cfg.Zabbix.Host, Key: key, Value: value, Clock: clock})
+	}
+	float := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+
+	// Stress is sent as 0/1 so it fits a numeric (unsigned) item
+	stress := "0"
+	if s.Stress {
+		stress = "1"
+	}
+	add(keys.CPU, float(s.CPU), true)
+	add(keys.Temp, float(s.Temp), s.Temp > 0)
+	add(keys.GPU, float(s.GPU), s.GPU >= 0)
+	add(keys.Disk, float(s.Disk), s.Disk >= 0)
+	add(keys.Net, float(s.Net), s.Net >= 0)
+	add(keys.Stress, stress, true)
+	return items
+}
+
+// send delivers one batch and checks the server's response.
+func (z *zabbixSink) send(items []zabbixItem) error {
+	payload, err := json.Marshal(struct {
+		Request string       `json:"request"`
+		Data    []zabbixItem `json:"data"`
+	}{"sender data", items})
+	if err != nil {
+		return err
+	}
+
+	server := z.cfg.Zabbix.Server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "10051")
+	}
+	conn, err := net.DialTimeout("tcp", server, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("zabbix: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	if _, err := conn.Write(zabbixPacket(payload)); err != nil {
+		return fmt.Errorf("zabbix: %v", err)
+	}
+
+	reply, err := readZabbixPacket(conn)
+	if err != nil {
+		return fmt.Errorf("zabbix: %v", err)
+	}
+	var resp struct {
+		Response string `json:"response"`
+		Info     string `json:"info"`
+	}
+	if err := json.Unmarshal(reply, &resp); err != nil {
+		return fmt.Errorf("zabbix: invalid response: %v", err)
+	}
+	if resp.Response != "success" {
+		return fmt.Errorf("zabbix: server replied %q: %s", resp.Response, resp.Info)
+	}
+	// "failed: N" in info means the server rejected some items, usually
+	// because the host or trapper item does not exist
+	if strings.Contains(resp.Info, "failed: ") && !strings.Contains(resp.Info, "failed: 0") {
+		return fmt.Errorf("zabbix: %s", resp.Info)
+	}
+	return nil
+}
+
+// zabbixPacket frames a payload with the ZBXD protocol header: the magic,
+// a flags byte, and the little-endian data length plus reserved bytes.
+func zabbixPacket(payload []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("ZBXD\x01")
+	binary.Write(&buf, binary.LittleEndian, uint64(len(payload)))
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// readZabbixPacket reads one ZBXD-framed reply.
+func readZabbixPacket(r io.Reader) ([]byte, error) {
+	header := make([]byte, 13)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if string(header[:4]) != "ZBXD" {
+		return nil, fmt.Errorf("invalid response header")
+	}
+	length := binary.LittleEndian.Uint64(header[5:])
+	if length > 1<<20 {
+		return nil, fmt.Errorf("response too large")
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
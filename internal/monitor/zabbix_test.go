@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestZabbixItems checks the trapper items of fixed samples, the ZBXD
+// framing of a sender request, and that a framed reply reads back.
+func TestZabbixItems(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Zabbix.Host = "bench"
+	cfg.Zabbix.Keys.CPU, cfg.Zabbix.Keys.Temp, cfg.Zabbix.Keys.GPU = "kkperf.cpu", "kkperf.temp", "kkperf.gpu"
+	cfg.Zabbix.Keys.Disk, cfg.Zabbix.Keys.Net, cfg.Zabbix.Keys.Stress = "kkperf.disk", "", "kkperf.stress"
+	z := &zabbixSink{cfg: cfg}
+
+	for _, tc := range []struct {
+		name   string
+		sample Sample
+		want   string
+	}{
+		{
+			name:   "all sources",
+			sample: Sample{Time: time.Unix(1700000000, 0), CPU: 12.345, Temp: 71.5, GPU: 40, Disk: 0, Net: 5, Stress: true},
+			want: `[{"host":"bench","key":"kkperf.cpu","value":"12.35","clock":1700000000},` +
+				`{"host":"bench","key":"kkperf.temp","value":"71.50","clock":1700000000},` +
+				`{"host":"bench","key":"kkperf.gpu","value":"40.00","clock":1700000000},` +
+				`{"host":"bench","key":"kkperf.disk","value":"0.00","clock":1700000000},` +
+				`{"host":"bench","key":"kkperf.stress","value":"1","clock":1700000000}]`,
+		},
+		{
+			name:   "unavailable sources",
+			sample: Sample{Time: time.Unix(1700000060, 0), CPU: 5, GPU: -1, Disk: -1, Net: -1},
+			want: `[{"host":"bench","key":"kkperf.cpu","value":"5.00","clock":1700000060},` +
+				`{"host":"bench","key":"kkperf.stress","value":"0","clock":1700000060}]`,
+		},
+	} {
+		data, _ := json.Marshal(z.items(&tc.sample))
+		if string(data) != tc.want {
+			t.Errorf("%s: items = %s\nwant %s", tc.name, data, tc.want)
+		}
+	}
+
+	payload := []byte(`{"request":"sender data","data":[]}`)
+	packet := zabbixPacket(payload)
+	header := []byte{'Z', 'B', 'X', 'D', 1, byte(len(payload)), 0, 0, 0, 0, 0, 0, 0}
+	if !bytes.HasPrefix(packet, header) || !bytes.Equal(packet[len(header):], payload) {
+		t.Errorf("packet = %q; want header %q then the payload", packet, header)
+	}
+	if reply, err := readZabbixPacket(bytes.NewReader(packet)); err != nil || !bytes.Equal(reply, payload) {
+		t.Errorf("read back %q, %v", reply, err)
+	}
+	if _, err := readZabbixPacket(bytes.NewReader(append([]byte("HTTP/"), packet[5:]...))); err == nil {
+		t.Error("reply without the ZBXD magic accepted")
+	}
+}
@@ -0,0 +1,410 @@
+// Package toml implements the subset of TOML used by the kkperf config
+// file and decodes it into tagged structs.
+package toml
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse parses the subset of TOML used by the config file: tables,
+// arrays of tables, and key/value pairs whose values are strings, numbers,
+// booleans, inline tables, or (possibly multi-line) arrays of those.
+func Parse(data string) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	current := root
+	lines := strings.Split(data, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(stripTOMLComment(lines[i]))
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "[["):
+			// Array of tables: append a fresh table to the named array
+			if !strings.HasSuffix(line, "]]") {
+				return nil, fmt.Errorf("line %d: unterminated table header", lineNo)
+			}
+			name := strings.TrimSpace(line[2 : len(line)-2])
+			parentPath, last := splitTOMLPath(name)
+			parent, err := tomlTable(root, parentPath)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			arr, _ := parent[last].([]interface{})
+			table := map[string]interface{}{}
+			parent[last] = append(arr, table)
+			current = table
+
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated table header", lineNo)
+			}
+			table, err := tomlTable(root, strings.TrimSpace(line[1:len(line)-1]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			current = table
+
+		default:
+			eq := indexOutsideQuotes(line, '=')
+			if eq < 0 {
+				return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+			}
+			key := unquoteTOMLKey(strings.TrimSpace(line[:eq]))
+			raw := strings.TrimSpace(line[eq+1:])
+
+			// Arrays may span several lines; keep reading until brackets balance
+			for strings.HasPrefix(raw, "[") && !tomlBalanced(raw) && i+1 < len(lines) {
+				i++
+				raw += " " + strings.TrimSpace(stripTOMLComment(lines[i]))
+			}
+
+			val, err := parseTOMLValue(raw)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %v", lineNo, key, err)
+			}
+			current[key] = val
+		}
+	}
+
+	return root, nil
+}
+
+// tomlTable walks a dotted table path from root, creating tables as needed.
+// When a path element names an array of tables, the most recent entry is used.
+func tomlTable(root map[string]interface{}, path string) (map[string]interface{}, error) {
+	t := root
+	if path == "" {
+		return t, nil
+	}
+	for _, part := range strings.Split(path, ".") {
+		part = unquoteTOMLKey(strings.TrimSpace(part))
+		switch v := t[part].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			t[part] = next
+			t = next
+		case map[string]interface{}:
+			t = v
+		case []interface{}:
+			if len(v) == 0 {
+				return nil, fmt.Errorf("%q is an empty array", part)
+			}
+			last, ok := v[len(v)-1].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%q is not a table", part)
+			}
+			t = last
+		default:
+			return nil, fmt.Errorf("%q is not a table", part)
+		}
+	}
+	return t, nil
+}
+
+// splitTOMLPath splits "a.b.c" into the parent path "a.b" and the last key "c".
+func splitTOMLPath(path string) (string, string) {
+	if dot := strings.LastIndex(path, "."); dot >= 0 {
+		return path[:dot], unquoteTOMLKey(strings.TrimSpace(path[dot+1:]))
+	}
+	return "", unquoteTOMLKey(path)
+}
+
+// unquoteTOMLKey strips the quotes from a quoted key; bare keys are returned as-is.
+func unquoteTOMLKey(key string) string {
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+		if key[0] == '"' {
+			if s, err := strconv.Unquote(key); err == nil {
+				return s
+			}
+		}
+		return key[1 : len(key)-1]
+	}
+	return key
+}
+
+// stripTOMLComment removes a trailing # comment that is not inside a string.
+func stripTOMLComment(line string) string {
+	if i := indexOutsideQuotes(line, '#'); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// indexOutsideQuotes returns the index of the first occurrence of c that is
+// not inside a basic or literal string, or -1 if there is none.
+func indexOutsideQuotes(s string, c byte) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == '\\' && quote == '"' {
+				i++
+			} else if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == c:
+			return i
+		}
+	}
+	return -1
+}
+
+// tomlBalanced reports whether all brackets and braces in s are closed.
+func tomlBalanced(s string) bool {
+	return tomlDepth(s) == 0
+}
+
+// tomlDepth returns the bracket nesting depth at the end of s, ignoring strings.
+func tomlDepth(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == '\\' && quote == '"' {
+				i++
+			} else if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '[' || s[i] == '{':
+			depth++
+		case s[i] == ']' || s[i] == '}':
+			depth--
+		}
+	}
+	return depth
+}
+
+// splitTOMLList splits s on sep at nesting depth zero, ignoring separators
+// inside strings, arrays and inline tables. Empty trailing items are dropped.
+func splitTOMLList(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == '\\' && quote == '"' {
+				i++
+			} else if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '[' || s[i] == '{':
+			depth++
+		case s[i] == ']' || s[i] == '}':
+			depth--
+		case s[i] == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	if tail := strings.TrimSpace(s[start:]); tail != "" || len(parts) == 0 {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
+
+// parseTOMLValue converts a raw TOML value into a Go value: string, int64,
+// float64, bool, []interface{} or map[string]interface{}.
+func parseTOMLValue(raw string) (interface{}, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+
+	switch raw[0] {
+	case '"':
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return s, nil
+	case '\'':
+		if len(raw) < 2 || raw[len(raw)-1] != '\'' {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case '[':
+		if raw[len(raw)-1] != ']' {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		arr := []interface{}{}
+		for _, item := range splitTOMLList(raw[1:len(raw)-1], ',') {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+			v, err := parseTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case '{':
+		if raw[len(raw)-1] != '}' {
+			return nil, fmt.Errorf("unterminated inline table")
+		}
+		table := map[string]interface{}{}
+		for _, item := range splitTOMLList(raw[1:len(raw)-1], ',') {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+			eq := indexOutsideQuotes(item, '=')
+			if eq < 0 {
+				return nil, fmt.Errorf("expected key = value in inline table")
+			}
+			v, err := parseTOMLValue(item[eq+1:])
+			if err != nil {
+				return nil, err
+			}
+			table[unquoteTOMLKey(strings.TrimSpace(item[:eq]))] = v
+		}
+		return table, nil
+	}
+
+	switch raw {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(raw, 0, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("invalid value %s", raw)
+}
+
+// Decode copies a parsed TOML table into the struct pointed to by out.
+// Struct fields are matched by their `toml` tag; keys without a matching
+// field are ignored so older binaries tolerate newer config files.
+func Decode(table map[string]interface{}, out interface{}) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("toml.Decode: expected pointer to struct")
+	}
+	return decodeTOMLStruct(table, v.Elem(), "")
+}
+
+// decodeTOMLStruct assigns table entries to the tagged fields of a struct value.
+func decodeTOMLStruct(table map[string]interface{}, v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("toml")
+		if name == "" || name == "-" {
+			continue
+		}
+		raw, ok := table[name]
+		if !ok {
+			continue
+		}
+		if err := assignTOML(v.Field(i), raw, prefix+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignTOML stores a parsed TOML value into dst, converting between the
+// TOML value kinds and the destination field type.
+func assignTOML(dst reflect.Value, raw interface{}, path string) error {
+	if dst.Type() == reflect.TypeOf(time.Duration(0)) {
+		switch r := raw.(type) {
+		case string:
+			d, err := time.ParseDuration(r)
+			if err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+			dst.SetInt(int64(d))
+			return nil
+		case int64:
+			// Bare integers are interpreted as seconds
+			dst.SetInt(int64(time.Duration(r) * time.Second))
+			return nil
+		}
+		return fmt.Errorf("%s: expected duration", path)
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string", path)
+		}
+		dst.SetString(s)
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("%s: expected boolean", path)
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := raw.(int64)
+		if !ok {
+			return fmt.Errorf("%s: expected integer", path)
+		}
+		dst.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		switch n := raw.(type) {
+		case int64:
+			dst.SetFloat(float64(n))
+		case float64:
+			dst.SetFloat(n)
+		default:
+			return fmt.Errorf("%s: expected number", path)
+		}
+	case reflect.Slice:
+		arr, ok := raw.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array", path)
+		}
+		out := reflect.MakeSlice(dst.Type(), len(arr), len(arr))
+		for i, item := range arr {
+			if err := assignTOML(out.Index(i), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(out)
+	case reflect.Map:
+		table, ok := raw.(map[string]interface{})
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%s: expected table", path)
+		}
+		out := reflect.MakeMapWithSize(dst.Type(), len(table))
+		for k, item := range table {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := assignTOML(elem, item, path+"."+k); err != nil {
+				return err
+			}
+			out.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(out)
+	case reflect.Struct:
+		table, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected table", path)
+		}
+		return decodeTOMLStruct(table, dst, path+".")
+	default:
+		return fmt.Errorf("%s: unsupported field type %s", path, dst.Type())
+	}
+	return nil
+}
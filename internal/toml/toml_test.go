@@ -0,0 +1,244 @@
+package toml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseStrings checks basic strings with their escapes, literal strings
+// taken as written, and quoted keys.
+func TestParseStrings(t *testing.T) {
+	table, err := Parse(`
+basic = "tab\there \"quoted\" back\\slash \u00e9"
+literal = 'C:\Users\kkperf'
+hash = "not # a comment"
+single = 'also # not one'
+empty = ""
+"quoted key" = "x"
+'literal key' = "y"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"basic":       "tab\there \"quoted\" back\\slash é",
+		"literal":     `C:\Users\kkperf`,
+		"hash":        "not # a comment",
+		"single":      "also # not one",
+		"empty":       "",
+		"quoted key":  "x",
+		"literal key": "y",
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("Parse = %#v; want %#v", table, want)
+	}
+}
+
+// TestParseValues checks numbers, booleans, arrays over several lines and
+// inline tables.
+func TestParseValues(t *testing.T) {
+	table, err := Parse(`
+int = 42
+negative = -7
+hex = 0x1b
+float = 2.5
+grouped = 1_000.5
+yes = true
+no = false
+empty = []
+nested = [[1, 2], ["a, b", 'c]']]
+sensors = [
+  "k10temp",   # CPU
+  "nvme",      # Disk, with a trailing comma
+]
+point = { x = 1, "y z" = "2", list = [3, 4] }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"int":      int64(42),
+		"negative": int64(-7),
+		"hex":      int64(0x1b),
+		"float":    2.5,
+		"grouped":  1000.5,
+		"yes":      true,
+		"no":       false,
+		"empty":    []interface{}{},
+		"nested":   []interface{}{[]interface{}{int64(1), int64(2)}, []interface{}{"a, b", "c]"}},
+		"sensors":  []interface{}{"k10temp", "nvme"},
+		"point": map[string]interface{}{
+			"x":    int64(1),
+			"y z":  "2",
+			"list": []interface{}{int64(3), int64(4)},
+		},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("Parse = %#v; want %#v", table, want)
+	}
+}
+
+// TestParseTables checks table headers, dotted and quoted table names,
+// arrays of tables, and tables nested under the latest array entry.
+func TestParseTables(t *testing.T) {
+	table, err := Parse(`
+# Full-line comment
+top = 1 # Trailing comment
+
+[safety]
+enabled = true
+
+[alerts.temp]
+limit = 90
+
+[ "quoted key" ]
+x = 1
+
+[[remote]]
+name = "a"
+
+[[remote]]
+name = "b"
+[remote.auth]
+user = "kk"
+
+[safety]
+log = "s.log"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"top":    int64(1),
+		"safety": map[string]interface{}{"enabled": true, "log": "s.log"},
+		"alerts": map[string]interface{}{
+			"temp": map[string]interface{}{"limit": int64(90)},
+		},
+		"quoted key": map[string]interface{}{"x": int64(1)},
+		"remote": []interface{}{
+			map[string]interface{}{"name": "a"},
+			map[string]interface{}{"name": "b", "auth": map[string]interface{}{"user": "kk"}},
+		},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("Parse = %#v; want %#v", table, want)
+	}
+}
+
+// TestParseErrors checks that malformed input is refused with the line it
+// is on.
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  string
+	}{
+		{"just words", "line 1: expected key = value"},
+		{"a = 1\n\n[safety", "line 3: unterminated table header"},
+		{"[[remote]\nname = 1", "line 1: unterminated table header"},
+		{"a =", "line 1: a: missing value"},
+		{"a = # nothing", "line 1: a: missing value"},
+		{"\nname = \"open", "line 2: name: invalid string"},
+		{"name = 'open", "line 1: name: invalid string"},
+		{`name = "bad \q escape"`, "line 1: name: invalid string"},
+		{"a = nope", "line 1: a: invalid value nope"},
+		{"a = 1.2.3", "line 1: a: invalid value 1.2.3"},
+		{"list = [1,\n  2,\n", "line 1: list: unterminated array"},
+		{"list = [1, nope]", "line 1: list: invalid value nope"},
+		{"p = { x = 1", "line 1: p: unterminated inline table"},
+		{"p = { x }", "line 1: p: expected key = value in inline table"},
+		{"a = 1\n[a]", `line 2: "a" is not a table`},
+		{"a = [1]\n[a.b]", `line 2: "a" is not a table`},
+		{"a = []\n[a.b]", `line 2: "a" is an empty array`},
+	} {
+		_, err := Parse(tc.input)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) = %v; want %q", tc.input, err, tc.want)
+		}
+	}
+}
+
+// TestDecode checks that values reach the tagged fields, converting where
+// the field type allows, and that mismatches name the key.
+func TestDecode(t *testing.T) {
+	type alert struct {
+		Limit float64 `toml:"limit"`
+	}
+	type config struct {
+		Name     string            `toml:"name"`
+		Enabled  bool              `toml:"enabled"`
+		Count    int               `toml:"count"`
+		Scale    float64           `toml:"scale"`
+		Interval time.Duration     `toml:"interval"`
+		Timeout  time.Duration     `toml:"timeout"`
+		Sensors  []string          `toml:"sensors"`
+		Labels   map[string]string `toml:"labels"`
+		Alert    alert             `toml:"alert"`
+		Alerts   []alert           `toml:"alerts"`
+		Skipped  string            `toml:"-"`
+		Untagged string
+	}
+	table, err := Parse(`
+name = "bench"
+enabled = true
+count = 3
+scale = 2
+interval = "1m30s"
+timeout = 5
+sensors = ["a", "b"]
+labels = { rack = "r1" }
+unknown = "ignored"
+Untagged = "ignored"
+[alert]
+limit = 90.5
+[[alerts]]
+limit = 80
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got config
+	if err := Decode(table, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := config{
+		Name:     "bench",
+		Enabled:  true,
+		Count:    3,
+		Scale:    2,
+		Interval: 90 * time.Second,
+		Timeout:  5 * time.Second,
+		Sensors:  []string{"a", "b"},
+		Labels:   map[string]string{"rack": "r1"},
+		Alert:    alert{Limit: 90.5},
+		Alerts:   []alert{{Limit: 80}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode = %+v; want %+v", got, want)
+	}
+
+	for _, tc := range []struct {
+		input string
+		want  string
+	}{
+		{`name = 1`, "name: expected string"},
+		{`count = 1.5`, "count: expected integer"},
+		{`interval = "soon"`, "interval: time: invalid duration"},
+		{`interval = 1.5`, "interval: expected duration"},
+		{`sensors = ["a", 2]`, "sensors[1]: expected string"},
+		{`labels = { rack = 1 }`, "labels.rack: expected string"},
+		{"[alert]\nlimit = \"hot\"", "alert.limit: expected number"},
+	} {
+		table, err := Parse(tc.input)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.input, err)
+		}
+		if err := Decode(table, &config{}); err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+			t.Errorf("Decode(%q) = %v; want %q", tc.input, err, tc.want)
+		}
+	}
+	if err := Decode(table, config{}); err == nil {
+		t.Error("Decode into a struct value, not a pointer, succeeded")
+	}
+}
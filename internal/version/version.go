@@ -0,0 +1,26 @@
+// Package version holds the build information shared by the kkperf
+// binaries. The values are set at build time via -ldflags, e.g.
+//
+//	-X 'github.com/jeremycharlesgillespie/kode_kronical_perf_monitor/internal/version.Version=v1.2.0'
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Build information - these values are set at build time via -ldflags
+var (
+	Version = "dev"     // Application version
+	Commit  = "unknown" // Git commit hash
+	Date    = "unknown" // Build date
+)
+
+// Print writes the version and build information of the named binary to
+// stdout.
+func Print(name string) {
+	fmt.Printf("%s %s\n", name, Version)
+	fmt.Printf("Commit: %s\n", Commit)
+	fmt.Printf("Built:  %s\n", Date)
+	fmt.Printf("Go:     %s\n", runtime.Version())
+}